@@ -0,0 +1,418 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaswant99k/gotap/shadowdb"
+)
+
+// AdminConfig holds configuration for the Admin dashboard. Accounts or
+// Middleware must authenticate its users.
+type AdminConfig struct {
+	// Accounts require HTTP basic auth, see BasicAuth
+	// Optional.
+	Accounts Accounts
+
+	// AllowedIPs restricts the dashboard to these IPs and CIDR ranges, see
+	// IPWhitelist
+	// Default: any IP
+	AllowedIPs []string
+
+	// Middleware runs before the dashboard, e.g. JWT authentication and
+	// an RBAC role check
+	// Optional.
+	Middleware []HandlerFunc
+
+	// Checks are the health checks by name, e.g. pinging the database
+	// Optional.
+	Checks map[string]func(ctx context.Context) error
+
+	// CheckTimeout bounds each health check
+	// Default: 5s
+	CheckTimeout time.Duration
+
+	// Stats are reported in the metrics panel by name, e.g. the Stats
+	// method of a Bulkhead, LoadShedder or ConsumerRunner
+	// Optional.
+	Stats map[string]func() any
+
+	// RateLimiters are the rate limiter stores by name; stores
+	// implementing RateLimiterInspector list their counters, which can be
+	// reset. See NewMemoryRateLimiterStore.
+	// Optional.
+	RateLimiters map[string]RateLimiterStore
+
+	// Flags are the feature flags, switchable from the dashboard when they
+	// have a Set method like KVFlags
+	// Optional.
+	Flags FeatureFlags
+
+	// FlagNames lists the flags shown
+	// Default: the keys of StaticFlags
+	FlagNames []string
+
+	// ShadowDB reports database health, see ShadowDBStatusHandler
+	// Optional.
+	ShadowDB *shadowdb.ShadowDB
+
+	// Hubs are the WebSocket hubs by name, reporting their client counts
+	// Optional.
+	Hubs map[string]*WebSocketHub
+}
+
+// AdminCheck is the result of a health check of the Admin dashboard
+type AdminCheck struct {
+	Name     string  `json:"name"`
+	Healthy  bool    `json:"healthy"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// AdminFlag is a feature flag of the Admin dashboard
+type AdminFlag struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Writable bool   `json:"writable"`
+}
+
+// flagSetter is implemented by feature flags switchable at runtime
+type flagSetter interface {
+	Set(ctx context.Context, flag string, enabled bool) error
+}
+
+// Admin mounts a dashboard for operations teams on group, showing the
+// routes, health checks, runtime metrics, rate limit counters, feature
+// flags, ShadowDB status and WebSocket clients:
+//
+//	admin := goTap.Admin(r.Group("/admin"), goTap.AdminConfig{
+//	    Accounts:     goTap.Accounts{"ops": os.Getenv("ADMIN_PASSWORD")},
+//	    Checks:       map[string]func(context.Context) error{"redis": pingRedis},
+//	    Stats:        map[string]func() any{"reports": func() any { return bulkhead.Stats() }},
+//	    RateLimiters: map[string]goTap.RateLimiterStore{"login": loginStore},
+//	    Flags:        flags,
+//	    ShadowDB:     sdb,
+//	    Hubs:         map[string]*goTap.WebSocketHub{"kitchen": hub},
+//	})
+//	admin.GET("/cache/stats", cacheStats)
+//
+// The page is served at the group path and reads JSON endpoints under
+// /api. It returns the authenticated group, for more admin endpoints. It
+// panics when neither Accounts nor Middleware is set.
+func Admin(group *RouterGroup, config AdminConfig) *RouterGroup {
+	if len(config.Accounts) == 0 && len(config.Middleware) == 0 {
+		panic("goTap: Admin requires Accounts or Middleware for authentication")
+	}
+	if config.CheckTimeout <= 0 {
+		config.CheckTimeout = 5 * time.Second
+	}
+	if len(config.FlagNames) == 0 {
+		if static, ok := config.Flags.(StaticFlags); ok {
+			for name := range static {
+				config.FlagNames = append(config.FlagNames, name)
+			}
+			sort.Strings(config.FlagNames)
+		}
+	}
+
+	var guards []HandlerFunc
+	if len(config.AllowedIPs) > 0 {
+		guards = append(guards, IPWhitelist(config.AllowedIPs...))
+	}
+	if len(config.Accounts) > 0 {
+		guards = append(guards, BasicAuthForRealm(config.Accounts, "Admin"))
+	}
+	guards = append(guards, config.Middleware...)
+	admin := group.Group("", guards...)
+	prefix := strings.TrimSuffix(admin.BasePath(), "/")
+	engine := admin.engine
+
+	admin.GET("", func(c *Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(adminPage))
+	})
+
+	api := admin.Group("/api", func(c *Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Next()
+	})
+	api.GET("/routes", func(c *Context) {
+		routes := make(RoutesInfo, 0)
+		for _, route := range engine.describeRoutes() {
+			if route.Path != prefix && !strings.HasPrefix(route.Path, prefix+"/") {
+				routes = append(routes, route)
+			}
+		}
+		c.JSON(http.StatusOK, routes)
+	})
+	api.GET("/health", func(c *Context) {
+		checks := runAdminChecks(c.Request.Context(), config.Checks, config.CheckTimeout)
+		code := http.StatusOK
+		for _, check := range checks {
+			if !check.Healthy {
+				code = http.StatusServiceUnavailable
+			}
+		}
+		c.JSON(code, H{"healthy": code == http.StatusOK, "checks": checks})
+	})
+	api.GET("/metrics", func(c *Context) {
+		stats := H{}
+		for name, fn := range config.Stats {
+			stats[name] = fn()
+		}
+		c.JSON(http.StatusOK, H{
+			"runtime":          readRuntimeStats(),
+			"circuit_breakers": CircuitBreakerStats(),
+			"stats":            stats,
+		})
+	})
+	api.GET("/ratelimits", func(c *Context) {
+		limiters := H{}
+		for name, store := range config.RateLimiters {
+			inspector, ok := store.(RateLimiterInspector)
+			if !ok {
+				limiters[name] = nil
+				continue
+			}
+			limiters[name] = inspector.Entries()
+		}
+		c.JSON(http.StatusOK, limiters)
+	})
+	api.POST("/ratelimits/:name/reset", func(c *Context) {
+		store, ok := config.RateLimiters[c.Param("name")]
+		if !ok {
+			c.JSON(http.StatusNotFound, H{"error": "Not Found", "message": "unknown rate limiter"})
+			c.Abort()
+			return
+		}
+		var body struct {
+			Key string `json:"key" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": "Bad Request", "message": err.Error()})
+			c.Abort()
+			return
+		}
+		if err := store.Reset(body.Key); err != nil {
+			c.JSON(http.StatusInternalServerError, H{"error": "Internal Server Error", "message": err.Error()})
+			c.Abort()
+			return
+		}
+		debugPrint("Admin reset rate limit %s for %s", c.Param("name"), body.Key)
+		c.Status(http.StatusNoContent)
+	})
+	api.GET("/flags", func(c *Context) {
+		flags := make([]AdminFlag, 0, len(config.FlagNames))
+		if config.Flags != nil {
+			_, writable := config.Flags.(flagSetter)
+			for _, name := range config.FlagNames {
+				flags = append(flags, AdminFlag{
+					Name:     name,
+					Enabled:  config.Flags.Enabled(c.Request.Context(), name, false),
+					Writable: writable,
+				})
+			}
+		}
+		c.JSON(http.StatusOK, flags)
+	})
+	api.PUT("/flags/:flag", func(c *Context) {
+		setter, ok := config.Flags.(flagSetter)
+		if !ok {
+			c.JSON(http.StatusMethodNotAllowed, H{"error": "Method Not Allowed", "message": "feature flags are read-only"})
+			c.Abort()
+			return
+		}
+		var body struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": "Bad Request", "message": err.Error()})
+			c.Abort()
+			return
+		}
+		if err := setter.Set(c.Request.Context(), c.Param("flag"), body.Enabled); err != nil {
+			c.JSON(http.StatusInternalServerError, H{"error": "Internal Server Error", "message": err.Error()})
+			c.Abort()
+			return
+		}
+		debugPrint("Admin set feature flag %s to %v", c.Param("flag"), body.Enabled)
+		c.Status(http.StatusNoContent)
+	})
+	if config.ShadowDB != nil {
+		api.GET("/shadowdb", ShadowDBStatusHandler(config.ShadowDB))
+	}
+	api.GET("/websockets", func(c *Context) {
+		hubs := H{}
+		for name, hub := range config.Hubs {
+			hubs[name] = hub.Stats()
+		}
+		c.JSON(http.StatusOK, hubs)
+	})
+
+	return admin
+}
+
+// runAdminChecks runs the health checks in parallel, sorted by name
+func runAdminChecks(ctx context.Context, checks map[string]func(ctx context.Context) error, timeout time.Duration) []AdminCheck {
+	results := make([]AdminCheck, 0, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := check(ctx)
+			result := AdminCheck{Name: name, Healthy: err == nil, Duration: durationMillis(time.Since(start))}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+const adminPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>goTap admin</title>
+<style>
+body { font: 14px system-ui, sans-serif; margin: 0 auto; max-width: 1100px; padding: 0 24px 24px; }
+h1 { font-size: 20px; }
+section { border: 1px solid #ddd; border-radius: 4px; margin: 16px 0; padding: 8px 16px; }
+h2 { font-size: 15px; margin: 8px 0; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 3px 8px; border-bottom: 1px solid #eee; font-family: monospace; }
+th { font-family: system-ui, sans-serif; color: #666; font-weight: 600; }
+.ok { color: #080; }
+.fail { color: #c00; }
+.muted { color: #666; }
+button { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>goTap admin <span class="muted" id="updated"></span></h1>
+<section><h2>Health</h2><div id="health"></div></section>
+<section><h2>Metrics</h2><div id="metrics"></div></section>
+<section><h2>Rate limits</h2><div id="ratelimits"></div></section>
+<section><h2>Feature flags</h2><div id="flags"></div></section>
+<section id="shadowdb-section" hidden><h2>ShadowDB</h2><div id="shadowdb"></div></section>
+<section><h2>WebSockets</h2><div id="websockets"></div></section>
+<section><h2>Routes</h2><div id="routes"></div></section>
+<script>
+const base = location.pathname.replace(/\/$/, "") + "/api";
+
+function el(tag, props, ...children) {
+  const e = Object.assign(document.createElement(tag), props);
+  e.append(...children);
+  return e;
+}
+
+function text(v) {
+  return v === null || v === undefined ? "" : typeof v === "object" ? JSON.stringify(v) : String(v);
+}
+
+function table(columns, rows) {
+  if (!rows.length) return el("p", {className: "muted", textContent: "None"});
+  return el("table", {},
+    el("tr", {}, ...columns.map(c => el("th", {textContent: c}))),
+    ...rows.map(row => el("tr", {}, ...row.map(v => v instanceof Node ? el("td", {}, v) : el("td", {textContent: text(v)})))));
+}
+
+function status(ok, label) {
+  return el("span", {className: ok ? "ok" : "fail", textContent: label || (ok ? "ok" : "failing")});
+}
+
+async function get(path) {
+  const res = await fetch(base + path);
+  return res.json();
+}
+
+async function send(method, path, body) {
+  const res = await fetch(base + path, {method, headers: {"Content-Type": "application/json"}, body: JSON.stringify(body)});
+  if (!res.ok) alert((await res.json()).message);
+  refresh();
+}
+
+function show(id, node) {
+  document.getElementById(id).replaceChildren(node);
+}
+
+async function refresh() {
+  const health = await get("/health");
+  show("health", table(["check", "status", "ms", "error"],
+    health.checks.map(c => [c.name, status(c.healthy), c.duration_ms.toFixed(1), c.error])));
+
+  const metrics = await get("/metrics");
+  const rt = metrics.runtime;
+  show("metrics", el("div", {},
+    table(["uptime", "goroutines", "heap MB", "GC", "GC CPU"],
+      [[rt.uptime_seconds + "s", rt.goroutines, (rt.heap_alloc_bytes / 1048576).toFixed(1), rt.num_gc, (rt.gc_cpu_fraction * 100).toFixed(2) + "%"]]),
+    table(["circuit breaker", "state", "stats"], (metrics.circuit_breakers || []).map(b => [b.name, b.state, b])),
+    table(["component", "stats"], Object.entries(metrics.stats).map(([k, v]) => [k, v]))));
+
+  const limits = await get("/ratelimits");
+  const rows = [];
+  Object.entries(limits).forEach(([name, entries]) => {
+    if (entries === null) return rows.push([name, "", "not inspectable", ""]);
+    entries.forEach(e => {
+      const reset = el("button", {textContent: "Reset"});
+      reset.onclick = () => send("POST", "/ratelimits/" + encodeURIComponent(name) + "/reset", {key: e.key});
+      rows.push([name, e.key, e.count, new Date(e.expires_at).toLocaleTimeString(), reset]);
+    });
+  });
+  show("ratelimits", table(["limiter", "key", "count", "resets", ""], rows));
+
+  const flags = await get("/flags");
+  show("flags", table(["flag", "enabled", ""], flags.map(f => {
+    let toggle = "";
+    if (f.writable) {
+      toggle = el("button", {textContent: f.enabled ? "Disable" : "Enable"});
+      toggle.onclick = () => send("PUT", "/flags/" + encodeURIComponent(f.name), {enabled: !f.enabled});
+    }
+    return [f.name, status(f.enabled, f.enabled ? "on" : "off"), toggle];
+  })));
+
+  const db = await fetch(base + "/shadowdb");
+  if (db.status !== 404) {
+    const s = await db.json();
+    document.getElementById("shadowdb-section").hidden = false;
+    show("shadowdb", table(["database", "status", "queries", "errors", "p99 ms"],
+      [["active: " + s.active_db, status(s.healthy), "", "", ""],
+       ...Object.entries(s.databases).map(([k, d]) => [k, d.status, d.queries, d.errors, d.p99_ms.toFixed(1)]),
+       ...s.replicas.map(r => [r.name, r.status, r.queries, r.errors, r.p99_ms.toFixed(1)])]));
+  }
+
+  const hubs = await get("/websockets");
+  show("websockets", table(["hub", "clients", "queued", "dropped", "disconnected"],
+    Object.entries(hubs).map(([k, h]) => [k, h.clients, h.queued, h.dropped, h.disconnected])));
+
+  const routes = await get("/routes");
+  show("routes", table(["method", "path", "handler", "middleware"],
+    routes.map(r => [r.method, r.path, r.handler, (r.middleware || []).join(", ")])));
+
+  document.getElementById("updated").textContent = "updated " + new Date().toLocaleTimeString();
+}
+
+refresh();
+setInterval(refresh, 5000);
+</script>
+</body>
+</html>
+`
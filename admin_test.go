@@ -0,0 +1,181 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func adminRequest(r *Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.SetBasicAuth("ops", "secret")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminRequiresAuth(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Admin without authentication did not panic")
+		}
+	}()
+	Admin(New().Group("/admin"), AdminConfig{})
+}
+
+func TestAdmin(t *testing.T) {
+	r := New()
+	r.GET("/products", func(c *Context) {})
+	hub := NewWebSocketHub()
+	defer hub.Close()
+	admin := Admin(r.Group("/admin"), AdminConfig{
+		Accounts: Accounts{"ops": "secret"},
+		Checks: map[string]func(context.Context) error{
+			"db":    func(ctx context.Context) error { return nil },
+			"redis": func(ctx context.Context) error { return errors.New("connection refused") },
+		},
+		Stats: map[string]func() any{"answer": func() any { return 42 }},
+		Hubs:  map[string]*WebSocketHub{"kitchen": hub},
+	})
+	admin.GET("/extra", func(c *Context) { c.String(http.StatusOK, "extra") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/api/routes", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want 401", w.Code)
+	}
+	if w := adminRequest(r, "GET", "/admin/extra", ""); w.Body.String() != "extra" {
+		t.Errorf("extra endpoint = %d %q", w.Code, w.Body)
+	}
+
+	w = adminRequest(r, "GET", "/admin", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goTap admin") {
+		t.Errorf("page = %d", w.Code)
+	}
+
+	var routes RoutesInfo
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/routes", "").Body.Bytes(), &routes)
+	if len(routes) != 1 || routes[0].Path != "/products" {
+		t.Errorf("routes = %+v, want only /products", routes)
+	}
+
+	w = adminRequest(r, "GET", "/admin/api/health", "")
+	var health struct {
+		Healthy bool         `json:"healthy"`
+		Checks  []AdminCheck `json:"checks"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &health)
+	if w.Code != http.StatusServiceUnavailable || health.Healthy || len(health.Checks) != 2 {
+		t.Fatalf("health = %d %s", w.Code, w.Body)
+	}
+	if !health.Checks[0].Healthy || health.Checks[1].Name != "redis" || health.Checks[1].Error != "connection refused" {
+		t.Errorf("checks = %+v", health.Checks)
+	}
+
+	var metrics struct {
+		Runtime RuntimeStats   `json:"runtime"`
+		Stats   map[string]int `json:"stats"`
+	}
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/metrics", "").Body.Bytes(), &metrics)
+	if metrics.Runtime.Goroutines == 0 || metrics.Stats["answer"] != 42 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+
+	var hubs map[string]HubStats
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/websockets", "").Body.Bytes(), &hubs)
+	if _, ok := hubs["kitchen"]; !ok {
+		t.Errorf("websockets = %+v", hubs)
+	}
+
+	if w := adminRequest(r, "GET", "/admin/api/shadowdb", ""); w.Code != http.StatusNotFound {
+		t.Errorf("shadowdb without ShadowDB = %d, want 404", w.Code)
+	}
+}
+
+func TestAdminRateLimits(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	r := New()
+	r.POST("/login", RateLimiterWithConfig(RateLimiterConfig{Max: 1, Window: time.Minute, Store: store}), func(c *Context) {})
+	Admin(r.Group("/admin"), AdminConfig{
+		Accounts:     Accounts{"ops": "secret"},
+		RateLimiters: map[string]RateLimiterStore{"login": store, "shared": NewKVRateLimiterStore(NewMemoryKVStore())},
+	})
+
+	login := func() int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "10.0.0.7:1234"
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	login()
+	if code := login(); code != http.StatusTooManyRequests {
+		t.Fatalf("second login = %d, want 429", code)
+	}
+
+	var limits map[string][]RateLimitEntry
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/ratelimits", "").Body.Bytes(), &limits)
+	if entries := limits["login"]; len(entries) != 1 || entries[0].Key != "10.0.0.7" || entries[0].Count != 2 {
+		t.Errorf("login entries = %+v", entries)
+	}
+	if entries, ok := limits["shared"]; !ok || entries != nil {
+		t.Errorf("shared entries = %+v, want null", entries)
+	}
+
+	if w := adminRequest(r, "POST", "/admin/api/ratelimits/login/reset", `{"key":"10.0.0.7"}`); w.Code != http.StatusNoContent {
+		t.Fatalf("reset = %d %s", w.Code, w.Body)
+	}
+	if code := login(); code != http.StatusOK {
+		t.Errorf("login after reset = %d, want 200", code)
+	}
+	if w := adminRequest(r, "POST", "/admin/api/ratelimits/nope/reset", `{"key":"x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown limiter = %d, want 404", w.Code)
+	}
+}
+
+func TestAdminFlags(t *testing.T) {
+	flags := &KVFlags{Store: NewMemoryKVStore()}
+	r := New()
+	Admin(r.Group("/admin"), AdminConfig{
+		Middleware: []HandlerFunc{func(c *Context) { c.Next() }},
+		Flags:      flags,
+		FlagNames:  []string{"new-checkout"},
+	})
+
+	if w := adminRequest(r, "PUT", "/admin/api/flags/new-checkout", `{"enabled":true}`); w.Code != http.StatusNoContent {
+		t.Fatalf("set flag = %d %s", w.Code, w.Body)
+	}
+	if !flags.Enabled(context.Background(), "new-checkout", false) {
+		t.Error("flag not switched on")
+	}
+	var list []AdminFlag
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/flags", "").Body.Bytes(), &list)
+	if len(list) != 1 || !list[0].Enabled || !list[0].Writable {
+		t.Errorf("flags = %+v", list)
+	}
+
+	r = New()
+	Admin(r.Group("/admin"), AdminConfig{
+		Accounts: Accounts{"ops": "secret"},
+		Flags:    StaticFlags{"b": true, "a": false},
+	})
+	json.Unmarshal(adminRequest(r, "GET", "/admin/api/flags", "").Body.Bytes(), &list)
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" || !list[1].Enabled || list[1].Writable {
+		t.Errorf("static flags = %+v", list)
+	}
+	if w := adminRequest(r, "PUT", "/admin/api/flags/a", `{"enabled":true}`); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("set static flag = %d, want 405", w.Code)
+	}
+}
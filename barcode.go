@@ -0,0 +1,248 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/codabar"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/code93"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/boombuler/barcode/qr"
+)
+
+// BarcodeFormat is a barcode symbology rendered by Context.Barcode
+type BarcodeFormat string
+
+// Barcode formats
+const (
+	Code128    BarcodeFormat = "code128"
+	Code39     BarcodeFormat = "code39"
+	Code93     BarcodeFormat = "code93"
+	Codabar    BarcodeFormat = "codabar"
+	DataMatrix BarcodeFormat = "datamatrix"
+	PDF417     BarcodeFormat = "pdf417"
+
+	// EAN encodes EAN-8 or EAN-13 by the number of digits; the check digit
+	// is added if missing
+	EAN BarcodeFormat = "ean"
+)
+
+const (
+	// barcodeModule is the size of a barcode module in pixels
+	barcodeModule = 2
+
+	// barcodeHeight is the height of linear barcodes in pixels
+	barcodeHeight = 80
+
+	// barcodeCacheControl lets clients and CDNs keep rendered codes, which
+	// never change for the same content
+	barcodeCacheControl = "public, max-age=86400"
+)
+
+// QRCode renders content as a QR code of size by size pixels, as PNG or,
+// if the Accept header prefers it, SVG. Responses carry an ETag and
+// caching headers:
+//
+//	r.GET("/pay/:id/qr", func(c *goTap.Context) {
+//	    c.QRCode(200, paymentURL(c.Param("id")), 256)
+//	})
+//
+// Content that doesn't fit a QR code gets 400.
+func (c *Context) QRCode(code int, content string, size int) {
+	qrCode, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		c.barcodeError(err)
+		return
+	}
+	c.renderBarcode(code, qrCode, "qr:"+content, size, size)
+}
+
+// Barcode renders content in format, e.g. Code128 or EAN, as PNG or, if
+// the Accept header prefers it, SVG, for product labels and shelf tags:
+//
+//	r.GET("/products/:sku/barcode", func(c *goTap.Context) {
+//	    c.Barcode(200, goTap.Code128, c.Param("sku"))
+//	})
+//
+// Linear barcodes are 2 pixels per bar and 80 pixels high. Content the
+// format can't encode gets 400.
+func (c *Context) Barcode(code int, format BarcodeFormat, content string) {
+	bc, err := encodeBarcode(format, content)
+	if err != nil {
+		c.barcodeError(err)
+		return
+	}
+	c.renderBarcode(code, bc, string(format)+":"+content, 0, 0)
+}
+
+func encodeBarcode(format BarcodeFormat, content string) (barcode.Barcode, error) {
+	switch format {
+	case Code128:
+		return code128.Encode(content)
+	case Code39:
+		return code39.Encode(content, false, true)
+	case Code93:
+		return code93.Encode(content, true, true)
+	case Codabar:
+		return codabar.Encode(content)
+	case DataMatrix:
+		return datamatrix.Encode(content)
+	case PDF417:
+		return pdf417.Encode(content, 2)
+	case EAN:
+		return ean.Encode(content)
+	}
+	return nil, fmt.Errorf("unsupported barcode format: %s", format)
+}
+
+func (c *Context) barcodeError(err error) {
+	c.JSON(http.StatusBadRequest, H{
+		"error":   "Bad Request",
+		"message": "Cannot encode barcode: " + err.Error(),
+	})
+	c.Abort()
+}
+
+// renderBarcode writes bc as PNG or SVG of at least width by height pixels
+// with a quiet zone, answering 304 to a matching If-None-Match
+func (c *Context) renderBarcode(code int, bc barcode.Barcode, key string, width, height int) {
+	contentType := c.NegotiateFormat("image/png", "image/svg+xml")
+	if contentType == "" {
+		contentType = "image/png"
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%d", contentType, key, width, height)))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", barcodeCacheControl)
+	header.Add("Vary", "Accept")
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	layout := newBarcodeLayout(bc, width, height)
+	if contentType == "image/svg+xml" {
+		c.Data(code, "image/svg+xml", layout.svg())
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, layout.image()); err != nil {
+		c.Error(err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(code, "image/png", buf.Bytes())
+}
+
+// barcodeLayout places the modules of a barcode in an image with a quiet
+// zone around it
+type barcodeLayout struct {
+	code          barcode.Barcode
+	cols, rows    int
+	width, height int
+	moduleW       int
+	moduleH       int
+	offsetX       int
+	offsetY       int
+}
+
+func newBarcodeLayout(bc barcode.Barcode, width, height int) *barcodeLayout {
+	bounds := bc.Bounds()
+	l := &barcodeLayout{code: bc, cols: bounds.Dx(), rows: bounds.Dy()}
+
+	if bc.Metadata().Dimensions == 1 {
+		// Linear codes need 10 modules of quiet zone on each side
+		l.rows = 1
+		l.moduleW = barcodeModule
+		if width > 0 {
+			l.moduleW = max(1, width/(l.cols+20))
+		}
+		l.moduleH = barcodeHeight
+		if height > 0 {
+			l.moduleH = height
+		}
+		l.width = max(width, (l.cols+20)*l.moduleW)
+		l.height = l.moduleH
+	} else {
+		// Matrix codes need 4 modules of quiet zone around them
+		module := barcodeModule * 2
+		if width > 0 {
+			module = max(1, min(width/(l.cols+8), height/(l.rows+8)))
+		}
+		l.moduleW, l.moduleH = module, module
+		l.width = max(width, (l.cols+8)*module)
+		l.height = max(height, (l.rows+8)*module)
+	}
+	l.offsetX = (l.width - l.cols*l.moduleW) / 2
+	l.offsetY = (l.height - l.rows*l.moduleH) / 2
+	return l
+}
+
+// dark reports whether the module at col, row is dark
+func (l *barcodeLayout) dark(col, row int) bool {
+	bounds := l.code.Bounds()
+	r, _, _, _ := l.code.At(bounds.Min.X+col, bounds.Min.Y+row).RGBA()
+	return r == 0
+}
+
+// runs calls fn for every horizontal run of dark modules
+func (l *barcodeLayout) runs(fn func(col, row, length int)) {
+	for row := 0; row < l.rows; row++ {
+		for col := 0; col < l.cols; col++ {
+			if !l.dark(col, row) {
+				continue
+			}
+			length := 1
+			for col+length < l.cols && l.dark(col+length, row) {
+				length++
+			}
+			fn(col, row, length)
+			col += length - 1
+		}
+	}
+}
+
+func (l *barcodeLayout) image() image.Image {
+	img := image.NewPaletted(image.Rect(0, 0, l.width, l.height), color.Palette{color.White, color.Black})
+	l.runs(func(col, row, length int) {
+		x0, y0 := l.offsetX+col*l.moduleW, l.offsetY+row*l.moduleH
+		for y := y0; y < y0+l.moduleH; y++ {
+			for x := x0; x < x0+length*l.moduleW; x++ {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	})
+	return img
+}
+
+func (l *barcodeLayout) svg() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, l.width, l.height)
+	l.runs(func(col, row, length int) {
+		fmt.Fprintf(&buf, "M%d %dh%dv%dh-%dz", l.offsetX+col*l.moduleW, l.offsetY+row*l.moduleH,
+			length*l.moduleW, l.moduleH, length*l.moduleW)
+	})
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQRCode(t *testing.T) {
+	r := New()
+	r.GET("/qr", func(c *Context) {
+		c.QRCode(http.StatusOK, c.Query("data"), 256)
+	})
+	request := func(accept, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/qr?data=https://pay.example.com/i/42", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || w.Header().Get("Cache-Control") != barcodeCacheControl {
+		t.Fatalf("Unexpected response %d %v", w.Code, w.Header())
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 256 || img.Bounds().Dy() != 256 {
+		t.Errorf("Expected 256x256, got %v", img.Bounds())
+	}
+	// The quiet zone is light, the finder pattern behind it dark
+	if r, _, _, _ := img.At(2, 2).RGBA(); r == 0 {
+		t.Error("Expected a light quiet zone")
+	}
+	if r, _, _, _ := img.At(128, 128).RGBA(); r != 0 && r != 0xffff {
+		t.Error("Expected black and white modules")
+	}
+
+	etag := w.Header().Get("ETag")
+	if w := request("", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	w = request("image/svg+xml", "")
+	if w.Header().Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(w.Body.String(), "<svg") || !strings.Contains(w.Body.String(), `width="256"`) {
+		t.Errorf("Unexpected SVG response %s %s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("Expected SVG and PNG to have different ETags")
+	}
+}
+
+func TestBarcode(t *testing.T) {
+	r := New()
+	r.GET("/barcode/:format/:content", func(c *Context) {
+		c.Barcode(http.StatusOK, BarcodeFormat(c.Param("format")), c.Param("content"))
+	})
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := request("/barcode/code128/SKU-12345")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d", w.Code)
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dy() != barcodeHeight || img.Bounds().Dx()%barcodeModule != 0 {
+		t.Errorf("Unexpected size %v", img.Bounds())
+	}
+	// Quiet zone, then the start bar
+	if r, _, _, _ := img.At(0, 40).RGBA(); r == 0 {
+		t.Error("Expected a light quiet zone")
+	}
+	if r, _, _, _ := img.At(20*barcodeModule/2, 40).RGBA(); r != 0 {
+		t.Error("Expected the start bar after the quiet zone")
+	}
+
+	for _, path := range []string{"/barcode/ean/5901234123457", "/barcode/ean/590123412345", "/barcode/datamatrix/LOT-7", "/barcode/pdf417/label", "/barcode/code39/ABC", "/barcode/code93/ABC", "/barcode/codabar/A123B"} {
+		if w := request(path); w.Code != http.StatusOK {
+			t.Errorf("Expected %s to render, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	for _, path := range []string{"/barcode/ean/12ab", "/barcode/unknown/x"} {
+		if w := request(path); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected %s to be rejected, got %d", path, w.Code)
+		}
+	}
+}
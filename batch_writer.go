@@ -0,0 +1,239 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what a BatchWriter does when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards the new item
+	OverflowDrop OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued item to make room
+	OverflowDropOldest
+	// OverflowBlock waits for room, applying backpressure to the caller
+	OverflowBlock
+	// OverflowDisconnect closes a slow WebSocket or SSE client whose send
+	// queue is full; BatchWriter treats it as OverflowDrop
+	OverflowDisconnect
+)
+
+// BatchWriterConfig holds configuration for a BatchWriter
+type BatchWriterConfig struct {
+	// Name identifies the writer in logs
+	// Default: "batch writer"
+	Name string
+
+	// Workers is the number of goroutines writing batches
+	// Default: 1
+	Workers int
+
+	// QueueSize bounds the number of items waiting to be written
+	// Default: 10000
+	QueueSize int
+
+	// BatchSize is the maximum number of items per write
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is the longest an item waits for its batch to fill
+	// Default: 1 second
+	FlushInterval time.Duration
+
+	// WriteTimeout bounds each batch write
+	// Default: 10 seconds
+	WriteTimeout time.Duration
+
+	// Overflow decides what happens when the queue is full
+	// Default: OverflowDrop
+	Overflow OverflowPolicy
+
+	// OnError is called when a batch write fails; the batch is discarded
+	// Default: logs a warning
+	OnError func(err error, items int)
+}
+
+// BatchWriterStats reports the activity of a BatchWriter
+type BatchWriterStats struct {
+	Written int64 `json:"written"`
+	Failed  int64 `json:"failed"`
+	Dropped int64 `json:"dropped"`
+	Queued  int   `json:"queued"`
+}
+
+// BatchWriter is a bounded worker pool that writes items in batches, for
+// fire-and-forget writes such as audit logs and analytics that should not
+// delay responses. Close flushes queued items.
+type BatchWriter[T any] struct {
+	config BatchWriterConfig
+	write  func(ctx context.Context, batch []T) error
+	queue  chan T
+
+	// mu guards closed; Add holds it for reading so Close never closes
+	// the queue under a sender
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	written, failed, dropped atomic.Int64
+}
+
+// NewBatchWriter starts a batch writer calling write with up to BatchSize
+// items at a time
+func NewBatchWriter[T any](write func(ctx context.Context, batch []T) error, config BatchWriterConfig) *BatchWriter[T] {
+	if config.Name == "" {
+		config.Name = "batch writer"
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	if config.OnError == nil {
+		name := config.Name
+		config.OnError = func(err error, items int) {
+			debugPrint("[WARNING] %s failed to write %d items: %v", name, items, err)
+		}
+	}
+
+	w := &BatchWriter[T]{
+		config: config,
+		write:  write,
+		queue:  make(chan T, config.QueueSize),
+	}
+	for i := 0; i < config.Workers; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+	return w
+}
+
+// Add queues an item, applying the overflow policy when the queue is full.
+// It reports whether the item was queued.
+func (w *BatchWriter[T]) Add(item T) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.dropped.Add(1)
+		return false
+	}
+
+	switch w.config.Overflow {
+	case OverflowBlock:
+		w.queue <- item
+		return true
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- item:
+				return true
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.queue <- item:
+			return true
+		default:
+			w.dropped.Add(1)
+			return false
+		}
+	}
+}
+
+// work collects items into batches until the queue is closed
+func (w *BatchWriter[T]) work() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, w.config.BatchSize)
+	for {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) == w.config.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *BatchWriter[T]) flush(batch []T) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.WriteTimeout)
+	defer cancel()
+
+	if err := w.write(ctx, batch); err != nil {
+		w.failed.Add(int64(len(batch)))
+		w.config.OnError(err, len(batch))
+		return
+	}
+	w.written.Add(int64(len(batch)))
+}
+
+// Close stops accepting items and waits until queued items are written
+// or ctx is done
+func (w *BatchWriter[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns writer counters
+func (w *BatchWriter[T]) Stats() BatchWriterStats {
+	return BatchWriterStats{
+		Written: w.written.Load(),
+		Failed:  w.failed.Load(),
+		Dropped: w.dropped.Load(),
+		Queued:  len(w.queue),
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchWriterBatchesAndFlushesOnClose(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	w := NewBatchWriter(func(ctx context.Context, batch []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, append([]int(nil), batch...))
+		return nil
+	}, BatchWriterConfig{BatchSize: 3, FlushInterval: time.Hour})
+
+	for i := 1; i <= 7; i++ {
+		w.Add(i)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[0]) != 3 || len(batches[2]) != 1 || batches[2][0] != 7 {
+		t.Errorf("Unexpected batches %v", batches)
+	}
+	if w.Add(8) {
+		t.Error("Expected Add after Close to be rejected")
+	}
+	if stats := w.Stats(); stats.Written != 7 || stats.Dropped != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestBatchWriterFlushInterval(t *testing.T) {
+	written := make(chan []string, 1)
+	w := NewBatchWriter(func(ctx context.Context, batch []string) error {
+		written <- append([]string(nil), batch...)
+		return nil
+	}, BatchWriterConfig{FlushInterval: 10 * time.Millisecond})
+	defer w.Close(context.Background())
+
+	w.Add("a")
+	select {
+	case batch := <-written:
+		if len(batch) != 1 || batch[0] != "a" {
+			t.Errorf("Unexpected batch %v", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected partial batch to be flushed after the interval")
+	}
+}
+
+func TestBatchWriterOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{OverflowDrop, "1,2,"},
+		{OverflowDropOldest, "3,4,"},
+	} {
+		release := make(chan struct{})
+		var got strings.Builder
+		w := NewBatchWriter(func(ctx context.Context, batch []string) error {
+			<-release
+			for _, item := range batch {
+				got.WriteString(item + ",")
+			}
+			return nil
+		}, BatchWriterConfig{QueueSize: 2, BatchSize: 1, Overflow: tc.policy})
+
+		// The worker holds "0" while the queue fills up
+		w.Add("0")
+		time.Sleep(10 * time.Millisecond)
+		for _, item := range []string{"1", "2", "3", "4"} {
+			w.Add(item)
+		}
+		close(release)
+		w.Close(context.Background())
+
+		if got.String() != "0,"+tc.want {
+			t.Errorf("Policy %d: expected 0,%s got %s", tc.policy, tc.want, got.String())
+		}
+		if stats := w.Stats(); stats.Dropped != 2 {
+			t.Errorf("Policy %d: expected 2 dropped, got %+v", tc.policy, stats)
+		}
+	}
+}
+
+func TestBatchWriterBlockAndErrors(t *testing.T) {
+	var failed int
+	w := NewBatchWriter(func(ctx context.Context, batch []int) error {
+		time.Sleep(time.Millisecond)
+		return errors.New("disk full")
+	}, BatchWriterConfig{QueueSize: 1, BatchSize: 1, Overflow: OverflowBlock, OnError: func(err error, items int) {
+		failed += items
+	}})
+	for i := 0; i < 5; i++ {
+		if !w.Add(i) {
+			t.Fatal("Expected blocking Add to queue the item")
+		}
+	}
+	w.Close(context.Background())
+	if stats := w.Stats(); failed != 5 || stats.Failed != 5 || stats.Dropped != 0 {
+		t.Errorf("Unexpected failures %d %+v", failed, stats)
+	}
+}
+
+// slowWriter delays each write
+type slowWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(20 * time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func TestAccessLogWriter(t *testing.T) {
+	out := &slowWriter{}
+	accessLog := NewAccessLogWriter(out, BatchWriterConfig{FlushInterval: time.Hour})
+
+	r := New()
+	r.Use(LoggerWithConfig(LoggerConfig{Output: accessLog}))
+	r.GET("/receipts/:id", func(c *Context) { c.Status(200) })
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/receipts/1", nil))
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("Expected requests not to wait for the log file, took %v", elapsed)
+	}
+
+	accessLog.Close(context.Background())
+	if lines := strings.Count(out.buf.String(), "/receipts/1"); lines != 20 {
+		t.Errorf("Expected 20 log lines, got %d", lines)
+	}
+	if out.writes >= 20 {
+		t.Errorf("Expected lines to be written in batches, got %d writes", out.writes)
+	}
+}
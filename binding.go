@@ -1,7 +1,8 @@
 package goTap
 
 import (
-	"encoding/json"
+	"bytes"
+	"encoding"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Binding describes the interface which needs to be implemented for binding request data
@@ -62,8 +64,12 @@ func GetValidator() Validator {
 	return defaultValidator
 }
 
-// validate validates the struct using the default validator
+// validate sanitizes the struct by its "sanitize" tags, see Sanitize, and
+// validates it using the default validator
 func validate(obj interface{}) error {
+	if err := Sanitize(obj); err != nil {
+		return err
+	}
 	if defaultValidator == nil {
 		return nil
 	}
@@ -90,8 +96,10 @@ func (jsonBinding) BindBody(body io.Reader, obj interface{}) error {
 }
 
 func decodeJSON(r io.Reader, obj interface{}) error {
-	decoder := json.NewDecoder(r)
-	if err := decoder.Decode(obj); err != nil {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	if err := GetJSONCodec().NewDecoder(r).Decode(obj); err != nil {
 		return err
 	}
 	return validate(obj)
@@ -117,6 +125,9 @@ func (xmlBinding) BindBody(body io.Reader, obj interface{}) error {
 }
 
 func decodeXML(r io.Reader, obj interface{}) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	decoder := xml.NewDecoder(r)
 	if err := decoder.Decode(obj); err != nil {
 		return err
@@ -136,6 +147,9 @@ func (formBinding) Bind(req *http.Request, obj interface{}) error {
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapForm(obj, req.Form); err != nil {
 		return err
 	}
@@ -152,6 +166,9 @@ func (queryBinding) Name() string {
 
 func (queryBinding) Bind(req *http.Request, obj interface{}) error {
 	values := req.URL.Query()
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapForm(obj, values); err != nil {
 		return err
 	}
@@ -170,6 +187,9 @@ func (formPostBinding) Bind(req *http.Request, obj interface{}) error {
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapForm(obj, req.PostForm); err != nil {
 		return err
 	}
@@ -188,6 +208,9 @@ func (formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
 	if err := req.ParseMultipartForm(32 << 20); err != nil { // 32MB max memory
 		return err
 	}
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapForm(obj, req.MultipartForm.Value); err != nil {
 		return err
 	}
@@ -203,6 +226,9 @@ func (headerBinding) Name() string {
 }
 
 func (headerBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapHeader(obj, req.Header); err != nil {
 		return err
 	}
@@ -218,6 +244,9 @@ func (uriBinding) Name() string {
 }
 
 func (uriBinding) BindUri(m map[string][]string, obj interface{}) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
 	if err := mapUri(obj, m); err != nil {
 		return err
 	}
@@ -241,6 +270,73 @@ func mapUri(ptr interface{}, m map[string][]string) error {
 	return mappingByPtr(ptr, formSource(m), "uri")
 }
 
+// noDefaults caches the struct types without "default" tags
+var noDefaults sync.Map
+
+// applyDefaults sets the fields of the struct ptr points to that have a
+// "default" tag, including fields of nested structs. Bindings apply them
+// before reading the request, so defaults remain only for fields the
+// request omits; a field sent empty stays empty:
+//
+//	type ListQuery struct {
+//	    Page   int      `form:"page" default:"1"`
+//	    Status string   `form:"status" default:"pending"`
+//	    Tags   []string `form:"tag" default:"new,sale"`
+//	}
+func applyDefaults(ptr interface{}) error {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := noDefaults.Load(value.Type()); ok {
+		return nil
+	}
+	found, err := setDefaults(value)
+	if err == nil && !found {
+		noDefaults.Store(value.Type(), true)
+	}
+	return err
+}
+
+// setDefaults applies the defaults of value and reports whether it has any
+func setDefaults(value reflect.Value) (bool, error) {
+	found := false
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		structField := value.Field(i)
+		if !structField.CanSet() {
+			continue
+		}
+
+		def, ok := typeField.Tag.Lookup("default")
+		if !ok {
+			if structField.Kind() == reflect.Struct {
+				nested, err := setDefaults(structField)
+				if err != nil {
+					return false, err
+				}
+				found = found || nested
+			}
+			continue
+		}
+		found = true
+
+		values := []string{def}
+		if structField.Kind() == reflect.Slice {
+			values = strings.Split(def, ",")
+		}
+		if err := setField(structField, values); err != nil {
+			return false, fmt.Errorf("invalid default for field '%s': %v", typeField.Name, err)
+		}
+	}
+	return found, nil
+}
+
 type formSource map[string][]string
 
 func (f formSource) TryGet(key string) ([]string, bool) {
@@ -306,6 +402,12 @@ func setField(field reflect.Value, values []string) error {
 	kind := field.Kind()
 	val := values[0]
 
+	if kind != reflect.Ptr && field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(val))
+		}
+	}
+
 	switch kind {
 	case reflect.String:
 		field.SetString(val)
@@ -454,20 +556,117 @@ func (c *Context) ShouldBindWith(obj interface{}, b Binding) error {
 	return b.Bind(c.Request, obj)
 }
 
+// BodyBytesKey is the context key of the request body cached by BodyBytes
+const BodyBytesKey = "gotap.request.body"
+
+// BodyBytes returns the request body, reading it once and caching it in the
+// context. c.Request.Body is replaced with a fresh reader over the cached
+// bytes, so handlers and middleware can keep reading it afterwards.
+func (c *Context) BodyBytes() ([]byte, error) {
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		body := cb.([]byte)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(BodyBytesKey, body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
 // ShouldBindBodyWith is similar to ShouldBindWith but it stores the request
 // body into the context and reuses when called again
 func (c *Context) ShouldBindBodyWith(obj interface{}, bb BindingBody) (err error) {
-	var body []byte
-	if cb, ok := c.Get("gotap.request.body"); ok {
-		body = cb.([]byte)
-	} else {
-		body, err = io.ReadAll(c.Request.Body)
-		if err != nil {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return bb.BindBody(bytes.NewReader(body), obj)
+}
+
+// BindAll is like ShouldBindAll but aborts with 400 when binding fails
+func (c *Context) BindAll(obj interface{}) error {
+	if err := c.ShouldBindAll(obj); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, H{"error": err.Error()})
+		return err
+	}
+	return nil
+}
+
+// ShouldBindAll binds the "header", "form" (query string), body and "uri"
+// tags of obj in one call, then validates it once. Sources are bound in
+// that order and later ones win, so path parameters override the body and
+// the body overrides the query string:
+//
+//	type UpdateItem struct {
+//	    ID     int    `uri:"id" json:"-"`
+//	    DryRun bool   `form:"dry_run" json:"-"`
+//	    Tenant string `header:"X-Tenant-Id" json:"-"`
+//	    Name   string `json:"name" validate:"required"`
+//	}
+//
+//	var req UpdateItem
+//	if err := c.ShouldBindAll(&req); err != nil { ... }
+//
+// Tag fields json:"-" to keep the body from setting them. The body is
+// decoded by Content-Type as JSON, XML or form fields and is cached like
+// BodyBytes, so it can still be read afterwards.
+func (c *Context) ShouldBindAll(obj interface{}) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	if err := mapHeader(obj, c.Request.Header); err != nil {
+		return err
+	}
+	if err := mapForm(obj, c.Request.URL.Query()); err != nil {
+		return err
+	}
+	if err := c.bindBodyFields(obj); err != nil {
+		return err
+	}
+	m := make(map[string][]string, len(c.Params))
+	for _, v := range c.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	if err := mapUri(obj, m); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// bindBodyFields decodes the request body into obj without validating it
+func (c *Context) bindBodyFields(obj interface{}) error {
+	switch c.ContentType() {
+	case "application/x-www-form-urlencoded":
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		return mapForm(obj, c.Request.PostForm)
+	case "multipart/form-data":
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
 			return err
 		}
-		c.Set("gotap.request.body", body)
+		return mapForm(obj, c.Request.MultipartForm.Value)
+	}
+
+	body, err := c.BodyBytes()
+	if err != nil || len(bytes.TrimSpace(body)) == 0 {
+		return err
+	}
+	switch contentType := c.ContentType(); {
+	case contentType == "application/xml" || contentType == "text/xml":
+		return xml.Unmarshal(body, obj)
+	case contentType == "" || contentType == "application/json" || strings.HasSuffix(contentType, "+json"):
+		return GetJSONCodec().Unmarshal(body, obj)
+	default:
+		return fmt.Errorf("unsupported content type %q", contentType)
 	}
-	return bb.BindBody(io.NopCloser(strings.NewReader(string(body))), obj)
 }
 
 // DefaultBinding returns the appropriate Binding instance based on the HTTP method
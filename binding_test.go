@@ -591,6 +591,127 @@ func TestBind(t *testing.T) {
 	}
 }
 
+// ========== Default Value Tests ==========
+
+type ListOrdersQuery struct {
+	Page   int      `form:"page" default:"1"`
+	Status string   `form:"status" default:"pending"`
+	Tags   []string `form:"tag" default:"new,sale"`
+	Sort   *string  `form:"sort" default:"desc"`
+}
+
+func TestBindingDefaults(t *testing.T) {
+	var query ListOrdersQuery
+	router := New()
+	router.GET("/orders", func(c *Context) {
+		query = ListOrdersQuery{}
+		if err := c.ShouldBindQuery(&query); err != nil {
+			t.Error(err)
+		}
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+	if query.Page != 1 || query.Status != "pending" || strings.Join(query.Tags, ",") != "new,sale" ||
+		query.Sort == nil || *query.Sort != "desc" {
+		t.Errorf("Expected the defaults, got %+v", query)
+	}
+
+	// An empty value is kept, only omitted fields get their default
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders?page=3&status=&tag=vip", nil))
+	if query.Page != 3 || query.Status != "" || strings.Join(query.Tags, ",") != "vip" {
+		t.Errorf("Expected the sent values, got %+v", query)
+	}
+}
+
+func TestBindingDefaultsJSON(t *testing.T) {
+	type Order struct {
+		Status string `json:"status" default:"pending"`
+		Note   string `json:"note" default:"none"`
+		Lines  struct {
+			Max int `json:"max" default:"10"`
+		} `json:"lines"`
+	}
+	var order Order
+	router := New()
+	router.POST("/orders", func(c *Context) {
+		order = Order{}
+		if err := c.ShouldBindJSON(&order); err != nil {
+			t.Error(err)
+		}
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", strings.NewReader(`{"note": ""}`)))
+	if order.Status != "pending" || order.Note != "" || order.Lines.Max != 10 {
+		t.Errorf("Unexpected defaults %+v", order)
+	}
+
+	type Bad struct {
+		Page int `form:"page" default:"first"`
+	}
+	if err := applyDefaults(&Bad{}); err == nil {
+		t.Error("Expected an error for an invalid default")
+	}
+}
+
+// ========== Multi-source Binding Tests ==========
+
+type UpdateItemRequest struct {
+	ID     int    `uri:"id" json:"id"`
+	Tenant string `header:"X-Tenant-Id" json:"-" validate:"required"`
+	DryRun bool   `form:"dry_run" json:"dry_run"`
+	Name   string `form:"name" json:"name" validate:"required,min=3"`
+}
+
+func TestShouldBindAll(t *testing.T) {
+	var req UpdateItemRequest
+	var bindErr error
+	router := New()
+	router.PUT("/items/:id", func(c *Context) {
+		req = UpdateItemRequest{}
+		bindErr = c.ShouldBindAll(&req)
+	})
+	put := func(target, contentType, body string, header map[string]string) {
+		r := httptest.NewRequest("PUT", target, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	tenant := map[string]string{"X-Tenant-Id": "cafe-1"}
+
+	// The path wins over the body, the body over the query string
+	put("/items/7?dry_run=true&name=query", "application/json", `{"id": 99, "name": "espresso"}`, tenant)
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if req != (UpdateItemRequest{ID: 7, Tenant: "cafe-1", DryRun: true, Name: "espresso"}) {
+		t.Errorf("Unexpected binding %+v", req)
+	}
+
+	// The query string fills fields the body leaves out
+	put("/items/7?name=latte", "application/json", `{}`, tenant)
+	if bindErr != nil || req.Name != "latte" {
+		t.Errorf("Expected the query name, got %+v %v", req, bindErr)
+	}
+
+	put("/items/7", "application/x-www-form-urlencoded", "name=mocha&dry_run=1", tenant)
+	if bindErr != nil || req.Name != "mocha" || !req.DryRun {
+		t.Errorf("Expected the form fields, got %+v %v", req, bindErr)
+	}
+
+	// Validation runs once over all sources
+	put("/items/7", "application/json", `{"name": "espresso"}`, nil)
+	if bindErr == nil {
+		t.Error("Expected a validation error for the missing header")
+	}
+	put("/items/7", "text/csv", "name\nespresso", tenant)
+	if bindErr == nil {
+		t.Error("Expected an error for an unsupported body")
+	}
+}
+
 // ========== Benchmarks ==========
 
 func BenchmarkBindJSON(b *testing.B) {
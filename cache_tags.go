@@ -0,0 +1,208 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CacheTagsKey is the context key holding the RedisCache tag state
+const CacheTagsKey = "gotap.cache_tags"
+
+// cacheTags tracks the tags of a response cached by RedisCache. Every tag
+// has a version counter in the store; InvalidateTags bumps it, which turns
+// all entries cached with the previous version into misses.
+type cacheTags struct {
+	store    KVStore
+	prefix   string
+	versions map[string]int64
+}
+
+func (t *cacheTags) key(tag string) string {
+	return t.prefix + "tag:" + tag
+}
+
+// version returns the current version of tag, 0 if it was never invalidated
+func (t *cacheTags) version(ctx context.Context, tag string) (int64, error) {
+	value, err := t.store.Get(ctx, t.key(tag))
+	if errors.Is(err, ErrKVNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+// fresh reports whether none of the tags were invalidated since versions
+// were recorded
+func (t *cacheTags) fresh(ctx context.Context, versions map[string]int64) bool {
+	for tag, v := range versions {
+		current, err := t.version(ctx, tag)
+		if err != nil || current != v {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheTags returns a middleware that tags the responses RedisCache caches
+// for the route. "{name}" is replaced by the route parameter:
+//
+//	r.GET("/products", goTap.CacheTags("products"), listProducts)
+//	r.GET("/products/:id", goTap.CacheTags("products", "product:{id}"), getProduct)
+func CacheTags(tags ...string) HandlerFunc {
+	return func(c *Context) {
+		expanded := make([]string, len(tags))
+		for i, tag := range tags {
+			expanded[i] = expandRouteParams(c, tag)
+		}
+		c.AddCacheTags(expanded...)
+		c.Next()
+	}
+}
+
+// AddCacheTags tags the response RedisCache caches, e.g. for the records a
+// handler loaded. It does nothing without RedisCache.
+func (c *Context) AddCacheTags(tags ...string) {
+	value, ok := c.Get(CacheTagsKey)
+	if !ok {
+		return
+	}
+	state := value.(*cacheTags)
+	if state.versions == nil {
+		state.versions = make(map[string]int64, len(tags))
+	}
+	for _, tag := range tags {
+		if _, ok := state.versions[tag]; ok {
+			continue
+		}
+		// The version is read before the response is built, so an
+		// invalidation while the handler runs isn't lost
+		v, err := state.version(c.Request.Context(), tag)
+		if err != nil {
+			debugPrint("[WARNING] Cache tag %s: %v", tag, err)
+			v = -1
+		}
+		state.versions[tag] = v
+	}
+}
+
+// InvalidateTags expires every response cached with any of the tags, e.g.
+// after a write:
+//
+//	r.PUT("/products/:id", func(c *goTap.Context) {
+//	    // ... update the product
+//	    goTap.InvalidateTags(c, "products", "product:"+c.Param("id"))
+//	})
+//
+// It uses the store of RedisCache, else Engine.KVStore with the "cache:"
+// prefix.
+func InvalidateTags(c *Context, tags ...string) error {
+	var state *cacheTags
+	if value, ok := c.Get(CacheTagsKey); ok {
+		state = value.(*cacheTags)
+	} else if store := c.kvStore(); store != nil {
+		state = &cacheTags{store: store, prefix: "cache:"}
+	} else {
+		return errors.New("goTap: no cache store for InvalidateTags")
+	}
+	for _, tag := range tags {
+		if _, _, err := state.store.Increment(c.Request.Context(), state.key(tag), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheEntry is the header stored before a cached body
+type cacheEntry struct {
+	Status      int              `json:"s"`
+	ContentType string           `json:"ct,omitempty"`
+	Tags        map[string]int64 `json:"t,omitempty"`
+	Gzip        bool             `json:"z,omitempty"`
+}
+
+// encodeCacheEntry stores the header as a JSON line followed by the body,
+// gzipped when compress is set and the body has at least minSize bytes
+func encodeCacheEntry(entry cacheEntry, body []byte, compress bool, minSize int) []byte {
+	if compress && len(body) >= minSize {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err == nil && zw.Close() == nil {
+			body = buf.Bytes()
+			entry.Gzip = true
+		}
+	}
+	header, _ := json.Marshal(entry)
+	data := make([]byte, 0, len(header)+1+len(body))
+	data = append(data, header...)
+	data = append(data, '\n')
+	return append(data, body...)
+}
+
+// decodeCacheEntry splits a cached entry into its header and body,
+// reporting false for entries it can't read
+func decodeCacheEntry(data []byte) (cacheEntry, []byte, bool) {
+	var entry cacheEntry
+	header, body, ok := bytes.Cut(data, []byte{'\n'})
+	if !ok || json.Unmarshal(header, &entry) != nil || entry.Status == 0 {
+		return entry, nil, false
+	}
+	if entry.Gzip {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return entry, nil, false
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return entry, nil, false
+		}
+	}
+	return entry, body, true
+}
+
+// CacheRemember returns the value cached under key, or loads it, caches it
+// for ttl and returns it. Values are stored as JSON:
+//
+//	product, err := goTap.CacheRemember(ctx, store, "product:42", time.Hour, func() (*Product, error) {
+//	    return repo.Find(ctx, 42)
+//	})
+func CacheRemember[T any](ctx context.Context, store KVStore, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	var value T
+	if data, err := store.Get(ctx, key); err == nil && json.Unmarshal(data, &value) == nil {
+		return value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	return value, CacheWrite(ctx, store, key, value, ttl)
+}
+
+// CacheWrite stores value under key as JSON, e.g. to write through after
+// saving a record so the next CacheRemember doesn't reload it
+func CacheWrite(ctx context.Context, store KVStore, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, key, data, ttl)
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
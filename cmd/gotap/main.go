@@ -0,0 +1,216 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Command gotap scaffolds goTap projects.
+//
+//	gotap new [-db postgres|mysql|sqlite] [-gotap path] [-skip-tidy] <module-path> [dir]
+//
+// creates a modular project laid out like examples/modular_auth: an auth
+// module with JWT login and roles, configuration loaded from the
+// environment, versioned migrations, a Dockerfile and docker-compose.yml,
+// and tests. New features are added as further directories under modules/.
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/jaswant99k/gotap"
+)
+
+//go:embed all:templates
+var templates embed.FS
+
+const usage = `Usage:
+
+	gotap new [flags] <module-path> [dir]
+
+Creates a modular goTap project in dir, which defaults to the last element
+of module-path.
+
+Flags:
+`
+
+// project is the data passed to the templates
+type project struct {
+	Module       string
+	Name         string
+	Database     string
+	DBName       string
+	DSN          string
+	DockerDSN    string
+	GoTapVersion string
+	GoTapPath    string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gotap:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("missing command; see 'gotap help'")
+	}
+	switch args[0] {
+	case "new":
+		return newProject(args[1:], w)
+	case "help", "-h", "-help", "--help":
+		fset, _ := newFlags(w)
+		fset.Usage()
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q; see 'gotap help'", args[0])
+	}
+}
+
+type newOptions struct {
+	database  string
+	goTapPath string
+	skipTidy  bool
+	force     bool
+}
+
+func newFlags(w io.Writer) (*flag.FlagSet, *newOptions) {
+	opts := &newOptions{}
+	fset := flag.NewFlagSet("gotap new", flag.ContinueOnError)
+	fset.SetOutput(w)
+	fset.StringVar(&opts.database, "db", "postgres", "database driver: postgres, mysql or sqlite")
+	fset.StringVar(&opts.goTapPath, "gotap", "", "path to a local goTap checkout to use through a replace directive")
+	fset.BoolVar(&opts.skipTidy, "skip-tidy", false, "don't run 'go mod tidy' in the new project")
+	fset.BoolVar(&opts.force, "force", false, "write into dir even if it isn't empty")
+	fset.Usage = func() {
+		fmt.Fprint(w, usage)
+		fset.PrintDefaults()
+	}
+	return fset, opts
+}
+
+func newProject(args []string, w io.Writer) error {
+	fset, opts := newFlags(w)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() < 1 || fset.NArg() > 2 {
+		fset.Usage()
+		return errors.New("new takes a module path and an optional directory")
+	}
+
+	p := project{
+		Module:       fset.Arg(0),
+		Name:         path.Base(fset.Arg(0)),
+		Database:     opts.database,
+		GoTapVersion: goTap.Version,
+	}
+	p.DBName = strings.NewReplacer("-", "_", ".", "_").Replace(p.Name)
+	switch p.Database {
+	case "postgres":
+		p.DSN = "host=localhost user=postgres password=postgres dbname=" + p.DBName + " port=5432 sslmode=disable"
+		p.DockerDSN = strings.Replace(p.DSN, "localhost", "db", 1)
+	case "mysql":
+		p.DSN = "root:mysql@tcp(localhost:3306)/" + p.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
+		p.DockerDSN = strings.Replace(p.DSN, "localhost", "db", 1)
+	case "sqlite":
+		p.DSN = p.DBName + ".db"
+		p.DockerDSN = "/data/" + p.DSN
+	default:
+		return fmt.Errorf("unsupported database %q (want postgres, mysql or sqlite)", p.Database)
+	}
+
+	dir := p.Name
+	if fset.NArg() == 2 {
+		dir = fset.Arg(1)
+	}
+	if opts.goTapPath != "" {
+		abs, err := filepath.Abs(opts.goTapPath)
+		if err != nil {
+			return err
+		}
+		p.GoTapPath = filepath.ToSlash(abs)
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !opts.force {
+		return fmt.Errorf("%s is not empty; use -force to write into it", dir)
+	}
+	files, err := generate(dir, p)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Fprintln(w, "  create", filepath.Join(dir, file))
+	}
+
+	if !opts.skipTidy {
+		fmt.Fprintln(w, "Running go mod tidy...")
+		tidy := exec.Command("go", "mod", "tidy")
+		tidy.Dir = dir
+		tidy.Stdout, tidy.Stderr = w, w
+		if err := tidy.Run(); err != nil {
+			return fmt.Errorf("go mod tidy: %w; fix the error and run it in %s", err, dir)
+		}
+	}
+
+	fmt.Fprintf(w, "\nCreated %s in %s. Next steps:\n\n", p.Module, dir)
+	fmt.Fprintf(w, "\tcd %s\n\tcp .env.example .env\n", dir)
+	if opts.skipTidy {
+		fmt.Fprintln(w, "\tgo mod tidy")
+	}
+	fmt.Fprintln(w, "\tgo run ./cmd/server")
+	if p.GoTapPath != "" {
+		fmt.Fprintln(w, "\nThe replace directive in go.mod points outside the project; remove it before building the Docker image.")
+	}
+	return nil
+}
+
+// generate renders the project templates into dir and returns the paths
+// written, relative to dir
+func generate(dir string, p project) ([]string, error) {
+	root, err := fs.Sub(templates, "templates/project")
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	err = fs.WalkDir(root, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		tmpl, err := template.ParseFS(root, name)
+		if err != nil {
+			return err
+		}
+
+		out := strings.TrimSuffix(name, ".tmpl")
+		target := filepath.Join(dir, filepath.FromSlash(out))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(f, p); err != nil {
+			f.Close()
+			return fmt.Errorf("rendering %s: %w", out, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		written = append(written, filepath.FromSlash(out))
+		return nil
+	})
+	return written, err
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewProject(t *testing.T) {
+	for _, db := range []string{"postgres", "mysql", "sqlite"} {
+		t.Run(db, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "pos-api")
+			var out bytes.Buffer
+			if err := run([]string{"new", "-db", db, "-skip-tidy", "example.com/acme/pos-api", dir}, &out); err != nil {
+				t.Fatalf("new: %v\n%s", err, out.String())
+			}
+
+			for _, file := range []string{
+				"go.mod", "Dockerfile", "docker-compose.yml", ".env.example", ".gitignore",
+				"cmd/server/main.go", "config/config.go", "shared/database/connection.go",
+				"migrations/migrations.go", "migrations/0002_seed_permissions.up.sql",
+				"modules/auth/routes.go", "modules/auth/handlers_test.go",
+			} {
+				if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+					t.Errorf("Expected %s: %v", file, err)
+				}
+			}
+
+			// Generated Go files are gofmt-clean
+			filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || !strings.HasSuffix(path, ".go") {
+					return err
+				}
+				src, _ := os.ReadFile(path)
+				formatted, err := format.Source(src)
+				if err != nil {
+					t.Errorf("%s: %v", path, err)
+				} else if !bytes.Equal(src, formatted) {
+					t.Errorf("%s is not gofmt-clean", path)
+				}
+				return nil
+			})
+
+			main, _ := os.ReadFile(filepath.Join(dir, "cmd/server/main.go"))
+			if !strings.Contains(string(main), `"example.com/acme/pos-api/modules/auth"`) {
+				t.Error("Expected imports of the project's modules")
+			}
+			config, _ := os.ReadFile(filepath.Join(dir, "config/config.go"))
+			if !strings.Contains(string(config), `getenv("DB_DRIVER", "`+db+`")`) {
+				t.Errorf("Expected %s as the default driver", db)
+			}
+			compose, _ := os.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+			if hasDB := strings.Contains(string(compose), "\n  db:\n"); hasDB == (db == "sqlite") {
+				t.Errorf("Unexpected database service in docker-compose.yml:\n%s", compose)
+			}
+		})
+	}
+}
+
+func TestNewProjectOptions(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644)
+	var out bytes.Buffer
+	if err := run([]string{"new", "-skip-tidy", "-gotap", "../gotap", "shop", dir}, &out); err == nil {
+		t.Fatal("Expected an error for a non-empty directory")
+	}
+	if err := run([]string{"new", "-skip-tidy", "-gotap", "../gotap", "shop", filepath.Join(dir, "shop")}, &out); err != nil {
+		t.Fatal(err)
+	}
+	mod, _ := os.ReadFile(filepath.Join(dir, "shop", "go.mod"))
+	if !strings.HasPrefix(string(mod), "module shop\n") || !strings.Contains(string(mod), "replace github.com/jaswant99k/gotap => /") {
+		t.Errorf("Unexpected go.mod:\n%s", mod)
+	}
+
+	if err := run([]string{"new", "-db", "oracle", "shop"}, &out); err == nil || !strings.Contains(err.Error(), "unsupported database") {
+		t.Errorf("Expected unsupported database error, got %v", err)
+	}
+	if err := run([]string{"generate"}, &out); err == nil {
+		t.Error("Expected unknown command error")
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"gorm.io/gorm/logger"
+)
+
+// Config configures an Engine, its server and the backends it connects
+// to. It is read from a YAML or JSON file by LoadConfig; every setting can
+// be overridden by the environment variable named in its env tag.
+type Config struct {
+	// Mode is the goTap mode, see SetMode
+	// Default: unchanged
+	Mode string `yaml:"mode" env:"GOTAP_MODE"`
+
+	// Host and Port form the listen address
+	// Default: all interfaces, port 5066
+	Host string `yaml:"host" env:"GOTAP_HOST"`
+	Port int    `yaml:"port" env:"GOTAP_PORT"`
+
+	// TrustedProxies lists the IPs and CIDRs whose forwarding headers
+	// ClientIP trusts, see Engine.SetTrustedProxies
+	// Default: unchanged
+	TrustedProxies []string `yaml:"trusted_proxies" env:"GOTAP_TRUSTED_PROXIES"`
+
+	Server    ServerConfig    `yaml:"server"`
+	TLS       TLSConfig       `yaml:"tls"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Mongo     MongoConfig     `yaml:"mongo"`
+	Database  DatabaseConfig  `yaml:"database"`
+
+	path    string
+	limiter *configLimiter
+}
+
+// ServerConfig holds the http.Server timeouts and limits, see ServerOption
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"GOTAP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"GOTAP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"GOTAP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"GOTAP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"GOTAP_MAX_HEADER_BYTES"`
+	MaxConnections    int           `yaml:"max_connections" env:"GOTAP_MAX_CONNECTIONS"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"GOTAP_SHUTDOWN_TIMEOUT"`
+}
+
+// TLSConfig holds the certificate served by RunConfig. Both files must be
+// set to serve HTTPS.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"GOTAP_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"GOTAP_TLS_KEY_FILE"`
+}
+
+// RateLimitConfig configures a rate limit per client IP for all routes
+type RateLimitConfig struct {
+	// Max requests per Window; 0 disables the limit
+	Max    int           `yaml:"max" env:"GOTAP_RATE_LIMIT_MAX"`
+	Window time.Duration `yaml:"window" env:"GOTAP_RATE_LIMIT_WINDOW"`
+}
+
+// RedisConfig holds the Redis connection, see NewRedisClient
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"GOTAP_REDIS_ADDR"`
+	Password string `yaml:"password" env:"GOTAP_REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"GOTAP_REDIS_DB"`
+}
+
+// MongoConfig holds the MongoDB connection, see NewMongoClient
+type MongoConfig struct {
+	URI      string `yaml:"uri" env:"GOTAP_MONGO_URI"`
+	Database string `yaml:"database" env:"GOTAP_MONGO_DATABASE"`
+}
+
+// DatabaseConfig holds the SQL database connection, see NewGormDB
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" env:"GOTAP_DB_DRIVER"`
+	DSN             string        `yaml:"dsn" env:"GOTAP_DB_DSN"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"GOTAP_DB_MAX_IDLE_CONNS"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"GOTAP_DB_MAX_OPEN_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"GOTAP_DB_CONN_MAX_LIFETIME"`
+}
+
+// LoadConfig reads a YAML or JSON config file and applies environment
+// overrides such as GOTAP_PORT. Without a path only the environment is
+// read.
+//
+//	cfg, err := goTap.LoadConfig("config.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	r := goTap.New()
+//	if err := cfg.Apply(r); err != nil {
+//	    log.Fatal(err)
+//	}
+//	cfg.ReloadOnSIGHUP(r)
+//	// ... routes ...
+//	r.RunConfig(cfg)
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{path: path}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv sets the fields of v whose env tag names a set variable
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("env")
+		if name == "" {
+			if value.Kind() == reflect.Struct {
+				if err := applyEnv(value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setConfigField(value, env); err != nil {
+			return fmt.Errorf("config: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setConfigField(value reflect.Value, s string) error {
+	switch value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	case []string:
+		var list []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		value.Set(reflect.ValueOf(list))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+func (cfg *Config) validate() error {
+	switch cfg.Mode {
+	case "", DebugMode, ReleaseMode, TestMode:
+	default:
+		return fmt.Errorf("config: unknown mode %q", cfg.Mode)
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("config: invalid port %d", cfg.Port)
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return errors.New("config: tls needs both cert_file and key_file")
+	}
+	if cfg.RateLimit.Max < 0 || (cfg.RateLimit.Max > 0 && cfg.RateLimit.Window <= 0) {
+		return errors.New("config: rate_limit needs a positive max and window")
+	}
+	if _, err := parseTrustedProxies(cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// Addr returns the listen address
+func (cfg *Config) Addr() string {
+	port := cfg.Port
+	if port == 0 {
+		port = 5066
+	}
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+}
+
+// Apply configures engine with the mode, trusted proxies and rate limit.
+// The rate limit is added as middleware, so call Apply before
+// registering routes.
+func (cfg *Config) Apply(engine *Engine) error {
+	if cfg.Mode != "" {
+		SetMode(cfg.Mode)
+	}
+	if cfg.TrustedProxies != nil {
+		if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			return err
+		}
+	}
+	if cfg.limiter == nil {
+		cfg.limiter = &configLimiter{}
+		if engine.KVStore != nil {
+			cfg.limiter.store = NewKVRateLimiterStore(engine.KVStore)
+		}
+		engine.Use(cfg.limiter.handle)
+	}
+	cfg.limiter.set(cfg.RateLimit)
+	return nil
+}
+
+// HTTPServer returns an http.Server for engine with the configured
+// address, timeouts and limits. MaxConnections is only enforced by
+// RunConfig. Call Engine.RunStartHooks before serving with it.
+func (cfg *Config) HTTPServer(engine *Engine) *http.Server {
+	srv, _ := engine.newServer(cfg.Addr(), []ServerOption{WithServerConfig(cfg.Server)})
+	return srv
+}
+
+// DBConfig returns the database settings for NewGormDB
+func (cfg *Config) DBConfig() *DBConfig {
+	db := DefaultDBConfig()
+	db.Driver, db.DSN = cfg.Database.Driver, cfg.Database.DSN
+	if cfg.Database.MaxIdleConns > 0 {
+		db.MaxIdleConns = cfg.Database.MaxIdleConns
+	}
+	if cfg.Database.MaxOpenConns > 0 {
+		db.MaxOpenConns = cfg.Database.MaxOpenConns
+	}
+	if cfg.Database.ConnMaxLifetime > 0 {
+		db.ConnMaxLifetime = cfg.Database.ConnMaxLifetime
+	}
+	if Mode() == ReleaseMode {
+		db.LogLevel = logger.Warn
+	}
+	return db
+}
+
+// RunConfig serves engine with the server settings of cfg, over HTTPS
+// when a certificate is configured
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunConfig(cfg *Config) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		return err
+	}
+	srv, maxConns := engine.newServer(cfg.Addr(), []ServerOption{WithServerConfig(cfg.Server)})
+	l, err := listen(srv, maxConns)
+	if err != nil {
+		return err
+	}
+	engine.printRoutes()
+	if cfg.TLS.CertFile != "" {
+		debugPrint("Listening and serving HTTPS on %s\n", srv.Addr)
+		return srv.ServeTLS(l, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	}
+	debugPrint("Listening and serving HTTP on %s\n", srv.Addr)
+	return srv.Serve(l)
+}
+
+// ReloadOnSIGHUP reloads the config file when the process receives
+// SIGHUP. The trusted proxies and rate limit take effect immediately;
+// changes to other settings are reported and need a restart. onReload
+// receives each reloaded config, e.g. to adjust application settings.
+// A config that fails to load is reported and ignored.
+func (cfg *Config) ReloadOnSIGHUP(engine *Engine, onReload ...func(*Config)) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		current := cfg
+		for {
+			select {
+			case <-sig:
+				if next, err := current.reload(engine); err != nil {
+					debugPrint("[WARNING] config reload failed, keeping the current config: %v", err)
+				} else {
+					current = next
+					for _, fn := range onReload {
+						fn(next)
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	engine.addService("config reload", func(ctx context.Context) error {
+		signal.Stop(sig)
+		close(done)
+		return nil
+	})
+}
+
+// reload loads the config again and applies its safe settings to engine
+func (cfg *Config) reload(engine *Engine) (*Config, error) {
+	next, err := LoadConfig(cfg.path)
+	if err != nil {
+		return nil, err
+	}
+	if next.TrustedProxies != nil {
+		if err := engine.SetTrustedProxies(next.TrustedProxies); err != nil {
+			return nil, err
+		}
+	}
+	next.limiter = cfg.limiter
+	if next.limiter != nil {
+		next.limiter.set(next.RateLimit)
+	}
+
+	for name, changed := range map[string]bool{
+		"mode":     next.Mode != cfg.Mode,
+		"address":  next.Addr() != cfg.Addr(),
+		"server":   next.Server != cfg.Server,
+		"tls":      next.TLS != cfg.TLS,
+		"redis":    next.Redis != cfg.Redis,
+		"mongo":    next.Mongo != cfg.Mongo,
+		"database": next.Database != cfg.Database,
+	} {
+		if changed {
+			debugPrint("[WARNING] config: %s changed, restart to apply it", name)
+		}
+	}
+	debugPrint("Config reloaded from %s", cfg.path)
+	return next, nil
+}
+
+// configLimiter is the rate limit middleware of a Config, replaced on
+// reload. The counters are kept across reloads.
+type configLimiter struct {
+	store   RateLimiterStore
+	handler atomic.Pointer[HandlerFunc]
+}
+
+func (l *configLimiter) set(rl RateLimitConfig) {
+	if rl.Max <= 0 {
+		l.handler.Store(nil)
+		return
+	}
+	if l.store == nil {
+		l.store = newInMemoryStore()
+	}
+	h := RateLimiterWithConfig(RateLimiterConfig{Max: rl.Max, Window: rl.Window, Store: l.store})
+	l.handler.Store(&h)
+}
+
+func (l *configLimiter) handle(c *Context) {
+	if h := l.handler.Load(); h != nil {
+		(*h)(c)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfigYAML = `
+mode: test
+port: 8080
+trusted_proxies: ["10.0.0.0/8"]
+server:
+  read_header_timeout: 2s
+  write_timeout: 30s
+  max_header_bytes: 65536
+rate_limit:
+  max: 2
+  window: 1m
+database:
+  driver: sqlite
+  dsn: pos.db
+  max_open_conns: 5
+redis:
+  addr: localhost:6379
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeTestConfig(t, testConfigYAML)
+	t.Setenv("GOTAP_PORT", "9090")
+	t.Setenv("GOTAP_DB_DSN", "prod.db")
+	t.Setenv("GOTAP_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr() != ":9090" || cfg.Mode != TestMode || cfg.Redis.Addr != "localhost:6379" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if cfg.Server.ReadHeaderTimeout != 2*time.Second || cfg.Server.WriteTimeout != 30*time.Second || cfg.Server.MaxHeaderBytes != 65536 {
+		t.Errorf("Unexpected server config %+v", cfg.Server)
+	}
+	if strings.Join(cfg.TrustedProxies, ",") != "10.0.0.0/8,192.168.1.1" {
+		t.Errorf("Expected the env proxies, got %v", cfg.TrustedProxies)
+	}
+	db := cfg.DBConfig()
+	if db.Driver != "sqlite" || db.DSN != "prod.db" || db.MaxOpenConns != 5 || db.MaxIdleConns != 10 {
+		t.Errorf("Unexpected DB config %+v", db)
+	}
+	srv := cfg.HTTPServer(New())
+	if srv.Addr != ":9090" || srv.ReadHeaderTimeout != 2*time.Second || srv.WriteTimeout != 30*time.Second {
+		t.Errorf("Unexpected server %+v", srv)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for content, want := range map[string]string{
+		"mode: staging":            "unknown mode",
+		"tls:\n  cert_file: a.pem": "both cert_file and key_file",
+		"rate_limit:\n  max: 10":   "positive max and window",
+		"trusted_proxies: [\"x\"]": "invalid trusted proxy",
+		"port: [1]":                "config",
+	} {
+		if _, err := LoadConfig(writeTestConfig(t, content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected an error containing %q, got %v", content, want, err)
+		}
+	}
+}
+
+func TestConfigApplyAndReload(t *testing.T) {
+	defer SetMode(Mode())
+	path := writeTestConfig(t, testConfigYAML)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := cfg.Apply(r); err != nil {
+		t.Fatal(err)
+	}
+	r.GET("/ip", func(c *Context) {
+		c.Writer.WriteString(c.ClientIP())
+	})
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/ip", nil)
+		req.RemoteAddr = remote + ":1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("10.1.2.3"); w.Body.String() != "203.0.113.7" {
+		t.Errorf("Expected the forwarded IP from a trusted proxy, got %q", w.Body.String())
+	}
+	if w := get("198.51.100.1"); w.Body.String() != "198.51.100.1" {
+		t.Errorf("Expected the remote IP from an untrusted client, got %q", w.Body.String())
+	}
+	get("10.1.2.3")
+	if w := get("10.1.2.3"); w.Code != 429 {
+		t.Errorf("Expected the configured rate limit, got %d", w.Code)
+	}
+
+	// Reloading trusts another proxy and raises the limit without losing counters
+	os.WriteFile(path, []byte(strings.NewReplacer("10.0.0.0/8", "198.51.100.0/24", "max: 2", "max: 5").Replace(testConfigYAML)), 0644)
+	next, err := cfg.reload(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RateLimit.Max != 5 || cfg.RateLimit.Max != 2 {
+		t.Errorf("Expected a new config, got %d and %d", next.RateLimit.Max, cfg.RateLimit.Max)
+	}
+	if w := get("198.51.100.1"); w.Body.String() != "203.0.113.7" {
+		t.Errorf("Expected the reloaded proxies, got %q", w.Body.String())
+	}
+	if w := get("10.1.2.3"); w.Code != 200 || w.Body.String() != "10.1.2.3" {
+		t.Errorf("Expected the raised limit and untrusted old proxy, got %d %q", w.Code, w.Body.String())
+	}
+
+	os.WriteFile(path, []byte("mode: staging"), 0644)
+	if _, err := cfg.reload(r); err == nil {
+		t.Error("Expected an invalid config to be rejected")
+	}
+}
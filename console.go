@@ -0,0 +1,285 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Binds returns a group whose routes declare obj's type as their request
+// body, or query for GET and DELETE routes. The API console builds its
+// forms from it:
+//
+//	api.Binds(LoginRequest{}).POST("/login", login)
+func (group *RouterGroup) Binds(obj any) *RouterGroup {
+	child := group.Group("")
+	child.binds = reflect.TypeOf(obj)
+	for child.binds.Kind() == reflect.Pointer {
+		child.binds = child.binds.Elem()
+	}
+	return child
+}
+
+// ConsoleRoute describes a route for the API console
+type ConsoleRoute struct {
+	RouteInfo
+	Params []string       `json:"params,omitempty"`
+	Query  []ConsoleField `json:"query,omitempty"`
+	Body   []ConsoleField `json:"body,omitempty"`
+}
+
+// ConsoleField is a form field generated from a binding struct field
+type ConsoleField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // "string", "number", "boolean" or "json"
+	Required bool   `json:"required,omitempty"`
+}
+
+// Console serves an interactive API console at relativePath, "/console"
+// if empty, listing every route with a form to try it. Forms have fields
+// for path parameters and for the request type declared with Binds, and a
+// bearer token is kept in the browser for authenticated routes.
+//
+// The console is for development and is only registered in debug mode.
+func (engine *Engine) Console(relativePath string) {
+	if !IsDebugging() {
+		return
+	}
+	if relativePath == "" {
+		relativePath = "/console"
+	}
+	prefix := strings.TrimSuffix(engine.calculateAbsolutePath(relativePath), "/")
+
+	engine.GET(relativePath, func(c *Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(consolePage))
+	})
+	engine.GET(prefix+"/routes", func(c *Context) {
+		c.JSON(http.StatusOK, engine.consoleRoutes(prefix))
+	})
+	debugPrint("API console at %s", prefix)
+}
+
+// consoleRoutes describes all routes except the console's own
+func (engine *Engine) consoleRoutes(prefix string) []ConsoleRoute {
+	var routes []ConsoleRoute
+	for _, route := range engine.describeRoutes() {
+		if route.Path == prefix || strings.HasPrefix(route.Path, prefix+"/") {
+			continue
+		}
+		cr := ConsoleRoute{RouteInfo: route}
+		for _, segment := range strings.Split(route.Path, "/") {
+			if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+				cr.Params = append(cr.Params, segment[1:])
+			}
+		}
+		if binds := engine.routeMeta[route.Method+" "+route.Path].binds; binds != nil {
+			if route.Method == http.MethodGet || route.Method == http.MethodDelete {
+				cr.Query = consoleFields(binds, "form")
+			} else {
+				cr.Body = consoleFields(binds, "json")
+			}
+		}
+		routes = append(routes, cr)
+	}
+	return routes
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// consoleFields lists the fields of struct type t named by tag
+func consoleFields(t reflect.Type, tag string) []ConsoleField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []ConsoleField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// Fields of embedded structs are promoted, as in encoding/json
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, consoleFields(ft, tag)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		field := ConsoleField{Name: name, Type: "json"}
+		switch ft.Kind() {
+		case reflect.String:
+			field.Type = "string"
+		case reflect.Bool:
+			field.Type = "boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			field.Type = "number"
+		case reflect.Struct:
+			if ft == timeType {
+				field.Type = "string"
+			}
+		}
+		for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				field.Required = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+const consolePage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>goTap API console</title>
+<style>
+body { font: 14px system-ui, sans-serif; margin: 0; display: flex; height: 100vh; }
+#routes { width: 340px; overflow-y: auto; border-right: 1px solid #ddd; }
+#routes h3 { margin: 12px 12px 4px; font-size: 12px; color: #666; }
+#routes div { padding: 4px 12px; cursor: pointer; font-family: monospace; }
+#routes div:hover, #routes div.active { background: #eef; }
+main { flex: 1; padding: 16px 24px; overflow-y: auto; }
+label { display: block; margin: 8px 0 2px; font-weight: 600; }
+input, textarea { width: 100%; box-sizing: border-box; font-family: monospace; padding: 4px; }
+textarea { height: 120px; }
+button { margin-top: 12px; padding: 6px 16px; }
+pre { background: #f6f6f6; padding: 12px; white-space: pre-wrap; word-break: break-all; }
+.method { display: inline-block; width: 64px; font-weight: bold; }
+.meta { color: #666; font-size: 12px; }
+</style>
+</head>
+<body>
+<nav id="routes"></nav>
+<main>
+<label for="token">Bearer token</label>
+<input id="token" placeholder="Sent as Authorization: Bearer ...; a token in a response is kept">
+<div id="form"><p>Choose a route.</p></div>
+<pre id="response" hidden></pre>
+</main>
+<script>
+const base = location.pathname.replace(/\/$/, "");
+const token = document.getElementById("token");
+token.value = localStorage.getItem("gotap.console.token") || "";
+token.onchange = () => localStorage.setItem("gotap.console.token", token.value);
+
+function el(tag, props, ...children) {
+  const e = Object.assign(document.createElement(tag), props);
+  e.append(...children);
+  return e;
+}
+
+function input(name, field, prefix) {
+  const id = prefix + name;
+  const type = field.type === "boolean" ? "checkbox" : field.type === "number" ? "number" : "text";
+  const control = field.type === "json" ? el("textarea", {id, placeholder: "JSON"}) : el("input", {id, type, step: "any"});
+  return [el("label", {htmlFor: id, textContent: name + (field.required ? " *" : "")}), control];
+}
+
+function value(field, prefix) {
+  const e = document.getElementById(prefix + field.name);
+  if (field.type === "boolean") return e.checked;
+  if (e.value === "") return undefined;
+  if (field.type === "number") return Number(e.value);
+  if (field.type === "json") return JSON.parse(e.value);
+  return e.value;
+}
+
+function show(route) {
+  const form = document.getElementById("form");
+  const meta = [route.group, ...(route.middleware || []), ...Object.entries(route.metadata || {}).map(([k, v]) => k + "=" + v)];
+  const fields = [];
+  (route.params || []).forEach(p => fields.push(...input(p, {type: "string", required: true}, "param-")));
+  (route.query || []).forEach(f => fields.push(...input(f.name, f, "query-")));
+  (route.body || []).forEach(f => fields.push(...input(f.name, f, "body-")));
+  if (!route.body && !["GET", "HEAD", "DELETE", "OPTIONS"].includes(route.method)) {
+    fields.push(el("label", {htmlFor: "raw", textContent: "Body"}), el("textarea", {id: "raw", placeholder: "JSON"}));
+  }
+  const send = el("button", {textContent: "Send"});
+  send.onclick = () => request(route).catch(err => output(String(err)));
+  form.replaceChildren(
+    el("h2", {}, el("span", {className: "method", textContent: route.method}), route.path),
+    el("div", {className: "meta", textContent: meta.join(" · ")}),
+    ...fields, send);
+}
+
+async function request(route) {
+  let path = route.path;
+  (route.params || []).forEach(p => {
+    path = path.replace(new RegExp("[:*]" + p + "(?=/|$)"), encodeURIComponent(document.getElementById("param-" + p).value));
+  });
+  const query = new URLSearchParams();
+  (route.query || []).forEach(f => { const v = value(f, "query-"); if (v !== undefined) query.set(f.name, v); });
+  if ([...query].length) path += "?" + query;
+
+  const headers = {};
+  if (token.value) headers.Authorization = "Bearer " + token.value;
+  let body;
+  if (route.body) {
+    const obj = {};
+    route.body.forEach(f => { const v = value(f, "body-"); if (v !== undefined) obj[f.name] = v; });
+    body = JSON.stringify(obj);
+  } else if (document.getElementById("raw") && document.getElementById("raw").value) {
+    body = document.getElementById("raw").value;
+  }
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+
+  const start = performance.now();
+  const res = await fetch(path, {method: route.method, headers, body});
+  const text = await res.text();
+  let pretty = text;
+  try {
+    const json = JSON.parse(text);
+    pretty = JSON.stringify(json, null, 2);
+    if (json && typeof json.token === "string") {
+      token.value = json.token;
+      token.onchange();
+    }
+  } catch (e) {}
+  const headerLines = [...res.headers].map(([k, v]) => k + ": " + v).join("\n");
+  output(res.status + " " + res.statusText + " (" + Math.round(performance.now() - start) + " ms)\n" + headerLines + "\n\n" + pretty);
+}
+
+function output(text) {
+  const pre = document.getElementById("response");
+  pre.hidden = false;
+  pre.textContent = text;
+}
+
+fetch(base + "/routes").then(r => r.json()).then(routes => {
+  const nav = document.getElementById("routes");
+  let group;
+  (routes || []).forEach(route => {
+    if (route.group !== group) {
+      group = route.group;
+      nav.append(el("h3", {textContent: group}));
+    }
+    const item = el("div", {}, el("span", {className: "method", textContent: route.method}), route.path);
+    item.onclick = () => {
+      nav.querySelectorAll(".active").forEach(e => e.classList.remove("active"));
+      item.classList.add("active");
+      show(route);
+    };
+    nav.append(item);
+  });
+});
+</script>
+</body>
+</html>
+`
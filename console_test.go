@@ -0,0 +1,100 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type consoleAddress struct {
+	City string `json:"city"`
+}
+
+type consoleOrder struct {
+	consoleAddress
+	Customer string     `json:"customer" binding:"required"`
+	Total    float64    `json:"total" binding:"required,gt=0"`
+	Paid     bool       `json:"paid"`
+	Items    []string   `json:"items"`
+	Due      *time.Time `json:"due,omitempty"`
+	Internal string     `json:"-"`
+}
+
+type consoleFilter struct {
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+}
+
+func TestConsole(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+
+	r := New()
+	r.Console("")
+	api := r.Group("/api")
+	api.Binds(&consoleOrder{}).POST("/orders", func(c *Context) {})
+	api.Binds(consoleFilter{}).GET("/orders", func(c *Context) {})
+	api.GET("/orders/:id/*rest", func(c *Context) {})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/console", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "goTap API console") {
+		t.Fatalf("Expected console page, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/console/routes", nil))
+	var routes []ConsoleRoute
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("Expected 3 routes without the console's own, got %+v", routes)
+	}
+
+	byKey := map[string]ConsoleRoute{}
+	for _, route := range routes {
+		byKey[route.Method+" "+route.Path] = route
+	}
+	body := byKey["POST /api/orders"].Body
+	want := []ConsoleField{
+		{Name: "city", Type: "string"},
+		{Name: "customer", Type: "string", Required: true},
+		{Name: "total", Type: "number", Required: true},
+		{Name: "paid", Type: "boolean"},
+		{Name: "items", Type: "json"},
+		{Name: "due", Type: "string"},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("Unexpected body fields %+v", body)
+	}
+	if byKey["POST /api/orders"].Metadata["binds"] != "goTap.consoleOrder" {
+		t.Errorf("Expected binds metadata, got %v", byKey["POST /api/orders"].Metadata)
+	}
+	if query := byKey["GET /api/orders"].Query; len(query) != 2 || query[1] != (ConsoleField{Name: "page", Type: "number"}) {
+		t.Errorf("Unexpected query fields %+v", query)
+	}
+	if params := byKey["GET /api/orders/:id/*rest"].Params; !reflect.DeepEqual(params, []string{"id", "rest"}) {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
+
+func TestConsoleReleaseMode(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+
+	r := New()
+	r.Console("/console")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/console", nil))
+	if w.Code != 404 {
+		t.Errorf("Expected no console in release mode, got %d", w.Code)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Message is a message received from a broker
+type Message struct {
+	// Topic is the Kafka topic or NATS subject
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+
+	// Attempt is the current delivery attempt, starting at 1
+	Attempt int
+
+	// Raw holds the client's own message type, e.g. for broker metadata
+	Raw any
+}
+
+// MessageConsumer reads messages from a broker. With more than one worker,
+// Fetch and Commit are called concurrently.
+type MessageConsumer interface {
+	// Fetch blocks until the next message is available or ctx is done
+	Fetch(ctx context.Context) (*Message, error)
+
+	// Commit acknowledges msg once it was handled or dead-lettered.
+	// Messages that are never committed are redelivered by the broker.
+	Commit(ctx context.Context, msg *Message) error
+}
+
+// MessageHandler handles a consumed message. Returning an error retries it.
+type MessageHandler func(ctx context.Context, msg *Message) error
+
+// ConsumerConfig holds configuration for a ConsumerRunner
+type ConsumerConfig struct {
+	// Name identifies the runner in logs
+	// Default: "consumer"
+	Name string
+
+	// Consumer is the message source
+	Consumer MessageConsumer
+
+	// Handler processes each message
+	Handler MessageHandler
+
+	// Workers is the number of messages handled concurrently
+	// Default: 1, which keeps messages in order
+	Workers int
+
+	// MaxRetries is the number of extra attempts for a failed message
+	// before it is dead-lettered
+	// Default: 3
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubling per attempt
+	// up to 1 minute
+	// Default: 1 second
+	RetryBackoff time.Duration
+
+	// DeadLetter receives messages that failed every attempt, see
+	// KafkaDeadLetter and NATSDeadLetter. If it fails the message is left
+	// uncommitted for the broker to redeliver.
+	// Default: logs and drops the message
+	DeadLetter func(ctx context.Context, msg *Message, err error) error
+}
+
+// ConsumerStats reports the activity of a ConsumerRunner
+type ConsumerStats struct {
+	Handled      int64 `json:"handled"`
+	Retried      int64 `json:"retried"`
+	DeadLettered int64 `json:"dead_lettered"`
+	Errors       int64 `json:"errors"`
+}
+
+// ConsumerRunner dispatches messages from a MessageConsumer to a handler
+// with retries and dead-lettering
+type ConsumerRunner struct {
+	config ConsumerConfig
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+	started bool
+	stopped bool
+
+	handled, retried, deadLettered, errs atomic.Int64
+}
+
+// NewConsumerRunner creates a consumer runner; call Start to begin consuming
+func NewConsumerRunner(config ConsumerConfig) *ConsumerRunner {
+	if config.Consumer == nil || config.Handler == nil {
+		panic("goTap: ConsumerRunner requires Consumer and Handler")
+	}
+	if config.Name == "" {
+		config.Name = "consumer"
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.DeadLetter == nil {
+		name := config.Name
+		config.DeadLetter = func(ctx context.Context, msg *Message, err error) error {
+			debugPrint("[WARNING] %s dropped message on %s after %d attempts: %v", name, msg.Topic, msg.Attempt, err)
+			return nil
+		}
+	}
+	return &ConsumerRunner{config: config}
+}
+
+// Start launches the workers
+func (r *ConsumerRunner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started || r.stopped {
+		return
+	}
+	r.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.work(ctx)
+		}()
+	}
+}
+
+// Stop stops fetching and waits for in-flight messages to finish. A message
+// waiting for a retry is left uncommitted and redelivered by the broker.
+func (r *ConsumerRunner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil
+	}
+	r.stopped = true
+	started := r.started
+	r.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns consumer counters
+func (r *ConsumerRunner) Stats() ConsumerStats {
+	return ConsumerStats{
+		Handled:      r.handled.Load(),
+		Retried:      r.retried.Load(),
+		DeadLettered: r.deadLettered.Load(),
+		Errors:       r.errs.Load(),
+	}
+}
+
+// work fetches and handles messages until ctx is canceled
+func (r *ConsumerRunner) work(ctx context.Context) {
+	failures := 0
+	for {
+		msg, err := r.config.Consumer.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// Broker unavailable: back off instead of spinning
+			r.errs.Add(1)
+			debugPrint("[WARNING] %s fetch failed: %v", r.config.Name, err)
+			failures++
+			if !sleepCtx(ctx, backoffDelay(r.config.RetryBackoff, failures, time.Minute)) {
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		if !r.handle(ctx, msg) {
+			return
+		}
+	}
+}
+
+// handle runs the handler with retries and commits the message. It returns
+// false when ctx was canceled during a retry backoff.
+func (r *ConsumerRunner) handle(ctx context.Context, msg *Message) bool {
+	var err error
+	for attempt := 1; attempt <= r.config.MaxRetries+1; attempt++ {
+		msg.Attempt = attempt
+		// In-flight handlers are not canceled by Stop
+		if err = r.call(context.WithoutCancel(ctx), msg); err == nil {
+			r.handled.Add(1)
+			r.commit(ctx, msg)
+			return true
+		}
+		if attempt <= r.config.MaxRetries {
+			r.retried.Add(1)
+			if !sleepCtx(ctx, backoffDelay(r.config.RetryBackoff, attempt, time.Minute)) {
+				return false
+			}
+		}
+	}
+
+	if dlqErr := r.config.DeadLetter(context.WithoutCancel(ctx), msg, err); dlqErr != nil {
+		r.errs.Add(1)
+		debugPrint("[WARNING] %s dead-lettering message on %s failed: %v", r.config.Name, msg.Topic, dlqErr)
+		return true
+	}
+	r.deadLettered.Add(1)
+	r.commit(ctx, msg)
+	return true
+}
+
+// call runs the handler, turning panics into errors
+func (r *ConsumerRunner) call(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+	return r.config.Handler(ctx, msg)
+}
+
+func (r *ConsumerRunner) commit(ctx context.Context, msg *Message) {
+	if err := r.config.Consumer.Commit(context.WithoutCancel(ctx), msg); err != nil {
+		r.errs.Add(1)
+		debugPrint("[WARNING] %s commit on %s failed: %v", r.config.Name, msg.Topic, err)
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is done first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Consume creates and starts a consumer runner that is stopped with the
+// server:
+//
+//	r.Consume(goTap.ConsumerConfig{
+//	    Consumer: ordersConsumer,
+//	    Handler: func(ctx context.Context, msg *goTap.Message) error {
+//	        return processOrder(ctx, msg.Value)
+//	    },
+//	})
+func (engine *Engine) Consume(config ConsumerConfig) *ConsumerRunner {
+	r := NewConsumerRunner(config)
+	r.Start()
+	engine.addService(r.config.Name, r.Stop)
+	return r
+}
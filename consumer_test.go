@@ -0,0 +1,197 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBroker is an in-memory KafkaClient and NATSClient
+type memoryBroker struct {
+	mu        sync.Mutex
+	topics    map[string]chan *Message
+	committed []string
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{topics: make(map[string]chan *Message)}
+}
+
+func (b *memoryBroker) topic(name string) chan *Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.topics[name] == nil {
+		b.topics[name] = make(chan *Message, 16)
+	}
+	return b.topics[name]
+}
+
+func (b *memoryBroker) Produce(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
+	b.topic(topic) <- &Message{Topic: topic, Key: key, Value: value, Headers: headers}
+	return nil
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	return b.Produce(ctx, subject, nil, data, headers)
+}
+
+func (b *memoryBroker) Consumer(topic, group string) (MessageConsumer, error) {
+	return &memoryConsumer{broker: b, ch: b.topic(topic)}, nil
+}
+
+func (b *memoryBroker) Subscribe(subject, queue string) (MessageConsumer, error) {
+	return b.Consumer(subject, queue)
+}
+
+func (b *memoryBroker) commits() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.committed...)
+}
+
+type memoryConsumer struct {
+	broker *memoryBroker
+	ch     chan *Message
+}
+
+func (c *memoryConsumer) Fetch(ctx context.Context) (*Message, error) {
+	select {
+	case msg := <-c.ch:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *memoryConsumer) Commit(ctx context.Context, msg *Message) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.broker.committed = append(c.broker.committed, string(msg.Value))
+	return nil
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestKafkaInjectAndConsume(t *testing.T) {
+	broker := newMemoryBroker()
+	r := New()
+	r.Use(KafkaInject(broker))
+	r.POST("/orders", func(c *Context) {
+		MustGetKafka(c).Produce(c.Request.Context(), "orders", []byte("k"), []byte(c.Query("id")), nil)
+		c.Status(202)
+	})
+
+	var mu sync.Mutex
+	var handled []string
+	runner, err := r.ConsumeKafka(broker, "orders", "billing", ConsumerConfig{
+		RetryBackoff: time.Millisecond,
+		DeadLetter:   KafkaDeadLetter(broker, "orders.dlq"),
+		Handler: func(ctx context.Context, msg *Message) error {
+			if string(msg.Value) == "bad" {
+				return errors.New("invalid order")
+			}
+			if string(msg.Value) == "flaky" && msg.Attempt < 3 {
+				return errors.New("timeout")
+			}
+			mu.Lock()
+			handled = append(handled, string(msg.Value))
+			mu.Unlock()
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"1", "flaky", "bad", "2"} {
+		req, _ := http.NewRequest("POST", "/orders?id="+id, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	waitFor(t, func() bool { return len(broker.commits()) == 4 })
+
+	if got := broker.commits(); got[0] != "1" || got[1] != "flaky" || got[2] != "bad" || got[3] != "2" {
+		t.Errorf("Expected messages committed in order, got %v", got)
+	}
+	mu.Lock()
+	if len(handled) != 3 {
+		t.Errorf("Unexpected handled %v", handled)
+	}
+	mu.Unlock()
+
+	dlq := <-broker.topic("orders.dlq")
+	if string(dlq.Value) != "bad" || dlq.Headers["dlq-topic"] != "orders" ||
+		dlq.Headers["dlq-error"] != "invalid order" || dlq.Headers["dlq-attempts"] != "4" {
+		t.Errorf("Unexpected dead letter %+v", dlq)
+	}
+	if stats := runner.Stats(); stats.Handled != 3 || stats.Retried != 5 || stats.DeadLettered != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	r.shutdownServices()
+	runner.mu.Lock()
+	stopped := runner.stopped
+	runner.mu.Unlock()
+	if !stopped {
+		t.Error("Expected consumer to stop with the engine")
+	}
+}
+
+func TestConsumerStopLeavesRetryUncommitted(t *testing.T) {
+	broker := newMemoryBroker()
+	calls := make(chan struct{}, 1)
+	runner := NewConsumerRunner(ConsumerConfig{
+		Consumer:     &memoryConsumer{broker: broker, ch: broker.topic("t")},
+		RetryBackoff: time.Hour,
+		Handler: func(ctx context.Context, msg *Message) error {
+			calls <- struct{}{}
+			panic("boom")
+		},
+	})
+	runner.Start()
+	broker.Produce(context.Background(), "t", nil, []byte("m"), nil)
+	<-calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := runner.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := broker.commits(); len(got) != 0 {
+		t.Errorf("Expected message left for redelivery, got commits %v", got)
+	}
+}
+
+func TestNATSInject(t *testing.T) {
+	broker := newMemoryBroker()
+	r := New()
+	r.Use(NATSInject(broker))
+	r.GET("/", func(c *Context) {
+		if _, ok := GetKafka(c); ok {
+			t.Error("Expected no Kafka client")
+		}
+		MustGetNATS(c).Publish(c.Request.Context(), "pos.sale", []byte("s1"), nil)
+	})
+	req, _ := http.NewRequest("GET", "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if msg := <-broker.topic("pos.sale"); string(msg.Value) != "s1" {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+}
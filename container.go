@@ -0,0 +1,85 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide adds val to the request's dependencies as a T, replacing any
+// earlier T. Unlike Set, the type is the key, so Resolve can't get a
+// value of the wrong type:
+//
+//	goTap.Provide(c, db)                         // a *goTap.DB
+//	goTap.Provide[goTap.KafkaClient](c, client)  // an interface
+//
+// The Inject middlewares provide their client, e.g. GormInject a *DB and
+// KafkaInject a KafkaClient.
+func Provide[T any](c *Context, val T) {
+	c.mu.Lock()
+	if c.provided == nil {
+		c.provided = make(map[reflect.Type]any)
+	}
+	c.provided[reflect.TypeFor[T]()] = val
+	c.mu.Unlock()
+}
+
+// Resolve returns the T added with Provide, ie: (value, true). T must be
+// the type it was provided as: a value provided as an interface isn't
+// resolved by its concrete type, nor the reverse.
+//
+//	db, ok := goTap.Resolve[*goTap.DB](c)
+func Resolve[T any](c *Context) (T, bool) {
+	c.mu.RLock()
+	val, ok := c.provided[reflect.TypeFor[T]()]
+	c.mu.RUnlock()
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return val.(T), true
+}
+
+// MustResolve returns the T added with Provide, otherwise it panics.
+func MustResolve[T any](c *Context) T {
+	if val, ok := Resolve[T](c); ok {
+		return val
+	}
+	panic("goTap: no " + reflect.TypeFor[T]().String() + " provided")
+}
+
+// Inject returns a middleware setting val under key and providing it as a
+// T, so handlers get it with Get, MustGet or Resolve. Integrations need no
+// accessors of their own:
+//
+//	r.Use(goTap.Inject("s3", s3Client))
+//
+//	client := goTap.MustGet[*s3.Client](c, "s3")
+func Inject[T any](key string, val T) HandlerFunc {
+	return func(c *Context) {
+		c.Set(key, val)
+		Provide(c, val)
+		c.Next()
+	}
+}
+
+// Get returns the value set under key as a T, ie: (value, true). It
+// returns false when the key doesn't exist or holds another type.
+func Get[T any](c *Context, key string) (T, bool) {
+	val, _ := c.Get(key)
+	v, ok := val.(T)
+	return v, ok
+}
+
+// MustGet returns the value set under key as a T, otherwise it panics.
+func MustGet[T any](c *Context, key string) T {
+	val := c.MustGet(key)
+	v, ok := val.(T)
+	if !ok {
+		panic(fmt.Sprintf("goTap: key %q holds a %T, not a %s", key, val, reflect.TypeFor[T]()))
+	}
+	return v
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type testClock struct{ now string }
+
+type testNamer interface{ Name() string }
+
+type testStore struct{}
+
+func (testStore) Name() string { return "store" }
+
+func TestProvideResolve(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+
+	if _, ok := Resolve[*testClock](c); ok {
+		t.Error("Resolve found a dependency before Provide")
+	}
+
+	clock := &testClock{now: "09:00"}
+	Provide(c, clock)
+	Provide(c, "a string")
+	if got, ok := Resolve[*testClock](c); !ok || got != clock {
+		t.Errorf("Resolve[*testClock] = %v, %v", got, ok)
+	}
+	if got := MustResolve[string](c); got != "a string" {
+		t.Errorf("MustResolve[string] = %q", got)
+	}
+	if _, ok := Resolve[testClock](c); ok {
+		t.Error("Resolve[testClock] found the *testClock")
+	}
+
+	// Provide replaces the earlier value of the same type
+	Provide(c, &testClock{now: "10:00"})
+	if MustResolve[*testClock](c).now != "10:00" {
+		t.Error("Provide did not replace the dependency")
+	}
+
+	// Interfaces are keyed by the type they were provided as
+	Provide[testNamer](c, testStore{})
+	if n, ok := Resolve[testNamer](c); !ok || n.Name() != "store" {
+		t.Errorf("Resolve[testNamer] = %v, %v", n, ok)
+	}
+	if _, ok := Resolve[testStore](c); ok {
+		t.Error("Resolve by the concrete type found the interface")
+	}
+
+	cp := c.Copy()
+	if _, ok := Resolve[*testClock](cp); !ok {
+		t.Error("Copy lost the dependencies")
+	}
+	c.reset()
+	if _, ok := Resolve[*testClock](c); ok {
+		t.Error("reset kept the dependencies")
+	}
+
+	defer func() {
+		if r := recover(); r != "goTap: no int provided" {
+			t.Errorf("MustResolve panic = %v", r)
+		}
+	}()
+	MustResolve[int](c)
+}
+
+func TestInjectProvides(t *testing.T) {
+	broker := newMemoryBroker()
+	r := New()
+	r.Use(KafkaInject(broker))
+	var resolved KafkaClient
+	r.GET("/", func(c *Context) {
+		resolved, _ = Resolve[KafkaClient](c)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if resolved != broker {
+		t.Errorf("Resolve[KafkaClient] = %v, want the injected client", resolved)
+	}
+}
+
+func TestInjectGet(t *testing.T) {
+	store := &testClock{now: "11:00"}
+	r := New()
+	r.Use(Inject("clock", store))
+	r.GET("/", func(c *Context) {
+		if got, ok := Get[*testClock](c, "clock"); !ok || got != store {
+			t.Errorf("Get = %v, %v", got, ok)
+		}
+		if got := MustGet[*testClock](c, "clock"); got != store {
+			t.Errorf("MustGet = %v", got)
+		}
+		if got, ok := Resolve[*testClock](c); !ok || got != store {
+			t.Errorf("Resolve = %v, %v", got, ok)
+		}
+		if _, ok := Get[string](c, "clock"); ok {
+			t.Error("Get[string] of a *testClock succeeded")
+		}
+		if _, ok := Get[*testClock](c, "missing"); ok {
+			t.Error("Get of a missing key succeeded")
+		}
+		defer func() {
+			if r := recover(); r != `goTap: key "clock" holds a *goTap.testClock, not a string` {
+				t.Errorf("MustGet panic = %v", r)
+			}
+		}()
+		MustGet[string](c, "clock")
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
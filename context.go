@@ -5,13 +5,16 @@
 package goTap
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +29,15 @@ const (
 	MIMEPlain             = "text/plain"
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/x-yaml"
+	MIMEYAML2             = "application/yaml"
+	MIMEMsgPack           = "application/msgpack"
+	MIMEMsgPack2          = "application/x-msgpack"
+	MIMEProtoBuf          = "application/x-protobuf"
+	MIMECSV               = "text/csv"
+	MIMEXLSX              = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MIMEJSONAPI           = "application/vnd.api+json"
+	MIMEHAL               = "application/hal+json"
 )
 
 const abortIndex int8 = math.MaxInt8 >> 1
@@ -46,12 +58,15 @@ type Context struct {
 	params       *Params
 	skippedNodes *[]skippedNode
 
-	// This mutex protects Keys map.
+	// This mutex protects Keys map and provided.
 	mu sync.RWMutex
 
 	// Keys is a key/value pair exclusively for the context of each request.
 	Keys map[string]any
 
+	// provided holds the dependencies added with Provide by type
+	provided map[reflect.Type]any
+
 	// Errors is a list of errors attached to all the handlers/middlewares who used this context.
 	Errors errorMsgs
 
@@ -77,7 +92,9 @@ func (c *Context) reset() {
 	c.index = -1
 
 	c.fullPath = ""
-	c.Keys = nil
+	// Keep the map allocated by Set for the next request
+	clear(c.Keys)
+	clear(c.provided)
 	c.Errors = c.Errors[:0]
 	c.Accepted = nil
 	c.queryCache = nil
@@ -103,6 +120,10 @@ func (c *Context) Copy() *Context {
 	cp.index = abortIndex
 	cp.handlers = nil
 	cp.fullPath = c.fullPath
+	c.mu.RLock()
+	cp.Keys = maps.Clone(c.Keys)
+	cp.provided = maps.Clone(c.provided)
+	c.mu.RUnlock()
 	cParams := c.Params
 	cp.Params = make([]Param, len(cParams))
 	copy(cp.Params, cParams)
@@ -348,9 +369,33 @@ func (c *Context) Header(key, value string) {
 // setContentType is an optimized version of Header for setting Content-Type.
 // It bypasses the expensive canonicalization in Header.Set for better performance.
 func (c *Context) setContentType(value string) {
+	if v, ok := contentTypeValues[value]; ok {
+		c.Writer.Header()["Content-Type"] = v
+		return
+	}
 	c.Writer.Header()["Content-Type"] = []string{value}
 }
 
+// contentTypeValues holds shared header values for the content types the
+// renderers set, saving a slice allocation per response. Header.Add appends
+// past their length and Header.Set replaces them, so sharing is safe.
+var contentTypeValues = func() map[string][]string {
+	m := make(map[string][]string)
+	for _, value := range []string{
+		MIMEJSON,
+		MIMEJSON + "; charset=utf-8",
+		MIMEPlain + "; charset=utf-8",
+		"text/html; charset=utf-8",
+		"application/xml; charset=utf-8",
+		"application/x-yaml; charset=utf-8",
+		"application/javascript; charset=utf-8",
+		"text/event-stream",
+	} {
+		m[value] = []string{value}
+	}
+	return m
+}()
+
 // GetHeader returns value from request headers.
 func (c *Context) GetHeader(key string) string {
 	return c.Request.Header.Get(key)
@@ -390,9 +435,15 @@ func (c *Context) Cookie(name string) (string, error) {
 }
 
 // ClientIP implements one best effort algorithm to return the real client IP.
-// It calls c.RemoteIP() under the hood, to check if the remote IP is a trusted proxy or not.
+// The X-Forwarded-For and X-Real-Ip headers are only used for requests from
+// trusted proxies, see Engine.SetTrustedProxies.
 func (c *Context) ClientIP() string {
-	if c.engine.ForwardedByClientIP {
+	remoteIP, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		remoteIP = ""
+	}
+
+	if c.engine.ForwardedByClientIP && c.engine.isTrustedProxy(net.ParseIP(remoteIP)) {
 		clientIP := c.Request.Header.Get("X-Forwarded-For")
 		clientIP = strings.TrimSpace(strings.Split(clientIP, ",")[0])
 		if clientIP != "" {
@@ -404,11 +455,7 @@ func (c *Context) ClientIP() string {
 		}
 	}
 
-	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
-		return ip
-	}
-
-	return ""
+	return remoteIP
 }
 
 // ContentType returns the Content-Type header of the request.
@@ -431,8 +478,13 @@ func (c *Context) FullPath() string {
 func (c *Context) JSON(code int, obj any) {
 	c.Status(code)
 	c.setContentType(MIMEJSON)
-	encoder := json.NewEncoder(c.Writer)
-	if err := encoder.Encode(obj); err != nil {
+	e := getJSONEncoder(true)
+	defer putJSONEncoder(e)
+	if err := e.encode(c.prepareJSON(obj)); err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := c.Writer.Write(e.buf.Bytes()); err != nil {
 		c.Error(err)
 	}
 }
@@ -442,13 +494,15 @@ func (c *Context) String(code int, format string, values ...any) {
 	c.Status(code)
 	c.setContentType(MIMEPlain + "; charset=utf-8")
 	if len(values) > 0 {
-		_, err := c.Writer.Write([]byte(sprintf(format, values...)))
-		if err != nil {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		fmt.Fprintf(buf, format, values...)
+		if _, err := c.Writer.Write(buf.Bytes()); err != nil {
 			c.Error(err)
 		}
 		return
 	}
-	_, err := c.Writer.Write([]byte(format))
+	_, err := c.Writer.WriteString(format)
 	if err != nil {
 		c.Error(err)
 	}
@@ -465,7 +519,12 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 }
 
 // File writes the specified file into the body stream in an efficient way.
+// Regular files get an ETag from their metadata and are sent with sendfile
+// where the platform and connection allow it.
 func (c *Context) File(filepath string) {
+	if fi, err := os.Stat(filepath); err == nil {
+		c.setFileETag(fi)
+	}
 	http.ServeFile(c.Writer, c.Request, filepath)
 }
 
@@ -477,7 +536,7 @@ func (c *Context) FileAttachment(filepath, filename string) {
 	} else {
 		c.Writer.Header().Set("Content-Disposition", `attachment; filename*=UTF-8''`+url.QueryEscape(filename))
 	}
-	http.ServeFile(c.Writer, c.Request, filepath)
+	c.File(filepath)
 }
 
 // FileFromFS writes the specified file from http.FileSystem into the body stream in an efficient way.
@@ -488,6 +547,12 @@ func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
 
 	c.Request.URL.Path = filepath
 
+	if f, err := fs.Open(filepath); err == nil {
+		if fi, err := f.Stat(); err == nil {
+			c.setFileETag(fi)
+		}
+		f.Close()
+	}
 	http.FileServer(fs).ServeHTTP(c.Writer, c.Request)
 }
 
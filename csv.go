@@ -0,0 +1,365 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ========== CSV Rendering ==========
+
+// CSV writes rows as CSV, row by row, so large exports are streamed
+// instead of built in memory. rows is a slice of structs or struct
+// pointers, or an iterator function such as iter.Seq[Product], with a
+// header row from the fields' "csv" tags; or a [][]string written as is:
+//
+//	type ProductRow struct {
+//	    SKU   string      `csv:"sku"`
+//	    Name  string      `csv:"name"`
+//	    Price goTap.Money `csv:"price"`
+//	    Notes string      `csv:"-"`
+//	}
+//
+//	c.Header("Content-Disposition", `attachment; filename="products.csv"`)
+//	c.CSV(http.StatusOK, rows)
+//
+// Fields without a tag use the field name. Times are written as RFC 3339.
+func (c *Context) CSV(code int, rows any) {
+	table, err := newTable(rows)
+	if err != nil {
+		panic(err)
+	}
+	c.Status(code)
+	c.setContentType(MIMECSV + "; charset=utf-8")
+
+	w := csv.NewWriter(c.Writer)
+	err = table.each(func(cells []reflect.Value) error {
+		record := make([]string, len(cells))
+		for i, cell := range cells {
+			record[i] = formatCell(cell)
+		}
+		return w.Write(record)
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// table iterates the rows of a CSV or XLSX export
+type table struct {
+	rows    reflect.Value
+	columns []tableColumn
+	raw     bool // [][]string rows
+}
+
+type tableColumn struct {
+	name  string
+	index []int
+}
+
+// newTable checks the type of rows
+func newTable(rows any) (*table, error) {
+	v := reflect.ValueOf(rows)
+	var elem reflect.Type
+	switch {
+	case !v.IsValid():
+		return nil, errors.New("goTap: table rows are nil")
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		elem = v.Type().Elem()
+	case v.Kind() == reflect.Func && v.Type().NumIn() == 1 && v.Type().In(0).Kind() == reflect.Func &&
+		v.Type().In(0).NumIn() == 1 && v.Type().NumOut() == 0:
+		elem = v.Type().In(0).In(0)
+	default:
+		return nil, fmt.Errorf("goTap: table rows must be a slice or an iterator, got %T", rows)
+	}
+
+	if elem == reflect.TypeOf([]string(nil)) {
+		return &table{rows: v, raw: true}, nil
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("goTap: table rows must be structs or []string, got %s", elem)
+	}
+	return &table{rows: v, columns: tableColumns(elem)}, nil
+}
+
+// tableColumns returns the exported fields of t by their "csv" tags
+func tableColumns(t reflect.Type) []tableColumn {
+	var columns []tableColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("csv"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, tableColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// each calls fn with the header, if any, and the cells of each row
+func (t *table) each(fn func(cells []reflect.Value) error) error {
+	if !t.raw {
+		header := make([]reflect.Value, len(t.columns))
+		for i, column := range t.columns {
+			header[i] = reflect.ValueOf(column.name)
+		}
+		if err := fn(header); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	emit := func(row reflect.Value) bool {
+		err = fn(t.cells(row))
+		return err == nil
+	}
+	if t.rows.Kind() == reflect.Func {
+		for row := range t.rows.Seq() {
+			if !emit(row) {
+				break
+			}
+		}
+		return err
+	}
+	for i := 0; i < t.rows.Len(); i++ {
+		if !emit(t.rows.Index(i)) {
+			break
+		}
+	}
+	return err
+}
+
+// cells returns the values of a row
+func (t *table) cells(row reflect.Value) []reflect.Value {
+	if t.raw {
+		cells := make([]reflect.Value, row.Len())
+		for i := range cells {
+			cells[i] = row.Index(i)
+		}
+		return cells
+	}
+	for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+		row = row.Elem()
+	}
+	cells := make([]reflect.Value, len(t.columns))
+	if !row.IsValid() {
+		return cells
+	}
+	for i, column := range t.columns {
+		cells[i] = row.FieldByIndex(column.index)
+	}
+	return cells
+}
+
+// formatCell formats a value for a CSV or XLSX cell, preferring its
+// MarshalText or String method; nil and zero structs are empty
+func formatCell(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.Struct && v.IsZero() {
+		// An unset time or Money
+		return ""
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+	switch m := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return m.String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ========== CSV Binding ==========
+
+// CSVRowError reports an invalid row of a CSV import
+type CSVRowError struct {
+	// Row is the line number, counting the header as line 1
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e CSVRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("row %d: %s: %s", e.Row, e.Column, e.Message)
+	}
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// CSVErrors lists the invalid rows of a CSV import
+type CSVErrors []CSVRowError
+
+func (e CSVErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, rowErr := range e {
+		messages[i] = rowErr.Error()
+	}
+	return fmt.Sprintf("%d invalid rows: %s", len(e), strings.Join(messages, "; "))
+}
+
+// ShouldBindCSV binds the rows of a CSV body, or of the first file of a
+// multipart form, into obj, a pointer to a slice of structs or struct
+// pointers. Columns are matched to fields by their "csv" tags or names,
+// ignoring case; unknown columns are ignored and empty cells leave the
+// field unset. Each row is validated like ShouldBindJSON:
+//
+//	var products []ProductRow
+//	err := c.ShouldBindCSV(&products)
+//	var rowErrs goTap.CSVErrors
+//	if errors.As(err, &rowErrs) {
+//	    c.JSON(http.StatusUnprocessableEntity, goTap.H{"imported": len(products), "errors": rowErrs})
+//	    return
+//	}
+//
+// Invalid rows are left out of the slice and reported together as
+// CSVErrors; other errors, such as malformed CSV, stop the import.
+func (c *Context) ShouldBindCSV(obj any) error {
+	if c.ContentType() != MIMEMultipartPOSTForm {
+		return decodeCSV(c.Request.Body, obj)
+	}
+	found := false
+	err := c.StreamMultipart(func(part *multipart.Part) error {
+		if found || part.FileName() == "" {
+			return nil
+		}
+		found = true
+		return decodeCSV(part, obj)
+	})
+	if err == nil && !found {
+		return errors.New("no CSV file in the form")
+	}
+	return err
+}
+
+// decodeCSV appends the rows of r to the slice obj points to
+func decodeCSV(r io.Reader, obj any) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("goTap: ShouldBindCSV requires a pointer to a slice, got %T", obj)
+	}
+	slice := ptr.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("goTap: ShouldBindCSV requires a slice of structs, got %T", obj)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]tableColumn)
+	for _, column := range tableColumns(structType) {
+		byName[strings.ToLower(column.name)] = column
+	}
+	columns := make([]*tableColumn, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if column, ok := byName[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[i] = &column
+		}
+	}
+
+	var rowErrs CSVErrors
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := reflect.New(structType)
+		valid := true
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if i >= len(columns) || columns[i] == nil || cell == "" {
+				continue
+			}
+			field := row.Elem().FieldByIndex(columns[i].index)
+			if err := setField(field, []string{cell}); err != nil {
+				rowErrs = append(rowErrs, CSVRowError{Row: line, Column: columns[i].name, Message: err.Error()})
+				valid = false
+			}
+		}
+		if valid {
+			if err := validate(row.Interface()); err != nil {
+				rowErrs = append(rowErrs, CSVRowError{Row: line, Message: err.Error()})
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, row)
+		} else {
+			slice = reflect.Append(slice, row.Elem())
+		}
+	}
+	ptr.Elem().Set(slice)
+	if len(rowErrs) > 0 {
+		return rowErrs
+	}
+	return nil
+}
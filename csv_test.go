@@ -0,0 +1,153 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type csvProduct struct {
+	SKU   string  `csv:"sku" validate:"required"`
+	Name  string  `csv:"name"`
+	Price Money   `csv:"price"`
+	Stock *int    `csv:"stock"`
+	Ratio float64 `csv:"ratio"`
+	Notes string  `csv:"-"`
+}
+
+func TestContextCSV(t *testing.T) {
+	stock := 3
+	products := []csvProduct{
+		{SKU: "A-1", Name: "Coffee, large", Price: Money{Amount: 450, Currency: "USD"}, Stock: &stock, Ratio: 0.5, Notes: "x"},
+		{SKU: "B-2", Name: "Tea"},
+	}
+	r := New()
+	r.GET("/slice", func(c *Context) { c.CSV(http.StatusOK, products) })
+	r.GET("/iter", func(c *Context) {
+		c.CSV(http.StatusOK, func(yield func(*csvProduct) bool) {
+			for i := range products {
+				if !yield(&products[i]) {
+					return
+				}
+			}
+		})
+	})
+	r.GET("/raw", func(c *Context) { c.CSV(http.StatusOK, [][]string{{"a", "b"}, {"1", "2"}}) })
+
+	want := "sku,name,price,stock,ratio\nA-1,\"Coffee, large\",USD 4.50,3,0.5\nB-2,Tea,,,0\n"
+	for _, path := range []string{"/slice", "/iter"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+			t.Errorf("%s: unexpected CSV %q (%s)", path, w.Body.String(), w.Header().Get("Content-Type"))
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/raw", nil))
+	if w.Body.String() != "a,b\n1,2\n" {
+		t.Errorf("Unexpected raw CSV %q", w.Body.String())
+	}
+}
+
+func TestShouldBindCSV(t *testing.T) {
+	body := "\ufeffSKU, Price ,stock,unknown\n" +
+		"A-1,USD 4.50,3,x\n" +
+		"B-2,4.50,1,x\n" +
+		",USD 1.00,2,x\n" +
+		"C-3,,,\n"
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/import", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", MIMECSV)
+	var products []*csvProduct
+	err := c.ShouldBindCSV(&products)
+
+	var rowErrs CSVErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 2 {
+		t.Fatalf("Expected two row errors, got %v", err)
+	}
+	if rowErrs[0].Row != 3 || rowErrs[0].Column != "price" || rowErrs[1].Row != 4 || rowErrs[1].Column != "" {
+		t.Errorf("Unexpected row errors %+v", rowErrs)
+	}
+	if len(products) != 2 || products[0].SKU != "A-1" || products[0].Price.Amount != 450 ||
+		*products[0].Stock != 3 || products[1].SKU != "C-3" || products[1].Stock != nil {
+		t.Errorf("Unexpected products %+v", products)
+	}
+
+	c.Request = httptest.NewRequest("POST", "/import", strings.NewReader("sku\n\"unterminated\n"))
+	if err := c.ShouldBindCSV(&products); err == nil || errors.As(err, &rowErrs) {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+}
+
+func TestShouldBindCSVMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("mode", "upsert")
+	fw, _ := mw.CreateFormFile("file", "products.csv")
+	io.WriteString(fw, "sku,name\nA-1,Coffee\n")
+	mw.Close()
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/import", &buf)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	var products []csvProduct
+	if err := c.ShouldBindCSV(&products); err != nil || len(products) != 1 || products[0].Name != "Coffee" {
+		t.Errorf("Unexpected result %+v %v", products, err)
+	}
+}
+
+func TestContextXLSX(t *testing.T) {
+	r := New()
+	r.GET("/export", func(c *Context) {
+		c.XLSX(http.StatusOK, []XLSXSheet{
+			{Name: "Products: <all>", Rows: []csvProduct{{SKU: "A&1", Price: Money{Amount: 100, Currency: "EUR"}, Ratio: 1.5}}},
+			{Rows: [][]string{{"total"}}},
+		})
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export", nil))
+	if w.Header().Get("Content-Type") != MIMEXLSX {
+		t.Errorf("Unexpected content type %s", w.Header().Get("Content-Type"))
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Products_ &lt;all&gt;" sheetId="1"`) ||
+		!strings.Contains(files["xl/workbook.xml"], `<sheet name="Sheet2" sheetId="2"`) {
+		t.Errorf("Unexpected workbook %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">A&amp;1</t></is></c>`) ||
+		!strings.Contains(sheet, `<c r="E2"><v>1.5</v></c>`) || !strings.Contains(sheet, `<c r="C2" t="inlineStr">`) {
+		t.Errorf("Unexpected sheet %s", sheet)
+	}
+	if !strings.Contains(files["xl/worksheets/sheet2.xml"], `<c r="A1" t="inlineStr">`) {
+		t.Errorf("Unexpected sheet %s", files["xl/worksheets/sheet2.xml"])
+	}
+
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
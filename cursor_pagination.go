@@ -0,0 +1,314 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+// or was issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorPagination is a keyset (seek) page request. Unlike offset pagination
+// its cost does not grow with the page number, so it stays fast on large,
+// append-heavy tables such as transactions.
+//
+// Rows are ordered by Field and then by Key as a unique tie-breaker.
+// Cursors are opaque base64 strings carrying the position of the first or
+// last row of a page.
+type CursorPagination struct {
+	// Field is the sort column (the BSON field name for MongoDB)
+	Field string
+	// Key is the unique tie-breaker column, e.g. "id" or "_id"
+	Key string
+	// Desc sorts newest/largest first
+	Desc bool
+	// Limit is the page size
+	Limit int
+
+	cursor     *pageCursor
+	nextCursor string
+	prevCursor string
+}
+
+// pageCursor is the decoded form of a cursor
+type pageCursor struct {
+	Field    string      `json:"f"`
+	Value    cursorValue `json:"v"`
+	Key      cursorValue `json:"k"`
+	Backward bool        `json:"b,omitempty"`
+}
+
+// cursorValue keeps the Go type of a keyset value across encoding so that
+// times and ObjectIDs compare correctly when the cursor comes back
+type cursorValue struct {
+	V any
+}
+
+func (v cursorValue) MarshalJSON() ([]byte, error) {
+	switch val := v.V.(type) {
+	case time.Time:
+		return json.Marshal(map[string]string{"t": val.Format(time.RFC3339Nano)})
+	case primitive.ObjectID:
+		return json.Marshal(map[string]string{"o": val.Hex()})
+	default:
+		return json.Marshal(val)
+	}
+}
+
+func (v *cursorValue) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch val := raw.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			v.V = i
+		} else {
+			v.V, err = val.Float64()
+			return err
+		}
+	case map[string]any:
+		if s, ok := val["t"].(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			v.V = t
+			return err
+		}
+		if s, ok := val["o"].(string); ok {
+			oid, err := primitive.ObjectIDFromHex(s)
+			v.V = oid
+			return err
+		}
+		return ErrInvalidCursor
+	default:
+		v.V = val
+	}
+	return nil
+}
+
+// NewCursorPagination reads the cursor and limit query parameters for a
+// keyset page ordered by field, using key ("id") as the tie-breaker.
+// The limit defaults to 20 and is capped at 100, or follows the Paginate
+// middleware when it is in use. A malformed cursor returns ErrInvalidCursor,
+// which handlers should report as 400.
+//
+//	p, err := goTap.NewCursorPagination(c, "created_at", true)
+//	if err != nil {
+//	    c.JSON(400, goTap.H{"error": err.Error()})
+//	    return
+//	}
+//	var orders []Order
+//	p.Apply(db).Find(&orders)
+//	orders = goTap.CursorResults(p, orders, func(o Order) (any, any) {
+//	    return o.CreatedAt, o.ID
+//	})
+//	c.JSON(200, goTap.H{"data": orders, "pagination": p.Response()})
+func NewCursorPagination(c *Context, field string, desc bool) (*CursorPagination, error) {
+	p := &CursorPagination{
+		Field: field,
+		Key:   "id",
+		Desc:  desc,
+		Limit: 20,
+	}
+
+	if pagination, ok := GetPagination(c); ok {
+		p.Limit = pagination.PerPage
+	} else if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 {
+		p.Limit = limit
+	}
+	if p.Limit > 100 {
+		p.Limit = 100
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil || cursor.Field != field {
+			return nil, ErrInvalidCursor
+		}
+		p.cursor = cursor
+	}
+	return p, nil
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &pageCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func encodeCursor(cursor *pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// backward reports whether the page is read towards the start of the list
+func (p *CursorPagination) backward() bool {
+	return p.cursor != nil && p.cursor.Backward
+}
+
+// descending reports the effective query order, which is reversed when
+// reading backward
+func (p *CursorPagination) descending() bool {
+	return p.Desc != p.backward()
+}
+
+// Apply adds the keyset condition, ordering and limit to a GORM query.
+// One extra row is fetched to detect whether another page exists.
+func (p *CursorPagination) Apply(db *gorm.DB) *gorm.DB {
+	desc := p.descending()
+	field := clause.Column{Name: p.Field}
+	key := clause.Column{Name: p.Key}
+
+	if p.cursor != nil {
+		after := func(col clause.Column, v any) clause.Expression {
+			if desc {
+				return clause.Lt{Column: col, Value: v}
+			}
+			return clause.Gt{Column: col, Value: v}
+		}
+		if p.Field == p.Key {
+			db = db.Where(after(key, p.cursor.Key.V))
+		} else {
+			db = db.Where(clause.Or(
+				after(field, p.cursor.Value.V),
+				clause.And(clause.Eq{Column: field, Value: p.cursor.Value.V}, after(key, p.cursor.Key.V)),
+			))
+		}
+	}
+
+	if p.Field != p.Key {
+		db = db.Order(clause.OrderByColumn{Column: field, Desc: desc})
+	}
+	return db.Order(clause.OrderByColumn{Column: key, Desc: desc}).Limit(p.Limit + 1)
+}
+
+// MongoFilter combines filter with the keyset condition. Set Key to "_id"
+// for MongoDB collections.
+func (p *CursorPagination) MongoFilter(filter bson.M) bson.M {
+	if p.cursor == nil {
+		return filter
+	}
+
+	op := "$gt"
+	if p.descending() {
+		op = "$lt"
+	}
+	var keyset bson.M
+	if p.Field == p.Key {
+		keyset = bson.M{p.Key: bson.M{op: p.cursor.Key.V}}
+	} else {
+		keyset = bson.M{"$or": bson.A{
+			bson.M{p.Field: bson.M{op: p.cursor.Value.V}},
+			bson.M{p.Field: p.cursor.Value.V, p.Key: bson.M{op: p.cursor.Key.V}},
+		}}
+	}
+
+	if len(filter) == 0 {
+		return keyset
+	}
+	return bson.M{"$and": bson.A{filter, keyset}}
+}
+
+// FindOptions returns MongoDB find options with the keyset sort and limit
+func (p *CursorPagination) FindOptions() *options.FindOptions {
+	dir := 1
+	if p.descending() {
+		dir = -1
+	}
+	sort := bson.D{}
+	if p.Field != p.Key {
+		sort = append(sort, bson.E{Key: p.Field, Value: dir})
+	}
+	sort = append(sort, bson.E{Key: p.Key, Value: dir})
+	return options.Find().SetSort(sort).SetLimit(int64(p.Limit + 1))
+}
+
+// CursorResults trims the extra row fetched by Apply or FindOptions, restores
+// the requested order and computes the next and previous cursors. keyFunc
+// returns the Field and Key values of a row.
+func CursorResults[T any](p *CursorPagination, rows []T, keyFunc func(T) (value, key any)) []T {
+	more := len(rows) > p.Limit
+	if more {
+		rows = rows[:p.Limit]
+	}
+	if p.backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	p.nextCursor, p.prevCursor = "", ""
+	if len(rows) == 0 {
+		return rows
+	}
+
+	position := func(row T, backward bool) string {
+		value, key := keyFunc(row)
+		return encodeCursor(&pageCursor{
+			Field:    p.Field,
+			Value:    cursorValue{value},
+			Key:      cursorValue{key},
+			Backward: backward,
+		})
+	}
+
+	// Reading forward, more rows mean a next page; any cursor means a previous one.
+	// Reading backward it is the other way round.
+	if (!p.backward() && more) || p.backward() {
+		p.nextCursor = position(rows[len(rows)-1], false)
+	}
+	if (p.backward() && more) || (!p.backward() && p.cursor != nil) {
+		p.prevCursor = position(rows[0], true)
+	}
+	return rows
+}
+
+// NextCursor returns the cursor of the following page, or "" on the last page
+func (p *CursorPagination) NextCursor() string {
+	return p.nextCursor
+}
+
+// PrevCursor returns the cursor of the preceding page, or "" on the first page
+func (p *CursorPagination) PrevCursor() string {
+	return p.prevCursor
+}
+
+// Response returns cursor pagination metadata for API response
+func (p *CursorPagination) Response() H {
+	resp := H{
+		"limit":       p.Limit,
+		"next_cursor": nil,
+		"prev_cursor": nil,
+	}
+	if p.nextCursor != "" {
+		resp["next_cursor"] = p.nextCursor
+	}
+	if p.prevCursor != "" {
+		resp["prev_cursor"] = p.prevCursor
+	}
+	return resp
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm/logger"
+)
+
+type cursorTxn struct {
+	ID        uint      `json:"id"`
+	Amount    int       `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func setupCursorRouter(t *testing.T) *Engine {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping cursor pagination tests: sqlite not available (%v)", err)
+	}
+	db.AutoMigrate(&cursorTxn{})
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Amounts contain ties so the id tie-breaker is exercised
+	for i, amount := range []int{5, 3, 5, 1, 3, 5, 2} {
+		db.Create(&cursorTxn{Amount: amount, CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+
+	r := New()
+	r.GET("/txns", func(c *Context) {
+		field := c.DefaultQuery("sort", "amount")
+		p, err := NewCursorPagination(c, field, c.Query("desc") == "1")
+		if err != nil {
+			c.JSON(400, H{"error": err.Error()})
+			return
+		}
+		var txns []cursorTxn
+		if err := p.Apply(db).Find(&txns).Error; err != nil {
+			c.JSON(500, H{"error": err.Error()})
+			return
+		}
+		txns = CursorResults(p, txns, func(t cursorTxn) (any, any) {
+			if field == "created_at" {
+				return t.CreatedAt, t.ID
+			}
+			return t.Amount, t.ID
+		})
+		c.JSON(200, H{"data": txns, "pagination": p.Response()})
+	})
+	return r
+}
+
+type cursorPage struct {
+	Data       []cursorTxn `json:"data"`
+	Pagination struct {
+		Next *string `json:"next_cursor"`
+		Prev *string `json:"prev_cursor"`
+	} `json:"pagination"`
+}
+
+func fetchCursorPage(t *testing.T, r *Engine, query string) cursorPage {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/txns?"+query, nil)
+	r.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 for %q, got %d: %s", query, w.Code, w.Body.String())
+	}
+	var page cursorPage
+	json.Unmarshal(w.Body.Bytes(), &page)
+	return page
+}
+
+func cursorIDs(txns []cursorTxn) string {
+	ids := ""
+	for _, txn := range txns {
+		ids += fmt.Sprintf("%d,", txn.ID)
+	}
+	return ids
+}
+
+func TestCursorPaginationForwardAndBackward(t *testing.T) {
+	r := setupCursorRouter(t)
+
+	// amount desc, id desc: 6(5) 3(5) 1(5) 5(3) 2(3) 7(2) 4(1)
+	want := []string{"6,3,1,", "5,2,7,", "4,"}
+	var pages []cursorPage
+	query := "limit=3&desc=1"
+	for i := 0; ; i++ {
+		page := fetchCursorPage(t, r, query)
+		pages = append(pages, page)
+		if got := cursorIDs(page.Data); i >= len(want) || got != want[i] {
+			t.Fatalf("Page %d: expected %v, got %s", i, want, got)
+		}
+		if page.Pagination.Next == nil {
+			break
+		}
+		query = "limit=3&desc=1&cursor=" + url.QueryEscape(*page.Pagination.Next)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+	if pages[0].Pagination.Prev != nil {
+		t.Error("Expected no prev_cursor on the first page")
+	}
+
+	// Walking back from the last page returns the same pages
+	back := fetchCursorPage(t, r, "limit=3&desc=1&cursor="+url.QueryEscape(*pages[2].Pagination.Prev))
+	if got := cursorIDs(back.Data); got != want[1] {
+		t.Errorf("Expected previous page %s, got %s", want[1], got)
+	}
+	back = fetchCursorPage(t, r, "limit=3&desc=1&cursor="+url.QueryEscape(*back.Pagination.Prev))
+	if got := cursorIDs(back.Data); got != want[0] {
+		t.Errorf("Expected first page %s, got %s", want[0], got)
+	}
+	if back.Pagination.Prev != nil || back.Pagination.Next == nil {
+		t.Errorf("Expected only next_cursor on the first page, got %+v", back.Pagination)
+	}
+}
+
+func TestCursorPaginationTimeField(t *testing.T) {
+	r := setupCursorRouter(t)
+
+	first := fetchCursorPage(t, r, "sort=created_at&limit=4")
+	next := fetchCursorPage(t, r, "sort=created_at&limit=4&cursor="+url.QueryEscape(*first.Pagination.Next))
+	if got := cursorIDs(first.Data) + cursorIDs(next.Data); got != "1,2,3,4,5,6,7," {
+		t.Errorf("Expected chronological order, got %s", got)
+	}
+}
+
+func TestCursorPaginationInvalidCursor(t *testing.T) {
+	r := setupCursorRouter(t)
+	page := fetchCursorPage(t, r, "limit=2")
+
+	for _, query := range []string{
+		"cursor=not-base64!",
+		"sort=created_at&cursor=" + url.QueryEscape(*page.Pagination.Next),
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/txns?"+query, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestCursorPaginationMongo(t *testing.T) {
+	oid := primitive.NewObjectID()
+	p := &CursorPagination{Field: "total", Key: "_id", Limit: 10}
+	p.cursor, _ = decodeCursor(encodeCursor(&pageCursor{Field: "total", Value: cursorValue{int64(30)}, Key: cursorValue{oid}}))
+
+	filter := p.MongoFilter(bson.M{"status": "paid"})
+	and, ok := filter["$and"].(bson.A)
+	if !ok || len(and) != 2 {
+		t.Fatalf("Expected $and filter, got %v", filter)
+	}
+	or := and[1].(bson.M)["$or"].(bson.A)
+	if tie := or[1].(bson.M); tie["total"] != int64(30) || tie["_id"].(bson.M)["$gt"] != oid {
+		t.Errorf("Expected typed tie-breaker condition, got %v", tie)
+	}
+
+	opts := p.FindOptions()
+	if *opts.Limit != 11 {
+		t.Errorf("Expected limit+1, got %d", *opts.Limit)
+	}
+}
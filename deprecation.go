@@ -0,0 +1,264 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxDeprecatedCallers bounds the callers tracked per route; further
+// callers are counted under "other"
+const maxDeprecatedCallers = 1000
+
+// DeprecationConfig holds configuration for deprecated routes
+type DeprecationConfig struct {
+	// Sunset is when the route will be removed, sent as the Sunset header
+	Sunset time.Time
+
+	// Link points to migration documentation, sent as a Link header with
+	// rel="deprecation"
+	Link string
+
+	// Since is when the route was deprecated, sent as the Deprecation header
+	// Default: the header value is "true"
+	Since time.Time
+
+	// CallerFunc identifies the client in usage logs and reports
+	// Default: "user_id" or "user" from the context, else client IP and User-Agent
+	CallerFunc func(c *Context) string
+
+	// Output receives a line the first time each caller uses a deprecated route
+	// Default: DefaultWriter
+	Output io.Writer
+}
+
+// DeprecatedRoute reports usage of a deprecated route
+type DeprecatedRoute struct {
+	Method   string             `json:"method"`
+	Path     string             `json:"path"`
+	Sunset   time.Time          `json:"sunset"`
+	Link     string             `json:"link,omitempty"`
+	Calls    int64              `json:"calls"`
+	LastCall *time.Time         `json:"last_call,omitempty"`
+	Callers  []DeprecatedCaller `json:"callers"`
+}
+
+// DeprecatedCaller reports usage of a deprecated route by one caller
+type DeprecatedCaller struct {
+	Caller   string    `json:"caller"`
+	Calls    int64     `json:"calls"`
+	LastCall time.Time `json:"last_call"`
+}
+
+// deprecationRegistry tracks deprecated routes and their usage per engine
+type deprecationRegistry struct {
+	mu     sync.Mutex
+	routes map[string]*deprecatedUsage
+}
+
+type deprecatedUsage struct {
+	route   DeprecatedRoute
+	callers map[string]*DeprecatedCaller
+}
+
+// Deprecated returns a middleware marking a route as deprecated. Responses
+// carry Deprecation, Sunset and Link headers and every call is recorded for
+// Engine.DeprecationReport:
+//
+//	r.GET("/v1/sales", goTap.Deprecated(sunset, "https://docs.example.com/v2"), legacySales)
+func Deprecated(sunset time.Time, link string) HandlerFunc {
+	return DeprecatedWithConfig(DeprecationConfig{Sunset: sunset, Link: link})
+}
+
+// DeprecatedWithConfig returns a Deprecated middleware with config
+func DeprecatedWithConfig(config DeprecationConfig) HandlerFunc {
+	if config.CallerFunc == nil {
+		config.CallerFunc = defaultDeprecationCaller
+	}
+	if config.Output == nil {
+		config.Output = DefaultWriter
+	}
+
+	deprecation := "true"
+	if !config.Since.IsZero() {
+		deprecation = "@" + strconv.FormatInt(config.Since.Unix(), 10)
+	}
+	sunset := ""
+	if !config.Sunset.IsZero() {
+		sunset = config.Sunset.UTC().Format(http.TimeFormat)
+	}
+	link := ""
+	if config.Link != "" {
+		link = "<" + config.Link + `>; rel="deprecation"`
+	}
+
+	return func(c *Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if link != "" {
+			c.Writer.Header().Add("Link", link)
+		}
+
+		if c.engine != nil {
+			caller := config.CallerFunc(c)
+			if first := c.engine.deprecations.record(c.Request.Method, c.FullPath(), config, caller); first {
+				fmt.Fprintf(config.Output, "[goTap] [DEPRECATED] %s %s called by %q (sunset %s)\n",
+					c.Request.Method, c.FullPath(), caller, sunset)
+			}
+		}
+		c.Next()
+	}
+}
+
+// Deprecated returns a group whose routes are marked deprecated. Routes
+// show up in Engine.DeprecationReport as soon as they are registered:
+//
+//	legacy := r.Group("/v1").Deprecated(sunset, "https://docs.example.com/v2")
+//	legacy.GET("/sales", legacySales)
+func (group *RouterGroup) Deprecated(sunset time.Time, link string) *RouterGroup {
+	return group.DeprecatedWithConfig(DeprecationConfig{Sunset: sunset, Link: link})
+}
+
+// DeprecatedWithConfig returns a group whose routes are marked deprecated with config
+func (group *RouterGroup) DeprecatedWithConfig(config DeprecationConfig) *RouterGroup {
+	child := group.Group("", DeprecatedWithConfig(config))
+	child.deprecation = &config
+	return child
+}
+
+// DeprecationReport returns usage of all deprecated routes, most recently
+// used first. Routes that were never called have no last call.
+func (engine *Engine) DeprecationReport() []DeprecatedRoute {
+	return engine.deprecations.report()
+}
+
+// DeprecationReportHandler returns a handler serving Engine.DeprecationReport
+// as JSON, for an admin endpoint:
+//
+//	admin.GET("/deprecations", goTap.DeprecationReportHandler())
+func DeprecationReportHandler() HandlerFunc {
+	return func(c *Context) {
+		routes := []DeprecatedRoute{}
+		if c.engine != nil {
+			routes = c.engine.DeprecationReport()
+		}
+		c.JSON(http.StatusOK, H{"routes": routes})
+	}
+}
+
+func defaultDeprecationCaller(c *Context) string {
+	for _, key := range []string{"user_id", "user"} {
+		if v, ok := c.Get(key); ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return c.ClientIP() + " " + c.Request.UserAgent()
+}
+
+// register adds a route so it is reported before its first call
+func (d *deprecationRegistry) register(method, path string, config DeprecationConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.usage(method, path, config)
+}
+
+// record counts a call and reports whether it is the caller's first
+func (d *deprecationRegistry) record(method, path string, config DeprecationConfig, caller string) bool {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	u := d.usage(method, path, config)
+	u.route.Calls++
+	u.route.LastCall = &now
+
+	c, ok := u.callers[caller]
+	if !ok {
+		if len(u.callers) >= maxDeprecatedCallers {
+			caller = "other"
+			c = u.callers[caller]
+		}
+		if c == nil {
+			c = &DeprecatedCaller{Caller: caller}
+			u.callers[caller] = c
+		}
+	}
+	c.Calls++
+	c.LastCall = now
+	return c.Calls == 1
+}
+
+func (d *deprecationRegistry) usage(method, path string, config DeprecationConfig) *deprecatedUsage {
+	if d.routes == nil {
+		d.routes = make(map[string]*deprecatedUsage)
+	}
+	key := method + " " + path
+	u, ok := d.routes[key]
+	if !ok {
+		u = &deprecatedUsage{
+			route: DeprecatedRoute{
+				Method: method,
+				Path:   path,
+				Sunset: config.Sunset,
+				Link:   config.Link,
+			},
+			callers: make(map[string]*DeprecatedCaller),
+		}
+		d.routes[key] = u
+	}
+	return u
+}
+
+// lookup returns the deprecated route registered for method and path
+func (d *deprecationRegistry) lookup(method, path string) (DeprecatedRoute, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	u, ok := d.routes[method+" "+path]
+	if !ok {
+		return DeprecatedRoute{}, false
+	}
+	return u.route, true
+}
+
+func (d *deprecationRegistry) report() []DeprecatedRoute {
+	d.mu.Lock()
+	routes := make([]DeprecatedRoute, 0, len(d.routes))
+	for _, u := range d.routes {
+		route := u.route
+		route.Callers = make([]DeprecatedCaller, 0, len(u.callers))
+		for _, c := range u.callers {
+			route.Callers = append(route.Callers, *c)
+		}
+		sort.Slice(route.Callers, func(i, j int) bool {
+			if route.Callers[i].Calls != route.Callers[j].Calls {
+				return route.Callers[i].Calls > route.Callers[j].Calls
+			}
+			return route.Callers[i].Caller < route.Callers[j].Caller
+		})
+		routes = append(routes, route)
+	}
+	d.mu.Unlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		if a, b := routes[i].LastCall, routes[j].LastCall; (a == nil) != (b == nil) {
+			return a != nil
+		} else if a != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
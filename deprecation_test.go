@@ -0,0 +1,110 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeprecatedHeaders(t *testing.T) {
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var log bytes.Buffer
+
+	r := New()
+	r.GET("/v1/sales", DeprecatedWithConfig(DeprecationConfig{
+		Sunset: sunset,
+		Since:  since,
+		Link:   "https://docs.example.com/v2/sales",
+		Output: &log,
+	}), func(c *Context) { c.String(200, "ok") })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/sales", nil)
+	req.Header.Set("User-Agent", "POS-Firmware/1.2")
+	req.RemoteAddr = "10.0.0.7:1234"
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("Unexpected Deprecation header %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Tue, 30 Jun 2026 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset header %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `<https://docs.example.com/v2/sales>; rel="deprecation"` {
+		t.Errorf("Unexpected Link header %q", got)
+	}
+	if !strings.Contains(log.String(), `GET /v1/sales called by "10.0.0.7 POS-Firmware/1.2"`) {
+		t.Errorf("Expected usage log with caller, got %q", log.String())
+	}
+
+	// Only the first call per caller is logged
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if strings.Count(log.String(), "\n") != 1 {
+		t.Errorf("Expected a single log line, got %q", log.String())
+	}
+}
+
+func TestDeprecatedGroupReport(t *testing.T) {
+	defer func(w io.Writer) { DefaultWriter = w }(DefaultWriter)
+	DefaultWriter = &bytes.Buffer{}
+
+	r := New()
+	r.Use(func(c *Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			c.Set("user_id", id)
+		}
+		c.Next()
+	})
+	legacy := r.Group("/v1").Deprecated(time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC), "")
+	legacy.GET("/sales", func(c *Context) { c.String(200, "ok") })
+	legacy.Group("/stock").POST("/adjust", func(c *Context) { c.String(200, "ok") })
+	r.GET("/v2/sales", func(c *Context) { c.String(200, "ok") })
+	r.GET("/admin/deprecations", DeprecationReportHandler())
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		req, _ := http.NewRequest("GET", "/v1/sales", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Header().Get("Deprecation") != "true" {
+			t.Errorf("Expected Deprecation header on grouped route")
+		}
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/sales", nil)
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected no Deprecation header outside the group")
+	}
+
+	report := r.DeprecationReport()
+	if len(report) != 2 {
+		t.Fatalf("Expected 2 deprecated routes, got %+v", report)
+	}
+	sales := report[0]
+	if sales.Path != "/v1/sales" || sales.Calls != 3 || sales.LastCall == nil {
+		t.Errorf("Unexpected sales usage %+v", sales)
+	}
+	if len(sales.Callers) != 2 || sales.Callers[0].Caller != "alice" || sales.Callers[0].Calls != 2 {
+		t.Errorf("Unexpected callers %+v", sales.Callers)
+	}
+	if adjust := report[1]; adjust.Method != "POST" || adjust.Path != "/v1/stock/adjust" || adjust.Calls != 0 {
+		t.Errorf("Expected unused route reported, got %+v", adjust)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/deprecations", nil)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"caller":"alice","calls":2`) {
+		t.Errorf("Unexpected report body %s", w.Body.String())
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SurrogateKeysKey is the context key holding the response's surrogate keys
+const SurrogateKeysKey = "gotap.surrogate_keys"
+
+// ModelSurrogateKey returns the surrogate key of a model record, e.g.
+// "product:42", or of the whole model, e.g. "product", when id is nil.
+// PurgeOnModelEvents purges both when a record changes, so detail pages
+// tag themselves with the record key and list pages with the model key.
+func ModelSurrogateKey(model string, id any) string {
+	if id == nil {
+		return model
+	}
+	return fmt.Sprintf("%s:%v", model, id)
+}
+
+// SurrogateKeyConfig holds configuration for SurrogateKeysWithConfig
+type SurrogateKeyConfig struct {
+	// Keys tag every response of the route. "{name}" is replaced by the
+	// route parameter, e.g. "product:{id}".
+	Keys []string
+
+	// Header carries the keys: "Surrogate-Key" for Fastly, "Cache-Tag" for
+	// Cloudflare or "xkey" for Varnish
+	// Default: "Surrogate-Key"
+	Header string
+
+	// Separator joins the keys
+	// Default: "," for Cache-Tag, otherwise a space
+	Separator string
+}
+
+// surrogateKeys collects a response's keys
+type surrogateKeys struct {
+	header    string
+	separator string
+	keys      []string
+}
+
+// SurrogateKeys returns a middleware tagging responses with surrogate keys
+// in the Surrogate-Key header, so a CDN can purge them by key:
+//
+//	r.GET("/products/:id", goTap.SurrogateKeys("product", "product:{id}"), showProduct)
+func SurrogateKeys(keys ...string) HandlerFunc {
+	return SurrogateKeysWithConfig(SurrogateKeyConfig{Keys: keys})
+}
+
+// SurrogateKeysWithConfig returns a SurrogateKeys middleware with config
+func SurrogateKeysWithConfig(config SurrogateKeyConfig) HandlerFunc {
+	if config.Header == "" {
+		config.Header = "Surrogate-Key"
+	}
+	if config.Separator == "" {
+		config.Separator = " "
+		if http.CanonicalHeaderKey(config.Header) == "Cache-Tag" {
+			config.Separator = ","
+		}
+	}
+
+	return func(c *Context) {
+		c.Set(SurrogateKeysKey, &surrogateKeys{header: config.Header, separator: config.Separator})
+		keys := make([]string, len(config.Keys))
+		for i, key := range config.Keys {
+			keys[i] = expandRouteParams(c, key)
+		}
+		c.AddSurrogateKeys(keys...)
+		c.Next()
+	}
+}
+
+// expandRouteParams replaces "{name}" in s with the route parameter
+func expandRouteParams(c *Context, s string) string {
+	for _, p := range c.Params {
+		s = strings.ReplaceAll(s, "{"+p.Key+"}", strings.TrimPrefix(p.Value, "/"))
+	}
+	return s
+}
+
+// AddSurrogateKeys tags the response with surrogate keys, e.g. for the
+// records a handler loaded:
+//
+//	for _, p := range products {
+//	    c.AddSurrogateKeys(goTap.ModelSurrogateKey("product", p.ID))
+//	}
+//
+// The header is the one configured by the route's SurrogateKeys middleware,
+// or Surrogate-Key. Keys must be set before the response is written.
+func (c *Context) AddSurrogateKeys(keys ...string) {
+	value, _ := c.Get(SurrogateKeysKey)
+	sk, _ := value.(*surrogateKeys)
+	if sk == nil {
+		sk = &surrogateKeys{header: "Surrogate-Key", separator: " "}
+		c.Set(SurrogateKeysKey, sk)
+	}
+	for _, key := range keys {
+		if key != "" && !containsString(sk.keys, key) {
+			sk.keys = append(sk.keys, key)
+		}
+	}
+	if len(sk.keys) > 0 {
+		c.Header(sk.header, strings.Join(sk.keys, sk.separator))
+	}
+}
+
+// EdgePurger invalidates CDN-cached responses by surrogate key
+type EdgePurger interface {
+	Purge(ctx context.Context, keys ...string) error
+}
+
+// FastlyPurger purges keys through the Fastly API
+type FastlyPurger struct {
+	ServiceID string
+	Token     string
+
+	// Soft marks content stale instead of removing it
+	Soft bool
+
+	// Endpoint is the API base URL
+	// Default: "https://api.fastly.com"
+	Endpoint string
+
+	// Client sends the requests
+	// Default: a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Purge implements EdgePurger
+func (p *FastlyPurger) Purge(ctx context.Context, keys ...string) error {
+	// Fastly accepts up to 256 keys per request
+	return purgeBatches(keys, 256, func(batch []string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+			endpointOr(p.Endpoint, "https://api.fastly.com")+"/service/"+p.ServiceID+"/purge", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Fastly-Key", p.Token)
+		req.Header.Set("Surrogate-Key", strings.Join(batch, " "))
+		if p.Soft {
+			req.Header.Set("Fastly-Soft-Purge", "1")
+		}
+		return sendPurge(p.Client, req, "fastly")
+	})
+}
+
+// CloudflarePurger purges cache tags through the Cloudflare API; responses
+// are tagged with SurrogateKeysWithConfig and Header "Cache-Tag"
+type CloudflarePurger struct {
+	ZoneID string
+	Token  string
+
+	// Endpoint is the API base URL
+	// Default: "https://api.cloudflare.com/client/v4"
+	Endpoint string
+
+	// Client sends the requests
+	// Default: a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Purge implements EdgePurger
+func (p *CloudflarePurger) Purge(ctx context.Context, keys ...string) error {
+	return purgeBatches(keys, 30, func(batch []string) error {
+		body, _ := json.Marshal(map[string][]string{"tags": batch})
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+			endpointOr(p.Endpoint, "https://api.cloudflare.com/client/v4")+"/zones/"+p.ZoneID+"/purge_cache", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+		req.Header.Set("Content-Type", "application/json")
+		return sendPurge(p.Client, req, "cloudflare")
+	})
+}
+
+// VarnishPurger purges keys from Varnish servers with the xkey module,
+// sending a PURGE request with the keys in a header. Responses are tagged
+// with SurrogateKeysWithConfig and Header "xkey". The VCL must handle the
+// method, e.g. with xkey.purge(req.http.xkey-purge).
+type VarnishPurger struct {
+	// URLs of the Varnish servers
+	URLs []string
+
+	// Header carries the keys
+	// Default: "xkey-purge"
+	Header string
+
+	// Client sends the requests
+	// Default: a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Purge implements EdgePurger
+func (p *VarnishPurger) Purge(ctx context.Context, keys ...string) error {
+	header := p.Header
+	if header == "" {
+		header = "xkey-purge"
+	}
+	return purgeBatches(keys, 100, func(batch []string) error {
+		for _, url := range p.URLs {
+			req, err := http.NewRequestWithContext(ctx, "PURGE", url, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set(header, strings.Join(batch, " "))
+			if err := sendPurge(p.Client, req, "varnish"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var defaultPurgeClient = &http.Client{Timeout: 10 * time.Second}
+
+func endpointOr(endpoint, fallback string) string {
+	if endpoint == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+func purgeBatches(keys []string, size int, purge func([]string) error) error {
+	for len(keys) > 0 {
+		n := min(size, len(keys))
+		if err := purge(keys[:n]); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func sendPurge(client *http.Client, req *http.Request, cdn string) error {
+	if client == nil {
+		client = defaultPurgeClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s purge: %w", cdn, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s purge: %s: %s", cdn, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// EdgePurgeConfig holds configuration for PurgeOnModelEvents
+type EdgePurgeConfig struct {
+	// Purger invalidates the keys
+	// Required.
+	Purger EdgePurger
+
+	// Models limits purging to these model names, e.g. "product"
+	// Default: all models publishing events
+	Models []string
+
+	// KeyFunc returns the keys to purge for an event
+	// Default: the model key and the record key, see ModelSurrogateKey
+	KeyFunc func(event ModelEvent) []string
+
+	// Timeout bounds each purge
+	// Default: 10 seconds
+	Timeout time.Duration
+}
+
+// PurgeOnModelEvents purges surrogate keys when models change, so CDN
+// cached pages update as soon as an admin edits a record:
+//
+//	goTap.RegisterModelEvents(db, r.Events(), &Product{})
+//	goTap.PurgeOnModelEvents(r.Events(), goTap.EdgePurgeConfig{
+//	    Purger: &goTap.FastlyPurger{ServiceID: id, Token: token},
+//	})
+//
+// Purges run in the background; failures are logged.
+func PurgeOnModelEvents(bus *EventBus, config EdgePurgeConfig) (unsubscribe func()) {
+	if config.Purger == nil {
+		panic("goTap: PurgeOnModelEvents requires a Purger")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(event ModelEvent) []string {
+			keys := []string{ModelSurrogateKey(event.Model, nil)}
+			if event.ID != nil {
+				keys = append(keys, ModelSurrogateKey(event.Model, event.ID))
+			}
+			return keys
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	handler := func(ctx context.Context, e Event) {
+		event, ok := e.Payload.(ModelEvent)
+		if !ok {
+			return
+		}
+		keys := config.KeyFunc(event)
+		if len(keys) == 0 {
+			return
+		}
+		// The purge outlives the request that changed the record
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.Timeout)
+		go func() {
+			defer cancel()
+			if err := config.Purger.Purge(ctx, keys...); err != nil {
+				debugPrint("[WARNING] edge purge of %v failed: %v", keys, err)
+			}
+		}()
+	}
+
+	patterns := []string{"*"}
+	if len(config.Models) > 0 {
+		patterns = patterns[:0]
+		for _, model := range config.Models {
+			patterns = append(patterns, model+".*")
+		}
+	}
+	var unsubscribes []func()
+	for _, pattern := range patterns {
+		unsubscribes = append(unsubscribes, bus.Subscribe(pattern, handler))
+	}
+	return func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}
+}
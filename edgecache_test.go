@@ -0,0 +1,143 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSurrogateKeys(t *testing.T) {
+	r := New()
+	r.GET("/products/:id", SurrogateKeys("product", "product:{id}"), func(c *Context) {
+		c.AddSurrogateKeys(ModelSurrogateKey("category", 3), "product")
+		c.String(200, "ok")
+	})
+	r.GET("/tags/:id", SurrogateKeysWithConfig(SurrogateKeyConfig{Header: "Cache-Tag", Keys: []string{"tag:{id}"}}), func(c *Context) {
+		c.AddSurrogateKeys("tags")
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/products/42", nil))
+	if got := w.Header().Get("Surrogate-Key"); got != "product product:42 category:3" {
+		t.Errorf("Unexpected Surrogate-Key %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/tags/7", nil))
+	if got := w.Header().Get("Cache-Tag"); got != "tag:7,tags" {
+		t.Errorf("Unexpected Cache-Tag %q", got)
+	}
+}
+
+type purgeRequest struct {
+	method, path, body string
+	header             http.Header
+}
+
+func purgeServer(t *testing.T, requests *[]purgeRequest) *httptest.Server {
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		*requests = append(*requests, purgeRequest{r.Method, r.URL.Path, string(body), r.Header})
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "denied") {
+			http.Error(w, "bad token", http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEdgePurgers(t *testing.T) {
+	var requests []purgeRequest
+	srv := purgeServer(t, &requests)
+	ctx := context.Background()
+
+	fastly := &FastlyPurger{ServiceID: "svc", Token: "fk", Soft: true, Endpoint: srv.URL}
+	if err := fastly.Purge(ctx, "product", "product:42"); err != nil {
+		t.Fatal(err)
+	}
+	req := requests[0]
+	if req.method != "POST" || req.path != "/service/svc/purge" || req.header.Get("Surrogate-Key") != "product product:42" ||
+		req.header.Get("Fastly-Key") != "fk" || req.header.Get("Fastly-Soft-Purge") != "1" {
+		t.Errorf("Unexpected Fastly request %+v", req)
+	}
+
+	keys := make([]string, 31)
+	for i := range keys {
+		keys[i] = ModelSurrogateKey("product", i)
+	}
+	cloudflare := &CloudflarePurger{ZoneID: "zone", Token: "cf", Endpoint: srv.URL}
+	if err := cloudflare.Purge(ctx, keys...); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || requests[1].path != "/zones/zone/purge_cache" ||
+		!strings.HasPrefix(requests[1].body, `{"tags":["product:0",`) || requests[2].body != `{"tags":["product:30"]}` ||
+		requests[1].header.Get("Authorization") != "Bearer cf" {
+		t.Errorf("Expected Cloudflare purges in batches of 30, got %+v", requests[1:])
+	}
+
+	varnish := &VarnishPurger{URLs: []string{srv.URL + "/a", srv.URL + "/b"}}
+	if err := varnish.Purge(ctx, "product:42"); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 5 || requests[3].method != "PURGE" || requests[4].path != "/b" || requests[4].header.Get("xkey-purge") != "product:42" {
+		t.Errorf("Unexpected Varnish requests %+v", requests[3:])
+	}
+
+	denied := &FastlyPurger{ServiceID: "denied", Endpoint: srv.URL}
+	if err := denied.Purge(ctx, "product"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected purge error, got %v", err)
+	}
+}
+
+type recordingPurger struct {
+	mu   sync.Mutex
+	keys [][]string
+}
+
+func (p *recordingPurger) Purge(ctx context.Context, keys ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, keys)
+	return nil
+}
+
+func (p *recordingPurger) purged() [][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]string(nil), p.keys...)
+}
+
+func TestPurgeOnModelEvents(t *testing.T) {
+	bus := NewEventBus()
+	purger := &recordingPurger{}
+	unsubscribe := PurgeOnModelEvents(bus, EdgePurgeConfig{Purger: purger, Models: []string{"product"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Publish(ctx, "product.updated", ModelEvent{Model: "product", Action: ModelUpdated, ID: uint(42)})
+	cancel() // the request ends before the purge runs
+	bus.Publish(context.Background(), "order.created", ModelEvent{Model: "order", Action: ModelCreated, ID: 1})
+
+	waitFor(t, func() bool { return len(purger.purged()) == 1 })
+	if got := purger.purged()[0]; !reflect.DeepEqual(got, []string{"product", "product:42"}) {
+		t.Errorf("Unexpected purged keys %v", got)
+	}
+
+	unsubscribe()
+	bus.Publish(context.Background(), "product.deleted", ModelEvent{Model: "product", Action: ModelDeleted, ID: 42})
+	if len(purger.purged()) != 1 {
+		t.Error("Expected no purge after unsubscribing")
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// Encryption errors
+var (
+	ErrNoKeyring     = errors.New("no encryption keyring configured")
+	ErrUnknownKey    = errors.New("unknown encryption key")
+	ErrNotEncrypted  = errors.New("value is not encrypted")
+	ErrDecryptFailed = errors.New("decryption failed")
+)
+
+// encryptedPrefix marks values encrypted by a Keyring
+const encryptedPrefix = "enc:v1:"
+
+// Keyring encrypts with AES-GCM under its primary key and decrypts with any
+// of its keys. Each ciphertext records the ID of its key, so keys rotate by
+// adding a new primary key and keeping the old ones until every value is
+// written again.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring returns a keyring encrypting with the key primary. Keys are
+// 16, 24 or 32 bytes for AES-128, AES-192 or AES-256.
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid encryption key ID %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+	return k, nil
+}
+
+// ParseKeyring returns a keyring from "id:base64key" pairs separated by
+// commas, the first being the primary key, e.g. from an environment
+// variable: "2025-06:q3Jb...,2024-11:7Hd0..."
+func ParseKeyring(spec string) (*Keyring, error) {
+	keys := map[string][]byte{}
+	var primary string
+	for _, pair := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, errors.New("encryption keys must be id:base64key pairs")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", id, err)
+		}
+		if primary == "" {
+			primary = id
+		}
+		keys[id] = key
+	}
+	return NewKeyring(primary, keys)
+}
+
+// Encrypt returns plaintext encrypted under the primary key
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.primary))
+	return encryptedPrefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value returned by Encrypt
+func (k *Keyring) Decrypt(ciphertext string) ([]byte, error) {
+	id, sealed, err := splitCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecryptFailed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation reports whether ciphertext was encrypted under a key other
+// than the primary key
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	id, _, err := splitCiphertext(ciphertext)
+	return err == nil && id != k.primary
+}
+
+func splitCiphertext(ciphertext string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(ciphertext, encryptedPrefix)
+	if !ok {
+		return "", nil, ErrNotEncrypted
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", nil, ErrNotEncrypted
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, ErrNotEncrypted
+	}
+	return id, sealed, nil
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetKeyring sets the keyring of Encrypted values and the "encrypted" GORM
+// serializer
+func SetKeyring(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+func currentKeyring() (*Keyring, error) {
+	if k := defaultKeyring.Load(); k != nil {
+		return k, nil
+	}
+	return nil, ErrNoKeyring
+}
+
+// Encrypted holds a value stored encrypted in the database, with the
+// keyring set by SetKeyring. The value is plain in memory and in JSON;
+// combine it with a masked type to hide it in responses too:
+//
+//	type Customer struct {
+//	    ID        uint
+//	    Email     goTap.Encrypted[goTap.MaskedEmail]
+//	    CardToken goTap.Encrypted[string] `json:"-"`
+//	}
+//
+//	customer.Email = goTap.Encrypt(goTap.MaskedEmail("ann@example.com"))
+//
+// It prints as "[encrypted]" so values don't leak into logs.
+type Encrypted[T any] struct {
+	V T
+}
+
+// Encrypt returns v as an Encrypted value
+func Encrypt[T any](v T) Encrypted[T] {
+	return Encrypted[T]{V: v}
+}
+
+// Value implements driver.Valuer
+func (e Encrypted[T]) Value() (driver.Value, error) {
+	k, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+	data, err := encryptionPayload(e.V)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(data)
+}
+
+// Scan implements sql.Scanner
+func (e *Encrypted[T]) Scan(src any) error {
+	var ciphertext string
+	switch src := src.(type) {
+	case nil:
+		var zero T
+		e.V = zero
+		return nil
+	case string:
+		ciphertext = src
+	case []byte:
+		ciphertext = string(src)
+	default:
+		return fmt.Errorf("cannot scan %T into an encrypted value", src)
+	}
+	k, err := currentKeyring()
+	if err != nil {
+		return err
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, &e.V)
+}
+
+// GormDataType stores encrypted values as text
+func (Encrypted[T]) GormDataType() string {
+	return "text"
+}
+
+// MarshalJSON encodes the plain value
+func (e Encrypted[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.V)
+}
+
+// UnmarshalJSON decodes the plain value
+func (e *Encrypted[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &e.V)
+}
+
+// String hides the value
+func (e Encrypted[T]) String() string {
+	return "[encrypted]"
+}
+
+// GoString hides the value from %#v
+func (e Encrypted[T]) GoString() string {
+	return "[encrypted]"
+}
+
+// EncryptedSerializer is the GORM serializer "encrypted", which encrypts
+// fields of any type with the keyring set by SetKeyring:
+//
+//	type Customer struct {
+//	    Email string `gorm:"serializer:encrypted"`
+//	}
+type EncryptedSerializer struct{}
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// Scan implements schema.SerializerInterface
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var ciphertext string
+		switch v := dbValue.(type) {
+		case string:
+			ciphertext = v
+		case []byte:
+			ciphertext = string(v)
+		default:
+			return fmt.Errorf("cannot scan %T into encrypted field %s", dbValue, field.Name)
+		}
+		k, err := currentKeyring()
+		if err != nil {
+			return err
+		}
+		plaintext, err := k.Decrypt(ciphertext)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err := json.Unmarshal(plaintext, fieldValue.Interface()); err != nil {
+			return err
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value implements schema.SerializerInterface
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	k, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+	data, err := encryptionPayload(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(data)
+}
+
+// encryptionPayload encodes v as JSON, storing masked types unmasked
+func encryptionPayload(v any) ([]byte, error) {
+	if m, ok := v.(interface{ unmasked() string }); ok {
+		return json.Marshal(m.unmasked())
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+func testKeyring(t *testing.T, primary string, ids ...string) *Keyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id[:1]), 32)
+	}
+	k, err := NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeyring(t *testing.T) {
+	old := testKeyring(t, "k1", "k1")
+	ciphertext, err := old.Encrypt([]byte("4111111111111111"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ciphertext, "enc:v1:k1:") || strings.Contains(ciphertext, "4111") {
+		t.Errorf("Unexpected ciphertext %q", ciphertext)
+	}
+
+	rotated := testKeyring(t, "k2", "k1", "k2")
+	plaintext, err := rotated.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "4111111111111111" {
+		t.Errorf("Expected the old key to decrypt, got %q %v", plaintext, err)
+	}
+	if !rotated.NeedsRotation(ciphertext) || old.NeedsRotation(ciphertext) {
+		t.Error("Expected values of the old key to need rotation")
+	}
+	fresh, _ := rotated.Encrypt(plaintext)
+	if !strings.HasPrefix(fresh, "enc:v1:k2:") {
+		t.Errorf("Expected the primary key to encrypt, got %q", fresh)
+	}
+
+	// The key ID is authenticated, so a value can't be moved to another key
+	tampered := strings.Replace(ciphertext, ":k1:", ":k2:", 1)
+	if _, err := rotated.Decrypt(tampered); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("Expected ErrDecryptFailed, got %v", err)
+	}
+	if _, err := old.Decrypt(fresh); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+	if _, err := old.Decrypt("plain"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Expected ErrNotEncrypted, got %v", err)
+	}
+
+	spec := "new:" + base64.StdEncoding.EncodeToString(make([]byte, 32)) + ", old:" + base64.StdEncoding.EncodeToString(make([]byte, 16))
+	if k, err := ParseKeyring(spec); err != nil || k.primary != "new" || len(k.keys) != 2 {
+		t.Errorf("Unexpected parsed keyring %v %v", k, err)
+	}
+	if _, err := ParseKeyring("bad:" + base64.StdEncoding.EncodeToString(make([]byte, 7))); err == nil {
+		t.Error("Expected an invalid key size to fail")
+	}
+}
+
+type encryptedCustomer struct {
+	ID        uint                       `json:"id"`
+	Name      string                     `json:"name"`
+	Email     Encrypted[MaskedEmail]     `json:"email"`
+	CardToken Encrypted[string]          `json:"-"`
+	Phone     string                     `json:"phone" gorm:"serializer:encrypted"`
+	Tags      []string                   `json:"tags" gorm:"serializer:encrypted"`
+	Card      Encrypted[MaskedCard]      `json:"card"`
+	Meta      Encrypted[map[string]bool] `json:"meta"`
+}
+
+func TestEncryptedFields(t *testing.T) {
+	defer SetKeyring(defaultKeyring.Load())
+	SetKeyring(testKeyring(t, "k1", "k1"))
+
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping encrypted field tests: sqlite not available (%v)", err)
+	}
+	if err := db.AutoMigrate(&encryptedCustomer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	customer := encryptedCustomer{
+		Name:      "Ann",
+		Email:     Encrypt(MaskedEmail("ann@example.com")),
+		CardToken: Encrypt("tok_visa_123"),
+		Phone:     "+1 555 0100",
+		Tags:      []string{"vip"},
+		Card:      Encrypt(MaskedCard("4111 1111 1111 1234")),
+		Meta:      Encrypt(map[string]bool{"newsletter": true}),
+	}
+	if err := db.Create(&customer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]any
+	db.Table("encrypted_customers").Take(&raw)
+	for _, column := range []string{"email", "card_token", "phone", "tags", "card", "meta"} {
+		value := fmt.Sprint(raw[column])
+		if !strings.HasPrefix(value, "enc:v1:k1:") {
+			t.Errorf("Expected column %s encrypted, got %q", column, value)
+		}
+	}
+
+	// Rotation: rows written under k1 stay readable after k2 becomes primary
+	SetKeyring(testKeyring(t, "k2", "k1", "k2"))
+	var loaded encryptedCustomer
+	if err := db.First(&loaded, customer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Email.V != "ann@example.com" || loaded.CardToken.V != "tok_visa_123" || loaded.Phone != "+1 555 0100" ||
+		loaded.Tags[0] != "vip" || loaded.Card.V != "4111 1111 1111 1234" || !loaded.Meta.V["newsletter"] {
+		t.Errorf("Unexpected decrypted customer %+v", loaded)
+	}
+
+	out, _ := json.Marshal(loaded)
+	if string(out) != `{"id":1,"name":"Ann","email":"a***@example.com","phone":"+1 555 0100","tags":["vip"],"card":"**** **** **** 1234","meta":{"newsletter":true}}` {
+		t.Errorf("Unexpected JSON %s", out)
+	}
+	if s := fmt.Sprintf("%v %#v", loaded.CardToken, loaded.CardToken); s != "[encrypted] [encrypted]" {
+		t.Errorf("Expected the value hidden from fmt, got %q", s)
+	}
+
+	SetKeyring(nil)
+	if err := db.Create(&encryptedCustomer{Name: "Bob"}).Error; !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("Expected ErrNoKeyring, got %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorReport is a panic or a Context error captured by ErrorReporter
+type ErrorReport struct {
+	EventID string    `json:"event_id"`
+	Time    time.Time `json:"time"`
+
+	// Level is "fatal" for panics and "error" for Context errors
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Err     error  `json:"-"`
+
+	// Stack is the panic's stack trace from the panicking function
+	// outwards; Context errors have none
+	Stack []StackFrame `json:"stack,omitempty"`
+
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	Route         string `json:"route,omitempty"`
+	Status        int    `json:"status"`
+	ClientIP      string `json:"client_ip,omitempty"`
+	UserID        string `json:"user_id,omitempty"`
+	TransactionID string `json:"transaction_id,omitempty"`
+
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Reporter delivers error reports to an error tracking service
+type Reporter interface {
+	Report(ctx context.Context, reports []ErrorReport) error
+}
+
+// ReporterFunc is a function Reporter
+type ReporterFunc func(ctx context.Context, reports []ErrorReport) error
+
+// Report calls f
+func (f ReporterFunc) Report(ctx context.Context, reports []ErrorReport) error {
+	return f(ctx, reports)
+}
+
+// ReporterConfig holds configuration for the ErrorReporter middleware
+type ReporterConfig struct {
+	// DSN reports to Sentry, e.g. "https://key@o1.ingest.sentry.io/42"
+	// Optional.
+	DSN string
+
+	// Reporter delivers the reports instead of the Sentry client, e.g. to
+	// Bugsnag or a log pipeline. DSN or Reporter is required.
+	Reporter Reporter
+
+	// Environment and Release are sent with every report
+	// Optional.
+	Environment string
+	Release     string
+
+	// Tags are sent with every report
+	// Optional.
+	Tags map[string]string
+
+	// SampleRate is the fraction of Context errors reported, from 0 to 1.
+	// Panics are always reported.
+	// Default: 1
+	SampleRate float64
+
+	// Filter reports whether a Context error is reported
+	// Default: all errors except binding errors and APIErrors with a
+	// status below 500
+	Filter func(c *Context, err *Error) bool
+
+	// UserFunc returns the user making the request
+	// Default: the "user_id" context value set by JWTAuth
+	UserFunc func(*Context) string
+
+	// Writer configures the worker pool delivering the reports
+	Writer BatchWriterConfig
+}
+
+// ErrorReporter returns a middleware reporting panics and the errors
+// added with c.Error to Sentry or another error tracker, with the route,
+// user and transaction ID of the request:
+//
+//	r := goTap.Default()
+//	r.Use(goTap.TransactionID(), goTap.ErrorReporter(goTap.ReporterConfig{
+//	    DSN:         os.Getenv("SENTRY_DSN"),
+//	    Environment: "production",
+//	    Release:     version,
+//	}))
+//
+// Panics are captured through the Recovery middleware, which must come
+// before ErrorReporter. Reports are delivered in the background and
+// flushed on server shutdown.
+func ErrorReporter(config ReporterConfig) HandlerFunc {
+	if config.Reporter == nil {
+		if config.DSN == "" {
+			panic("goTap: ErrorReporter requires a DSN or a Reporter")
+		}
+		sentry, err := NewSentryReporter(config.DSN)
+		if err != nil {
+			panic(err)
+		}
+		config.Reporter = sentry
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.Filter == nil {
+		config.Filter = defaultReportFilter
+	}
+	if config.UserFunc == nil {
+		config.UserFunc = func(c *Context) string {
+			if v, ok := c.Get("user_id"); ok {
+				return fmt.Sprint(v)
+			}
+			return ""
+		}
+	}
+	if config.Writer.Name == "" {
+		config.Writer.Name = "error reporter"
+	}
+
+	writer := NewBatchWriter(config.Reporter.Report, config.Writer)
+	var registerOnce sync.Once
+
+	newReport := func(c *Context, level string, err any, status int) ErrorReport {
+		report := ErrorReport{
+			EventID:       newEventID(),
+			Time:          time.Now(),
+			Level:         level,
+			Message:       fmt.Sprint(err),
+			Type:          fmt.Sprintf("%T", err),
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Route:         c.FullPath(),
+			Status:        status,
+			ClientIP:      c.ClientIP(),
+			UserID:        config.UserFunc(c),
+			TransactionID: GetTransactionID(c),
+			Environment:   config.Environment,
+			Release:       config.Release,
+			Tags:          config.Tags,
+		}
+		if e, ok := err.(error); ok {
+			report.Err = e
+		}
+		return report
+	}
+
+	onPanic := func(c *Context, event PanicEvent) {
+		if isBrokenPipe(event.Err) {
+			return
+		}
+		report := newReport(c, "fatal", event.Err, http.StatusInternalServerError)
+		report.Stack = event.Stack
+		writer.Add(report)
+	}
+
+	return func(c *Context) {
+		if c.engine != nil {
+			registerOnce.Do(func() { c.engine.addService(config.Writer.Name, writer.Close) })
+		}
+		addPanicHook(c, onPanic)
+
+		c.Next()
+
+		for _, e := range c.Errors {
+			if !config.Filter(c, e) || (config.SampleRate < 1 && mathrand.Float64() >= config.SampleRate) {
+				continue
+			}
+			writer.Add(newReport(c, "error", e.Err, c.Writer.Status()))
+		}
+	}
+}
+
+// defaultReportFilter skips client errors, which aren't bugs
+func defaultReportFilter(c *Context, err *Error) bool {
+	if err.IsType(ErrorTypeBind) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err.Err, &apiErr) && apiErr.Status < http.StatusInternalServerError {
+		return false
+	}
+	return true
+}
+
+// panicHooksKey holds the PanicHooks middleware added for the request
+const panicHooksKey = "gotap.panic_hooks"
+
+// addPanicHook registers hook with the Recovery middleware for the request
+func addPanicHook(c *Context, hook PanicHook) {
+	hooks, _ := c.Get(panicHooksKey)
+	list, _ := hooks.([]PanicHook)
+	c.Set(panicHooksKey, append(list, hook))
+}
+
+// contextPanicHooks returns the PanicHooks added with addPanicHook
+func contextPanicHooks(c *Context) []PanicHook {
+	hooks, _ := c.Get(panicHooksKey)
+	list, _ := hooks.([]PanicHook)
+	return list
+}
+
+// newEventID returns 32 random hex digits, the Sentry event ID format
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SentryReporter sends error reports to Sentry's envelope endpoint
+type SentryReporter struct {
+	// Client sends the requests
+	// Default: http.Client with a 10 second timeout
+	Client *http.Client
+
+	dsn      string
+	endpoint string
+	auth     string
+}
+
+// NewSentryReporter returns a Reporter for a Sentry DSN of the form
+// "https://<key>@<host>/<project>"
+func NewSentryReporter(dsn string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("goTap: invalid Sentry DSN: %w", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || project == "" {
+		return nil, errors.New("goTap: invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+	if _, err := strconv.ParseUint(project, 10, 64); err != nil {
+		return nil, fmt.Errorf("goTap: invalid Sentry DSN project %q", project)
+	}
+	return &SentryReporter{
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		dsn:      dsn,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:slash], project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=gotap/%s, sentry_key=%s", Version, u.User.Username()),
+	}, nil
+}
+
+// Report sends each report as a Sentry event
+func (s *SentryReporter) Report(ctx context.Context, reports []ErrorReport) error {
+	var errs []error
+	for _, report := range reports {
+		if err := s.send(ctx, report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SentryReporter) send(ctx context.Context, report ErrorReport) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	enc.Encode(map[string]string{"event_id": report.EventID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339)})
+	enc.Encode(map[string]string{"type": "event"})
+	if err := enc.Encode(sentryEvent(report)); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry %s responded %d", s.endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// sentryEvent converts a report to Sentry's event payload
+func sentryEvent(report ErrorReport) H {
+	tags := map[string]string{"route": report.Route, "status": strconv.Itoa(report.Status)}
+	if report.TransactionID != "" {
+		tags["transaction_id"] = report.TransactionID
+	}
+	for k, v := range report.Tags {
+		tags[k] = v
+	}
+
+	exception := H{"type": report.Type, "value": report.Message}
+	if len(report.Stack) > 0 {
+		// Sentry lists frames from the outermost call inwards
+		frames := make([]H, len(report.Stack))
+		for i, frame := range report.Stack {
+			frames[len(frames)-1-i] = H{
+				"function": frame.Function,
+				"abs_path": frame.File,
+				"lineno":   frame.Line,
+				"in_app":   !SkipFrameworkFrames(frame),
+			}
+		}
+		exception["stacktrace"] = H{"frames": frames}
+	}
+	if report.Level == "fatal" {
+		exception["mechanism"] = H{"type": "goTap.recovery", "handled": false}
+	}
+
+	event := H{
+		"event_id":    report.EventID,
+		"timestamp":   report.Time.UTC().Format(time.RFC3339Nano),
+		"platform":    "go",
+		"level":       report.Level,
+		"logger":      "goTap",
+		"transaction": report.Method + " " + report.Route,
+		"exception":   H{"values": []H{exception}},
+		"request":     H{"method": report.Method, "url": report.Path},
+		"tags":        tags,
+	}
+	if report.Environment != "" {
+		event["environment"] = report.Environment
+	}
+	if report.Release != "" {
+		event["release"] = report.Release
+	}
+	if report.UserID != "" || report.ClientIP != "" {
+		event["user"] = H{"id": report.UserID, "ip_address": report.ClientIP}
+	}
+	return event
+}
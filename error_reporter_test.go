@@ -0,0 +1,120 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorReporter(t *testing.T) {
+	var mu sync.Mutex
+	var reports []ErrorReport
+	reporter := ReporterFunc(func(ctx context.Context, batch []ErrorReport) error {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, batch...)
+		return nil
+	})
+
+	r := New()
+	r.Use(RecoveryWithWriter(io.Discard), TransactionID())
+	r.Use(func(c *Context) { c.Set("user_id", "u-7"); c.Next() })
+	r.Use(ErrorReporter(ReporterConfig{
+		Reporter:    reporter,
+		Environment: "test",
+		Writer:      BatchWriterConfig{FlushInterval: 5 * time.Millisecond},
+	}))
+	r.GET("/orders/:id", func(c *Context) { panic("boom") })
+	r.POST("/orders", func(c *Context) {
+		c.Error(errors.New("payment gateway timeout"))
+		c.Error(ErrConflict)
+		c.Error(errors.New("bad json")).SetType(ErrorTypeBind)
+		c.Status(http.StatusBadGateway)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/orders/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected Recovery to write 500, got %d", w.Code)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+
+	waitFor(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(reports) == 2 })
+	mu.Lock()
+	defer mu.Unlock()
+	p := reports[0]
+	if p.Level != "fatal" || p.Message != "boom" || p.Route != "/orders/:id" || p.Status != 500 ||
+		p.UserID != "u-7" || p.TransactionID == "" || p.Environment != "test" || len(p.Stack) == 0 {
+		t.Errorf("Unexpected panic report %+v", p)
+	}
+	e := reports[1]
+	if e.Level != "error" || e.Message != "payment gateway timeout" || e.Status != http.StatusBadGateway || e.Stack != nil {
+		t.Errorf("Unexpected error report %+v", e)
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	type envelope struct {
+		path, auth string
+		lines      []map[string]any
+	}
+	received := make(chan envelope, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		env := envelope{path: req.URL.Path, auth: req.Header.Get("X-Sentry-Auth")}
+		scanner := bufio.NewScanner(req.Body)
+		for scanner.Scan() {
+			var line map[string]any
+			json.Unmarshal(scanner.Bytes(), &line)
+			env.lines = append(env.lines, line)
+		}
+		received <- env
+	}))
+	defer srv.Close()
+
+	if _, err := NewSentryReporter("https://o1.ingest.sentry.io/42"); err == nil {
+		t.Error("Expected a DSN without a key to be rejected")
+	}
+	dsn := strings.Replace(srv.URL, "://", "://pubkey@", 1) + "/42"
+	reporter, err := NewSentryReporter(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = reporter.Report(context.Background(), []ErrorReport{{
+		EventID: newEventID(),
+		Time:    time.Now(),
+		Level:   "fatal",
+		Message: "boom",
+		Type:    "string",
+		Stack:   []StackFrame{{Function: "main.handler", File: "main.go", Line: 12}, {Function: "net/http.serve", File: "server.go", Line: 1}},
+		Method:  "GET",
+		Route:   "/orders/:id",
+		UserID:  "u-7",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := <-received
+	if env.path != "/api/42/envelope/" || !strings.Contains(env.auth, "sentry_key=pubkey") || len(env.lines) != 3 {
+		t.Fatalf("Unexpected envelope %+v", env)
+	}
+	event := env.lines[2]
+	frames := event["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)["stacktrace"].(map[string]any)["frames"].([]any)
+	if event["level"] != "fatal" || event["transaction"] != "GET /orders/:id" ||
+		event["user"].(map[string]any)["id"] != "u-7" || frames[1].(map[string]any)["function"] != "main.handler" ||
+		frames[0].(map[string]any)["in_app"] != false {
+		t.Errorf("Unexpected event %v", event)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+)
+
+// Event is a message published on an EventBus
+type Event struct {
+	// Name identifies the event, e.g. "product.created"
+	Name string
+	// Payload carries the event data
+	Payload any
+	// Time is when the event was published
+	Time time.Time
+}
+
+// EventHandler handles events delivered by an EventBus
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus is an in-process publish/subscribe bus. Handlers run synchronously
+// in the publisher's goroutine, in subscription order; long-running work
+// should be handed off to a goroutine or queue by the handler.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []*eventSubscription
+	nextID int
+}
+
+type eventSubscription struct {
+	id      int
+	pattern string
+	handler EventHandler
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers handler for events whose name matches pattern.
+// Patterns use path.Match syntax with "." separated names, so "product.*"
+// matches "product.created" and "*" matches every event.
+// The returned function removes the subscription.
+func (b *EventBus) Subscribe(pattern string, handler EventHandler) (unsubscribe func()) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("goTap: invalid event pattern " + pattern + ": " + err.Error())
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, &eventSubscription{id: id, pattern: pattern, handler: handler})
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subs {
+			if sub.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers an event to every matching subscriber. A panicking
+// handler is logged and does not affect other subscribers or the publisher.
+func (b *EventBus) Publish(ctx context.Context, name string, payload any) {
+	event := Event{Name: name, Payload: payload, Time: time.Now()}
+
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if matched, _ := path.Match(sub.pattern, name); matched {
+			b.deliver(ctx, sub, event)
+		}
+	}
+}
+
+func (b *EventBus) deliver(ctx context.Context, sub *eventSubscription, event Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			debugPrint("[WARNING] event handler for %q panicked: %v", event.Name, err)
+		}
+	}()
+	sub.handler(ctx, event)
+}
+
+// Events returns the engine event bus, creating it if needed
+func (engine *Engine) Events() *EventBus {
+	engine.servicesMu.Lock()
+	defer engine.servicesMu.Unlock()
+	if engine.events == nil {
+		engine.events = NewEventBus()
+	}
+	return engine.events
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEventBusPatterns(t *testing.T) {
+	bus := NewEventBus()
+	var got []string
+	record := func(prefix string) EventHandler {
+		return func(ctx context.Context, e Event) {
+			got = append(got, prefix+":"+e.Name)
+		}
+	}
+
+	bus.Subscribe("product.*", record("product"))
+	bus.Subscribe("*.deleted", record("deleted"))
+	unsubscribe := bus.Subscribe("*", record("all"))
+
+	bus.Publish(context.Background(), "product.created", nil)
+	bus.Publish(context.Background(), "order.deleted", nil)
+	unsubscribe()
+	bus.Publish(context.Background(), "user.updated", nil)
+
+	want := []string{"product:product.created", "all:product.created", "deleted:order.deleted", "all:order.deleted"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestEventBusHandlerPanic(t *testing.T) {
+	bus := NewEventBus()
+	delivered := false
+	bus.Subscribe("job.*", func(ctx context.Context, e Event) { panic("boom") })
+	bus.Subscribe("job.*", func(ctx context.Context, e Event) { delivered = e.Payload == 42 })
+
+	bus.Publish(context.Background(), "job.done", 42)
+	if !delivered {
+		t.Error("Expected later subscribers to receive the event after a panic")
+	}
+}
+
+func TestEngineEvents(t *testing.T) {
+	r := New()
+	if r.Events() == nil || r.Events() != r.Events() {
+		t.Error("Expected Events to return the same bus")
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/jaswant99k/gotap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -34,16 +33,15 @@ type Permission struct {
 	Users       []User `gorm:"many2many:user_permissions;" json:"-"`
 }
 
-// HashPassword hashes a plain text password
+// HashPassword hashes a plain text password with argon2id
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+	return goTap.HashPassword(password)
 }
 
 // VerifyPassword checks if password matches hash
 func (u *User) VerifyPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	ok, err := goTap.VerifyPassword(u.PasswordHash, password)
+	return ok && err == nil
 }
 
 // HasPermission checks if user has a specific permission
@@ -255,35 +255,21 @@ func main() {
 
 	// Update transaction with multiple binding sources
 	app.PUT("/api/transactions/:id", func(c *goTap.Context) {
-		// Bind URI parameter
-		var uri struct {
-			ID string `uri:"id" validate:"required"`
-		}
-		if err := c.ShouldBindUri(&uri); err != nil {
-			c.JSON(http.StatusBadRequest, goTap.H{"error": "Invalid transaction ID"})
-			return
-		}
-
-		// Bind headers for auth
-		var headers AuthHeader
-		if err := c.ShouldBindHeader(&headers); err != nil {
-			c.JSON(http.StatusUnauthorized, goTap.H{"error": "Missing authentication"})
-			return
-		}
-
-		// Bind JSON body
+		// Bind URI parameter, auth header and JSON body in one call
 		var update struct {
-			Status      string `json:"status" validate:"required,oneof=pending completed cancelled"`
-			Description string `json:"description" validate:"max=500"`
+			ID            string `uri:"id" json:"-" validate:"required"`
+			Authorization string `header:"Authorization" json:"-" validate:"required"`
+			Status        string `json:"status" validate:"required,oneof=pending completed cancelled"`
+			Description   string `json:"description" validate:"max=500"`
 		}
-		if err := c.ShouldBindJSON(&update); err != nil {
+		if err := c.ShouldBindAll(&update); err != nil {
 			c.JSON(http.StatusBadRequest, goTap.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, goTap.H{
 			"message":        "Transaction updated",
-			"transaction_id": uri.ID,
+			"transaction_id": update.ID,
 			"new_status":     update.Status,
 			"description":    update.Description,
 		})
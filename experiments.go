@@ -0,0 +1,225 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// ExperimentsKey is the context key holding the request's experiment
+// assignments
+const ExperimentsKey = "gotap.experiments"
+
+// ExposureEvent is the event published when a request is exposed to a
+// variant; its payload is an Exposure
+const ExposureEvent = "experiment.exposure"
+
+// Experiment is an A/B test splitting units, such as users or terminals,
+// between variants
+type Experiment struct {
+	// Name identifies the experiment, e.g. "new-receipt-layout"
+	// Required.
+	Name string
+
+	// Variants and their relative weights; the first is the control,
+	// served to units outside the experiment
+	// Default: "control" and "treatment" with equal weights
+	Variants []Variant
+
+	// Traffic is the fraction of units enrolled, from 0 to 1
+	// Default: 1
+	Traffic float64
+
+	// Flag is the feature flag acting as kill switch: while it is off,
+	// every unit gets the control and no exposure is recorded
+	// Default: the experiment name
+	Flag string
+}
+
+// Variant is an arm of an Experiment
+type Variant struct {
+	Name   string
+	Weight int
+}
+
+// Exposure records a unit seeing a variant
+type Exposure struct {
+	Experiment string    `json:"experiment"`
+	Variant    string    `json:"variant"`
+	Unit       string    `json:"unit"`
+	Path       string    `json:"path"`
+	Time       time.Time `json:"time"`
+}
+
+// ExperimentsConfig holds configuration for the Experiments middleware
+type ExperimentsConfig struct {
+	// Experiments running
+	Experiments []Experiment
+
+	// UnitFunc returns the ID units are bucketed by; requests without one
+	// get the control
+	// Default: the "user_id" context value, then the X-Terminal-ID header
+	UnitFunc func(*Context) string
+
+	// Flags holds the kill switches
+	// Default: no kill switches
+	Flags FeatureFlags
+
+	// Bus receives an ExposureEvent the first time a request reads each
+	// variant, for the analytics pipeline
+	// Default: the engine event bus
+	Bus *EventBus
+
+	// Salt changes every bucketing, e.g. to reshuffle units between
+	// experiment runs
+	Salt string
+}
+
+// experiments assigns a request's variants
+type experiments struct {
+	config  *ExperimentsConfig
+	byName  map[string]*Experiment
+	unit    string
+	exposed map[string]string
+}
+
+// Experiments returns a middleware assigning requests to experiment
+// variants, read with Context.Variant. Units are bucketed deterministically
+// by hashing their ID, so a user or terminal always sees the same variant:
+//
+//	r.Use(goTap.Experiments(goTap.ExperimentsConfig{
+//	    Experiments: []goTap.Experiment{{Name: "new-receipt-layout", Traffic: 0.2}},
+//	    Flags:       &goTap.KVFlags{Store: r.KVStore},
+//	}))
+//	r.Events().Subscribe(goTap.ExposureEvent, func(ctx context.Context, e goTap.Event) {
+//	    analytics.Add(e.Payload.(goTap.Exposure))
+//	})
+func Experiments(config ExperimentsConfig) HandlerFunc {
+	if config.UnitFunc == nil {
+		config.UnitFunc = func(c *Context) string {
+			if v, ok := c.Get("user_id"); ok {
+				if id := fmt.Sprint(v); id != "" {
+					return id
+				}
+			}
+			return c.GetHeader("X-Terminal-ID")
+		}
+	}
+
+	byName := make(map[string]*Experiment, len(config.Experiments))
+	for i := range config.Experiments {
+		exp := config.Experiments[i]
+		if exp.Name == "" {
+			panic("goTap: experiments require a name")
+		}
+		if len(exp.Variants) == 0 {
+			exp.Variants = []Variant{{Name: "control", Weight: 1}, {Name: "treatment", Weight: 1}}
+		}
+		total := 0
+		for _, v := range exp.Variants {
+			if v.Weight < 0 {
+				panic("goTap: experiment " + exp.Name + " has a negative variant weight")
+			}
+			total += v.Weight
+		}
+		if total == 0 {
+			panic("goTap: experiment " + exp.Name + " has no variant weight")
+		}
+		if exp.Traffic <= 0 || exp.Traffic > 1 {
+			exp.Traffic = 1
+		}
+		if exp.Flag == "" {
+			exp.Flag = exp.Name
+		}
+		byName[exp.Name] = &exp
+	}
+
+	return func(c *Context) {
+		c.Set(ExperimentsKey, &experiments{config: &config, byName: byName, unit: config.UnitFunc(c)})
+		c.Next()
+	}
+}
+
+// Variant returns the request's variant of an experiment and records the
+// exposure, e.g.:
+//
+//	if c.Variant("new-receipt-layout") == "treatment" {
+//	    c.HTML(200, "receipt_v2", data)
+//	    return
+//	}
+//
+// Units outside the experiment's traffic, requests without a unit and
+// experiments switched off by their flag get the control without an
+// exposure. Unknown experiments return "".
+func (c *Context) Variant(experiment string) string {
+	v, _ := c.Get(ExperimentsKey)
+	e, _ := v.(*experiments)
+	if e == nil {
+		debugPrint("[WARNING] Variant(%q) called without the Experiments middleware", experiment)
+		return ""
+	}
+	exp, ok := e.byName[experiment]
+	if !ok {
+		debugPrint("[WARNING] unknown experiment %q", experiment)
+		return ""
+	}
+	if variant, ok := e.exposed[experiment]; ok {
+		return variant
+	}
+
+	control := exp.Variants[0].Name
+	if e.unit == "" || (e.config.Flags != nil && !e.config.Flags.Enabled(c.Request.Context(), exp.Flag, true)) {
+		return control
+	}
+	if float64(bucketHash(e.config.Salt, "traffic", exp.Name, e.unit)%10000) >= exp.Traffic*10000 {
+		return control
+	}
+
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	point := int(bucketHash(e.config.Salt, "variant", exp.Name, e.unit) % uint64(total))
+	variant := control
+	for _, v := range exp.Variants {
+		if point < v.Weight {
+			variant = v.Name
+			break
+		}
+		point -= v.Weight
+	}
+
+	if e.exposed == nil {
+		e.exposed = map[string]string{}
+	}
+	e.exposed[experiment] = variant
+	bus := e.config.Bus
+	if bus == nil && c.engine != nil {
+		bus = c.engine.Events()
+	}
+	if bus != nil {
+		bus.Publish(c.Request.Context(), ExposureEvent, Exposure{
+			Experiment: experiment,
+			Variant:    variant,
+			Unit:       e.unit,
+			Path:       c.FullPath(),
+			Time:       time.Now(),
+		})
+	}
+	return variant
+}
+
+// bucketHash hashes the parts of a bucketing decision
+func bucketHash(parts ...string) uint64 {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
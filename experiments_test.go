@@ -0,0 +1,114 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestExperimentBucketing(t *testing.T) {
+	r := New()
+	r.Use(Experiments(ExperimentsConfig{Experiments: []Experiment{
+		{Name: "layout"},
+		{Name: "upsell", Traffic: 0.2, Variants: []Variant{{"off", 1}, {"small", 1}, {"large", 2}}},
+	}}))
+	r.GET("/", func(c *Context) {
+		c.String(200, "%s %s", c.Variant("layout"), c.Variant("upsell"))
+	})
+
+	var mu sync.Mutex
+	exposures := map[string]int{}
+	r.Events().Subscribe(ExposureEvent, func(ctx context.Context, e Event) {
+		x := e.Payload.(Exposure)
+		mu.Lock()
+		exposures[x.Experiment+":"+x.Variant]++
+		mu.Unlock()
+	})
+
+	counts := map[string]int{}
+	const units = 10000
+	for i := 0; i < units; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Terminal-ID", fmt.Sprintf("T-%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var layout, upsell string
+		fmt.Sscan(w.Body.String(), &layout, &upsell)
+		counts[layout]++
+		counts[upsell]++
+	}
+
+	near := func(got, want int) bool { return got > want-300 && got < want+300 }
+	if !near(counts["control"], units/2) || !near(counts["treatment"], units/2) {
+		t.Errorf("Expected an even split, got %v", counts)
+	}
+	// 20% of units are enrolled, split 1:1:2; the rest see the control
+	if !near(counts["small"], units/20) || !near(counts["large"], units/10) || !near(counts["off"], units*17/20) {
+		t.Errorf("Unexpected weighted split %v", counts)
+	}
+	if exposures["layout:control"]+exposures["layout:treatment"] != units || !near(exposures["upsell:off"], units/20) {
+		t.Errorf("Unexpected exposures %v", exposures)
+	}
+
+	// The same unit always gets the same variant
+	variant := func(unit string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Terminal-ID", unit)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if first := variant("T-42"); variant("T-42") != first {
+		t.Error("Expected deterministic bucketing")
+	}
+	if got := variant(""); got != "control off" {
+		t.Errorf("Expected the control without a unit, got %q", got)
+	}
+}
+
+func TestExperimentKillSwitch(t *testing.T) {
+	flags := &KVFlags{Store: NewMemoryKVStore()}
+	bus := NewEventBus()
+	exposed := 0
+	bus.Subscribe(ExposureEvent, func(ctx context.Context, e Event) { exposed++ })
+
+	r := New()
+	r.Use(func(c *Context) { c.Set("user_id", 7); c.Next() })
+	r.Use(Experiments(ExperimentsConfig{
+		Experiments: []Experiment{{Name: "checkout", Variants: []Variant{{"old", 0}, {"new", 1}}}},
+		Flags:       flags,
+		Bus:         bus,
+	}))
+	r.GET("/", func(c *Context) {
+		c.Variant("checkout")
+		c.String(200, "%s%s", c.Variant("checkout"), c.Variant("missing"))
+	})
+	serve := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+
+	if got := serve(); got != "new" || exposed != 1 {
+		t.Errorf("Expected one exposure to the new variant, got %q after %d", got, exposed)
+	}
+	ctx := context.Background()
+	flags.Set(ctx, "checkout", false)
+	if got := serve(); got != "old" || exposed != 1 {
+		t.Errorf("Expected the kill switch to serve the control, got %q after %d", got, exposed)
+	}
+	flags.Unset(ctx, "checkout")
+	if got := serve(); got != "new" {
+		t.Errorf("Expected the experiment back on, got %q", got)
+	}
+
+	if !(StaticFlags{"a": true}).Enabled(ctx, "a", false) || (StaticFlags{}).Enabled(ctx, "b", false) {
+		t.Error("Unexpected static flags")
+	}
+}
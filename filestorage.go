@@ -0,0 +1,257 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrFileNotFound is returned by FileStorage for missing keys
+var ErrFileNotFound = errors.New("file not found")
+
+// FileStorage stores files by slash-separated key, e.g. "products/42.png"
+type FileStorage interface {
+	// Save stores size bytes from r under key, replacing any existing file
+	Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the file stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key; missing keys are no error
+	Delete(ctx context.Context, key string) error
+}
+
+// ========== Local disk ==========
+
+// LocalStorage stores files in a directory
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns a FileStorage writing under dir
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// path maps key into the directory; keys can't escape it
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Save implements FileStorage. The file is written to a temporary name
+// and renamed, so readers never see a partial file.
+func (s *LocalStorage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Open implements FileStorage
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+	return f, err
+}
+
+// Delete implements FileStorage
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ========== S3 compatible ==========
+
+// S3Config holds configuration for an S3Storage or S3ObjectStore
+type S3Config struct {
+	// Endpoint is the service URL, e.g. "https://s3.eu-west-1.amazonaws.com"
+	// or "http://minio:9000"
+	// Required.
+	Endpoint string
+
+	// Region signs the requests
+	// Default: "us-east-1"
+	Region string
+
+	// Bucket holds the files; an S3ObjectStore takes the bucket of each
+	// object instead
+	// Required by NewS3Storage.
+	Bucket string
+
+	AccessKey string
+	SecretKey string
+
+	// VirtualHosted addresses the bucket as a subdomain of the endpoint
+	// instead of a path prefix, as required by some providers
+	VirtualHosted bool
+
+	// Client sends the requests
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// S3Storage stores files in an S3 compatible object store such as AWS S3,
+// MinIO, Cloudflare R2 or DigitalOcean Spaces, signing requests with AWS
+// Signature Version 4
+type S3Storage struct {
+	objects *S3ObjectStore
+	bucket  string
+}
+
+// NewS3Storage returns a FileStorage for an S3 compatible bucket
+func NewS3Storage(config S3Config) *S3Storage {
+	if config.Endpoint == "" || config.Bucket == "" {
+		panic("goTap: S3Storage requires Endpoint and Bucket")
+	}
+	return &S3Storage{objects: NewS3ObjectStore(config), bucket: config.Bucket}
+}
+
+// Save implements FileStorage
+func (s *S3Storage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return s.objects.Put(ctx, s.bucket, key, r, size, contentType)
+}
+
+// Open implements FileStorage
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.objects.Get(ctx, s.bucket, key)
+}
+
+// Delete implements FileStorage
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.objects.Delete(ctx, s.bucket, key)
+}
+
+// s3Error closes resp and returns an error unless its status is expected
+func s3Error(resp *http.Response, expected ...int) error {
+	defer resp.Body.Close()
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// signS3Request adds an AWS Signature Version 4 Authorization header.
+// payloadHash is the hex SHA-256 of the body or "UNSIGNED-PAYLOAD".
+func signS3Request(req *http.Request, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") || lk == "content-type" || lk == "content-md5" || lk == "range" {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalS3Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	signature := s3Signature(canonicalRequest, amzDate, region, secretKey)
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// s3Signature signs canonicalRequest with a key derived from secretKey
+func s3Signature(canonicalRequest, amzDate, region, secretKey string) string {
+	date := amzDate[:8]
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// FeatureFlags reports whether features are switched on
+type FeatureFlags interface {
+	// Enabled reports whether flag is on, or fallback when it isn't set
+	Enabled(ctx context.Context, flag string, fallback bool) bool
+}
+
+// StaticFlags are feature flags fixed at startup, e.g. from configuration
+type StaticFlags map[string]bool
+
+// Enabled implements FeatureFlags
+func (f StaticFlags) Enabled(ctx context.Context, flag string, fallback bool) bool {
+	if enabled, ok := f[flag]; ok {
+		return enabled
+	}
+	return fallback
+}
+
+// KVFlags keeps feature flags in a KVStore, so they switch at runtime on
+// every instance sharing the store
+type KVFlags struct {
+	Store KVStore
+
+	// Prefix is prepended to flag names
+	// Default: "flag:"
+	Prefix string
+}
+
+func (f *KVFlags) key(flag string) string {
+	if f.Prefix == "" {
+		return "flag:" + flag
+	}
+	return f.Prefix + flag
+}
+
+// Enabled implements FeatureFlags. Store errors return fallback.
+func (f *KVFlags) Enabled(ctx context.Context, flag string, fallback bool) bool {
+	value, err := f.Store.Get(ctx, f.key(flag))
+	if err != nil {
+		if !errors.Is(err, ErrKVNotFound) {
+			debugPrint("[WARNING] feature flag %s: %v", flag, err)
+		}
+		return fallback
+	}
+	enabled, err := strconv.ParseBool(string(value))
+	if err != nil {
+		return fallback
+	}
+	return enabled
+}
+
+// Set switches flag on or off
+func (f *KVFlags) Set(ctx context.Context, flag string, enabled bool) error {
+	return f.Store.Set(ctx, f.key(flag), []byte(strconv.FormatBool(enabled)), 0)
+}
+
+// Unset removes flag, so Enabled returns the fallback
+func (f *KVFlags) Unset(ctx context.Context, flag string) error {
+	return f.Store.Delete(ctx, f.key(flag))
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 )
 
@@ -68,12 +69,30 @@ func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileS
 			c.Abort()
 			return
 		}
+		if fi, err := f.Stat(); err == nil {
+			c.setFileETag(fi)
+		}
 		f.Close()
 
 		fileServer.ServeHTTP(c.Writer, c.Request)
 	}
 }
 
+// setFileETag sets an ETag derived from the file's modification time and
+// size, so conditional requests are answered with 304 Not Modified by
+// http.ServeContent without reading the file. An ETag set by the handler
+// is kept.
+func (c *Context) setFileETag(fi os.FileInfo) {
+	if !fi.Mode().IsRegular() {
+		return
+	}
+	header := c.Writer.Header()
+	if _, ok := header["Etag"]; ok {
+		return
+	}
+	header["Etag"] = []string{`"` + strconv.FormatInt(fi.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(fi.Size(), 36) + `"`}
+}
+
 // Dir returns a http.FileSystem that can be used by http.FileServer(). It is used internally
 // in router.Static().
 // if listDirectory == true, then it works the same as http.Dir() otherwise it returns
@@ -91,12 +110,16 @@ type onlyFilesFS struct {
 	fs http.FileSystem
 }
 
-// Open conforms to http.FileSystem.
+// Open conforms to http.FileSystem. Only directories are wrapped, so
+// regular files keep their *os.File type and can be sent with sendfile.
 func (fs onlyFilesFS) Open(name string) (http.File, error) {
 	f, err := fs.fs.Open(name)
 	if err != nil {
 		return nil, err
 	}
+	if fi, err := f.Stat(); err == nil && !fi.IsDir() {
+		return f, nil
+	}
 	return neuteredReaddirFile{f}, nil
 }
 
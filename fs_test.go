@@ -0,0 +1,135 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileETagAndNotModified(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "receipt.txt"), []byte("receipt #1"), 0644)
+
+	r := New()
+	r.Static("/static", dir)
+	r.GET("/file", func(c *Context) { c.File(filepath.Join(dir, "receipt.txt")) })
+	r.GET("/custom", func(c *Context) {
+		c.Header("ETag", `"v1"`)
+		c.File(filepath.Join(dir, "receipt.txt"))
+	})
+
+	for _, path := range []string{"/static/receipt.txt", "/file"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		etag := w.Header().Get("ETag")
+		if w.Code != 200 || w.Body.String() != "receipt #1" || etag == "" || w.Header().Get("Last-Modified") == "" {
+			t.Fatalf("GET %s: unexpected response %d %q %v", path, w.Code, w.Body.String(), w.Header())
+		}
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("GET %s: expected 304 for matching ETag, got %d", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/custom", nil))
+	if etag := w.Header().Get("ETag"); etag != `"v1"` {
+		t.Errorf("Expected handler ETag to be kept, got %q", etag)
+	}
+
+	// The ETag changes with the file
+	before := httptest.NewRecorder()
+	r.ServeHTTP(before, httptest.NewRequest("GET", "/file", nil))
+	os.WriteFile(filepath.Join(dir, "receipt.txt"), []byte("receipt #22"), 0644)
+	after := httptest.NewRecorder()
+	r.ServeHTTP(after, httptest.NewRequest("GET", "/file", nil))
+	if before.Header().Get("ETag") == after.Header().Get("ETag") {
+		t.Error("Expected ETag to change when the file changes")
+	}
+}
+
+// readerFromRecorder records what reaches ReadFrom
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (w *readerFromRecorder) ReadFrom(r io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+func TestFileSendfileAndBufferSize(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("x", 100<<10)
+	os.WriteFile(filepath.Join(dir, "image.png"), []byte(content), 0644)
+
+	r := New()
+	r.FileBufferSize = 4 << 10
+	r.Static("/static", dir)
+	r.GET("/string", func(c *Context) {
+		io.Copy(c.Writer.(io.ReaderFrom).(io.Writer), strings.NewReader(content))
+	})
+
+	for _, tc := range []struct {
+		path     string
+		tls      bool
+		sendfile bool
+	}{
+		{"/static/image.png", false, true},
+		{"/static/image.png", true, false},
+		{"/string", false, false},
+	} {
+		w := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		r.ServeHTTP(w, req)
+		if w.Body.String() != content {
+			t.Errorf("GET %s: unexpected body of %d bytes", tc.path, w.Body.Len())
+		}
+		if w.readFrom != tc.sendfile {
+			t.Errorf("GET %s (tls=%v): expected sendfile path %v", tc.path, tc.tls, tc.sendfile)
+		}
+	}
+
+	buf := r.getFileBuffer()
+	if len(*buf) != 4<<10 {
+		t.Errorf("Expected 4 KB buffers, got %d", len(*buf))
+	}
+}
+
+func TestStaticServesFilesOverHTTP(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("receipt line\n", 10000)
+	os.WriteFile(filepath.Join(dir, "receipt.txt"), []byte(content), 0644)
+
+	r := New()
+	r.Static("/static", dir)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/static/receipt.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || string(body) != content {
+		t.Errorf("Unexpected response %d with %d bytes", resp.StatusCode, len(body))
+	}
+}
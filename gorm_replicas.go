@@ -0,0 +1,288 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicaPolicy selects the replica serving a read
+type ReplicaPolicy string
+
+const (
+	// ReplicaRoundRobin cycles through the replicas
+	ReplicaRoundRobin ReplicaPolicy = "round_robin"
+	// ReplicaRandom picks a random replica
+	ReplicaRandom ReplicaPolicy = "random"
+	// ReplicaLeastConn picks the replica with the fewest connections in use
+	ReplicaLeastConn ReplicaPolicy = "least_conn"
+)
+
+// resolverPolicy returns the dbresolver policy of p
+func (p ReplicaPolicy) resolverPolicy() (dbresolver.Policy, error) {
+	switch p {
+	case ReplicaRoundRobin, "":
+		return dbresolver.RoundRobinPolicy(), nil
+	case ReplicaRandom:
+		return dbresolver.RandomPolicy{}, nil
+	case ReplicaLeastConn:
+		return dbresolver.PolicyFunc(leastConn), nil
+	default:
+		return nil, fmt.Errorf("unsupported replica policy: %s", p)
+	}
+}
+
+// leastConn returns the pool with the fewest connections in use
+func leastConn(pools []gorm.ConnPool) gorm.ConnPool {
+	best, bestInUse := pools[0], -1
+	for _, pool := range pools {
+		sqlDB, ok := pool.(*sql.DB)
+		if !ok {
+			continue
+		}
+		if inUse := sqlDB.Stats().InUse; bestInUse < 0 || inUse < bestInUse {
+			best, bestInUse = pool, inUse
+		}
+	}
+	return best
+}
+
+// GormConnMetrics holds the metrics of a primary or replica connection
+type GormConnMetrics struct {
+	Queries         int64         `json:"queries"`
+	Errors          int64         `json:"errors"`
+	AvgLatency      time.Duration `json:"avg_latency"`
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+}
+
+type gormConnStats struct {
+	name    string
+	sqlDB   *sql.DB
+	queries atomic.Int64
+	errors  atomic.Int64
+	latency atomic.Int64
+}
+
+// GormReplicas is a GORM database sending writes to a primary and reads to
+// replicas
+type GormReplicas struct {
+	// DB routes queries through the resolver, use it as any *goTap.DB
+	DB *DB
+
+	primary *gormConnStats
+	conns   map[gorm.ConnPool]*gormConnStats
+	order   []*gormConnStats
+}
+
+// NewGormReplicas connects to the primary of config and to replicaDSNs
+// with the same driver and pool settings. Queries and rows are read from a
+// replica chosen by policy, everything else and transactions use the
+// primary.
+func NewGormReplicas(config *DBConfig, replicaDSNs []string, policy ReplicaPolicy) (*GormReplicas, error) {
+	if config == nil {
+		config = DefaultDBConfig()
+	}
+	resolverPolicy, err := policy.resolverPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := NewGormDB(config)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &GormReplicas{DB: db, conns: map[gorm.ConnPool]*gormConnStats{}}
+	r.primary = r.track("primary", sqlDB)
+
+	replicas := make([]gorm.Dialector, 0, len(replicaDSNs))
+	for i, dsn := range replicaDSNs {
+		replica, err := openReplica(config, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		r.track(fmt.Sprintf("replica-%d", i+1), replica)
+
+		dialector, err := gormDialector(config.Driver, dsn, replica)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	err = db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   resolverPolicy,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register resolver: %w", err)
+	}
+	if err := r.registerCallbacks(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[GORM] Connected to %d %s replica(s)", len(replicaDSNs), config.Driver)
+	return r, nil
+}
+
+// openReplica opens the connection pool of a replica
+func openReplica(config *DBConfig, dsn string) (*sql.DB, error) {
+	dialector, err := gormDialector(config.Driver, dsn, nil)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(config.LogLevel)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return sqlDB, nil
+}
+
+func (r *GormReplicas) track(name string, sqlDB *sql.DB) *gormConnStats {
+	stats := &gormConnStats{name: name, sqlDB: sqlDB}
+	r.conns[sqlDB] = stats
+	r.order = append(r.order, stats)
+	return stats
+}
+
+const gormReplicaStatsKey = "gotap:replica_stats"
+
+// registerCallbacks records the metrics of the connection chosen by the
+// resolver, before a create, update or delete opens its transaction
+func (r *GormReplicas) registerCallbacks() error {
+	callback := r.DB.Callback()
+	return errors.Join(
+		callback.Create().After("gorm:db_resolver").Before("gorm:begin_transaction").Register("gotap:replica_start", r.start),
+		callback.Create().Register("gotap:replica_end", r.end),
+		callback.Query().After("gorm:db_resolver").Before("gorm:query").Register("gotap:replica_start", r.start),
+		callback.Query().Register("gotap:replica_end", r.end),
+		callback.Update().After("gorm:db_resolver").Before("gorm:begin_transaction").Register("gotap:replica_start", r.start),
+		callback.Update().Register("gotap:replica_end", r.end),
+		callback.Delete().After("gorm:db_resolver").Before("gorm:begin_transaction").Register("gotap:replica_start", r.start),
+		callback.Delete().Register("gotap:replica_end", r.end),
+		callback.Row().After("gorm:db_resolver").Before("gorm:row").Register("gotap:replica_start", r.start),
+		callback.Row().Register("gotap:replica_end", r.end),
+		callback.Raw().After("gorm:db_resolver").Before("gorm:raw").Register("gotap:replica_start", r.start),
+		callback.Raw().Register("gotap:replica_end", r.end),
+	)
+}
+
+func (r *GormReplicas) start(db *gorm.DB) {
+	pool := db.Statement.ConnPool
+	if prepared, ok := pool.(*gorm.PreparedStmtDB); ok {
+		pool = prepared.ConnPool
+	}
+	stats, ok := r.conns[pool]
+	if !ok {
+		// Transactions run on the primary
+		stats = r.primary
+	}
+	db.InstanceSet(gormReplicaStatsKey, stats)
+	db.InstanceSet(gormReplicaStatsKey+":start", time.Now())
+}
+
+func (r *GormReplicas) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormReplicaStatsKey)
+	if !ok {
+		return
+	}
+	stats := value.(*gormConnStats)
+	if start, ok := db.InstanceGet(gormReplicaStatsKey + ":start"); ok {
+		stats.latency.Add(int64(time.Since(start.(time.Time))))
+	}
+	stats.queries.Add(1)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		stats.errors.Add(1)
+	}
+}
+
+// Metrics returns the metrics of the primary and of each replica, keyed
+// by "primary" and "replica-1", "replica-2", ...
+func (r *GormReplicas) Metrics() map[string]GormConnMetrics {
+	metrics := make(map[string]GormConnMetrics, len(r.order))
+	for _, stats := range r.order {
+		dbStats := stats.sqlDB.Stats()
+		m := GormConnMetrics{
+			Queries:         stats.queries.Load(),
+			Errors:          stats.errors.Load(),
+			OpenConnections: dbStats.OpenConnections,
+			InUse:           dbStats.InUse,
+			Idle:            dbStats.Idle,
+		}
+		if m.Queries > 0 {
+			m.AvgLatency = time.Duration(stats.latency.Load() / m.Queries)
+		}
+		metrics[stats.name] = m
+	}
+	return metrics
+}
+
+// Close closes the primary and replica connections
+func (r *GormReplicas) Close() error {
+	var errs []error
+	for _, stats := range r.order {
+		errs = append(errs, stats.sqlDB.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// GormInjectWithReplicas connects to a primary and its read replicas and
+// injects the database, see NewGormReplicas. The driver and pool settings
+// come from DBConfigFromEnv. Handlers read with c.DBRead() and write with
+// c.DBWrite():
+//
+//	r.Use(goTap.GormInjectWithReplicas(primaryDSN, []string{replica1, replica2}, goTap.ReplicaRoundRobin))
+//
+// It panics if a database can't be reached; use NewGormReplicas and
+// GormInject to handle the error or to expose Metrics.
+func GormInjectWithReplicas(primaryDSN string, replicaDSNs []string, policy ReplicaPolicy) HandlerFunc {
+	config := DBConfigFromEnv()
+	config.DSN = primaryDSN
+	replicas, err := NewGormReplicas(config, replicaDSNs, policy)
+	if err != nil {
+		panic(err)
+	}
+	return GormInject(replicas.DB)
+}
+
+// DBRead returns the injected database bound to the request context,
+// reading from a replica when replicas are configured. Queries already
+// default to replicas, so the resolver's Read clause isn't added: it would
+// consume a second pick of the policy per query.
+func (c *Context) DBRead() *DB {
+	return MustGetGorm(c).WithContext(c.Request.Context())
+}
+
+// DBWrite returns the injected database bound to the request context,
+// using the primary when replicas are configured. Read through it to see
+// your own writes.
+func (c *Context) DBWrite() *DB {
+	return MustGetGorm(c).WithContext(c.Request.Context()).Clauses(dbresolver.Write)
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+)
+
+type replicaItem struct {
+	ID   uint
+	Name string
+}
+
+// setupReplicas creates a primary and two replicas, each holding a row
+// named after the database
+func setupReplicas(t *testing.T, policy ReplicaPolicy) *GormReplicas {
+	dir := t.TempDir()
+	names := []string{"primary", "replica-1", "replica-2"}
+	dsns := make([]string, len(names))
+	for i, name := range names {
+		dsns[i] = filepath.Join(dir, name+".db")
+		db, err := gorm.Open(sqlite.Open(dsns[i]), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.AutoMigrate(&replicaItem{})
+		db.Create(&replicaItem{Name: name})
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}
+
+	config := DefaultDBConfig()
+	config.Driver = "sqlite"
+	config.DSN = dsns[0]
+	config.LogLevel = logger.Silent
+	replicas, err := NewGormReplicas(config, dsns[1:], policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { replicas.Close() })
+	return replicas
+}
+
+func TestGormReplicas(t *testing.T) {
+	replicas := setupReplicas(t, ReplicaRoundRobin)
+
+	r := New()
+	r.Use(GormInject(replicas.DB))
+	r.GET("/read", func(c *Context) {
+		var item replicaItem
+		c.DBRead().First(&item)
+		c.String(http.StatusOK, item.Name)
+	})
+	r.GET("/write", func(c *Context) {
+		var item replicaItem
+		c.DBWrite().First(&item)
+		c.String(http.StatusOK, item.Name)
+	})
+	r.POST("/items", func(c *Context) {
+		c.DBRead().Create(&replicaItem{Name: "latte"})
+		c.Status(http.StatusCreated)
+	})
+
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[get("/read")] = true
+	}
+	if len(seen) != 2 || !seen["replica-1"] || !seen["replica-2"] {
+		t.Errorf("Expected reads from both replicas, got %v", seen)
+	}
+	if name := get("/write"); name != "primary" {
+		t.Errorf("Expected DBWrite to use the primary, got %q", name)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	var count int64
+	replicas.DB.Clauses(dbresolver.Write).Model(&replicaItem{}).Count(&count)
+	if w.Code != http.StatusCreated || count != 2 {
+		t.Errorf("Expected the create on the primary, got %d rows", count)
+	}
+
+	metrics := replicas.Metrics()
+	if m := metrics["primary"]; m.Queries != 3 || m.Errors != 0 || m.OpenConnections == 0 {
+		t.Errorf("Unexpected primary metrics %+v", m)
+	}
+	if metrics["replica-1"].Queries != 2 || metrics["replica-2"].Queries != 2 {
+		t.Errorf("Unexpected replica metrics %+v", metrics)
+	}
+
+	var missing replicaItem
+	replicas.DB.Table("missing").First(&missing)
+	metrics = replicas.Metrics()
+	if n := metrics["replica-1"].Errors + metrics["replica-2"].Errors; n != 1 {
+		t.Errorf("Expected 1 replica error, got %d", n)
+	}
+}
+
+func TestGormReplicasTransaction(t *testing.T) {
+	replicas := setupReplicas(t, ReplicaLeastConn)
+
+	var item replicaItem
+	err := replicas.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.First(&item).Error
+	})
+	if err != nil || item.Name != "primary" {
+		t.Errorf("Expected transactions to read the primary, got %q %v", item.Name, err)
+	}
+	if n := replicas.Metrics()["primary"].Queries; n != 1 {
+		t.Errorf("Expected 1 primary query, got %d", n)
+	}
+}
+
+func TestReplicaPolicy(t *testing.T) {
+	if _, err := ReplicaPolicy("fastest").resolverPolicy(); err == nil {
+		t.Error("Expected an unknown policy to be rejected")
+	}
+	if _, err := NewGormReplicas(&DBConfig{Driver: "sqlite", DSN: ":memory:"}, nil, "fastest"); err == nil {
+		t.Error("Expected NewGormReplicas to reject an unknown policy")
+	}
+}
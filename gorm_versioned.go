@@ -0,0 +1,121 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrStaleRecord is returned when a versioned record was changed since it
+// was read. c.Problem sends it as 409 Conflict.
+var ErrStaleRecord = RegisterErrorCode("stale_record", http.StatusConflict, "Record was modified by another request")
+
+// GormUpdateVersioned applies updates to model if its Version still
+// matches the database, and increments it. updates is a map or a struct
+// such as model itself. It returns ErrStaleRecord if another request
+// updated the record first and gorm.ErrRecordNotFound if it is gone:
+//
+//	var product Product
+//	db.First(&product, id) // e.g. version 3
+//	err := goTap.GormUpdateVersioned(db, &product, map[string]interface{}{"stock": 8})
+//	if errors.Is(err, goTap.ErrStaleRecord) {
+//	    c.Problem(err) // 409, reload and retry
+//	}
+//
+// The version is read from model; set it to the version the client saw to
+// check edits made in between.
+func GormUpdateVersioned(db *gorm.DB, model interface{}, updates interface{}) error {
+	ctx := db.Statement.Context
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField("Version")
+	if field == nil {
+		return fmt.Errorf("%s has no Version field", stmt.Schema.Name)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return fmt.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+	modelValue := reflect.ValueOf(model)
+	version, err := versionOf(field.ReflectValueOf(ctx, modelValue))
+	if err != nil {
+		return err
+	}
+
+	// Write the next version with the updates, restoring the current one
+	// if the update fails
+	next := version + 1
+	restore := func() {}
+	switch u := updates.(type) {
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(u)+1)
+		for k, v := range u {
+			values[k] = v
+		}
+		values[field.DBName] = next
+		updates = values
+	default:
+		updatesStmt := &gorm.Statement{DB: db}
+		if err := updatesStmt.Parse(updates); err != nil {
+			return err
+		}
+		updatesField := updatesStmt.Schema.LookUpField("Version")
+		if updatesField == nil {
+			return fmt.Errorf("%s has no Version field", updatesStmt.Schema.Name)
+		}
+		updatesValue := reflect.ValueOf(updates)
+		current, _ := updatesField.ValueOf(ctx, updatesValue)
+		if err := updatesField.Set(ctx, updatesValue, next); err != nil {
+			return err
+		}
+		restore = func() { updatesField.Set(ctx, updatesValue, current) }
+	}
+
+	result := db.Model(model).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: version}).
+		Updates(updates)
+	if result.Error != nil {
+		restore()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		restore()
+		return staleOrMissing(db, model, stmt.Schema.PrioritizedPrimaryField)
+	}
+	return field.Set(ctx, modelValue, next)
+}
+
+// staleOrMissing returns ErrStaleRecord if model still exists and
+// gorm.ErrRecordNotFound otherwise
+func staleOrMissing(db *gorm.DB, model interface{}, primary *schema.Field) error {
+	id, _ := primary.ValueOf(db.Statement.Context, reflect.ValueOf(model))
+	exists, err := GormExists(db.Session(&gorm.Session{NewDB: true}), model,
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: primary.DBName}, Value: id})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrStaleRecord.WithDetail("Reload the record and apply your changes again", nil)
+}
+
+func versionOf(v reflect.Value) (uint64, error) {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), nil
+	default:
+		return 0, fmt.Errorf("version field must be an integer, got %s", v.Type())
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type versionedProduct struct {
+	VersionedModel
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+}
+
+func setupVersionedDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&versionedProduct{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestGormUpdateVersioned(t *testing.T) {
+	db := setupVersionedDB(t)
+	product := versionedProduct{Name: "Coffee", Stock: 10}
+	db.Create(&product)
+	if product.Version != 1 {
+		t.Fatalf("Expected version 1, got %d", product.Version)
+	}
+
+	// Two terminals load the same product
+	var first, second versionedProduct
+	db.First(&first, product.ID)
+	db.First(&second, product.ID)
+
+	if err := GormUpdateVersioned(db, &first, map[string]interface{}{"stock": 9}); err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2, got %d", first.Version)
+	}
+
+	second.Stock = 7
+	err := GormUpdateVersioned(db, &second, &second)
+	if !errors.Is(err, ErrStaleRecord) {
+		t.Fatalf("Expected ErrStaleRecord, got %v", err)
+	}
+	if second.Version != 1 {
+		t.Errorf("Expected the stale version to be kept, got %d", second.Version)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusConflict {
+		t.Errorf("Expected a 409 error, got %v", err)
+	}
+
+	// Reload and retry
+	db.First(&second, product.ID)
+	second.Stock = 7
+	if err := GormUpdateVersioned(db, &second, &second); err != nil {
+		t.Fatal(err)
+	}
+	var stored versionedProduct
+	db.First(&stored, product.ID)
+	if stored.Stock != 7 || stored.Version != 3 || second.Version != 3 {
+		t.Errorf("Unexpected product %+v", stored)
+	}
+
+	db.Delete(&stored)
+	if err := GormUpdateVersioned(db, &stored, map[string]interface{}{"stock": 1}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+	if err := GormUpdateVersioned(db, &resourceItem{}, map[string]interface{}{"name": "x"}); err == nil {
+		t.Error("Expected a model without Version to be rejected")
+	}
+}
+
+func TestResourceVersioned(t *testing.T) {
+	db := setupVersionedDB(t)
+	r := New()
+	r.Use(GormInject(db))
+	Resource[versionedProduct](r.Group("/products"), ResourceConfig{})
+
+	w, body := resourceRequest(r, "POST", "/products", `{"name":"Coffee","stock":10}`)
+	if w.Code != http.StatusCreated || body["version"] != float64(1) {
+		t.Fatalf("Expected version 1, got %d %v", w.Code, body)
+	}
+
+	w, body = resourceRequest(r, "PATCH", "/products/1", `{"stock":9,"version":1}`)
+	if w.Code != http.StatusOK || body["version"] != float64(2) {
+		t.Fatalf("Expected version 2, got %d %v", w.Code, body)
+	}
+	w, body = resourceRequest(r, "PATCH", "/products/1", `{"stock":7,"version":1}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected a conflict, got %d %v", w.Code, body)
+	}
+	w, body = resourceRequest(r, "GET", "/products/1", "")
+	if body["stock"] != float64(9) || body["version"] != float64(2) {
+		t.Errorf("Expected the first update to be kept, got %v", body)
+	}
+}
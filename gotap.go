@@ -6,11 +6,17 @@ package goTap
 
 import (
 	"context"
+	"fmt"
 	"html/template"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/jaswant99k/gotap/receipts"
 )
 
 const Version = "0.1.0"
@@ -31,10 +37,21 @@ func (c HandlersChain) Last() HandlerFunc {
 
 // RouteInfo represents a request route's specification which contains method and path and its handler.
 type RouteInfo struct {
-	Method      string
-	Path        string
-	Handler     string
-	HandlerFunc HandlerFunc
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Handler     string      `json:"handler"`
+	HandlerFunc HandlerFunc `json:"-"`
+
+	// Middleware names the handlers running before Handler
+	Middleware []string `json:"middleware,omitempty"`
+
+	// Group is the prefix of the router group the route was registered on;
+	// set by WriteRoutes
+	Group string `json:"group,omitempty"`
+
+	// Metadata holds declared timeouts, body limits, request types and
+	// deprecation; set by WriteRoutes
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // RoutesInfo defines a RouteInfo slice.
@@ -54,23 +71,126 @@ type Engine struct {
 	UnescapePathValues     bool
 	RemoveExtraSlash       bool
 
+	// HandleOPTIONS answers OPTIONS requests for paths without an OPTIONS
+	// route with 204 No Content and an Allow header listing the path's
+	// methods, after the global middleware such as CORS. HandleMethodNotAllowed
+	// responses include OPTIONS in Allow.
+	// Default: false
+	HandleOPTIONS bool
+
 	// Template rendering
 	delims             Delims
 	FuncMap            template.FuncMap
+	htmlRender         htmlRender
+	htmlData           []func(c *Context) H
+	receiptTemplates   *receipts.Templates
 	allNoRoute         HandlersChain
 	allNoMethod        HandlersChain
 	noRoute            HandlersChain
 	noMethod           HandlersChain
+	allOptions         HandlersChain
+	groupNoRoute       []groupFallback
+	groupNoMethod      []groupFallback
 	pool               sync.Pool
 	trees              methodTrees
 	maxParams          uint16
 	maxSections        uint16
 	trustedProxies     []string
-	trustedCIDRs       []*net.IPNet
+	trustedCIDRs       atomic.Pointer[[]*net.IPNet]
 	MaxMultipartMemory int64
 
+	// FileBufferSize is the buffer size used to copy files to the client
+	// when the sendfile system call can't be used, e.g. on TLS connections,
+	// for compressed responses or files from embedded file systems.
+	// Default: 32 KB
+	FileBufferSize int
+	fileBuffers    sync.Pool
+
+	// KVStore backs the state of rate limiting, idempotency, sessions and
+	// caching middleware that don't configure their own store, so the
+	// backend is configured once for all of them.
+	// Default: each middleware keeps its state in memory
+	KVStore KVStore
+
+	// Authorizer decides the Authorize middleware and Context.Can checks
+	// that don't configure their own, e.g. a *Policy
+	// Default: checks fail
+	Authorizer Authorizer
+
+	// FileStorage receives uploads saved with SaveUploadedFileValidated
+	// that don't configure their own storage.
+	// Default: the "uploads" directory
+	FileStorage FileStorage
+
+	// DefaultOffer is the format NegotiateFormat falls back to when the
+	// Accept header accepts none of the offers, if it is offered
+	// Default: the first offer
+	DefaultOffer string
+
+	// Mocks serves the canned responses of routes using MockResponse to
+	// every request, for a backend run for frontend development. Outside
+	// release mode a request can also ask for them with X-Mock-Response.
+	// Default: false
+	Mocks bool
+
+	// DefaultLocale formats amounts and dates for requests whose
+	// Accept-Language names no supported locale, see Context.Locale
+	// Default: "en-US"
+	DefaultLocale string
+
 	// JSON rendering
 	secureJSONPrefix string
+
+	// Read-only mode, see SetReadOnly
+	readOnly atomic.Pointer[readOnlyState]
+
+	// Deprecated routes and their usage, see DeprecationReport
+	deprecations deprecationRegistry
+
+	// Recovered panics per route, see PanicReport
+	panics panicRegistry
+
+	// Registration details per route, keyed by method and path
+	routeMeta map[string]routeMeta
+
+	// Named middleware by priority, see RegisterMiddleware
+	middlewareRegistry []registeredMiddleware
+
+	// StrictRoutes also rejects routes repeating a param name or naming the
+	// params of a path differently per method, e.g. "GET /users/:id" and
+	// "DELETE /users/:user_id", panicking with a *RouteConflictError. Set it
+	// before registering routes.
+	// Default: false
+	StrictRoutes bool
+
+	// Path shapes of registered routes for StrictRoutes
+	routeShapes map[string]string
+
+	// RoutesFile receives the route table when the server starts, as
+	// Markdown for ".md", JSON for ".json" and text otherwise, as an always
+	// current API inventory
+	// Optional.
+	RoutesFile string
+
+	// Background services stopped on server shutdown
+	servicesMu sync.Mutex
+	scheduler  *Scheduler
+	events     *EventBus
+	services   []engineService
+	startHooks []startHook
+
+	// Middleware from New and Default options, installed after all options
+	// are applied
+	optLogger     HandlerFunc
+	optRecovery   HandlerFunc
+	optMiddleware HandlersChain
+	noBanner      bool
+}
+
+// engineService is a background service stopped with the server
+type engineService struct {
+	name string
+	stop func(ctx context.Context) error
 }
 
 // Delims represents template delimiters
@@ -81,19 +201,23 @@ type Delims struct {
 
 var _ IRouter = (*Engine)(nil)
 
-const defaultMultipartMemory = 32 << 20 // 32 MB
+const (
+	defaultMultipartMemory = 32 << 20 // 32 MB
+	defaultFileBufferSize  = 32 << 10 // 32 KB
+)
 
 // New returns a new blank Engine instance without any middleware attached.
 // By default, the configuration is:
 // - RedirectTrailingSlash:  true
 // - RedirectFixedPath:      false
 // - HandleMethodNotAllowed: false
+// - HandleOPTIONS:          false
 // - ForwardedByClientIP:    true
 // - UseRawPath:             false
 // - UnescapePathValues:     true
-func New() *Engine {
-	debugPrint("goTap v%s - High-performance web framework\n", Version)
-
+//
+// Options configure the engine, see OptionFunc.
+func New(opts ...OptionFunc) *Engine {
 	engine := &Engine{
 		RouterGroup: RouterGroup{
 			Handlers: nil,
@@ -108,29 +232,64 @@ func New() *Engine {
 		UseRawPath:             false,
 		UnescapePathValues:     true,
 		MaxMultipartMemory:     defaultMultipartMemory,
+		FileBufferSize:         defaultFileBufferSize,
 		trees:                  make(methodTrees, 0, 9),
 		delims:                 Delims{Left: "{{", Right: "}}"},
 		trustedProxies:         []string{"0.0.0.0/0", "::/0"},
 	}
 	engine.RouterGroup.engine = engine
+	engine.rebuild405Handlers()
 	engine.pool.New = func() any {
 		return engine.allocateContext(engine.maxParams)
 	}
+
+	for _, opt := range opts {
+		opt(engine)
+	}
+	if !engine.noBanner {
+		debugPrint("goTap v%s - High-performance web framework\n", Version)
+	}
+	for _, h := range []HandlerFunc{engine.optLogger, engine.optRecovery} {
+		if h != nil {
+			engine.Use(h)
+		}
+	}
+	engine.Use(engine.optMiddleware...)
+	engine.optLogger, engine.optRecovery, engine.optMiddleware = nil, nil, nil
 	return engine
 }
 
 // Default returns an Engine instance with the Logger and Recovery middleware already attached.
-func Default() *Engine {
-	debugPrintWARNINGDefault()
-	engine := New()
-	engine.Use(Logger(), Recovery())
+// WithLogger and WithRecovery options replace them.
+func Default(opts ...OptionFunc) *Engine {
+	opts = append([]OptionFunc{WithLogger(Logger()), WithRecovery(Recovery())}, opts...)
+	engine := New(opts...)
+	if !engine.noBanner {
+		debugPrintWARNINGDefault()
+	}
 	return engine
 }
 
 func (engine *Engine) allocateContext(maxParams uint16) *Context {
 	v := make(Params, 0, maxParams)
 	skippedNodes := make([]skippedNode, 0, engine.maxSections)
-	return &Context{engine: engine, params: &v, skippedNodes: &skippedNodes}
+	c := &Context{engine: engine, params: &v, skippedNodes: &skippedNodes}
+	c.writermem.engine = engine
+	return c
+}
+
+// sizeContext regrows the Params and skippedNodes slices of a pooled context
+// allocated before routes with more params were added, so the router can
+// fill them in place without allocating
+func (engine *Engine) sizeContext(c *Context) {
+	if cap(*c.params) < int(engine.maxParams) {
+		v := make(Params, 0, engine.maxParams)
+		c.params = &v
+	}
+	if cap(*c.skippedNodes) < int(engine.maxSections) {
+		skippedNodes := make([]skippedNode, 0, engine.maxSections)
+		c.skippedNodes = &skippedNodes
+	}
 }
 
 // Use attaches a global middleware to the router. i.e. the middleware attached through Use() will be
@@ -149,6 +308,12 @@ func (engine *Engine) rebuild404Handlers() {
 
 func (engine *Engine) rebuild405Handlers() {
 	engine.allNoMethod = engine.combineHandlers(engine.noMethod)
+	engine.allOptions = engine.combineHandlers(HandlersChain{autoOptions})
+}
+
+// autoOptions answers OPTIONS requests when HandleOPTIONS is set
+func autoOptions(c *Context) {
+	c.Status(http.StatusNoContent)
 }
 
 // SecureJSONPrefix sets the prefix for SecureJSON rendering
@@ -157,6 +322,61 @@ func (engine *Engine) SecureJSONPrefix(prefix string) {
 	engine.secureJSONPrefix = prefix
 }
 
+// SetTrustedProxies sets the IPs and CIDRs of the proxies whose
+// X-Forwarded-For and X-Real-Ip headers ClientIP trusts. Requests from
+// other addresses are identified by their remote address. nil trusts no
+// proxy. It is safe to call while serving requests.
+// Default: every address is trusted
+func (engine *Engine) SetTrustedProxies(proxies []string) error {
+	cidrs, err := parseTrustedProxies(proxies)
+	if err != nil {
+		return err
+	}
+	engine.trustedProxies = proxies
+	engine.trustedCIDRs.Store(&cidrs)
+	return nil
+}
+
+// isTrustedProxy reports whether ClientIP trusts the forwarding headers
+// of a request from remoteIP. Requests without a remote IP come over a unix
+// socket from a local proxy and are trusted.
+func (engine *Engine) isTrustedProxy(remoteIP net.IP) bool {
+	cidrs := engine.trustedCIDRs.Load()
+	if cidrs == nil || remoteIP == nil {
+		return true
+	}
+	for _, cidr := range *cidrs {
+		if cidr.Contains(remoteIP) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
 // NoRoute adds handlers for NoRoute. It returns a 404 code by default.
 func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
 	engine.noRoute = handlers
@@ -164,6 +384,7 @@ func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
 }
 
 // NoMethod sets the handlers called when Engine.HandleMethodNotAllowed = true.
+// It returns a 405 code with an Allow header by default.
 func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
 	engine.noMethod = handlers
 	engine.rebuild405Handlers()
@@ -173,8 +394,10 @@ func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := engine.pool.Get().(*Context)
 	c.writermem.reset(w)
+	c.writermem.tls = req.TLS != nil
 	c.Request = req
 	c.reset()
+	engine.sizeContext(c)
 
 	engine.handleHTTPRequest(c)
 
@@ -207,11 +430,89 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		break
 	}
 
+	if engine.HandleMethodNotAllowed || engine.HandleOPTIONS {
+		if allow := engine.allowedMethods(httpMethod, rPath, c); allow != "" {
+			c.writermem.Header().Set("Allow", allow)
+			if httpMethod == http.MethodOptions && engine.HandleOPTIONS {
+				c.handlers = engine.allOptions
+				c.Next()
+				c.writermem.WriteHeaderNow()
+				return
+			}
+			if engine.HandleMethodNotAllowed {
+				c.handlers = fallbackFor(engine.groupNoMethod, rPath, engine.allNoMethod)
+				serveError(c, http.StatusMethodNotAllowed, []byte("405 method not allowed"))
+				return
+			}
+		}
+	}
+
 	// Handle 404
-	c.handlers = engine.allNoRoute
+	c.handlers = fallbackFor(engine.groupNoRoute, rPath, engine.allNoRoute)
 	serveError(c, http.StatusNotFound, []byte("404 page not found"))
 }
 
+// allowedMethods returns the Allow header for path, listing the methods
+// with a route for it other than method, or "" if there are none
+func (engine *Engine) allowedMethods(method, path string, c *Context) string {
+	var allowed []string
+	for _, tree := range engine.trees {
+		if tree.method == method {
+			continue
+		}
+		*c.skippedNodes = (*c.skippedNodes)[:0]
+		if value := tree.root.getValue(path, nil, c.skippedNodes, false); value.handlers != nil {
+			allowed = append(allowed, tree.method)
+		}
+	}
+	if len(allowed) == 0 {
+		return ""
+	}
+	if engine.HandleOPTIONS && !containsString(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.SliceStable(allowed, func(i, j int) bool { return methodOrder(allowed[i]) < methodOrder(allowed[j]) })
+	return strings.Join(allowed, ", ")
+}
+
+// groupFallback holds the NoRoute or NoMethod handlers of a router group
+type groupFallback struct {
+	prefix   string
+	handlers HandlersChain
+}
+
+// fallbackFor returns the handlers of the group with the longest prefix
+// containing path, or def
+func fallbackFor(fallbacks []groupFallback, path string, def HandlersChain) HandlersChain {
+	best := -1
+	for i, f := range fallbacks {
+		if pathInGroup(path, f.prefix) && (best < 0 || len(f.prefix) > len(fallbacks[best].prefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return def
+	}
+	return fallbacks[best].handlers
+}
+
+// pathInGroup reports whether path is prefix or below it
+func pathInGroup(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// setGroupFallback sets the fallback handlers of the group at prefix
+func setGroupFallback(fallbacks []groupFallback, prefix string, handlers HandlersChain) []groupFallback {
+	for i := range fallbacks {
+		if fallbacks[i].prefix == prefix {
+			fallbacks[i].handlers = handlers
+			return fallbacks
+		}
+	}
+	return append(fallbacks, groupFallback{prefix: prefix, handlers: handlers})
+}
+
 func serveError(c *Context, code int, defaultMessage []byte) {
 	c.writermem.status = code
 	c.Next()
@@ -230,19 +531,36 @@ func serveError(c *Context, code int, defaultMessage []byte) {
 }
 
 // Run attaches the router to a http.Server and starts listening and serving HTTP requests.
-// It is a shortcut for http.ListenAndServe(addr, router)
+// It takes an optional address, ":5066" by default. Use RunWithOptions to configure the server.
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
-func (engine *Engine) Run(addr ...string) (err error) {
+func (engine *Engine) Run(addr ...string) error {
+	return engine.RunWithOptions(resolveAddress(addr))
+}
+
+// RunWithOptions is like Run, with ServerOptions applied to the http.Server:
+//
+//	r.RunWithOptions(":8080", goTap.WithReadTimeout(5*time.Second), goTap.WithWriteTimeout(10*time.Second))
+func (engine *Engine) RunWithOptions(address string, opts ...ServerOption) (err error) {
 	defer func() { debugPrintError(err) }()
 
-	address := resolveAddress(addr)
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		return err
+	}
+	srv, maxConns := engine.newServer(address, opts)
+	l, err := listen(srv, maxConns)
+	if err != nil {
+		return err
+	}
+	engine.printRoutes()
 	debugPrint("Listening and serving HTTP on %s\n", address)
-	err = http.ListenAndServe(address, engine)
+	err = srv.Serve(l)
 	return
 }
 
 // RunServer attaches the router to a http.Server and starts listening and serving HTTP requests.
+// It takes an optional address, ":5066" by default, like Run.
 // This method returns the http.Server instance for advanced configuration and graceful shutdown.
+// The OnStart hooks run in the background before it listens.
 // Example:
 //
 //	srv := router.RunServer(":5066")
@@ -255,16 +573,25 @@ func (engine *Engine) Run(addr ...string) (err error) {
 //	defer cancel()
 //	srv.Shutdown(ctx)
 func (engine *Engine) RunServer(addr ...string) *http.Server {
-	address := resolveAddress(addr)
-	debugPrint("Listening and serving HTTP on %s\n", address)
+	return engine.RunServerWithOptions(resolveAddress(addr))
+}
 
-	srv := &http.Server{
-		Addr:    address,
-		Handler: engine,
-	}
+// RunServerWithOptions is like RunServer, with ServerOptions applied to the http.Server
+func (engine *Engine) RunServerWithOptions(address string, opts ...ServerOption) *http.Server {
+	engine.printRoutes()
+	debugPrint("Listening and serving HTTP on %s\n", address)
 
+	srv, maxConns := engine.newServer(address, opts)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		err := engine.RunStartHooks(context.Background())
+		var l net.Listener
+		if err == nil {
+			l, err = listen(srv, maxConns)
+		}
+		if err == nil {
+			err = srv.Serve(l)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			debugPrintError(err)
 		}
 	}()
@@ -294,14 +621,55 @@ func ShutdownWithTimeout(srv *http.Server, timeout ...time.Duration) error {
 	return Shutdown(srv, ctx)
 }
 
+// shutdownServices stops the background services owned by the engine,
+// such as the scheduler. It is registered as a shutdown hook by RunServer.
+// Services are stopped after the scheduler, in reverse registration order.
+func (engine *Engine) shutdownServices() {
+	engine.servicesMu.Lock()
+	scheduler := engine.scheduler
+	services := engine.services
+	engine.services = nil
+	engine.servicesMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if scheduler != nil {
+		if err := scheduler.Stop(ctx); err != nil {
+			debugPrint("[WARNING] scheduler did not stop cleanly: %v", err)
+		}
+	}
+	for i := len(services) - 1; i >= 0; i-- {
+		if err := services[i].stop(ctx); err != nil {
+			debugPrint("[WARNING] %s did not stop cleanly: %v", services[i].name, err)
+		}
+	}
+}
+
+// addService registers a background service to stop on server shutdown
+func (engine *Engine) addService(name string, stop func(ctx context.Context) error) {
+	engine.servicesMu.Lock()
+	defer engine.servicesMu.Unlock()
+	engine.services = append(engine.services, engineService{name: name, stop: stop})
+}
+
 // RunTLS attaches the router to a http.Server and starts listening and serving HTTPS requests.
-// It is a shortcut for http.ListenAndServeTLS(addr, certFile, keyFile, router)
+// It is a shortcut for http.ListenAndServeTLS(addr, certFile, keyFile, router) with ServerOptions
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
-func (engine *Engine) RunTLS(addr, certFile, keyFile string) (err error) {
-	debugPrint("Listening and serving HTTPS on %s\n", addr)
+func (engine *Engine) RunTLS(addr, certFile, keyFile string, opts ...ServerOption) (err error) {
 	defer func() { debugPrintError(err) }()
 
-	err = http.ListenAndServeTLS(addr, certFile, keyFile, engine)
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		return err
+	}
+	srv, maxConns := engine.newServer(addr, opts)
+	l, err := listen(srv, maxConns)
+	if err != nil {
+		return err
+	}
+	engine.printRoutes()
+	debugPrint("Listening and serving HTTPS on %s\n", addr)
+	err = srv.ServeTLS(l, certFile, keyFile)
 	return
 }
 
@@ -318,11 +686,16 @@ func iterate(path, method string, routes RoutesInfo, root *node) RoutesInfo {
 	path += root.path
 	if len(root.handlers) > 0 {
 		handlerFunc := root.handlers.Last()
+		var middleware []string
+		for _, h := range root.handlers[:len(root.handlers)-1] {
+			middleware = append(middleware, middlewareName(h))
+		}
 		routes = append(routes, RouteInfo{
 			Method:      method,
 			Path:        path,
 			Handler:     nameOfFunction(handlerFunc),
 			HandlerFunc: handlerFunc,
+			Middleware:  middleware,
 		})
 	}
 	for _, child := range root.children {
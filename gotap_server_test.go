@@ -2,8 +2,12 @@ package goTap
 
 import (
 	"context"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -29,6 +33,160 @@ func TestResolveAddress(t *testing.T) {
 	}
 }
 
+func TestServerOptions(t *testing.T) {
+	engine := New()
+	srv, maxConns := engine.newServer(":8080", []ServerOption{WithReadTimeout(5 * time.Second), WithMaxConnections(10)})
+	if srv.Addr != ":8080" || srv.ReadTimeout != 5*time.Second || maxConns != 10 {
+		t.Errorf("Unexpected server %+v, max connections %d", srv, maxConns)
+	}
+	if srv.ReadHeaderTimeout != DefaultReadHeaderTimeout {
+		t.Errorf("Expected the default header timeout, got %v", srv.ReadHeaderTimeout)
+	}
+
+	srv, _ = engine.newServer(":5066", []ServerOption{WithServerConfig(ServerConfig{WriteTimeout: time.Minute, MaxHeaderBytes: 4096})})
+	if srv.Addr != ":5066" || srv.WriteTimeout != time.Minute || srv.MaxHeaderBytes != 4096 || srv.ReadHeaderTimeout != DefaultReadHeaderTimeout {
+		t.Errorf("Unexpected server %+v", srv)
+	}
+}
+
+func TestRunServerWithOptions(t *testing.T) {
+	engine := New()
+	srv := engine.RunServerWithOptions("127.0.0.1:0", WithReadTimeout(5*time.Second), WithIdleTimeout(time.Minute))
+	defer srv.Close()
+	if srv.Addr != "127.0.0.1:0" || srv.ReadTimeout != 5*time.Second || srv.IdleTimeout != time.Minute {
+		t.Errorf("Unexpected server %+v", srv)
+	}
+	if srv.ReadHeaderTimeout != DefaultReadHeaderTimeout {
+		t.Errorf("Expected the default header timeout, got %v", srv.ReadHeaderTimeout)
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	l, err := listen(srv, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	c1, _ := net.Dial("tcp", l.Addr().String())
+	defer c1.Close()
+	c2, _ := net.Dial("tcp", l.Addr().String())
+	defer c2.Close()
+
+	first := <-accepted
+	select {
+	case <-accepted:
+		t.Fatal("Expected the second connection to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	first.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second connection once the first closed")
+	}
+}
+
+func TestRunUnix(t *testing.T) {
+	engine := New()
+	engine.SetTrustedProxies([]string{"10.0.0.1"})
+	engine.GET("/ip", func(c *Context) {
+		c.Writer.WriteString(c.ClientIP())
+	})
+	socket := filepath.Join(t.TempDir(), "gotap.sock")
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close() // leaves a stale socket file
+	go engine.RunUnix(socket, 0660)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		req, _ := http.NewRequest("GET", "http://unix/ip", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		if resp, err = client.Do(req); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "203.0.113.7" {
+		t.Errorf("Expected the proxy on the socket to be trusted, got %q", body)
+	}
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0660 {
+		t.Errorf("Expected socket permissions 0660, got %v %v", info.Mode(), err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(socket)); len(entries) != 1 {
+		t.Errorf("Expected only the socket in its directory, got %v", entries)
+	}
+}
+
+func TestRunUnixKeepsRegularFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(file, []byte("keep"), 0644)
+
+	if err := New().RunUnix(file, 0660); err == nil {
+		t.Error("Expected an error for a path that is not a socket")
+	}
+	if data, _ := os.ReadFile(file); string(data) != "keep" {
+		t.Errorf("Expected the file to be left alone, got %q", data)
+	}
+}
+
+func TestRunListener(t *testing.T) {
+	engine := New()
+	engine.GET("/ping", func(c *Context) {
+		c.String(200, "pong")
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go engine.RunListener(l)
+	defer l.Close()
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "pong" {
+		t.Errorf("Expected pong, got %q", body)
+	}
+
+	// Not socket activated
+	if listeners, err := SystemdListeners(); listeners != nil || err != nil {
+		t.Errorf("Expected no systemd sockets, got %v %v", listeners, err)
+	}
+	if err := engine.RunSystemd(); err == nil {
+		t.Error("Expected an error without systemd sockets")
+	}
+}
+
 func TestResolveAddressPanic(t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
@@ -7,6 +7,7 @@ package goTap
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -212,3 +213,99 @@ func TestHTTPMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestParamsRegrowAfterLateRoutes(t *testing.T) {
+	r := New()
+	r.GET("/ping/:id", func(c *Context) {})
+	// Warm the context pool with contexts sized for one param
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ping/1", nil))
+
+	r.GET("/shops/:shop/orders/:order", func(c *Context) {
+		c.String(200, c.Param("shop")+"/"+c.Param("order"))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/shops/s1/orders/o2", nil))
+	if w.Code != 200 || w.Body.String() != "s1/o2" {
+		t.Errorf("Expected s1/o2, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestGroupNoRouteAndNoMethod(t *testing.T) {
+	r := New()
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *Context) {
+		c.Data(404, MIMEHTML, []byte("<h1>Not found</h1>"))
+	})
+	api := r.Group("/api", func(c *Context) {
+		c.Header("X-API", "1")
+		c.Next()
+	})
+	api.GET("/products", func(c *Context) {})
+	api.POST("/products", func(c *Context) {})
+	api.NoRoute(func(c *Context) {
+		c.JSON(404, H{"error": "not_found"})
+	})
+	v2 := api.Group("/v2")
+	v2.NoRoute(func(c *Context) {
+		c.JSON(404, H{"error": "not_found", "version": 2})
+	})
+	api.NoMethod(func(c *Context) {
+		c.JSON(405, H{"error": "method_not_allowed"})
+	})
+	r.GET("/home", func(c *Context) {})
+
+	for _, tt := range []struct {
+		method, path string
+		code         int
+		body, allow  string
+	}{
+		{"GET", "/api/missing", 404, `{"error":"not_found"}`, ""},
+		{"GET", "/api", 404, `{"error":"not_found"}`, ""},
+		{"GET", "/api/v2/missing", 404, `{"error":"not_found","version":2}`, ""},
+		{"GET", "/apix", 404, "<h1>Not found</h1>", ""},
+		{"DELETE", "/api/products", 405, `{"error":"method_not_allowed"}`, "GET, POST"},
+		{"POST", "/home", 405, "405 method not allowed", "GET"},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || strings.TrimSpace(w.Body.String()) != tt.body || w.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s: expected %d %q Allow %q, got %d %q Allow %q", tt.method, tt.path, tt.code, tt.body, tt.allow,
+				w.Code, w.Body.String(), w.Header().Get("Allow"))
+		}
+		if strings.HasPrefix(tt.path, "/api/") && w.Header().Get("X-API") != "1" {
+			t.Errorf("%s %s: expected the group middleware to run", tt.method, tt.path)
+		}
+	}
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	r := New()
+	r.Use(func(c *Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Next()
+	})
+	r.GET("/products/:id", func(c *Context) {})
+	r.PUT("/products/:id", func(c *Context) {})
+	r.OPTIONS("/custom", func(c *Context) { c.String(200, "custom") })
+
+	options := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("OPTIONS", path, nil))
+		return w
+	}
+	if w := options("/products/1"); w.Code != 404 {
+		t.Errorf("Expected 404 with HandleOPTIONS disabled, got %d", w.Code)
+	}
+
+	r.HandleOPTIONS = true
+	w := options("/products/1")
+	if w.Code != 204 || w.Header().Get("Allow") != "GET, PUT, OPTIONS" || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Unexpected auto OPTIONS response %d %v", w.Code, w.Header())
+	}
+	if w := options("/custom"); w.Body.String() != "custom" {
+		t.Errorf("Expected the registered OPTIONS route, got %q", w.Body.String())
+	}
+	if w := options("/missing"); w.Code != 404 {
+		t.Errorf("Expected 404 for an unknown path, got %d", w.Code)
+	}
+}
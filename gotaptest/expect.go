@@ -0,0 +1,156 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gotaptest
+
+import (
+	"encoding/json"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Expectation asserts on a response, reporting failures to the test and
+// returning itself so assertions chain
+type Expectation struct {
+	t   testing.TB
+	res *Response
+}
+
+// Expect starts assertions on the response. A request that couldn't be
+// built fails the test immediately.
+func (r *Response) Expect(t testing.TB) *Expectation {
+	t.Helper()
+	if r.Err != nil {
+		t.Fatalf("%v", r.Err)
+	}
+	return &Expectation{t: t, res: r}
+}
+
+func (e *Expectation) errorf(format string, args ...any) {
+	e.t.Helper()
+	args = append(args, e.res.Request.Method, e.res.Request.URL.RequestURI(), e.res.Body.String())
+	e.t.Errorf(format+"\n%s %s responded: %s", args...)
+}
+
+// Status asserts the status code
+func (e *Expectation) Status(code int) *Expectation {
+	e.t.Helper()
+	if e.res.Code != code {
+		e.errorf("Expected status %d, got %d", code, e.res.Code)
+	}
+	return e
+}
+
+// Header asserts a response header
+func (e *Expectation) Header(key, value string) *Expectation {
+	e.t.Helper()
+	if got := e.res.Header().Get(key); got != value {
+		e.errorf("Expected header %s %q, got %q", key, value, got)
+	}
+	return e
+}
+
+// NoHeader asserts a response header is absent
+func (e *Expectation) NoHeader(key string) *Expectation {
+	e.t.Helper()
+	if got, ok := e.res.Header()[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		e.errorf("Expected no header %s, got %q", key, got)
+	}
+	return e
+}
+
+// Body asserts the whole body
+func (e *Expectation) Body(body string) *Expectation {
+	e.t.Helper()
+	if got := e.res.Body.String(); got != body {
+		e.errorf("Expected body %q, got %q", body, got)
+	}
+	return e
+}
+
+// Contains asserts the body contains s
+func (e *Expectation) Contains(s string) *Expectation {
+	e.t.Helper()
+	if !strings.Contains(e.res.Body.String(), s) {
+		e.errorf("Expected the body to contain %q", s)
+	}
+	return e
+}
+
+// JSON asserts the JSON body equals want, compared after encoding want as
+// JSON so structs, maps and numbers of any type compare by value
+func (e *Expectation) JSON(want any) *Expectation {
+	e.t.Helper()
+	return e.JSONPath("", want)
+}
+
+// JSONPath asserts the value at a path into the JSON body, see Response.Path
+func (e *Expectation) JSONPath(path string, want any) *Expectation {
+	e.t.Helper()
+	got, err := e.res.Path(path)
+	if err != nil {
+		e.errorf("%v", err)
+		return e
+	}
+	if !jsonEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		e.errorf("Expected %q to be %s, got %s", path, wantJSON, gotJSON)
+	}
+	return e
+}
+
+// JSONPathExists asserts a path exists in the JSON body
+func (e *Expectation) JSONPathExists(path string) *Expectation {
+	e.t.Helper()
+	if _, err := e.res.Path(path); err != nil {
+		e.errorf("%v", err)
+	}
+	return e
+}
+
+// JSONLen asserts the length of the array or object at path
+func (e *Expectation) JSONLen(path string, n int) *Expectation {
+	e.t.Helper()
+	got, err := e.res.Path(path)
+	if err != nil {
+		e.errorf("%v", err)
+		return e
+	}
+	length := -1
+	switch v := got.(type) {
+	case []any:
+		length = len(v)
+	case map[string]any:
+		length = len(v)
+	}
+	if length != n {
+		e.errorf("Expected %q to have %d items, got %d", path, n, length)
+	}
+	return e
+}
+
+// Decode decodes the JSON body into v, failing the test if it can't
+func (e *Expectation) Decode(v any) *Expectation {
+	e.t.Helper()
+	if err := e.res.JSON(v); err != nil {
+		e.errorf("Decoding the JSON body: %v", err)
+	}
+	return e
+}
+
+// jsonEqual compares a decoded JSON value with want encoded as JSON
+func jsonEqual(got, want any) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, normalized)
+}
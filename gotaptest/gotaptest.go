@@ -0,0 +1,304 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package gotaptest provides a test client for goTap engines, replacing
+// the httptest boilerplate of building requests, recording responses and
+// decoding bodies:
+//
+//	tc := gotaptest.New(engine)
+//	tc.JWTSecret = "secret"
+//
+//	tc.POST("/products", gotaptest.WithJWT(goTap.JWTClaims{UserID: "42"}), gotaptest.WithJSON(product)).
+//	    Expect(t).
+//	    Status(201).
+//	    JSONPath("data.name", "Coffee")
+package gotaptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	goTap "github.com/jaswant99k/gotap"
+)
+
+// Client sends requests to a handler in memory. Cookies set by responses
+// are sent with later requests, so sessions work across calls.
+type Client struct {
+	// JWTSecret signs the tokens of WithJWT
+	JWTSecret string
+
+	// RemoteAddr is the client address of every request
+	// Default: "192.0.2.1:1234"
+	RemoteAddr string
+
+	handler  http.Handler
+	defaults []Option
+	jar      http.CookieJar
+
+	mu     sync.Mutex
+	server *httptest.Server
+}
+
+// New returns a client for handler, usually a *goTap.Engine
+func New(handler http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		RemoteAddr: "192.0.2.1:1234",
+		handler:    handler,
+		jar:        jar,
+	}
+}
+
+// Use adds options applied to every request before its own options, e.g.
+// authentication shared by a whole test
+func (c *Client) Use(opts ...Option) *Client {
+	c.defaults = append(c.defaults, opts...)
+	return c
+}
+
+// GET sends a GET request
+func (c *Client) GET(path string, opts ...Option) *Response {
+	return c.Do(http.MethodGet, path, opts...)
+}
+
+// POST sends a POST request
+func (c *Client) POST(path string, opts ...Option) *Response {
+	return c.Do(http.MethodPost, path, opts...)
+}
+
+// PUT sends a PUT request
+func (c *Client) PUT(path string, opts ...Option) *Response {
+	return c.Do(http.MethodPut, path, opts...)
+}
+
+// PATCH sends a PATCH request
+func (c *Client) PATCH(path string, opts ...Option) *Response {
+	return c.Do(http.MethodPatch, path, opts...)
+}
+
+// DELETE sends a DELETE request
+func (c *Client) DELETE(path string, opts ...Option) *Response {
+	return c.Do(http.MethodDelete, path, opts...)
+}
+
+// HEAD sends a HEAD request
+func (c *Client) HEAD(path string, opts ...Option) *Response {
+	return c.Do(http.MethodHead, path, opts...)
+}
+
+// OPTIONS sends an OPTIONS request
+func (c *Client) OPTIONS(path string, opts ...Option) *Response {
+	return c.Do(http.MethodOptions, path, opts...)
+}
+
+// Do sends a request with method to path. An option that fails is
+// reported by the response's Expect.
+func (c *Client) Do(method, path string, opts ...Option) *Response {
+	req, err := c.newRequest(method, path, opts)
+	if err != nil {
+		return &Response{Err: err, ResponseRecorder: httptest.NewRecorder()}
+	}
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, req)
+	c.jar.SetCookies(cookieURL(req), w.Result().Cookies())
+	return &Response{ResponseRecorder: w, Request: req}
+}
+
+func (c *Client) newRequest(method, path string, opts []Option) (*http.Request, error) {
+	r := &Request{client: c, header: http.Header{}, query: url.Values{}}
+	for _, opt := range append(c.defaults[:len(c.defaults):len(c.defaults)], opts...) {
+		opt(r)
+		if r.err != nil {
+			return nil, r.err
+		}
+	}
+
+	req := httptest.NewRequest(method, path, r.body)
+	if len(r.query) > 0 {
+		q := req.URL.Query()
+		for key, values := range r.query {
+			q[key] = append(q[key], values...)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	req.RemoteAddr = c.RemoteAddr
+	for _, cookie := range c.jar.Cookies(cookieURL(req)) {
+		req.AddCookie(cookie)
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	if r.remoteAddr != "" {
+		req.RemoteAddr = r.remoteAddr
+	}
+	return req, nil
+}
+
+// cookieURL returns the absolute URL of req for the cookie jar
+func cookieURL(req *http.Request) *url.URL {
+	return &url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path}
+}
+
+// Request collects the options of a request
+type Request struct {
+	client     *Client
+	header     http.Header
+	query      url.Values
+	cookies    []*http.Cookie
+	body       io.Reader
+	remoteAddr string
+	err        error
+}
+
+// Option configures a request
+type Option func(*Request)
+
+// WithHeader sets a request header
+func WithHeader(key, value string) Option {
+	return func(r *Request) { r.header.Set(key, value) }
+}
+
+// WithQuery adds a query parameter
+func WithQuery(key, value string) Option {
+	return func(r *Request) { r.query.Add(key, value) }
+}
+
+// WithCookie adds a cookie
+func WithCookie(cookie *http.Cookie) Option {
+	return func(r *Request) { r.cookies = append(r.cookies, cookie) }
+}
+
+// WithRemoteAddr sets the client address, e.g. "10.0.0.1:1234" to test IP
+// based middleware
+func WithRemoteAddr(addr string) Option {
+	return func(r *Request) { r.remoteAddr = addr }
+}
+
+// WithToken sends token as a bearer token
+func WithToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithBasicAuth sends HTTP basic auth credentials
+func WithBasicAuth(user, password string) Option {
+	return func(r *Request) {
+		req := http.Request{Header: http.Header{}}
+		req.SetBasicAuth(user, password)
+		r.header.Set("Authorization", req.Header.Get("Authorization"))
+	}
+}
+
+// WithJWT sends a bearer token for claims signed with the client's
+// JWTSecret. Tokens without an expiry are valid for an hour.
+func WithJWT(claims goTap.JWTClaims) Option {
+	return func(r *Request) {
+		if claims.ExpiresAt == 0 {
+			claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		}
+		token, err := goTap.GenerateJWT(r.client.JWTSecret, claims)
+		if err != nil {
+			r.err = fmt.Errorf("gotaptest: generate JWT: %w", err)
+			return
+		}
+		WithToken(token)(r)
+	}
+}
+
+// WithBody sends body with contentType
+func WithBody(contentType string, body []byte) Option {
+	return func(r *Request) {
+		r.body = bytes.NewReader(body)
+		r.header.Set("Content-Type", contentType)
+	}
+}
+
+// WithJSON sends v encoded as JSON
+func WithJSON(v any) Option {
+	return func(r *Request) {
+		body, err := json.Marshal(v)
+		if err != nil {
+			r.err = fmt.Errorf("gotaptest: encode JSON body: %w", err)
+			return
+		}
+		WithBody(goTap.MIMEJSON, body)(r)
+	}
+}
+
+// WithForm sends values as a URL encoded form
+func WithForm(values url.Values) Option {
+	return WithBody(goTap.MIMEPOSTForm, []byte(values.Encode()))
+}
+
+// Response is a recorded response
+type Response struct {
+	*httptest.ResponseRecorder
+
+	// Request is the request sent
+	Request *http.Request
+
+	// Err is the error of an option that failed to build the request
+	Err error
+}
+
+// Status returns the status code
+func (r *Response) Status() int {
+	return r.Code
+}
+
+// String returns the body as a string
+func (r *Response) String() string {
+	return r.Body.String()
+}
+
+// JSON decodes the body into v
+func (r *Response) JSON(v any) error {
+	return json.Unmarshal(r.Body.Bytes(), v)
+}
+
+// Path returns the value at a dot separated path into the JSON body, with
+// numeric segments indexing arrays, e.g. "data.items.0.name"
+func (r *Response) Path(path string) (any, error) {
+	var doc any
+	if err := r.JSON(&doc); err != nil {
+		return nil, fmt.Errorf("decode JSON body: %w", err)
+	}
+	return lookup(doc, path)
+}
+
+func lookup(doc any, path string) (any, error) {
+	if path == "" {
+		return doc, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := doc.(type) {
+		case map[string]any:
+			value, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("path %q: no key %q", path, key)
+			}
+			doc = value
+		case []any:
+			var i int
+			if _, err := fmt.Sscan(key, &i); err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("path %q: invalid index %q into %d items", path, key, len(v))
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("path %q: %q is not an object or array", path, key)
+		}
+	}
+	return doc, nil
+}
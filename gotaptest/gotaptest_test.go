@@ -0,0 +1,164 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gotaptest
+
+import (
+	"io"
+	"net/url"
+	"testing"
+
+	goTap "github.com/jaswant99k/gotap"
+)
+
+func newTestEngine() *goTap.Engine {
+	goTap.SetMode(goTap.TestMode)
+	r := goTap.New()
+	api := r.Group("/api", goTap.JWTAuth("secret"))
+	api.POST("/products", func(c *goTap.Context) {
+		var body map[string]any
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, goTap.H{"error": err.Error()})
+			return
+		}
+		claims, _ := goTap.GetJWTClaims(c)
+		c.Header("X-User", claims.UserID)
+		c.JSON(201, goTap.H{"data": body, "tags": []string{"hot", "new"}})
+	})
+	r.GET("/search", func(c *goTap.Context) {
+		c.JSON(200, goTap.H{"q": c.Query("q"), "session": c.GetHeader("Cookie")})
+	})
+	r.GET("/login", func(c *goTap.Context) {
+		c.SetCookie("session", "abc", 3600, "/", "", false, true)
+		c.Status(204)
+	})
+	r.POST("/form", func(c *goTap.Context) {
+		c.String(200, "%s", c.PostForm("name"))
+	})
+	r.POST("/upload", func(c *goTap.Context) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.String(400, "%s", err.Error())
+			return
+		}
+		f, _ := file.Open()
+		content, _ := io.ReadAll(f)
+		c.JSON(200, goTap.H{"name": c.PostForm("name"), "file": file.Filename, "content": string(content)})
+	})
+	r.GET("/ws", func(c *goTap.Context) {
+		c.WebSocket(func(ws *goTap.WebSocketConn) {
+			var msg map[string]string
+			for ws.ReadJSON(&msg) == nil {
+				ws.SendJSON(goTap.H{"echo": msg["text"], "user": c.Query("user")})
+			}
+		})
+	})
+	r.GET("/events", func(c *goTap.Context) {
+		for i, status := range []string{"placed", "paid"} {
+			c.Render(-1, goTap.SSEvent{Event: "order", ID: string(rune('1' + i)), Data: status})
+			c.Writer.Flush()
+		}
+	})
+	return r
+}
+
+func TestClient(t *testing.T) {
+	tc := New(newTestEngine())
+	tc.JWTSecret = "secret"
+
+	tc.POST("/api/products", WithJWT(goTap.JWTClaims{UserID: "42"}), WithJSON(goTap.H{"name": "Coffee", "price": 3.5})).
+		Expect(t).
+		Status(201).
+		Header("X-User", "42").
+		JSONPath("data.name", "Coffee").
+		JSONPath("data.price", 3.5).
+		JSONPath("tags.1", "new").
+		JSONLen("tags", 2)
+
+	tc.POST("/api/products", WithJSON(goTap.H{})).Expect(t).Status(401)
+
+	tc.GET("/login").Expect(t).Status(204)
+	tc.GET("/search", WithQuery("q", "tea")).
+		Expect(t).
+		JSON(goTap.H{"q": "tea", "session": "session=abc"})
+
+	tc.POST("/form", WithForm(url.Values{"name": {"Tea"}})).Expect(t).Body("Tea")
+
+	form := NewMultipart().Field("name", "receipt").File("file", "r.txt", []byte("total 9.99"))
+	tc.POST("/upload", WithMultipart(form)).
+		Expect(t).
+		Status(200).
+		JSON(map[string]string{"name": "receipt", "file": "r.txt", "content": "total 9.99"})
+}
+
+func TestExpectationFailures(t *testing.T) {
+	tc := New(newTestEngine())
+	tc.JWTSecret = "secret"
+	res := tc.POST("/api/products", WithJWT(goTap.JWTClaims{UserID: "42"}), WithJSON(goTap.H{"name": "Coffee"}))
+
+	for name, assert := range map[string]func(e *Expectation){
+		"status":  func(e *Expectation) { e.Status(200) },
+		"header":  func(e *Expectation) { e.Header("X-User", "7") },
+		"path":    func(e *Expectation) { e.JSONPath("data.name", "Tea") },
+		"missing": func(e *Expectation) { e.JSONPathExists("data.sku") },
+		"index":   func(e *Expectation) { e.JSONPath("tags.5", "new") },
+		"len":     func(e *Expectation) { e.JSONLen("tags", 3) },
+	} {
+		rec := &recordingTB{TB: t}
+		assert(res.Expect(rec))
+		if !rec.failed {
+			t.Errorf("%s: expected the assertion to fail", name)
+		}
+	}
+
+	if res := tc.POST("/api/products", WithJSON(func() {})); res.Err == nil {
+		t.Error("Expected an unencodable body to be reported")
+	}
+}
+
+func TestWebSocketAndSSE(t *testing.T) {
+	tc := New(newTestEngine())
+	defer tc.Close()
+
+	ws, err := tc.WebSocket("/ws", WithQuery("user", "ana"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SendJSON(goTap.H{"text": "hello"})
+	var reply map[string]string
+	if err := ws.ReadJSON(&reply); err != nil || reply["echo"] != "hello" || reply["user"] != "ana" {
+		t.Errorf("Unexpected reply %v: %v", reply, err)
+	}
+
+	stream, err := tc.SSE("/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	for _, want := range []Event{{ID: "1", Event: "order", Data: "placed"}, {ID: "2", Event: "order", Data: "paid"}} {
+		if event, err := stream.Next(); err != nil || event != want {
+			t.Errorf("Expected %+v, got %+v: %v", want, event, err)
+		}
+	}
+	if _, err := stream.Next(); err == nil {
+		t.Error("Expected the stream to end")
+	}
+	if _, err := tc.SSE("/missing"); err == nil {
+		t.Error("Expected an error for a missing stream")
+	}
+	if got := stream.Response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Unexpected content type %q", got)
+	}
+}
+
+// recordingTB records failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) { r.failed = true }
+func (r *recordingTB) Fatalf(format string, args ...any) { r.failed = true }
+func (r *recordingTB) Helper()                           {}
@@ -0,0 +1,76 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gotaptest
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// Multipart builds a multipart/form-data body:
+//
+//	form := gotaptest.NewMultipart().
+//	    Field("name", "Coffee").
+//	    File("image", "coffee.png", png)
+//	tc.POST("/products", gotaptest.WithMultipart(form))
+type Multipart struct {
+	buf    bytes.Buffer
+	writer *multipart.Writer
+	closed bool
+	err    error
+}
+
+// NewMultipart returns an empty multipart body
+func NewMultipart() *Multipart {
+	m := &Multipart{}
+	m.writer = multipart.NewWriter(&m.buf)
+	return m
+}
+
+// Field adds a form field
+func (m *Multipart) Field(name, value string) *Multipart {
+	if m.err == nil && !m.closed {
+		m.err = m.writer.WriteField(name, value)
+	}
+	return m
+}
+
+// File adds a file with content type application/octet-stream
+func (m *Multipart) File(field, filename string, content []byte) *Multipart {
+	return m.FileWithType(field, filename, "application/octet-stream", content)
+}
+
+// FileWithType adds a file with contentType
+func (m *Multipart) FileWithType(field, filename, contentType string, content []byte) *Multipart {
+	if m.err != nil || m.closed {
+		return m
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, filename))
+	header.Set("Content-Type", contentType)
+	part, err := m.writer.CreatePart(header)
+	if err == nil {
+		_, err = part.Write(content)
+	}
+	m.err = err
+	return m
+}
+
+// WithMultipart sends the multipart body m
+func WithMultipart(m *Multipart) Option {
+	return func(r *Request) {
+		if m.err == nil && !m.closed {
+			m.err = m.writer.Close()
+			m.closed = true
+		}
+		if m.err != nil {
+			r.err = fmt.Errorf("gotaptest: build multipart body: %w", m.err)
+			return
+		}
+		WithBody(m.writer.FormDataContentType(), m.buf.Bytes())(r)
+	}
+}
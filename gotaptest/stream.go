@@ -0,0 +1,213 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gotaptest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultTimeout bounds how long the WebSocket and SSE clients wait for a
+// message
+const DefaultTimeout = 5 * time.Second
+
+// Server returns a real HTTP server for the handler, started on first use,
+// for clients that need a network connection such as WebSocket. Close
+// stops it.
+func (c *Client) Server() *httptest.Server {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.server == nil {
+		c.server = httptest.NewServer(c.handler)
+	}
+	return c.server
+}
+
+// Close stops the server started by Server, WebSocket or SSE
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.server != nil {
+		c.server.Close()
+		c.server = nil
+	}
+}
+
+// WSConn is a WebSocket test connection
+type WSConn struct {
+	*websocket.Conn
+
+	// Timeout bounds each read
+	// Default: DefaultTimeout
+	Timeout time.Duration
+}
+
+// WebSocket opens a WebSocket connection to path, sending the headers and
+// query of opts with the handshake:
+//
+//	ws, err := tc.WebSocket("/ws", gotaptest.WithToken(token))
+//	ws.SendJSON(goTap.H{"type": "subscribe"})
+//	ws.ReadJSON(&msg)
+func (c *Client) WebSocket(path string, opts ...Option) (*WSConn, error) {
+	req, err := c.newRequest(http.MethodGet, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	url := "ws" + strings.TrimPrefix(c.Server().URL, "http") + req.URL.RequestURI()
+	conn, res, err := websocket.DefaultDialer.Dial(url, req.Header)
+	if err != nil {
+		if res != nil {
+			return nil, fmt.Errorf("gotaptest: websocket handshake to %s: %w (status %d)", path, err, res.StatusCode)
+		}
+		return nil, fmt.Errorf("gotaptest: websocket handshake to %s: %w", path, err)
+	}
+	return &WSConn{Conn: conn, Timeout: DefaultTimeout}, nil
+}
+
+// SendText sends a text message
+func (ws *WSConn) SendText(message string) error {
+	return ws.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+// SendJSON sends v as a JSON text message
+func (ws *WSConn) SendJSON(v any) error {
+	return ws.WriteJSON(v)
+}
+
+// ReadText reads the next message as text
+func (ws *WSConn) ReadText() (string, error) {
+	ws.SetReadDeadline(time.Now().Add(ws.Timeout))
+	_, data, err := ws.ReadMessage()
+	return string(data), err
+}
+
+// ReadJSON decodes the next message into v
+func (ws *WSConn) ReadJSON(v any) error {
+	ws.SetReadDeadline(time.Now().Add(ws.Timeout))
+	return ws.Conn.ReadJSON(v)
+}
+
+// Event is a received Server-Sent Event
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry int
+}
+
+// SSEStream reads Server-Sent Events from a response
+type SSEStream struct {
+	// Response is the streaming response; its headers are available
+	Response *http.Response
+
+	// Timeout bounds each Next
+	// Default: DefaultTimeout
+	Timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	events chan Event
+	err    error
+}
+
+// SSE opens an event stream from path:
+//
+//	stream, err := tc.SSE("/orders/stream")
+//	defer stream.Close()
+//	event, err := stream.Next()
+func (c *Client) SSE(path string, opts ...Option) (*SSEStream, error) {
+	req, err := c.newRequest(http.MethodGet, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Server().URL+req.URL.RequestURI(), nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	out.Header = req.Header
+	out.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(out)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("gotaptest: open event stream %s: %w", path, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("gotaptest: open event stream %s: status %d", path, res.StatusCode)
+	}
+
+	s := &SSEStream{Response: res, Timeout: DefaultTimeout, ctx: ctx, cancel: cancel, events: make(chan Event)}
+	go s.read()
+	return s, nil
+}
+
+func (s *SSEStream) read() {
+	defer close(s.events)
+	scanner := bufio.NewScanner(s.Response.Body)
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data != nil {
+				event.Data = strings.Join(data, "\n")
+				select {
+				case s.events <- event:
+				case <-s.ctx.Done():
+					return
+				}
+			}
+			event, data = Event{}, nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			event.Retry, _ = strconv.Atoi(value)
+		}
+	}
+	s.err = scanner.Err()
+}
+
+// Next returns the next event, waiting at most Timeout
+func (s *SSEStream) Next() (Event, error) {
+	select {
+	case event, ok := <-s.events:
+		if !ok {
+			if s.err != nil {
+				return Event{}, s.err
+			}
+			return Event{}, errors.New("gotaptest: event stream ended")
+		}
+		return event, nil
+	case <-time.After(s.Timeout):
+		return Event{}, errors.New("gotaptest: timed out waiting for an event")
+	}
+}
+
+// Close closes the stream
+func (s *SSEStream) Close() error {
+	s.cancel()
+	return s.Response.Body.Close()
+}
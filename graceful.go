@@ -0,0 +1,134 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// gracefulFDEnv tells a process started by RunGraceful that it inherited
+// the listening socket as file descriptor 3
+const gracefulFDEnv = "GOTAP_GRACEFUL_FD"
+
+// RunGraceful serves HTTP requests like Run and supports upgrading the
+// binary without dropping connections. On SIGUSR2 the executable is started
+// again and inherits the listening socket; once the new process serves, it
+// asks the old one to stop accepting connections and finish its active
+// requests, waiting up to the shutdown timeout. SIGINT and SIGTERM stop the
+// server the same way.
+//
+//	r.RunGraceful(":8080", goTap.WithShutdownTimeout(time.Minute))
+//
+//	# after replacing the binary
+//	kill -USR2 $(pidof pos-server)
+//
+// It returns nil once the server has been drained.
+func (engine *Engine) RunGraceful(addr string, opts ...ServerOption) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	config := serverConfig(opts)
+	srv, maxConns := engine.newServer(addr, opts)
+	l, inherited, err := gracefulListener(srv.Addr)
+	if err != nil {
+		return err
+	}
+	// The previous process serves while the new one warms up
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		l.Close()
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR2, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	engine.printRoutes()
+	debugPrint("Listening and serving HTTP on %s (pid %d)", l.Addr(), os.Getpid())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(limitListen(l, maxConns)) }()
+
+	if inherited {
+		// The new process serves: the parent can drain
+		if err := syscall.Kill(os.Getppid(), syscall.SIGTERM); err != nil {
+			debugPrint("[WARNING] could not stop the previous process: %v", err)
+		}
+	}
+
+	for {
+		select {
+		case err := <-served:
+			return err
+		case sig := <-sigs:
+			if sig == syscall.SIGUSR2 {
+				if pid, err := restartProcess(l); err != nil {
+					debugPrint("[WARNING] graceful restart failed, still serving: %v", err)
+				} else {
+					debugPrint("Started process %d, handing over", pid)
+				}
+				continue
+			}
+			debugPrint("Draining connections (pid %d)", os.Getpid())
+			ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+			err := srv.Shutdown(ctx)
+			cancel()
+			return err
+		}
+	}
+}
+
+// gracefulListener returns the socket inherited from the previous process,
+// or a new one
+func gracefulListener(addr string) (net.Listener, bool, error) {
+	if os.Getenv(gracefulFDEnv) == "" {
+		l, err := net.Listen("tcp", addr)
+		return l, false, err
+	}
+	os.Unsetenv(gracefulFDEnv)
+	f := os.NewFile(3, "gotap-listener")
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, false, fmt.Errorf("inherited listener: %w", err)
+	}
+	return l, true, nil
+}
+
+// restartProcess starts the executable again with the same arguments,
+// passing it the listening socket
+func restartProcess(l net.Listener) (int, error) {
+	filer, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return 0, errors.New("listener can't be inherited")
+	}
+	f, err := filer.File()
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	path, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), gracefulFDEnv+"=3")
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	// Reap the process if it exits while this one still runs
+	go cmd.Wait()
+	return cmd.Process.Pid, nil
+}
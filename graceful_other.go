@@ -0,0 +1,18 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package goTap
+
+import (
+	"errors"
+	"runtime"
+)
+
+// RunGraceful serves HTTP requests with zero-downtime restarts on SIGUSR2.
+// It is not supported on this platform.
+func (engine *Engine) RunGraceful(addr string, opts ...ServerOption) error {
+	return errors.New("goTap: graceful restart is not supported on " + runtime.GOOS)
+}
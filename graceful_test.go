@@ -0,0 +1,97 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package goTap
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestGracefulHelper is the server process of TestRunGracefulRestart
+func TestGracefulHelper(t *testing.T) {
+	addr := os.Getenv("GOTAP_GRACEFUL_TEST_ADDR")
+	if addr == "" {
+		t.Skip("helper process")
+	}
+	r := New()
+	r.GET("/pid", func(c *Context) {
+		c.Writer.WriteString(strconv.Itoa(os.Getpid()))
+	})
+	if err := r.RunGraceful(addr, WithShutdownTimeout(5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunGracefulRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts server processes")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGracefulHelper$")
+	cmd.Env = append(os.Environ(), "GOTAP_GRACEFUL_TEST_ADDR="+addr)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	servingPID := func() int {
+		resp, err := client.Get("http://" + addr + "/pid")
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		pid, _ := strconv.Atoi(string(body))
+		return pid
+	}
+	waitPID := func(accept func(pid int) bool) int {
+		for i := 0; i < 200; i++ {
+			if pid := servingPID(); pid != 0 && accept(pid) {
+				return pid
+			}
+			time.Sleep(25 * time.Millisecond)
+		}
+		t.Fatal("Timed out waiting for the server")
+		return 0
+	}
+
+	if pid := waitPID(func(int) bool { return true }); pid != cmd.Process.Pid {
+		t.Fatalf("Expected the first process to serve, got pid %d", pid)
+	}
+
+	cmd.Process.Signal(syscall.SIGUSR2)
+	newPID := waitPID(func(pid int) bool { return pid != cmd.Process.Pid })
+	defer syscall.Kill(newPID, syscall.SIGTERM)
+
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Errorf("Expected the old process to drain and exit cleanly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("Expected the old process to exit")
+	}
+	if pid := servingPID(); pid != newPID {
+		t.Errorf("Expected the new process to keep serving, got pid %d", pid)
+	}
+}
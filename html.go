@@ -0,0 +1,360 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// htmlRender executes a named template
+type htmlRender interface {
+	render(w io.Writer, name string, data any) error
+}
+
+// SetFuncMap sets the functions available to templates loaded afterwards
+func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
+	engine.FuncMap = funcMap
+}
+
+// Delims sets the action delimiters of templates loaded afterwards
+func (engine *Engine) Delims(left, right string) *Engine {
+	engine.delims = Delims{Left: left, Right: right}
+	return engine
+}
+
+// AddHTMLData adds values to the data of every HTML template rendered with
+// Context.HTML, such as the CSRF token or the signed-in user for a layout.
+// Values are added when the handler passes an H, a map[string]any or nil;
+// the handler's own values take precedence.
+//
+//	r.AddHTMLData(func(c *goTap.Context) goTap.H {
+//	    user, _ := c.Get("user")
+//	    return goTap.H{"user": user}
+//	})
+func (engine *Engine) AddHTMLData(fn func(c *Context) H) {
+	engine.htmlData = append(engine.htmlData, fn)
+}
+
+func (engine *Engine) newTemplate() *template.Template {
+	return template.New("").Delims(engine.delims.Left, engine.delims.Right).Funcs(engine.FuncMap)
+}
+
+// LoadHTMLGlob loads HTML templates from a glob pattern
+func (engine *Engine) LoadHTMLGlob(pattern string) {
+	engine.loadHTMLSet(func() (*template.Template, error) {
+		return engine.newTemplate().ParseGlob(pattern)
+	})
+}
+
+// LoadHTMLFiles loads HTML templates from specific files
+func (engine *Engine) LoadHTMLFiles(files ...string) {
+	engine.loadHTMLSet(func() (*template.Template, error) {
+		return engine.newTemplate().ParseFiles(files...)
+	})
+}
+
+// loadHTMLSet loads a template set; in debug mode it is parsed again on
+// every render so edits show up without a restart
+func (engine *Engine) loadHTMLSet(load func() (*template.Template, error)) {
+	set := &htmlSet{templates: template.Must(load())}
+	if IsDebugging() {
+		set.reload = load
+	}
+	engine.htmlRender = set
+}
+
+// SetHTMLTemplate sets a custom HTML template
+func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
+	engine.htmlRender = &htmlSet{templates: templ}
+}
+
+// htmlSet renders from a single template set
+type htmlSet struct {
+	templates *template.Template
+	reload    func() (*template.Template, error)
+}
+
+func (s *htmlSet) render(w io.Writer, name string, data any) error {
+	templates := s.templates
+	if s.reload != nil {
+		var err error
+		if templates, err = s.reload(); err != nil {
+			return err
+		}
+	}
+	return templates.ExecuteTemplate(w, name, data)
+}
+
+// HTMLConfig holds configuration for LoadHTMLWithConfig
+type HTMLConfig struct {
+	// Dirs hold the templates. A template in an earlier directory replaces
+	// one with the same name in a later directory, e.g. a theme over the
+	// defaults.
+	// Required.
+	Dirs []string
+
+	// FS holds Dirs, e.g. an embed.FS
+	// Default: the OS file system
+	FS fs.FS
+
+	// Extension of template files
+	// Default: ".html"
+	Extension string
+
+	// LayoutsDir is the subdirectory of each dir holding layouts
+	// Default: "layouts"
+	LayoutsDir string
+
+	// PartialsDir is the subdirectory of each dir holding partials
+	// Default: "partials"
+	PartialsDir string
+
+	// DisableReload stops templates from being parsed again when their
+	// files change in debug mode
+	DisableReload bool
+}
+
+// LoadHTMLDirs loads templates from directories with layouts and partials,
+// see LoadHTMLWithConfig
+func (engine *Engine) LoadHTMLDirs(dirs ...string) {
+	engine.LoadHTMLWithConfig(HTMLConfig{Dirs: dirs})
+}
+
+// LoadHTMLWithConfig loads page templates composed with layouts and
+// partials. Pages are named by their path without the extension, e.g.
+// "products" or "admin/users"; layouts and partials by their file name
+// without the extension. Every page can use every partial and layout, and
+// defines the blocks its layout leaves open:
+//
+//	templates/layouts/main.html:   <html>{{template "nav" .}}{{block "content" .}}{{end}}</html>
+//	templates/partials/nav.html:   <nav>{{.user}}</nav>
+//	templates/products.html:       {{define "content"}}<ul>...</ul>{{end}}
+//
+//	r.LoadHTMLDirs("templates")
+//	c.HTML(200, "main:products", data)   // products in the main layout
+//	c.HTML(200, "products", data)        // products on its own
+//
+// In debug mode templates are parsed again when their files change.
+func (engine *Engine) LoadHTMLWithConfig(config HTMLConfig) {
+	if len(config.Dirs) == 0 {
+		panic("goTap: LoadHTMLWithConfig needs at least one directory")
+	}
+	if config.Extension == "" {
+		config.Extension = ".html"
+	}
+	if config.LayoutsDir == "" {
+		config.LayoutsDir = "layouts"
+	}
+	if config.PartialsDir == "" {
+		config.PartialsDir = "partials"
+	}
+
+	h := &htmlDirs{
+		config: config,
+		reload: IsDebugging() && !config.DisableReload,
+		newSet: engine.newTemplate,
+	}
+	if err := h.load(); err != nil {
+		panic(err)
+	}
+	engine.htmlRender = h
+}
+
+// htmlDirs renders pages composed with layouts and partials; each page is
+// parsed into its own copy of the layouts and partials so pages can define
+// the same blocks
+type htmlDirs struct {
+	config HTMLConfig
+	reload bool
+	newSet func() *template.Template
+
+	mu      sync.RWMutex
+	pages   map[string]*template.Template
+	version string
+}
+
+// templateFile is where a template is read from
+type templateFile struct {
+	fsys fs.FS
+	path string
+}
+
+// scan finds the layouts, partials and pages, and a version string that
+// changes whenever a file is added, removed or modified
+func (h *htmlDirs) scan() (layouts, partials, pages map[string]templateFile, version string, err error) {
+	layouts = make(map[string]templateFile)
+	partials = make(map[string]templateFile)
+	pages = make(map[string]templateFile)
+	var stamps []string
+
+	for _, dir := range h.config.Dirs {
+		fsys := h.config.FS
+		if fsys == nil {
+			fsys, dir = os.DirFS(dir), "."
+		}
+		err = fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(p) != h.config.Extension {
+				return err
+			}
+			name := strings.TrimSuffix(p, h.config.Extension)
+			if dir != "." {
+				name = strings.TrimPrefix(name, dir+"/")
+			}
+
+			kind := pages
+			if sub, rest, ok := strings.Cut(name, "/"); ok && sub == h.config.LayoutsDir {
+				kind, name = layouts, rest
+			} else if ok && sub == h.config.PartialsDir {
+				kind, name = partials, rest
+			}
+			if _, exists := kind[name]; exists {
+				return nil
+			}
+			kind[name] = templateFile{fsys: fsys, path: p}
+
+			if h.reload {
+				if info, err := d.Info(); err == nil {
+					stamps = append(stamps, fmt.Sprintf("%s:%d:%d", p, info.Size(), info.ModTime().UnixNano()))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, nil, "", err
+		}
+	}
+	sort.Strings(stamps)
+	return layouts, partials, pages, strings.Join(stamps, "|"), nil
+}
+
+func (h *htmlDirs) load() error {
+	layouts, partials, pages, version, err := h.scan()
+	if err != nil {
+		return err
+	}
+
+	base := h.newSet()
+	for _, files := range []map[string]templateFile{partials, layouts} {
+		for name, file := range files {
+			if err := parseTemplateFile(base.New(name), file); err != nil {
+				return err
+			}
+		}
+	}
+
+	parsed := make(map[string]*template.Template, len(pages))
+	for name, file := range pages {
+		set, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if err := parseTemplateFile(set.New(name), file); err != nil {
+			return err
+		}
+		parsed[name] = set
+	}
+
+	h.mu.Lock()
+	h.pages, h.version = parsed, version
+	h.mu.Unlock()
+	return nil
+}
+
+func parseTemplateFile(t *template.Template, file templateFile) error {
+	src, err := fs.ReadFile(file.fsys, file.path)
+	if err != nil {
+		return err
+	}
+	if _, err := t.Parse(string(src)); err != nil {
+		return fmt.Errorf("parsing %s: %w", file.path, err)
+	}
+	return nil
+}
+
+func (h *htmlDirs) render(w io.Writer, name string, data any) error {
+	if h.reload {
+		_, _, _, version, err := h.scan()
+		if err != nil {
+			return err
+		}
+		h.mu.RLock()
+		changed := version != h.version
+		h.mu.RUnlock()
+		if changed {
+			if err := h.load(); err != nil {
+				return err
+			}
+		}
+	}
+
+	layout, page, ok := strings.Cut(name, ":")
+	if !ok {
+		layout, page = "", name
+	}
+	h.mu.RLock()
+	set := h.pages[page]
+	h.mu.RUnlock()
+	if set == nil {
+		return fmt.Errorf("html template %q not found", page)
+	}
+	if layout == "" {
+		return set.ExecuteTemplate(w, page, data)
+	}
+	if set.Lookup(layout) == nil {
+		return fmt.Errorf("html layout %q not found", layout)
+	}
+	return set.ExecuteTemplate(w, layout, data)
+}
+
+// HTML renders the HTTP template specified by its name. With templates
+// loaded by LoadHTMLDirs, "layout:page" renders page within layout.
+func (c *Context) HTML(code int, name string, obj interface{}) {
+	c.Status(code)
+	c.setContentType("text/html; charset=utf-8")
+
+	if c.engine == nil || c.engine.htmlRender == nil {
+		panic("HTML templates not loaded. Use LoadHTMLGlob(), LoadHTMLFiles() or LoadHTMLDirs()")
+	}
+
+	if err := c.engine.htmlRender.render(c.Writer, name, c.htmlData(obj)); err != nil {
+		panic(err)
+	}
+}
+
+// htmlData adds the engine's AddHTMLData values to obj
+func (c *Context) htmlData(obj any) any {
+	if len(c.engine.htmlData) == 0 {
+		return obj
+	}
+	var own map[string]any
+	switch v := obj.(type) {
+	case nil:
+	case H:
+		own = v
+	case map[string]any:
+		own = v
+	default:
+		return obj
+	}
+
+	data := make(H, len(own))
+	for _, fn := range c.engine.htmlData {
+		for k, v := range fn(c) {
+			data[k] = v
+		}
+	}
+	for k, v := range own {
+		data[k] = v
+	}
+	return data
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeTemplates(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// htmlRenderer registers a route rendering the named template with data
+func htmlRenderer(r *Engine) func(name string, data any) *httptest.ResponseRecorder {
+	var data any
+	r.GET("/render/*name", func(c *Context) {
+		c.HTML(200, strings.TrimPrefix(c.Param("name"), "/"), data)
+	})
+	return func(name string, d any) *httptest.ResponseRecorder {
+		data = d
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/render/"+name, nil))
+		return w
+	}
+}
+
+func TestHTMLFuncMapAndDelims(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{"receipt.html": "<p>[[.total | money]]</p>"})
+
+	r := New()
+	r.Delims("[[", "]]")
+	r.SetFuncMap(template.FuncMap{
+		"money": func(cents int) string { return fmt.Sprintf("$%.2f", float64(cents)/100) },
+	})
+	r.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	if w := htmlRenderer(r)("receipt.html", H{"total": 1250}); w.Body.String() != "<p>$12.50</p>" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+}
+
+func TestHTMLLayoutsAndPartials(t *testing.T) {
+	theme, base := t.TempDir(), t.TempDir()
+	writeTemplates(t, base, map[string]string{
+		"layouts/main.html":  `<main>{{template "nav" .}}{{block "content" .}}empty{{end}}</main>`,
+		"partials/nav.html":  `<nav>{{.user}}</nav>`,
+		"products.html":      `{{define "content"}}products: {{.count}}{{end}}`,
+		"admin/users.html":   `{{define "content"}}users{{end}}`,
+		"layouts/plain.html": `{{block "content" .}}{{end}}`,
+	})
+	// The theme replaces the nav partial
+	writeTemplates(t, theme, map[string]string{"partials/nav.html": `<nav class="theme">{{.user}}</nav>`})
+
+	r := New()
+	r.Use(Recovery())
+	r.AddHTMLData(func(c *Context) H { return H{"user": "alice", "count": 0} })
+	r.LoadHTMLDirs(theme, base)
+	render := htmlRenderer(r)
+
+	for name, want := range map[string]string{
+		"main:products":    `<main><nav class="theme">alice</nav>products: 3</main>`,
+		"main:admin/users": `<main><nav class="theme">alice</nav>users</main>`,
+		"plain:products":   `products: 3`,
+	} {
+		if w := render(name, H{"count": 3}); w.Body.String() != want {
+			t.Errorf("%s: expected %q, got %q", name, want, w.Body.String())
+		}
+	}
+
+	if w := render("missing:products", nil); w.Code != 500 {
+		t.Errorf("Expected 500 for a missing layout, got %d", w.Code)
+	}
+}
+
+func TestHTMLDirsFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"web/layouts/main.html": {Data: []byte(`<body>{{block "content" .}}{{end}}</body>`)},
+		"web/index.html":        {Data: []byte(`{{define "content"}}home{{end}}`)},
+	}
+	r := New()
+	r.LoadHTMLWithConfig(HTMLConfig{FS: fsys, Dirs: []string{"web"}})
+	if w := htmlRenderer(r)("main:index", nil); w.Body.String() != "<body>home</body>" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+}
+
+func TestHTMLReloadInDebugMode(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{"index.html": "v1"})
+	r := New()
+	r.LoadHTMLDirs(dir)
+	r.GET("/", func(c *Context) { c.HTML(200, "index", nil) })
+
+	get := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+	if body := get(); body != "v1" {
+		t.Fatalf("Expected v1, got %q", body)
+	}
+	writeTemplates(t, dir, map[string]string{"index.html": "version 2"})
+	if body := get(); body != "version 2" {
+		t.Errorf("Expected reloaded template, got %q", body)
+	}
+
+	SetMode(ReleaseMode)
+	r.LoadHTMLDirs(dir)
+	writeTemplates(t, dir, map[string]string{"index.html": "version 3"})
+	if body := get(); body != "version 2" {
+		t.Errorf("Expected no reload in release mode, got %q", body)
+	}
+}
@@ -0,0 +1,479 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResourceOptions holds the document-level parts of a JSON:API or HAL
+// response
+type ResourceOptions struct {
+	// BaseURL prefixes the self links of resources, which are
+	// BaseURL/<type>/<id>, e.g. "https://api.example.com/v1"
+	// Default: "" (links relative to the host)
+	BaseURL string
+
+	// Pagination adds first, prev, next and last links and the page
+	// metadata to a collection
+	// Default: the pagination of the Paginate middleware, if any
+	Pagination *Pagination
+
+	// PageParam is the query parameter the page links set
+	// Default: "page"
+	PageParam string
+
+	// Include lists the relationships whose resources are added to the
+	// "included" member of a JSON:API document; HAL always embeds loaded
+	// relationships
+	// Default: the comma-separated "include" query parameter
+	Include []string
+
+	// Meta is added to the document's meta (JSON:API) or top level (HAL)
+	// Optional.
+	Meta H
+}
+
+// JSONAPI writes data, a struct or a slice of structs, as a JSON:API
+// document. Resources are described with "api" struct tags:
+//
+//	type Product struct {
+//	    ID         uint      `json:"id" api:"id,products"`
+//	    Name       string    `json:"name"`
+//	    Category   *Category `json:"-" api:"rel,category"`
+//	    SupplierID uint      `json:"-" api:"rel,supplier,suppliers"`
+//	}
+//
+//	c.JSONAPI(http.StatusOK, products, goTap.ResourceOptions{})
+//
+// The field tagged "id,<type>" is the resource's id and type; without one
+// the field named ID is used with the type returned by a ResourceType()
+// string method. Fields tagged "rel,<name>" are relationships: a struct,
+// struct pointer or slice of them is linked by its id, and added to
+// "included" when requested; a scalar field is the id of a resource of
+// the type given as third option. Other fields are attributes named by
+// their json tags; "api:\"-\"" leaves a field out.
+func (c *Context) JSONAPI(code int, data any, opts ResourceOptions) {
+	b := c.resourceBuilder(data, opts)
+	doc := H{}
+	if b.collection {
+		// Resources in data must not be repeated in included
+		for i := 0; i < b.data.Len(); i++ {
+			if item := reflect.Indirect(b.data.Index(i)); item.IsValid() {
+				b.seen[b.elem.typ+"/"+b.elem.id(item)] = true
+			}
+		}
+		resources := make([]H, 0, b.data.Len())
+		for i := 0; i < b.data.Len(); i++ {
+			resources = append(resources, b.jsonAPIResource(b.data.Index(i), true))
+		}
+		doc["data"] = resources
+	} else if b.data.Kind() == reflect.Ptr && b.data.IsNil() {
+		doc["data"] = nil
+	} else {
+		b.seen[b.elem.typ+"/"+b.elem.id(reflect.Indirect(b.data))] = true
+		doc["data"] = b.jsonAPIResource(b.data, true)
+	}
+	if len(b.included) > 0 {
+		doc["included"] = b.included
+	}
+
+	links := b.documentLinks()
+	doc["links"] = links
+	meta := H{}
+	if b.pagination != nil && b.collection {
+		meta["page"] = b.pagination.Response()
+	}
+	for k, v := range opts.Meta {
+		meta[k] = v
+	}
+	if len(meta) > 0 {
+		doc["meta"] = meta
+	}
+	c.renderResource(code, MIMEJSONAPI, doc)
+}
+
+// HAL writes data, a struct or a slice of structs, as a HAL document,
+// with the "api" struct tags of JSONAPI: each resource has its attributes,
+// a self link, links to scalar relationships and its loaded relationships
+// in "_embedded". A collection embeds its items under their resource type
+// and adds the page links and counts of the pagination:
+//
+//	{"_links": {"self": ..., "next": ...}, "_embedded": {"products": [...]}, "page": 2, ...}
+func (c *Context) HAL(code int, data any, opts ResourceOptions) {
+	b := c.resourceBuilder(data, opts)
+	if !b.collection {
+		resource := H{}
+		if !(b.data.Kind() == reflect.Ptr && b.data.IsNil()) {
+			resource = b.halResource(b.data)
+		}
+		c.renderResource(code, MIMEHAL, resource)
+		return
+	}
+
+	items := make([]H, 0, b.data.Len())
+	for i := 0; i < b.data.Len(); i++ {
+		items = append(items, b.halResource(b.data.Index(i)))
+	}
+	name := "items"
+	if b.elem.typ != "" {
+		name = b.elem.typ
+	}
+	doc := H{"_embedded": H{name: items}}
+	links := H{}
+	for rel, href := range b.documentLinks() {
+		links[rel] = H{"href": href}
+	}
+	doc["_links"] = links
+	if b.pagination != nil {
+		for k, v := range b.pagination.Response() {
+			doc[k] = v
+		}
+	}
+	for k, v := range opts.Meta {
+		doc[k] = v
+	}
+	c.renderResource(code, MIMEHAL, doc)
+}
+
+func (c *Context) renderResource(code int, contentType string, doc H) {
+	c.Status(code)
+	c.setContentType(contentType)
+	e := getJSONEncoder(true)
+	defer putJSONEncoder(e)
+	if err := e.encode(doc); err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := c.Writer.Write(e.buf.Bytes()); err != nil {
+		c.Error(err)
+	}
+}
+
+// resourceBuilder builds the resources of one document
+type resourceBuilder struct {
+	c          *Context
+	opts       ResourceOptions
+	data       reflect.Value
+	elem       *resourceType
+	collection bool
+	pagination *Pagination
+	include    map[string]bool
+	included   []H
+	seen       map[string]bool
+}
+
+func (c *Context) resourceBuilder(data any, opts ResourceOptions) *resourceBuilder {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() {
+		panic("goTap: resources are nil")
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() != reflect.Struct {
+		v = v.Elem()
+	}
+	b := &resourceBuilder{c: c, opts: opts, data: v, seen: make(map[string]bool)}
+	t := v.Type()
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		b.collection = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("goTap: resources must be structs or slices of structs, got %T", data))
+	}
+	b.elem = resourceTypeOf(t)
+
+	b.pagination = opts.Pagination
+	if b.pagination == nil {
+		b.pagination, _ = GetPagination(c)
+	}
+	if b.opts.PageParam == "" {
+		b.opts.PageParam = "page"
+	}
+	include := opts.Include
+	if include == nil && c.Query("include") != "" {
+		include = strings.Split(c.Query("include"), ",")
+	}
+	b.include = make(map[string]bool, len(include))
+	for _, name := range include {
+		b.include[strings.TrimSpace(name)] = true
+	}
+	return b
+}
+
+// jsonAPIResource returns a resource object; top-level resources add
+// their requested relationships to the included resources
+func (b *resourceBuilder) jsonAPIResource(v reflect.Value, top bool) H {
+	v = reflect.Indirect(v)
+	rt := resourceTypeOf(v.Type())
+	if rt.typ == "" {
+		panic(fmt.Sprintf("goTap: %s has no resource type, tag its id with api:\"id,<type>\"", v.Type()))
+	}
+	id := rt.id(v)
+	resource := H{"type": rt.typ, "id": id}
+
+	attributes := H{}
+	relationships := H{}
+	for _, f := range rt.fields {
+		fv := v.FieldByIndex(f.index)
+		switch {
+		case f.rel == "":
+			if !f.omitEmpty || !fv.IsZero() {
+				attributes[f.name] = fv.Interface()
+			}
+		case f.relType != "":
+			var linkage any
+			if !fv.IsZero() {
+				linkage = H{"type": f.relType, "id": formatCell(fv)}
+			}
+			relationships[f.rel] = H{"data": linkage}
+		default:
+			related := relatedValues(fv)
+			linkages := make([]H, 0, len(related))
+			for _, r := range related {
+				rrt := resourceTypeOf(r.Type())
+				linkages = append(linkages, H{"type": rrt.typ, "id": rrt.id(r)})
+				if top && b.include[f.rel] {
+					b.addIncluded(r)
+				}
+			}
+			switch {
+			case fv.Kind() == reflect.Slice:
+				relationships[f.rel] = H{"data": linkages}
+			case len(linkages) == 1:
+				relationships[f.rel] = H{"data": linkages[0]}
+			default:
+				relationships[f.rel] = H{"data": nil}
+			}
+		}
+	}
+	if len(attributes) > 0 {
+		resource["attributes"] = attributes
+	}
+	if len(relationships) > 0 {
+		resource["relationships"] = relationships
+	}
+	resource["links"] = H{"self": b.resourceURL(rt.typ, id)}
+	return resource
+}
+
+func (b *resourceBuilder) addIncluded(v reflect.Value) {
+	rt := resourceTypeOf(v.Type())
+	key := rt.typ + "/" + rt.id(v)
+	if b.seen[key] {
+		return
+	}
+	b.seen[key] = true
+	b.included = append(b.included, b.jsonAPIResource(v, false))
+}
+
+// halResource returns a resource with its links and embedded resources
+func (b *resourceBuilder) halResource(v reflect.Value) H {
+	v = reflect.Indirect(v)
+	rt := resourceTypeOf(v.Type())
+	resource := H{}
+	links := H{}
+	embedded := H{}
+	if rt.typ != "" {
+		links["self"] = H{"href": b.resourceURL(rt.typ, rt.id(v))}
+	}
+	if rt.idIndex != nil {
+		resource[rt.idName] = v.FieldByIndex(rt.idIndex).Interface()
+	}
+	for _, f := range rt.fields {
+		fv := v.FieldByIndex(f.index)
+		switch {
+		case f.rel == "":
+			if !f.omitEmpty || !fv.IsZero() {
+				resource[f.name] = fv.Interface()
+			}
+		case f.relType != "":
+			if !fv.IsZero() {
+				links[f.rel] = H{"href": b.resourceURL(f.relType, formatCell(fv))}
+			}
+		default:
+			related := relatedValues(fv)
+			if fv.Kind() == reflect.Slice {
+				items := make([]H, len(related))
+				for i, r := range related {
+					items[i] = b.halResource(r)
+				}
+				embedded[f.rel] = items
+			} else if len(related) == 1 {
+				embedded[f.rel] = b.halResource(related[0])
+			}
+		}
+	}
+	if len(links) > 0 {
+		resource["_links"] = links
+	}
+	if len(embedded) > 0 {
+		resource["_embedded"] = embedded
+	}
+	return resource
+}
+
+func (b *resourceBuilder) resourceURL(typ, id string) string {
+	return strings.TrimSuffix(b.opts.BaseURL, "/") + "/" + typ + "/" + url.PathEscape(id)
+}
+
+// documentLinks returns the self link and, for a paginated collection,
+// the page links of the request
+func (b *resourceBuilder) documentLinks() map[string]string {
+	u := *b.c.Request.URL
+	links := map[string]string{"self": u.RequestURI()}
+	p := b.pagination
+	if !b.collection || p == nil || p.Page < 1 {
+		return links
+	}
+	page := func(n int64) string {
+		query := u.Query()
+		query.Set(b.opts.PageParam, strconv.FormatInt(n, 10))
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+	links["first"] = page(1)
+	if p.Page > 1 {
+		links["prev"] = page(int64(p.Page) - 1)
+	}
+	if p.Pages > 0 {
+		links["last"] = page(p.Pages)
+		if int64(p.Page) < p.Pages {
+			links["next"] = page(int64(p.Page) + 1)
+		}
+	}
+	return links
+}
+
+// relatedValues returns the loaded structs of a relationship field
+func relatedValues(v reflect.Value) []reflect.Value {
+	if v.Kind() == reflect.Slice {
+		related := make([]reflect.Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item := reflect.Indirect(v.Index(i)); item.IsValid() {
+				related = append(related, item)
+			}
+		}
+		return related
+	}
+	if v = reflect.Indirect(v); !v.IsValid() || v.IsZero() {
+		return nil
+	}
+	return []reflect.Value{v}
+}
+
+// resourceType describes the "api" tags of a struct
+type resourceType struct {
+	typ     string
+	idIndex []int
+	idName  string // attribute name of the id in HAL
+	fields  []resourceField
+}
+
+type resourceField struct {
+	index     []int
+	name      string // attribute name
+	omitEmpty bool
+	rel       string // relationship name
+	relType   string // type of a scalar relationship
+}
+
+var resourceTypes sync.Map // reflect.Type -> *resourceType
+
+func resourceTypeOf(t reflect.Type) *resourceType {
+	if rt, ok := resourceTypes.Load(t); ok {
+		return rt.(*resourceType)
+	}
+	rt := &resourceType{}
+	rt.collect(t, nil)
+	if rt.typ == "" {
+		if typer, ok := reflect.Zero(t).Interface().(interface{ ResourceType() string }); ok {
+			rt.typ = typer.ResourceType()
+		}
+	}
+	if rt.idIndex == nil {
+		if f, ok := t.FieldByName("ID"); ok {
+			rt.idIndex = f.Index
+		}
+	}
+	// The id is not an attribute
+	fields := rt.fields[:0]
+	for _, f := range rt.fields {
+		if reflect.DeepEqual(f.index, rt.idIndex) {
+			rt.idName = f.name
+			continue
+		}
+		fields = append(fields, f)
+	}
+	rt.fields = fields
+	if rt.idName == "" {
+		rt.idName = "id"
+	}
+	resourceTypes.Store(t, rt)
+	return rt
+}
+
+// collect adds the fields of t, flattening embedded structs such as
+// gorm.Model
+func (rt *resourceType) collect(t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag := strings.Split(field.Tag.Get("api"), ",")
+		jsonName, jsonOpts, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		switch tag[0] {
+		case "-":
+			continue
+		case "id":
+			rt.idIndex = fieldIndex
+			if len(tag) > 1 {
+				rt.typ = tag[1]
+			}
+			if jsonName != "" && jsonName != "-" {
+				rt.idName = jsonName
+			}
+			continue
+		case "rel":
+			if len(tag) < 2 {
+				panic(fmt.Sprintf("goTap: relationship %s.%s needs a name", t, field.Name))
+			}
+			f := resourceField{index: fieldIndex, rel: tag[1]}
+			if len(tag) > 2 {
+				f.relType = tag[2]
+			}
+			rt.fields = append(rt.fields, f)
+			continue
+		}
+
+		if field.Anonymous && jsonName == "" && field.Type.Kind() == reflect.Struct {
+			rt.collect(field.Type, fieldIndex)
+			continue
+		}
+		if !field.IsExported() || jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		f := resourceField{index: fieldIndex, name: jsonName, omitEmpty: strings.Contains(jsonOpts, "omitempty")}
+		rt.fields = append(rt.fields, f)
+	}
+}
+
+// id returns the id of a resource value
+func (rt *resourceType) id(v reflect.Value) string {
+	if rt.idIndex == nil {
+		return ""
+	}
+	return formatCell(v.FieldByIndex(rt.idIndex))
+}
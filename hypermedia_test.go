@@ -0,0 +1,132 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type apiCategory struct {
+	ID   uint   `json:"id" api:"id,categories"`
+	Name string `json:"name"`
+}
+
+type apiTag struct {
+	Name string `json:"name"`
+}
+
+func (apiTag) ResourceType() string { return "tags" }
+
+type apiProduct struct {
+	ID         uint         `json:"id" api:"id,products"`
+	Name       string       `json:"name"`
+	Note       string       `json:"note,omitempty"`
+	Secret     string       `json:"-"`
+	Category   *apiCategory `json:"-" api:"rel,category"`
+	Tags       []apiTag     `json:"-" api:"rel,tags"`
+	SupplierID uint         `json:"-" api:"rel,supplier,suppliers"`
+}
+
+func hypermediaRouter() *Engine {
+	coffee := &apiCategory{ID: 7, Name: "Coffee"}
+	products := []apiProduct{
+		{ID: 1, Name: "Latte", Category: coffee, SupplierID: 3},
+		{ID: 2, Name: "Mocha", Category: coffee},
+	}
+	r := New()
+	pages := r.Group("", Paginate())
+	pages.GET("/jsonapi", func(c *Context) {
+		p, _ := GetPagination(c)
+		p.SetTotal(5)
+		c.JSONAPI(http.StatusOK, products, ResourceOptions{BaseURL: "https://api.example.com/"})
+	})
+	pages.GET("/hal", func(c *Context) {
+		p, _ := GetPagination(c)
+		p.SetTotal(5)
+		c.HAL(http.StatusOK, products, ResourceOptions{})
+	})
+	r.GET("/hal/one", func(c *Context) { c.HAL(http.StatusOK, &products[0], ResourceOptions{}) })
+	return r
+}
+
+func decodeDocument(t *testing.T, r *Engine, path string) (map[string]any, string) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return doc, w.Header().Get("Content-Type")
+}
+
+func TestContextJSONAPI(t *testing.T) {
+	doc, contentType := decodeDocument(t, hypermediaRouter(), "/jsonapi?page=2&per_page=2&include=category")
+	if contentType != MIMEJSONAPI {
+		t.Errorf("Unexpected content type %s", contentType)
+	}
+
+	data := doc["data"].([]any)
+	first := data[0].(map[string]any)
+	attributes := first["attributes"].(map[string]any)
+	relationships := first["relationships"].(map[string]any)
+	if first["type"] != "products" || first["id"] != "1" || attributes["name"] != "Latte" ||
+		attributes["id"] != nil || attributes["note"] != nil || attributes["Secret"] != nil {
+		t.Errorf("Unexpected resource %v", first)
+	}
+	if relationships["category"].(map[string]any)["data"].(map[string]any)["id"] != "7" ||
+		relationships["supplier"].(map[string]any)["data"].(map[string]any)["type"] != "suppliers" ||
+		len(relationships["tags"].(map[string]any)["data"].([]any)) != 0 {
+		t.Errorf("Unexpected relationships %v", relationships)
+	}
+	if data[1].(map[string]any)["relationships"].(map[string]any)["supplier"].(map[string]any)["data"] != nil {
+		t.Error("Expected a null linkage for an unset scalar relationship")
+	}
+	if first["links"].(map[string]any)["self"] != "https://api.example.com/products/1" {
+		t.Errorf("Unexpected resource links %v", first["links"])
+	}
+
+	included := doc["included"].([]any)
+	if len(included) != 1 || included[0].(map[string]any)["type"] != "categories" {
+		t.Errorf("Expected the shared category to be included once, got %v", included)
+	}
+
+	links := doc["links"].(map[string]any)
+	if links["prev"] != "/jsonapi?include=category&page=1&page_size=2&per_page=2" ||
+		links["next"] != "/jsonapi?include=category&page=3&page_size=2&per_page=2" ||
+		links["last"] != "/jsonapi?include=category&page=3&page_size=2&per_page=2" {
+		t.Errorf("Unexpected page links %v", links)
+	}
+	if doc["meta"].(map[string]any)["page"].(map[string]any)["total"] != float64(5) {
+		t.Errorf("Unexpected meta %v", doc["meta"])
+	}
+}
+
+func TestContextHAL(t *testing.T) {
+	r := hypermediaRouter()
+	doc, contentType := decodeDocument(t, r, "/hal?page=1&per_page=2")
+	if contentType != MIMEHAL {
+		t.Errorf("Unexpected content type %s", contentType)
+	}
+	items := doc["_embedded"].(map[string]any)["products"].([]any)
+	links := doc["_links"].(map[string]any)
+	if len(items) != 2 || links["next"].(map[string]any)["href"] != "/hal?page=2&page_size=2&per_page=2" ||
+		links["prev"] != nil || doc["total"] != float64(5) {
+		t.Errorf("Unexpected collection %v", doc)
+	}
+
+	one, _ := decodeDocument(t, r, "/hal/one")
+	oneLinks := one["_links"].(map[string]any)
+	embedded := one["_embedded"].(map[string]any)
+	if one["id"] != float64(1) || one["name"] != "Latte" ||
+		oneLinks["self"].(map[string]any)["href"] != "/products/1" ||
+		oneLinks["supplier"].(map[string]any)["href"] != "/suppliers/3" ||
+		embedded["category"].(map[string]any)["name"] != "Coffee" ||
+		embedded["category"].(map[string]any)["_links"].(map[string]any)["self"].(map[string]any)["href"] != "/categories/7" {
+		t.Errorf("Unexpected resource %v", one)
+	}
+}
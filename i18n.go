@@ -0,0 +1,531 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// I18nKey is the context key holding the request's translation bundle
+const I18nKey = "gotap.i18n"
+
+// pluralCategories are the CLDR plural categories
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// message is a translation, with plural forms by category if it has any
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+// Bundle holds translations by locale. Messages are loaded from JSON or
+// YAML files named by locale, e.g. locales/de.json or locales/pt-BR.yaml.
+// Nested objects make dotted keys, and objects of plural categories make
+// plural messages:
+//
+//	{
+//	  "checkout": {
+//	    "total": "Total: {amount}",
+//	    "items": {"one": "{count} item", "other": "{count} items"}
+//	  }
+//	}
+//
+// Translations fall back from the locale to its language, e.g. de-AT to
+// de, and then to the default locale.
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]message
+}
+
+// NewBundle creates an empty bundle falling back to defaultLocale
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      make(map[string]map[string]message),
+	}
+}
+
+// LoadBundle creates a bundle with the translation files of fsys
+func LoadBundle(fsys fs.FS, defaultLocale string) (*Bundle, error) {
+	b := NewBundle(defaultLocale)
+	if err := b.LoadFS(fsys); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// LoadFS adds the .json, .yaml and .yml files of fsys and its
+// subdirectories, taking the locale from the file name
+func (b *Bundle) LoadFS(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := path.Ext(name)
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var messages map[string]any
+		if ext == ".json" {
+			err = json.Unmarshal(data, &messages)
+		} else {
+			err = yaml.Unmarshal(data, &messages)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse translations %s: %w", name, err)
+		}
+		return b.Add(strings.TrimSuffix(path.Base(name), ext), messages)
+	})
+}
+
+// Add adds messages for locale, replacing existing keys
+func (b *Bundle) Add(locale string, messages map[string]any) error {
+	flat := make(map[string]message)
+	if err := flattenMessages("", messages, flat); err != nil {
+		return fmt.Errorf("translations %s: %w", locale, err)
+	}
+	locale = normalizeLocale(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.messages[locale] == nil {
+		b.messages[locale] = make(map[string]message, len(flat))
+	}
+	for key, msg := range flat {
+		b.messages[locale][key] = msg
+	}
+	return nil
+}
+
+func flattenMessages(prefix string, messages map[string]any, out map[string]message) error {
+	for key, v := range messages {
+		key = prefix + key
+		switch v := v.(type) {
+		case string:
+			out[key] = message{text: v}
+		case map[string]any:
+			if plural, ok := pluralForms(v); ok {
+				out[key] = message{text: plural["other"], plural: plural}
+				continue
+			}
+			if err := flattenMessages(key+".", v, out); err != nil {
+				return err
+			}
+		case nil:
+			return fmt.Errorf("message %s is empty", key)
+		default:
+			out[key] = message{text: fmt.Sprint(v)}
+		}
+	}
+	return nil
+}
+
+// pluralForms returns the forms of an object of plural categories with
+// at least an "other" form
+func pluralForms(v map[string]any) (map[string]string, bool) {
+	if _, ok := v["other"]; !ok {
+		return nil, false
+	}
+	forms := make(map[string]string, len(v))
+	for category, form := range v {
+		s, ok := form.(string)
+		if !ok || !containsString(pluralCategories, category) {
+			return nil, false
+		}
+		forms[category] = s
+	}
+	return forms, true
+}
+
+// Locales returns the bundle's locales, sorted
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supports reports whether the bundle has translations for locale or its
+// language
+func (b *Bundle) Supports(locale string) bool {
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.messages[locale] != nil || b.messages[language] != nil
+}
+
+func (b *Bundle) lookup(locale, key string) (message, bool) {
+	language, _, _ := strings.Cut(locale, "-")
+	defaultLanguage, _, _ := strings.Cut(b.defaultLocale, "-")
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range []string{locale, language, b.defaultLocale, defaultLanguage} {
+		if msg, ok := b.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return message{}, false
+}
+
+// Translate returns the message for key in locale with its "{name}"
+// placeholders replaced by args, or key if there is none. A "count"
+// argument selects the plural form by the language's plural rule, and a
+// "zero" form, if present, is used for a count of 0 in any language.
+// Numbers, Money and dates are formatted for locale.
+func (b *Bundle) Translate(locale, key string, args H) string {
+	locale = normalizeLocale(locale)
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return key
+	}
+	text := msg.text
+	if msg.plural != nil {
+		if n, ok := pluralCount(args["count"]); ok {
+			category := PluralCategory(locale, n)
+			if n == 0 && msg.plural["zero"] != "" {
+				category = "zero"
+			}
+			if form, ok := msg.plural[category]; ok {
+				text = form
+			}
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	formatted := make(H, len(args))
+	for name, v := range args {
+		formatted[name] = formatMessageArg(locale, v)
+	}
+	return expandParams(text, formatted)
+}
+
+// FuncMap returns the template function "t", translating a key for a
+// locale with name and value pairs as arguments:
+//
+//	r.SetFuncMap(bundle.FuncMap())
+//	r.AddHTMLData(goTap.LocaleHTMLData)
+//
+//	<h1>{{t .locale "checkout.title"}}</h1>
+//	<p>{{t .locale "checkout.items" "count" .cart.Count}}</p>
+func (b *Bundle) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"t": func(locale, key string, pairs ...any) (string, error) {
+			if len(pairs)%2 != 0 {
+				return "", fmt.Errorf("t %s: odd number of arguments", key)
+			}
+			args := make(H, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				name, ok := pairs[i].(string)
+				if !ok {
+					return "", fmt.Errorf("t %s: argument name %v is not a string", key, pairs[i])
+				}
+				args[name] = pairs[i+1]
+			}
+			return b.Translate(locale, key, args), nil
+		},
+	}
+}
+
+func pluralCount(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// formatMessageArg formats numbers, Money and dates for locale
+func formatMessageArg(locale string, v any) any {
+	switch v := v.(type) {
+	case Money:
+		return FormatMoney(locale, v)
+	case *Money:
+		if v != nil {
+			return FormatMoney(locale, *v)
+		}
+	case time.Time:
+		return FormatDate(locale, v)
+	case float32, float64:
+		n, _ := pluralCount(v)
+		decimals := 0
+		if s := strconv.FormatFloat(n, 'f', -1, 64); strings.Contains(s, ".") {
+			decimals = len(s) - strings.Index(s, ".") - 1
+		}
+		return FormatNumber(locale, n, decimals)
+	default:
+		if n, ok := pluralCount(v); ok {
+			return FormatNumber(locale, n, 0)
+		}
+	}
+	return v
+}
+
+// PluralRule returns the CLDR plural category of n: "zero", "one", "two",
+// "few", "many" or "other"
+type PluralRule func(n float64) string
+
+var (
+	pluralRulesMu sync.RWMutex
+	pluralRules   = map[string]PluralRule{
+		"fr": pluralOneBelowTwo,
+		"pt": pluralOneBelowTwo,
+		"hi": pluralOneBelowTwo,
+		"ru": pluralEastSlavic,
+		"uk": pluralEastSlavic,
+		"be": pluralEastSlavic,
+		"pl": pluralPolish,
+		"cs": pluralCzech,
+		"sk": pluralCzech,
+		"ar": pluralArabic,
+		"ja": pluralNone,
+		"zh": pluralNone,
+		"ko": pluralNone,
+		"vi": pluralNone,
+		"th": pluralNone,
+		"id": pluralNone,
+		"ms": pluralNone,
+	}
+)
+
+// RegisterPluralRule sets the plural rule of a language, e.g. "lt".
+// Languages without a rule use "one" for 1 and "other" otherwise, as in
+// English or German.
+func RegisterPluralRule(language string, rule PluralRule) {
+	pluralRulesMu.Lock()
+	defer pluralRulesMu.Unlock()
+	pluralRules[strings.ToLower(language)] = rule
+}
+
+// PluralCategory returns the plural category of n in locale
+func PluralCategory(locale string, n float64) string {
+	language, _, _ := strings.Cut(normalizeLocale(locale), "-")
+	pluralRulesMu.RLock()
+	rule, ok := pluralRules[language]
+	pluralRulesMu.RUnlock()
+	if !ok {
+		rule = pluralOne
+	}
+	return rule(n)
+}
+
+func pluralOne(n float64) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func pluralNone(float64) string {
+	return "other"
+}
+
+func pluralOneBelowTwo(n float64) string {
+	if n >= 0 && n < 2 {
+		return "one"
+	}
+	return "other"
+}
+
+// slavicFew reports whether an integer ends in 2-4 but not 12-14
+func slavicFew(i int64) bool {
+	return i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14)
+}
+
+func pluralEastSlavic(n float64) string {
+	if n != math.Trunc(n) {
+		return "other"
+	}
+	i := int64(math.Abs(n))
+	switch {
+	case i%10 == 1 && i%100 != 11:
+		return "one"
+	case slavicFew(i):
+		return "few"
+	}
+	return "many"
+}
+
+func pluralPolish(n float64) string {
+	if n != math.Trunc(n) {
+		return "other"
+	}
+	i := int64(math.Abs(n))
+	switch {
+	case i == 1:
+		return "one"
+	case slavicFew(i):
+		return "few"
+	}
+	return "many"
+}
+
+func pluralCzech(n float64) string {
+	switch {
+	case n != math.Trunc(n):
+		return "many"
+	case n == 1:
+		return "one"
+	case n >= 2 && n <= 4:
+		return "few"
+	}
+	return "other"
+}
+
+func pluralArabic(n float64) string {
+	if n != math.Trunc(n) {
+		return "other"
+	}
+	i := int64(math.Abs(n))
+	switch {
+	case i == 0:
+		return "zero"
+	case i == 1:
+		return "one"
+	case i == 2:
+		return "two"
+	case i%100 >= 3 && i%100 <= 10:
+		return "few"
+	case i%100 >= 11:
+		return "many"
+	}
+	return "other"
+}
+
+// I18nConfig defines the config for I18n middleware
+type I18nConfig struct {
+	// Bundle holds the translations
+	Bundle *Bundle
+
+	// DefaultLocale is used when the request asks for no supported locale
+	// Default: the bundle's default locale
+	DefaultLocale string
+
+	// QueryParam is a query parameter selecting the locale
+	// Default: "lang"
+	QueryParam string
+
+	// CookieName is a cookie selecting the locale
+	// Default: "lang"
+	CookieName string
+}
+
+// I18n returns a middleware translating with the JSON or YAML files of
+// bundleFS, see Bundle. It panics if the files can't be loaded.
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	r.Use(goTap.I18n(locales, "en"))
+//	r.GET("/cart", func(c *goTap.Context) {
+//	    c.JSON(200, goTap.H{"message": c.T("checkout.items", goTap.H{"count": len(items)})})
+//	})
+func I18n(bundleFS fs.FS, defaultLocale string) HandlerFunc {
+	bundle, err := LoadBundle(bundleFS, defaultLocale)
+	if err != nil {
+		panic(err)
+	}
+	return I18nWithConfig(I18nConfig{Bundle: bundle})
+}
+
+// I18nWithConfig returns an I18n middleware with config. The locale is
+// taken from the query parameter, the cookie or the Accept-Language header,
+// in that order, if the bundle supports it, and set with SetLocale so
+// formatting follows it too.
+func I18nWithConfig(config I18nConfig) HandlerFunc {
+	if config.Bundle == nil {
+		panic("I18n: Bundle is required")
+	}
+	if config.DefaultLocale == "" {
+		config.DefaultLocale = config.Bundle.defaultLocale
+	}
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+
+	return func(c *Context) {
+		locale := ""
+		if lang := c.Query(config.QueryParam); lang != "" && config.Bundle.Supports(lang) {
+			locale = lang
+		} else if lang, err := c.Cookie(config.CookieName); err == nil && lang != "" && config.Bundle.Supports(lang) {
+			locale = lang
+		} else {
+			locale = negotiateLocale(c.GetHeader("Accept-Language"), config.DefaultLocale, config.Bundle.Supports)
+		}
+		c.SetLocale(locale)
+		c.Set(I18nKey, config.Bundle)
+		c.Header("Content-Language", c.Locale())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// T translates key for the request's locale, see Bundle.Translate. It
+// returns key if no I18n middleware is in use.
+//
+//	c.T("checkout.total", goTap.H{"amount": order.Total})
+func (c *Context) T(key string, args ...H) string {
+	bundle, ok := c.Get(I18nKey)
+	if !ok {
+		return key
+	}
+	b, ok := bundle.(*Bundle)
+	if !ok {
+		return key
+	}
+	merged := H{}
+	for _, a := range args {
+		for name, v := range a {
+			merged[name] = v
+		}
+	}
+	return b.Translate(c.Locale(), key, merged)
+}
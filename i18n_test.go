@@ -0,0 +1,173 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testLocales = fstest.MapFS{
+	"locales/en.json": {Data: []byte(`{
+		"checkout": {
+			"title": "Checkout",
+			"total": "Total: {amount}",
+			"items": {"zero": "Your cart is empty", "one": "{count} item", "other": "{count} items"}
+		},
+		"ordered": "Ordered on {date}"
+	}`)},
+	"locales/de.yaml": {Data: []byte(`
+checkout:
+  title: Kasse
+  total: "Summe: {amount}"
+  items:
+    one: "{count} Artikel"
+    other: "{count} Artikel"
+ordered: "Bestellt am {date}"
+`)},
+	"locales/ru.json":   {Data: []byte(`{"checkout": {"items": {"one": "{count} товар", "few": "{count} товара", "many": "{count} товаров", "other": "{count} товара"}}}`)},
+	"locales/README.md": {Data: []byte("not translations")},
+}
+
+func TestBundleTranslate(t *testing.T) {
+	b, err := LoadBundle(testLocales, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Locales(); len(got) != 3 || got[0] != "de" || got[1] != "en" || got[2] != "ru" {
+		t.Errorf("Unexpected locales %v", got)
+	}
+
+	total := Money{Amount: 123450, Currency: "EUR"}
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale, key string
+		args        H
+		want        string
+	}{
+		{"en-US", "checkout.title", nil, "Checkout"},
+		{"de-AT", "checkout.title", nil, "Kasse"},
+		{"fr-FR", "checkout.title", nil, "Checkout"},
+		{"ru", "checkout.title", nil, "Checkout"},
+		{"en-US", "checkout.missing", nil, "checkout.missing"},
+		{"de-DE", "checkout.total", H{"amount": total}, "Summe: 1.234,50 €"},
+		{"en-US", "checkout.total", H{"amount": total}, "Total: €1,234.50"},
+		{"de-DE", "ordered", H{"date": date}, "Bestellt am 14.03.2025"},
+		{"en-US", "checkout.items", H{"count": 0}, "Your cart is empty"},
+		{"en-US", "checkout.items", H{"count": 1}, "1 item"},
+		{"en-US", "checkout.items", H{"count": 1500}, "1,500 items"},
+		{"de-DE", "checkout.items", H{"count": 0}, "0 Artikel"},
+		{"de-DE", "checkout.items", H{"count": 2.5}, "2,5 Artikel"},
+		{"ru", "checkout.items", H{"count": 21}, "21 товар"},
+		{"ru", "checkout.items", H{"count": 3}, "3 товара"},
+		{"ru", "checkout.items", H{"count": 11}, "11 товаров"},
+		{"en-US", "checkout.items", nil, "{count} items"},
+	}
+	for _, tt := range tests {
+		if got := b.Translate(tt.locale, tt.key, tt.args); got != tt.want {
+			t.Errorf("Translate(%s, %s, %v) = %q, want %q", tt.locale, tt.key, tt.args, got, tt.want)
+		}
+	}
+
+	if err := b.Add("en", map[string]any{"bad": nil}); err == nil {
+		t.Error("Expected an empty message to fail")
+	}
+	if _, err := LoadBundle(fstest.MapFS{"en.json": {Data: []byte("{")}}, "en"); err == nil {
+		t.Error("Expected invalid JSON to fail")
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      float64
+		want   string
+	}{
+		{"en", 1, "one"},
+		{"en", 0, "other"},
+		{"fr", 0, "one"},
+		{"fr", 1.5, "one"},
+		{"fr", 2, "other"},
+		{"pl", 1, "one"},
+		{"pl", 22, "few"},
+		{"pl", 21, "many"},
+		{"ru", 111, "many"},
+		{"cs", 3, "few"},
+		{"cs", 1.5, "many"},
+		{"ar", 2, "two"},
+		{"ar", 105, "few"},
+		{"ar", 111, "many"},
+		{"ar", 100, "other"},
+		{"ja", 1, "other"},
+	}
+	for _, tt := range tests {
+		if got := PluralCategory(tt.locale, tt.n); got != tt.want {
+			t.Errorf("PluralCategory(%s, %v) = %s, want %s", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestI18n(t *testing.T) {
+	r := New()
+	r.Use(I18n(testLocales, "en"))
+	r.GET("/cart", func(c *Context) {
+		c.String(http.StatusOK, c.Locale()+" "+c.T("checkout.items", H{"count": 1}))
+	})
+
+	tests := []struct {
+		url, cookie, accept string
+		want                string
+	}{
+		{"/cart", "", "", "en 1 item"},
+		{"/cart", "", "de-CH,de;q=0.9,en;q=0.8", "de-CH 1 Artikel"},
+		{"/cart", "", "fr-FR,ru;q=0.5", "ru 1 товар"},
+		{"/cart", "", "fr-FR", "en 1 item"},
+		{"/cart", "lang=de", "en", "de 1 Artikel"},
+		{"/cart?lang=ru", "lang=de", "en", "ru 1 товар"},
+		{"/cart?lang=xx", "", "de", "de 1 Artikel"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		if tt.cookie != "" {
+			req.Header.Set("Cookie", tt.cookie)
+		}
+		if tt.accept != "" {
+			req.Header.Set("Accept-Language", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s cookie=%q accept=%q: got %q, want %q", tt.url, tt.cookie, tt.accept, w.Body.String(), tt.want)
+		}
+		if lang := w.Header().Get("Content-Language"); lang == "" {
+			t.Error("Expected a Content-Language header")
+		}
+	}
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	if got := c.T("checkout.title"); got != "checkout.title" {
+		t.Errorf("Expected the key without I18n middleware, got %q", got)
+	}
+}
+
+func TestBundleFuncMap(t *testing.T) {
+	b, _ := LoadBundle(testLocales, "en")
+	tmpl := template.Must(template.New("cart").Funcs(b.FuncMap()).Parse(`{{t .locale "checkout.title"}}: {{t .locale "checkout.items" "count" .count}}`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, H{"locale": "de-DE", "count": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Kasse: 3 Artikel" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+	if err := template.Must(template.New("bad").Funcs(b.FuncMap()).Parse(`{{t "en" "x" "count"}}`)).Execute(&buf, nil); err == nil {
+		t.Error("Expected an odd number of arguments to fail")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// JSONCodec encodes and decodes JSON for rendering (c.JSON, c.IndentedJSON,
+// c.SecureJSON, ...) and binding (c.BindJSON, c.ShouldBindJSON, ...).
+// Replace it with SetJSONCodec or the jsoniter and sonic build tags:
+//
+//	go build -tags jsoniter .
+//	go build -tags sonic .
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) JSONEncoder
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+// JSONEncoder writes JSON values to a stream, like json.Encoder
+type JSONEncoder interface {
+	Encode(v any) error
+	SetEscapeHTML(on bool)
+	SetIndent(prefix, indent string)
+}
+
+// JSONDecoder reads JSON values from a stream, like json.Decoder
+type JSONDecoder interface {
+	Decode(v any) error
+}
+
+// StdJSON is the encoding/json codec used by default
+var StdJSON JSONCodec = stdJSONCodec{}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (stdJSONCodec) NewEncoder(w io.Writer) JSONEncoder { return json.NewEncoder(w) }
+func (stdJSONCodec) NewDecoder(r io.Reader) JSONDecoder { return json.NewDecoder(r) }
+
+// jsonCodecState wraps the current codec so pooled encoders can tell
+// whether they were created by it
+type jsonCodecState struct {
+	codec JSONCodec
+}
+
+var currentJSONCodec atomic.Pointer[jsonCodecState]
+
+func init() {
+	currentJSONCodec.Store(&jsonCodecState{codec: StdJSON})
+}
+
+// SetJSONCodec replaces the codec used for rendering and binding JSON, e.g.
+// with jsoniter:
+//
+//	var api = jsoniter.ConfigCompatibleWithStandardLibrary
+//
+//	type jsoniterCodec struct{}
+//
+//	func (jsoniterCodec) Marshal(v any) ([]byte, error)      { return api.Marshal(v) }
+//	func (jsoniterCodec) Unmarshal(data []byte, v any) error { return api.Unmarshal(data, v) }
+//	func (jsoniterCodec) NewEncoder(w io.Writer) goTap.JSONEncoder { return api.NewEncoder(w) }
+//	func (jsoniterCodec) NewDecoder(r io.Reader) goTap.JSONDecoder { return api.NewDecoder(r) }
+//
+//	goTap.SetJSONCodec(jsoniterCodec{})
+//
+// Call it during startup, before serving requests. A nil codec restores
+// StdJSON.
+func SetJSONCodec(codec JSONCodec) {
+	if codec == nil {
+		codec = StdJSON
+	}
+	currentJSONCodec.Store(&jsonCodecState{codec: codec})
+}
+
+// GetJSONCodec returns the codec used for rendering and binding JSON
+func GetJSONCodec() JSONCodec {
+	return currentJSONCodec.Load().codec
+}
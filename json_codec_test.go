@@ -0,0 +1,91 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingCodec delegates to encoding/json and counts what goes through it
+type countingCodec struct {
+	encoders, decoders, unmarshals *atomic.Int32
+}
+
+func (c countingCodec) Marshal(v any) ([]byte, error) { return StdJSON.Marshal(v) }
+
+func (c countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals.Add(1)
+	return StdJSON.Unmarshal(data, v)
+}
+
+func (c countingCodec) NewEncoder(w io.Writer) JSONEncoder {
+	c.encoders.Add(1)
+	return StdJSON.NewEncoder(w)
+}
+
+func (c countingCodec) NewDecoder(r io.Reader) JSONDecoder {
+	c.decoders.Add(1)
+	return StdJSON.NewDecoder(r)
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	codec := countingCodec{new(atomic.Int32), new(atomic.Int32), new(atomic.Int32)}
+	SetJSONCodec(codec)
+	defer SetJSONCodec(nil)
+	if GetJSONCodec() != codec {
+		t.Fatal("Expected the custom codec")
+	}
+
+	type item struct {
+		SKU string `json:"sku" binding:"required"`
+	}
+	r := New()
+	r.POST("/bind", func(c *Context) {
+		var it item
+		if err := c.ShouldBindJSON(&it); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.JSON(200, it)
+	})
+	r.POST("/any", func(c *Context) {
+		var it item
+		if err := c.ShouldBind(&it); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.IndentedJSON(200, it)
+	})
+	post := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"sku":"C-1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/bind"); w.Code != 200 || w.Body.String() != `{"sku":"C-1"}`+"\n" {
+		t.Errorf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w := post("/any"); w.Code != 200 || w.Body.String() != "{\n    \"sku\": \"C-1\"\n}" {
+		t.Errorf("Unexpected indented response %d %q", w.Code, w.Body.String())
+	}
+	if codec.encoders.Load() == 0 || codec.decoders.Load()+codec.unmarshals.Load() != 2 {
+		t.Errorf("Expected rendering and binding through the codec, got %d encoders, %d decoders and %d unmarshals",
+			codec.encoders.Load(), codec.decoders.Load(), codec.unmarshals.Load())
+	}
+
+	// Pooled encoders of a replaced codec aren't used
+	SetJSONCodec(nil)
+	encoders := codec.encoders.Load()
+	post("/bind")
+	if GetJSONCodec() != StdJSON || codec.encoders.Load() != encoders {
+		t.Error("Expected encoding/json to be restored")
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build jsoniter
+
+package goTap
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Building with -tags jsoniter renders and binds JSON with jsoniter
+func init() {
+	SetJSONCodec(jsoniterCodec{api: jsoniter.ConfigCompatibleWithStandardLibrary})
+}
+
+type jsoniterCodec struct {
+	api jsoniter.API
+}
+
+func (c jsoniterCodec) Marshal(v any) ([]byte, error)      { return c.api.Marshal(v) }
+func (c jsoniterCodec) Unmarshal(data []byte, v any) error { return c.api.Unmarshal(data, v) }
+func (c jsoniterCodec) NewEncoder(w io.Writer) JSONEncoder { return c.api.NewEncoder(w) }
+func (c jsoniterCodec) NewDecoder(r io.Reader) JSONDecoder { return c.api.NewDecoder(r) }
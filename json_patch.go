@@ -0,0 +1,270 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPatch is returned by ApplyJSONPatch for patches that do not
+// match the document
+var ErrInvalidPatch = errors.New("invalid JSON patch")
+
+// maxArrayDiffCells bounds the LCS table used to diff arrays; larger arrays
+// are replaced as a whole
+const maxArrayDiffCells = 1 << 22
+
+// JSONPatchOp is a single RFC 6902 JSON Patch operation
+type JSONPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON omits the value of remove operations only, so null values
+// are kept for add and replace
+func (op JSONPatchOp) MarshalJSON() ([]byte, error) {
+	if op.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	type plain JSONPatchOp
+	return json.Marshal(plain(op))
+}
+
+// DiffJSON returns the RFC 6902 patch turning the document from into to.
+// Array elements that are objects with an "id" field are matched by id, so
+// changing one item of a collection yields operations for that item only.
+func DiffJSON(from, to []byte) ([]JSONPatchOp, error) {
+	a, err := decodeJSONDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decodeJSONDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffJSONValue(nil, "", a, b), nil
+}
+
+// ApplyJSONPatch applies add, remove and replace operations to a document
+func ApplyJSONPatch(doc []byte, patch []JSONPatchOp) ([]byte, error) {
+	v, err := decodeJSONDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range patch {
+		if v, err = applyJSONPatchOp(v, op); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(v)
+}
+
+func decodeJSONDocument(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffJSONValue(ops []JSONPatchOp, path string, a, b any) []JSONPatchOp {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			return diffJSONObject(ops, path, a, b)
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			return diffJSONArray(ops, path, a, b)
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, JSONPatchOp{Op: "replace", Path: path, Value: b})
+	}
+	return ops
+}
+
+func diffJSONObject(ops []JSONPatchOp, path string, a, b map[string]any) []JSONPatchOp {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path + "/" + escapeJSONPointer(k)
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			ops = append(ops, JSONPatchOp{Op: "remove", Path: p})
+		case !inA:
+			ops = append(ops, JSONPatchOp{Op: "add", Path: p, Value: bv})
+		default:
+			ops = diffJSONValue(ops, p, av, bv)
+		}
+	}
+	return ops
+}
+
+// diffJSONArray diffs arrays along their longest common subsequence of
+// element identities
+func diffJSONArray(ops []JSONPatchOp, path string, a, b []any) []JSONPatchOp {
+	n, m := len(a), len(b)
+	if (n+1)*(m+1) > maxArrayDiffCells {
+		if !reflect.DeepEqual(a, b) {
+			ops = append(ops, JSONPatchOp{Op: "replace", Path: path, Value: b})
+		}
+		return ops
+	}
+
+	ka := make([]string, n)
+	for i, v := range a {
+		ka[i] = jsonElementKey(v)
+	}
+	kb := make([]string, m)
+	for j, v := range b {
+		kb[j] = jsonElementKey(v)
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if ka[i] == kb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j, pos := 0, 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && ka[i] == kb[j]:
+			ops = diffJSONValue(ops, path+"/"+strconv.Itoa(pos), a[i], b[j])
+			i, j, pos = i+1, j+1, pos+1
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			ops = append(ops, JSONPatchOp{Op: "add", Path: path + "/" + strconv.Itoa(pos), Value: b[j]})
+			j, pos = j+1, pos+1
+		default:
+			ops = append(ops, JSONPatchOp{Op: "remove", Path: path + "/" + strconv.Itoa(pos)})
+			i++
+		}
+	}
+	return ops
+}
+
+// jsonElementKey identifies an array element: objects by their "id" field,
+// anything else by its content
+func jsonElementKey(v any) string {
+	if obj, ok := v.(map[string]any); ok {
+		if id, ok := obj["id"]; ok {
+			return fmt.Sprintf("id:%v", id)
+		}
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func unescapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+}
+
+func applyJSONPatchOp(doc any, op JSONPatchOp) (any, error) {
+	if op.Path == "" {
+		if op.Op == "remove" {
+			return nil, nil
+		}
+		return op.Value, nil
+	}
+	if !strings.HasPrefix(op.Path, "/") {
+		return nil, fmt.Errorf("%w: path %q", ErrInvalidPatch, op.Path)
+	}
+	tokens := strings.Split(op.Path[1:], "/")
+	return patchJSONValue(doc, tokens, op)
+}
+
+// patchJSONValue applies op below doc and returns the updated value
+func patchJSONValue(doc any, tokens []string, op JSONPatchOp) (any, error) {
+	token := unescapeJSONPointer(tokens[0])
+	last := len(tokens) == 1
+
+	switch v := doc.(type) {
+	case map[string]any:
+		if !last {
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: missing %q in %s", ErrInvalidPatch, token, op.Path)
+			}
+			updated, err := patchJSONValue(child, tokens[1:], op)
+			v[token] = updated
+			return v, err
+		}
+		switch op.Op {
+		case "add", "replace":
+			v[token] = op.Value
+		case "remove":
+			delete(v, token)
+		default:
+			return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidPatch, op.Op)
+		}
+		return v, nil
+
+	case []any:
+		idx, err := strconv.Atoi(token)
+		if token == "-" && last && op.Op == "add" {
+			idx, err = len(v), nil
+		}
+		if err != nil || idx < 0 || idx > len(v) || (idx == len(v) && !(last && op.Op == "add")) {
+			return nil, fmt.Errorf("%w: bad index %q in %s", ErrInvalidPatch, token, op.Path)
+		}
+		if !last {
+			updated, err := patchJSONValue(v[idx], tokens[1:], op)
+			v[idx] = updated
+			return v, err
+		}
+		switch op.Op {
+		case "add":
+			v = append(v, nil)
+			copy(v[idx+1:], v[idx:])
+			v[idx] = op.Value
+		case "replace":
+			v[idx] = op.Value
+		case "remove":
+			v = append(v[:idx], v[idx+1:]...)
+		default:
+			return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidPatch, op.Op)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %s does not point into a container", ErrInvalidPatch, op.Path)
+}
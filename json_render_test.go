@@ -320,6 +320,50 @@ func TestPureJSONvsJSON(t *testing.T) {
 	}
 }
 
+// TestPooledJSONEncoder tests that pooled encoders don't leak state between renders
+func TestPooledJSONEncoder(t *testing.T) {
+	router := New()
+	router.GET("/pure", func(c *Context) {
+		c.PureJSON(http.StatusOK, H{"html": "<b>"})
+	})
+	router.GET("/json", func(c *Context) {
+		c.JSON(http.StatusOK, H{"html": "<b>"})
+	})
+	router.GET("/large", func(c *Context) {
+		c.JSON(http.StatusOK, strings.Repeat("x", maxPooledBufferSize))
+	})
+	router.GET("/invalid", func(c *Context) {
+		c.JSON(http.StatusOK, H{"ch": make(chan int)})
+	})
+
+	for i := 0; i < 3; i++ {
+		for _, tc := range []struct{ path, want string }{
+			{"/pure", "{\"html\":\"<b>\"}\n"},
+			{"/json", "{\"html\":\"\\u003cb\\u003e\"}\n"},
+			{"/invalid", ""},
+		} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			router.ServeHTTP(w, req)
+			if w.Body.String() != tc.want {
+				t.Errorf("GET %s: expected %q, got %q", tc.path, tc.want, w.Body.String())
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/large", nil)
+	router.ServeHTTP(w, req)
+	if w.Body.Len() != maxPooledBufferSize+3 {
+		t.Errorf("Expected full large body, got %d bytes", w.Body.Len())
+	}
+	e := getJSONEncoder(true)
+	if e.buf.Len() != 0 || e.buf.Cap() > maxPooledBufferSize {
+		t.Errorf("Expected pooled encoder with small empty buffer, got len=%d cap=%d", e.buf.Len(), e.buf.Cap())
+	}
+	putJSONEncoder(e)
+}
+
 // TestAbortWithStatusJSON tests abort with JSON
 func TestAbortWithStatusJSON(t *testing.T) {
 	router := New()
@@ -0,0 +1,28 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build sonic && !jsoniter
+
+package goTap
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+)
+
+// Building with -tags sonic renders and binds JSON with sonic. The sonic
+// version in go.mod must support the Go toolchain in use.
+func init() {
+	SetJSONCodec(sonicCodec{api: sonic.ConfigStd})
+}
+
+type sonicCodec struct {
+	api sonic.API
+}
+
+func (c sonicCodec) Marshal(v any) ([]byte, error)      { return c.api.Marshal(v) }
+func (c sonicCodec) Unmarshal(data []byte, v any) error { return c.api.Unmarshal(data, v) }
+func (c sonicCodec) NewEncoder(w io.Writer) JSONEncoder { return c.api.NewEncoder(w) }
+func (c sonicCodec) NewDecoder(r io.Reader) JSONDecoder { return c.api.NewDecoder(r) }
@@ -0,0 +1,420 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrKVNotFound is returned by KVStore.Get for missing or expired keys
+var ErrKVNotFound = errors.New("key not found")
+
+// KVStore is the key-value storage behind framework state such as rate
+// limits, idempotency keys, sessions, locks and caches. Set it once as
+// Engine.KVStore to share one backend between middleware.
+//
+// A ttl of 0 means the key doesn't expire.
+type KVStore interface {
+	// Get returns the value of key or ErrKVNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// SetNX stores value only if key doesn't exist, reporting whether it did
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// Delete removes key
+	Delete(ctx context.Context, key string) error
+
+	// CompareAndDelete removes key only if it holds value, e.g. to release
+	// a lock only while still owning it
+	CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error)
+
+	// Increment adds one to the counter at key, creating it with ttl, and
+	// returns the new count and when the counter expires
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, time.Time, error)
+
+	// Expire resets the ttl of key, reporting whether it exists
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// kvStore returns the engine's KVStore, or nil
+func (c *Context) kvStore() KVStore {
+	if c.engine == nil {
+		return nil
+	}
+	return c.engine.KVStore
+}
+
+// expiry returns the expiration time for ttl, zero if it doesn't expire
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// MemoryKVStore is an in-process KVStore for single instance deployments
+// and tests
+type MemoryKVStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryKVEntry
+	sweep   time.Time
+}
+
+type memoryKVEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e memoryKVEntry) live(now time.Time) bool {
+	return e.expires.IsZero() || now.Before(e.expires)
+}
+
+// NewMemoryKVStore creates an in-memory KVStore
+func NewMemoryKVStore() *MemoryKVStore {
+	return &MemoryKVStore{entries: make(map[string]memoryKVEntry)}
+}
+
+// lookup returns the live entry of key and evicts expired entries at most
+// once a minute. s.mu must be held.
+func (s *MemoryKVStore) lookup(key string, now time.Time) (memoryKVEntry, bool) {
+	if now.Sub(s.sweep) >= time.Minute {
+		s.sweep = now
+		for k, e := range s.entries {
+			if !e.live(now) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	e, ok := s.entries[key]
+	if !ok || !e.live(now) {
+		return memoryKVEntry{}, false
+	}
+	return e, true
+}
+
+// Get implements KVStore
+func (s *MemoryKVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key, time.Now())
+	if !ok {
+		return nil, ErrKVNotFound
+	}
+	return bytes.Clone(e.value), nil
+}
+
+// Set implements KVStore
+func (s *MemoryKVStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryKVEntry{value: bytes.Clone(value), expires: expiry(time.Now(), ttl)}
+	return nil
+}
+
+// SetNX implements KVStore
+func (s *MemoryKVStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lookup(key, now); ok {
+		return false, nil
+	}
+	s.entries[key] = memoryKVEntry{value: bytes.Clone(value), expires: expiry(now, ttl)}
+	return true, nil
+}
+
+// Delete implements KVStore
+func (s *MemoryKVStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// CompareAndDelete implements KVStore
+func (s *MemoryKVStore) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key, time.Now())
+	if !ok || !bytes.Equal(e.value, value) {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+// Increment implements KVStore
+func (s *MemoryKVStore) Increment(ctx context.Context, key string, ttl time.Duration) (int64, time.Time, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookup(key, now)
+	if !ok {
+		e = memoryKVEntry{expires: expiry(now, ttl)}
+	}
+	n, err := strconv.ParseInt(string(e.value), 10, 64)
+	if ok && err != nil {
+		return 0, time.Time{}, errors.New("value is not a counter")
+	}
+	n++
+	e.value = strconv.AppendInt(nil, n, 10)
+	s.entries[key] = e
+	return n, e.expires, nil
+}
+
+// Expire implements KVStore
+func (s *MemoryKVStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key, now)
+	if !ok {
+		return false, nil
+	}
+	e.expires = expiry(now, ttl)
+	s.entries[key] = e
+	return true, nil
+}
+
+// RedisKVStore is a KVStore in Redis
+type RedisKVStore struct {
+	client *RedisClient
+	prefix string
+}
+
+// NewRedisKVStore creates a Redis KVStore; prefix is prepended to all keys
+func NewRedisKVStore(client *RedisClient, prefix string) *RedisKVStore {
+	return &RedisKVStore{client: client, prefix: prefix}
+}
+
+var (
+	redisCompareAndDelete = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+	redisIncrement = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {n, redis.call("PTTL", KEYS[1])}`)
+)
+
+// Get implements KVStore
+func (s *RedisKVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKVNotFound
+	}
+	return value, err
+}
+
+// Set implements KVStore
+func (s *RedisKVStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// SetNX implements KVStore
+func (s *RedisKVStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.client.Client.SetNX(ctx, s.prefix+key, value, ttl).Result()
+}
+
+// Delete implements KVStore
+func (s *RedisKVStore) Delete(ctx context.Context, key string) error {
+	return s.client.Client.Del(ctx, s.prefix+key).Err()
+}
+
+// CompareAndDelete implements KVStore
+func (s *RedisKVStore) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	n, err := redisCompareAndDelete.Run(ctx, s.client.Client, []string{s.prefix + key}, value).Int()
+	return n == 1, err
+}
+
+// Increment implements KVStore
+func (s *RedisKVStore) Increment(ctx context.Context, key string, ttl time.Duration) (int64, time.Time, error) {
+	res, err := redisIncrement.Run(ctx, s.client.Client, []string{s.prefix + key}, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	var expires time.Time
+	if res[1] > 0 {
+		expires = time.Now().Add(time.Duration(res[1]) * time.Millisecond)
+	}
+	return res[0], expires, nil
+}
+
+// Expire implements KVStore
+func (s *RedisKVStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return s.client.Client.Persist(ctx, s.prefix+key).Result()
+	}
+	return s.client.Client.PExpire(ctx, s.prefix+key, ttl).Result()
+}
+
+// MongoKVStore is a KVStore in a MongoDB collection. Expired documents are
+// removed by a TTL index on expires_at.
+type MongoKVStore struct {
+	coll *mongo.Collection
+}
+
+// mongoKVDoc is a MongoKVStore document; counters keep their value in Count
+type mongoKVDoc struct {
+	Key       string     `bson:"_id"`
+	Value     []byte     `bson:"value,omitempty"`
+	Count     int64      `bson:"count,omitempty"`
+	ExpiresAt *time.Time `bson:"expires_at"`
+}
+
+// NewMongoKVStore creates a MongoDB KVStore in collection, creating its
+// TTL index
+func NewMongoKVStore(client *MongoClient, collection string) (*MongoKVStore, error) {
+	coll := client.Collection(collection)
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoKVStore{coll: coll}, nil
+}
+
+// live matches key if it hasn't expired; the TTL index removes documents
+// only about once a minute
+func (s *MongoKVStore) live(key string, now time.Time) bson.M {
+	return bson.M{"_id": key, "$or": bson.A{
+		bson.M{"expires_at": nil},
+		bson.M{"expires_at": bson.M{"$gt": now}},
+	}}
+}
+
+func mongoKVExpiry(now time.Time, ttl time.Duration) *time.Time {
+	if ttl <= 0 {
+		return nil
+	}
+	t := now.Add(ttl)
+	return &t
+}
+
+// Get implements KVStore
+func (s *MongoKVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var doc mongoKVDoc
+	err := s.coll.FindOne(ctx, s.live(key, time.Now())).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrKVNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc.Value == nil && doc.Count != 0 {
+		return strconv.AppendInt(nil, doc.Count, 10), nil
+	}
+	return doc.Value, nil
+}
+
+// Set implements KVStore
+func (s *MongoKVStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	doc := mongoKVDoc{Key: key, Value: value, ExpiresAt: mongoKVExpiry(time.Now(), ttl)}
+	_, err := s.coll.ReplaceOne(ctx, bson.M{"_id": key}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// SetNX implements KVStore
+func (s *MongoKVStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.insert(ctx, mongoKVDoc{Key: key, Value: value}, ttl)
+}
+
+// insert creates doc unless a live document holds its key
+func (s *MongoKVStore) insert(ctx context.Context, doc mongoKVDoc, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	doc.ExpiresAt = mongoKVExpiry(now, ttl)
+	_, err := s.coll.InsertOne(ctx, doc)
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+	// Replace a document that expired but wasn't removed yet
+	res, err := s.coll.ReplaceOne(ctx, bson.M{"_id": doc.Key, "expires_at": bson.M{"$lte": now}}, doc)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// Delete implements KVStore
+func (s *MongoKVStore) Delete(ctx context.Context, key string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+// CompareAndDelete implements KVStore
+func (s *MongoKVStore) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	filter := s.live(key, time.Now())
+	filter["value"] = value
+	res, err := s.coll.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount == 1, nil
+}
+
+// Increment implements KVStore
+func (s *MongoKVStore) Increment(ctx context.Context, key string, ttl time.Duration) (int64, time.Time, error) {
+	// A concurrent request may create the counter between the update and
+	// the insert, so try twice
+	for i := 0; i < 2; i++ {
+		var doc mongoKVDoc
+		err := s.coll.FindOneAndUpdate(ctx, s.live(key, time.Now()), bson.M{"$inc": bson.M{"count": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+		if err == nil {
+			var expires time.Time
+			if doc.ExpiresAt != nil {
+				expires = *doc.ExpiresAt
+			}
+			return doc.Count, expires, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, time.Time{}, err
+		}
+
+		doc = mongoKVDoc{Key: key, Count: 1}
+		inserted, err := s.insert(ctx, doc, ttl)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		if inserted {
+			return 1, expiry(time.Now(), ttl), nil
+		}
+	}
+	return 0, time.Time{}, errors.New("counter contention")
+}
+
+// Expire implements KVStore
+func (s *MongoKVStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	res, err := s.coll.UpdateOne(ctx, s.live(key, time.Now()),
+		bson.M{"$set": bson.M{"expires_at": mongoKVExpiry(time.Now(), ttl)}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
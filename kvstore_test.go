@@ -0,0 +1,240 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKVStore checks the KVStore contract
+func testKVStore(t *testing.T, kv KVStore, expire func(time.Duration)) {
+	ctx := context.Background()
+
+	if _, err := kv.Get(ctx, "missing"); !errors.Is(err, ErrKVNotFound) {
+		t.Errorf("Expected ErrKVNotFound, got %v", err)
+	}
+	kv.Set(ctx, "a", []byte("1"), 0)
+	if v, err := kv.Get(ctx, "a"); err != nil || string(v) != "1" {
+		t.Errorf("Get = %q, %v", v, err)
+	}
+
+	if ok, _ := kv.SetNX(ctx, "lock", []byte("owner-1"), time.Second); !ok {
+		t.Error("Expected SetNX on a new key to succeed")
+	}
+	if ok, _ := kv.SetNX(ctx, "lock", []byte("owner-2"), time.Second); ok {
+		t.Error("Expected SetNX on an existing key to fail")
+	}
+	if ok, _ := kv.CompareAndDelete(ctx, "lock", []byte("owner-2")); ok {
+		t.Error("Expected CompareAndDelete with another value to fail")
+	}
+	if ok, _ := kv.CompareAndDelete(ctx, "lock", []byte("owner-1")); !ok {
+		t.Error("Expected CompareAndDelete by the owner to succeed")
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		n, expires, err := kv.Increment(ctx, "counter", time.Second)
+		if err != nil || n != i || expires.IsZero() {
+			t.Fatalf("Increment = %d, %v, %v; want %d", n, expires, err, i)
+		}
+	}
+	if v, _ := kv.Get(ctx, "counter"); string(v) != "3" {
+		t.Errorf("Expected counter value 3, got %q", v)
+	}
+
+	kv.Set(ctx, "short", []byte("x"), time.Second)
+	kv.Set(ctx, "kept", []byte("x"), time.Second)
+	if ok, _ := kv.Expire(ctx, "kept", time.Hour); !ok {
+		t.Error("Expected Expire on an existing key to succeed")
+	}
+	expire(2 * time.Second)
+	for key, live := range map[string]bool{"short": false, "kept": true, "counter": false, "a": true} {
+		if _, err := kv.Get(ctx, key); (err == nil) != live {
+			t.Errorf("Key %s: expected live=%v, got %v", key, live, err)
+		}
+	}
+	if n, _, _ := kv.Increment(ctx, "counter", time.Second); n != 1 {
+		t.Errorf("Expected expired counter to restart, got %d", n)
+	}
+	if ok, _ := kv.SetNX(ctx, "short", []byte("y"), 0); !ok {
+		t.Error("Expected SetNX on an expired key to succeed")
+	}
+
+	kv.Delete(ctx, "a")
+	if _, err := kv.Get(ctx, "a"); !errors.Is(err, ErrKVNotFound) {
+		t.Errorf("Expected deleted key to be gone, got %v", err)
+	}
+}
+
+func TestMemoryKVStore(t *testing.T) {
+	testKVStore(t, NewMemoryKVStore(), func(d time.Duration) { time.Sleep(d) })
+}
+
+func TestRedisKVStore(t *testing.T) {
+	client, mr := setupMiniRedis(t)
+	defer mr.Close()
+	testKVStore(t, NewRedisKVStore(client, "test:"), mr.FastForward)
+	if !mr.Exists("test:kept") {
+		t.Error("Expected keys to be prefixed")
+	}
+}
+
+func TestMongoKVStore(t *testing.T) {
+	client := skipIfNoMongo(t)
+	defer client.Close()
+	client.Collection("test_kv").Drop(context.Background())
+	kv, err := NewMongoKVStore(client, "test_kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testKVStore(t, kv, func(d time.Duration) { time.Sleep(d) })
+}
+
+func TestEngineKVStoreSharedByMiddleware(t *testing.T) {
+	client, mr := setupMiniRedis(t)
+	defer mr.Close()
+	kv := NewRedisKVStore(client, "")
+
+	// Two instances behind a load balancer share limits and sessions
+	newInstance := func() *Engine {
+		r := New()
+		r.KVStore = kv
+		r.Use(RateLimiter(3, time.Minute))
+		r.Use(Sessions(SessionConfig{}))
+		r.POST("/cart", func(c *Context) {
+			MustGetSession(c).Set("cart", "receipt-1")
+			c.Status(200)
+		})
+		r.GET("/cart", func(c *Context) {
+			cart, _ := MustGetSession(c).Get("cart")
+			c.String(200, cart)
+		})
+		return r
+	}
+	a, b := newInstance(), newInstance()
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest("POST", "/cart", nil))
+	cookie := w.Result().Cookies()[0]
+
+	req := httptest.NewRequest("GET", "/cart", nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	b.ServeHTTP(w, req)
+	if w.Body.String() != "receipt-1" {
+		t.Errorf("Expected session from the other instance, got %q", w.Body.String())
+	}
+
+	a.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/cart", nil))
+	w = httptest.NewRecorder()
+	b.ServeHTTP(w, httptest.NewRequest("GET", "/cart", nil))
+	if w.Code != 429 {
+		t.Errorf("Expected the shared rate limit to be exceeded, got %d", w.Code)
+	}
+	found := false
+	for _, key := range mr.Keys() {
+		found = found || strings.HasPrefix(key, "ratelimit:") && strings.HasSuffix(key, ":192.0.2.1")
+	}
+	if !found {
+		t.Errorf("Expected rate limit counter in Redis, got keys %v", mr.Keys())
+	}
+}
+
+func TestStackedRateLimitersOnKVStore(t *testing.T) {
+	r := New()
+	r.KVStore = NewMemoryKVStore()
+	r.Use(RateLimiter(100, time.Minute))
+	r.GET("/products", func(c *Context) {})
+	r.POST("/login", RateLimiter(3, time.Minute), func(c *Context) {})
+
+	request := func(method, path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		request("GET", "/products")
+	}
+	for i := 1; i <= 3; i++ {
+		if code := request("POST", "/login"); code != http.StatusOK {
+			t.Fatalf("Expected login %d to pass the login limiter, got %d", i, code)
+		}
+	}
+	if code := request("POST", "/login"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the fourth login to be limited, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/products", nil))
+	if remaining := w.Header().Get("X-RateLimit-Remaining"); remaining != "92" {
+		t.Errorf("Expected the global limiter to count each request once, got %s remaining", remaining)
+	}
+}
+
+func TestSessionsRejectUnknownIDs(t *testing.T) {
+	r := New()
+	r.Use(Sessions(SessionConfig{Store: NewMemoryKVStore()}))
+	r.POST("/login", func(c *Context) {
+		session := MustGetSession(c)
+		if c.Query("regenerate") != "" {
+			if err := session.Regenerate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		session.Set("user", "alice")
+		c.String(200, session.ID)
+	})
+	r.GET("/me", func(c *Context) {
+		user, _ := MustGetSession(c).Get("user")
+		c.String(200, user)
+	})
+
+	request := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// An attacker-chosen ID is replaced by a server-generated one
+	planted := &http.Cookie{Name: "session_id", Value: "0123456789abcdef0123456789abcdef"}
+	w := request("POST", "/login", planted)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == planted.Value || w.Body.String() != cookies[0].Value {
+		t.Fatalf("Expected a new session ID, got %v", cookies)
+	}
+	if !cookies[0].HttpOnly {
+		t.Error("Expected the session cookie to be HttpOnly by default")
+	}
+	if w := request("GET", "/me", planted); w.Body.String() != "" {
+		t.Errorf("Expected the planted ID to have no session, got %q", w.Body.String())
+	}
+	issued := cookies[0]
+	if w := request("GET", "/me", issued); w.Body.String() != "alice" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected the issued ID to be accepted, got %q %v", w.Body.String(), w.Result().Cookies())
+	}
+
+	// Regenerate moves the data to a new ID
+	w = request("POST", "/login?regenerate=1", issued)
+	cookies = w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == issued.Value {
+		t.Fatalf("Expected Regenerate to send a new ID, got %v", cookies)
+	}
+	if w := request("GET", "/me", cookies[0]); w.Body.String() != "alice" {
+		t.Errorf("Expected the data under the new ID, got %q", w.Body.String())
+	}
+	if w := request("GET", "/me", issued); w.Body.String() != "" {
+		t.Errorf("Expected the old ID to be dropped, got %q", w.Body.String())
+	}
+}
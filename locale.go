@@ -0,0 +1,355 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"html/template"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocaleKey is the context key holding the request's locale, e.g. "de-DE"
+const LocaleKey = "gotap.locale"
+
+// LocaleFormat describes how a locale writes numbers, amounts and dates
+type LocaleFormat struct {
+	// Decimal separates the fraction, e.g. "." or ","
+	Decimal string
+
+	// Group separates groups of digits, e.g. "," or "."
+	Group string
+
+	// Grouping lists the sizes of digit groups from the right; the last
+	// size repeats, e.g. [3, 2] for 12,34,567
+	// Default: [3]
+	Grouping []int
+
+	// Currency places the currency symbol "¤" and the number "#", e.g.
+	// "¤#" for $1.00 or "# ¤" for 1,00 €
+	Currency string
+
+	// Symbols replaces currency symbols, e.g. {"CAD": "$"} in Canada
+	// Optional.
+	Symbols map[string]string
+
+	// DateLayout, TimeLayout and DateTimeLayout are time.Format layouts
+	DateLayout string
+	TimeLayout string
+
+	// Default: DateLayout and TimeLayout separated by a space
+	DateTimeLayout string
+}
+
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f"
+)
+
+var (
+	localeFormatsMu sync.RWMutex
+	localeFormats   = map[string]LocaleFormat{
+		"en-US": {Decimal: ".", Group: ",", Currency: "¤#", DateLayout: "01/02/2006", TimeLayout: "3:04 PM"},
+		"en-GB": {Decimal: ".", Group: ",", Currency: "¤#", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"en-AU": {Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"AUD": "$"}, DateLayout: "02/01/2006", TimeLayout: "3:04 PM"},
+		"en-CA": {Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"CAD": "$", "USD": "US$"}, DateLayout: "2006-01-02", TimeLayout: "3:04 PM"},
+		"en-IN": {Decimal: ".", Group: ",", Grouping: []int{3, 2}, Currency: "¤#", DateLayout: "02/01/2006", TimeLayout: "3:04 PM"},
+		"hi-IN": {Decimal: ".", Group: ",", Grouping: []int{3, 2}, Currency: "¤#", DateLayout: "02/01/2006", TimeLayout: "3:04 PM"},
+		"de-DE": {Decimal: ",", Group: ".", Currency: "#" + nbsp + "¤", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+		"de-CH": {Decimal: ".", Group: "’", Currency: "¤" + nbsp + "#", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+		"fr-FR": {Decimal: ",", Group: narrowNbsp, Currency: "#" + nbsp + "¤", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"fr-CA": {Decimal: ",", Group: nbsp, Currency: "#" + nbsp + "¤", Symbols: map[string]string{"CAD": "$", "USD": "US$"}, DateLayout: "2006-01-02", TimeLayout: "15 h 04"},
+		"es-ES": {Decimal: ",", Group: ".", Currency: "#" + nbsp + "¤", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"es-MX": {Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"MXN": "$", "USD": "USD"}, DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"it-IT": {Decimal: ",", Group: ".", Currency: "#" + nbsp + "¤", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"pt-BR": {Decimal: ",", Group: ".", Currency: "¤" + nbsp + "#", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"pt-PT": {Decimal: ",", Group: nbsp, Currency: "#" + nbsp + "¤", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+		"nl-NL": {Decimal: ",", Group: ".", Currency: "¤" + nbsp + "#", DateLayout: "02-01-2006", TimeLayout: "15:04"},
+		"sv-SE": {Decimal: ",", Group: nbsp, Currency: "#" + nbsp + "¤", DateLayout: "2006-01-02", TimeLayout: "15:04"},
+		"pl-PL": {Decimal: ",", Group: nbsp, Currency: "#" + nbsp + "¤", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+		"ru-RU": {Decimal: ",", Group: nbsp, Currency: "#" + nbsp + "¤", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+		"tr-TR": {Decimal: ",", Group: ".", Currency: "¤#", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+		"ja-JP": {Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"JPY": "￥"}, DateLayout: "2006/01/02", TimeLayout: "15:04"},
+		"zh-CN": {Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"CNY": "¥", "JPY": "JP¥"}, DateLayout: "2006/01/02", TimeLayout: "15:04"},
+		"ko-KR": {Decimal: ".", Group: ",", Currency: "¤#", DateLayout: "2006. 01. 02.", TimeLayout: "15:04"},
+	}
+	// localeDefaults map a language to the locale formatting it
+	localeDefaults = map[string]string{
+		"en": "en-US", "hi": "hi-IN", "de": "de-DE", "fr": "fr-FR", "es": "es-ES", "it": "it-IT",
+		"pt": "pt-BR", "nl": "nl-NL", "sv": "sv-SE", "pl": "pl-PL", "ru": "ru-RU", "tr": "tr-TR",
+		"ja": "ja-JP", "zh": "zh-CN", "ko": "ko-KR",
+	}
+)
+
+// RegisterLocaleFormat adds or replaces the formatting of a locale, e.g.
+// "en-NZ". The first locale registered for a language also formats the
+// language's other regions.
+func RegisterLocaleFormat(locale string, format LocaleFormat) {
+	locale = normalizeLocale(locale)
+	localeFormatsMu.Lock()
+	defer localeFormatsMu.Unlock()
+	localeFormats[locale] = format
+	language, _, _ := strings.Cut(locale, "-")
+	if _, ok := localeDefaults[language]; !ok {
+		localeDefaults[language] = locale
+	}
+}
+
+// lookupLocaleFormat returns the format of locale or of its language
+func lookupLocaleFormat(locale string) (LocaleFormat, bool) {
+	locale = normalizeLocale(locale)
+	localeFormatsMu.RLock()
+	defer localeFormatsMu.RUnlock()
+	if f, ok := localeFormats[locale]; ok {
+		return f, true
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	f, ok := localeFormats[localeDefaults[language]]
+	return f, ok
+}
+
+func localeFormat(locale string) LocaleFormat {
+	if f, ok := lookupLocaleFormat(locale); ok {
+		return f
+	}
+	f, _ := lookupLocaleFormat("en-US")
+	return f
+}
+
+// normalizeLocale canonicalizes a language tag, e.g. "pt_br" to "pt-BR"
+func normalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// negotiateLocale returns the most preferred locale of an Accept-Language
+// header that supported accepts, or fallback
+func negotiateLocale(header, fallback string, supported func(locale string) bool) string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			accepted = append(accepted, weighted{normalizeLocale(tag), q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	for _, a := range accepted {
+		if supported(a.locale) {
+			return a.locale
+		}
+	}
+	return fallback
+}
+
+// Locale returns the request's locale: the one set with SetLocale, or the
+// most preferred Accept-Language locale with known formatting or error
+// messages, or Engine.DefaultLocale
+func (c *Context) Locale() string {
+	if v, ok := c.Get(LocaleKey); ok {
+		if locale, ok := v.(string); ok && locale != "" {
+			return locale
+		}
+	}
+	fallback := "en-US"
+	if c.engine != nil && c.engine.DefaultLocale != "" {
+		fallback = c.engine.DefaultLocale
+	}
+	locale := negotiateLocale(c.GetHeader("Accept-Language"), fallback, localeSupported)
+	c.Set(LocaleKey, locale)
+	return locale
+}
+
+// localeSupported reports whether locale or its language has formatting
+// or translated error messages
+func localeSupported(locale string) bool {
+	if _, ok := lookupLocaleFormat(locale); ok {
+		return true
+	}
+	return hasErrorMessages(locale)
+}
+
+// SetLocale sets the request's locale, e.g. from the signed-in user's
+// profile or a store's region
+func (c *Context) SetLocale(locale string) {
+	c.Set(LocaleKey, normalizeLocale(locale))
+}
+
+// FormatMoney formats an amount for the request's locale, e.g. "$1,234.50"
+// for en-US or "1.234,50 €" for de-DE
+func (c *Context) FormatMoney(m Money) string {
+	return FormatMoney(c.Locale(), m)
+}
+
+// FormatNumber formats a number with decimals digits for the request's locale
+func (c *Context) FormatNumber(v float64, decimals int) string {
+	return FormatNumber(c.Locale(), v, decimals)
+}
+
+// FormatDate formats the date of t for the request's locale, e.g.
+// "05/24/2025" for en-US or "24.05.2025" for de-DE
+func (c *Context) FormatDate(t time.Time) string {
+	return FormatDate(c.Locale(), t)
+}
+
+// FormatTime formats the time of day of t for the request's locale
+func (c *Context) FormatTime(t time.Time) string {
+	return FormatTime(c.Locale(), t)
+}
+
+// FormatDateTime formats the date and time of t for the request's locale
+func (c *Context) FormatDateTime(t time.Time) string {
+	return FormatDateTime(c.Locale(), t)
+}
+
+// FormatMoney formats an amount for locale
+func FormatMoney(locale string, m Money) string {
+	f := localeFormat(locale)
+	currency := lookupCurrency(m.Currency)
+	symbol := currency.symbol
+	if s, ok := f.Symbols[strings.ToUpper(m.Currency)]; ok {
+		symbol = s
+	}
+
+	// Negating math.MinInt64 overflows back to itself, which converts to
+	// the right unsigned magnitude
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		abs = uint64(-m.Amount)
+	}
+	scale := uint64(math.Pow10(currency.digits))
+	var frac string
+	if currency.digits > 0 {
+		frac = strconv.FormatUint(abs%scale+scale, 10)[1:]
+	}
+	number := f.formatDigits(strconv.FormatUint(abs/scale, 10), frac)
+
+	pattern := f.Currency
+	if pattern == "" {
+		pattern = "¤#"
+	}
+	s := strings.Replace(strings.Replace(pattern, "#", number, 1), "¤", symbol, 1)
+	if m.Amount < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// FormatNumber formats a number with decimals digits for locale
+func FormatNumber(locale string, v float64, decimals int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', max(decimals, 0), 64)
+	integer, frac, _ := strings.Cut(s, ".")
+	s = localeFormat(locale).formatDigits(integer, frac)
+	if v < 0 && strings.Trim(integer+frac, "0") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// FormatDate formats the date of t for locale
+func FormatDate(locale string, t time.Time) string {
+	return t.Format(localeFormat(locale).DateLayout)
+}
+
+// FormatTime formats the time of day of t for locale
+func FormatTime(locale string, t time.Time) string {
+	return t.Format(localeFormat(locale).TimeLayout)
+}
+
+// FormatDateTime formats the date and time of t for locale
+func FormatDateTime(locale string, t time.Time) string {
+	f := localeFormat(locale)
+	layout := f.DateTimeLayout
+	if layout == "" {
+		layout = f.DateLayout + " " + f.TimeLayout
+	}
+	return t.Format(layout)
+}
+
+// formatDigits groups the integer digits and appends the fraction
+func (f LocaleFormat) formatDigits(integer, frac string) string {
+	if f.Group != "" {
+		sizes := f.Grouping
+		if len(sizes) == 0 {
+			sizes = []int{3}
+		}
+		var groups []string
+		for i := 0; integer != ""; i++ {
+			size := sizes[min(i, len(sizes)-1)]
+			if size <= 0 || len(integer) <= size {
+				groups = append(groups, integer)
+				break
+			}
+			groups = append(groups, integer[len(integer)-size:])
+			integer = integer[:len(integer)-size]
+		}
+		for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+			groups[i], groups[j] = groups[j], groups[i]
+		}
+		integer = strings.Join(groups, f.Group)
+	}
+	if frac == "" {
+		return integer
+	}
+	decimal := f.Decimal
+	if decimal == "" {
+		decimal = "."
+	}
+	return integer + decimal + frac
+}
+
+// LocaleFuncMap returns template functions formatting for a locale passed
+// as the first argument. With LocaleHTMLData the request's locale is
+// available to every template as .locale:
+//
+//	r.SetFuncMap(goTap.LocaleFuncMap())
+//	r.AddHTMLData(goTap.LocaleHTMLData)
+//	r.LoadHTMLGlob("templates/*")
+//
+//	<td>{{formatDate .locale .order.CreatedAt}}</td>
+//	<td>{{formatMoney .locale .order.Total}}</td>
+func LocaleFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"formatMoney":    FormatMoney,
+		"formatNumber":   FormatNumber,
+		"formatDate":     FormatDate,
+		"formatTime":     FormatTime,
+		"formatDateTime": FormatDateTime,
+	}
+}
+
+// LocaleHTMLData adds the request's locale to template data as "locale",
+// see Engine.AddHTMLData
+func LocaleHTMLData(c *Context) H {
+	return H{"locale": c.Locale()}
+}
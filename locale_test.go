@@ -0,0 +1,123 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"html/template"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		locale string
+		money  Money
+		want   string
+	}{
+		{"en-US", NewMoney(123450, "usd"), "$1,234.50"},
+		{"en-US", NewMoney(-5, "USD"), "-$0.05"},
+		{"de-DE", NewMoney(123450, "EUR"), "1.234,50\u00a0€"},
+		{"de-AT", NewMoney(99, "EUR"), "0,99\u00a0€"},
+		{"fr-FR", NewMoney(123456789, "EUR"), "1\u202f234\u202f567,89\u00a0€"},
+		{"en-IN", NewMoney(1234567800, "INR"), "₹1,23,45,678.00"},
+		{"ja-JP", NewMoney(1500, "JPY"), "￥1,500"},
+		{"en-US", NewMoney(1234, "KWD"), "KWD1.234"},
+		{"en-US", NewMoney(100, "XYZ"), "XYZ1.00"},
+		{"xx", NewMoney(100, "GBP"), "£1.00"},
+		{"en-US", NewMoney(math.MinInt64, "USD"), "-$92,233,720,368,547,758.08"},
+	}
+	for _, tt := range tests {
+		if got := FormatMoney(tt.locale, tt.money); got != tt.want {
+			t.Errorf("FormatMoney(%q, %v) = %q, want %q", tt.locale, tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumberAndDate(t *testing.T) {
+	if got := FormatNumber("de-DE", -1234567.891, 2); got != "-1.234.567,89" {
+		t.Errorf("Unexpected number %q", got)
+	}
+	if got := FormatNumber("en-US", -0.001, 2); got != "0.00" {
+		t.Errorf("Expected no negative zero, got %q", got)
+	}
+	if got := FormatNumber("en-US", 42, 0); got != "42" {
+		t.Errorf("Unexpected number %q", got)
+	}
+
+	ts := time.Date(2025, 5, 24, 14, 30, 0, 0, time.UTC)
+	for locale, want := range map[string]string{
+		"en-US": "05/24/2025 2:30 PM",
+		"en-GB": "24/05/2025 14:30",
+		"de-DE": "24.05.2025 14:30",
+		"ja-JP": "2025/05/24 14:30",
+	} {
+		if got := FormatDateTime(locale, ts); got != want {
+			t.Errorf("FormatDateTime(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	r := New()
+	r.GET("/total", func(c *Context) {
+		c.String(200, "%s %s", c.Locale(), c.FormatMoney(NewMoney(199900, "EUR")))
+	})
+	r.GET("/profile", func(c *Context) {
+		c.SetLocale("pt_br")
+		c.String(200, "%s %s", c.Locale(), c.FormatDate(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	for header, want := range map[string]string{
+		"":                              "en-US €1,999.00",
+		"de-CH, de;q=0.9":               "de-CH €\u00a01’999.00",
+		"xx-YY, fr;q=0.8, en;q=0.9":     "en €1,999.00",
+		"en;q=0.1, es-ES":               "es-ES 1.999,00\u00a0€",
+		"tlh, x-klingon;q=0.5, *;q=0.1": "en-US €1,999.00",
+	} {
+		req := httptest.NewRequest("GET", "/total", nil)
+		req.Header.Set("Accept-Language", header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != want {
+			t.Errorf("Accept-Language %q: got %q, want %q", header, w.Body.String(), want)
+		}
+	}
+
+	r.DefaultLocale = "de-DE"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/total", nil))
+	if w.Body.String() != "de-DE 1.999,00\u00a0€" {
+		t.Errorf("Expected the default locale, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/profile", nil))
+	if w.Body.String() != "pt-BR 02/01/2025" {
+		t.Errorf("Expected the set locale, got %q", w.Body.String())
+	}
+}
+
+func TestLocaleTemplateFuncs(t *testing.T) {
+	RegisterLocaleFormat("en-NZ", LocaleFormat{Decimal: ".", Group: ",", Currency: "¤#", Symbols: map[string]string{"NZD": "$"}, DateLayout: "2/01/2006", TimeLayout: "3:04 pm"})
+
+	r := New()
+	r.AddHTMLData(LocaleHTMLData)
+	r.SetHTMLTemplate(template.Must(template.New("receipt").Funcs(LocaleFuncMap()).Parse(
+		`{{formatDate .locale .at}} {{formatMoney .locale .total}} {{formatNumber .locale .qty 1}}`)))
+	r.GET("/receipt", func(c *Context) {
+		c.HTML(200, "receipt", H{"at": time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), "total": NewMoney(2500050, "NZD"), "qty": 1234.5})
+	})
+
+	req := httptest.NewRequest("GET", "/receipt", nil)
+	req.Header.Set("Accept-Language", "en-NZ")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := strings.TrimSpace(w.Body.String()); got != "9/03/2025 $25,000.50 1,234.5" {
+		t.Errorf("Unexpected receipt %q", got)
+	}
+}
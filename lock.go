@@ -0,0 +1,294 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLockNotAcquired is returned when a lock is held elsewhere for
+	// longer than LockConfig.WaitTimeout
+	ErrLockNotAcquired = errors.New("lock not acquired")
+
+	// ErrLockLost is returned when a held lock expired or was taken over
+	// before it was released
+	ErrLockLost = errors.New("lock lost")
+)
+
+// LockConfig holds configuration for NewLocker
+type LockConfig struct {
+	// Stores hold the locks. With several independent stores (e.g. 3 or 5
+	// Redis primaries) a lock needs a majority of them, like Redlock.
+	// Default: Engine.KVStore in WithLock
+	Stores []KVStore
+
+	// Prefix is prepended to lock keys
+	// Default: "lock:"
+	Prefix string
+
+	// WaitTimeout bounds how long Acquire retries a held lock; 0 means
+	// the default, a negative value tries once
+	// Default: 5s
+	WaitTimeout time.Duration
+
+	// RetryDelay is the base delay between attempts, with up to as much
+	// random jitter added
+	// Default: 50ms
+	RetryDelay time.Duration
+
+	// RenewInterval is how often a held lock's ttl is renewed while its
+	// function runs
+	// Default: a third of the ttl
+	RenewInterval time.Duration
+
+	// DriftFactor is the share of the ttl reserved for clock drift between
+	// the stores
+	// Default: 0.01
+	DriftFactor float64
+}
+
+// Locker acquires distributed locks in KVStores
+type Locker struct {
+	config LockConfig
+	quorum int
+}
+
+// NewLocker creates a Locker, e.g. over three Redis instances:
+//
+//	locker := goTap.NewLocker(goTap.LockConfig{Stores: []goTap.KVStore{
+//	    goTap.NewRedisKVStore(redis1, ""),
+//	    goTap.NewRedisKVStore(redis2, ""),
+//	    goTap.NewRedisKVStore(redis3, ""),
+//	}})
+func NewLocker(config LockConfig) *Locker {
+	if config.Prefix == "" {
+		config.Prefix = "lock:"
+	}
+	if config.WaitTimeout == 0 {
+		config.WaitTimeout = 5 * time.Second
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 50 * time.Millisecond
+	}
+	if config.DriftFactor <= 0 {
+		config.DriftFactor = 0.01
+	}
+	return &Locker{config: config, quorum: len(config.Stores)/2 + 1}
+}
+
+// Lock is a held distributed lock
+type Lock struct {
+	// Key is the locked resource
+	Key string
+
+	// Fence is the fencing token, which grows with every acquisition of
+	// Key. Pass it to writes guarded by the lock so storage can reject a
+	// holder whose lock expired, e.g.
+	// "UPDATE stock SET qty = ?, fence = ? WHERE sku = ? AND fence < ?".
+	Fence int64
+
+	locker *Locker
+	token  []byte
+	ttl    time.Duration
+	held   []KVStore
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Context is cancelled with ErrLockLost when the lock can't be renewed
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Acquire takes the lock on key for ttl, retrying until WaitTimeout. The
+// lock is renewed until Release.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if len(l.config.Stores) == 0 {
+		return nil, errors.New("goTap: no lock stores configured")
+	}
+	// The random token tells this holder's lock from later ones
+	token := []byte(generateSessionID())
+	storeKey := l.config.Prefix + key
+	deadline := time.Now().Add(l.config.WaitTimeout)
+
+	for {
+		held, fence, ok := l.tryAcquire(ctx, storeKey, token, ttl)
+		if ok {
+			lock := &Lock{
+				Key:    key,
+				Fence:  fence,
+				locker: l,
+				token:  token,
+				ttl:    ttl,
+				held:   held,
+				stop:   make(chan struct{}),
+				done:   make(chan struct{}),
+			}
+			lock.ctx, lock.cancel = context.WithCancelCause(ctx)
+			go lock.renew()
+			return lock, nil
+		}
+
+		delay := l.config.RetryDelay + rand.N(l.config.RetryDelay)
+		if time.Now().Add(delay).After(deadline) {
+			return nil, ErrLockNotAcquired
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// tryAcquire sets the lock in the stores, keeping it only if a quorum was
+// reached within the ttl
+func (l *Locker) tryAcquire(ctx context.Context, key string, token []byte, ttl time.Duration) ([]KVStore, int64, bool) {
+	start := time.Now()
+	held := make([]KVStore, 0, len(l.config.Stores))
+	for _, store := range l.config.Stores {
+		if ok, err := store.SetNX(ctx, key, token, ttl); ok && err == nil {
+			held = append(held, store)
+		}
+	}
+	drift := time.Duration(float64(ttl)*l.config.DriftFactor) + 2*time.Millisecond
+	if len(held) < l.quorum || time.Since(start)+drift >= ttl {
+		l.release(held, key, token)
+		return nil, 0, false
+	}
+
+	// The highest counter of the quorum is the fencing token
+	var fence int64
+	fenced := 0
+	for _, store := range held {
+		n, _, err := store.Increment(ctx, key+":fence", 0)
+		if err != nil {
+			continue
+		}
+		fenced++
+		fence = max(fence, n)
+	}
+	if fenced < l.quorum {
+		l.release(held, key, token)
+		return nil, 0, false
+	}
+	return held, fence, true
+}
+
+// release deletes the lock from the stores still holding token
+func (l *Locker) release(stores []KVStore, key string, token []byte) {
+	// The caller's context may be cancelled already
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, store := range stores {
+		if _, err := store.CompareAndDelete(ctx, key, token); err != nil {
+			debugPrint("[WARNING] Failed to release lock %s: %v", key, err)
+		}
+	}
+}
+
+// renew extends the lock's ttl until it is released, cancelling its
+// context once a quorum of the stores no longer holds it
+func (l *Lock) renew() {
+	defer close(l.done)
+	interval := l.locker.config.RenewInterval
+	if interval <= 0 {
+		interval = l.ttl / 3
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	key := l.locker.config.Prefix + l.Key
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		renewed := 0
+		for _, store := range l.held {
+			if l.extend(store, key) {
+				renewed++
+			}
+		}
+		if renewed < l.locker.quorum {
+			l.cancel(ErrLockLost)
+			return
+		}
+	}
+}
+
+// extend resets the ttl of key in store while it still holds the token
+func (l *Lock) extend(store KVStore, key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl)
+	defer cancel()
+	value, err := store.Get(ctx, key)
+	if err != nil || !bytes.Equal(value, l.token) {
+		return false
+	}
+	ok, err := store.Expire(ctx, key, l.ttl)
+	return ok && err == nil
+}
+
+// Release stops renewing the lock and deletes it. It returns ErrLockLost if
+// the lock was lost while held.
+func (l *Lock) Release() error {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.locker.release(l.held, l.locker.config.Prefix+l.Key, l.token)
+		l.cancel(nil)
+	})
+	if errors.Is(context.Cause(l.ctx), ErrLockLost) {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Do runs fn while holding the lock on key. An error from fn is returned
+// as is; otherwise ErrLockLost reports that the lock expired while fn ran.
+func (l *Locker) Do(ctx context.Context, key string, ttl time.Duration, fn func(lock *Lock) error) error {
+	lock, err := l.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	err = fn(lock)
+	if releaseErr := lock.Release(); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// WithLock runs fn while holding the distributed lock on key in
+// Engine.KVStore, so instances sharing a Redis KVStore serialize work on
+// the same resource:
+//
+//	err := goTap.WithLock(c, "inventory:"+sku, 10*time.Second, func(lock *goTap.Lock) error {
+//	    return decrementStock(lock.Context(), sku, qty, lock.Fence)
+//	})
+//	if errors.Is(err, goTap.ErrLockNotAcquired) {
+//	    c.JSON(409, goTap.H{"error": "Conflict", "message": "inventory is busy"})
+//	    return
+//	}
+//
+// The lock is renewed while fn runs and lock.Context() is cancelled if it
+// is lost anyway. Use NewLocker for other stores or several Redis
+// instances.
+func WithLock(c *Context, key string, ttl time.Duration, fn func(lock *Lock) error) error {
+	store := c.kvStore()
+	if store == nil {
+		return errors.New("goTap: WithLock requires Engine.KVStore")
+	}
+	return NewLocker(LockConfig{Stores: []KVStore{store}}).Do(c.Request.Context(), key, ttl, fn)
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downKVStore is a KVStore whose backend is unreachable
+type downKVStore struct{ KVStore }
+
+var errStoreDown = errors.New("connection refused")
+
+func (downKVStore) SetNX(context.Context, string, []byte, time.Duration) (bool, error) {
+	return false, errStoreDown
+}
+
+func (downKVStore) CompareAndDelete(context.Context, string, []byte) (bool, error) {
+	return false, errStoreDown
+}
+
+func TestWithLockSerializesStockUpdates(t *testing.T) {
+	redisClient, mr := setupMiniRedis(t)
+	defer mr.Close()
+	defer redisClient.Close()
+
+	r := New()
+	r.KVStore = NewRedisKVStore(redisClient, "")
+	var mu sync.Mutex
+	stock, fences := 5, []int64{}
+	r.POST("/sell", func(c *Context) {
+		err := WithLock(c, "inventory:SKU123", time.Second, func(lock *Lock) error {
+			// Read-modify-write with a pause, racing without the lock
+			mu.Lock()
+			current := stock
+			fences = append(fences, lock.Fence)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			if current == 0 {
+				return errors.New("out of stock")
+			}
+			mu.Lock()
+			stock = current - 1
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			c.JSON(409, H{"error": "Conflict", "message": err.Error()})
+			return
+		}
+		c.Status(204)
+	})
+
+	var wg sync.WaitGroup
+	var sold sync.Map
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/sell", nil))
+			sold.Store(i, w.Code)
+		}(i)
+	}
+	wg.Wait()
+
+	if stock != 0 {
+		t.Errorf("Expected the stock to reach 0, got %d", stock)
+	}
+	count := 0
+	sold.Range(func(_, code any) bool {
+		if code == 204 {
+			count++
+		}
+		return true
+	})
+	if count != 5 {
+		t.Errorf("Expected 5 sales, got %d", count)
+	}
+	for i := 1; i < len(fences); i++ {
+		if fences[i] <= fences[i-1] {
+			t.Fatalf("Expected increasing fencing tokens, got %v", fences)
+		}
+	}
+	if mr.Exists("lock:inventory:SKU123") {
+		t.Error("Expected the lock to be released")
+	}
+}
+
+func TestLockRenewal(t *testing.T) {
+	store := NewMemoryKVStore()
+	locker := NewLocker(LockConfig{Stores: []KVStore{store}, WaitTimeout: -1})
+	ctx := context.Background()
+
+	err := locker.Do(ctx, "receipt", 60*time.Millisecond, func(lock *Lock) error {
+		// The lock outlives its ttl while held
+		time.Sleep(200 * time.Millisecond)
+		if _, err := locker.Acquire(ctx, "receipt", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+			t.Errorf("Expected the renewed lock to be held, got %v", err)
+		}
+		return lock.Context().Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := locker.Acquire(ctx, "receipt", time.Second)
+	if err != nil {
+		t.Fatalf("Expected the released lock to be free, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestLockLost(t *testing.T) {
+	store := NewMemoryKVStore()
+	locker := NewLocker(LockConfig{Stores: []KVStore{store}, RenewInterval: 10 * time.Millisecond})
+
+	err := locker.Do(context.Background(), "drawer", time.Second, func(lock *Lock) error {
+		// Another process takes over, e.g. after a long GC pause
+		store.Set(context.Background(), "lock:drawer", []byte("other"), time.Second)
+		select {
+		case <-lock.Context().Done():
+		case <-time.After(time.Second):
+			t.Error("Expected the lock context to be cancelled")
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost, got %v", err)
+	}
+	if value, _ := store.Get(context.Background(), "lock:drawer"); string(value) != "other" {
+		t.Errorf("Expected the other holder's lock to be kept, got %q", value)
+	}
+}
+
+func TestLockQuorum(t *testing.T) {
+	ctx := context.Background()
+	a, b := NewMemoryKVStore(), NewMemoryKVStore()
+
+	// 2 of 3 stores are a majority
+	locker := NewLocker(LockConfig{Stores: []KVStore{a, b, downKVStore{}}, WaitTimeout: -1})
+	lock, err := locker.Acquire(ctx, "till", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Fence != 1 {
+		t.Errorf("Expected fencing token 1, got %d", lock.Fence)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1 of 3 isn't, and the partial lock is released
+	locker = NewLocker(LockConfig{Stores: []KVStore{a, downKVStore{}, downKVStore{}}, WaitTimeout: -1})
+	if _, err := locker.Acquire(ctx, "till", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("Expected ErrLockNotAcquired, got %v", err)
+	}
+	if _, err := a.Get(ctx, "lock:till"); !errors.Is(err, ErrKVNotFound) {
+		t.Errorf("Expected the partial lock to be released, got %v", err)
+	}
+}
+
+func TestLockWaits(t *testing.T) {
+	store := NewMemoryKVStore()
+	locker := NewLocker(LockConfig{Stores: []KVStore{store}, WaitTimeout: time.Second, RetryDelay: 5 * time.Millisecond})
+	ctx := context.Background()
+
+	first, err := locker.Acquire(ctx, "sku", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		first.Release()
+	}()
+	second, err := locker.Acquire(ctx, "sku", time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock after it was released, got %v", err)
+	}
+	defer second.Release()
+	if second.Fence <= first.Fence {
+		t.Errorf("Expected a higher fencing token, got %d after %d", second.Fence, first.Fence)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := locker.Acquire(cancelled, "sku", time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled context error, got %v", err)
+	}
+}
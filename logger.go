@@ -5,10 +5,13 @@
 package goTap
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // LoggerConfig defines the config for Logger middleware.
@@ -57,10 +60,10 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 
 		// Log only when path is not being skipped
 		if _, ok := skip[path]; !ok {
-			param := LogFormatterParams{
-				Request: c.Request,
-				Keys:    c.Keys,
-			}
+			entry := logEntryPool.Get().(*logEntry)
+			param := &entry.param
+			param.Request = c.Request
+			param.Keys = c.Keys
 
 			// Stop timer
 			param.TimeStamp = time.Now()
@@ -79,11 +82,85 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 
 			param.Path = path
 
-			fmt.Fprint(out, formatter(param))
+			entry.buf = formatter(entry.buf[:0], param)
+			out.Write(entry.buf)
+			entry.release()
 		}
 	}
 }
 
+// logEntry holds the per-request state of the Logger middleware. Entries
+// are pooled so logging a request allocates only what the formatter can't
+// avoid.
+type logEntry struct {
+	param LogFormatterParams
+	buf   []byte
+}
+
+var logEntryPool = sync.Pool{
+	New: func() any {
+		return &logEntry{buf: make([]byte, 0, 256)}
+	},
+}
+
+func (e *logEntry) release() {
+	e.param = LogFormatterParams{}
+	if cap(e.buf) > maxPooledBufferSize {
+		return
+	}
+	logEntryPool.Put(e)
+}
+
+// AccessLogWriter writes log lines to an underlying writer, such as an
+// access log file, from a bounded worker pool, so slow disks don't hold up
+// requests. Lines queued together are written with a single call.
+//
+//	file, _ := os.OpenFile("access.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+//	accessLog := goTap.NewAccessLogWriter(file, goTap.BatchWriterConfig{})
+//	defer accessLog.Close(context.Background())
+//	r.Use(goTap.LoggerWithConfig(goTap.LoggerConfig{Output: accessLog}))
+type AccessLogWriter struct {
+	writer *BatchWriter[[]byte]
+}
+
+// NewAccessLogWriter creates an AccessLogWriter writing to out
+func NewAccessLogWriter(out io.Writer, config BatchWriterConfig) *AccessLogWriter {
+	if config.Name == "" {
+		config.Name = "access log"
+	}
+	return &AccessLogWriter{
+		writer: NewBatchWriter(func(ctx context.Context, lines [][]byte) error {
+			n := 0
+			for _, line := range lines {
+				n += len(line)
+			}
+			joined := make([]byte, 0, n)
+			for _, line := range lines {
+				joined = append(joined, line...)
+			}
+			_, err := out.Write(joined)
+			return err
+		}, config),
+	}
+}
+
+// Write queues a copy of p. It never blocks unless the writer was created
+// with OverflowBlock.
+func (w *AccessLogWriter) Write(p []byte) (int, error) {
+	w.writer.Add(append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// Close writes queued lines and stops the workers
+func (w *AccessLogWriter) Close(ctx context.Context) error {
+	return w.writer.Close(ctx)
+}
+
+// Stats returns the number of written, failed, dropped and queued lines
+func (w *AccessLogWriter) Stats() BatchWriterStats {
+	return w.writer.Stats()
+}
+
 // LogFormatterParams is the structure any formatter will be handed when time to log comes
 type LogFormatterParams struct {
 	Request *http.Request
@@ -154,7 +231,12 @@ func (p *LogFormatterParams) ResetColor() string {
 }
 
 // defaultLogFormatter is the default log format function Logger middleware uses.
-var defaultLogFormatter = func(param LogFormatterParams) string {
+// It appends the log line for param to buf, producing the same output as
+//
+//	fmt.Sprintf("[goTap] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s", ...)
+//
+// without the allocations of fmt.
+var defaultLogFormatter = func(buf []byte, param *LogFormatterParams) []byte {
 	var statusColor, methodColor, resetColor string
 	if IsDebugging() {
 		statusColor = param.StatusCodeColor()
@@ -162,16 +244,47 @@ var defaultLogFormatter = func(param LogFormatterParams) string {
 		resetColor = param.ResetColor()
 	}
 
-	if param.Latency > time.Minute {
-		param.Latency = param.Latency.Truncate(time.Second)
+	latency := param.Latency
+	if latency > time.Minute {
+		latency = latency.Truncate(time.Second)
+	}
+
+	var num [20]byte
+	buf = append(buf, "[goTap] "...)
+	buf = param.TimeStamp.AppendFormat(buf, "2006/01/02 - 15:04:05")
+	buf = append(buf, " |"...)
+	buf = append(buf, statusColor...)
+	buf = append(buf, ' ')
+	buf = appendPadded(buf, strconv.AppendInt(num[:0], int64(param.StatusCode), 10), 3)
+	buf = append(buf, ' ')
+	buf = append(buf, resetColor...)
+	buf = append(buf, "| "...)
+	buf = appendPadded(buf, []byte(latency.String()), 13)
+	buf = append(buf, " | "...)
+	buf = appendPadded(buf, []byte(param.ClientIP), 15)
+	buf = append(buf, " |"...)
+	buf = append(buf, methodColor...)
+	buf = append(buf, ' ')
+	buf = append(buf, param.Method...)
+	buf = appendSpaces(buf, 7-utf8.RuneCountInString(param.Method))
+	buf = append(buf, ' ')
+	buf = append(buf, resetColor...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendQuote(buf, param.Path)
+	buf = append(buf, '\n')
+	buf = append(buf, param.ErrorMessage...)
+	return buf
+}
+
+// appendPadded appends s right-aligned in a field of width runes
+func appendPadded(buf, s []byte, width int) []byte {
+	buf = appendSpaces(buf, width-utf8.RuneCount(s))
+	return append(buf, s...)
+}
+
+func appendSpaces(buf []byte, n int) []byte {
+	for ; n > 0; n-- {
+		buf = append(buf, ' ')
 	}
-	return fmt.Sprintf("[goTap] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
-		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
-		statusColor, param.StatusCode, resetColor,
-		param.Latency,
-		param.ClientIP,
-		methodColor, param.Method, resetColor,
-		param.Path,
-		param.ErrorMessage,
-	)
+	return buf
 }
@@ -0,0 +1,68 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultLogFormatterMatchesSprintf(t *testing.T) {
+	ts := time.Date(2025, 3, 1, 14, 5, 9, 0, time.UTC)
+	for _, param := range []LogFormatterParams{
+		{TimeStamp: ts, StatusCode: 200, Latency: 1234567, ClientIP: "10.0.0.1", Method: "GET", Path: "/users/42?expand=roles"},
+		{TimeStamp: ts, StatusCode: 404, Latency: 2 * time.Minute, ClientIP: "2001:db8::1234:5678:9abc", Method: "OPTIONS", Path: "/é\"quoted\"\t"},
+		{TimeStamp: ts, StatusCode: 500, Method: "PROPFIND", Path: "/", ErrorMessage: "Error #01: boom\n"},
+	} {
+		var statusColor, methodColor, resetColor string
+		if IsDebugging() {
+			statusColor, methodColor, resetColor = param.StatusCodeColor(), param.MethodColor(), param.ResetColor()
+		}
+		latency := param.Latency
+		if latency > time.Minute {
+			latency = latency.Truncate(time.Second)
+		}
+		want := fmt.Sprintf("[goTap] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			param.Path,
+			param.ErrorMessage,
+		)
+		if got := string(defaultLogFormatter(nil, &param)); got != want {
+			t.Errorf("Formatter mismatch\n got: %q\nwant: %q", got, want)
+		}
+	}
+}
+
+func TestLoggerSkipPaths(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(LoggerWithConfig(LoggerConfig{Output: &buf, SkipPaths: []string{"/health"}}))
+	r.GET("/health", func(c *Context) { c.Status(200) })
+	r.GET("/users/:id", func(c *Context) { c.Status(201) })
+
+	for _, path := range []string{"/health", "/users/1?x=1", "/users/2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "201") || !strings.HasSuffix(lines[0], `"/users/1?x=1"`) {
+		t.Errorf("Unexpected line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], `"/users/2"`) {
+		t.Errorf("Expected pooled entry to be reset, got %q", lines[1])
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskCard masks all but the last four digits of a card number, e.g.
+// "**** **** **** 1234"
+func MaskCard(number string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	if len(digits) < 4 {
+		return strings.Repeat("*", len(digits))
+	}
+	return "**** **** **** " + digits[len(digits)-4:]
+}
+
+// MaskEmail masks the local part of an email address but its first
+// character, e.g. "a***@example.com"
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return MaskString(email, 0)
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	if first == utf8.RuneError {
+		return "***@" + domain
+	}
+	return string(first) + "***@" + domain
+}
+
+// MaskString replaces all but the last visible characters of s with "*"
+func MaskString(s string, visible int) string {
+	runes := []rune(s)
+	visible = max(0, min(visible, len(runes)))
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// MaskedCard is a card number rendered masked in JSON, see MaskCard
+type MaskedCard string
+
+func (m MaskedCard) unmasked() string { return string(m) }
+
+// MarshalJSON implements json.Marshaler
+func (m MaskedCard) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MaskCard(string(m)))
+}
+
+// MaskedEmail is an email address rendered masked in JSON, see MaskEmail
+type MaskedEmail string
+
+func (m MaskedEmail) unmasked() string { return string(m) }
+
+// MarshalJSON implements json.Marshaler
+func (m MaskedEmail) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MaskEmail(string(m)))
+}
+
+// MaskedString is a string rendered in JSON with all but its last four
+// characters masked, e.g. for phone numbers and account numbers
+type MaskedString string
+
+func (m MaskedString) unmasked() string { return string(m) }
+
+// MarshalJSON implements json.Marshaler
+func (m MaskedString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MaskString(string(m), 4))
+}
@@ -0,0 +1,25 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import "testing"
+
+func TestMasking(t *testing.T) {
+	tests := []struct{ got, want string }{
+		{MaskCard("4111-1111-1111-1234"), "**** **** **** 1234"},
+		{MaskCard("12"), "**"},
+		{MaskEmail("ann@example.com"), "a***@example.com"},
+		{MaskEmail("élodie@example.fr"), "é***@example.fr"},
+		{MaskEmail("@example.com"), "***@example.com"},
+		{MaskEmail("not-an-email"), "************"},
+		{MaskString("+1 555 0100", 4), "*******0100"},
+		{MaskString("ab", 4), "ab"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records an audited request
+type AuditEntry struct {
+	ID           uint      `json:"-" bson:"-" gorm:"primaryKey"`
+	Time         time.Time `json:"time" bson:"time" gorm:"index"`
+	Method       string    `json:"method" bson:"method" gorm:"size:16"`
+	Path         string    `json:"path" bson:"path" gorm:"size:2048"`
+	Route        string    `json:"route,omitempty" bson:"route,omitempty" gorm:"size:512;index"`
+	Query        string    `json:"query,omitempty" bson:"query,omitempty"`
+	Status       int       `json:"status" bson:"status"`
+	DurationMs   int64     `json:"duration_ms" bson:"duration_ms"`
+	IP           string    `json:"ip" bson:"ip" gorm:"size:64"`
+	UserAgent    string    `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	UserID       string    `json:"user_id,omitempty" bson:"user_id,omitempty" gorm:"size:128;index"`
+	RequestBody  string    `json:"request_body,omitempty" bson:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty" bson:"response_body,omitempty"`
+	Errors       string    `json:"errors,omitempty" bson:"errors,omitempty"`
+}
+
+// AuditStore persists audit entries
+type AuditStore interface {
+	WriteAudit(ctx context.Context, entries []AuditEntry) error
+}
+
+// AuditConfig holds configuration for the AuditLog middleware
+type AuditConfig struct {
+	// Store persists the entries, e.g. NewGormAuditStore,
+	// NewMongoAuditStore or NewFileAuditStore
+	// Required.
+	Store AuditStore
+
+	// RedactFields lists the values replaced in JSON and form bodies and in
+	// the query string. A name matches the key at any depth, e.g.
+	// "password"; a dotted path matches from the top, e.g.
+	// "payment.card.number" or "items[*].pan", and "*" matches any key.
+	// Names match case-insensitively.
+	// Default: "password", "card_number", "cvv", "pin", "token", "secret"
+	RedactFields []string
+
+	// Redacted replaces redacted values
+	// Default: "[REDACTED]"
+	Redacted string
+
+	// MaxBodyBytes is the largest body recorded. Larger bodies, and bodies
+	// that aren't JSON or forms and so can't be redacted, are recorded as
+	// a placeholder with their type and size. -1 records no bodies.
+	// Default: 64 KB
+	MaxBodyBytes int64
+
+	// SampleRate is the fraction of requests recorded, from 0 to 1
+	// Default: 1
+	SampleRate float64
+
+	// SkipPaths are not recorded, e.g. health checks
+	SkipPaths []string
+
+	// UserFunc returns the user making the request
+	// Default: the "user_id" context value set by JWTAuth
+	UserFunc func(*Context) string
+
+	// Writer configures the worker pool writing entries to the Store
+	Writer BatchWriterConfig
+}
+
+// AuditLog returns a middleware recording requests with their redacted
+// bodies to a store, as an audit trail of who changed what:
+//
+//	store, _ := goTap.NewGormAuditStore(db)
+//	r.Use(goTap.AuditLog(goTap.AuditConfig{
+//	    Store:        store,
+//	    RedactFields: []string{"password", "card_number", "payment.cvv"},
+//	}))
+//
+// Entries are written in the background in batches and flushed on server
+// shutdown.
+func AuditLog(config AuditConfig) HandlerFunc {
+	if config.Store == nil {
+		panic("goTap: AuditLog requires a Store")
+	}
+	if config.RedactFields == nil {
+		config.RedactFields = []string{"password", "card_number", "cvv", "pin", "token", "secret"}
+	}
+	if config.Redacted == "" {
+		config.Redacted = "[REDACTED]"
+	}
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = 64 << 10
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.UserFunc == nil {
+		config.UserFunc = func(c *Context) string {
+			if v, ok := c.Get("user_id"); ok {
+				return fmt.Sprint(v)
+			}
+			return ""
+		}
+	}
+	if config.Writer.Name == "" {
+		config.Writer.Name = "audit log"
+	}
+
+	rules := newAuditRedactor(config.RedactFields, config.Redacted)
+	writer := NewBatchWriter(config.Store.WriteAudit, config.Writer)
+	var registerOnce sync.Once
+
+	return func(c *Context) {
+		if c.engine != nil {
+			registerOnce.Do(func() { c.engine.addService(config.Writer.Name, writer.Close) })
+		}
+		if containsString(config.SkipPaths, c.Request.URL.Path) ||
+			(config.SampleRate < 1 && rand.Float64() >= config.SampleRate) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var reqBody []byte
+		var reqTruncated bool
+		if config.MaxBodyBytes > 0 && c.Request.Body != nil {
+			reqBody, reqTruncated = peekBody(c, config.MaxBodyBytes)
+		}
+		aw := &auditWriter{ResponseWriter: c.Writer, limit: config.MaxBodyBytes}
+		if config.MaxBodyBytes > 0 {
+			c.Writer = aw
+		}
+
+		c.Next()
+
+		c.Writer = aw.ResponseWriter
+		entry := AuditEntry{
+			Time:       start,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Route:      c.FullPath(),
+			Query:      rules.query(c.Request.URL.RawQuery),
+			Status:     c.Writer.Status(),
+			DurationMs: time.Since(start).Milliseconds(),
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			UserID:     config.UserFunc(c),
+		}
+		if config.MaxBodyBytes > 0 {
+			entry.RequestBody = rules.body(c.ContentType(), reqBody, reqTruncated)
+			entry.ResponseBody = rules.body(c.Writer.Header().Get("Content-Type"), aw.body, aw.truncated)
+		}
+		if len(c.Errors) > 0 {
+			entry.Errors = c.Errors.String()
+		}
+		writer.Add(entry)
+	}
+}
+
+// peekBody reads up to limit bytes of the request body and puts them back.
+// It reports whether the body is longer than limit.
+func peekBody(c *Context, limit int64) ([]byte, bool) {
+	if cached, ok := c.Get(BodyBytesKey); ok {
+		body := cached.([]byte)
+		return body, int64(len(body)) > limit
+	}
+	buf, _ := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(buf), c.Request.Body), c.Request.Body}
+	if int64(len(buf)) > limit {
+		return nil, true
+	}
+	return buf, false
+}
+
+// readCloser reads from a Reader and closes a Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// auditWriter captures the response body up to a limit
+type auditWriter struct {
+	ResponseWriter
+	limit     int64
+	body      []byte
+	size      int64
+	truncated bool
+}
+
+func (w *auditWriter) capture(data []byte) {
+	w.size += int64(len(data))
+	if w.truncated {
+		return
+	}
+	if w.size > w.limit {
+		w.truncated, w.body = true, nil
+		return
+	}
+	w.body = append(w.body, data...)
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// auditRedactor replaces sensitive values in bodies and query strings
+type auditRedactor struct {
+	names    map[string]struct{}
+	paths    [][]string
+	redacted string
+}
+
+func newAuditRedactor(fields []string, redacted string) *auditRedactor {
+	r := &auditRedactor{names: map[string]struct{}{}, redacted: redacted}
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimPrefix(field, "$."))
+		field = strings.NewReplacer("[*]", "", "[]", "").Replace(field)
+		if strings.Contains(field, ".") {
+			r.paths = append(r.paths, strings.Split(field, "."))
+		} else {
+			r.names[field] = struct{}{}
+		}
+	}
+	return r
+}
+
+// redacts reports whether the value at path is sensitive
+func (r *auditRedactor) redacts(path []string) bool {
+	if _, ok := r.names[strings.ToLower(path[len(path)-1])]; ok {
+		return true
+	}
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		match := true
+		for i := range p {
+			if p[i] != "*" && p[i] != strings.ToLower(path[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// body returns the recordable form of a body
+func (r *auditRedactor) body(contentType string, body []byte, truncated bool) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if truncated {
+		return fmt.Sprintf("[%s body over the size limit]", mediaTypeOr(mediaType))
+	}
+	if len(body) == 0 {
+		return ""
+	}
+	switch {
+	case mediaType == MIMEJSON || strings.HasSuffix(mediaType, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return fmt.Sprintf("[invalid JSON, %d bytes]", len(body))
+		}
+		out, _ := json.Marshal(r.redactJSON(v, nil))
+		return string(out)
+	case mediaType == MIMEPOSTForm:
+		return r.query(string(body))
+	default:
+		return fmt.Sprintf("[%s, %d bytes]", mediaTypeOr(mediaType), len(body))
+	}
+}
+
+func (r *auditRedactor) redactJSON(v any, path []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			p := append(path[:len(path):len(path)], k)
+			if r.redacts(p) {
+				v[k] = r.redacted
+			} else {
+				v[k] = r.redactJSON(child, p)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = r.redactJSON(child, path)
+		}
+	}
+	return v
+}
+
+// query redacts a URL-encoded query or form
+func (r *auditRedactor) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "[invalid query]"
+	}
+	for k, vs := range values {
+		if r.redacts([]string{k}) {
+			for i := range vs {
+				vs[i] = r.redacted
+			}
+		}
+	}
+	return values.Encode()
+}
+
+func mediaTypeOr(mediaType string) string {
+	if mediaType == "" {
+		return "unknown"
+	}
+	return mediaType
+}
+
+// FileAuditStore appends audit entries to a file as JSON lines
+type FileAuditStore struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditStore opens path for appending audit entries
+func NewFileAuditStore(path string) (*FileAuditStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditStore{file: f}, nil
+}
+
+// WriteAudit implements AuditStore
+func (s *FileAuditStore) WriteAudit(ctx context.Context, entries []AuditEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file
+func (s *FileAuditStore) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+type memoryAuditStore struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *memoryAuditStore) WriteAudit(ctx context.Context, entries []AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *memoryAuditStore) all() []AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AuditEntry(nil), s.entries...)
+}
+
+func TestAuditLogRedaction(t *testing.T) {
+	store := &memoryAuditStore{}
+	r := New()
+	r.Use(func(c *Context) { c.Set("user_id", "u-7"); c.Next() })
+	r.Use(AuditLog(AuditConfig{
+		Store:        store,
+		RedactFields: []string{"password", "Card_Number", "payment.cvv", "items[*].pan", "token"},
+		MaxBodyBytes: 256,
+		SkipPaths:    []string{"/health"},
+		Writer:       BatchWriterConfig{FlushInterval: 5 * time.Millisecond},
+	}))
+	var handlerBody string
+	r.POST("/payments/:id", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		handlerBody = string(body)
+		c.JSON(201, H{"id": c.Param("id"), "token": "tok_secret", "cvv": "kept at the top level"})
+	})
+	r.POST("/login", func(c *Context) { c.String(200, "welcome") })
+	r.GET("/health", func(c *Context) { c.String(200, "ok") })
+
+	payment := `{"amount":12.5,"card_number":"4111111111111111","payment":{"cvv":"123","cvv_hint":"x"},"items":[{"pan":"5500000000000004","sku":"A1"}]}`
+	req := httptest.NewRequest("POST", "/payments/9?token=abc&page=2", strings.NewReader(payment))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if handlerBody != payment {
+		t.Errorf("Expected the handler to read the full body, got %q", handlerBody)
+	}
+
+	req = httptest.NewRequest("POST", "/login", strings.NewReader("user=ann&password=hunter2&"+strings.Repeat("x", 300)+"=1"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest("POST", "/login", strings.NewReader("user=ann&password=hunter2"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	waitFor(t, func() bool { return len(store.all()) == 3 })
+	entries := store.all()
+
+	e := entries[0]
+	var logged map[string]any
+	json.Unmarshal([]byte(e.RequestBody), &logged)
+	if logged["card_number"] != "[REDACTED]" || logged["amount"] != 12.5 ||
+		logged["payment"].(map[string]any)["cvv"] != "[REDACTED]" || logged["payment"].(map[string]any)["cvv_hint"] != "x" ||
+		logged["items"].([]any)[0].(map[string]any)["pan"] != "[REDACTED]" {
+		t.Errorf("Unexpected redacted request body %s", e.RequestBody)
+	}
+	if strings.Contains(e.ResponseBody, "tok_secret") || !strings.Contains(e.ResponseBody, "kept at the top level") {
+		t.Errorf("Unexpected redacted response body %s", e.ResponseBody)
+	}
+	if e.Query != "page=2&token=%5BREDACTED%5D" || e.Route != "/payments/:id" || e.Status != 201 || e.UserID != "u-7" {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+
+	if entries[1].RequestBody != "[application/x-www-form-urlencoded body over the size limit]" || entries[1].ResponseBody != "[text/plain, 7 bytes]" {
+		t.Errorf("Expected placeholders, got %q %q", entries[1].RequestBody, entries[1].ResponseBody)
+	}
+	if entries[2].RequestBody != "password=%5BREDACTED%5D&user=ann" {
+		t.Errorf("Unexpected redacted form %q", entries[2].RequestBody)
+	}
+}
+
+func TestAuditStores(t *testing.T) {
+	entry := AuditEntry{Time: time.Now(), Method: "POST", Path: "/orders", Status: 201, UserID: "u-1"}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fileStore, err := NewFileAuditStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fileStore.WriteAudit(context.Background(), []AuditEntry{entry, entry}); err != nil {
+		t.Fatal(err)
+	}
+	fileStore.Close()
+	data, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"path":"/orders"`) {
+		t.Errorf("Unexpected audit file %s", data)
+	}
+
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping GORM audit store: sqlite not available (%v)", err)
+	}
+	gormStore, err := NewGormAuditStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gormStore.WriteAudit(context.Background(), []AuditEntry{entry, entry}); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Model(&AuditEntry{}).Where("user_id = ?", "u-1").Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 audit rows, got %d", count)
+	}
+}
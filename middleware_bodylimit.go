@@ -0,0 +1,84 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BodyLimit returns a middleware limiting the request body to limit, given
+// as a size like "512", "256KB", "10MB" or "1GB" (powers of 1024).
+// Requests declaring a larger Content-Length are rejected with 413; reads
+// past the limit fail, so binding returns an error.
+//
+// Used globally or on a group, it is skipped for routes declaring their own
+// limit with RouterGroup.BodyLimit, so upload routes can accept more.
+func BodyLimit(limit string) HandlerFunc {
+	return bodyLimitMiddleware(limit, false)
+}
+
+// BodyLimit returns a group whose routes declare their own body limit,
+// replacing a global BodyLimit middleware:
+//
+//	r.Use(goTap.BodyLimit("1MB"))
+//	r.BodyLimit("256KB").POST("/payments/capture", capturePayment)
+//	r.BodyLimit("50MB").POST("/reports/upload", uploadReport)
+func (group *RouterGroup) BodyLimit(limit string) *RouterGroup {
+	child := group.Group("", bodyLimitMiddleware(limit, true))
+	child.limits.bodyLimit = limit
+	return child
+}
+
+func bodyLimitMiddleware(limit string, declared bool) HandlerFunc {
+	n, err := ParseByteSize(limit)
+	if err != nil {
+		panic("goTap: " + err.Error())
+	}
+	return func(c *Context) {
+		if !declared && c.declaredLimits().bodyLimit != "" {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > n {
+			c.JSON(http.StatusRequestEntityTooLarge, H{
+				"error":   "Request Entity Too Large",
+				"message": "request body exceeds " + limit,
+			})
+			c.Abort()
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		}
+		c.Next()
+	}
+}
+
+// ParseByteSize parses sizes like "512", "512B", "256KB", "1.5MB" or "2GB",
+// where units are powers of 1024
+func ParseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.value
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
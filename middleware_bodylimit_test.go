@@ -0,0 +1,66 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for size, want := range map[string]int64{
+		"512": 512, "512B": 512, "256KB": 256 << 10, "10mb": 10 << 20, "1.5MB": 3 << 19, "2G": 2 << 30,
+	} {
+		if got, err := ParseByteSize(size); err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", size, got, err, want)
+		}
+	}
+	for _, size := range []string{"", "KB", "-1KB", "ten"} {
+		if _, err := ParseByteSize(size); err == nil {
+			t.Errorf("Expected error for %q", size)
+		}
+	}
+}
+
+func TestRouteBodyLimit(t *testing.T) {
+	r := New()
+	r.Use(BodyLimit("1KB"))
+	read := func(c *Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.String(200, "ok")
+	}
+	r.POST("/capture", read)
+	r.BodyLimit("8KB").POST("/upload", read)
+
+	for _, tc := range []struct {
+		path    string
+		size    int
+		chunked bool
+		want    int
+	}{
+		{"/capture", 512, false, 200},
+		{"/capture", 2 << 10, false, 413},
+		{"/capture", 2 << 10, true, 400},
+		{"/upload", 4 << 10, false, 200},
+		{"/upload", 4 << 10, true, 200},
+		{"/upload", 16 << 10, false, 413},
+		{"/upload", 16 << 10, true, 400},
+	} {
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(strings.Repeat("x", tc.size)))
+		if tc.chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("POST %s with %d bytes (chunked=%v): expected %d, got %d", tc.path, tc.size, tc.chunked, tc.want, w.Code)
+		}
+	}
+}
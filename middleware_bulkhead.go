@@ -0,0 +1,144 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// BulkheadConfig holds configuration for a Bulkhead
+type BulkheadConfig struct {
+	// Limit is the number of requests handled at once
+	// Default: 10
+	Limit int
+
+	// QueueSize is the number of requests waiting for a slot; further
+	// requests are rejected at once
+	// Default: 0 (no queue)
+	QueueSize int
+
+	// QueueTimeout is how long a request waits for a slot before it is
+	// rejected
+	// Default: 5s
+	QueueTimeout time.Duration
+
+	// RetryAfter is sent in the Retry-After header of rejected requests
+	// Default: QueueTimeout, at least 1 second
+	RetryAfter time.Duration
+
+	// OnReject is called for each rejected request, e.g. for metrics
+	// Optional.
+	OnReject func(c *Context)
+}
+
+// BulkheadStats reports the activity of a Bulkhead
+type BulkheadStats struct {
+	Limit    int   `json:"limit"`
+	Active   int   `json:"active"`
+	Queued   int   `json:"queued"`
+	Rejected int64 `json:"rejected"`
+}
+
+// Bulkhead bounds the requests handled at once by the routes it guards,
+// so a slow endpoint can't take all the capacity the others need
+type Bulkhead struct {
+	config   BulkheadConfig
+	slots    chan struct{}
+	queued   atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewBulkhead returns a bulkhead, whose Middleware can guard several
+// routes with one limit and whose Stats can be exposed for monitoring
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	if config.Limit <= 0 {
+		config.Limit = 10
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = 5 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = config.QueueTimeout
+	}
+	return &Bulkhead{config: config, slots: make(chan struct{}, config.Limit)}
+}
+
+// ConcurrencyLimit returns a middleware handling at most n requests at
+// once, with up to queueSize requests waiting up to timeout for a slot.
+// Other requests are rejected with 503 and a Retry-After header:
+//
+//	r.GET("/reports/sales", goTap.ConcurrencyLimit(2, 10, 3*time.Second), salesReport)
+//	pos := r.Group("/pos", goTap.ConcurrencyLimit(100, 200, time.Second))
+func ConcurrencyLimit(n, queueSize int, timeout time.Duration) HandlerFunc {
+	return NewBulkhead(BulkheadConfig{Limit: n, QueueSize: queueSize, QueueTimeout: timeout}).Middleware()
+}
+
+// Middleware returns the middleware of the bulkhead
+func (b *Bulkhead) Middleware() HandlerFunc {
+	retryAfter := strconv.Itoa(max(1, int((b.config.RetryAfter+time.Second-1)/time.Second)))
+	return func(c *Context) {
+		if !b.acquire(c) {
+			if c.Request.Context().Err() != nil {
+				// The client is gone
+				c.Abort()
+				return
+			}
+			b.rejected.Add(1)
+			if b.config.OnReject != nil {
+				b.config.OnReject(c)
+			}
+			c.Header("Retry-After", retryAfter)
+			c.JSON(http.StatusServiceUnavailable, H{
+				"error":   "Service Unavailable",
+				"message": "too many concurrent requests",
+			})
+			c.Abort()
+			return
+		}
+		defer func() { <-b.slots }()
+		c.Next()
+	}
+}
+
+// acquire takes a slot, waiting in the queue if there is room
+func (b *Bulkhead) acquire(c *Context) bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if b.queued.Add(1) > int64(b.config.QueueSize) {
+		b.queued.Add(-1)
+		return false
+	}
+	defer b.queued.Add(-1)
+
+	timer := time.NewTimer(b.config.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
+
+// Stats reports the requests handled, waiting and rejected
+func (b *Bulkhead) Stats() BulkheadStats {
+	return BulkheadStats{
+		Limit:    b.config.Limit,
+		Active:   len(b.slots),
+		Queued:   int(b.queued.Load()),
+		Rejected: b.rejected.Load(),
+	}
+}
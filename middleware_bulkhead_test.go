@@ -0,0 +1,85 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadConfig{Limit: 1, QueueSize: 1, QueueTimeout: 50 * time.Millisecond})
+	release := make(chan struct{})
+	r := New()
+	r.GET("/reports", bulkhead.Middleware(), func(c *Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/pos", func(c *Context) { c.Status(http.StatusOK) })
+
+	codes := make(chan int, 3)
+	var wg sync.WaitGroup
+	get := func(path string) {
+		defer wg.Done()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		codes <- w.Code
+	}
+
+	wg.Add(2)
+	go get("/reports")
+	waitFor(t, func() bool { return bulkhead.Stats().Active == 1 })
+	go get("/reports")
+	waitFor(t, func() bool { return bulkhead.Stats().Queued == 1 })
+
+	// The queue is full: rejected at once
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/reports", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 503 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+	// Other routes are not limited
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/pos", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected other routes to be served, got %d", w.Code)
+	}
+
+	// The queued request times out
+	if code := <-codes; code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the queued request to time out, got %d", code)
+	}
+	close(release)
+	wg.Wait()
+	if code := <-codes; code != http.StatusOK {
+		t.Errorf("Expected the first request to complete, got %d", code)
+	}
+	if stats := bulkhead.Stats(); stats.Active != 0 || stats.Queued != 0 || stats.Rejected != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A queued request gets the slot released before its timeout
+	limited := New()
+	limited.GET("/", ConcurrencyLimit(1, 5, time.Second), func(c *Context) {
+		time.Sleep(10 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	results := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			w := httptest.NewRecorder()
+			limited.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			results <- w.Code
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if code := <-results; code != http.StatusOK {
+			t.Errorf("Expected queued requests to be served, got %d", code)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CBState is the state of a circuit breaker
+type CBState int
+
+const (
+	// CBClosed lets calls through and counts failures
+	CBClosed CBState = iota
+	// CBOpen rejects calls until OpenTimeout has passed
+	CBOpen
+	// CBHalfOpen lets up to HalfOpenMax trial calls through
+	CBHalfOpen
+)
+
+func (s CBState) String() string {
+	switch s {
+	case CBClosed:
+		return "closed"
+	case CBOpen:
+		return "open"
+	case CBHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CBConfig holds configuration for a circuit breaker
+type CBConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit
+	// Default: 5
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before trial calls
+	// Default: 30 seconds
+	OpenTimeout time.Duration
+
+	// HalfOpenMax is the number of trial calls allowed while half-open; the
+	// circuit closes once they all succeed
+	// Default: 1
+	HalfOpenMax int
+
+	// IsFailure decides whether a call failed from its status code and error
+	// Default: an error or a 5xx status
+	IsFailure func(status int, err error) bool
+
+	// OnStateChange is called when the circuit changes state
+	// Optional.
+	OnStateChange func(name string, from, to CBState)
+}
+
+// CBStats reports the activity of a circuit breaker
+type CBStats struct {
+	Name                string    `json:"name"`
+	State               string    `json:"state"`
+	Requests            int64     `json:"requests"`
+	Failures            int64     `json:"failures"`
+	Rejected            int64     `json:"rejected"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	StateChangedAt      time.Time `json:"state_changed_at"`
+}
+
+// Breaker is a named circuit breaker, shared by the CircuitBreaker
+// middleware and the HTTP clients returned by c.HTTPClient
+type Breaker struct {
+	name   string
+	config CBConfig
+	now    func() time.Time
+
+	mu             sync.Mutex
+	state          CBState
+	generation     uint64
+	consecutive    int
+	halfOpenActive int
+	halfOpenOK     int
+	changedAt      time.Time
+	requests       int64
+	failures       int64
+	rejected       int64
+
+	clientOnce sync.Once
+	client     *http.Client
+}
+
+// circuitBreakers holds breakers by name
+var circuitBreakers = struct {
+	sync.Mutex
+	m map[string]*Breaker
+}{m: make(map[string]*Breaker)}
+
+// RegisterCircuitBreaker returns the breaker called name, creating it with
+// config if it doesn't exist yet
+func RegisterCircuitBreaker(name string, config CBConfig) *Breaker {
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+	if b, ok := circuitBreakers.m[name]; ok {
+		return b
+	}
+
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMax <= 0 {
+		config.HalfOpenMax = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(status int, err error) bool {
+			return err != nil || status >= 500
+		}
+	}
+	b := &Breaker{name: name, config: config, now: time.Now, changedAt: time.Now()}
+	circuitBreakers.m[name] = b
+	return b
+}
+
+// GetCircuitBreaker returns the breaker called name
+func GetCircuitBreaker(name string) (*Breaker, bool) {
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+	b, ok := circuitBreakers.m[name]
+	return b, ok
+}
+
+// CircuitBreaker returns a middleware guarding routes that depend on a
+// downstream service. While the circuit is open requests fail fast with
+// 503 instead of piling up on a dead dependency:
+//
+//	gateway := goTap.CircuitBreaker("payment-gateway", goTap.CBConfig{
+//	    FailureThreshold: 5,
+//	    OpenTimeout:      30 * time.Second,
+//	})
+//	r.POST("/payments/capture", gateway, capturePayment)
+//
+// Responses with a 5xx status and panics count as failures. Use
+// c.HTTPClient with the same name to share the circuit with outgoing calls.
+func CircuitBreaker(name string, config CBConfig) HandlerFunc {
+	b := RegisterCircuitBreaker(name, config)
+	return func(c *Context) {
+		done, err := b.Allow()
+		if err != nil {
+			c.Header("Retry-After", strconv.Itoa(int(b.config.OpenTimeout.Seconds())))
+			c.JSON(503, H{
+				"error":   "Service Unavailable",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		panicked := true
+		defer func() {
+			if panicked {
+				done(false)
+			}
+		}()
+		c.Next()
+		panicked = false
+		done(!b.config.IsFailure(c.Writer.Status(), nil))
+	}
+}
+
+// Name returns the breaker's name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state
+func (b *Breaker) State() CBState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// Allow reserves a call. It returns ErrCircuitOpen if the call is
+// rejected; otherwise done must be called with the call's outcome.
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	b.mu.Lock()
+	change := b.refresh()
+	allowed := true
+	switch b.state {
+	case CBOpen:
+		allowed = false
+	case CBHalfOpen:
+		if b.halfOpenActive >= b.config.HalfOpenMax {
+			allowed = false
+		} else {
+			b.halfOpenActive++
+		}
+	}
+	if allowed {
+		b.requests++
+	} else {
+		b.rejected++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(change)
+
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, b.name)
+	}
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { b.record(generation, success) })
+	}, nil
+}
+
+// record applies the outcome of a call made in generation
+func (b *Breaker) record(generation uint64, success bool) {
+	b.mu.Lock()
+	if !success {
+		b.failures++
+	}
+	// Results of calls from before the last state change don't count
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var change *cbChange
+	switch b.state {
+	case CBClosed:
+		if success {
+			b.consecutive = 0
+		} else if b.consecutive++; b.consecutive >= b.config.FailureThreshold {
+			change = b.setState(CBOpen)
+		}
+	case CBHalfOpen:
+		b.halfOpenActive--
+		if !success {
+			b.consecutive++
+			change = b.setState(CBOpen)
+		} else if b.halfOpenOK++; b.halfOpenOK >= b.config.HalfOpenMax {
+			change = b.setState(CBClosed)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// Reset closes the circuit
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	var change *cbChange
+	if b.state != CBClosed {
+		change = b.setState(CBClosed)
+	}
+	b.consecutive = 0
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// Stats returns breaker counters
+func (b *Breaker) Stats() CBStats {
+	b.mu.Lock()
+	change := b.refresh()
+	stats := CBStats{
+		Name:                b.name,
+		State:               b.state.String(),
+		Requests:            b.requests,
+		Failures:            b.failures,
+		Rejected:            b.rejected,
+		ConsecutiveFailures: b.consecutive,
+		StateChangedAt:      b.changedAt,
+	}
+	b.mu.Unlock()
+	b.notify(change)
+	return stats
+}
+
+type cbChange struct {
+	from, to CBState
+}
+
+// refresh moves an open circuit to half-open once OpenTimeout passed.
+// b.mu must be held.
+func (b *Breaker) refresh() *cbChange {
+	if b.state == CBOpen && b.now().Sub(b.changedAt) >= b.config.OpenTimeout {
+		return b.setState(CBHalfOpen)
+	}
+	return nil
+}
+
+// setState changes state and starts a new generation. b.mu must be held.
+func (b *Breaker) setState(state CBState) *cbChange {
+	change := &cbChange{from: b.state, to: state}
+	b.state = state
+	b.generation++
+	b.changedAt = b.now()
+	b.halfOpenActive = 0
+	b.halfOpenOK = 0
+	if state == CBClosed {
+		b.consecutive = 0
+	}
+	return change
+}
+
+// notify calls OnStateChange outside the lock
+func (b *Breaker) notify(change *cbChange) {
+	if change == nil {
+		return
+	}
+	debugPrint("[WARNING] circuit breaker %s: %s -> %s", b.name, change.from, change.to)
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(b.name, change.from, change.to)
+	}
+}
+
+// RoundTripper wraps next so outgoing requests go through the breaker
+func (b *Breaker) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &breakerTransport{breaker: b, next: next}
+}
+
+type breakerTransport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	done(!t.breaker.config.IsFailure(status, err))
+	return resp, err
+}
+
+// HTTPClient returns an HTTP client for the downstream service name whose
+// calls go through the circuit breaker of that name, registering one with
+// the default CBConfig if needed. Calls fail with ErrCircuitOpen while the
+// circuit is open:
+//
+//	resp, err := c.HTTPClient("payment-gateway").Do(req.WithContext(c.Request.Context()))
+func (c *Context) HTTPClient(name string) *http.Client {
+	b := RegisterCircuitBreaker(name, CBConfig{})
+	b.clientOnce.Do(func() {
+		b.client = &http.Client{
+			Transport: b.RoundTripper(http.DefaultTransport),
+			Timeout:   30 * time.Second,
+		}
+	})
+	return b.client
+}
+
+// CircuitBreakerStats returns the stats of all circuit breakers by name
+func CircuitBreakerStats() []CBStats {
+	circuitBreakers.Lock()
+	breakers := make([]*Breaker, 0, len(circuitBreakers.m))
+	for _, b := range circuitBreakers.m {
+		breakers = append(breakers, b)
+	}
+	circuitBreakers.Unlock()
+
+	stats := make([]CBStats, len(breakers))
+	for i, b := range breakers {
+		stats[i] = b.Stats()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// CircuitBreakerHandler returns a handler serving CircuitBreakerStats for
+// dashboards and metrics scrapers
+func CircuitBreakerHandler() HandlerFunc {
+	return func(c *Context) {
+		c.JSON(200, H{"circuit_breakers": CircuitBreakerStats()})
+	}
+}
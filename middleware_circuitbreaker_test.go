@@ -0,0 +1,144 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	now := time.Now()
+	failing := true
+
+	r := New()
+	r.Use(Recovery())
+	r.POST("/capture", CircuitBreaker("test-gateway", CBConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		HalfOpenMax:      2,
+		OnStateChange: func(name string, from, to CBState) {
+			mu.Lock()
+			changes = append(changes, from.String()+">"+to.String())
+			mu.Unlock()
+		},
+	}), func(c *Context) {
+		if failing {
+			c.Status(502)
+			return
+		}
+		c.Status(200)
+	})
+	b, _ := GetCircuitBreaker("test-gateway")
+	b.now = func() time.Time { return now }
+
+	capture := func() int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/capture", nil))
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := capture(); code != 502 {
+			t.Fatalf("Expected downstream failure, got %d", code)
+		}
+	}
+	if code := capture(); code != 503 || b.State() != CBOpen {
+		t.Fatalf("Expected open circuit to fail fast, got %d in state %s", code, b.State())
+	}
+
+	// After OpenTimeout a failing trial reopens the circuit
+	now = now.Add(time.Minute)
+	if code := capture(); code != 502 || b.State() != CBOpen {
+		t.Fatalf("Expected failed trial to reopen, got %d in state %s", code, b.State())
+	}
+
+	// Successful trials close it
+	now = now.Add(time.Minute)
+	failing = false
+	for i := 0; i < 2; i++ {
+		if code := capture(); code != 200 {
+			t.Fatalf("Expected trial to pass, got %d", code)
+		}
+	}
+	if b.State() != CBClosed {
+		t.Errorf("Expected closed circuit, got %s", b.State())
+	}
+
+	mu.Lock()
+	got := strings.Join(changes, ",")
+	mu.Unlock()
+	if got != "closed>open,open>half-open,half-open>open,open>half-open,half-open>closed" {
+		t.Errorf("Unexpected state changes %s", got)
+	}
+	if stats := b.Stats(); stats.Requests != 6 || stats.Failures != 4 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	b := RegisterCircuitBreaker("test-half-open", CBConfig{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	done, _ := b.Allow()
+	done(false)
+	time.Sleep(2 * time.Millisecond)
+
+	trial, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a single trial call while half-open, got %v", err)
+	}
+	trial(true)
+	if b.State() != CBClosed {
+		t.Errorf("Expected closed circuit, got %s", b.State())
+	}
+}
+
+func TestContextHTTPClient(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer downstream.Close()
+	RegisterCircuitBreaker("test-client", CBConfig{FailureThreshold: 2})
+
+	r := New()
+	r.GET("/charge", func(c *Context) {
+		resp, err := c.HTTPClient("test-client").Get(downstream.URL)
+		if errors.Is(err, ErrCircuitOpen) {
+			c.String(503, "gateway unavailable")
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		c.Status(resp.StatusCode)
+	})
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/charge", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != 500 || codes[1] != 500 || codes[2] != 503 {
+		t.Errorf("Expected circuit to open after 2 failures, got %v", codes)
+	}
+
+	r.GET("/breakers", CircuitBreakerHandler())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/breakers", nil))
+	if !strings.Contains(w.Body.String(), `"name":"test-client","state":"open"`) {
+		t.Errorf("Expected breaker in stats, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"path"
+	"strings"
+)
+
+// Matcher reports whether a request matches, for When and Unless
+type Matcher func(c *Context) bool
+
+// When runs middleware only for the requests matching m; other requests go
+// straight to the next handler:
+//
+//	r.Use(goTap.When(goTap.MethodIs("POST", "PUT", "DELETE"), goTap.AuditLog(auditConfig)))
+func When(m Matcher, middleware HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if m(c) {
+			middleware(c)
+		}
+	}
+}
+
+// Unless runs middleware for every request except those matching m, e.g.
+// to keep health checks and static files out of authentication:
+//
+//	r.Use(goTap.Unless(goTap.PathIs("/health", "/metrics"), goTap.JWTAuth(secret)))
+//	r.Use(goTap.Unless(goTap.PathPrefix("/static/"), goTap.Gzip()))
+func Unless(m Matcher, middleware HandlerFunc) HandlerFunc {
+	return When(Not(m), middleware)
+}
+
+// PathIs matches requests whose URL path is one of paths
+func PathIs(paths ...string) Matcher {
+	return func(c *Context) bool {
+		return containsString(paths, c.Request.URL.Path)
+	}
+}
+
+// PathPrefix matches requests whose URL path starts with one of prefixes
+func PathPrefix(prefixes ...string) Matcher {
+	return func(c *Context) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PathMatches matches requests whose URL path matches one of patterns, in
+// the syntax of path.Match, e.g. "/api/*/export"
+func PathMatches(patterns ...string) Matcher {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic("goTap: invalid path pattern " + pattern + ": " + err.Error())
+		}
+	}
+	return func(c *Context) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, c.Request.URL.Path); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RouteIs matches requests handled by one of routes, as registered, e.g.
+// "/orders/:id"
+func RouteIs(routes ...string) Matcher {
+	return func(c *Context) bool {
+		return containsString(routes, c.FullPath())
+	}
+}
+
+// MethodIs matches requests with one of methods
+func MethodIs(methods ...string) Matcher {
+	upper := make([]string, len(methods))
+	for i, method := range methods {
+		upper[i] = strings.ToUpper(method)
+	}
+	return func(c *Context) bool {
+		return containsString(upper, c.Request.Method)
+	}
+}
+
+// Not matches the requests m does not match
+func Not(m Matcher) Matcher {
+	return func(c *Context) bool {
+		return !m(c)
+	}
+}
+
+// AnyOf matches the requests matching at least one of matchers
+func AnyOf(matchers ...Matcher) Matcher {
+	return func(c *Context) bool {
+		for _, m := range matchers {
+			if m(c) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf matches the requests matching all of matchers
+func AllOf(matchers ...Matcher) Matcher {
+	return func(c *Context) bool {
+		for _, m := range matchers {
+			if !m(c) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalMiddleware(t *testing.T) {
+	var ran []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) {
+			ran = append(ran, name)
+			c.Next()
+		}
+	}
+	deny := func(c *Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+
+	r := New()
+	r.Use(When(MethodIs("post", "DELETE"), mark("audit")))
+	r.Use(Unless(AnyOf(PathIs("/health"), PathPrefix("/static/"), PathMatches("/public/*")), deny))
+	ok := func(c *Context) { c.Status(http.StatusOK) }
+	r.GET("/health", ok)
+	r.GET("/static/*file", ok)
+	r.GET("/public/:page", ok)
+	r.POST("/public/:page", ok)
+	r.DELETE("/orders/:id", ok)
+
+	for _, tt := range []struct {
+		method, path string
+		code         int
+		ran          []string
+	}{
+		{"GET", "/health", http.StatusOK, nil},
+		{"GET", "/static/css/app.css", http.StatusOK, nil},
+		{"POST", "/public/terms", http.StatusOK, []string{"audit"}},
+		{"DELETE", "/orders/7", http.StatusUnauthorized, []string{"audit"}},
+	} {
+		ran = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || len(ran) != len(tt.ran) || (len(ran) > 0 && ran[0] != tt.ran[0]) {
+			t.Errorf("%s %s: expected %d %v, got %d %v", tt.method, tt.path, tt.code, tt.ran, w.Code, ran)
+		}
+	}
+
+	order := New()
+	order.Use(When(AllOf(RouteIs("/orders/:id"), Not(MethodIs("GET"))), mark("order")))
+	order.GET("/orders/:id", ok)
+	order.DELETE("/orders/:id", ok)
+	ran = nil
+	order.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/7", nil))
+	order.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/orders/7", nil))
+	if len(ran) != 1 || ran[0] != "order" {
+		t.Errorf("Expected the route matcher to select the DELETE only, got %v", ran)
+	}
+}
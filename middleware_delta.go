@@ -0,0 +1,249 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DeltaConfig holds configuration for the DeltaSync middleware
+type DeltaConfig struct {
+	// Versions is how many previous payloads are kept per collection to
+	// compute deltas against
+	// Default: 8
+	Versions int
+
+	// MaxCollections bounds the number of collections kept in memory; the
+	// least recently used one is dropped first
+	// Default: 1000
+	MaxCollections int
+
+	// KeyFunc identifies the collection a response belongs to, e.g. the
+	// store ID for a per-store product catalog
+	// Default: request path and query
+	KeyFunc func(c *Context) string
+}
+
+// DeltaSync returns a middleware for collection sync endpoints. JSON GET
+// responses get an ETag; a matching If-None-Match yields 304 Not Modified.
+// Clients that send "A-IM: json-patch" and the ETag of a version they hold
+// receive 226 IM Used with an RFC 6902 patch against that version:
+//
+//	r.GET("/sync/products", goTap.DeltaSync(), listProducts)
+//
+//	GET /sync/products
+//	If-None-Match: "3f2a9c41d07b5e88"
+//	A-IM: json-patch
+func DeltaSync() HandlerFunc {
+	return DeltaSyncWithConfig(DeltaConfig{})
+}
+
+// DeltaSyncWithConfig returns a DeltaSync middleware with config
+func DeltaSyncWithConfig(config DeltaConfig) HandlerFunc {
+	if config.Versions <= 0 {
+		config.Versions = 8
+	}
+	if config.MaxCollections <= 0 {
+		config.MaxCollections = 1000
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *Context) string { return c.Request.URL.RequestURI() }
+	}
+	store := &deltaStore{
+		versions: config.Versions,
+		max:      config.MaxCollections,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+
+	return func(c *Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		dw := &deltaWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = dw
+		c.Next()
+		c.Writer = original
+
+		body := dw.body.Bytes()
+		if dw.status != http.StatusOK || !strings.Contains(original.Header().Get("Content-Type"), "json") {
+			dw.flush()
+			return
+		}
+
+		etag := `"` + deltaHash(body) + `"`
+		base, baseBody := store.put(config.KeyFunc(c), etag, body, c.GetHeader("If-None-Match"))
+
+		header := original.Header()
+		header.Set("ETag", etag)
+		header.Add("Vary", "A-IM")
+
+		if base == etag {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		if baseBody != nil && acceptsJSONPatch(c.GetHeader("A-IM")) {
+			if ops, err := DiffJSON(baseBody, body); err == nil {
+				if patch, err := json.Marshal(ops); err == nil && len(patch) < len(body) {
+					header.Set("Content-Type", "application/json-patch+json")
+					header.Set("IM", "json-patch")
+					header.Set("Delta-Base", base)
+					header.Del("Content-Length")
+					original.WriteHeader(http.StatusIMUsed)
+					original.Write(patch)
+					return
+				}
+			}
+		}
+		dw.flush()
+	}
+}
+
+func acceptsJSONPatch(aim string) bool {
+	for _, im := range strings.Split(aim, ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(im), ";"); strings.EqualFold(name, "json-patch") {
+			return true
+		}
+	}
+	return false
+}
+
+func deltaHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// deltaWriter buffers the response so it can be replaced by 304 or a delta
+type deltaWriter struct {
+	ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *deltaWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *deltaWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *deltaWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *deltaWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *deltaWriter) Status() int {
+	return w.status
+}
+
+func (w *deltaWriter) Size() int {
+	if !w.written {
+		return noWritten
+	}
+	return w.body.Len()
+}
+
+func (w *deltaWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op: the body is held until the handler returns
+func (w *deltaWriter) Flush() {}
+
+// flush writes the buffered response unchanged
+func (w *deltaWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// deltaStore keeps recent payload versions per collection
+type deltaStore struct {
+	mu       sync.Mutex
+	versions int
+	max      int
+	items    map[string]*list.Element
+	lru      *list.List
+}
+
+type deltaCollection struct {
+	key      string
+	versions []deltaVersion // oldest first
+}
+
+type deltaVersion struct {
+	etag string
+	body []byte
+}
+
+// put records the current payload of a collection and returns the first
+// If-None-Match ETag that names a known version, with that version's body
+func (s *deltaStore) put(key, etag string, body []byte, ifNoneMatch string) (string, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var col *deltaCollection
+	if el, ok := s.items[key]; ok {
+		s.lru.MoveToFront(el)
+		col = el.Value.(*deltaCollection)
+	} else {
+		col = &deltaCollection{key: key}
+		s.items[key] = s.lru.PushFront(col)
+		if s.lru.Len() > s.max {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.items, oldest.Value.(*deltaCollection).key)
+		}
+	}
+
+	var base string
+	var baseBody []byte
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			base, baseBody = etag, nil
+			break
+		}
+		for _, v := range col.versions {
+			if v.etag == tag && base == "" {
+				base, baseBody = tag, v.body
+			}
+		}
+	}
+
+	if n := len(col.versions); n == 0 || col.versions[n-1].etag != etag {
+		col.versions = append(col.versions, deltaVersion{etag: etag, body: bytes.Clone(body)})
+		if len(col.versions) > s.versions {
+			col.versions = col.versions[1:]
+		}
+	}
+	return base, baseBody
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiffJSONCollection(t *testing.T) {
+	from := []byte(`{"data":[{"id":1,"name":"tea","price":2},{"id":2,"name":"coffee","price":3},{"id":3,"name":"juice","price":4}],"total":3}`)
+	to := []byte(`{"data":[{"id":1,"name":"tea","price":2.5},{"id":3,"name":"juice","price":4},{"id":4,"name":"cola","price":null}],"total":3}`)
+
+	ops, err := DiffJSON(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, _ := json.Marshal(ops)
+	want := `[{"op":"replace","path":"/data/0/price","value":2.5},{"op":"remove","path":"/data/1"},{"op":"add","path":"/data/2","value":{"id":4,"name":"cola","price":null}}]`
+	if string(patch) != want {
+		t.Errorf("Unexpected patch\n got %s\nwant %s", patch, want)
+	}
+
+	applied, err := ApplyJSONPatch(from, ops)
+	if err != nil {
+		t.Fatalf("ApplyJSONPatch failed: %v", err)
+	}
+	var got, expected any
+	json.Unmarshal(applied, &got)
+	json.Unmarshal(to, &expected)
+	if string(mustJSON(got)) != string(mustJSON(expected)) {
+		t.Errorf("Patched document differs\n got %s\nwant %s", applied, to)
+	}
+
+	if _, err := ApplyJSONPatch([]byte(`{"a":[]}`), []JSONPatchOp{{Op: "remove", Path: "/a/3"}}); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func TestDeltaSync(t *testing.T) {
+	products := []H{{"id": 1, "name": "tea"}, {"id": 2, "name": "coffee"}}
+	for i := 3; i < 20; i++ {
+		products = append(products, H{"id": i, "name": "product with a long enough name"})
+	}
+
+	r := New()
+	r.GET("/sync/products", DeltaSync(), func(c *Context) {
+		c.JSON(200, H{"data": products})
+	})
+	r.GET("/sync/text", DeltaSync(), func(c *Context) {
+		c.String(200, "plain")
+	})
+
+	get := func(path, etag, aim string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if aim != "" {
+			req.Header.Set("A-IM", aim)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/sync/products", "", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != 200 || etag == "" {
+		t.Fatalf("Expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+
+	if w := get("/sync/products", etag, "json-patch"); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 for current version, got %d %q", w.Code, w.Body.String())
+	}
+
+	products[1]["name"] = "espresso"
+
+	w := get("/sync/products", etag, "json-patch")
+	if w.Code != http.StatusIMUsed {
+		t.Fatalf("Expected 226 delta, got %d", w.Code)
+	}
+	if w.Header().Get("Delta-Base") != etag || w.Header().Get("IM") != "json-patch" || w.Header().Get("Content-Type") != "application/json-patch+json" {
+		t.Errorf("Unexpected delta headers %v", w.Header())
+	}
+	if w.Body.String() != `[{"op":"replace","path":"/data/1/name","value":"espresso"}]` {
+		t.Errorf("Unexpected delta body %s", w.Body.String())
+	}
+	var ops []JSONPatchOp
+	json.Unmarshal(w.Body.Bytes(), &ops)
+	patched, _ := ApplyJSONPatch(first.Body.Bytes(), ops)
+	full := get("/sync/products", "", "")
+	if string(mustJSON(decodeForTest(patched))) != string(mustJSON(decodeForTest(full.Body.Bytes()))) {
+		t.Errorf("Patched payload does not match full payload")
+	}
+	if w.Header().Get("ETag") != full.Header().Get("ETag") {
+		t.Errorf("Expected delta to carry the new ETag")
+	}
+
+	// Without A-IM or with an unknown base the full payload is sent
+	if w := get("/sync/products", etag, ""); w.Code != 200 {
+		t.Errorf("Expected full response without A-IM, got %d", w.Code)
+	}
+	if w := get("/sync/products", `"unknown"`, "json-patch"); w.Code != 200 {
+		t.Errorf("Expected full response for unknown base, got %d", w.Code)
+	}
+
+	if w := get("/sync/text", "", ""); w.Code != 200 || w.Body.String() != "plain" || w.Header().Get("ETag") != "" {
+		t.Errorf("Expected non-JSON response to pass through, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func decodeForTest(data []byte) any {
+	var v any
+	json.Unmarshal(data, &v)
+	return v
+}
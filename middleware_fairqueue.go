@@ -0,0 +1,281 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestSchedulerConfig holds configuration for a RequestScheduler
+type RequestSchedulerConfig struct {
+	// Limit is the number of requests handled at once
+	// Default: 100
+	Limit int
+
+	// Weights are the shares of the freed slots each priority gets while
+	// several priorities wait, e.g. with the defaults critical requests get
+	// 8 of every 11 slots, so background requests are delayed but not
+	// starved. A missing priority has weight 1.
+	// Default: critical 8, normal 2, background 1
+	Weights map[Priority]int
+
+	// QueueSize is the number of requests waiting per priority; further
+	// requests are rejected at once
+	// Default: 1000
+	QueueSize int
+
+	// QueueTimeout is how long a request waits for a slot before it is
+	// rejected
+	// Default: 10s
+	QueueTimeout time.Duration
+
+	// RetryAfter is sent in the Retry-After header of rejected requests
+	// Default: 1s
+	RetryAfter time.Duration
+}
+
+// RequestSchedulerStats reports the queue of a priority of a RequestScheduler
+type RequestSchedulerStats struct {
+	Priority string `json:"priority"`
+	Queued   int    `json:"queued"`
+	Admitted int64  `json:"admitted"`
+	Rejected int64  `json:"rejected"`
+
+	// Waited counts the admitted requests that had to queue, and AvgWait
+	// and MaxWait their time in the queue
+	Waited  int64         `json:"waited"`
+	AvgWait time.Duration `json:"avg_wait"`
+	MaxWait time.Duration `json:"max_wait"`
+}
+
+// RequestScheduler bounds the requests handled at once and, under saturation,
+// serves the queued requests by priority with weighted fair queuing
+type RequestScheduler struct {
+	config     RequestSchedulerConfig
+	retryAfter string
+
+	mu     sync.Mutex
+	active int
+	// classes are indexed by schedulerClass: background, normal, critical
+	classes [3]schedulerClass
+}
+
+type schedulerClass struct {
+	weight  int
+	current int // smooth weighted round robin state
+	queue   []*schedulerWaiter
+
+	admitted, rejected, waited int64
+	totalWait, maxWait         time.Duration
+}
+
+type schedulerWaiter struct {
+	ready   chan struct{}
+	granted bool
+	since   time.Time
+}
+
+var schedulerPriorities = [3]Priority{PriorityBackground, PriorityNormal, PriorityCritical}
+
+// NewRequestScheduler returns a scheduler, whose Stats can be exposed for
+// monitoring
+func NewRequestScheduler(config RequestSchedulerConfig) *RequestScheduler {
+	if config.Limit <= 0 {
+		config.Limit = 100
+	}
+	if config.Weights == nil {
+		config.Weights = map[Priority]int{PriorityCritical: 8, PriorityNormal: 2, PriorityBackground: 1}
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = 10 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	s := &RequestScheduler{
+		config:     config,
+		retryAfter: strconv.Itoa(max(1, int((config.RetryAfter+time.Second-1)/time.Second))),
+	}
+	for i, p := range schedulerPriorities {
+		s.classes[i].weight = 1
+		if w, ok := config.Weights[p]; ok && w > 0 {
+			s.classes[i].weight = w
+		}
+	}
+	return s
+}
+
+// PriorityScheduler returns a middleware scheduling requests by the
+// priority of their routes, see RouterGroup.Priority:
+//
+//	r.Use(goTap.PriorityScheduler(goTap.RequestSchedulerConfig{Limit: 200}))
+//	r.Group("/pos").Priority(goTap.PriorityCritical).POST("/transaction", pay)
+//
+// Requests are handled at once while fewer than Limit are in progress.
+// Beyond that they queue, and each finished request hands its slot to a
+// queued one, picked by the weights of the priorities waiting. Requests
+// that find their queue full or wait longer than QueueTimeout get 503
+// with a Retry-After header.
+func PriorityScheduler(config RequestSchedulerConfig) HandlerFunc {
+	return NewRequestScheduler(config).Middleware()
+}
+
+// Middleware returns the middleware of the scheduler
+func (s *RequestScheduler) Middleware() HandlerFunc {
+	return func(c *Context) {
+		if !s.acquire(c) {
+			if c.Request.Context().Err() != nil {
+				// The client is gone
+				c.Abort()
+				return
+			}
+			c.Header("Retry-After", s.retryAfter)
+			c.JSON(http.StatusServiceUnavailable, H{
+				"error":   "Service Unavailable",
+				"message": "server saturated, retry later",
+			})
+			c.Abort()
+			return
+		}
+		defer s.release()
+		c.Next()
+	}
+}
+
+// schedulerClassOf returns the class index of p
+func schedulerClassOf(p Priority) int {
+	switch {
+	case p < PriorityNormal:
+		return 0
+	case p > PriorityNormal:
+		return 2
+	}
+	return 1
+}
+
+// acquire takes a slot, queueing for one while all are taken
+func (s *RequestScheduler) acquire(c *Context) bool {
+	class := &s.classes[schedulerClassOf(c.Priority())]
+
+	s.mu.Lock()
+	if s.active < s.config.Limit && s.queued() == 0 {
+		s.active++
+		class.admitted++
+		s.mu.Unlock()
+		return true
+	}
+	if len(class.queue) >= s.config.QueueSize {
+		class.rejected++
+		s.mu.Unlock()
+		return false
+	}
+	w := &schedulerWaiter{ready: make(chan struct{}), since: time.Now()}
+	class.queue = append(class.queue, w)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.config.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return true
+	case <-timer.C:
+	case <-c.Request.Context().Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.granted {
+		// The slot was handed over while timing out
+		return true
+	}
+	for i, queued := range class.queue {
+		if queued == w {
+			class.queue = append(class.queue[:i], class.queue[i+1:]...)
+			break
+		}
+	}
+	class.rejected++
+	return false
+}
+
+// release hands the slot to the next queued request, or frees it
+func (s *RequestScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Smooth weighted round robin over the priorities waiting
+	var next *schedulerClass
+	total := 0
+	for i := range s.classes {
+		class := &s.classes[i]
+		if len(class.queue) == 0 {
+			continue
+		}
+		class.current += class.weight
+		total += class.weight
+		if next == nil || class.current > next.current {
+			next = class
+		}
+	}
+	if next == nil {
+		s.active--
+		return
+	}
+	next.current -= total
+
+	w := next.queue[0]
+	next.queue = next.queue[1:]
+	wait := time.Since(w.since)
+	next.admitted++
+	next.waited++
+	next.totalWait += wait
+	next.maxWait = max(next.maxWait, wait)
+	w.granted = true
+	close(w.ready)
+}
+
+func (s *RequestScheduler) queued() int {
+	n := 0
+	for i := range s.classes {
+		n += len(s.classes[i].queue)
+	}
+	return n
+}
+
+// Stats reports the queue of each priority, the most important first
+func (s *RequestScheduler) Stats() []RequestSchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]RequestSchedulerStats, 0, len(s.classes))
+	for i := len(s.classes) - 1; i >= 0; i-- {
+		class := &s.classes[i]
+		st := RequestSchedulerStats{
+			Priority: schedulerPriorities[i].String(),
+			Queued:   len(class.queue),
+			Admitted: class.admitted,
+			Rejected: class.rejected,
+			Waited:   class.waited,
+			MaxWait:  class.maxWait,
+		}
+		if class.waited > 0 {
+			st.AvgWait = class.totalWait / time.Duration(class.waited)
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// Active returns the number of requests in progress
+func (s *RequestScheduler) Active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityScheduler(t *testing.T) {
+	scheduler := NewRequestScheduler(RequestSchedulerConfig{Limit: 1, QueueSize: 2, QueueTimeout: time.Second})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var served []string
+
+	r := New()
+	r.Use(scheduler.Middleware())
+	r.GET("/block", func(c *Context) { <-release })
+	record := func(c *Context) {
+		mu.Lock()
+		served = append(served, c.Query("id"))
+		mu.Unlock()
+	}
+	r.Group("/pos").Priority(PriorityCritical).POST("/transaction", record)
+	r.GET("/products", record)
+	r.Group("/reports").Priority(PriorityBackground).GET("/sales", record)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	send := func(method, path string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			codes <- w.Code
+		}()
+	}
+	queued := func() int {
+		n := 0
+		for _, st := range scheduler.Stats() {
+			n += st.Queued
+		}
+		return n
+	}
+
+	send("GET", "/block")
+	waitFor(t, func() bool { return scheduler.Active() == 1 })
+	for i, req := range [][2]string{
+		{"GET", "/reports/sales?id=bg1"},
+		{"GET", "/reports/sales?id=bg2"},
+		{"GET", "/products?id=n1"},
+		{"POST", "/pos/transaction?id=c1"},
+		{"POST", "/pos/transaction?id=c2"},
+	} {
+		send(req[0], req[1])
+		waitFor(t, func() bool { return queued() == i+1 })
+	}
+
+	// The background queue is full
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/reports/sales?id=bg3", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 503 for a full queue, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+	if want := []string{"c1", "c2", "n1", "bg1", "bg2"}; !reflect.DeepEqual(served, want) {
+		t.Errorf("Expected %v, got %v", want, served)
+	}
+
+	stats := scheduler.Stats()
+	if stats[0].Priority != "critical" || stats[0].Waited != 2 || stats[0].MaxWait <= 0 ||
+		stats[2].Priority != "background" || stats[2].Admitted != 2 || stats[2].Rejected != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if scheduler.Active() != 0 {
+		t.Errorf("Expected no active request, got %d", scheduler.Active())
+	}
+}
+
+func TestPrioritySchedulerTimeout(t *testing.T) {
+	scheduler := NewRequestScheduler(RequestSchedulerConfig{Limit: 1, QueueTimeout: 20 * time.Millisecond})
+	release := make(chan struct{})
+	r := New()
+	r.Use(scheduler.Middleware())
+	r.GET("/block", func(c *Context) { <-release })
+	r.GET("/", func(c *Context) {})
+
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/block", nil))
+		close(done)
+	}()
+	waitFor(t, func() bool { return scheduler.Active() == 1 })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a queued request to time out, got %d", w.Code)
+	}
+	close(release)
+	<-done
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || scheduler.Active() != 0 {
+		t.Errorf("Expected the slot to be free again, got %d with %d active", w.Code, scheduler.Active())
+	}
+}
@@ -1,6 +1,7 @@
 package goTap
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -46,16 +47,9 @@ func NewGormDB(config *DBConfig) (*DB, error) {
 	// Configure GORM logger
 	gormLogger := logger.Default.LogMode(config.LogLevel)
 
-	var dialector gorm.Dialector
-	switch config.Driver {
-	case "mysql":
-		dialector = mysql.Open(config.DSN)
-	case "postgres", "postgresql":
-		dialector = postgres.Open(config.DSN)
-	case "sqlite":
-		dialector = sqlite.Open(config.DSN)
-	default:
-		return nil, fmt.Errorf("unsupported driver: %s", config.Driver)
+	dialector, err := gormDialector(config.Driver, config.DSN, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	// Open connection
@@ -86,6 +80,21 @@ func NewGormDB(config *DBConfig) (*DB, error) {
 	return db, nil
 }
 
+// gormDialector returns the dialector of driver for dsn, or for an open
+// connection pool conn
+func gormDialector(driver, dsn string, conn gorm.ConnPool) (gorm.Dialector, error) {
+	switch driver {
+	case "mysql":
+		return mysql.New(mysql.Config{DSN: dsn, Conn: conn}), nil
+	case "postgres", "postgresql":
+		return postgres.New(postgres.Config{DSN: dsn, Conn: conn}), nil
+	case "sqlite":
+		return &sqlite.Dialector{DSN: dsn, Conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %s", driver)
+	}
+}
+
 // DBConfigFromEnv loads database configuration from environment variables
 func DBConfigFromEnv() *DBConfig {
 	config := DefaultDBConfig()
@@ -130,20 +139,12 @@ func ConnectDB() (*DB, error) {
 
 // GormInject injects GORM database instance into context
 func GormInject(db *DB) HandlerFunc {
-	return func(c *Context) {
-		c.Set("gorm", db)
-		c.Next()
-	}
+	return Inject("gorm", db)
 }
 
 // GetGorm retrieves GORM database from context
 func GetGorm(c *Context) (*DB, bool) {
-	db, exists := c.Get("gorm")
-	if !exists {
-		return nil, false
-	}
-	gormDB, ok := db.(*DB)
-	return gormDB, ok
+	return Get[*DB](c, "gorm")
 }
 
 // MustGetGorm retrieves GORM database from context or panics
@@ -230,6 +231,7 @@ func GormTransaction() HandlerFunc {
 
 		// Replace db with transaction in context
 		c.Set("gorm", tx)
+		Provide(c, tx)
 
 		// Defer rollback in case of panic
 		defer func() {
@@ -264,6 +266,10 @@ type GormPagination struct {
 
 // NewGormPagination creates a new pagination instance from request
 func NewGormPagination(c *Context) *GormPagination {
+	if p, ok := GetPagination(c); ok {
+		return &GormPagination{Page: p.Page, PageSize: p.PerPage}
+	}
+
 	pagination := &GormPagination{
 		Page:     1,
 		PageSize: 20,
@@ -463,3 +469,47 @@ func GormExists(db *gorm.DB, model interface{}, condition ...interface{}) (bool,
 	}
 	return count > 0, nil
 }
+
+// gormAuditStore writes audit entries to a table
+type gormAuditStore struct {
+	db *gorm.DB
+}
+
+// NewGormAuditStore returns an AuditStore writing to the audit_entries
+// table, which it creates or migrates
+func NewGormAuditStore(db *gorm.DB) (AuditStore, error) {
+	if err := db.AutoMigrate(&AuditEntry{}); err != nil {
+		return nil, err
+	}
+	return &gormAuditStore{db: db}, nil
+}
+
+// WriteAudit implements AuditStore
+func (s *gormAuditStore) WriteAudit(ctx context.Context, entries []AuditEntry) error {
+	return s.db.WithContext(ctx).CreateInBatches(entries, 100).Error
+}
+
+// GormStatusProbe returns a StatusProbe reading the database server version
+func GormStatusProbe(name string, db *gorm.DB) StatusProbe {
+	kind := db.Dialector.Name()
+	query := "SELECT version()"
+	switch kind {
+	case "postgres":
+		query = "SHOW server_version"
+	case "mysql":
+		query = "SELECT VERSION()"
+	case "sqlite":
+		query = "SELECT sqlite_version()"
+	case "sqlserver":
+		query = "SELECT SERVERPROPERTY('ProductVersion')"
+	}
+	return StatusProbe{
+		Name: name,
+		Kind: kind,
+		Version: func(ctx context.Context) (string, error) {
+			var version string
+			err := db.WithContext(ctx).Raw(query).Scan(&version).Error
+			return version, err
+		},
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IdempotentResponse is a stored response replayed for duplicate requests
+type IdempotentResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+
+	// Fingerprint identifies the request the response belongs to, so a key
+	// reused for a different request is rejected instead of replayed
+	Fingerprint string `json:"fingerprint"`
+}
+
+// IdempotencyStore holds idempotency keys and their responses. Entries
+// must be shared by all instances for duplicates to be caught across them.
+type IdempotencyStore interface {
+	// Begin reserves key for an in-flight request. It returns locked=true
+	// when the caller now holds the key, or the stored response if the key
+	// already completed. locked=false with a nil response means another
+	// request holding the key is still in flight.
+	Begin(ctx context.Context, key string, ttl time.Duration) (resp *IdempotentResponse, locked bool, err error)
+
+	// Complete stores the response for key, replacing the reservation
+	Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+
+	// Release drops the reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig holds configuration for the Idempotency middleware
+type IdempotencyConfig struct {
+	// Store holds keys and responses, see NewKVIdempotencyStore
+	// Default: Engine.KVStore if set, else an in-memory store
+	Store IdempotencyStore
+
+	// TTL is how long responses are replayed
+	// Default: 24 hours
+	TTL time.Duration
+
+	// LockTimeout bounds how long an in-flight reservation blocks
+	// duplicates, in case the instance handling it dies
+	// Default: 1 minute
+	LockTimeout time.Duration
+
+	// Header carries the client's key
+	// Default: "Idempotency-Key"
+	Header string
+
+	// Methods the middleware applies to
+	// Default: ["POST"]
+	Methods []string
+
+	// Required rejects requests without a key with 400
+	// Optional.
+	Required bool
+
+	// KeyFunc scopes the client's key, e.g. per API client
+	// Default: user_id from context, method and path
+	KeyFunc func(c *Context, key string) string
+}
+
+// Idempotency returns a middleware that replays the first response for
+// requests repeating an Idempotency-Key, so retried payments don't charge
+// twice:
+//
+//	store := goTap.NewRedisIdempotencyStore(redisClient, "")
+//	r.POST("/payments", goTap.Idempotency(store, 24*time.Hour), createPayment)
+//
+// Duplicates arriving while the first request is in flight get 409.
+// Responses with a 5xx status are not stored, so they can be retried.
+func Idempotency(store IdempotencyStore, ttl time.Duration) HandlerFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{Store: store, TTL: ttl})
+}
+
+// IdempotencyWithConfig returns an Idempotency middleware with config
+func IdempotencyWithConfig(config IdempotencyConfig) HandlerFunc {
+	var memory IdempotencyStore
+	if config.Store == nil {
+		memory = NewMemoryIdempotencyStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = time.Minute
+	}
+	if config.Header == "" {
+		config.Header = "Idempotency-Key"
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost}
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultIdempotencyKey
+	}
+
+	return func(c *Context) {
+		applies := false
+		for _, method := range config.Methods {
+			if c.Request.Method == method {
+				applies = true
+				break
+			}
+		}
+		if !applies {
+			c.Next()
+			return
+		}
+
+		clientKey := c.GetHeader(config.Header)
+		if clientKey == "" {
+			if config.Required {
+				c.JSON(400, H{
+					"error":   "Bad Request",
+					"message": config.Header + " header is required",
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(clientKey) > 255 {
+			c.JSON(400, H{
+				"error":   "Bad Request",
+				"message": config.Header + " header is too long",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := c.BodyBytes()
+		if err != nil {
+			c.AbortWithStatus(400)
+			return
+		}
+		fingerprint := idempotencyFingerprint(c, body)
+		key := config.KeyFunc(c, clientKey)
+		ctx := c.Request.Context()
+
+		store := config.Store
+		if store == nil {
+			if kv := c.kvStore(); kv != nil {
+				store = &kvIdempotencyStore{kv: kv, prefix: "idempotency:"}
+			} else {
+				store = memory
+			}
+		}
+
+		stored, locked, err := store.Begin(ctx, key, config.LockTimeout)
+		if err != nil {
+			// Without the store, processing could charge twice
+			debugPrint("[WARNING] idempotency store failed: %v", err)
+			c.JSON(503, H{
+				"error":   "Service Unavailable",
+				"message": "idempotency store unavailable",
+			})
+			c.Abort()
+			return
+		}
+		if !locked {
+			switch {
+			case stored == nil:
+				c.Header("Retry-After", "1")
+				c.JSON(409, H{
+					"error":   "Conflict",
+					"message": "a request with this " + config.Header + " is already in progress",
+				})
+			case stored.Fingerprint != fingerprint:
+				c.JSON(422, H{
+					"error":   "Unprocessable Entity",
+					"message": config.Header + " was already used for a different request",
+				})
+			default:
+				header := c.Writer.Header()
+				for k, v := range stored.Header {
+					header[k] = v
+				}
+				header.Set("Idempotent-Replayed", "true")
+				c.Writer.WriteHeader(stored.Status)
+				c.Writer.WriteHeaderNow()
+				c.Writer.Write(stored.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			// Handler failed or panicked: let the client retry
+			if !completed {
+				if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+					debugPrint("[WARNING] idempotency release failed: %v", err)
+				}
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		status := writer.Status()
+		if status >= 500 {
+			return
+		}
+		resp := &IdempotentResponse{
+			Status:      status,
+			Header:      writer.Header().Clone(),
+			Body:        writer.body,
+			Fingerprint: fingerprint,
+		}
+		if err := store.Complete(context.WithoutCancel(ctx), key, resp, config.TTL); err != nil {
+			debugPrint("[WARNING] idempotency store failed to save response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+func defaultIdempotencyKey(c *Context, key string) string {
+	scope := ""
+	if userID, ok := c.Get("user_id"); ok && userID != nil {
+		scope = fmt.Sprint(userID)
+	}
+	return scope + ":" + c.Request.Method + ":" + c.Request.URL.Path + ":" + key
+}
+
+// idempotencyFingerprint hashes the parts of a request that must match
+// for a stored response to be replayed
+func idempotencyFingerprint(c *Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyWriter captures the response while writing it through
+type idempotencyWriter struct {
+	ResponseWriter
+	body []byte
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body = append(w.body, data...)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body = append(w.body, s...)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyInFlight marks a reserved key
+const idempotencyInFlight = "in-flight"
+
+// kvIdempotencyStore keeps idempotency keys in a KVStore
+type kvIdempotencyStore struct {
+	kv     KVStore
+	prefix string
+}
+
+// NewKVIdempotencyStore returns an IdempotencyStore keeping keys in kv;
+// prefix defaults to "idempotency:"
+func NewKVIdempotencyStore(kv KVStore, prefix string) IdempotencyStore {
+	if prefix == "" {
+		prefix = "idempotency:"
+	}
+	return &kvIdempotencyStore{kv: kv, prefix: prefix}
+}
+
+// NewMemoryIdempotencyStore returns an in-memory IdempotencyStore for
+// single instance deployments and tests
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return NewKVIdempotencyStore(NewMemoryKVStore(), "")
+}
+
+// NewRedisIdempotencyStore returns an IdempotencyStore shared through
+// Redis; prefix defaults to "idempotency:"
+func NewRedisIdempotencyStore(client *RedisClient, prefix string) IdempotencyStore {
+	return NewKVIdempotencyStore(NewRedisKVStore(client, ""), prefix)
+}
+
+func (s *kvIdempotencyStore) Begin(ctx context.Context, key string, ttl time.Duration) (*IdempotentResponse, bool, error) {
+	key = s.prefix + key
+	// The key may expire between SetNX and Get, so try twice
+	for i := 0; i < 2; i++ {
+		locked, err := s.kv.SetNX(ctx, key, []byte(idempotencyInFlight), ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		if locked {
+			return nil, true, nil
+		}
+
+		data, err := s.kv.Get(ctx, key)
+		if errors.Is(err, ErrKVNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if string(data) == idempotencyInFlight {
+			return nil, false, nil
+		}
+		var resp IdempotentResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, false, err
+		}
+		return &resp, false, nil
+	}
+	return nil, false, nil
+}
+
+func (s *kvIdempotencyStore) Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, s.prefix+key, data, ttl)
+}
+
+func (s *kvIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.kv.Delete(ctx, s.prefix+key)
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func idempotentRequest(r *Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/payments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	for name, store := range map[string]func(t *testing.T) IdempotencyStore{
+		"memory": func(t *testing.T) IdempotencyStore { return NewMemoryIdempotencyStore() },
+		"redis": func(t *testing.T) IdempotencyStore {
+			client, mr := setupMiniRedis(t)
+			t.Cleanup(mr.Close)
+			return NewRedisIdempotencyStore(client, "")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var charges atomic.Int32
+			r := New()
+			r.POST("/payments", Idempotency(store(t), time.Hour), func(c *Context) {
+				n := charges.Add(1)
+				c.Header("X-Charge", "ch_1")
+				c.JSON(201, H{"charge": n})
+			})
+
+			first := idempotentRequest(r, "k1", `{"amount":100}`)
+			second := idempotentRequest(r, "k1", `{"amount":100}`)
+			if charges.Load() != 1 {
+				t.Fatalf("Expected one charge, got %d", charges.Load())
+			}
+			if second.Code != 201 || second.Body.String() != first.Body.String() ||
+				second.Header().Get("X-Charge") != "ch_1" || second.Header().Get("Idempotent-Replayed") != "true" {
+				t.Errorf("Unexpected replay %d %s %v", second.Code, second.Body.String(), second.Header())
+			}
+
+			if w := idempotentRequest(r, "k1", `{"amount":999}`); w.Code != 422 {
+				t.Errorf("Expected 422 for reused key, got %d", w.Code)
+			}
+			idempotentRequest(r, "k2", `{"amount":100}`)
+			idempotentRequest(r, "", `{"amount":100}`)
+			if charges.Load() != 3 {
+				t.Errorf("Expected new and missing keys to be processed, got %d charges", charges.Load())
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlightAndFailures(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls atomic.Int32
+	r := New()
+	r.Use(Recovery())
+	r.POST("/payments", IdempotencyWithConfig(IdempotencyConfig{
+		Store:    NewMemoryIdempotencyStore(),
+		Required: true,
+	}), func(c *Context) {
+		switch c.GetHeader("Idempotency-Key") {
+		case "slow":
+			started <- struct{}{}
+			<-release
+		case "flaky":
+			if calls.Add(1) == 1 {
+				c.Status(502)
+				return
+			}
+		case "panic":
+			if calls.Add(1) == 3 {
+				panic("gateway down")
+			}
+		}
+		c.Status(200)
+	})
+
+	done := make(chan int)
+	go func() { done <- idempotentRequest(r, "slow", "").Code }()
+	<-started
+	if w := idempotentRequest(r, "slow", ""); w.Code != 409 || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 for in-flight duplicate, got %d", w.Code)
+	}
+	close(release)
+	if code := <-done; code != 200 {
+		t.Errorf("Expected first request to succeed, got %d", code)
+	}
+
+	// 5xx responses and panics are not stored, so retries run again
+	if w := idempotentRequest(r, "flaky", ""); w.Code != 502 {
+		t.Errorf("Expected 502, got %d", w.Code)
+	}
+	if w := idempotentRequest(r, "flaky", ""); w.Code != 200 || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected retry after 5xx to be processed, got %d", w.Code)
+	}
+	if w := idempotentRequest(r, "panic", ""); w.Code != 500 {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	if w := idempotentRequest(r, "panic", ""); w.Code != 200 {
+		t.Errorf("Expected retry after panic to be processed, got %d", w.Code)
+	}
+
+	if w := idempotentRequest(r, "", ""); w.Code != 400 {
+		t.Errorf("Expected 400 when key is required, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyScopesNonStringUserIDs(t *testing.T) {
+	var charges atomic.Int32
+	r := New()
+	r.Use(func(c *Context) {
+		// Auth middleware storing a numeric user ID
+		userID, _ := strconv.Atoi(c.GetHeader("X-User"))
+		c.Set("user_id", userID)
+	})
+	r.POST("/payments", Idempotency(NewMemoryIdempotencyStore(), time.Hour), func(c *Context) {
+		c.JSON(201, H{"charge": charges.Add(1)})
+	})
+
+	for _, user := range []string{"1", "2", "1"} {
+		req := httptest.NewRequest("POST", "/payments", strings.NewReader(`{"amount":100}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		req.Header.Set("X-User", user)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if charges.Load() != 2 {
+		t.Errorf("Expected one charge per user, got %d", charges.Load())
+	}
+}
@@ -62,6 +62,10 @@ type JWTConfig struct {
 
 	// SuccessHandler defines a function which is executed after successful token validation.
 	SuccessHandler func(*Context, *JWTClaims)
+
+	// Cache caches verified tokens to skip verification on repeat requests.
+	// Optional. Default value nil (every token is verified).
+	Cache *JWTCache
 }
 
 // JWTAuth returns a JWT authentication middleware
@@ -147,11 +151,24 @@ func JWTAuthWithConfig(config JWTConfig) HandlerFunc {
 			token = cookie.Value
 		}
 
-		// Parse and validate token
-		claims, err := parseJWT(token, config.Secret, config.TimeFunc)
-		if err != nil {
-			config.ErrorHandler(c, err)
-			return
+		// Parse and validate token, unless it was verified recently
+		var claims *JWTClaims
+		var cacheKey [sha256.Size]byte
+		cached := false
+		if config.Cache != nil {
+			cacheKey = jwtCacheKey(config.Secret, token)
+			claims, cached = config.Cache.get(cacheKey, config.TimeFunc())
+		}
+		if !cached {
+			var err error
+			claims, err = parseJWT(token, config.Secret, config.TimeFunc)
+			if err != nil {
+				config.ErrorHandler(c, err)
+				return
+			}
+			if config.Cache != nil {
+				config.Cache.put(cacheKey, claims, config.TimeFunc())
+			}
 		}
 
 		// Store claims in context
@@ -243,12 +260,7 @@ func createSignature(message, secret string) string {
 
 // GetJWTClaims retrieves JWT claims from context
 func GetJWTClaims(c *Context) (*JWTClaims, bool) {
-	claims, exists := c.Get("jwt_claims")
-	if !exists {
-		return nil, false
-	}
-	jwtClaims, ok := claims.(*JWTClaims)
-	return jwtClaims, ok
+	return Get[*JWTClaims](c, "jwt_claims")
 }
 
 // RefreshToken generates a new token with extended expiration
@@ -0,0 +1,191 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JWTCacheConfig holds configuration for a JWTCache
+type JWTCacheConfig struct {
+	// MaxEntries bounds the number of cached tokens
+	// Default: 10000
+	MaxEntries int
+
+	// TTL is how long a verified token is cached; entries never outlive
+	// the token's exp claim
+	// Default: 5 minutes
+	TTL time.Duration
+}
+
+// JWTCacheStats reports JWTCache effectiveness
+type JWTCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+}
+
+// JWTCache caches verified JWT claims so repeat requests with the same
+// token skip signature verification and claim parsing. Only the token hash
+// is stored. Set it as JWTConfig.Cache:
+//
+//	cache := goTap.NewJWTCache(goTap.JWTCacheConfig{})
+//	r.Use(goTap.JWTAuthWithConfig(goTap.JWTConfig{Secret: secret, Cache: cache}))
+type JWTCache struct {
+	config JWTCacheConfig
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]jwtCacheEntry
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type jwtCacheEntry struct {
+	claims  JWTClaims
+	expires time.Time
+}
+
+// NewJWTCache creates a JWT verification cache
+func NewJWTCache(config JWTCacheConfig) *JWTCache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.TTL <= 0 {
+		config.TTL = 5 * time.Minute
+	}
+	return &JWTCache{
+		config:  config,
+		entries: make(map[[sha256.Size]byte]jwtCacheEntry),
+	}
+}
+
+// jwtCacheKey hashes the token together with the secret, so middlewares
+// with different secrets never share entries
+func jwtCacheKey(secret, token string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(secret))
+	h.Write([]byte{0})
+	h.Write([]byte(token))
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// get returns a copy of the cached claims for a token
+func (jc *JWTCache) get(key [sha256.Size]byte, now time.Time) (*JWTClaims, bool) {
+	jc.mu.Lock()
+	entry, ok := jc.entries[key]
+	if ok && !now.Before(entry.expires) {
+		delete(jc.entries, key)
+		ok = false
+	}
+	jc.mu.Unlock()
+
+	if !ok {
+		jc.misses.Add(1)
+		return nil, false
+	}
+	jc.hits.Add(1)
+	claims := cloneClaims(&entry.claims)
+	return &claims, true
+}
+
+// cloneClaims deep-copies claims, so handlers changing the Custom claims
+// of one request don't affect other requests with the same token
+func cloneClaims(claims *JWTClaims) JWTClaims {
+	clone := *claims
+	if claims.Custom != nil {
+		clone.Custom = cloneJSONValue(claims.Custom).(map[string]interface{})
+	}
+	return clone
+}
+
+// cloneJSONValue deep-copies the maps and slices of a decoded JSON value
+func cloneJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			clone[key] = cloneJSONValue(value)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, value := range v {
+			clone[i] = cloneJSONValue(value)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+// put caches verified claims until the TTL or the token's exp, whichever
+// comes first
+func (jc *JWTCache) put(key [sha256.Size]byte, claims *JWTClaims, now time.Time) {
+	expires := now.Add(jc.config.TTL)
+	if claims.ExpiresAt > 0 {
+		if exp := time.Unix(claims.ExpiresAt, 0); exp.Before(expires) {
+			expires = exp
+		}
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+	if _, ok := jc.entries[key]; !ok && len(jc.entries) >= jc.config.MaxEntries {
+		jc.evict(now)
+	}
+	jc.entries[key] = jwtCacheEntry{claims: cloneClaims(claims), expires: expires}
+}
+
+// evict drops expired entries, or an arbitrary one when none has expired.
+// Callers hold jc.mu.
+func (jc *JWTCache) evict(now time.Time) {
+	evicted := uint64(0)
+	for key, entry := range jc.entries {
+		if !now.Before(entry.expires) {
+			delete(jc.entries, key)
+			evicted++
+		}
+	}
+	if evicted == 0 {
+		for key := range jc.entries {
+			delete(jc.entries, key)
+			evicted++
+			break
+		}
+	}
+	jc.evictions.Add(evicted)
+}
+
+// Purge removes all cached tokens, e.g. after revoking tokens or rotating
+// the secret
+func (jc *JWTCache) Purge() {
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+	jc.entries = make(map[[sha256.Size]byte]jwtCacheEntry)
+}
+
+// Stats returns cache hit, miss and eviction counts
+func (jc *JWTCache) Stats() JWTCacheStats {
+	jc.mu.Lock()
+	entries := len(jc.entries)
+	jc.mu.Unlock()
+	return JWTCacheStats{
+		Hits:      jc.hits.Load(),
+		Misses:    jc.misses.Load(),
+		Evictions: jc.evictions.Load(),
+		Entries:   entries,
+	}
+}
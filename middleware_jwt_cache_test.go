@@ -0,0 +1,128 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token, _ := GenerateJWT("secret", JWTClaims{UserID: "u1", ExpiresAt: now.Add(time.Minute).Unix()})
+	cache := NewJWTCache(JWTCacheConfig{TTL: time.Hour})
+
+	r := New()
+	r.Use(JWTAuthWithConfig(JWTConfig{
+		Secret:   "secret",
+		Cache:    cache,
+		TimeFunc: func() time.Time { return now },
+	}))
+	r.GET("/me", func(c *Context) {
+		claims, _ := GetJWTClaims(c)
+		claims.Role = "mutated" // must not leak into the cache
+		c.String(200, claims.UserID)
+	})
+
+	request := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		if w := request(token); w.Code != 200 || w.Body.String() != "u1" {
+			t.Fatalf("Expected 200 u1, got %d %s", w.Code, w.Body.String())
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if claims, _ := cache.get(jwtCacheKey("secret", token), now); claims.Role != "" {
+		t.Errorf("Expected cached claims to be copied, got role %q", claims.Role)
+	}
+
+	// The TTL is bounded by exp
+	now = now.Add(2 * time.Minute)
+	if w := request(token); w.Code != 401 {
+		t.Errorf("Expected expired token to be rejected, got %d", w.Code)
+	}
+
+	// Tokens signed with another secret are never served from the cache
+	forged, _ := GenerateJWT("other", JWTClaims{UserID: "u2"})
+	if w := request(forged); w.Code != 401 {
+		t.Errorf("Expected forged token to be rejected, got %d", w.Code)
+	}
+}
+
+func TestJWTCacheCopiesCustomClaims(t *testing.T) {
+	token, _ := GenerateJWT("secret", JWTClaims{
+		UserID:    "u1",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Custom:    map[string]interface{}{"store": "s1", "scopes": []interface{}{"read"}},
+	})
+	r := New()
+	r.Use(JWTAuthWithConfig(JWTConfig{Secret: "secret", Cache: NewJWTCache(JWTCacheConfig{})}))
+	r.GET("/me", func(c *Context) {
+		claims, _ := GetJWTClaims(c)
+		c.JSON(200, claims.Custom)
+		// Handlers changing a cache hit's claims must not affect other requests
+		claims.Custom["store"] = "mutated"
+		claims.Custom["scopes"].([]interface{})[0] = "admin"
+	})
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		if body := strings.TrimSpace(w.Body.String()); body != `{"scopes":["read"],"store":"s1"}` {
+			t.Fatalf("Request %d got claims %s", i+1, body)
+		}
+	}
+}
+
+func TestJWTCacheEviction(t *testing.T) {
+	now := time.Now()
+	cache := NewJWTCache(JWTCacheConfig{MaxEntries: 2, TTL: time.Minute})
+	for _, token := range []string{"a", "b", "c"} {
+		cache.put(jwtCacheKey("s", token), &JWTClaims{UserID: token}, now)
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	cache.Purge()
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected empty cache after purge, got %+v", stats)
+	}
+}
+
+func BenchmarkJWTAuthCache(b *testing.B) {
+	token, _ := GenerateJWT("secret", JWTClaims{UserID: "u1", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	for _, bc := range []struct {
+		name  string
+		cache *JWTCache
+	}{{"NoCache", nil}, {"Cache", NewJWTCache(JWTCacheConfig{})}} {
+		b.Run(bc.name, func(b *testing.B) {
+			r := New()
+			r.Use(JWTAuthWithConfig(JWTConfig{Secret: "secret", Cache: bc.cache}))
+			r.GET("/me", func(c *Context) {})
+			req, _ := http.NewRequest("GET", "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.ServeHTTP(w, req)
+			}
+		})
+	}
+}
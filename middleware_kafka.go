@@ -0,0 +1,79 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"strconv"
+)
+
+// KafkaClient is the part of a Kafka client goTap uses. goTap does not
+// depend on a Kafka library; wrap the client of your choice, e.g. a kafka-go
+// Writer for Produce and a Reader per topic and group for Consumer.
+type KafkaClient interface {
+	KafkaProducer
+
+	// Consumer returns a consumer reading topic as part of a consumer group
+	Consumer(topic, group string) (MessageConsumer, error)
+}
+
+// KafkaInject injects a Kafka client into context for use in handlers
+func KafkaInject(client KafkaClient) HandlerFunc {
+	return Inject("kafka", client)
+}
+
+// GetKafka retrieves the Kafka client from context
+func GetKafka(c *Context) (KafkaClient, bool) {
+	return Get[KafkaClient](c, "kafka")
+}
+
+// MustGetKafka retrieves the Kafka client from context or panics
+func MustGetKafka(c *Context) KafkaClient {
+	client, ok := GetKafka(c)
+	if !ok {
+		panic("Kafka client not found in context. Did you forget to use KafkaInject()?")
+	}
+	return client
+}
+
+// ConsumeKafka starts a consumer runner for topic in the given consumer
+// group, stopped with the server. config.Consumer is set from client.
+//
+//	r.ConsumeKafka(kafka, "orders", "billing", goTap.ConsumerConfig{
+//	    Handler:    handleOrder,
+//	    DeadLetter: goTap.KafkaDeadLetter(kafka, "orders.dlq"),
+//	})
+func (engine *Engine) ConsumeKafka(client KafkaClient, topic, group string, config ConsumerConfig) (*ConsumerRunner, error) {
+	consumer, err := client.Consumer(topic, group)
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer = consumer
+	if config.Name == "" {
+		config.Name = "kafka " + topic + "/" + group
+	}
+	return engine.Consume(config), nil
+}
+
+// KafkaDeadLetter returns a ConsumerConfig.DeadLetter producing failed
+// messages to topic. The original topic, the error and the number of
+// attempts are added as dlq-topic, dlq-error and dlq-attempts headers.
+func KafkaDeadLetter(producer KafkaProducer, topic string) func(ctx context.Context, msg *Message, err error) error {
+	return func(ctx context.Context, msg *Message, err error) error {
+		return producer.Produce(ctx, topic, msg.Key, msg.Value, deadLetterHeaders(msg, err))
+	}
+}
+
+// deadLetterHeaders copies msg headers and adds the failure details
+func deadLetterHeaders(msg *Message, err error) map[string]string {
+	headers := make(map[string]string, len(msg.Headers)+3)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers["dlq-topic"] = msg.Topic
+	headers["dlq-error"] = err.Error()
+	headers["dlq-attempts"] = strconv.Itoa(msg.Attempt)
+	return headers
+}
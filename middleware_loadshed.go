@@ -0,0 +1,258 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadShedConfig holds configuration for a LoadShedder. A signal above its
+// threshold means overload; the pressure is the highest ratio of a signal
+// to its threshold.
+type LoadShedConfig struct {
+	// MaxLatency is the p99 latency of the served requests above which the
+	// server is overloaded
+	// Default: 1s
+	MaxLatency time.Duration
+
+	// MaxGoroutines is the goroutine count above which the server is
+	// overloaded
+	// Default: 0 (not monitored)
+	MaxGoroutines int
+
+	// MaxCPU is the share of the CPU time available to GOMAXPROCS, between
+	// 0 and 1, above which the server is overloaded. It is measured with
+	// the runtime's estimates, see runtime/metrics.
+	// Default: 0 (not monitored)
+	MaxCPU float64
+
+	// Window is the period the latency percentile is computed over
+	// Default: 10s
+	Window time.Duration
+
+	// Interval is how often the signals are sampled
+	// Default: 1s
+	Interval time.Duration
+
+	// RetryAfter is sent in the Retry-After header of shed requests
+	// Default: 1s
+	RetryAfter time.Duration
+
+	// OnShed is called for each shed request, e.g. for metrics
+	// Optional.
+	OnShed func(c *Context, pressure float64)
+}
+
+// LoadShedStats reports the state of a LoadShedder
+type LoadShedStats struct {
+	Pressure   float64          `json:"pressure"`
+	P99        time.Duration    `json:"p99"`
+	Goroutines int              `json:"goroutines"`
+	CPU        float64          `json:"cpu"`
+	Shed       map[string]int64 `json:"shed"`
+}
+
+// LoadShedder drops part of the low-priority traffic while the server is
+// overloaded, so critical routes such as payments stay responsive
+type LoadShedder struct {
+	config LoadShedConfig
+
+	mu        sync.Mutex
+	samples   []latencySample
+	next      int
+	sampledAt time.Time
+	stats     LoadShedStats
+	cpuTotal  float64
+	cpuIdle   float64
+
+	pressure                   atomic.Uint64 // math.Float64bits
+	shedBackground, shedNormal atomic.Int64
+	readCPU                    func() (total, idle float64)
+	countGoroutines            func() int
+}
+
+type latencySample struct {
+	at time.Time
+	d  time.Duration
+}
+
+// latencySamples bounds the memory of the latency window
+const latencySamples = 1024
+
+// NewLoadShedder returns a load shedder, whose Stats can be exposed for
+// monitoring
+func NewLoadShedder(config LoadShedConfig) *LoadShedder {
+	if config.MaxLatency <= 0 {
+		config.MaxLatency = time.Second
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	return &LoadShedder{
+		config:          config,
+		samples:         make([]latencySample, 0, latencySamples),
+		readCPU:         readRuntimeCPU,
+		countGoroutines: runtime.NumGoroutine,
+	}
+}
+
+// LoadShed returns a middleware shedding load with config:
+//
+//	r.Use(goTap.LoadShed(goTap.LoadShedConfig{MaxLatency: 300 * time.Millisecond, MaxCPU: 0.9}))
+//	r.Group("/pos").Priority(goTap.PriorityCritical).POST("/transaction", pay)
+//	r.Group("/reports").Priority(goTap.PriorityBackground).GET("/sales", salesReport)
+//
+// Under a pressure p above 1, background requests are shed with
+// probability 2(p-1), all of them from 1.5 times the thresholds, and normal
+// requests with probability p-1.5, all of them from 2.5 times the
+// thresholds. Critical requests are never shed. Shed requests get 503 with
+// a Retry-After header.
+func LoadShed(config LoadShedConfig) HandlerFunc {
+	return NewLoadShedder(config).Middleware()
+}
+
+// Middleware returns the middleware of the load shedder
+func (s *LoadShedder) Middleware() HandlerFunc {
+	retryAfter := strconv.Itoa(max(1, int((s.config.RetryAfter+time.Second-1)/time.Second)))
+	return func(c *Context) {
+		now := time.Now()
+		s.sample(now)
+		priority := c.Priority()
+		if pressure := s.Pressure(); s.shed(priority, pressure) {
+			if priority < PriorityNormal {
+				s.shedBackground.Add(1)
+			} else {
+				s.shedNormal.Add(1)
+			}
+			if s.config.OnShed != nil {
+				s.config.OnShed(c, pressure)
+			}
+			c.Header("Retry-After", retryAfter)
+			c.JSON(http.StatusServiceUnavailable, H{
+				"error":   "Service Unavailable",
+				"message": "server overloaded, retry later",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+		s.observe(now, time.Since(now))
+	}
+}
+
+// shed decides whether to drop a request of priority
+func (s *LoadShedder) shed(priority Priority, pressure float64) bool {
+	var p float64
+	switch {
+	case priority > PriorityNormal || pressure <= 1:
+		return false
+	case priority < PriorityNormal:
+		p = 2 * (pressure - 1)
+	default:
+		p = pressure - 1.5
+	}
+	return p >= 1 || (p > 0 && rand.Float64() < p)
+}
+
+// observe records the latency of a served request
+func (s *LoadShedder) observe(at time.Time, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample := latencySample{at: at, d: d}
+	if len(s.samples) < latencySamples {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % latencySamples
+}
+
+// sample updates the signals once per Interval
+func (s *LoadShedder) sample(now time.Time) {
+	if !s.mu.TryLock() {
+		// Another request is sampling
+		return
+	}
+	defer s.mu.Unlock()
+	if now.Sub(s.sampledAt) < s.config.Interval {
+		return
+	}
+	s.sampledAt = now
+
+	var recent []time.Duration
+	for _, sample := range s.samples {
+		if now.Sub(sample.at) <= s.config.Window {
+			recent = append(recent, sample.d)
+		}
+	}
+	s.stats.P99 = 0
+	if len(recent) > 0 {
+		slices.Sort(recent)
+		s.stats.P99 = recent[(len(recent)*99-1)/100]
+	}
+	pressure := float64(s.stats.P99) / float64(s.config.MaxLatency)
+
+	if s.config.MaxGoroutines > 0 {
+		s.stats.Goroutines = s.countGoroutines()
+		pressure = max(pressure, float64(s.stats.Goroutines)/float64(s.config.MaxGoroutines))
+	}
+	if s.config.MaxCPU > 0 {
+		total, idle := s.readCPU()
+		if total > s.cpuTotal {
+			s.stats.CPU = 1 - (idle-s.cpuIdle)/(total-s.cpuTotal)
+		}
+		s.cpuTotal, s.cpuIdle = total, idle
+		pressure = max(pressure, s.stats.CPU/s.config.MaxCPU)
+	}
+	s.stats.Pressure = pressure
+	s.pressure.Store(math.Float64bits(pressure))
+}
+
+// Pressure returns the last sampled pressure; above 1 the server is
+// overloaded
+func (s *LoadShedder) Pressure() float64 {
+	return math.Float64frombits(s.pressure.Load())
+}
+
+// Stats reports the sampled signals and the shed requests per priority
+func (s *LoadShedder) Stats() LoadShedStats {
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+	stats.Shed = map[string]int64{
+		PriorityBackground.String(): s.shedBackground.Load(),
+		PriorityNormal.String():     s.shedNormal.Load(),
+	}
+	return stats
+}
+
+// readRuntimeCPU returns the CPU time available to the process and the
+// part of it left idle, as estimated by the runtime
+func readRuntimeCPU() (total, idle float64) {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindFloat64 || samples[1].Value.Kind() != metrics.KindFloat64 {
+		return 0, 0
+	}
+	return samples[0].Value.Float64(), samples[1].Value.Float64()
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadShed(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{
+		MaxLatency:    100 * time.Millisecond,
+		MaxGoroutines: 100,
+		MaxCPU:        0.25,
+		Interval:      time.Nanosecond,
+	})
+	goroutines := 10
+	shedder.countGoroutines = func() int { return goroutines }
+	cpuTotal, cpuIdle := 0.0, 0.0
+	shedder.readCPU = func() (float64, float64) { return cpuTotal, cpuIdle }
+
+	r := New()
+	r.Use(shedder.Middleware())
+	ok := func(c *Context) { c.Status(http.StatusOK) }
+	r.Group("/pos").Priority(PriorityCritical).POST("/transaction", ok)
+	r.GET("/products", ok)
+	r.Group("/reports").Priority(PriorityBackground).GET("/sales", ok)
+
+	do := func(method, path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+	expect := func(state string, pos, products, reports int) {
+		t.Helper()
+		if got := do("POST", "/pos/transaction"); got != pos {
+			t.Errorf("%s: expected %d for critical, got %d", state, pos, got)
+		}
+		if got := do("GET", "/products"); got != products {
+			t.Errorf("%s: expected %d for normal, got %d", state, products, got)
+		}
+		if got := do("GET", "/reports/sales"); got != reports {
+			t.Errorf("%s: expected %d for background, got %d", state, reports, got)
+		}
+	}
+
+	expect("idle", 200, 200, 200)
+
+	// 1.5x the goroutine threshold sheds all background traffic
+	goroutines = 150
+	expect("goroutines", 200, 200, 503)
+
+	// 3.4x the CPU threshold sheds normal traffic too
+	goroutines = 10
+	cpuTotal, cpuIdle = 10, 10
+	do("GET", "/products")
+	cpuTotal, cpuIdle = 20, 11.5
+	expect("cpu", 200, 503, 503)
+	if stats := shedder.Stats(); stats.CPU != 0.85 || stats.Shed["background"] != 2 || stats.Shed["normal"] != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A slow p99 is overload too
+	cpuTotal, cpuIdle = 30, 21.5
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		shedder.observe(now, 300*time.Millisecond)
+	}
+	expect("latency", 200, 503, 503)
+	if stats := shedder.Stats(); stats.P99 != 300*time.Millisecond {
+		t.Errorf("Expected a p99 of 300ms, got %v", stats.P99)
+	}
+
+	if PriorityBackground.String() != "background" || Priority(5).String() != "critical" {
+		t.Error("Unexpected priority names")
+	}
+}
@@ -51,20 +51,12 @@ func (m *MongoClient) Collection(name string) *mongo.Collection {
 
 // MongoInject injects MongoDB client into context for use in handlers
 func MongoInject(client *MongoClient) HandlerFunc {
-	return func(c *Context) {
-		c.Set("mongodb", client)
-		c.Next()
-	}
+	return Inject("mongodb", client)
 }
 
 // GetMongo retrieves MongoDB client from context
 func GetMongo(c *Context) (*MongoClient, bool) {
-	client, exists := c.Get("mongodb")
-	if !exists {
-		return nil, false
-	}
-	mongoClient, ok := client.(*MongoClient)
-	return mongoClient, ok
+	return Get[*MongoClient](c, "mongodb")
 }
 
 // MustGetMongo retrieves MongoDB client from context or panics
@@ -76,6 +68,21 @@ func MustGetMongo(c *Context) *MongoClient {
 	return client
 }
 
+// MongoStatusProbe returns a StatusProbe reading the MongoDB server version
+func MongoStatusProbe(name string, client *MongoClient) StatusProbe {
+	return StatusProbe{
+		Name: name,
+		Kind: "mongodb",
+		Version: func(ctx context.Context) (string, error) {
+			var info struct {
+				Version string `bson:"version"`
+			}
+			err := client.Database.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info)
+			return info.Version, err
+		},
+	}
+}
+
 // MongoHealthCheck returns middleware that checks MongoDB health
 func MongoHealthCheck(client *MongoClient) HandlerFunc {
 	return func(c *Context) {
@@ -173,6 +180,10 @@ func MongoTransaction(client *MongoClient) HandlerFunc {
 // MongoRepository provides common database operations
 type MongoRepository struct {
 	collection *mongo.Collection
+
+	// Model events, see PublishEvents
+	events *EventBus
+	model  string
 }
 
 // NewMongoRepository creates a new repository for a collection
@@ -199,32 +210,58 @@ func (r *MongoRepository) FindByID(ctx context.Context, id interface{}) *mongo.S
 
 // InsertOne inserts a single document
 func (r *MongoRepository) InsertOne(ctx context.Context, document interface{}) (*mongo.InsertOneResult, error) {
-	return r.collection.InsertOne(ctx, document)
+	result, err := r.collection.InsertOne(ctx, document)
+	if err == nil {
+		r.publish(ctx, ModelCreated, result.InsertedID, document, nil)
+	}
+	return result, err
 }
 
 // InsertMany inserts multiple documents
 func (r *MongoRepository) InsertMany(ctx context.Context, documents []interface{}) (*mongo.InsertManyResult, error) {
-	return r.collection.InsertMany(ctx, documents)
+	result, err := r.collection.InsertMany(ctx, documents)
+	if err == nil {
+		for i, id := range result.InsertedIDs {
+			r.publish(ctx, ModelCreated, id, documents[i], nil)
+		}
+	}
+	return result, err
 }
 
 // UpdateOne updates a single document
 func (r *MongoRepository) UpdateOne(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
-	return r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err == nil && result.MatchedCount > 0 {
+		r.publish(ctx, ModelUpdated, result.UpsertedID, filter, update)
+	}
+	return result, err
 }
 
 // UpdateByID updates a document by ID
 func (r *MongoRepository) UpdateByID(ctx context.Context, id interface{}, update interface{}) (*mongo.UpdateResult, error) {
-	return r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err == nil && result.MatchedCount > 0 {
+		r.publish(ctx, ModelUpdated, id, nil, update)
+	}
+	return result, err
 }
 
 // DeleteOne deletes a single document
 func (r *MongoRepository) DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
-	return r.collection.DeleteOne(ctx, filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err == nil && result.DeletedCount > 0 {
+		r.publish(ctx, ModelDeleted, nil, filter, nil)
+	}
+	return result, err
 }
 
 // DeleteByID deletes a document by ID
 func (r *MongoRepository) DeleteByID(ctx context.Context, id interface{}) (*mongo.DeleteResult, error) {
-	return r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err == nil && result.DeletedCount > 0 {
+		r.publish(ctx, ModelDeleted, id, nil, nil)
+	}
+	return result, err
 }
 
 // CountDocuments counts documents matching filter
@@ -309,20 +346,48 @@ func (mc *MongoCache) Clear(ctx context.Context) error {
 	return err
 }
 
-// MongoAuditLog middleware logs all requests to MongoDB
+// MongoAuditLog middleware logs all requests to MongoDB. Entries are
+// written in batches by a bounded worker pool; call Close on shutdown to
+// flush them. Bodies are stored as received; AuditLog with a
+// NewMongoAuditStore redacts sensitive fields.
 type MongoAuditLog struct {
 	collection  *mongo.Collection
 	includeBody bool
+	writer      *BatchWriter[any]
 }
 
 // NewMongoAuditLog creates a new audit log middleware
 func NewMongoAuditLog(client *MongoClient, collectionName string, includeBody bool) *MongoAuditLog {
+	return NewMongoAuditLogWithConfig(client, collectionName, includeBody, BatchWriterConfig{})
+}
+
+// NewMongoAuditLogWithConfig creates an audit log middleware with a custom
+// worker pool configuration, e.g. OverflowBlock when no entry may be lost
+func NewMongoAuditLogWithConfig(client *MongoClient, collectionName string, includeBody bool, config BatchWriterConfig) *MongoAuditLog {
+	if config.Name == "" {
+		config.Name = "mongo audit log"
+	}
+	collection := client.Collection(collectionName)
 	return &MongoAuditLog{
-		collection:  client.Collection(collectionName),
+		collection:  collection,
 		includeBody: includeBody,
+		writer: NewBatchWriter(func(ctx context.Context, batch []any) error {
+			_, err := collection.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+			return err
+		}, config),
 	}
 }
 
+// Close flushes queued entries and stops the workers
+func (mal *MongoAuditLog) Close(ctx context.Context) error {
+	return mal.writer.Close(ctx)
+}
+
+// Stats returns the number of written, failed, dropped and queued entries
+func (mal *MongoAuditLog) Stats() BatchWriterStats {
+	return mal.writer.Stats()
+}
+
 // Middleware returns the audit log middleware
 func (mal *MongoAuditLog) Middleware() HandlerFunc {
 	return func(c *Context) {
@@ -357,14 +422,30 @@ func (mal *MongoAuditLog) Middleware() HandlerFunc {
 		}
 
 		// Store in MongoDB asynchronously
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			mal.collection.InsertOne(ctx, logEntry)
-		}()
+		mal.writer.Add(logEntry)
 	}
 }
 
+// mongoAuditStore writes audit entries to a collection
+type mongoAuditStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAuditStore returns an AuditStore writing to a collection
+func NewMongoAuditStore(client *MongoClient, collectionName string) AuditStore {
+	return &mongoAuditStore{collection: client.Collection(collectionName)}
+}
+
+// WriteAudit implements AuditStore
+func (s *mongoAuditStore) WriteAudit(ctx context.Context, entries []AuditEntry) error {
+	docs := make([]any, len(entries))
+	for i := range entries {
+		docs[i] = entries[i]
+	}
+	_, err := s.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
 // MongoPagination provides pagination helper
 type MongoPagination struct {
 	Page     int64
@@ -375,6 +456,10 @@ type MongoPagination struct {
 
 // NewMongoPagination creates pagination from context query params
 func NewMongoPagination(c *Context) *MongoPagination {
+	if p, ok := GetPagination(c); ok {
+		return &MongoPagination{Page: int64(p.Page), PageSize: int64(p.PerPage)}
+	}
+
 	page := parseInt64(c.DefaultQuery("page", "1"), 1)
 	pageSize := parseInt64(c.DefaultQuery("page_size", "20"), 20)
 
@@ -459,8 +459,10 @@ func TestMongoAuditLog(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 
-	// Give goroutine time to insert
-	time.Sleep(100 * time.Millisecond)
+	// Flush the queued entry
+	if err := auditLog.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify log entry was created
 	collection := mongoClient.Collection("audit_log")
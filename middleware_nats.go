@@ -0,0 +1,76 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"strconv"
+)
+
+// NATSClient is the part of a NATS client goTap uses. goTap does not depend
+// on the NATS library; wrap a nats.Conn (or a JetStream context for
+// acknowledged delivery) to implement it.
+type NATSClient interface {
+	// Publish sends data on subject
+	Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error
+
+	// Subscribe returns a consumer for subject; subscribers sharing a
+	// non-empty queue group split the messages between them
+	Subscribe(subject, queue string) (MessageConsumer, error)
+}
+
+// NATSInject injects a NATS client into context for use in handlers
+func NATSInject(client NATSClient) HandlerFunc {
+	return Inject("nats", client)
+}
+
+// GetNATS retrieves the NATS client from context
+func GetNATS(c *Context) (NATSClient, bool) {
+	return Get[NATSClient](c, "nats")
+}
+
+// MustGetNATS retrieves the NATS client from context or panics
+func MustGetNATS(c *Context) NATSClient {
+	client, ok := GetNATS(c)
+	if !ok {
+		panic("NATS client not found in context. Did you forget to use NATSInject()?")
+	}
+	return client
+}
+
+// ConsumeNATS starts a consumer runner for subject in the given queue
+// group, stopped with the server. config.Consumer is set from client.
+func (engine *Engine) ConsumeNATS(client NATSClient, subject, queue string, config ConsumerConfig) (*ConsumerRunner, error) {
+	consumer, err := client.Subscribe(subject, queue)
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer = consumer
+	if config.Name == "" {
+		config.Name = "nats " + subject
+		if queue != "" {
+			config.Name += "/" + queue
+		}
+	}
+	return engine.Consume(config), nil
+}
+
+// NATSDeadLetter returns a ConsumerConfig.DeadLetter publishing failed
+// messages to subject, with the same dlq-* headers as KafkaDeadLetter
+func NATSDeadLetter(client NATSClient, subject string) func(ctx context.Context, msg *Message, err error) error {
+	return func(ctx context.Context, msg *Message, err error) error {
+		return client.Publish(ctx, subject, msg.Value, deadLetterHeaders(msg, err))
+	}
+}
+
+// NewNATSOutboxPublisher publishes outbox messages on the subject named
+// after the topic
+func NewNATSOutboxPublisher(client NATSClient) OutboxPublisher {
+	return OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		return client.Publish(ctx, msg.Topic, msg.Payload, map[string]string{
+			"outbox-id": strconv.FormatUint(msg.ID, 10),
+		})
+	})
+}
@@ -5,7 +5,10 @@
 package goTap
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"sync"
 	"time"
 )
@@ -29,9 +32,16 @@ type RateLimiterConfig struct {
 	// Useful for whitelisting certain IPs or paths
 	SkipFunc func(*Context) bool
 
-	// Store is the storage backend for rate limit data
-	// Default: in-memory store
+	// Store is the storage backend for rate limit data, see
+	// NewKVRateLimiterStore
+	// Default: Engine.KVStore if set, else an in-memory store
 	Store RateLimiterStore
+
+	// Prefix namespaces the counters of this limiter in Engine.KVStore, so
+	// stacked limiters don't share counts while instances running the same
+	// code do. Set it when two limiters have the same Max, Window and KeyFunc.
+	// Default: "ratelimit:" followed by a hash of Max, Window and KeyFunc
+	Prefix string
 }
 
 // RateLimiterStore defines the interface for rate limiter storage
@@ -44,6 +54,41 @@ type RateLimiterStore interface {
 	Reset(key string) error
 }
 
+// RateLimitEntry is the counter of a rate limiter key
+type RateLimitEntry struct {
+	Key       string    `json:"key"`
+	Count     int       `json:"count"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RateLimiterInspector is implemented by stores that can list their
+// counters, e.g. for the Admin dashboard
+type RateLimiterInspector interface {
+	// Entries returns the live counters, highest count first
+	Entries() []RateLimitEntry
+}
+
+// kvRateLimiterStore keeps rate limit counters in a KVStore
+type kvRateLimiterStore struct {
+	kv     KVStore
+	prefix string
+}
+
+// NewKVRateLimiterStore returns a RateLimiterStore keeping counters in kv,
+// so limits are shared between instances
+func NewKVRateLimiterStore(kv KVStore) RateLimiterStore {
+	return &kvRateLimiterStore{kv: kv, prefix: "ratelimit:"}
+}
+
+func (s *kvRateLimiterStore) Increment(key string, window time.Duration) (int, time.Time, error) {
+	count, expiresAt, err := s.kv.Increment(context.Background(), s.prefix+key, window)
+	return int(count), expiresAt, err
+}
+
+func (s *kvRateLimiterStore) Reset(key string) error {
+	return s.kv.Delete(context.Background(), s.prefix+key)
+}
+
 // inMemoryStore is a simple in-memory rate limiter store
 type inMemoryStore struct {
 	mu      sync.RWMutex
@@ -56,6 +101,13 @@ type rateLimitEntry struct {
 	windowSize time.Duration
 }
 
+// NewMemoryRateLimiterStore returns an in-memory RateLimiterStore, the
+// default of a single instance. Set it as RateLimiterConfig.Store to share
+// it between rate limiters or to inspect it with Admin.
+func NewMemoryRateLimiterStore() RateLimiterStore {
+	return newInMemoryStore()
+}
+
 func newInMemoryStore() *inMemoryStore {
 	store := &inMemoryStore{
 		entries: make(map[string]*rateLimitEntry),
@@ -95,6 +147,28 @@ func (s *inMemoryStore) Reset(key string) error {
 	return nil
 }
 
+// Entries implements RateLimiterInspector
+func (s *inMemoryStore) Entries() []RateLimitEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	entries := make([]RateLimitEntry, 0, len(s.entries))
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			continue
+		}
+		entries = append(entries, RateLimitEntry{Key: key, Count: entry.count, ExpiresAt: entry.expiresAt})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
 func (s *inMemoryStore) cleanup() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -149,8 +223,14 @@ func RateLimiterWithConfig(config RateLimiterConfig) HandlerFunc {
 		}
 	}
 
+	var memory RateLimiterStore
 	if config.Store == nil {
-		config.Store = newInMemoryStore()
+		memory = newInMemoryStore()
+		if config.Prefix == "" {
+			h := fnv.New32a()
+			fmt.Fprintf(h, "%d/%s/%s", config.Max, config.Window, nameOfFunction(config.KeyFunc))
+			config.Prefix = fmt.Sprintf("ratelimit:%08x:", h.Sum32())
+		}
 	}
 
 	return func(c *Context) {
@@ -163,8 +243,17 @@ func RateLimiterWithConfig(config RateLimiterConfig) HandlerFunc {
 		// Get key for this request
 		key := config.KeyFunc(c)
 
+		store := config.Store
+		if store == nil {
+			if kv := c.kvStore(); kv != nil {
+				store = &kvRateLimiterStore{kv: kv, prefix: config.Prefix}
+			} else {
+				store = memory
+			}
+		}
+
 		// Increment counter
-		count, expiresAt, err := config.Store.Increment(key, config.Window)
+		count, expiresAt, err := store.Increment(key, config.Window)
 		if err != nil {
 			// On error, allow the request but log it
 			debugPrint("rate limiter error: %v", err)
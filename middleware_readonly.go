@@ -0,0 +1,127 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readOnlyState is the engine-wide read-only switch
+type readOnlyState struct {
+	reason string
+	since  time.Time
+}
+
+// ReadOnlyConfig holds configuration for the ReadOnly middleware
+type ReadOnlyConfig struct {
+	// AllowPaths accept writes even in read-only mode, e.g. login or the
+	// admin endpoint that turns read-only mode off. A trailing "*" matches
+	// any path with that prefix.
+	// Default: none
+	AllowPaths []string
+
+	// Condition reports additional read-only state checked on every request,
+	// e.g. func() bool { return !sdb.IsUsingPrimary() } after a ShadowDB
+	// failover to a replica that must not accept writes
+	Condition func() bool
+
+	// Methods are the request methods rejected in read-only mode
+	// Default: POST, PUT, PATCH, DELETE
+	Methods []string
+
+	// RetryAfter is sent as the Retry-After header when set
+	RetryAfter time.Duration
+}
+
+// ReadOnly returns a middleware that rejects mutating requests with 503
+// while the engine is in read-only mode. Toggle it at runtime with
+// Engine.SetReadOnly:
+//
+//	r.Use(goTap.ReadOnly())
+//	r.SetReadOnly(true, "database maintenance")
+func ReadOnly() HandlerFunc {
+	return ReadOnlyWithConfig(ReadOnlyConfig{})
+}
+
+// ReadOnlyWithConfig returns a ReadOnly middleware with config
+func ReadOnlyWithConfig(config ReadOnlyConfig) HandlerFunc {
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	methods := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[strings.ToUpper(method)] = true
+	}
+
+	return func(c *Context) {
+		if !methods[c.Request.Method] || readOnlyPathAllowed(config.AllowPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		reason := ""
+		var state *readOnlyState
+		if c.engine != nil {
+			state = c.engine.readOnly.Load()
+		}
+		switch {
+		case state != nil:
+			reason = state.reason
+		case config.Condition != nil && config.Condition():
+		default:
+			c.Next()
+			return
+		}
+
+		if config.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(config.RetryAfter.Seconds())))
+		}
+		message := "Service is in read-only mode"
+		if reason != "" {
+			message += ": " + reason
+		}
+		c.JSON(http.StatusServiceUnavailable, H{
+			"error":   "Service Unavailable",
+			"message": message,
+		})
+		c.Abort()
+	}
+}
+
+func readOnlyPathAllowed(allowed []string, path string) bool {
+	for _, p := range allowed {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReadOnly turns read-only mode on or off for the ReadOnly middleware.
+// reason is included in rejected responses.
+func (engine *Engine) SetReadOnly(enabled bool, reason string) {
+	if !enabled {
+		engine.readOnly.Store(nil)
+		return
+	}
+	engine.readOnly.Store(&readOnlyState{reason: reason, since: time.Now()})
+	debugPrint("[WARNING] read-only mode enabled: %s", reason)
+}
+
+// IsReadOnly reports whether read-only mode is on and since when
+func (engine *Engine) IsReadOnly() (enabled bool, reason string, since time.Time) {
+	state := engine.readOnly.Load()
+	if state == nil {
+		return false, "", time.Time{}
+	}
+	return true, state.reason, state.since
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadOnlyToggle(t *testing.T) {
+	r := New()
+	r.Use(ReadOnlyWithConfig(ReadOnlyConfig{
+		AllowPaths: []string{"/login", "/admin/*"},
+		RetryAfter: 30 * time.Second,
+	}))
+	ok := func(c *Context) { c.Status(200) }
+	r.GET("/orders", ok)
+	r.POST("/orders", ok)
+	r.POST("/login", ok)
+	r.POST("/admin/readonly", ok)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/orders"); w.Code != 200 {
+		t.Errorf("Expected writes before read-only mode, got %d", w.Code)
+	}
+
+	r.SetReadOnly(true, "database maintenance")
+	if enabled, reason, _ := r.IsReadOnly(); !enabled || reason != "database maintenance" {
+		t.Errorf("Expected read-only mode, got %v %q", enabled, reason)
+	}
+
+	w := do("POST", "/orders")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 in read-only mode, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" || !strings.Contains(w.Body.String(), "database maintenance") {
+		t.Errorf("Unexpected response %v %s", w.Header(), w.Body.String())
+	}
+	for _, req := range [][2]string{{"GET", "/orders"}, {"POST", "/login"}, {"POST", "/admin/readonly"}} {
+		if w := do(req[0], req[1]); w.Code != 200 {
+			t.Errorf("%s %s: expected 200 in read-only mode, got %d", req[0], req[1], w.Code)
+		}
+	}
+
+	r.SetReadOnly(false, "")
+	if w := do("POST", "/orders"); w.Code != 200 {
+		t.Errorf("Expected writes after read-only mode, got %d", w.Code)
+	}
+}
+
+func TestReadOnlyCondition(t *testing.T) {
+	failedOver := true
+	r := New()
+	r.Use(ReadOnlyWithConfig(ReadOnlyConfig{Condition: func() bool { return failedOver }}))
+	r.DELETE("/orders/1", func(c *Context) { c.Status(204) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/orders/1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while failed over, got %d", w.Code)
+	}
+
+	failedOver = false
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != 204 {
+		t.Errorf("Expected 204 after failback, got %d", w.Code)
+	}
+}
@@ -2,9 +2,12 @@ package goTap
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -45,6 +48,10 @@ type RedisCacheConfig struct {
 	// Redis client instance
 	Client *RedisClient
 
+	// Store caches responses in any KVStore instead of Client
+	// Default: Client, else Engine.KVStore; without either caching is skipped
+	Store KVStore
+
 	// TTL for cached responses (default: 5 minutes)
 	TTL time.Duration
 
@@ -59,9 +66,32 @@ type RedisCacheConfig struct {
 
 	// Custom key generator function (optional)
 	KeyGenerator func(c *Context) string
+
+	// CacheStatuses lists the response statuses that are cached, so errors
+	// such as 5xx responses are not served from the cache
+	// Default: [200]
+	CacheStatuses []int
+
+	// Compress gzips cached bodies of at least CompressMinSize bytes to
+	// save cache memory; they are decompressed when served
+	// Optional. Default value false.
+	Compress bool
+
+	// CompressMinSize is the smallest body that is compressed
+	// Default: 1024
+	CompressMinSize int
 }
 
-// RedisCache returns a middleware that caches GET requests in Redis
+// RedisCache returns a middleware that caches GET requests in Redis or a
+// KVStore. Responses can be tagged with CacheTags and invalidated by tag
+// with InvalidateTags after writes:
+//
+//	r.Use(goTap.RedisCache(goTap.RedisCacheConfig{Client: rdb}))
+//	r.GET("/products", goTap.CacheTags("products"), listProducts)
+//	r.POST("/products", func(c *goTap.Context) {
+//	    // ... create the product
+//	    goTap.InvalidateTags(c, "products")
+//	})
 func RedisCache(config RedisCacheConfig) HandlerFunc {
 	// Set defaults
 	if config.TTL == 0 {
@@ -76,13 +106,29 @@ func RedisCache(config RedisCacheConfig) HandlerFunc {
 	if config.KeyGenerator == nil {
 		config.KeyGenerator = defaultCacheKeyGenerator
 	}
+	if len(config.CacheStatuses) == 0 {
+		config.CacheStatuses = []int{http.StatusOK}
+	}
+	if config.CompressMinSize <= 0 {
+		config.CompressMinSize = 1024
+	}
+	if config.Store == nil && config.Client != nil && config.Client.Client != nil {
+		config.Store = NewRedisKVStore(config.Client, "")
+	}
 
 	return func(c *Context) {
-		// Skip if client not provided
-		if config.Client == nil || config.Client.Client == nil {
+		store := config.Store
+		if store == nil {
+			store = c.kvStore()
+		}
+		// Skip if no store configured
+		if store == nil {
 			c.Next()
 			return
 		}
+		// Handlers of any method find the store for InvalidateTags
+		tags := &cacheTags{store: store, prefix: config.Prefix}
+		c.Set(CacheTagsKey, tags)
 
 		// Check if method is cacheable
 		cacheable := false
@@ -109,15 +155,16 @@ func RedisCache(config RedisCacheConfig) HandlerFunc {
 		cacheKey := config.Prefix + config.KeyGenerator(c)
 
 		// Try to get from cache
-		ctx := context.Background()
-		cached, err := config.Client.Client.Get(ctx, cacheKey).Result()
-		if err == nil {
-			// Cache hit
-			c.Header("X-Cache", "HIT")
-			c.Header("X-Cache-Key", cacheKey)
-			c.Data(200, "application/json", []byte(cached))
-			c.Abort()
-			return
+		ctx := c.Request.Context()
+		if cached, err := store.Get(ctx, cacheKey); err == nil {
+			if entry, body, ok := decodeCacheEntry(cached); ok && tags.fresh(ctx, entry.Tags) {
+				// Cache hit
+				c.Header("X-Cache", "HIT")
+				c.Header("X-Cache-Key", cacheKey)
+				c.Data(entry.Status, entry.ContentType, body)
+				c.Abort()
+				return
+			}
 		}
 
 		// Cache miss - capture response
@@ -134,9 +181,11 @@ func RedisCache(config RedisCacheConfig) HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Store in cache if status is 200 and body exists
-		if writer.status == 200 && len(writer.body) > 0 {
-			config.Client.Client.Set(ctx, cacheKey, writer.body, config.TTL)
+		// Store in cache if the status is cacheable and body exists
+		status := writer.Status()
+		if containsInt(config.CacheStatuses, status) && len(writer.body) > 0 && !c.IsAborted() {
+			entry := cacheEntry{Status: status, ContentType: writer.Header().Get("Content-Type"), Tags: tags.versions}
+			store.Set(ctx, cacheKey, encodeCacheEntry(entry, writer.body, config.Compress, config.CompressMinSize), config.TTL)
 		}
 	}
 }
@@ -144,8 +193,7 @@ func RedisCache(config RedisCacheConfig) HandlerFunc {
 // cachedWriter captures response body for caching
 type cachedWriter struct {
 	ResponseWriter
-	body   []byte
-	status int
+	body []byte
 }
 
 func (w *cachedWriter) Write(data []byte) (int, error) {
@@ -153,9 +201,9 @@ func (w *cachedWriter) Write(data []byte) (int, error) {
 	return w.ResponseWriter.Write(data)
 }
 
-func (w *cachedWriter) WriteHeader(statusCode int) {
-	w.status = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
+func (w *cachedWriter) WriteString(s string) (int, error) {
+	w.body = append(w.body, s...)
+	return w.ResponseWriter.WriteString(s)
 }
 
 // defaultCacheKeyGenerator generates a cache key from request
@@ -173,20 +221,12 @@ func defaultCacheKeyGenerator(c *Context) string {
 
 // RedisInject injects Redis client into context for use in handlers
 func RedisInject(client *RedisClient) HandlerFunc {
-	return func(c *Context) {
-		c.Set("redis", client)
-		c.Next()
-	}
+	return Inject("redis", client)
 }
 
 // GetRedis retrieves Redis client from context
 func GetRedis(c *Context) (*RedisClient, bool) {
-	client, exists := c.Get("redis")
-	if !exists {
-		return nil, false
-	}
-	redisClient, ok := client.(*RedisClient)
-	return redisClient, ok
+	return Get[*RedisClient](c, "redis")
 }
 
 // MustGetRedis retrieves Redis client from context or panics
@@ -222,7 +262,9 @@ type RedisSessionConfig struct {
 	HttpOnly bool
 }
 
-// RedisSession returns middleware for Redis-backed session management
+// RedisSession returns middleware for Redis-backed session management.
+// Session IDs are only accepted if Redis holds their session, otherwise a
+// new ID is issued; call Session.Regenerate when the user logs in.
 func RedisSession(config RedisSessionConfig) HandlerFunc {
 	// Set defaults
 	if config.TTL == 0 {
@@ -242,27 +284,33 @@ func RedisSession(config RedisSessionConfig) HandlerFunc {
 			return
 		}
 
-		// Get session ID from cookie
-		sessionID, err := c.Cookie(config.CookieName)
-		if err != nil || sessionID == "" {
-			// No session - create new one
-			sessionID = generateSessionID()
-			c.SetCookie(config.CookieName, sessionID, int(config.TTL.Seconds()),
-				config.CookiePath, config.CookieDomain, config.Secure, config.HttpOnly)
-		}
-
-		// Load session data from Redis
-		ctx := context.Background()
-		sessionKey := "session:" + sessionID
-		sessionData, _ := config.Client.Client.HGetAll(ctx, sessionKey).Result()
-
 		// Create session object
+		ctx := context.Background()
 		session := &Session{
-			ID:     sessionID,
-			Data:   sessionData,
 			client: config.Client,
-			key:    sessionKey,
 			ttl:    config.TTL,
+			setCookie: func(id string) {
+				c.SetCookie(config.CookieName, id, int(config.TTL.Seconds()),
+					config.CookiePath, config.CookieDomain, config.Secure, config.HttpOnly)
+			},
+		}
+
+		// Load session data from Redis. Only IDs stored in Redis are
+		// accepted, so a client can't fix the ID of a session before it
+		// is authenticated.
+		if id, err := c.Cookie(config.CookieName); err == nil && validSessionID(id) {
+			data, err := config.Client.Client.HGetAll(ctx, "session:"+id).Result()
+			if err == nil && len(data) > 0 {
+				session.ID, session.key, session.Data = id, "session:"+id, data
+			}
+		}
+		if session.ID == "" {
+			// No known session - create new one
+			session.ID = generateSessionID()
+			session.key = "session:" + session.ID
+			session.Data = make(map[string]string)
+			session.issued = true
+			session.setCookie(session.ID)
 		}
 
 		// Inject into context
@@ -277,18 +325,24 @@ func RedisSession(config RedisSessionConfig) HandlerFunc {
 		}
 
 		// Refresh TTL
-		config.Client.Client.Expire(ctx, sessionKey, config.TTL)
+		config.Client.Client.Expire(ctx, session.key, config.TTL)
 	}
 }
 
-// Session represents a user session stored in Redis
+// Session represents a user session stored in Redis or a KVStore
 type Session struct {
 	ID       string
 	Data     map[string]string
 	client   *RedisClient
+	store    KVStore
 	key      string
 	ttl      time.Duration
 	modified bool
+
+	// setCookie sends the session cookie with a new ID
+	setCookie func(id string)
+	// issued is set when the ID was generated during this request
+	issued bool
 }
 
 // Get retrieves a value from session
@@ -312,8 +366,11 @@ func (s *Session) Delete(key string) {
 	s.modified = true
 }
 
-// Save persists session data to Redis
+// Save persists session data
 func (s *Session) Save() error {
+	if s.store != nil {
+		return s.saveKV()
+	}
 	if s.client == nil || s.client.Client == nil {
 		return fmt.Errorf("redis client not available")
 	}
@@ -335,8 +392,11 @@ func (s *Session) Save() error {
 	return err
 }
 
-// Destroy removes session from Redis
+// Destroy removes the session from its store
 func (s *Session) Destroy() error {
+	if s.store != nil {
+		return s.store.Delete(context.Background(), s.key)
+	}
 	if s.client == nil || s.client.Client == nil {
 		return fmt.Errorf("redis client not available")
 	}
@@ -345,14 +405,29 @@ func (s *Session) Destroy() error {
 	return s.client.Client.Del(ctx, s.key).Err()
 }
 
+// Regenerate moves the session data to a new ID and sends the new cookie,
+// so an ID obtained before login can't be used to take over the session.
+// Call it when the user logs in or passes 2FA, before writing the response.
+func (s *Session) Regenerate() error {
+	if s.issued {
+		return nil
+	}
+	if err := s.Destroy(); err != nil {
+		return err
+	}
+	s.ID = generateSessionID()
+	s.key = "session:" + s.ID
+	s.modified = true
+	s.issued = true
+	if s.setCookie != nil {
+		s.setCookie(s.ID)
+	}
+	return nil
+}
+
 // GetSession retrieves session from context
 func GetSession(c *Context) (*Session, bool) {
-	session, exists := c.Get("session")
-	if !exists {
-		return nil, false
-	}
-	sess, ok := session.(*Session)
-	return sess, ok
+	return Get[*Session](c, "session")
 }
 
 // MustGetSession retrieves session from context or panics
@@ -364,13 +439,33 @@ func MustGetSession(c *Context) *Session {
 	return session
 }
 
-// generateSessionID creates a unique session ID
+// generateSessionID creates a unique, unguessable session ID
 func generateSessionID() string {
-	// Use timestamp + random nanotime for uniqueness
-	timestamp := time.Now().UnixNano()
-	data := fmt.Sprintf("%d-%d", timestamp, timestamp/1000000)
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:16]) // Use first 16 bytes (32 hex chars)
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic("goTap: failed to generate session ID: " + err.Error())
+	}
+	return hex.EncodeToString(id) // 32 hex chars
+}
+
+// RedisStatusProbe returns a StatusProbe reading the Redis server version
+func RedisStatusProbe(name string, client *RedisClient) StatusProbe {
+	return StatusProbe{
+		Name: name,
+		Kind: "redis",
+		Version: func(ctx context.Context) (string, error) {
+			info, err := client.Client.Info(ctx, "server").Result()
+			if err != nil {
+				return "", err
+			}
+			for _, line := range strings.Split(info, "\n") {
+				if version, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+					return version, nil
+				}
+			}
+			return "", nil
+		},
+	}
 }
 
 // RedisHealthCheck returns middleware that checks Redis health
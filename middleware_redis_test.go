@@ -3,6 +3,7 @@ package goTap
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -275,6 +276,165 @@ func TestRedisCacheCustomKeyGenerator(t *testing.T) {
 	}
 }
 
+func TestRedisCacheTags(t *testing.T) {
+	redisClient, mr := setupMiniRedis(t)
+	defer mr.Close()
+	defer redisClient.Close()
+
+	r := New()
+	r.Use(RedisCache(RedisCacheConfig{Client: redisClient, TTL: time.Minute}))
+
+	counter := 0
+	r.GET("/products", CacheTags("products"), func(c *Context) {
+		counter++
+		c.String(200, "list %d", counter)
+	})
+	r.GET("/products/:id", CacheTags("product:{id}"), func(c *Context) {
+		counter++
+		c.String(200, "product %d", counter)
+	})
+	r.POST("/products/:id", func(c *Context) {
+		if err := InvalidateTags(c, "products", "product:"+c.Param("id")); err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		c.Status(204)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	get("/products")
+	get("/products/1")
+	get("/products/2")
+	if w := get("/products"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "list 1" {
+		t.Fatalf("Expected a cache hit, got %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("/products"); w.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf("Expected the cached content type, got %q", w.Header().Get("Content-Type"))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/products/1", nil))
+	if w.Code != 204 {
+		t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Only the entries tagged with an invalidated tag are refreshed
+	if w := get("/products"); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "list 4" {
+		t.Errorf("Expected the list to be refreshed, got %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("/products/1"); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("Expected product 1 to be refreshed")
+	}
+	if w := get("/products/2"); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("Expected product 2 to stay cached")
+	}
+	if w := get("/products"); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("Expected the refreshed list to be cached")
+	}
+}
+
+func TestRedisCacheStatuses(t *testing.T) {
+	store := NewMemoryKVStore()
+	r := New()
+	r.Use(RedisCache(RedisCacheConfig{Store: store, CacheStatuses: []int{200, 404}}))
+
+	fail := true
+	r.GET("/flaky", func(c *Context) {
+		if fail {
+			c.JSON(503, H{"error": "unavailable"})
+			return
+		}
+		c.JSON(200, H{"ok": true})
+	})
+	r.GET("/missing", func(c *Context) {
+		c.JSON(404, H{"error": "not found"})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	get("/flaky")
+	fail = false
+	if w := get("/flaky"); w.Code != 200 || w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Expected the 503 not to be cached, got %d %s", w.Code, w.Header().Get("X-Cache"))
+	}
+	get("/missing")
+	if w := get("/missing"); w.Code != 404 || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected the cached 404, got %d %s", w.Code, w.Header().Get("X-Cache"))
+	}
+}
+
+func TestRedisCacheCompress(t *testing.T) {
+	redisClient, mr := setupMiniRedis(t)
+	defer mr.Close()
+	defer redisClient.Close()
+
+	r := New()
+	r.Use(RedisCache(RedisCacheConfig{Client: redisClient, Prefix: "c:", Compress: true, CompressMinSize: 100}))
+	body := strings.Repeat("receipt line\n", 500)
+	r.GET("/large", func(c *Context) { c.String(200, body) })
+	r.GET("/small", func(c *Context) { c.String(200, "ok") })
+
+	for _, path := range []string{"/large", "/small"} {
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if want := map[string]string{"/large": body, "/small": "ok"}[path]; w.Body.String() != want {
+				t.Fatalf("Unexpected body for %s (%s)", path, w.Header().Get("X-Cache"))
+			}
+		}
+	}
+	for _, key := range mr.Keys() {
+		value, _ := mr.Get(key)
+		entry, _, ok := decodeCacheEntry([]byte(value))
+		if !ok {
+			t.Fatalf("Unexpected entry %q", value)
+		}
+		if large := len(value) > 100; entry.Gzip != large || len(value) > len(body)/10 {
+			t.Errorf("Expected only the large body to be compressed, got %d bytes gzip=%v", len(value), entry.Gzip)
+		}
+	}
+}
+
+func TestCacheRemember(t *testing.T) {
+	store := NewMemoryKVStore()
+	ctx := context.Background()
+	type product struct{ Name string }
+
+	loads := 0
+	load := func() (product, error) {
+		loads++
+		return product{Name: "Coffee"}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if p, err := CacheRemember(ctx, store, "product:1", time.Minute, load); err != nil || p.Name != "Coffee" {
+			t.Fatalf("Unexpected product %+v %v", p, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected one load, got %d", loads)
+	}
+
+	// Writing through replaces the cached value without a load
+	if err := CacheWrite(ctx, store, "product:1", product{Name: "Espresso"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := CacheRemember(ctx, store, "product:1", time.Minute, load); p.Name != "Espresso" || loads != 1 {
+		t.Errorf("Expected the written value, got %+v after %d loads", p, loads)
+	}
+	if _, err := CacheRemember(ctx, store, "product:2", time.Minute, func() (product, error) {
+		return product{}, fmt.Errorf("database down")
+	}); err == nil {
+		t.Error("Expected the load error")
+	}
+}
+
 func TestRedisInjectAndGet(t *testing.T) {
 	redisClient, mr := setupMiniRedis(t)
 	defer mr.Close()
@@ -424,6 +584,64 @@ func TestRedisSession(t *testing.T) {
 	}
 }
 
+func TestRedisSessionRejectsUnknownIDs(t *testing.T) {
+	redisClient, mr := setupMiniRedis(t)
+	defer mr.Close()
+	defer redisClient.Close()
+
+	r := New()
+	r.Use(RedisSession(RedisSessionConfig{Client: redisClient, TTL: time.Minute}))
+	r.POST("/login", func(c *Context) {
+		session := MustGetSession(c)
+		if c.Query("regenerate") != "" {
+			if err := session.Regenerate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		session.Set("user", "alice")
+	})
+	r.GET("/me", func(c *Context) {
+		user, _ := MustGetSession(c).Get("user")
+		c.String(200, user)
+	})
+
+	request := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// An attacker-chosen ID is replaced by a server-generated one
+	planted := &http.Cookie{Name: "session_id", Value: "0123456789abcdef0123456789abcdef"}
+	cookies := request("POST", "/login", planted).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == planted.Value {
+		t.Fatalf("Expected a new session ID, got %v", cookies)
+	}
+	if mr.Exists("session:" + planted.Value) {
+		t.Error("Expected no session stored under the planted ID")
+	}
+	issued := cookies[0]
+	if w := request("GET", "/me", issued); w.Body.String() != "alice" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected the issued ID to be accepted, got %q %v", w.Body.String(), w.Result().Cookies())
+	}
+
+	// Regenerate moves the data to a new ID
+	cookies = request("POST", "/login?regenerate=1", issued).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == issued.Value {
+		t.Fatalf("Expected Regenerate to send a new ID, got %v", cookies)
+	}
+	if w := request("GET", "/me", cookies[0]); w.Body.String() != "alice" {
+		t.Errorf("Expected the data under the new ID, got %q", w.Body.String())
+	}
+	if mr.Exists("session:" + issued.Value) {
+		t.Error("Expected the old session to be deleted")
+	}
+}
+
 func TestSessionDestroy(t *testing.T) {
 	redisClient, mr := setupMiniRedis(t)
 	defer mr.Close()
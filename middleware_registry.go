@@ -0,0 +1,160 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// registeredMiddleware is a middleware added with RegisterMiddleware
+type registeredMiddleware struct {
+	name     string
+	priority int
+	handler  HandlerFunc
+}
+
+// RegisterMiddleware adds named middleware to the routes registered
+// afterwards, like Use, ordered by priority instead of by call order, so
+// modules can register their middleware in any order:
+//
+//	r := goTap.Default()
+//	r.RegisterMiddleware("tenant", 20, tenantdb.Middleware())
+//	r.RegisterMiddleware("auth", 10, goTap.JWTAuth(secret))
+//	r.RegisterMiddleware("audit", 30, goTap.AuditLog(auditConfig))
+//
+//	public := r.Group("/public")
+//	public.SkipMiddleware("auth", "audit")
+//
+// Registered middleware runs after the engine's Use middleware, such as the
+// Logger and Recovery of Default, and before the middleware of groups, in
+// ascending priority; equal priorities keep their registration order.
+// Registering a name again replaces its middleware and priority.
+func (engine *Engine) RegisterMiddleware(name string, priority int, middleware HandlerFunc) {
+	entry := registeredMiddleware{name: name, priority: priority, handler: middleware}
+	replaced := false
+	for i, m := range engine.middlewareRegistry {
+		if m.name == name {
+			engine.middlewareRegistry[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		engine.middlewareRegistry = append(engine.middlewareRegistry, entry)
+	}
+	sort.SliceStable(engine.middlewareRegistry, func(i, j int) bool {
+		return engine.middlewareRegistry[i].priority < engine.middlewareRegistry[j].priority
+	})
+}
+
+// OverrideMiddleware replaces registered middleware for the routes
+// registered afterwards on the group and its subgroups, e.g. a different
+// authentication for an admin area. It panics if name is not registered.
+func (group *RouterGroup) OverrideMiddleware(name string, middleware HandlerFunc) *RouterGroup {
+	if !group.engine.hasMiddleware(name) {
+		panic(fmt.Sprintf("goTap: middleware %q is not registered", name))
+	}
+	group.setOverride(name, middleware)
+	return group
+}
+
+// SkipMiddleware disables registered middleware for the routes registered
+// afterwards on the group and its subgroups. It panics if a name is not
+// registered.
+func (group *RouterGroup) SkipMiddleware(names ...string) *RouterGroup {
+	for _, name := range names {
+		if !group.engine.hasMiddleware(name) {
+			panic(fmt.Sprintf("goTap: middleware %q is not registered", name))
+		}
+		group.setOverride(name, nil)
+	}
+	return group
+}
+
+// setOverride copies the overrides, which subgroups share, before changing
+// them
+func (group *RouterGroup) setOverride(name string, middleware HandlerFunc) {
+	overrides := maps.Clone(group.overrides)
+	if overrides == nil {
+		overrides = make(map[string]HandlerFunc)
+	}
+	overrides[name] = middleware
+	group.overrides = overrides
+}
+
+func (engine *Engine) hasMiddleware(name string) bool {
+	for _, m := range engine.middlewareRegistry {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MiddlewareChain returns the names of the middleware running before the
+// handler of route, given as "METHOD /path" as registered, e.g.
+// "GET /orders/:id". Registered middleware shows under its registered name,
+// other middleware under its function name as in Routes. It returns nil for
+// unknown routes.
+func (engine *Engine) MiddlewareChain(route string) []string {
+	meta, ok := engine.routeMeta[route]
+	if !ok {
+		return nil
+	}
+	return append([]string{}, meta.middleware...)
+}
+
+// inheritedHandlers returns how many of the group's handlers come from the
+// engine's Use
+func (group *RouterGroup) inheritedHandlers() int {
+	if group.root {
+		return len(group.Handlers)
+	}
+	return group.engineHandlers
+}
+
+// routeHandlers returns the chain of a route with handlers, with the
+// registered middleware after the engine's middleware, and the names of its
+// middleware
+func (group *RouterGroup) routeHandlers(handlers HandlersChain) (HandlersChain, []string) {
+	var registered []registeredMiddleware
+	for _, m := range group.engine.middlewareRegistry {
+		if override, ok := group.overrides[m.name]; ok {
+			if override == nil {
+				continue
+			}
+			m.handler = override
+		}
+		registered = append(registered, m)
+	}
+
+	n := group.inheritedHandlers()
+	finalSize := len(group.Handlers) + len(registered) + len(handlers)
+	assert1(finalSize < int(abortIndex), "too many handlers")
+	chain := make(HandlersChain, 0, finalSize)
+	names := make([]string, 0, finalSize)
+	for _, h := range group.Handlers[:n] {
+		chain = append(chain, h)
+		names = append(names, middlewareName(h))
+	}
+	for _, m := range registered {
+		chain = append(chain, m.handler)
+		names = append(names, m.name)
+	}
+	for _, h := range group.Handlers[n:] {
+		chain = append(chain, h)
+		names = append(names, middlewareName(h))
+	}
+	chain = append(chain, handlers...)
+	for _, h := range handlers {
+		names = append(names, middlewareName(h))
+	}
+	if len(names) > 0 {
+		// The last handler is the route's handler
+		names = names[:len(names)-1]
+	}
+	return chain, names
+}
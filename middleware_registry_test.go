@@ -0,0 +1,73 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareRegistry(t *testing.T) {
+	var ran []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) {
+			ran = append(ran, name)
+			c.Next()
+		}
+	}
+	ok := func(c *Context) { c.Status(http.StatusOK) }
+
+	r := New()
+	r.Use(mark("logger"))
+	r.RegisterMiddleware("audit", 30, mark("audit"))
+	r.RegisterMiddleware("auth", 10, mark("auth"))
+	r.RegisterMiddleware("tenant", 20, mark("tenant"))
+	r.RegisterMiddleware("audit", 5, mark("audit2"))
+
+	api := r.Group("/api", mark("group"))
+	api.GET("/orders", ok)
+	admin := api.Group("/admin")
+	admin.OverrideMiddleware("auth", mark("admin-auth"))
+	admin.GET("/users", ok)
+	public := r.Group("/public")
+	public.SkipMiddleware("auth", "tenant")
+	public.GET("/menu", ok)
+
+	for path, want := range map[string][]string{
+		"/api/orders":      {"logger", "audit2", "auth", "tenant", "group"},
+		"/api/admin/users": {"logger", "audit2", "admin-auth", "tenant", "group"},
+		"/public/menu":     {"logger", "audit2"},
+	} {
+		ran = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || !reflect.DeepEqual(ran, want) {
+			t.Errorf("%s: expected %v, got %d %v", path, want, w.Code, ran)
+		}
+	}
+
+	chain := r.MiddlewareChain("GET /api/orders")
+	if len(chain) != 5 || chain[1] != "audit" || chain[2] != "auth" || chain[3] != "tenant" {
+		t.Errorf("Unexpected chain %v", chain)
+	}
+	if r.MiddlewareChain("GET /missing") != nil {
+		t.Error("Expected no chain for an unknown route")
+	}
+	var out strings.Builder
+	r.WriteRoutes(&out, RoutesJSON)
+	if !strings.Contains(out.String(), `"auth"`) {
+		t.Errorf("Expected WriteRoutes to show registered names, got %s", out.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected skipping an unknown middleware to panic")
+		}
+	}()
+	public.SkipMiddleware("missing")
+}
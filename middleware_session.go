@@ -0,0 +1,137 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// SessionConfig holds configuration for KVStore backed sessions
+type SessionConfig struct {
+	// Store holds session data
+	// Default: Engine.KVStore if set, else an in-memory store
+	Store KVStore
+
+	// TTL of idle sessions
+	// Default: 24 hours
+	TTL time.Duration
+
+	// CookieName of the session ID cookie
+	// Default: "session_id"
+	CookieName string
+
+	// CookiePath of the session ID cookie
+	// Default: "/"
+	CookiePath string
+
+	// CookieDomain of the session ID cookie
+	// Optional.
+	CookieDomain string
+
+	// Secure sets the cookie's Secure flag
+	Secure bool
+
+	// DisableHttpOnly lets scripts read the session cookie. The cookie is
+	// HttpOnly by default.
+	DisableHttpOnly bool
+}
+
+// Sessions returns a session middleware storing sessions in a KVStore. It
+// works like RedisSession with any backend; get the session with
+// GetSession. Session IDs are only accepted if the store knows them,
+// otherwise a new ID is issued, and Session.Regenerate replaces the ID
+// when the user logs in:
+//
+//	r.KVStore = goTap.NewRedisKVStore(redisClient, "")
+//	r.Use(goTap.Sessions(goTap.SessionConfig{Secure: true}))
+func Sessions(config SessionConfig) HandlerFunc {
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.CookieName == "" {
+		config.CookieName = "session_id"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	var memory KVStore
+	if config.Store == nil {
+		memory = NewMemoryKVStore()
+	}
+
+	return func(c *Context) {
+		store := config.Store
+		if store == nil {
+			if store = c.kvStore(); store == nil {
+				store = memory
+			}
+		}
+
+		ctx := context.Background()
+		session := &Session{
+			Data:  make(map[string]string),
+			store: store,
+			ttl:   config.TTL,
+			setCookie: func(id string) {
+				c.SetCookie(config.CookieName, id, int(config.TTL.Seconds()),
+					config.CookiePath, config.CookieDomain, config.Secure, !config.DisableHttpOnly)
+			},
+		}
+
+		// Only IDs the store issued are accepted, so a client can't fix the
+		// ID of a session before it is authenticated
+		known := false
+		if id, err := c.Cookie(config.CookieName); err == nil && validSessionID(id) {
+			if data, err := store.Get(ctx, "session:"+id); err == nil {
+				session.ID, session.key = id, "session:"+id
+				json.Unmarshal(data, &session.Data)
+				known = true
+			}
+		}
+		if !known {
+			session.ID = generateSessionID()
+			session.key = "session:" + session.ID
+			session.issued = true
+			session.setCookie(session.ID)
+		}
+		c.Set("session", session)
+
+		c.Next()
+
+		if session.modified {
+			if err := session.Save(); err != nil {
+				debugPrint("[WARNING] failed to save session: %v", err)
+			}
+		} else {
+			store.Expire(ctx, session.key, config.TTL)
+		}
+	}
+}
+
+// validSessionID reports whether id has the format of generateSessionID
+func validSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// saveKV persists session data to its KVStore
+func (s *Session) saveKV() error {
+	ctx := context.Background()
+	s.modified = false
+	if len(s.Data) == 0 {
+		return s.store.Delete(ctx, s.key)
+	}
+	data, err := json.Marshal(s.Data)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(ctx, s.key, data, s.ttl)
+}
@@ -6,6 +6,7 @@ package goTap
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/jaswant99k/gotap/shadowdb"
 )
@@ -14,6 +15,7 @@ import (
 func ShadowDBMiddleware(sdb *shadowdb.ShadowDB) HandlerFunc {
 	return func(c *Context) {
 		c.Set(shadowdb.ContextKeyShadowDB, sdb)
+		Provide(c, sdb)
 
 		// Pre-fetch read and write connections for this request
 		readDB, _ := sdb.Read()
@@ -28,32 +30,17 @@ func ShadowDBMiddleware(sdb *shadowdb.ShadowDB) HandlerFunc {
 
 // GetShadowDB retrieves Shadow DB from context
 func GetShadowDB(c *Context) (*shadowdb.ShadowDB, bool) {
-	sdb, exists := c.Get(shadowdb.ContextKeyShadowDB)
-	if !exists {
-		return nil, false
-	}
-	shadowDB, ok := sdb.(*shadowdb.ShadowDB)
-	return shadowDB, ok
+	return Get[*shadowdb.ShadowDB](c, shadowdb.ContextKeyShadowDB)
 }
 
 // GetReadDB retrieves read database connection from context
 func GetReadDB(c *Context) (*sql.DB, bool) {
-	db, exists := c.Get(shadowdb.ContextKeyReadDB)
-	if !exists {
-		return nil, false
-	}
-	readDB, ok := db.(*sql.DB)
-	return readDB, ok
+	return Get[*sql.DB](c, shadowdb.ContextKeyReadDB)
 }
 
 // GetWriteDB retrieves write database connection from context
 func GetWriteDB(c *Context) (*sql.DB, bool) {
-	db, exists := c.Get(shadowdb.ContextKeyWriteDB)
-	if !exists {
-		return nil, false
-	}
-	writeDB, ok := db.(*sql.DB)
-	return writeDB, ok
+	return Get[*sql.DB](c, shadowdb.ContextKeyWriteDB)
 }
 
 // DBHealthCheck returns a middleware that checks database health
@@ -106,3 +93,87 @@ func RequireHealthyDB() HandlerFunc {
 		c.Next()
 	}
 }
+
+// ShadowDBStatusHandler returns a handler reporting ShadowDB health, query
+// metrics, replicas, replication lag and failover history as JSON, for ops
+// dashboards. It responds 503 when no database accepts writes.
+//
+//	admin.GET("/db/status", goTap.ShadowDBStatusHandler(sdb))
+func ShadowDBStatusHandler(sdb *shadowdb.ShadowDB) HandlerFunc {
+	return func(c *Context) {
+		status := sdb.GetStatus()
+		metrics := sdb.Metrics()
+
+		databases := H{"primary": shadowDBStatusJSON(status.PrimaryHealth, metrics["primary"])}
+		if sdb.Shadow() != nil {
+			databases["shadow"] = shadowDBStatusJSON(status.ShadowHealth, metrics["shadow"])
+		}
+
+		replicas := make([]H, 0, len(status.Replicas))
+		for _, r := range status.Replicas {
+			replica := shadowDBStatusJSON(r.Health, metrics[r.Name])
+			replica["name"] = r.Name
+			replica["weight"] = r.Weight
+			replica["in_pool"] = r.InPool
+			replica["ping_ms"] = durationMillis(r.Latency)
+			replicas = append(replicas, replica)
+		}
+
+		events := make([]H, 0)
+		for _, e := range sdb.FailoverEvents() {
+			events = append(events, H{
+				"type":   e.Type,
+				"from":   e.From,
+				"to":     e.To,
+				"reason": e.Reason,
+				"time":   e.Time,
+			})
+		}
+
+		_, err := sdb.Write()
+		body := H{
+			"healthy":        err == nil,
+			"active_db":      status.ActiveDB,
+			"read_strategy":  status.ReadStrategy,
+			"write_strategy": status.WriteStrategy,
+			"auto_failover":  status.AutoFailover,
+			"auto_failback":  status.AutoFailback,
+			"databases":      databases,
+			"replicas":       replicas,
+			"events":         events,
+		}
+		if status.WriteStrategy == shadowdb.WriteBoth {
+			r := sdb.ReplicationStatus()
+			body["replication"] = H{
+				"pending":    r.Pending,
+				"replicated": r.Replicated,
+				"conflicts":  r.Conflicts,
+				"last_error": r.LastError,
+			}
+		}
+
+		code := 200
+		if err != nil {
+			code = 503
+		}
+		c.JSON(code, body)
+	}
+}
+
+func shadowDBStatusJSON(health shadowdb.HealthStats, m shadowdb.DBMetrics) H {
+	return H{
+		"status":            health.Status,
+		"last_check":        health.LastCheck,
+		"consecutive_fails": health.ConsecutiveFails,
+		"queries":           m.Queries,
+		"errors":            m.Errors,
+		"error_rate":        m.ErrorRate,
+		"p50_ms":            durationMillis(m.P50),
+		"p95_ms":            durationMillis(m.P95),
+		"p99_ms":            durationMillis(m.P99),
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jaswant99k/gotap/shadowdb"
+)
+
+func TestShadowDBStatusHandler(t *testing.T) {
+	dir := t.TempDir()
+	sdb, err := shadowdb.New(shadowdb.Config{
+		Primary:             shadowdb.DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "primary.db")},
+		Shadow:              shadowdb.DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "shadow.db")},
+		Replicas:            []shadowdb.ReplicaConfig{{Name: "r1", DBConfig: shadowdb.DBConfig{Driver: "sqlite3", DSN: ":memory:"}}},
+		WriteStrategy:       shadowdb.WriteBoth,
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Skipf("Skipping ShadowDB tests: sqlite not available (%v)", err)
+	}
+	defer sdb.Close()
+	sdb.ExecWrite("CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	sdb.Failover()
+
+	r := New()
+	r.GET("/admin/db", ShadowDBStatusHandler(sdb))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/db", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Healthy   bool   `json:"healthy"`
+		ActiveDB  string `json:"active_db"`
+		Databases map[string]struct {
+			Status  string `json:"status"`
+			Queries int64  `json:"queries"`
+		} `json:"databases"`
+		Replicas    []map[string]any `json:"replicas"`
+		Events      []map[string]any `json:"events"`
+		Replication map[string]any   `json:"replication"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if !body.Healthy || body.ActiveDB != "shadow" {
+		t.Errorf("Unexpected status %s", w.Body.String())
+	}
+	if body.Databases["primary"].Status != "healthy" || body.Databases["primary"].Queries != 1 {
+		t.Errorf("Unexpected primary entry %+v", body.Databases["primary"])
+	}
+	if len(body.Replicas) != 1 || body.Replicas[0]["name"] != "r1" {
+		t.Errorf("Unexpected replicas %+v", body.Replicas)
+	}
+	if len(body.Events) != 1 || body.Events[0]["type"] != "failover" || body.Events[0]["reason"] != "manual" {
+		t.Errorf("Unexpected events %+v", body.Events)
+	}
+	if body.Replication == nil {
+		t.Error("Expected replication stats for WriteBoth")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SingleflightConfig holds configuration for the Singleflight middleware
+type SingleflightConfig struct {
+	// KeyFunc identifies identical requests. Responses are shared between
+	// requests with the same key, so it must include everything the
+	// response depends on.
+	// Default: method, URL, user_id from context and the Accept,
+	// Accept-Encoding, Authorization and Cookie headers
+	KeyFunc func(c *Context) string
+
+	// Methods the middleware applies to
+	// Default: ["GET", "HEAD"]
+	Methods []string
+
+	// Timeout bounds how long a request waits for the shared response
+	// before running the handler itself
+	// Optional. Default value 0 (wait until the first request completes).
+	Timeout time.Duration
+}
+
+// singleflightCall is a handler execution shared by identical requests
+type singleflightCall struct {
+	done   chan struct{}
+	ok     bool
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Singleflight returns a middleware that runs the handler once for
+// identical concurrent requests and sends its response to all of them,
+// e.g. to spare the database a thundering herd of catalog requests after a
+// cache entry expires:
+//
+//	r.GET("/products", goTap.Singleflight(nil), listProducts)
+//
+// Only requests arriving while the first one is in flight share its
+// response; later requests run the handler again. A nil keyFunc uses the
+// default of SingleflightConfig.KeyFunc.
+func Singleflight(keyFunc func(c *Context) string) HandlerFunc {
+	return SingleflightWithConfig(SingleflightConfig{KeyFunc: keyFunc})
+}
+
+// SingleflightWithConfig returns a Singleflight middleware with config
+func SingleflightWithConfig(config SingleflightConfig) HandlerFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultSingleflightKey
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]*singleflightCall)
+
+	return func(c *Context) {
+		if !containsString(config.Methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		key := config.KeyFunc(c)
+		mu.Lock()
+		if call, ok := calls[key]; ok {
+			mu.Unlock()
+			if !waitSingleflight(c, call, config.Timeout) {
+				c.Next()
+			}
+			return
+		}
+		call := &singleflightCall{done: make(chan struct{})}
+		calls[key] = call
+		mu.Unlock()
+
+		writer := &singleflightWriter{ResponseWriter: c.Writer}
+		defer func() {
+			// A panicking handler leaves ok false, so waiters run it themselves
+			c.Writer = writer.ResponseWriter
+			mu.Lock()
+			delete(calls, key)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		c.Writer = writer
+		c.Next()
+		call.status = writer.Status()
+		call.header = writer.Header().Clone()
+		call.body = writer.body
+		call.ok = true
+	}
+}
+
+// waitSingleflight waits for call and writes its response, reporting
+// whether it did
+func waitSingleflight(c *Context, call *singleflightCall, timeout time.Duration) bool {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-call.done:
+	case <-expired:
+		return false
+	case <-c.Request.Context().Done():
+		c.Abort()
+		return true
+	}
+	if !call.ok {
+		return false
+	}
+
+	header := c.Writer.Header()
+	for k, v := range call.header {
+		header[k] = append([]string(nil), v...)
+	}
+	c.Writer.WriteHeader(call.status)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Write(call.body)
+	c.Abort()
+	return true
+}
+
+func defaultSingleflightKey(c *Context) string {
+	scope := ""
+	if userID, ok := c.Get("user_id"); ok && userID != nil {
+		scope = fmt.Sprint(userID)
+	}
+	r := c.Request
+	return scope + "\n" + r.Method + " " + r.URL.RequestURI() + "\n" +
+		r.Header.Get("Accept") + "\n" + r.Header.Get("Accept-Encoding") + "\n" +
+		r.Header.Get("Authorization") + "\n" + r.Header.Get("Cookie")
+}
+
+// singleflightWriter captures the response while writing it through
+type singleflightWriter struct {
+	ResponseWriter
+	body []byte
+}
+
+func (w *singleflightWriter) Write(data []byte) (int, error) {
+	w.body = append(w.body, data...)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *singleflightWriter) WriteString(s string) (int, error) {
+	w.body = append(w.body, s...)
+	return w.ResponseWriter.WriteString(s)
+}
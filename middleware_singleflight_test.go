@@ -0,0 +1,138 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := New()
+	r.GET("/products", Singleflight(nil), func(c *Context) {
+		calls.Add(1)
+		<-release
+		c.Header("X-Catalog", "v1")
+		c.JSON(200, H{"page": c.Query("page")})
+	})
+
+	get := func(path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = get("/products?page=1", "")
+		}(i)
+	}
+	// An identical request from another user and another page run separately
+	wg.Add(2)
+	var other, page2 *httptest.ResponseRecorder
+	go func() { defer wg.Done(); other = get("/products?page=1", "Bearer b") }()
+	go func() { defer wg.Done(); page2 = get("/products?page=2", "") }()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected 3 handler executions, got %d", n)
+	}
+	for _, w := range responses {
+		if w.Code != 200 || w.Body.String() != `{"page":"1"}`+"\n" || w.Header().Get("X-Catalog") != "v1" {
+			t.Errorf("Unexpected shared response %d %q %v", w.Code, w.Body.String(), w.Header())
+		}
+	}
+	if other.Code != 200 || page2.Body.String() != `{"page":"2"}`+"\n" {
+		t.Errorf("Unexpected separate responses %q %q", other.Body.String(), page2.Body.String())
+	}
+
+	// Completed requests aren't shared with later ones
+	get("/products?page=1", "")
+	if n := calls.Load(); n != 4 {
+		t.Errorf("Expected a new execution after completion, got %d", n)
+	}
+}
+
+func TestSingleflightPanicAndTimeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := New()
+	r.Use(Recovery())
+	r.GET("/panic", SingleflightWithConfig(SingleflightConfig{KeyFunc: func(c *Context) string { return "k" }}), func(c *Context) {
+		if calls.Add(1) == 1 {
+			<-release
+			panic("upstream failed")
+		}
+		c.String(200, "ok")
+	})
+	var slowCalls atomic.Int32
+	r.GET("/slow", SingleflightWithConfig(SingleflightConfig{Timeout: 20 * time.Millisecond}), func(c *Context) {
+		if slowCalls.Add(1) == 1 {
+			<-release
+		}
+		c.String(200, "done")
+	})
+
+	var wg sync.WaitGroup
+	var leader, waiter *httptest.ResponseRecorder
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		leader = httptest.NewRecorder()
+		r.ServeHTTP(leader, httptest.NewRequest("GET", "/panic", nil))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		waiter = httptest.NewRecorder()
+		r.ServeHTTP(waiter, httptest.NewRequest("GET", "/panic", nil))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The waiter stops waiting for the slow leader and runs the handler
+	slow := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+		slow <- w
+	}()
+	time.Sleep(10 * time.Millisecond)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Body.String() != "done" || slowCalls.Load() != 2 {
+		t.Errorf("Expected the waiter to run the handler after the timeout, got %q", w.Body.String())
+	}
+	close(release)
+	wg.Wait()
+	<-slow
+
+	if leader.Code != 500 || waiter.Code != 200 || waiter.Body.String() != "ok" {
+		t.Errorf("Expected the waiter to run after the leader panicked, got %d and %d %q", leader.Code, waiter.Code, waiter.Body.String())
+	}
+}
+
+func TestSingleflightKeyScopesNonStringUserIDs(t *testing.T) {
+	c1, _ := CreateTestContext(httptest.NewRecorder())
+	c1.Set("user_id", 1)
+	c2, _ := CreateTestContext(httptest.NewRecorder())
+	c2.Set("user_id", 2)
+	if defaultSingleflightKey(c1) == defaultSingleflightKey(c2) {
+		t.Error("Expected users with numeric IDs to get different keys")
+	}
+}
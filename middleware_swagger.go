@@ -4,13 +4,27 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+	"golang.org/x/net/webdav"
+)
+
+// DocsRenderer is the UI serving API documentation
+type DocsRenderer string
+
+// Documentation renderers
+const (
+	SwaggerUIRenderer DocsRenderer = "swagger-ui"
+	ReDocRenderer     DocsRenderer = "redoc"
+	RapiDocRenderer   DocsRenderer = "rapidoc"
 )
 
 // SwaggerConfig holds Swagger UI configuration
@@ -25,6 +39,55 @@ type SwaggerConfig struct {
 	PersistAuthorization bool
 	// DefaultModelsExpandDepth sets the default expansion depth for models
 	DefaultModelsExpandDepth int
+
+	// Renderer serves the docs with Swagger UI, ReDoc or RapiDoc
+	// Default: SwaggerUIRenderer
+	Renderer DocsRenderer
+
+	// ScriptURL loads the ReDoc or RapiDoc bundle
+	// Default: the renderer's CDN bundle
+	ScriptURL string
+
+	// Title of the ReDoc or RapiDoc page
+	// Default: "API Documentation"
+	Title string
+
+	// InstanceName is the swag instance served as doc.json, see
+	// swag init --instanceName
+	// Default: "swagger"
+	InstanceName string
+
+	// Spec is served as doc.json instead of a swag instance
+	// Optional.
+	Spec []byte
+
+	// Middleware protects the docs, e.g. goTap.BasicAuth(accounts) or
+	// goTap.JWTAuth(secret)
+	// Optional.
+	Middleware []HandlerFunc
+
+	// DisableInRelease doesn't register the docs in release mode
+	DisableInRelease bool
+
+	// DisableEnv doesn't register the docs if the environment variable is
+	// set, e.g. "DISABLE_SWAGGER"
+	// Optional.
+	DisableEnv string
+}
+
+// SwaggerSpec is one of the specs of a multi-version API, see
+// SetupSwaggerSpecs
+type SwaggerSpec struct {
+	// Name is the path segment and label of the spec, e.g. "v1"
+	Name string
+
+	// InstanceName is the swag instance, see swag init --instanceName
+	// Default: Name
+	InstanceName string
+
+	// Spec is served instead of a swag instance
+	// Optional.
+	Spec []byte
 }
 
 // DefaultSwaggerConfig returns default Swagger configuration
@@ -35,27 +98,106 @@ func DefaultSwaggerConfig() *SwaggerConfig {
 		DeepLinking:              true,
 		PersistAuthorization:     true,
 		DefaultModelsExpandDepth: 1,
+		Renderer:                 SwaggerUIRenderer,
 	}
 }
 
-// SwaggerHandler returns a handler that serves Swagger UI
+const (
+	redocScriptURL   = "https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"
+	rapidocScriptURL = "https://unpkg.com/rapidoc/dist/rapidoc-min.js"
+)
+
+var docsPageTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body style="margin:0">
+{{if eq .Renderer "rapidoc"}}<rapi-doc spec-url="{{.URL}}"></rapi-doc>{{else}}<redoc spec-url="{{.URL}}"></redoc>{{end}}
+<script src="{{.ScriptURL}}"></script>
+</body>
+</html>
+`))
+
+var docsIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>API Documentation</title></head>
+<body>
+<h1>API Documentation</h1>
+<ul>{{range .}}<li><a href="{{.}}/index.html">{{.}}</a></li>{{end}}</ul>
+</body>
+</html>
+`))
+
+// SwaggerHandler returns a handler that serves Swagger UI, ReDoc or
+// RapiDoc by config.Renderer, for a route ending in /*any
 // It wraps gin-swagger to work with goTap's Context
 func SwaggerHandler(config *SwaggerConfig) HandlerFunc {
 	if config == nil {
 		config = DefaultSwaggerConfig()
 	}
+	if config.URL == "" {
+		config.URL = "doc.json"
+	}
+	if config.DocExpansion == "" {
+		config.DocExpansion = "list"
+	}
+	if config.InstanceName == "" {
+		config.InstanceName = swag.Name
+	}
 
-	// Create gin-swagger handler with configuration
-	ginHandler := ginSwagger.WrapHandler(
-		swaggerFiles.Handler,
-		ginSwagger.URL(config.URL),
-		ginSwagger.DocExpansion(config.DocExpansion),
-		ginSwagger.DeepLinking(config.DeepLinking),
-		ginSwagger.PersistAuthorization(config.PersistAuthorization),
-		ginSwagger.DefaultModelsExpandDepth(config.DefaultModelsExpandDepth),
-	)
+	var ginHandler gin.HandlerFunc
+	var page []byte
+	switch config.Renderer {
+	case "", SwaggerUIRenderer:
+		// Each handler gets its own file server, as it remembers its prefix
+		files := &webdav.Handler{FileSystem: swaggerFiles.FS, LockSystem: webdav.NewMemLS()}
+		ginHandler = ginSwagger.WrapHandler(
+			files,
+			ginSwagger.URL(config.URL),
+			ginSwagger.DocExpansion(config.DocExpansion),
+			ginSwagger.DeepLinking(config.DeepLinking),
+			ginSwagger.PersistAuthorization(config.PersistAuthorization),
+			ginSwagger.DefaultModelsExpandDepth(config.DefaultModelsExpandDepth),
+			ginSwagger.InstanceName(config.InstanceName),
+		)
+	case ReDocRenderer, RapiDocRenderer:
+		data := *config
+		if data.ScriptURL == "" {
+			data.ScriptURL = redocScriptURL
+			if config.Renderer == RapiDocRenderer {
+				data.ScriptURL = rapidocScriptURL
+			}
+		}
+		if data.Title == "" {
+			data.Title = "API Documentation"
+		}
+		var buf strings.Builder
+		if err := docsPageTemplate.Execute(&buf, data); err != nil {
+			panic(err)
+		}
+		page = []byte(buf.String())
+	default:
+		panic("goTap: unknown docs renderer " + string(config.Renderer))
+	}
 
 	return func(c *Context) {
+		file := strings.TrimPrefix(c.Param("any"), "/")
+		if file == "doc.json" {
+			serveSwaggerDoc(c, config)
+			return
+		}
+		if ginHandler == nil {
+			if file != "" && file != "index.html" {
+				c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+				return
+			}
+			c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+			return
+		}
+
 		// Call the gin-swagger handler directly with our request/response
 		ginHandler(&gin.Context{
 			Request: c.Request,
@@ -64,6 +206,28 @@ func SwaggerHandler(config *SwaggerConfig) HandlerFunc {
 	}
 }
 
+// serveSwaggerDoc serves config.Spec or the swag instance as doc.json
+func serveSwaggerDoc(c *Context, config *SwaggerConfig) {
+	if config.Spec != nil {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", config.Spec)
+		return
+	}
+	doc, err := swag.ReadDoc(config.InstanceName)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+}
+
+// swaggerDisabled reports whether config turns the docs off
+func swaggerDisabled(config *SwaggerConfig) bool {
+	if config.DisableInRelease && Mode() == ReleaseMode {
+		return true
+	}
+	return config.DisableEnv != "" && os.Getenv(config.DisableEnv) != ""
+}
+
 // ginResponseWriter wraps goTap's ResponseWriter to work with gin
 type ginResponseWriter struct {
 	http.ResponseWriter
@@ -119,18 +283,32 @@ func SetupSwagger(r *Engine, basePath string) {
 	SetupSwaggerWithConfig(r, basePath, nil)
 }
 
-// SetupSwaggerWithConfig registers Swagger UI routes with dynamic host configuration
-// Usage:
+// SetupSwaggerWithConfig registers documentation routes with config, e.g.
+// ReDoc behind basic auth and hidden in release mode:
 //
 //	import _ "yourmodule/docs"
-//	goTap.SetupSwaggerWithConfig(r, "/swagger", &goTap.SwaggerConfig{URL: "doc.json"})
+//	goTap.SetupSwaggerWithConfig(r, "/docs", &goTap.SwaggerConfig{
+//	    Renderer:         goTap.ReDocRenderer,
+//	    Middleware:       []goTap.HandlerFunc{goTap.BasicAuth(goTap.Accounts{"dev": "secret"})},
+//	    DisableInRelease: true,
+//	})
+//
+// The docs are served at /docs/index.html and the spec at /docs/doc.json.
 func SetupSwaggerWithConfig(r *Engine, basePath string, config *SwaggerConfig) {
 	if basePath == "" {
 		basePath = "/swagger"
 	}
+	if config == nil {
+		config = DefaultSwaggerConfig()
+	}
+	if swaggerDisabled(config) {
+		debugPrint("Swagger docs at %s are disabled\n", basePath)
+		return
+	}
 
-	// Swagger UI
-	r.GET(basePath+"/*any", SwaggerHandler(config))
+	group := r.Group(basePath)
+	group.Use(config.Middleware...)
+	group.GET("/*any", SwaggerHandler(config))
 }
 
 // SetupSwaggerWithAuth registers Swagger UI routes with authentication
@@ -138,13 +316,54 @@ func SetupSwaggerWithConfig(r *Engine, basePath string, config *SwaggerConfig) {
 //
 //	goTap.SetupSwaggerWithAuth(r, "/swagger", goTap.JWTAuth(jwtSecret))
 func SetupSwaggerWithAuth(r *Engine, basePath string, authMiddleware ...HandlerFunc) {
+	config := DefaultSwaggerConfig()
+	config.Middleware = authMiddleware
+	SetupSwaggerWithConfig(r, basePath, config)
+}
+
+// SetupSwaggerSpecs registers the docs of each spec of a multi-version API
+// under basePath, e.g. /docs/v1/index.html and /docs/v2/index.html, and
+// a page linking them at basePath. config applies to every spec and may be
+// nil.
+//
+//	goTap.SetupSwaggerSpecs(r, "/docs", nil,
+//	    goTap.SwaggerSpec{Name: "v1"},
+//	    goTap.SwaggerSpec{Name: "v2"},
+//	)
+func SetupSwaggerSpecs(r *Engine, basePath string, config *SwaggerConfig, specs ...SwaggerSpec) {
 	if basePath == "" {
 		basePath = "/swagger"
 	}
+	basePath = strings.TrimSuffix(basePath, "/")
+	if config == nil {
+		config = DefaultSwaggerConfig()
+	}
+	if swaggerDisabled(config) {
+		debugPrint("Swagger docs at %s are disabled\n", basePath)
+		return
+	}
 
-	group := r.Group(basePath)
-	group.Use(authMiddleware...)
-	group.GET("/*any", SwaggerHandler(nil))
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		specConfig := *config
+		specConfig.InstanceName = spec.InstanceName
+		if specConfig.InstanceName == "" {
+			specConfig.InstanceName = spec.Name
+		}
+		specConfig.Spec = spec.Spec
+		SetupSwaggerWithConfig(r, basePath+"/"+spec.Name, &specConfig)
+		names = append(names, spec.Name)
+	}
+
+	var buf strings.Builder
+	if err := docsIndexTemplate.Execute(&buf, names); err != nil {
+		panic(err)
+	}
+	index := []byte(buf.String())
+	handlers := append(append([]HandlerFunc{}, config.Middleware...), func(c *Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+	r.GET(basePath, handlers...)
 }
 
 // UpdateSwaggerHost updates the Swagger spec host dynamically based on the server's running port
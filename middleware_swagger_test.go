@@ -0,0 +1,109 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/swaggo/swag"
+)
+
+type testSwaggerDoc string
+
+func (d testSwaggerDoc) ReadDoc() string { return string(d) }
+
+func init() {
+	swag.Register("v2", testSwaggerDoc(`{"swagger":"2.0","info":{"title":"v2"}}`))
+}
+
+func swaggerRequest(r *Engine, path string, auth bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if auth {
+		req.SetBasicAuth("dev", "secret")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetupSwaggerRenderers(t *testing.T) {
+	spec := []byte(`{"swagger":"2.0","info":{"title":"POS"}}`)
+	r := New()
+	SetupSwaggerWithConfig(r, "/swagger", &SwaggerConfig{Spec: spec})
+	SetupSwaggerWithConfig(r, "/redoc", &SwaggerConfig{Renderer: ReDocRenderer, Spec: spec})
+	SetupSwaggerWithConfig(r, "/rapidoc", &SwaggerConfig{Renderer: RapiDocRenderer, Title: "POS API", Spec: spec})
+
+	if w := swaggerRequest(r, "/swagger/index.html", false); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "swagger-ui") {
+		t.Errorf("Expected Swagger UI, got %d", w.Code)
+	}
+	if w := swaggerRequest(r, "/redoc/index.html", false); !strings.Contains(w.Body.String(), `<redoc spec-url="doc.json">`) || !strings.Contains(w.Body.String(), redocScriptURL) {
+		t.Errorf("Expected ReDoc, got %s", w.Body.String())
+	}
+	if w := swaggerRequest(r, "/rapidoc/", false); !strings.Contains(w.Body.String(), "<rapi-doc") || !strings.Contains(w.Body.String(), "<title>POS API</title>") {
+		t.Errorf("Expected RapiDoc, got %s", w.Body.String())
+	}
+	if w := swaggerRequest(r, "/redoc/missing.js", false); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+	for _, path := range []string{"/swagger/doc.json", "/redoc/doc.json"} {
+		if w := swaggerRequest(r, path, false); w.Body.String() != string(spec) {
+			t.Errorf("%s: expected the spec, got %s", path, w.Body.String())
+		}
+	}
+}
+
+func TestSetupSwaggerProtected(t *testing.T) {
+	r := New()
+	SetupSwaggerWithConfig(r, "/docs", &SwaggerConfig{
+		Renderer:   ReDocRenderer,
+		Middleware: []HandlerFunc{BasicAuth(Accounts{"dev": "secret"})},
+	})
+	if w := swaggerRequest(r, "/docs/index.html", false); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %d", w.Code)
+	}
+	if w := swaggerRequest(r, "/docs/index.html", true); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 with credentials, got %d", w.Code)
+	}
+}
+
+func TestSetupSwaggerDisabled(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+	t.Setenv("TEST_DISABLE_DOCS", "1")
+
+	r := New()
+	SetupSwaggerWithConfig(r, "/release", &SwaggerConfig{DisableInRelease: true})
+	SetupSwaggerWithConfig(r, "/env", &SwaggerConfig{DisableEnv: "TEST_DISABLE_DOCS"})
+	SetupSwaggerWithConfig(r, "/enabled", &SwaggerConfig{DisableEnv: "TEST_DOCS_UNSET", Spec: []byte("{}")})
+	for path, want := range map[string]int{
+		"/release/index.html": http.StatusNotFound,
+		"/env/index.html":     http.StatusNotFound,
+		"/enabled/doc.json":   http.StatusOK,
+	} {
+		if w := swaggerRequest(r, path, false); w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestSetupSwaggerSpecs(t *testing.T) {
+	r := New()
+	SetupSwaggerSpecs(r, "/docs", &SwaggerConfig{Renderer: RapiDocRenderer},
+		SwaggerSpec{Name: "v1", Spec: []byte(`{"info":{"title":"v1"}}`)},
+		SwaggerSpec{Name: "v2"},
+	)
+	if w := swaggerRequest(r, "/docs", false); !strings.Contains(w.Body.String(), `href="v1/index.html"`) || !strings.Contains(w.Body.String(), `href="v2/index.html"`) {
+		t.Errorf("Expected links to both specs, got %s", w.Body.String())
+	}
+	if w := swaggerRequest(r, "/docs/v1/doc.json", false); !strings.Contains(w.Body.String(), `"v1"`) {
+		t.Errorf("Expected the v1 spec, got %s", w.Body.String())
+	}
+	if w := swaggerRequest(r, "/docs/v2/doc.json", false); !strings.Contains(w.Body.String(), `"v2"`) {
+		t.Errorf("Expected the swag v2 instance, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,701 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SpecViolation describes how a request breaks the API specification
+type SpecViolation struct {
+	// In is where the value is: "path", "query", "header", "cookie",
+	// "formData" or "body"
+	In string `json:"in"`
+
+	// Name is the parameter name, or the path of a body field, e.g.
+	// "items[0].quantity"
+	Name string `json:"name,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// SwaggerValidatorConfig defines the config for SwaggerValidator middleware
+type SwaggerValidatorConfig struct {
+	// Spec is the swagger.json generated by swag, e.g.
+	// []byte(docs.SwaggerInfo.ReadDoc()). OpenAPI 3 documents work too.
+	Spec []byte
+
+	// RejectUndocumented answers 404 to routes the spec doesn't describe
+	// Default: false, they pass through unvalidated
+	RejectUndocumented bool
+
+	// SkipPaths are not validated, e.g. "/health"
+	// Optional.
+	SkipPaths []string
+
+	// ErrorHandler writes the response for requests that don't conform
+	// Default: 400 with the violations as "errors"
+	ErrorHandler func(c *Context, violations []SpecViolation)
+}
+
+// SwaggerValidator returns a middleware rejecting requests whose
+// parameters or JSON body don't conform to spec, before they reach
+// handlers. It panics if spec can't be parsed.
+//
+//	r := goTap.New()
+//	r.Use(goTap.SwaggerValidator([]byte(docs.SwaggerInfo.ReadDoc())))
+//	r.GET("/products", listProducts) // @Param page query int false "Page" minimum(1)
+//
+// A request for /products?page=0 gets:
+//
+//	{"error": "Bad Request", "message": "Request does not match the API specification",
+//	 "errors": [{"in": "query", "name": "page", "message": "must be at least 1"}]}
+//
+// Operations are found by the route, so the middleware must be added with
+// Engine.Use or RouterGroup.Use.
+func SwaggerValidator(spec []byte) HandlerFunc {
+	return SwaggerValidatorWithConfig(SwaggerValidatorConfig{Spec: spec})
+}
+
+// SwaggerValidatorWithConfig returns a SwaggerValidator middleware with
+// config
+func SwaggerValidatorWithConfig(config SwaggerValidatorConfig) HandlerFunc {
+	v, err := newSpecValidator(config.Spec)
+	if err != nil {
+		panic(err)
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *Context, violations []SpecViolation) {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "Bad Request",
+				"message": "Request does not match the API specification",
+				"errors":  violations,
+			})
+			c.Abort()
+		}
+	}
+
+	return func(c *Context) {
+		if containsString(config.SkipPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		item, op := v.operation(c)
+		if op == nil {
+			if config.RejectUndocumented && c.FullPath() != "" {
+				status, message := http.StatusNotFound, "Route is not documented"
+				if item != nil {
+					status, message = http.StatusMethodNotAllowed, "Method is not documented"
+				}
+				c.JSON(status, H{
+					"error":   http.StatusText(status),
+					"message": message,
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if violations := v.validate(c, item, op); len(violations) > 0 {
+			config.ErrorHandler(c, violations)
+			return
+		}
+		c.Next()
+	}
+}
+
+// specSchema is the subset of a JSON schema that is validated. In Swagger
+// 2 non-body parameters carry these fields inline.
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Format               string                 `json:"format"`
+	Enum                 []any                  `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*specSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *specSchema            `json:"items"`
+	AllOf                []*specSchema          `json:"allOf"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	Pattern              string                 `json:"pattern"`
+	Nullable             bool                   `json:"nullable"`
+	XNullable            bool                   `json:"x-nullable"`
+	CollectionFormat     string                 `json:"collectionFormat"`
+}
+
+type specParameter struct {
+	specSchema
+	Ref      string      `json:"$ref"`
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   *specSchema `json:"schema"`
+	Explode  *bool       `json:"explode"`
+}
+
+type specRequestBody struct {
+	Ref      string `json:"$ref"`
+	Required bool   `json:"required"`
+	Content  map[string]struct {
+		Schema *specSchema `json:"schema"`
+	} `json:"content"`
+}
+
+type specOperation struct {
+	Parameters  []*specParameter `json:"parameters"`
+	RequestBody *specRequestBody `json:"requestBody"`
+}
+
+type specPathItem struct {
+	parameters []*specParameter
+	operations map[string]*specOperation
+
+	// pathParams are the names of the path parameters in order
+	pathParams []string
+}
+
+type specDocument struct {
+	BasePath    string                    `json:"basePath"`
+	Paths       map[string]map[string]any `json:"paths"`
+	Definitions map[string]*specSchema    `json:"definitions"`
+	Parameters  map[string]*specParameter `json:"parameters"`
+	Servers     []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Components struct {
+		Schemas       map[string]*specSchema      `json:"schemas"`
+		Parameters    map[string]*specParameter   `json:"parameters"`
+		RequestBodies map[string]*specRequestBody `json:"requestBodies"`
+	} `json:"components"`
+}
+
+// specMethods are the operation keys of a path item
+var specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type specValidator struct {
+	doc      specDocument
+	paths    map[string]*specPathItem
+	patterns sync.Map // string -> *regexp.Regexp
+}
+
+func newSpecValidator(spec []byte) (*specValidator, error) {
+	v := &specValidator{paths: make(map[string]*specPathItem)}
+	if err := decodeSpecJSON(spec, &v.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse API specification: %w", err)
+	}
+
+	basePath := strings.TrimSuffix(v.doc.BasePath, "/")
+	if len(v.doc.Servers) > 0 {
+		if u, err := url.Parse(v.doc.Servers[0].URL); err == nil {
+			basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	for path, raw := range v.doc.Paths {
+		item := &specPathItem{operations: make(map[string]*specOperation)}
+		for key, value := range raw {
+			data, _ := json.Marshal(value)
+			if key == "parameters" {
+				if err := decodeSpecJSON(data, &item.parameters); err != nil {
+					return nil, fmt.Errorf("path %s: %w", path, err)
+				}
+				continue
+			}
+			if !containsString(specMethods, key) {
+				continue
+			}
+			var op specOperation
+			if err := decodeSpecJSON(data, &op); err != nil {
+				return nil, fmt.Errorf("path %s %s: %w", path, key, err)
+			}
+			item.operations[strings.ToLower(key)] = &op
+		}
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				item.pathParams = append(item.pathParams, segment[1:len(segment)-1])
+			}
+		}
+		v.paths[routeShape(basePath+path)] = item
+	}
+	return v, nil
+}
+
+func decodeSpecJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// routeShape replaces the parameters of a spec path ("{id}") or a route
+// (":id", "*path") with "{}", so both match regardless of names
+func routeShape(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") ||
+			(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operation returns the path item and operation of the request's route
+func (v *specValidator) operation(c *Context) (*specPathItem, *specOperation) {
+	if c.FullPath() == "" {
+		return nil, nil
+	}
+	item, ok := v.paths[routeShape(c.FullPath())]
+	if !ok {
+		return nil, nil
+	}
+	return item, item.operations[strings.ToLower(c.Request.Method)]
+}
+
+func (v *specValidator) validate(c *Context, item *specPathItem, op *specOperation) []SpecViolation {
+	var violations []SpecViolation
+
+	// Operation parameters override path item parameters
+	params := make(map[string]*specParameter)
+	var order []string
+	for _, list := range [][]*specParameter{item.parameters, op.Parameters} {
+		for _, p := range list {
+			p = v.resolveParameter(p)
+			if p == nil {
+				continue
+			}
+			key := p.In + "\x00" + p.Name
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = p
+		}
+	}
+
+	var bodySchema *specSchema
+	bodyRequired := false
+	for _, key := range order {
+		p := params[key]
+		switch p.In {
+		case "body":
+			bodySchema, bodyRequired = p.Schema, p.Required
+		case "path":
+			for i, name := range item.pathParams {
+				if name == p.Name && i < len(c.Params) {
+					violations = v.validateParam(violations, p, []string{c.Params[i].Value}, true)
+				}
+			}
+		case "query":
+			values, ok := c.Request.URL.Query()[p.Name]
+			violations = v.validateParam(violations, p, values, ok)
+		case "header":
+			values := c.Request.Header.Values(p.Name)
+			violations = v.validateParam(violations, p, values, len(values) > 0)
+		case "cookie":
+			cookie, err := c.Request.Cookie(p.Name)
+			var values []string
+			if err == nil {
+				values = []string{cookie.Value}
+			}
+			violations = v.validateParam(violations, p, values, err == nil)
+		case "formData":
+			violations = v.validateFormParam(violations, c, p)
+		}
+	}
+
+	if rb := v.resolveRequestBody(op.RequestBody); rb != nil {
+		bodyRequired = rb.Required
+		for mediaType, content := range rb.Content {
+			if strings.Contains(mediaType, "json") {
+				bodySchema = content.Schema
+				break
+			}
+		}
+	}
+	if bodySchema != nil {
+		violations = v.validateBody(violations, c, bodySchema, bodyRequired)
+	}
+	return violations
+}
+
+func (v *specValidator) resolveParameter(p *specParameter) *specParameter {
+	if p.Ref == "" {
+		return p
+	}
+	name := p.Ref[strings.LastIndex(p.Ref, "/")+1:]
+	if strings.HasPrefix(p.Ref, "#/components/") {
+		return v.doc.Components.Parameters[name]
+	}
+	return v.doc.Parameters[name]
+}
+
+func (v *specValidator) resolveRequestBody(rb *specRequestBody) *specRequestBody {
+	if rb == nil || rb.Ref == "" {
+		return rb
+	}
+	return v.doc.Components.RequestBodies[rb.Ref[strings.LastIndex(rb.Ref, "/")+1:]]
+}
+
+func (v *specValidator) resolve(s *specSchema) *specSchema {
+	for depth := 0; s != nil && s.Ref != "" && depth < 32; depth++ {
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		if strings.HasPrefix(s.Ref, "#/components/") {
+			s = v.doc.Components.Schemas[name]
+		} else {
+			s = v.doc.Definitions[name]
+		}
+	}
+	return s
+}
+
+// validateParam checks the string values of a path, query, header, cookie
+// or form parameter
+func (v *specValidator) validateParam(violations []SpecViolation, p *specParameter, values []string, present bool) []SpecViolation {
+	if !present || (len(values) == 1 && values[0] == "" && p.In == "path") {
+		if p.Required {
+			violations = append(violations, SpecViolation{In: p.In, Name: p.Name, Message: "is required"})
+		}
+		return violations
+	}
+	schema := &p.specSchema
+	if p.Schema != nil {
+		schema = v.resolve(p.Schema)
+	}
+	if schema == nil {
+		return violations
+	}
+
+	if schema.Type == "array" {
+		format := p.CollectionFormat
+		if format == "" && p.Schema != nil && (p.Explode == nil || *p.Explode) && (p.In == "query" || p.In == "cookie") {
+			format = "multi"
+		}
+		var items []string
+		for _, value := range values {
+			items = append(items, splitCollection(value, format)...)
+		}
+		itemSchema := v.resolve(schema.Items)
+		arr := make([]any, 0, len(items))
+		for i, item := range items {
+			converted, ok := convertParam(itemSchema, item)
+			if !ok {
+				violations = append(violations, SpecViolation{In: p.In, Name: fmt.Sprintf("%s[%d]", p.Name, i), Message: "must be " + typeName(itemSchema.Type)})
+				continue
+			}
+			arr = append(arr, converted)
+		}
+		return v.validateSchema(violations, p.In, p.Name, schema, arr)
+	}
+
+	value, ok := convertParam(schema, values[0])
+	if !ok {
+		return append(violations, SpecViolation{In: p.In, Name: p.Name, Message: "must be " + typeName(schema.Type)})
+	}
+	return v.validateSchema(violations, p.In, p.Name, schema, value)
+}
+
+func (v *specValidator) validateFormParam(violations []SpecViolation, c *Context, p *specParameter) []SpecViolation {
+	if c.Request.Form == nil {
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			c.Request.ParseMultipartForm(32 << 20) // 32MB max memory
+		} else {
+			c.Request.ParseForm()
+		}
+	}
+	if p.Type == "file" {
+		if c.Request.MultipartForm == nil || len(c.Request.MultipartForm.File[p.Name]) == 0 {
+			if p.Required {
+				violations = append(violations, SpecViolation{In: p.In, Name: p.Name, Message: "is required"})
+			}
+		}
+		return violations
+	}
+	values, ok := c.Request.PostForm[p.Name]
+	if !ok && c.Request.MultipartForm != nil {
+		values, ok = c.Request.MultipartForm.Value[p.Name]
+	}
+	return v.validateParam(violations, p, values, ok)
+}
+
+func (v *specValidator) validateBody(violations []SpecViolation, c *Context, schema *specSchema, required bool) []SpecViolation {
+	if mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); mediaType != "" && !strings.Contains(mediaType, "json") {
+		return violations
+	}
+	body, err := c.BodyBytes()
+	if err != nil || len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			violations = append(violations, SpecViolation{In: "body", Message: "is required"})
+		}
+		return violations
+	}
+	var value any
+	if err := decodeSpecJSON(body, &value); err != nil {
+		return append(violations, SpecViolation{In: "body", Message: "must be valid JSON"})
+	}
+	return v.validateSchema(violations, "body", "", schema, value)
+}
+
+// splitCollection splits an array parameter by its Swagger 2
+// collectionFormat
+func splitCollection(value, format string) []string {
+	switch format {
+	case "multi":
+		return []string{value}
+	case "ssv":
+		return strings.Split(value, " ")
+	case "tsv":
+		return strings.Split(value, "\t")
+	case "pipes":
+		return strings.Split(value, "|")
+	}
+	return strings.Split(value, ",")
+}
+
+// convertParam converts a parameter string to the JSON value of its type
+func convertParam(schema *specSchema, s string) (any, bool) {
+	if schema == nil {
+		return s, true
+	}
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(s), true
+	case "number":
+		if f, err := strconv.ParseFloat(s, 64); err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		return json.Number(s), true
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	}
+	return s, true
+}
+
+func typeName(typ string) string {
+	switch typ {
+	case "integer":
+		return "an integer"
+	case "object", "array":
+		return "an " + typ
+	case "":
+		return "a value"
+	}
+	return "a " + typ
+}
+
+// validateSchema checks a JSON value against schema
+func (v *specValidator) validateSchema(violations []SpecViolation, in, name string, schema *specSchema, value any) []SpecViolation {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return violations
+	}
+	violation := func(format string, args ...any) {
+		violations = append(violations, SpecViolation{In: in, Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, sub := range schema.AllOf {
+		violations = v.validateSchema(violations, in, name, sub, value)
+	}
+	if value == nil {
+		if schema.Type != "" && !schema.Nullable && !schema.XNullable {
+			violation("must not be null")
+		}
+		return violations
+	}
+
+	switch schema.Type {
+	case "object":
+		if _, ok := value.(map[string]any); !ok {
+			violation("must be an object")
+			return violations
+		}
+	case "array":
+		if _, ok := value.([]any); !ok {
+			violation("must be an array")
+			return violations
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			violation("must be a string")
+			return violations
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if f, err := n.Float64(); !ok || err != nil || f != math.Trunc(f) {
+			violation("must be an integer")
+			return violations
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			violation("must be a number")
+			return violations
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			violation("must be a boolean")
+			return violations
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		allowed := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		violation("must be one of: %s", strings.Join(allowed, ", "))
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		if schema.Minimum != nil && f < *schema.Minimum {
+			violation("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			violation("must be at most %v", *schema.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			violation("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			violation("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if re := v.pattern(schema.Pattern); re != nil && !re.MatchString(value) {
+				violation("must match %s", schema.Pattern)
+			}
+		}
+		if msg := checkFormat(schema.Format, value); msg != "" {
+			violation(msg)
+		}
+	case []any:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			violation("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			violation("must have at most %d items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range value {
+				violations = v.validateSchema(violations, in, fmt.Sprintf("%s[%d]", name, i), schema.Items, item)
+			}
+		}
+	case map[string]any:
+		for _, field := range schema.Required {
+			if _, ok := value[field]; !ok {
+				violations = append(violations, SpecViolation{In: in, Name: joinSpecPath(name, field), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prop, ok := schema.Properties[key]; ok {
+				violations = v.validateSchema(violations, in, joinSpecPath(name, key), prop, value[key])
+				continue
+			}
+			switch strings.TrimSpace(string(schema.AdditionalProperties)) {
+			case "", "true":
+			case "false":
+				violations = append(violations, SpecViolation{In: in, Name: joinSpecPath(name, key), Message: "is not allowed"})
+			default:
+				var additional specSchema
+				if json.Unmarshal(schema.AdditionalProperties, &additional) == nil {
+					violations = v.validateSchema(violations, in, joinSpecPath(name, key), &additional, value[key])
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func joinSpecPath(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		if n, ok := value.(json.Number); ok {
+			if en, ok := e.(json.Number); ok {
+				a, _ := n.Float64()
+				b, _ := en.Float64()
+				if a == b {
+					return true
+				}
+			}
+			continue
+		}
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+var specUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkFormat returns a message if s doesn't match a known string format
+func checkFormat(format, s string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date like 2006-01-02"
+		}
+	case "uuid":
+		if !specUUIDPattern.MatchString(s) {
+			return "must be a UUID"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil {
+			return "must be an email address"
+		}
+	}
+	return ""
+}
+
+func (v *specValidator) pattern(expr string) *regexp.Regexp {
+	if re, ok := v.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		debugPrint("[WARNING] SwaggerValidator: invalid pattern %q: %v\n", expr, err)
+		return nil
+	}
+	v.patterns.Store(expr, re)
+	return re
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSwaggerSpec = `{
+	"swagger": "2.0",
+	"basePath": "/api/v1",
+	"paths": {
+		"/products": {
+			"get": {
+				"parameters": [
+					{"name": "page", "in": "query", "type": "integer", "minimum": 1},
+					{"name": "status", "in": "query", "type": "string", "enum": ["active", "archived"]},
+					{"name": "tag", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi", "maxItems": 2},
+					{"name": "X-Store-ID", "in": "header", "type": "string", "required": true, "format": "uuid"}
+				]
+			},
+			"post": {
+				"parameters": [
+					{"name": "product", "in": "body", "required": true, "schema": {"$ref": "#/definitions/main.Product"}}
+				]
+			}
+		},
+		"/products/{id}": {
+			"parameters": [{"name": "id", "in": "path", "type": "integer", "required": true}],
+			"get": {}
+		}
+	},
+	"definitions": {
+		"main.Product": {
+			"type": "object",
+			"required": ["name", "price"],
+			"additionalProperties": false,
+			"properties": {
+				"name": {"type": "string", "minLength": 2, "maxLength": 40},
+				"price": {"type": "number", "minimum": 0},
+				"sku": {"type": "string", "pattern": "^[A-Z]{2}-\\d+$"},
+				"variants": {"type": "array", "items": {"$ref": "#/definitions/main.Variant"}}
+			}
+		},
+		"main.Variant": {
+			"type": "object",
+			"properties": {"stock": {"type": "integer"}}
+		}
+	}
+}`
+
+func swaggerValidatorRouter(config SwaggerValidatorConfig) *Engine {
+	r := New()
+	r.Use(SwaggerValidatorWithConfig(config))
+	v1 := r.Group("/api/v1")
+	ok := func(c *Context) { c.String(http.StatusOK, "ok") }
+	v1.GET("/products", ok)
+	v1.POST("/products", ok)
+	v1.GET("/products/:productID", ok)
+	v1.DELETE("/products/:productID", ok)
+	r.GET("/health", ok)
+	return r
+}
+
+func TestSwaggerValidator(t *testing.T) {
+	r := swaggerValidatorRouter(SwaggerValidatorConfig{Spec: []byte(testSwaggerSpec)})
+	storeID := "0b5e8a52-7f3c-4c1a-9b2e-3d6f1a2b4c5d"
+
+	tests := []struct {
+		method, path, body string
+		header             bool
+		want               []SpecViolation
+	}{
+		{"GET", "/api/v1/products?page=2&status=active&tag=a&tag=b", "", true, nil},
+		{"GET", "/api/v1/products", "", false, []SpecViolation{{"header", "X-Store-ID", "is required"}}},
+		{"GET", "/api/v1/products?page=0&status=deleted&tag=a&tag=b&tag=c", "", true, []SpecViolation{
+			{"query", "page", "must be at least 1"},
+			{"query", "status", "must be one of: active, archived"},
+			{"query", "tag", "must have at most 2 items"},
+		}},
+		{"GET", "/api/v1/products?page=two", "", true, []SpecViolation{{"query", "page", "must be an integer"}}},
+		{"GET", "/api/v1/products/42", "", false, nil},
+		{"GET", "/api/v1/products/abc", "", false, []SpecViolation{{"path", "id", "must be an integer"}}},
+		{"DELETE", "/api/v1/products/abc", "", false, nil},
+		{"GET", "/health", "", false, nil},
+		{"POST", "/api/v1/products", `{"name": "Flat white", "price": 4.5, "sku": "FW-1", "variants": [{"stock": 3}]}`, false, nil},
+		{"POST", "/api/v1/products", "", false, []SpecViolation{{In: "body", Message: "is required"}}},
+		{"POST", "/api/v1/products", `{"name": "F`, false, []SpecViolation{{In: "body", Message: "must be valid JSON"}}},
+		{"POST", "/api/v1/products", `[]`, false, []SpecViolation{{In: "body", Message: "must be an object"}}},
+		{"POST", "/api/v1/products", `{"name": "F", "sku": "fw1", "color": "red", "variants": [{"stock": 1.5}]}`, false, []SpecViolation{
+			{"body", "price", "is required"},
+			{"body", "color", "is not allowed"},
+			{"body", "name", "must be at least 2 characters"},
+			{"body", "sku", `must match ^[A-Z]{2}-\d+$`},
+			{"body", "variants[0].stock", "must be an integer"},
+		}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.header {
+			req.Header.Set("X-Store-ID", storeID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if tt.want == nil {
+			if w.Code != http.StatusOK {
+				t.Errorf("%s %s: expected 200, got %d %s", tt.method, tt.path, w.Code, w.Body.String())
+			}
+			continue
+		}
+		var resp struct {
+			Errors []SpecViolation `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || len(resp.Errors) != len(tt.want) {
+			t.Errorf("%s %s: expected %v, got %d %s", tt.method, tt.path, tt.want, w.Code, w.Body.String())
+			continue
+		}
+		for i, v := range resp.Errors {
+			if v != tt.want[i] {
+				t.Errorf("%s %s: violation %d = %+v, want %+v", tt.method, tt.path, i, v, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSwaggerValidatorUndocumented(t *testing.T) {
+	r := swaggerValidatorRouter(SwaggerValidatorConfig{
+		Spec:               []byte(testSwaggerSpec),
+		RejectUndocumented: true,
+		SkipPaths:          []string{"/health"},
+	})
+	for path, want := range map[string]int{
+		"DELETE /api/v1/products/1": http.StatusMethodNotAllowed,
+		"GET /health":               http.StatusOK,
+		"GET /api/v1/products/1":    http.StatusOK,
+	} {
+		method, target, _ := strings.Cut(path, " ")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestSwaggerValidatorOpenAPI3(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.3",
+		"servers": [{"url": "https://api.example.com/v2"}],
+		"paths": {
+			"/orders": {
+				"summary": "Orders",
+				"post": {
+					"parameters": [{"name": "ids", "in": "query", "schema": {"type": "array", "items": {"type": "integer"}}}],
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Order"}}}}
+				}
+			}
+		},
+		"components": {"schemas": {"Order": {"type": "object", "required": ["total"], "properties": {"total": {"type": "integer", "nullable": true}}}}}
+	}`
+	r := New()
+	r.Use(SwaggerValidator([]byte(spec)))
+	r.POST("/v2/orders", func(c *Context) {
+		var order map[string]any
+		c.ShouldBindJSON(&order)
+		c.JSON(http.StatusOK, order)
+	})
+
+	request := func(target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", target, strings.NewReader(body)))
+		return w
+	}
+	if w := request("/v2/orders?ids=1&ids=2", `{"total": null}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":null`) {
+		t.Errorf("Expected a valid order to reach the handler with its body, got %d %s", w.Code, w.Body.String())
+	}
+	if w := request("/v2/orders?ids=x", `{}`); w.Code != http.StatusBadRequest ||
+		!strings.Contains(w.Body.String(), `"name":"ids[0]"`) || !strings.Contains(w.Body.String(), `"name":"total"`) {
+		t.Errorf("Expected violations for ids and total, got %d %s", w.Code, w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid spec to panic")
+		}
+	}()
+	SwaggerValidator([]byte("{"))
+}
@@ -0,0 +1,370 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownTenant should be returned by a tenant resolver when the tenant
+// does not exist. TenantDB responds 404 instead of 503 for it.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// ErrInvalidTenant is returned by TenantDBPool.Get for a tenant ID rejected
+// by ValidateTenant. TenantDB responds 400 for it.
+var ErrInvalidTenant = errors.New("invalid tenant ID")
+
+// TenantDBConfig holds configuration for the TenantDB middleware
+type TenantDBConfig struct {
+	// Resolver opens the database for a tenant. It is called lazily, once per
+	// tenant, the first time a request for that tenant arrives. The tenant ID
+	// comes from the client: look its DSN up in your own tenant registry
+	// rather than building one from the ID, and return ErrUnknownTenant for
+	// tenants that don't exist.
+	Resolver func(tenant string) (*DB, error)
+
+	// TenantFunc extracts the tenant ID from the request
+	// Default: the X-Tenant-ID header
+	TenantFunc func(*Context) string
+
+	// ValidateTenant reports whether a tenant ID is acceptable before it
+	// reaches the Resolver, e.g. by checking an allow-list
+	// Default: 1 to 64 ASCII letters, digits, '_' or '-'
+	ValidateTenant func(tenant string) bool
+
+	// MaxTenants limits the number of cached tenant connection pools. When
+	// a new tenant arrives at the limit, the pool of the least recently used
+	// tenant is closed once its in-flight requests complete.
+	// Default: 100
+	MaxTenants int
+
+	// MaxOpenConns limits open connections per tenant
+	// Default: 10
+	MaxOpenConns int
+
+	// MaxIdleConns limits idle connections per tenant
+	// Default: 2
+	MaxIdleConns int
+
+	// ConnMaxLifetime limits how long a tenant connection is reused
+	// Default: 1 hour
+	ConnMaxLifetime time.Duration
+}
+
+// TenantDBPool lazily opens and caches one connection pool per tenant
+type TenantDBPool struct {
+	config TenantDBConfig
+
+	mu    sync.Mutex
+	conns map[string]*tenantConn
+}
+
+type tenantConn struct {
+	ready chan struct{}
+	db    *DB
+	err   error
+
+	// Guarded by TenantDBPool.mu
+	opened   bool
+	lastUsed time.Time
+	active   int
+	evicted  bool
+}
+
+// NewTenantDBPool creates a tenant connection pool
+func NewTenantDBPool(config TenantDBConfig) *TenantDBPool {
+	if config.Resolver == nil {
+		panic("goTap: TenantDB requires a Resolver")
+	}
+	if config.TenantFunc == nil {
+		config.TenantFunc = func(c *Context) string {
+			return c.GetHeader("X-Tenant-ID")
+		}
+	}
+	if config.ValidateTenant == nil {
+		config.ValidateTenant = validTenantID
+	}
+	if config.MaxTenants <= 0 {
+		config.MaxTenants = 100
+	}
+	if config.MaxOpenConns <= 0 {
+		config.MaxOpenConns = 10
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = 2
+	}
+	if config.ConnMaxLifetime <= 0 {
+		config.ConnMaxLifetime = time.Hour
+	}
+
+	return &TenantDBPool{
+		config: config,
+		conns:  make(map[string]*tenantConn),
+	}
+}
+
+// validTenantID accepts 1 to 64 ASCII letters, digits, '_' or '-'
+func validTenantID(tenant string) bool {
+	if len(tenant) == 0 || len(tenant) > 64 {
+		return false
+	}
+	for i := 0; i < len(tenant); i++ {
+		switch ch := tenant[i]; {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '_', ch == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the database for tenant, opening it on first use.
+// Concurrent callers for the same tenant share a single Resolver call,
+// and a failed open is retried on the next call. The returned database
+// may be closed when the tenant is evicted, see MaxTenants; Middleware
+// keeps it open for the duration of the request.
+func (p *TenantDBPool) Get(tenant string) (*DB, error) {
+	conn, err := p.acquire(tenant)
+	if err != nil {
+		return nil, err
+	}
+	p.release(conn)
+	return conn.db, nil
+}
+
+// acquire returns the connection pool of tenant, opened and marked in use
+// until release
+func (p *TenantDBPool) acquire(tenant string) (*tenantConn, error) {
+	if !p.config.ValidateTenant(tenant) {
+		return nil, ErrInvalidTenant
+	}
+
+	var evicted *tenantConn
+	p.mu.Lock()
+	conn, ok := p.conns[tenant]
+	if !ok {
+		evicted = p.evictLocked()
+		conn = &tenantConn{ready: make(chan struct{})}
+		p.conns[tenant] = conn
+	}
+	conn.active++
+	conn.lastUsed = time.Now()
+	p.mu.Unlock()
+
+	if evicted != nil {
+		if err := closeTenantConn(evicted); err != nil {
+			debugPrint("[WARNING] closing evicted tenant database: %v", err)
+		}
+	}
+
+	if ok {
+		<-conn.ready
+	} else {
+		conn.db, conn.err = p.open(tenant)
+		p.mu.Lock()
+		conn.opened = true
+		if conn.err != nil && p.conns[tenant] == conn {
+			delete(p.conns, tenant)
+		}
+		p.mu.Unlock()
+		close(conn.ready)
+	}
+
+	if conn.err != nil {
+		p.release(conn)
+		return nil, conn.err
+	}
+	return conn, nil
+}
+
+// release marks conn no longer in use, closing it if it was evicted
+func (p *TenantDBPool) release(conn *tenantConn) {
+	p.mu.Lock()
+	conn.active--
+	closeNow := conn.evicted && conn.active == 0
+	p.mu.Unlock()
+
+	if closeNow {
+		if err := closeTenantConn(conn); err != nil {
+			debugPrint("[WARNING] closing evicted tenant database: %v", err)
+		}
+	}
+}
+
+// evictLocked removes the least recently used opened tenant when the pool
+// is full, returning it if it can be closed right away
+func (p *TenantDBPool) evictLocked() *tenantConn {
+	if len(p.conns) < p.config.MaxTenants {
+		return nil
+	}
+
+	var (
+		lru    string
+		oldest *tenantConn
+	)
+	for tenant, conn := range p.conns {
+		if conn.opened && (oldest == nil || conn.lastUsed.Before(oldest.lastUsed)) {
+			lru, oldest = tenant, conn
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	delete(p.conns, lru)
+	oldest.evicted = true
+	if oldest.active > 0 {
+		return nil
+	}
+	return oldest
+}
+
+func (p *TenantDBPool) open(tenant string) (*DB, error) {
+	db, err := p.config.Resolver(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if db == nil {
+		return nil, ErrUnknownTenant
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(p.config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(p.config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(p.config.ConnMaxLifetime)
+	return db, nil
+}
+
+// Tenants returns the IDs of the tenants with an open connection pool
+func (p *TenantDBPool) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tenants := make([]string, 0, len(p.conns))
+	for tenant := range p.conns {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Close closes the connection pool of a single tenant
+func (p *TenantDBPool) Close(tenant string) error {
+	p.mu.Lock()
+	conn, ok := p.conns[tenant]
+	delete(p.conns, tenant)
+	p.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	<-conn.ready
+	return closeTenantConn(conn)
+}
+
+// CloseAll closes every tenant connection pool
+func (p *TenantDBPool) CloseAll() error {
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = make(map[string]*tenantConn)
+	p.mu.Unlock()
+
+	var errs []error
+	for _, conn := range conns {
+		<-conn.ready
+		if err := closeTenantConn(conn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func closeTenantConn(conn *tenantConn) error {
+	if conn.db == nil {
+		return nil
+	}
+	sqlDB, err := conn.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Middleware returns a middleware that injects the tenant's database as the
+// request's GORM instance, so MustGetGorm and Resource use it transparently
+func (p *TenantDBPool) Middleware() HandlerFunc {
+	return func(c *Context) {
+		tenant := p.config.TenantFunc(c)
+		if tenant == "" {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "Bad Request",
+				"message": "Tenant not specified",
+			})
+			c.Abort()
+			return
+		}
+
+		conn, err := p.acquire(tenant)
+		if err != nil {
+			if errors.Is(err, ErrInvalidTenant) {
+				c.JSON(http.StatusBadRequest, H{
+					"error":   "Bad Request",
+					"message": "Invalid tenant",
+				})
+			} else if errors.Is(err, ErrUnknownTenant) {
+				c.JSON(http.StatusNotFound, H{
+					"error":   "Not Found",
+					"message": "Unknown tenant",
+				})
+			} else {
+				debugPrint("[WARNING] tenant %q database unavailable: %v", tenant, err)
+				c.JSON(http.StatusServiceUnavailable, H{
+					"error":   "Service Unavailable",
+					"message": "Tenant database unavailable",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		defer p.release(conn)
+
+		c.Set("tenant", tenant)
+		c.Set("gorm", conn.db)
+		Provide(c, conn.db)
+		c.Next()
+	}
+}
+
+// TenantDB returns a middleware that routes each request to its tenant's
+// database, opening tenant connections lazily:
+//
+//	r.Use(goTap.TenantDB(func(tenant string) (*goTap.DB, error) {
+//	    // tenantDSNs is loaded from the tenant registry, never built from the ID
+//	    dsn, ok := tenantDSNs[tenant]
+//	    if !ok {
+//	        return nil, goTap.ErrUnknownTenant
+//	    }
+//	    return goTap.NewGormDB(&goTap.DBConfig{Driver: "postgres", DSN: dsn})
+//	}))
+func TenantDB(resolver func(tenant string) (*DB, error)) HandlerFunc {
+	return TenantDBWithConfig(TenantDBConfig{Resolver: resolver})
+}
+
+// TenantDBWithConfig returns a TenantDB middleware with config
+func TenantDBWithConfig(config TenantDBConfig) HandlerFunc {
+	return NewTenantDBPool(config).Middleware()
+}
+
+// GetTenant retrieves the tenant ID set by TenantDB from context
+func GetTenant(c *Context) (string, bool) {
+	return Get[string](c, "tenant")
+}
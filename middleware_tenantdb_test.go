@@ -0,0 +1,215 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+type tenantNote struct {
+	ID   uint
+	Text string
+}
+
+func tenantResolver(opened *int32) func(string) (*DB, error) {
+	return func(tenant string) (*DB, error) {
+		if tenant == "ghost" {
+			return nil, ErrUnknownTenant
+		}
+		if tenant == "broken" {
+			return nil, errors.New("connection refused")
+		}
+		atomic.AddInt32(opened, 1)
+		db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+		if err != nil {
+			return nil, err
+		}
+		return db, db.AutoMigrate(&tenantNote{})
+	}
+}
+
+func TestTenantDBIsolation(t *testing.T) {
+	var opened int32
+	pool := NewTenantDBPool(TenantDBConfig{Resolver: tenantResolver(&opened), MaxOpenConns: 1})
+	defer pool.CloseAll()
+
+	r := New()
+	r.Use(pool.Middleware())
+	r.POST("/notes", func(c *Context) {
+		MustGetGorm(c).Create(&tenantNote{Text: c.Query("text")})
+		c.Status(201)
+	})
+	r.GET("/notes", func(c *Context) {
+		var count int64
+		MustGetGorm(c).Model(&tenantNote{}).Count(&count)
+		tenant, _ := GetTenant(c)
+		c.JSON(200, H{"tenant": tenant, "count": count})
+	})
+
+	do := func(method, tenant, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	do("POST", "acme", "/notes?text=a")
+	do("POST", "acme", "/notes?text=b")
+	do("POST", "globex", "/notes?text=c")
+
+	if body := do("GET", "acme", "/notes").Body.String(); body != "{\"count\":2,\"tenant\":\"acme\"}\n" {
+		t.Errorf("Unexpected acme response %s", body)
+	}
+	if body := do("GET", "globex", "/notes").Body.String(); body != "{\"count\":1,\"tenant\":\"globex\"}\n" {
+		t.Errorf("Unexpected globex response %s", body)
+	}
+	if opened != 2 {
+		t.Errorf("Expected 2 lazily opened tenants, got %d", opened)
+	}
+
+	db, _ := pool.Get("acme")
+	sqlDB, _ := db.DB()
+	if max := sqlDB.Stats().MaxOpenConnections; max != 1 {
+		t.Errorf("Expected per-tenant MaxOpenConns 1, got %d", max)
+	}
+
+	if w := do("GET", "", "/notes"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without tenant, got %d", w.Code)
+	}
+	if w := do("GET", "ghost", "/notes"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown tenant, got %d", w.Code)
+	}
+	if w := do("GET", "broken", "/notes"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for failing tenant, got %d", w.Code)
+	}
+	if w := do("GET", "acme' search_path=public", "/notes"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid tenant ID, got %d", w.Code)
+	}
+	if w := do("GET", strings.Repeat("a", 65), "/notes"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a too long tenant ID, got %d", w.Code)
+	}
+	if opened != 2 {
+		t.Errorf("Expected invalid tenants not to be resolved, got %d opened", opened)
+	}
+}
+
+func TestTenantDBValidateTenant(t *testing.T) {
+	var opened int32
+	pool := NewTenantDBPool(TenantDBConfig{
+		Resolver: tenantResolver(&opened),
+		ValidateTenant: func(tenant string) bool {
+			return tenant == "acme"
+		},
+	})
+	defer pool.CloseAll()
+
+	if _, err := pool.Get("acme"); err != nil {
+		t.Errorf("Get(acme) failed: %v", err)
+	}
+	if _, err := pool.Get("globex"); !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("Expected ErrInvalidTenant, got %v", err)
+	}
+}
+
+func TestTenantDBPoolEviction(t *testing.T) {
+	var opened int32
+	pool := NewTenantDBPool(TenantDBConfig{Resolver: tenantResolver(&opened), MaxTenants: 2})
+	defer pool.CloseAll()
+
+	// A request for globex is in flight while other tenants are used
+	r := New()
+	r.Use(pool.Middleware())
+	inFlight := make(chan *DB)
+	done := make(chan struct{})
+	r.GET("/notes", func(c *Context) {
+		inFlight <- MustGetGorm(c)
+		<-done
+		var count int64
+		if err := MustGetGorm(c).Model(&tenantNote{}).Count(&count).Error; err != nil {
+			t.Errorf("Evicted database closed during the request: %v", err)
+		}
+	})
+	go func() {
+		req, _ := http.NewRequest("GET", "/notes", nil)
+		req.Header.Set("X-Tenant-ID", "globex")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		close(inFlight)
+	}()
+	globex := <-inFlight
+
+	time.Sleep(time.Millisecond)
+	acme, _ := pool.Get("acme")
+	time.Sleep(time.Millisecond)
+	pool.Get("initech") // evicts globex, the least recently used
+	if tenants := pool.Tenants(); len(tenants) != 2 || tenants[0] != "acme" || tenants[1] != "initech" {
+		t.Errorf("Expected [acme initech], got %v", tenants)
+	}
+	if err := mustSQLDB(t, globex).Ping(); err != nil {
+		t.Errorf("Expected globex to stay open during its request: %v", err)
+	}
+
+	close(done)
+	<-inFlight
+	if err := mustSQLDB(t, globex).Ping(); err == nil {
+		t.Error("Expected the evicted database to be closed after its request")
+	}
+	if err := mustSQLDB(t, acme).Ping(); err != nil {
+		t.Errorf("Expected acme to stay open: %v", err)
+	}
+
+	pool.Get("globex")
+	if opened != 4 {
+		t.Errorf("Expected globex to be reopened, got %d opens", opened)
+	}
+}
+
+func mustSQLDB(t *testing.T, db *DB) *sql.DB {
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sqlDB
+}
+
+func TestTenantDBPoolConcurrentOpen(t *testing.T) {
+	var opened int32
+	pool := NewTenantDBPool(TenantDBConfig{Resolver: tenantResolver(&opened)})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.Get("acme"); err != nil {
+				t.Errorf("Get failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if opened != 1 {
+		t.Errorf("Expected a single resolver call, got %d", opened)
+	}
+	if tenants := pool.Tenants(); len(tenants) != 1 || tenants[0] != "acme" {
+		t.Errorf("Expected [acme], got %v", tenants)
+	}
+	if err := pool.Close("acme"); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if len(pool.Tenants()) != 0 {
+		t.Error("Expected no tenants after Close")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// routeLimits records the limits a route declares itself, so global
+// Timeout and BodyLimit middleware step aside for them
+type routeLimits struct {
+	timeout   time.Duration
+	bodyLimit string
+}
+
+// declaredLimits returns the limits declared by the matched route
+func (c *Context) declaredLimits() routeLimits {
+	if c.engine == nil {
+		return routeLimits{}
+	}
+	return c.engine.routeMeta[c.Request.Method+" "+c.FullPath()].limits
+}
+
+// Timeout returns a middleware that sets a deadline on the request context.
+// Handlers observe it through c.Request.Context(), e.g. in database and
+// HTTP calls. If the deadline passes before anything was written, the
+// response is 503.
+//
+// Used globally or on a group, it is skipped for routes declaring their own
+// timeout with RouterGroup.Timeout, so they can get a longer budget.
+func Timeout(timeout time.Duration) HandlerFunc {
+	return timeoutMiddleware(timeout, false)
+}
+
+// Timeout returns a group whose routes declare their own timeout, replacing
+// a global Timeout middleware:
+//
+//	r.Use(goTap.Timeout(10 * time.Second))
+//	r.Timeout(2*time.Second).POST("/payments/capture", capturePayment)
+//	r.Timeout(2*time.Minute).POST("/reports", buildReport)
+func (group *RouterGroup) Timeout(timeout time.Duration) *RouterGroup {
+	child := group.Group("", timeoutMiddleware(timeout, true))
+	child.limits.timeout = timeout
+	return child
+}
+
+func timeoutMiddleware(timeout time.Duration, declared bool) HandlerFunc {
+	if timeout <= 0 {
+		panic("goTap: Timeout must be positive")
+	}
+	return func(c *Context) {
+		if !declared && c.declaredLimits().timeout > 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.JSON(503, H{
+				"error":   "Service Unavailable",
+				"message": "request timed out",
+			})
+			c.Abort()
+		}
+	}
+}
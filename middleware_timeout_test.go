@@ -0,0 +1,42 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	r := New()
+	r.Use(Timeout(20 * time.Millisecond))
+
+	// waitDone blocks until the request deadline and reports the budget
+	waitDone := func(c *Context) {
+		deadline, _ := c.Request.Context().Deadline()
+		budget := time.Until(deadline)
+		<-c.Request.Context().Done()
+		if budget > 30*time.Millisecond {
+			c.String(200, "long")
+		}
+	}
+	r.GET("/default", waitDone)
+	r.Timeout(5*time.Millisecond).GET("/capture", waitDone)
+	r.Timeout(50*time.Millisecond).GET("/report", waitDone)
+	r.GET("/fast", func(c *Context) { c.String(200, "ok") })
+
+	for path, want := range map[string]int{"/default": 503, "/capture": 503, "/report": 200, "/fast": 200} {
+		w := httptest.NewRecorder()
+		start := time.Now()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != want {
+			t.Errorf("GET %s: expected %d, got %d", path, want, w.Code)
+		}
+		if path == "/capture" && time.Since(start) > 15*time.Millisecond {
+			t.Errorf("Expected route timeout to be tighter than the global one, took %v", time.Since(start))
+		}
+	}
+}
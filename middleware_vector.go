@@ -1,10 +1,12 @@
 package goTap
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"time"
 )
 
@@ -83,13 +85,9 @@ func (s *InMemoryVectorStore) Search(ctx context.Context, queryVector Vector, li
 	}
 
 	// Sort by score descending
-	for i := 0; i < len(results); i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[j].Score > results[i].Score {
-				results[i], results[j] = results[j], results[i]
-			}
-		}
-	}
+	slices.SortFunc(results, func(a, b *VectorSearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
 
 	// Return top results
 	if limit > len(results) {
@@ -111,7 +109,7 @@ func (s *InMemoryVectorStore) Delete(ctx context.Context, ids []string) error {
 func (s *InMemoryVectorStore) Get(ctx context.Context, id string) (*VectorDocument, error) {
 	doc, exists := s.vectors[id]
 	if !exists {
-		return nil, fmt.Errorf("vector not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, id)
 	}
 	return doc, nil
 }
@@ -119,7 +117,7 @@ func (s *InMemoryVectorStore) Get(ctx context.Context, id string) (*VectorDocume
 // Update updates a vector
 func (s *InMemoryVectorStore) Update(ctx context.Context, document *VectorDocument) error {
 	if _, exists := s.vectors[document.ID]; !exists {
-		return fmt.Errorf("vector not found: %s", document.ID)
+		return fmt.Errorf("%w: %s", ErrVectorNotFound, document.ID)
 	}
 	s.vectors[document.ID] = document
 	return nil
@@ -176,20 +174,12 @@ func DotProduct(a, b Vector) float32 {
 
 // VectorInject injects vector store into context for use in handlers
 func VectorInject(store VectorStore) HandlerFunc {
-	return func(c *Context) {
-		c.Set("vectorstore", store)
-		c.Next()
-	}
+	return Inject("vectorstore", store)
 }
 
 // GetVectorStore retrieves vector store from context
 func GetVectorStore(c *Context) (VectorStore, bool) {
-	store, exists := c.Get("vectorstore")
-	if !exists {
-		return nil, false
-	}
-	vectorStore, ok := store.(VectorStore)
-	return vectorStore, ok
+	return Get[VectorStore](c, "vectorstore")
 }
 
 // MustGetVectorStore retrieves vector store from context or panics
@@ -0,0 +1,161 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook verification errors
+var (
+	ErrMissingWebhookSignature = errors.New("missing webhook signature")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrWebhookTimestamp        = errors.New("webhook timestamp outside tolerance")
+)
+
+// DefaultWebhookTolerance is the maximum age of a timestamped webhook
+// accepted by VerifierStripe
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// WebhookVerifier checks the signature of a webhook request against its raw
+// body
+type WebhookVerifier func(req *http.Request, body []byte, secret string) error
+
+var (
+	// VerifierStripe verifies Stripe-Signature headers
+	// ("t=<unix>,v1=<hex>") with DefaultWebhookTolerance
+	VerifierStripe = StripeVerifier(DefaultWebhookTolerance)
+
+	// VerifierGitHub verifies X-Hub-Signature-256 headers
+	// ("sha256=<hex>")
+	VerifierGitHub = HMACVerifier("X-Hub-Signature-256", "sha256=", hex.DecodeString)
+
+	// VerifierShopify verifies X-Shopify-Hmac-Sha256 headers (base64)
+	VerifierShopify = HMACVerifier("X-Shopify-Hmac-Sha256", "", base64.StdEncoding.DecodeString)
+
+	// VerifierGoTap verifies X-Webhook-Signature headers sent by
+	// NewWebhookOutboxPublisher
+	VerifierGoTap = HMACVerifier("X-Webhook-Signature", "sha256=", hex.DecodeString)
+)
+
+// HMACVerifier returns a verifier for an HMAC-SHA256 of the body sent in
+// header, after prefix, in the encoding decode reverses
+func HMACVerifier(header, prefix string, decode func(string) ([]byte, error)) WebhookVerifier {
+	return func(req *http.Request, body []byte, secret string) error {
+		value := req.Header.Get(header)
+		if value == "" {
+			return ErrMissingWebhookSignature
+		}
+		if !strings.HasPrefix(value, prefix) {
+			return ErrInvalidWebhookSignature
+		}
+		sig, err := decode(strings.TrimPrefix(value, prefix))
+		if err != nil {
+			return ErrInvalidWebhookSignature
+		}
+		if !hmac.Equal(sig, webhookHMAC(secret, body)) {
+			return ErrInvalidWebhookSignature
+		}
+		return nil
+	}
+}
+
+// StripeVerifier returns a verifier for Stripe style signatures, which sign
+// "<timestamp>.<body>" and reject timestamps more than tolerance away from
+// now to prevent replays. A tolerance <= 0 disables the timestamp check.
+func StripeVerifier(tolerance time.Duration) WebhookVerifier {
+	return func(req *http.Request, body []byte, secret string) error {
+		value := req.Header.Get("Stripe-Signature")
+		if value == "" {
+			return ErrMissingWebhookSignature
+		}
+
+		var timestamp string
+		var signatures [][]byte
+		for _, part := range strings.Split(value, ",") {
+			key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = val
+			case "v1":
+				// Several v1 signatures are sent while a secret is rolled
+				if sig, err := hex.DecodeString(val); err == nil {
+					signatures = append(signatures, sig)
+				}
+			}
+		}
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || len(signatures) == 0 {
+			return ErrInvalidWebhookSignature
+		}
+
+		expected := webhookHMAC(secret, append([]byte(timestamp+"."), body...))
+		valid := false
+		for _, sig := range signatures {
+			if hmac.Equal(sig, expected) {
+				valid = true
+			}
+		}
+		if !valid {
+			return ErrInvalidWebhookSignature
+		}
+
+		if tolerance > 0 {
+			age := time.Since(time.Unix(ts, 0))
+			if math.Abs(float64(age)) > float64(tolerance) {
+				return ErrWebhookTimestamp
+			}
+		}
+		return nil
+	}
+}
+
+func webhookHMAC(secret string, data []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyWebhookSignature checks the request's webhook signature with
+// verifier. The body is captured with BodyBytes, so it can still be bound
+// afterwards, and is also available if earlier middleware already read it
+// through BodyBytes or ShouldBindBodyWith.
+func (c *Context) VerifyWebhookSignature(secret string, verifier WebhookVerifier) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return verifier(c.Request, body, secret)
+}
+
+// WebhookAuth returns a middleware rejecting webhook requests whose
+// signature doesn't verify with 401:
+//
+//	r.POST("/webhooks/stripe", goTap.WebhookAuth(secret, goTap.VerifierStripe), handleStripe)
+func WebhookAuth(secret string, verifier WebhookVerifier) HandlerFunc {
+	if secret == "" {
+		panic("goTap: WebhookAuth requires a secret")
+	}
+	return func(c *Context) {
+		if err := c.VerifyWebhookSignature(secret, verifier); err != nil {
+			c.JSON(401, H{
+				"error":   "Unauthorized",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookVerifiers(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"id":"evt_1","type":"charge.succeeded"}`)
+	mac := webhookHMAC(secret, body)
+	stripeSig := func(ts int64, s string) string {
+		sig := webhookHMAC(s, append([]byte(fmt.Sprintf("%d.", ts)), body...))
+		return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(sig))
+	}
+	now := time.Now().Unix()
+
+	for _, tc := range []struct {
+		name     string
+		verifier WebhookVerifier
+		header   string
+		value    string
+		want     error
+	}{
+		{"github", VerifierGitHub, "X-Hub-Signature-256", "sha256=" + hex.EncodeToString(mac), nil},
+		{"github wrong", VerifierGitHub, "X-Hub-Signature-256", "sha256=" + hex.EncodeToString(webhookHMAC("other", body)), ErrInvalidWebhookSignature},
+		{"github missing", VerifierGitHub, "", "", ErrMissingWebhookSignature},
+		{"shopify", VerifierShopify, "X-Shopify-Hmac-Sha256", base64.StdEncoding.EncodeToString(mac), nil},
+		{"shopify garbage", VerifierShopify, "X-Shopify-Hmac-Sha256", "%%%", ErrInvalidWebhookSignature},
+		{"stripe", VerifierStripe, "Stripe-Signature", stripeSig(now, secret), nil},
+		{"stripe rolled secret", VerifierStripe, "Stripe-Signature", stripeSig(now, secret) + ",v1=" + hex.EncodeToString(mac), nil},
+		{"stripe wrong", VerifierStripe, "Stripe-Signature", stripeSig(now, "other"), ErrInvalidWebhookSignature},
+		{"stripe replay", VerifierStripe, "Stripe-Signature", stripeSig(now-3600, secret), ErrWebhookTimestamp},
+		{"stripe no tolerance", StripeVerifier(0), "Stripe-Signature", stripeSig(now-3600, secret), nil},
+	} {
+		req := httptest.NewRequest("POST", "/", nil)
+		if tc.header != "" {
+			req.Header.Set(tc.header, tc.value)
+		}
+		if err := tc.verifier(req, body, secret); err != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestWebhookAuthKeepsBodyReadable(t *testing.T) {
+	const secret = "s3cret"
+	r := New()
+	// Earlier middleware consuming the body
+	r.Use(func(c *Context) {
+		var probe map[string]any
+		c.ShouldBindBodyWith(&probe, JSON)
+		c.Next()
+	})
+	r.POST("/webhooks", WebhookAuth(secret, VerifierGitHub), func(c *Context) {
+		var event struct {
+			ID string `json:"id"`
+		}
+		if err := c.ShouldBindJSON(&event); err != nil {
+			t.Errorf("Expected body to be readable after verification: %v", err)
+		}
+		c.String(200, event.ID)
+	})
+
+	body := `{"id":"evt_1"}`
+	send := func(sig string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature-256", sig)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("sha256=" + hex.EncodeToString(webhookHMAC(secret, []byte(body)))); w.Code != 200 || w.Body.String() != "evt_1" {
+		t.Errorf("Expected verified webhook to be handled, got %d %s", w.Code, w.Body.String())
+	}
+	if w := send("sha256=00"); w.Code != 401 || !strings.Contains(w.Body.String(), ErrInvalidWebhookSignature.Error()) {
+		t.Errorf("Expected 401 for bad signature, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestWebhookAuthVerifiesOutboxWebhooks(t *testing.T) {
+	r := New()
+	received := make(chan string, 1)
+	r.POST("/hooks", WebhookAuth("s3cret", VerifierGoTap), func(c *Context) {
+		received <- c.GetHeader("X-Webhook-Topic")
+		c.Status(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	publisher := NewWebhookOutboxPublisher(WebhookPublisherConfig{URL: srv.URL + "/hooks", Secret: "s3cret"})
+	msg := &OutboxMessage{ID: 1, Topic: "order.created", Payload: []byte(`{"id":1}`)}
+	if err := publisher.Publish(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if topic := <-received; topic != "order.created" {
+		t.Errorf("Unexpected topic %q", topic)
+	}
+}
@@ -0,0 +1,418 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration errors
+var (
+	ErrMigrationLocked   = errors.New("migrations are locked by another instance")
+	ErrNoDownMigration   = errors.New("migration has no down step")
+	ErrDuplicateVersion  = errors.New("duplicate migration version")
+	ErrUnknownMigrateCmd = errors.New("unknown migrate command")
+)
+
+// Migration is a single versioned schema change. SQL migrations are loaded
+// from files named "<version>_<name>.up.sql" and "<version>_<name>.down.sql";
+// Go migrations are added with Migrator.Register.
+type Migration struct {
+	Version int64
+	Name    string
+
+	UpSQL   string
+	DownSQL string
+
+	Up   func(tx *DB) error
+	Down func(tx *DB) error
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// MigratorConfig holds configuration for a Migrator
+type MigratorConfig struct {
+	// TableName records applied migrations
+	// Default: "schema_migrations"
+	TableName string
+
+	// LockTableName holds the lock that keeps instances from migrating concurrently
+	// Default: "schema_migrations_lock"
+	LockTableName string
+
+	// LockTimeout is how long to wait for another instance to release the lock
+	// Default: 1 minute
+	LockTimeout time.Duration
+}
+
+// Migrator applies versioned migrations to a GORM database
+type Migrator struct {
+	db         *DB
+	config     MigratorConfig
+	migrations map[int64]*Migration
+}
+
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+type schemaMigrationLock struct {
+	ID       int `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string
+	LockedAt time.Time
+}
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// NewMigrator creates a migrator loading SQL migrations from fsys, which is
+// usually an embed.FS. Files may live in any directory of fsys. fsys may be
+// nil when only Go migrations are used.
+func NewMigrator(db *DB, fsys fs.FS, config ...MigratorConfig) (*Migrator, error) {
+	cfg := MigratorConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.TableName == "" {
+		cfg.TableName = "schema_migrations"
+	}
+	if cfg.LockTableName == "" {
+		cfg.LockTableName = "schema_migrations_lock"
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+
+	m := &Migrator{
+		db:         db,
+		config:     cfg,
+		migrations: make(map[int64]*Migration),
+	}
+	if fsys == nil {
+		return m, nil
+	}
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		match := migrationFileRegexp.FindStringSubmatch(path.Base(p))
+		if match == nil {
+			return nil
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version in %s: %w", p, err)
+		}
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		mig, ok := m.migrations[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			m.migrations[version] = mig
+		} else if mig.Name != match[2] {
+			return fmt.Errorf("%w %d: %s and %s", ErrDuplicateVersion, version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.UpSQL = string(body)
+		} else {
+			mig.DownSQL = string(body)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Register adds a Go migration. down may be nil for irreversible migrations.
+func (m *Migrator) Register(version int64, name string, up, down func(tx *DB) error) error {
+	if _, exists := m.migrations[version]; exists {
+		return fmt.Errorf("%w %d", ErrDuplicateVersion, version)
+	}
+	m.migrations[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+	return nil
+}
+
+// Migrations returns all known migrations ordered by version
+func (m *Migrator) Migrations() []*Migration {
+	list := make([]*Migration, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		list = append(list, mig)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+// Up applies all pending migrations in version order. Each migration runs
+// in its own transaction together with its bookkeeping row.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(db *DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.Migrations() {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := db.Transaction(func(tx *DB) error {
+				if err := mig.run(tx, true); err != nil {
+					return err
+				}
+				return tx.Table(m.config.TableName).Create(&schemaMigration{
+					Version:   mig.Version,
+					Name:      mig.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			debugPrint("[MIGRATE] applied %d_%s", mig.Version, mig.Name)
+		}
+		return nil
+	})
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(db *DB) error {
+		var last schemaMigration
+		err := db.Table(m.config.TableName).Order("version DESC").First(&last).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		mig, ok := m.migrations[last.Version]
+		if !ok {
+			return fmt.Errorf("applied migration %d_%s is not known to this binary", last.Version, last.Name)
+		}
+		err = db.Transaction(func(tx *DB) error {
+			if err := mig.run(tx, false); err != nil {
+				return err
+			}
+			return tx.Table(m.config.TableName).Where("version = ?", mig.Version).Delete(&schemaMigration{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		debugPrint("[MIGRATE] rolled back %d_%s", mig.Version, mig.Name)
+		return nil
+	})
+}
+
+// Status reports every known or applied migration ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.Migrations() {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &row.AppliedAt
+			delete(applied, mig.Version)
+		}
+		status = append(status, s)
+	}
+	// Migrations applied by a newer binary are still reported
+	for _, row := range applied {
+		row := row
+		status = append(status, MigrationStatus{Version: row.Version, Name: row.Name, Applied: true, AppliedAt: &row.AppliedAt})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// RunCommand runs "up", "down" or "status", writing a report to w.
+// It lets a binary expose migrations as a subcommand:
+//
+//	if len(os.Args) > 2 && os.Args[1] == "migrate" {
+//	    err := migrator.RunCommand(ctx, os.Args[2], os.Stdout)
+//	}
+func (m *Migrator) RunCommand(ctx context.Context, command string, w io.Writer) error {
+	switch command {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		if err := m.Down(ctx); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("%w %q (want up, down or status)", ErrUnknownMigrateCmd, command)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range status {
+		state := "pending"
+		if s.Applied {
+			state = "applied " + s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d_%s\t%s\n", s.Version, s.Name, state)
+	}
+	return nil
+}
+
+// ForceUnlock removes a lock left behind by a crashed instance
+func (m *Migrator) ForceUnlock(ctx context.Context) error {
+	return m.db.WithContext(ctx).Table(m.config.LockTableName).Where("id = ?", 1).Delete(&schemaMigrationLock{}).Error
+}
+
+func (mig *Migration) run(tx *DB, up bool) error {
+	fn, sql := mig.Up, mig.UpSQL
+	if !up {
+		fn, sql = mig.Down, mig.DownSQL
+	}
+	switch {
+	case fn != nil:
+		return fn(tx)
+	case sql != "":
+		return tx.Exec(sql).Error
+	case up:
+		return nil
+	default:
+		return ErrNoDownMigration
+	}
+}
+
+func (m *Migrator) ensureTables(db *DB) error {
+	if err := db.Table(m.config.TableName).AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+	return db.Table(m.config.LockTableName).AutoMigrate(&schemaMigrationLock{})
+}
+
+func (m *Migrator) applied(db *DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Table(m.config.TableName).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// withLock runs fn while holding the migration lock row. The lock is a
+// single primary-key row, so only one instance can insert it at a time.
+func (m *Migrator) withLock(ctx context.Context, fn func(db *DB) error) error {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	lock := &schemaMigrationLock{ID: 1, Owner: fmt.Sprintf("%s:%d", host, os.Getpid())}
+	deadline := time.Now().Add(m.config.LockTimeout)
+	for {
+		lock.LockedAt = time.Now()
+		err := db.Table(m.config.LockTableName).Create(lock).Error
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrMigrationLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+	defer func() {
+		if err := m.ForceUnlock(context.Background()); err != nil {
+			debugPrint("[WARNING] failed to release migration lock: %v", err)
+		}
+	}()
+
+	return fn(db)
+}
+
+// Migrate applies all pending SQL migrations found in fsys:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	if err := goTap.Migrate(db, migrations); err != nil {
+//	    log.Fatal(err)
+//	}
+func Migrate(db *DB, fsys fs.FS) error {
+	m, err := NewMigrator(db, fsys)
+	if err != nil {
+		return err
+	}
+	return m.Up(context.Background())
+}
+
+// MigrationStatusHandler returns a handler reporting migration status,
+// typically mounted behind authentication at /admin/migrations
+func MigrationStatusHandler(m *Migrator) HandlerFunc {
+	return func(c *Context) {
+		status, err := m.Status(c.Request.Context())
+		if err != nil {
+			c.JSON(500, H{
+				"error":   "Internal Server Error",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		pending := 0
+		var current int64
+		for _, s := range status {
+			if s.Applied {
+				current = s.Version
+			} else {
+				pending++
+			}
+		}
+		c.JSON(200, H{
+			"current":    current,
+			"pending":    pending,
+			"migrations": status,
+		})
+	}
+}
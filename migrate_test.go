@@ -0,0 +1,150 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+var testMigrations = fstest.MapFS{
+	"migrations/001_create_products.up.sql":   {Data: []byte("CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT)")},
+	"migrations/001_create_products.down.sql": {Data: []byte("DROP TABLE products")},
+	"migrations/002_add_price.up.sql":         {Data: []byte("ALTER TABLE products ADD COLUMN price REAL")},
+	"migrations/README.md":                    {Data: []byte("ignored")},
+}
+
+func setupMigrateDB(t *testing.T) *DB {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping migration tests: sqlite not available (%v)", err)
+	}
+	return db
+}
+
+func TestMigrateUpDownStatus(t *testing.T) {
+	db := setupMigrateDB(t)
+	ctx := context.Background()
+
+	if err := Migrate(db, testMigrations); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if err := db.Exec("INSERT INTO products (name, price) VALUES ('tea', 2.5)").Error; err != nil {
+		t.Fatalf("Expected migrated schema, got %v", err)
+	}
+
+	m, err := NewMigrator(db, testMigrations)
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %v", err)
+	}
+	err = m.Register(3, "seed", func(tx *DB) error {
+		return tx.Exec("INSERT INTO products (name) VALUES ('coffee')").Error
+	}, nil)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := m.Register(3, "again", nil, nil); !errors.Is(err, ErrDuplicateVersion) {
+		t.Errorf("Expected ErrDuplicateVersion, got %v", err)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	var count int64
+	db.Table("products").Count(&count)
+	if count != 2 {
+		t.Errorf("Expected Go migration to run once, got %d products", count)
+	}
+
+	status, _ := m.Status(ctx)
+	if len(status) != 3 || !status[2].Applied || status[2].Name != "seed" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	// Version 3 has no down step
+	if err := m.Down(ctx); !errors.Is(err, ErrNoDownMigration) {
+		t.Errorf("Expected ErrNoDownMigration, got %v", err)
+	}
+}
+
+func TestMigrateDownRollsBack(t *testing.T) {
+	db := setupMigrateDB(t)
+	ctx := context.Background()
+	m, _ := NewMigrator(db, fstest.MapFS{
+		"001_create_products.up.sql":   testMigrations["migrations/001_create_products.up.sql"],
+		"001_create_products.down.sql": testMigrations["migrations/001_create_products.down.sql"],
+	})
+
+	var out strings.Builder
+	if err := m.RunCommand(ctx, "up", &out); err != nil {
+		t.Fatalf("up failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "1_create_products\tapplied") {
+		t.Errorf("Unexpected up report %q", out.String())
+	}
+
+	out.Reset()
+	if err := m.RunCommand(ctx, "down", &out); err != nil {
+		t.Fatalf("down failed: %v", err)
+	}
+	if out.String() != "1_create_products\tpending\n" {
+		t.Errorf("Unexpected down report %q", out.String())
+	}
+	if db.Migrator().HasTable("products") {
+		t.Error("Expected products table to be dropped")
+	}
+
+	if err := m.RunCommand(ctx, "sideways", &out); !errors.Is(err, ErrUnknownMigrateCmd) {
+		t.Errorf("Expected ErrUnknownMigrateCmd, got %v", err)
+	}
+}
+
+func TestMigrateLock(t *testing.T) {
+	db := setupMigrateDB(t)
+	m, _ := NewMigrator(db, testMigrations, MigratorConfig{LockTimeout: 300 * time.Millisecond})
+	m.ensureTables(db)
+	db.Table("schema_migrations_lock").Create(&schemaMigrationLock{ID: 1, Owner: "other", LockedAt: time.Now()})
+
+	if err := m.Up(context.Background()); !errors.Is(err, ErrMigrationLocked) {
+		t.Fatalf("Expected ErrMigrationLocked, got %v", err)
+	}
+	if db.Migrator().HasTable("products") {
+		t.Error("Expected no migrations to run while locked")
+	}
+
+	m.ForceUnlock(context.Background())
+	if err := m.Up(context.Background()); err != nil {
+		t.Errorf("Expected Up to succeed after unlock, got %v", err)
+	}
+}
+
+func TestMigrationStatusHandler(t *testing.T) {
+	db := setupMigrateDB(t)
+	m, _ := NewMigrator(db, testMigrations)
+	m.Up(context.Background())
+	m.Register(3, "pending_one", func(tx *DB) error { return nil }, nil)
+
+	r := New()
+	r.GET("/admin/migrations", MigrationStatusHandler(m))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/migrations", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"current":2`) || !strings.Contains(body, `"pending":1`) {
+		t.Errorf("Unexpected status body %s", body)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MockHeader is the request header asking for mock responses, and the
+// response header marking them
+const MockHeader = "X-Mock-Response"
+
+// MockConfig holds configuration for a mocked route
+type MockConfig struct {
+	// Status is the mocked status code
+	// Default: 200
+	Status int
+
+	// Body is the mocked body. Strings and byte slices are sent as they
+	// are, anything else as JSON.
+	Body any
+
+	// ContentType is sent with string and byte slice bodies
+	// Default: detected from the body
+	ContentType string
+
+	// Headers are added to the mocked response
+	Headers map[string]string
+
+	// Delay simulates a slow backend
+	Delay time.Duration
+
+	// Output receives a line for every mocked response
+	// Default: DefaultWriter
+	Output io.Writer
+}
+
+// MockResponse returns a middleware serving a canned response instead of
+// the route's handlers while mocks are enabled, so frontend teams can work
+// against endpoints that aren't finished or data that is hard to produce.
+// Mocks are served when Engine.Mocks is set, or for requests sending the
+// X-Mock-Response header outside release mode; otherwise the route runs
+// as usual:
+//
+//	r.GET("/orders/:id", goTap.MockResponse(200, goTap.H{"id": 42, "status": "paid"}), getOrder)
+//
+//	curl -H "X-Mock-Response: true" localhost:8080/orders/42
+func MockResponse(status int, body any) HandlerFunc {
+	return MockResponseWithConfig(MockConfig{Status: status, Body: body})
+}
+
+// MockResponseWithConfig returns a MockResponse middleware with config
+func MockResponseWithConfig(config MockConfig) HandlerFunc {
+	if config.Status == 0 {
+		config.Status = http.StatusOK
+	}
+	if config.Output == nil {
+		config.Output = DefaultWriter
+	}
+
+	return func(c *Context) {
+		if !mockRequested(c) {
+			c.Next()
+			return
+		}
+
+		if config.Delay > 0 {
+			select {
+			case <-time.After(config.Delay):
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+		}
+
+		fmt.Fprintf(config.Output, "[goTap] [MOCK] %s %s -> %d\n", c.Request.Method, c.Request.URL.Path, config.Status)
+		for k, v := range config.Headers {
+			c.Header(k, v)
+		}
+		c.Header(MockHeader, "true")
+
+		switch body := config.Body.(type) {
+		case nil:
+			c.Status(config.Status)
+		case []byte:
+			c.Data(config.Status, mockContentType(config.ContentType, body), body)
+		case string:
+			c.Data(config.Status, mockContentType(config.ContentType, []byte(body)), []byte(body))
+		default:
+			c.JSON(config.Status, body)
+		}
+		c.Abort()
+	}
+}
+
+// mockRequested reports whether c should get the mocked response. The
+// request header is ignored in release mode so production clients can't
+// bypass the real handlers.
+func mockRequested(c *Context) bool {
+	if c.engine != nil && c.engine.Mocks {
+		return true
+	}
+	if Mode() == ReleaseMode {
+		return false
+	}
+	enabled, _ := strconv.ParseBool(c.GetHeader(MockHeader))
+	return enabled
+}
+
+func mockContentType(contentType string, body []byte) string {
+	if contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(body)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMockResponse(t *testing.T) {
+	var log bytes.Buffer
+	r := New()
+	r.GET("/orders/:id", MockResponseWithConfig(MockConfig{
+		Status:  201,
+		Body:    H{"id": 42, "status": "paid"},
+		Headers: map[string]string{"X-Total": "1"},
+		Output:  &log,
+	}), func(c *Context) {
+		c.String(200, "real")
+	})
+	get := func(mock string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/orders/42", nil)
+		if mock != "" {
+			req.Header.Set(MockHeader, mock)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(""); w.Code != 200 || w.Body.String() != "real" {
+		t.Errorf("Expected the real handler without the header, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("false"); w.Body.String() != "real" {
+		t.Errorf("Expected the real handler for a false header, got %q", w.Body.String())
+	}
+
+	w := get("true")
+	if w.Code != 201 || strings.TrimSpace(w.Body.String()) != `{"id":42,"status":"paid"}` ||
+		w.Header().Get("X-Total") != "1" || w.Header().Get(MockHeader) != "true" {
+		t.Errorf("Unexpected mock %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if !strings.Contains(log.String(), "[MOCK] GET /orders/42 -> 201") {
+		t.Errorf("Expected the mock to be logged, got %q", log.String())
+	}
+
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+	if w := get("true"); w.Body.String() != "real" {
+		t.Errorf("Expected the header to be ignored in release mode, got %q", w.Body.String())
+	}
+	r.Mocks = true
+	if w := get(""); w.Code != 201 {
+		t.Errorf("Expected Engine.Mocks to serve the mock, got %d", w.Code)
+	}
+}
+
+func TestMockResponseRawBody(t *testing.T) {
+	r := New()
+	r.Mocks = true
+	r.GET("/page", MockResponse(200, "<html><body>soon</body></html>"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/page", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") || w.Body.String() != "<html><body>soon</body></html>" {
+		t.Errorf("Unexpected raw mock %q %q", ct, w.Body.String())
+	}
+}
@@ -26,3 +26,22 @@ type Model struct {
 
 // BaseModel is an alias for Model for backward compatibility
 type BaseModel = Model
+
+// VersionedModel is a Model with a version for optimistic locking. Update
+// it with GormUpdateVersioned, which fails with ErrStaleRecord if the record
+// changed since it was read:
+//
+//	type Product struct {
+//	    gotap.VersionedModel
+//	    Name  string `json:"name"`
+//	    Stock int    `json:"stock"`
+//	}
+//
+// Resource checks the version sent in update bodies.
+type VersionedModel struct {
+	Model
+	Version uint `gorm:"not null;default:1" json:"version" example:"1"`
+}
+
+// versioned marks models embedding VersionedModel
+func (VersionedModel) versioned() {}
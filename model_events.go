@@ -0,0 +1,169 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Model lifecycle actions
+const (
+	ModelCreated = "created"
+	ModelUpdated = "updated"
+	ModelDeleted = "deleted"
+)
+
+// ModelEvent is the payload of the "<model>.created", "<model>.updated"
+// and "<model>.deleted" events published for registered models
+type ModelEvent struct {
+	// Model is the snake_case model name, e.g. "order_item"
+	Model string
+	// Action is ModelCreated, ModelUpdated or ModelDeleted
+	Action string
+	// ID is the primary key of the record, when known
+	ID any
+	// Record is the record as passed to GORM (a pointer to the model), or
+	// for MongoDB the inserted document or the filter of the write
+	Record any
+	// Changes holds the update map or document for partial updates
+	Changes any
+}
+
+// modelEventRegistry holds the models published for one GORM database
+type modelEventRegistry struct {
+	mu     sync.RWMutex
+	bus    *EventBus
+	models map[reflect.Type]string
+}
+
+var modelEventRegistries sync.Map // *gorm.Config -> *modelEventRegistry
+
+// RegisterModelEvents publishes Created/Updated/Deleted events on bus for
+// the given models whenever they are written through db:
+//
+//	goTap.RegisterModelEvents(db, r.Events(), &Product{}, &Order{})
+//	r.Events().Subscribe("product.*", func(ctx context.Context, e goTap.Event) {
+//	    cache.Delete(fmt.Sprint("product:", e.Payload.(goTap.ModelEvent).ID))
+//	})
+//
+// Events are published after GORM commits its own transaction for the
+// statement. Writes made inside an explicit db.Transaction publish before
+// the outer transaction commits; use the transactional outbox when
+// subscribers must never observe rolled-back data.
+func RegisterModelEvents(db *DB, bus *EventBus, models ...any) error {
+	value, loaded := modelEventRegistries.LoadOrStore(db.Config, &modelEventRegistry{
+		models: make(map[reflect.Type]string),
+	})
+	registry := value.(*modelEventRegistry)
+
+	registry.mu.Lock()
+	registry.bus = bus
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			registry.mu.Unlock()
+			return fmt.Errorf("failed to register model events for %T: %w", model, err)
+		}
+		registry.models[stmt.Schema.ModelType] = db.NamingStrategy.ColumnName("", stmt.Schema.Name)
+	}
+	registry.mu.Unlock()
+
+	if loaded {
+		return nil
+	}
+
+	publish := func(action string) func(*gorm.DB) {
+		return func(tx *gorm.DB) { registry.publish(tx, action) }
+	}
+	const after = "gorm:commit_or_rollback_transaction"
+	if err := db.Callback().Create().After(after).Register("gotap:model_events_created", publish(ModelCreated)); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After(after).Register("gotap:model_events_updated", publish(ModelUpdated)); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After(after).Register("gotap:model_events_deleted", publish(ModelDeleted)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *modelEventRegistry) publish(tx *gorm.DB, action string) {
+	if tx.Error != nil || tx.Statement.RowsAffected == 0 || tx.Statement.Schema == nil {
+		return
+	}
+
+	r.mu.RLock()
+	name, ok := r.models[tx.Statement.Schema.ModelType]
+	bus := r.bus
+	r.mu.RUnlock()
+	if !ok || bus == nil {
+		return
+	}
+
+	var changes any
+	if action == ModelUpdated {
+		if m, ok := tx.Statement.Dest.(map[string]any); ok {
+			changes = m
+		}
+	}
+
+	ctx := tx.Statement.Context
+	primary := tx.Statement.Schema.PrioritizedPrimaryField
+	emit := func(rv reflect.Value) {
+		event := ModelEvent{Model: name, Action: action, Changes: changes}
+		if primary != nil {
+			if id, zero := primary.ValueOf(ctx, rv); !zero {
+				event.ID = id
+			}
+		}
+		if rv.CanAddr() {
+			event.Record = rv.Addr().Interface()
+		} else {
+			event.Record = rv.Interface()
+		}
+		bus.Publish(ctx, name+"."+action, event)
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			emit(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		emit(rv)
+	}
+}
+
+// PublishEvents makes the repository publish "<model>.created",
+// "<model>.updated" and "<model>.deleted" events on bus after successful
+// writes. It returns the repository for chaining:
+//
+//	orders := goTap.NewMongoRepository(client, "orders").PublishEvents(r.Events(), "order")
+func (r *MongoRepository) PublishEvents(bus *EventBus, model string) *MongoRepository {
+	r.events = bus
+	r.model = model
+	return r
+}
+
+// publish emits a model event if the repository has an event bus
+func (r *MongoRepository) publish(ctx context.Context, action string, id, record, changes any) {
+	if r.events == nil {
+		return
+	}
+	r.events.Publish(ctx, r.model+"."+action, ModelEvent{
+		Model:   r.model,
+		Action:  action,
+		ID:      id,
+		Record:  record,
+		Changes: changes,
+	})
+}
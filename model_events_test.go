@@ -0,0 +1,84 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+type eventProduct struct {
+	ID    uint
+	Name  string
+	Price float64
+}
+
+type eventAudit struct {
+	ID   uint
+	Note string
+}
+
+func TestRegisterModelEventsGorm(t *testing.T) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping model event tests: sqlite not available (%v)", err)
+	}
+	db.AutoMigrate(&eventProduct{}, &eventAudit{})
+
+	bus := NewEventBus()
+	var got []string
+	bus.Subscribe("*", func(ctx context.Context, e Event) {
+		me := e.Payload.(ModelEvent)
+		got = append(got, fmt.Sprintf("%s#%v", e.Name, me.ID))
+		if me.Action == ModelUpdated && me.Changes != nil {
+			got = append(got, fmt.Sprintf("changes=%v", me.Changes))
+		}
+	})
+
+	if err := RegisterModelEvents(db, bus, &eventProduct{}); err != nil {
+		t.Fatalf("RegisterModelEvents failed: %v", err)
+	}
+	// Registering again must not duplicate callbacks
+	if err := RegisterModelEvents(db, bus, &eventProduct{}); err != nil {
+		t.Fatalf("RegisterModelEvents failed: %v", err)
+	}
+
+	p := &eventProduct{Name: "Tea", Price: 2}
+	db.Create(p)
+	db.Create([]eventProduct{{Name: "A"}, {Name: "B"}})
+	db.Model(p).Updates(map[string]any{"price": 3})
+	db.Delete(p)
+	db.Delete(&eventProduct{}, 999) // no rows affected
+	db.Create(&eventAudit{Note: "not registered"})
+
+	want := []string{
+		"event_product.created#1",
+		"event_product.created#2",
+		"event_product.created#3",
+		"event_product.updated#1", "changes=map[price:3]",
+		"event_product.deleted#1",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestMongoRepositoryPublishEvents(t *testing.T) {
+	bus := NewEventBus()
+	var got ModelEvent
+	bus.Subscribe("order.updated", func(ctx context.Context, e Event) {
+		got = e.Payload.(ModelEvent)
+	})
+
+	repo := (&MongoRepository{}).PublishEvents(bus, "order")
+	repo.publish(context.Background(), ModelUpdated, "abc", nil, H{"$set": H{"status": "paid"}})
+
+	if got.Model != "order" || got.ID != "abc" || got.Changes == nil {
+		t.Errorf("Unexpected event %+v", got)
+	}
+}
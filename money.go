@@ -0,0 +1,448 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Money errors
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrMoneyOverflow    = errors.New("money: amount overflows int64")
+)
+
+// RoundingMode decides how amounts between two minor units are rounded
+type RoundingMode int
+
+// Rounding modes
+const (
+	// RoundHalfUp rounds halves away from zero, e.g. 2.5 to 3 and -2.5 to -3
+	RoundHalfUp RoundingMode = iota
+
+	// RoundHalfEven rounds halves to the even neighbour (banker's
+	// rounding), e.g. 2.5 to 2 and 3.5 to 4
+	RoundHalfEven
+
+	// RoundHalfDown rounds halves toward zero, e.g. 2.5 to 2
+	RoundHalfDown
+
+	// RoundDown truncates toward zero
+	RoundDown
+
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+// Money is an amount of a currency in its minor unit, e.g. cents, so sums
+// never suffer from floating point rounding. Arithmetic returns
+// ErrCurrencyMismatch for different currencies and ErrMoneyOverflow
+// instead of wrapping around.
+//
+// It binds from JSON as {"amount":1999,"currency":"USD"} or "USD 19.99",
+// from forms as "USD 19.99", and is stored by GORM as two columns when
+// embedded:
+//
+//	type Order struct {
+//	    goTap.Model
+//	    Total goTap.Money `gorm:"embedded;embeddedPrefix:total_" json:"total" validate:"currency"`
+//	}
+type Money struct {
+	// Amount in the currency's minor unit, e.g. 1999 for $19.99
+	Amount int64 `json:"amount"`
+
+	// Currency is the ISO 4217 code, e.g. "USD"
+	Currency string `gorm:"size:3" json:"currency"`
+}
+
+// NewMoney returns amount minor units of currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal amount of currency, e.g. "19.99" or "-5", into
+// minor units. More decimals than the currency has are rejected.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || strings.ContainsAny(amount, "eE/") {
+		return Money{}, fmt.Errorf("money: invalid amount %q", amount)
+	}
+	r.Mul(r, new(big.Rat).SetInt(minorUnitScale(currency)))
+	if !r.IsInt() {
+		return Money{}, fmt.Errorf("money: %q has more than %d decimals for %s", amount, CurrencyDigits(currency), currency)
+	}
+	if !r.Num().IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Amount: r.Num().Int64(), Currency: currency}, nil
+}
+
+// MoneyFromFloat converts a float amount, e.g. from legacy float64 columns,
+// rounding to the currency's minor unit with mode
+func MoneyFromFloat(amount float64, currency string, mode RoundingMode) (Money, error) {
+	currency = strings.ToUpper(currency)
+	// The shortest decimal representation avoids 19.99 becoming
+	// 19.989999...
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	if !ok {
+		return Money{}, fmt.Errorf("money: invalid amount %v", amount)
+	}
+	r.Mul(r, new(big.Rat).SetInt(minorUnitScale(currency)))
+	minor, err := roundQuo(r.Num(), r.Denom(), mode)
+	return Money{Amount: minor, Currency: currency}, err
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + o.Amount
+	if (sum > m.Amount) != (o.Amount > 0) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	diff := m.Amount - o.Amount
+	if (diff < m.Amount) != (o.Amount > 0) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Amount: diff, Currency: m.Currency}, nil
+}
+
+// Mul returns m * n, e.g. a unit price times a quantity
+func (m Money) Mul(n int64) (Money, error) {
+	return m.MulRat(n, 1, RoundHalfUp)
+}
+
+// MulRat returns m * num / den rounded with mode, e.g. 8.25% tax:
+//
+//	tax, err := subtotal.MulRat(825, 10000, goTap.RoundHalfUp)
+func (m Money) MulRat(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("money: division by zero")
+	}
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	amount, err := roundQuo(product, big.NewInt(den), mode)
+	return Money{Amount: amount, Currency: m.Currency}, err
+}
+
+// RoundTo rounds m to a multiple of increment minor units with mode, e.g.
+// 5 for cash payments in CHF
+func (m Money) RoundTo(increment int64, mode RoundingMode) (Money, error) {
+	if increment <= 0 {
+		return Money{}, errors.New("money: increment must be positive")
+	}
+	units, err := roundQuo(big.NewInt(m.Amount), big.NewInt(increment), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: units, Currency: m.Currency}.Mul(increment)
+}
+
+// Allocate splits m by ratios without losing minor units, e.g. a bill
+// split three ways: $100.00 by (1, 1, 1) is $33.34, $33.33 and $33.33.
+// Remainders go to the first parts.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("money: negative ratio")
+		}
+		total += ratio
+	}
+	if total <= 0 {
+		return nil, errors.New("money: ratios must sum to more than zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.Amount
+	for i, ratio := range ratios {
+		part, err := m.MulRat(ratio, total, RoundDown)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = part
+		remainder -= part.Amount
+	}
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i++ {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].Amount += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Cmp compares m and o, returning -1, 0 or +1
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if !strings.EqualFold(m.Currency, o.Currency) {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+// Decimal returns the amount in major units, e.g. "19.99", as payment
+// gateways expect
+func (m Money) Decimal() string {
+	digits := CurrencyDigits(m.Currency)
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), minorUnitScale(m.Currency)).FloatString(digits)
+}
+
+// String returns the currency and decimal amount, e.g. "USD 19.99"
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Format formats m for locale, e.g. "$19.99" for en-US, see FormatMoney
+func (m Money) Format(locale string) string {
+	return FormatMoney(locale, m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting
+// {"amount":1999,"currency":"USD"} and "USD 19.99"
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return m.UnmarshalText([]byte(s))
+	}
+	type plain Money
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	m.Currency = strings.ToUpper(m.Currency)
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for form binding,
+// accepting "USD 19.99"
+func (m *Money) UnmarshalText(text []byte) error {
+	currency, amount, ok := strings.Cut(strings.TrimSpace(string(text)), " ")
+	if !ok {
+		return fmt.Errorf("money: %q is not \"<currency> <amount>\"", text)
+	}
+	parsed, err := ParseMoney(amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// roundQuo returns num / den rounded with mode
+func roundQuo(num, den *big.Int, mode RoundingMode) (int64, error) {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() != 0 {
+		sign := int64(num.Sign() * den.Sign())
+		// Compare the remainder with half the divisor
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(new(big.Int).Abs(den))
+		var away bool
+		switch mode {
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		case RoundHalfDown:
+			away = cmp > 0
+		case RoundUp:
+			away = true
+		}
+		if away {
+			q.Add(q, big.NewInt(sign))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+	return q.Int64(), nil
+}
+
+// minorUnitScale returns 10^digits of currency
+func minorUnitScale(currency string) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyDigits(currency))), nil)
+}
+
+// ValidCurrency reports whether code is an active ISO 4217 currency code
+func ValidCurrency(code string) bool {
+	return isoCurrencies[strings.ToUpper(code)]
+}
+
+// CurrencyDigits returns the number of decimals of the currency's minor
+// unit, e.g. 2 for USD and 0 for JPY
+func CurrencyDigits(code string) int {
+	return lookupCurrency(code).digits
+}
+
+// validateCurrency implements the "currency" rule for currency codes and
+// Money, optionally restricted to a list, e.g. "currency=USD EUR"
+func validateCurrency(fieldName string, value reflect.Value, param string) error {
+	var code string
+	switch v := value.Interface().(type) {
+	case string:
+		code = v
+	case Money:
+		code = v.Currency
+	case *Money:
+		if v == nil {
+			return nil
+		}
+		code = v.Currency
+	default:
+		return nil
+	}
+	if code == "" {
+		return nil
+	}
+	if !ValidCurrency(code) {
+		return fmt.Errorf("field '%s' must be an ISO 4217 currency code", fieldName)
+	}
+	if param != "" {
+		for _, allowed := range strings.Fields(param) {
+			if strings.EqualFold(code, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("field '%s' must be one of: %s", fieldName, param)
+	}
+	return nil
+}
+
+// isoCurrencies are the active ISO 4217 currency codes
+var isoCurrencies = func() map[string]bool {
+	codes := strings.Fields(`AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+		BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB
+		EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY
+		KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+		MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF
+		SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS
+		UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG`)
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}()
+
+// currencyExponents lists the currencies without two decimals missing
+// from currencies
+var currencyExponents = map[string]int{
+	"BIF": 0, "DJF": 0, "GNF": 0, "KMF": 0, "PYG": 0, "RWF": 0, "UGX": 0,
+	"VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"IQD": 3, "LYD": 3,
+}
+
+// currencyInfo describes an ISO 4217 currency
+type currencyInfo struct {
+	digits int
+	symbol string
+}
+
+// currencies lists common ISO 4217 currencies; others format with two
+// decimals and their code as symbol
+var currencies = map[string]currencyInfo{
+	"AED": {2, "AED"},
+	"ARS": {2, "ARS"},
+	"AUD": {2, "A$"},
+	"BHD": {3, "BHD"},
+	"BRL": {2, "R$"},
+	"CAD": {2, "CA$"},
+	"CHF": {2, "CHF"},
+	"CLP": {0, "CLP"},
+	"CNY": {2, "CN¥"},
+	"CZK": {2, "Kč"},
+	"DKK": {2, "kr."},
+	"EUR": {2, "€"},
+	"GBP": {2, "£"},
+	"HKD": {2, "HK$"},
+	"HUF": {2, "Ft"},
+	"IDR": {2, "Rp"},
+	"ILS": {2, "₪"},
+	"INR": {2, "₹"},
+	"ISK": {0, "kr"},
+	"JOD": {3, "JOD"},
+	"JPY": {0, "¥"},
+	"KRW": {0, "₩"},
+	"KWD": {3, "KWD"},
+	"MXN": {2, "MX$"},
+	"MYR": {2, "RM"},
+	"NGN": {2, "₦"},
+	"NOK": {2, "kr"},
+	"NZD": {2, "NZ$"},
+	"OMR": {3, "OMR"},
+	"PHP": {2, "₱"},
+	"PKR": {2, "Rs"},
+	"PLN": {2, "zł"},
+	"RUB": {2, "₽"},
+	"SAR": {2, "SAR"},
+	"SEK": {2, "kr"},
+	"SGD": {2, "S$"},
+	"THB": {2, "฿"},
+	"TND": {3, "TND"},
+	"TRY": {2, "₺"},
+	"TWD": {2, "NT$"},
+	"UAH": {2, "₴"},
+	"USD": {2, "$"},
+	"VND": {0, "₫"},
+	"ZAR": {2, "R"},
+}
+
+func lookupCurrency(code string) currencyInfo {
+	code = strings.ToUpper(code)
+	if info, ok := currencies[code]; ok {
+		return info
+	}
+	if digits, ok := currencyExponents[code]; ok {
+		return currencyInfo{digits: digits, symbol: code}
+	}
+	return currencyInfo{digits: 2, symbol: code}
+}
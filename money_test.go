@@ -0,0 +1,177 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount, currency string
+		want             Money
+		err              bool
+	}{
+		{"19.99", "usd", NewMoney(1999, "USD"), false},
+		{"-5", "USD", NewMoney(-500, "USD"), false},
+		{"1500", "JPY", NewMoney(1500, "JPY"), false},
+		{"1.234", "KWD", NewMoney(1234, "KWD"), false},
+		{"1.5", "JPY", Money{}, true},
+		{"19.999", "USD", Money{}, true},
+		{"1e3", "USD", Money{}, true},
+		{"abc", "USD", Money{}, true},
+		{"99999999999999999999", "USD", Money{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.amount, tt.currency)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseMoney(%q, %q) = %v, %v", tt.amount, tt.currency, got, err)
+		}
+	}
+
+	if m, _ := MoneyFromFloat(19.99, "USD", RoundHalfUp); m.Amount != 1999 {
+		t.Errorf("Expected 1999, got %d", m.Amount)
+	}
+	if m, _ := MoneyFromFloat(0.125, "USD", RoundHalfEven); m.Amount != 12 {
+		t.Errorf("Expected banker's rounding to 12, got %d", m.Amount)
+	}
+	if m := NewMoney(-1999, "USD"); m.String() != "USD -19.99" || NewMoney(1500, "JPY").Decimal() != "1500" {
+		t.Errorf("Unexpected string %s", m)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	price := NewMoney(1999, "USD")
+	if sum, err := price.Add(NewMoney(1, "USD")); err != nil || sum.Amount != 2000 {
+		t.Errorf("Unexpected sum %v %v", sum, err)
+	}
+	if _, err := price.Add(NewMoney(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := NewMoney(math.MaxInt64, "USD").Add(NewMoney(1, "USD")); err != ErrMoneyOverflow {
+		t.Errorf("Expected ErrMoneyOverflow, got %v", err)
+	}
+	if _, err := NewMoney(math.MinInt64, "USD").Sub(NewMoney(1, "USD")); err != ErrMoneyOverflow {
+		t.Errorf("Expected ErrMoneyOverflow, got %v", err)
+	}
+	if _, err := NewMoney(math.MaxInt64/2+1, "USD").Mul(2); err != ErrMoneyOverflow {
+		t.Errorf("Expected ErrMoneyOverflow, got %v", err)
+	}
+	if total, _ := price.Mul(3); total.Amount != 5997 {
+		t.Errorf("Unexpected product %v", total)
+	}
+
+	// 8.25% of $10.10 is 83.325 cents
+	subtotal := NewMoney(1010, "USD")
+	for mode, want := range map[RoundingMode]int64{
+		RoundHalfUp: 83, RoundDown: 83, RoundUp: 84,
+	} {
+		if tax, _ := subtotal.MulRat(825, 10000, mode); tax.Amount != want {
+			t.Errorf("Mode %d: expected %d, got %d", mode, want, tax.Amount)
+		}
+	}
+	// Halves: 2.5 and -2.5 cents
+	for mode, want := range map[RoundingMode][2]int64{
+		RoundHalfUp:   {3, -3},
+		RoundHalfEven: {2, -2},
+		RoundHalfDown: {2, -2},
+	} {
+		pos, _ := NewMoney(5, "USD").MulRat(1, 2, mode)
+		neg, _ := NewMoney(-5, "USD").MulRat(1, 2, mode)
+		if pos.Amount != want[0] || neg.Amount != want[1] {
+			t.Errorf("Mode %d: expected %v, got %d %d", mode, want, pos.Amount, neg.Amount)
+		}
+	}
+
+	if cash, _ := NewMoney(1033, "CHF").RoundTo(5, RoundHalfUp); cash.Amount != 1035 {
+		t.Errorf("Expected cash rounding to 10.35, got %v", cash)
+	}
+
+	parts, err := NewMoney(10000, "USD").Allocate(1, 1, 1)
+	if err != nil || parts[0].Amount != 3334 || parts[1].Amount != 3333 || parts[2].Amount != 3333 {
+		t.Errorf("Unexpected allocation %v %v", parts, err)
+	}
+	parts, _ = NewMoney(-5, "USD").Allocate(0, 1, 1)
+	if parts[0].Amount != 0 || parts[1].Amount != -3 || parts[2].Amount != -2 {
+		t.Errorf("Unexpected allocation %v", parts)
+	}
+
+	if c, _ := price.Cmp(NewMoney(2000, "usd")); c != -1 {
+		t.Errorf("Expected -1, got %d", c)
+	}
+	if price.Neg().Amount != -1999 || !price.Neg().IsNegative() || price.IsZero() {
+		t.Error("Unexpected sign helpers")
+	}
+}
+
+func TestMoneyBinding(t *testing.T) {
+	type payment struct {
+		Total    Money  `json:"total" form:"total" validate:"currency=USD EUR"`
+		Currency string `json:"currency" form:"currency" validate:"currency"`
+	}
+
+	var p payment
+	if err := json.Unmarshal([]byte(`{"total":{"amount":1999,"currency":"eur"}}`), &p); err != nil || p.Total != NewMoney(1999, "EUR") {
+		t.Errorf("Unexpected object binding %v %v", p.Total, err)
+	}
+	if err := json.Unmarshal([]byte(`{"total":"USD 5.00"}`), &p); err != nil || p.Total != NewMoney(500, "USD") {
+		t.Errorf("Unexpected string binding %v %v", p.Total, err)
+	}
+	if data, _ := json.Marshal(p.Total); string(data) != `{"amount":500,"currency":"USD"}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	r := New()
+	r.POST("/pay", func(c *Context) {
+		var p payment
+		if err := c.ShouldBind(&p); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, p.Total.Format("en-US"))
+	})
+	request := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/pay", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := request("total=USD+12.50&currency=usd"); w.Code != http.StatusOK || w.Body.String() != "$12.50" {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := request("total=GBP+12.50"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a disallowed currency to fail, got %d", w.Code)
+	}
+	if w := request("total=USD+12.50&currency=XXX"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown currency to fail, got %d", w.Code)
+	}
+	if w := request("total=12.50"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an amount without currency to fail, got %d", w.Code)
+	}
+}
+
+func TestMoneyGorm(t *testing.T) {
+	type order struct {
+		ID    uint
+		Total Money `gorm:"embedded;embeddedPrefix:total_"`
+	}
+	db := setupResourceDB(t)
+	if err := AutoMigrate(db, &order{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&order{Total: NewMoney(2599, "EUR")})
+
+	var loaded order
+	if err := db.Where("total_amount > ?", 2000).First(&loaded).Error; err != nil || loaded.Total != NewMoney(2599, "EUR") {
+		t.Errorf("Unexpected order %+v %v", loaded, err)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangeEvent is a change of a Mongo document as broadcast by
+// WatchChangeStream
+type ChangeEvent struct {
+	// ID is the resume token of the change
+	ID string `json:"id"`
+	// Operation is "insert", "update", "replace" or "delete"
+	Operation string `json:"operation"`
+	// Collection is the changed collection
+	Collection string `json:"collection"`
+	// DocumentKey holds the _id of the document
+	DocumentKey bson.M `json:"documentKey"`
+	// Document is the document after the change, if it still exists
+	Document bson.M `json:"document,omitempty"`
+	// UpdatedFields and RemovedFields describe an update
+	UpdatedFields bson.M   `json:"updatedFields,omitempty"`
+	RemovedFields []string `json:"removedFields,omitempty"`
+	// Time is the cluster time of the change
+	Time time.Time `json:"time"`
+}
+
+// ChangeStreamConfig holds configuration for WatchChangeStream
+type ChangeStreamConfig struct {
+	// Collection is watched for changes; change streams require a replica
+	// set or sharded cluster
+	Collection *mongo.Collection
+
+	// Operations are the operation types broadcast
+	// Default: ["insert", "update", "replace"]
+	Operations []string
+
+	// Pipeline holds extra aggregation stages, e.g. a $match on fields
+	Pipeline mongo.Pipeline
+
+	// Hub receives the events as JSON messages in the event's room
+	Hub *WebSocketHub
+
+	// SSE receives the events in the event's room, named
+	// "<collection>.<operation>" with the resume token as event ID
+	SSE *SSEHub
+
+	// Room returns the room of an event, e.g. "store:" + the store of an
+	// order
+	// Default: the collection name
+	Room func(event ChangeEvent) string
+
+	// ResumeStore keeps the resume token of the last broadcast change, so a
+	// restarted watcher continues where it stopped instead of missing the
+	// changes in between
+	// Optional. Default value nil, which starts from the current changes.
+	ResumeStore KVStore
+
+	// ResumeKey is the ResumeStore key
+	// Default: "changestream:<database>.<collection>"
+	ResumeKey string
+
+	// RetryDelay is the pause before reopening a failed stream
+	// Default: 1s
+	RetryDelay time.Duration
+}
+
+// changeStreamCursor is the part of *mongo.ChangeStream the watcher uses
+type changeStreamCursor interface {
+	Next(ctx context.Context) bool
+	Decode(v interface{}) error
+	ResumeToken() bson.Raw
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// WatchChangeStream broadcasts the changes of a collection to WebSocket and
+// SSE clients until ctx is cancelled, so dashboards get live updates
+// without polling:
+//
+//	hub := goTap.NewSSEHub()
+//	go goTap.WatchChangeStream(ctx, goTap.ChangeStreamConfig{
+//	    Collection:  mongo.Collection("orders"),
+//	    SSE:         hub,
+//	    Room:        func(e goTap.ChangeEvent) string { return fmt.Sprint("store:", e.Document["store_id"]) },
+//	    ResumeStore: r.KVStore,
+//	})
+//	r.GET("/orders/live", func(c *goTap.Context) { hub.Serve(c, "store:"+c.Query("store")) })
+//
+// A failed stream is reopened after the last broadcast change.
+func WatchChangeStream(ctx context.Context, config ChangeStreamConfig) error {
+	if config.Collection == nil {
+		return errors.New("goTap: WatchChangeStream requires a Collection")
+	}
+	if len(config.Operations) == 0 {
+		config.Operations = []string{"insert", "update", "replace"}
+	}
+	if config.ResumeKey == "" {
+		config.ResumeKey = "changestream:" + config.Collection.Database().Name() + "." + config.Collection.Name()
+	}
+	pipeline := append(mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "operationType", Value: bson.D{{Key: "$in", Value: config.Operations}}}}}},
+	}, config.Pipeline...)
+
+	return watchChangeStream(ctx, config, func(ctx context.Context, resumeAfter bson.Raw) (changeStreamCursor, error) {
+		opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+		if resumeAfter != nil {
+			opts.SetResumeAfter(resumeAfter)
+		}
+		return config.Collection.Watch(ctx, pipeline, opts)
+	})
+}
+
+func watchChangeStream(ctx context.Context, config ChangeStreamConfig, open func(ctx context.Context, resumeAfter bson.Raw) (changeStreamCursor, error)) error {
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	var token bson.Raw
+	if config.ResumeStore != nil {
+		if saved, err := config.ResumeStore.Get(ctx, config.ResumeKey); err == nil {
+			token = saved
+		}
+	}
+
+	for {
+		err := func() error {
+			stream, err := open(ctx, token)
+			if err != nil {
+				return err
+			}
+			defer stream.Close(context.Background())
+			for stream.Next(ctx) {
+				var change mongoChange
+				if err := stream.Decode(&change); err != nil {
+					return err
+				}
+				broadcastChange(config, change.event())
+				token = stream.ResumeToken()
+				if config.ResumeStore != nil {
+					if err := config.ResumeStore.Set(ctx, config.ResumeKey, token, 0); err != nil {
+						debugPrint("[WARNING] Failed to save change stream resume token: %v", err)
+					}
+				}
+			}
+			return stream.Err()
+		}()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && (serverErr.HasErrorCode(286) || serverErr.HasErrorCode(280)) {
+			// The token fell out of the oplog; changes in between are lost
+			debugPrint("[WARNING] Change stream history lost, restarting from now: %v", err)
+			token = nil
+		} else if err != nil {
+			debugPrint("[WARNING] Change stream failed, reopening in %v: %v", config.RetryDelay, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(config.RetryDelay):
+		}
+	}
+}
+
+// broadcastChange sends event to the hubs
+func broadcastChange(config ChangeStreamConfig, event ChangeEvent) {
+	room := event.Collection
+	if config.Room != nil {
+		room = config.Room(event)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		debugPrint("[WARNING] Failed to encode change event %s: %v", event.ID, err)
+		return
+	}
+	if config.Hub != nil {
+		config.Hub.BroadcastToRoom(room, data)
+	}
+	if config.SSE != nil {
+		config.SSE.Publish(room, SSEvent{
+			Event: event.Collection + "." + event.Operation,
+			ID:    event.ID,
+			Data:  string(data),
+		})
+	}
+}
+
+// mongoChange is a change stream document
+type mongoChange struct {
+	ID            bson.Raw            `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	NS            struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey       bson.M `bson:"documentKey"`
+	FullDocument      bson.M `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+func (m mongoChange) event() ChangeEvent {
+	id, _ := m.ID.Lookup("_data").StringValueOK()
+	return ChangeEvent{
+		ID:            id,
+		Operation:     m.OperationType,
+		Collection:    m.NS.Coll,
+		DocumentKey:   m.DocumentKey,
+		Document:      m.FullDocument,
+		UpdatedFields: m.UpdateDescription.UpdatedFields,
+		RemovedFields: m.UpdateDescription.RemovedFields,
+		Time:          time.Unix(int64(m.ClusterTime.T), 0).UTC(),
+	}
+}
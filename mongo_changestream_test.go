@@ -0,0 +1,203 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeChangeStream replays changes, then fails with err or blocks
+type fakeChangeStream struct {
+	changes []bson.D
+	err     error
+	current bson.Raw
+	token   bson.Raw
+}
+
+func (s *fakeChangeStream) Next(ctx context.Context) bool {
+	if len(s.changes) == 0 {
+		if s.err == nil {
+			<-ctx.Done()
+		}
+		return false
+	}
+	s.current, _ = bson.Marshal(s.changes[0])
+	s.token = s.current.Lookup("_id").Document()
+	s.changes = s.changes[1:]
+	return true
+}
+
+func (s *fakeChangeStream) Decode(v interface{}) error  { return bson.Unmarshal(s.current, v) }
+func (s *fakeChangeStream) ResumeToken() bson.Raw       { return s.token }
+func (s *fakeChangeStream) Err() error                  { return s.err }
+func (s *fakeChangeStream) Close(context.Context) error { return nil }
+
+func orderChange(token, op string, id int, store int) bson.D {
+	return bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: token}}},
+		{Key: "operationType", Value: op},
+		{Key: "clusterTime", Value: primitive.Timestamp{T: 1700000000, I: 1}},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "pos"}, {Key: "coll", Value: "orders"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: id}, {Key: "store", Value: store}, {Key: "status", Value: "paid"}}},
+		{Key: "updateDescription", Value: bson.D{{Key: "updatedFields", Value: bson.D{{Key: "status", Value: "paid"}}}, {Key: "removedFields", Value: bson.A{}}}},
+	}
+}
+
+func TestWatchChangeStreamSSE(t *testing.T) {
+	hub := NewSSEHub()
+	r := New()
+	r.GET("/orders/live", func(c *Context) {
+		hub.Serve(c, "store:"+c.Query("store"))
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	client := srv.Client()
+	client.Timeout = 5 * time.Second
+	resp, err := client.Get(srv.URL + "/orders/live?store=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	for hub.ClientCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	store := NewMemoryKVStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	var opened []string
+	var mu sync.Mutex
+	done := make(chan error)
+	go func() {
+		done <- watchChangeStream(ctx, ChangeStreamConfig{
+			SSE:         hub,
+			Room:        func(e ChangeEvent) string { return fmt.Sprint("store:", e.Document["store"]) },
+			ResumeStore: store,
+			ResumeKey:   "orders",
+			RetryDelay:  time.Millisecond,
+		}, func(ctx context.Context, resumeAfter bson.Raw) (changeStreamCursor, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			token, _ := resumeAfter.Lookup("_data").StringValueOK()
+			opened = append(opened, token)
+			if len(opened) == 1 {
+				// The first stream breaks after two changes
+				return &fakeChangeStream{
+					changes: []bson.D{orderChange("t1", "insert", 1, 1), orderChange("t2", "insert", 2, 2)},
+					err:     errors.New("connection reset"),
+				}, nil
+			}
+			return &fakeChangeStream{changes: []bson.D{orderChange("t3", "update", 1, 1)}}, nil
+		})
+	}()
+
+	lines := bufio.NewScanner(resp.Body)
+	var events []string
+	for len(events) < 6 && lines.Scan() {
+		if line := lines.Text(); line != "" {
+			events = append(events, line)
+		}
+	}
+	// Store 2's order isn't sent to store 1
+	if len(events) != 6 || events[0] != "event: orders.insert" || events[1] != "id: t1" ||
+		events[3] != "event: orders.update" || events[4] != "id: t3" {
+		t.Fatalf("Unexpected events %v", events)
+	}
+	var event ChangeEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(events[2], "data: ")), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Operation != "insert" || event.Document["status"] != "paid" || event.Time.Unix() != 1700000000 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// The stream was reopened after the last change
+	if len(opened) != 2 || opened[0] != "" || opened[1] != "t2" {
+		t.Errorf("Expected the stream to resume after t2, got %v", opened)
+	}
+	if saved, _ := store.Get(context.Background(), "orders"); bson.Raw(saved).Lookup("_data").StringValue() != "t3" {
+		t.Errorf("Expected the last resume token to be saved, got %v", bson.Raw(saved))
+	}
+}
+
+func TestWatchChangeStreamWebSocketRooms(t *testing.T) {
+	hub := NewWebSocketHub()
+	defer hub.Close()
+	r := New()
+	r.GET("/ws", func(c *Context) {
+		c.WebSocket(func(ws *WebSocketConn) {
+			hub.Register(ws)
+			hub.Join(ws, c.Query("room"))
+			defer hub.Unregister(ws)
+			for {
+				if _, _, err := ws.Conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		})
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?room="
+	orders, _, err := websocket.DefaultDialer.Dial(url+"orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orders.Close()
+	other, _, err := websocket.DefaultDialer.Dial(url+"other", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hub.RoomClientCount("orders") == 0 || hub.RoomClientCount("other") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchChangeStream(ctx, ChangeStreamConfig{Hub: hub}, func(ctx context.Context, _ bson.Raw) (changeStreamCursor, error) {
+		return &fakeChangeStream{changes: []bson.D{orderChange("t1", "insert", 7, 1)}}, nil
+	})
+
+	orders.SetReadDeadline(time.Now().Add(time.Second))
+	var event ChangeEvent
+	if err := orders.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != "t1" || event.Collection != "orders" || event.DocumentKey["_id"] != float64(7) {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	other.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, _, err := other.ReadMessage(); err == nil {
+		t.Error("Expected no event outside the room")
+	}
+
+	// Leaving clients are removed from their rooms
+	other.Close()
+	for hub.RoomClientCount("other") != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
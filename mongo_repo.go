@@ -0,0 +1,324 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRepo is a MongoRepository decoding documents into T:
+//
+//	type Product struct {
+//	    ID    primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+//	    Name  string             `bson:"name" json:"name"`
+//	    Price int64              `bson:"price" json:"price"`
+//	}
+//
+//	products := goTap.NewMongoRepo[Product](client, "products").WithSoftDelete("")
+//
+//	r.GET("/products", func(c *goTap.Context) {
+//	    page := goTap.NewMongoPagination(c)
+//	    list, err := products.FindAllAs(c.Request.Context(), bson.M{}, page)
+//	    // ...
+//	    c.JSON(200, goTap.H{"data": list, "pagination": page.Response()})
+//	})
+//
+// Methods taking an id accept ObjectID hex strings for ObjectID keys, e.g.
+// c.Param("id").
+type MongoRepo[T any] struct {
+	*MongoRepository
+
+	// softDelete is the field marking deleted documents, "" to delete them
+	softDelete string
+}
+
+// NewMongoRepo creates a typed repository for a collection
+func NewMongoRepo[T any](client *MongoClient, collectionName string) *MongoRepo[T] {
+	return &MongoRepo[T]{MongoRepository: NewMongoRepository(client, collectionName)}
+}
+
+// WithSoftDelete makes Delete set field to the deletion time instead of
+// removing documents; queries skip documents where it is set. An empty
+// field means "deleted_at".
+func (r *MongoRepo[T]) WithSoftDelete(field string) *MongoRepo[T] {
+	if field == "" {
+		field = "deleted_at"
+	}
+	r.softDelete = field
+	return r
+}
+
+// PublishEvents makes the repository publish model events, see
+// MongoRepository.PublishEvents
+func (r *MongoRepo[T]) PublishEvents(bus *EventBus, model string) *MongoRepo[T] {
+	r.MongoRepository.PublishEvents(bus, model)
+	return r
+}
+
+// scope restricts filter to documents that aren't soft-deleted, or to the
+// soft-deleted ones when trashed is set
+func (r *MongoRepo[T]) scope(filter interface{}, trashed bool) interface{} {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	if r.softDelete == "" {
+		return filter
+	}
+	deleted := bson.M{r.softDelete: nil}
+	if trashed {
+		deleted = bson.M{r.softDelete: bson.M{"$ne": nil}}
+	}
+	return bson.M{"$and": bson.A{filter, deleted}}
+}
+
+// FindOneAs returns the first document matching filter, or
+// mongo.ErrNoDocuments
+func (r *MongoRepo[T]) FindOneAs(ctx context.Context, filter interface{}) (*T, error) {
+	var doc T
+	if err := r.collection.FindOne(ctx, r.scope(filter, false)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// FindByIDAs returns the document with id, or mongo.ErrNoDocuments
+func (r *MongoRepo[T]) FindByIDAs(ctx context.Context, id interface{}) (*T, error) {
+	return r.FindOneAs(ctx, bson.M{"_id": MongoID(id)})
+}
+
+// FindAllAs returns the documents matching filter. With a pagination the
+// page is returned and its total set; opts can add e.g. a sort.
+func (r *MongoRepo[T]) FindAllAs(ctx context.Context, filter interface{}, pagination *MongoPagination, opts ...*options.FindOptions) ([]T, error) {
+	return r.findAll(ctx, r.scope(filter, false), pagination, opts)
+}
+
+// FindTrashedAs returns the soft-deleted documents matching filter
+func (r *MongoRepo[T]) FindTrashedAs(ctx context.Context, filter interface{}, pagination *MongoPagination, opts ...*options.FindOptions) ([]T, error) {
+	return r.findAll(ctx, r.scope(filter, true), pagination, opts)
+}
+
+func (r *MongoRepo[T]) findAll(ctx context.Context, filter interface{}, pagination *MongoPagination, opts []*options.FindOptions) ([]T, error) {
+	if pagination != nil {
+		total, err := r.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		pagination.SetTotal(total)
+		opts = append(opts, pagination.FindOptions())
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	docs := []T{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Count counts the documents matching filter
+func (r *MongoRepo[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	return r.collection.CountDocuments(ctx, r.scope(filter, false))
+}
+
+// Create inserts doc, first setting an empty ObjectID or string _id field
+// to a new ObjectID
+func (r *MongoRepo[T]) Create(ctx context.Context, doc *T) error {
+	setMongoObjectID(doc)
+	_, err := r.InsertOne(ctx, doc)
+	return err
+}
+
+// Update applies update to the document with id, returning
+// mongo.ErrNoDocuments if it doesn't exist:
+//
+//	err := products.Update(ctx, c.Param("id"), goTap.MongoSet("price", 450).Inc("version", 1))
+func (r *MongoRepo[T]) Update(ctx context.Context, id interface{}, update *MongoUpdate) error {
+	id = MongoID(id)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": id}, false), update.Document())
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	r.publish(ctx, ModelUpdated, id, nil, update.Document())
+	return nil
+}
+
+// Delete soft-deletes the document with id, or removes it without
+// WithSoftDelete. It returns mongo.ErrNoDocuments if it doesn't exist.
+func (r *MongoRepo[T]) Delete(ctx context.Context, id interface{}) error {
+	if r.softDelete == "" {
+		return r.ForceDelete(ctx, id)
+	}
+	id = MongoID(id)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": id}, false), MongoSet(r.softDelete, time.Now()).Document())
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	r.publish(ctx, ModelDeleted, id, nil, nil)
+	return nil
+}
+
+// Restore undoes the soft delete of the document with id
+func (r *MongoRepo[T]) Restore(ctx context.Context, id interface{}) error {
+	id = MongoID(id)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": id}, true), MongoUnset(r.softDelete).Document())
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	r.publish(ctx, ModelUpdated, id, nil, nil)
+	return nil
+}
+
+// ForceDelete removes the document with id, even if soft-deleted
+func (r *MongoRepo[T]) ForceDelete(ctx context.Context, id interface{}) error {
+	result, err := r.DeleteByID(ctx, MongoID(id))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MongoID converts an ObjectID hex string, e.g. a route parameter, to an
+// ObjectID and returns other ids unchanged
+func MongoID(id interface{}) interface{} {
+	if s, ok := id.(string); ok {
+		if oid, err := primitive.ObjectIDFromHex(s); err == nil {
+			return oid
+		}
+	}
+	return id
+}
+
+// setMongoObjectID sets the _id field of doc to a new ObjectID if it is
+// an empty ObjectID or string
+func setMongoObjectID(doc any) {
+	v := reflect.ValueOf(doc).Elem()
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("bson"), ",")
+		if name != "_id" {
+			continue
+		}
+		field := v.Field(i)
+		switch {
+		case field.Type() == reflect.TypeOf(primitive.ObjectID{}) && field.IsZero():
+			field.Set(reflect.ValueOf(primitive.NewObjectID()))
+		case field.Kind() == reflect.String && field.String() == "":
+			field.SetString(primitive.NewObjectID().Hex())
+		}
+		return
+	}
+}
+
+// MongoUpdate builds an update document:
+//
+//	goTap.MongoSet("status", "paid").Inc("visits", 1).Push("log", entry)
+type MongoUpdate struct {
+	ops bson.M
+}
+
+// NewMongoUpdate creates an empty update
+func NewMongoUpdate() *MongoUpdate {
+	return &MongoUpdate{ops: bson.M{}}
+}
+
+// MongoSet starts an update setting field to value
+func MongoSet(field string, value interface{}) *MongoUpdate {
+	return NewMongoUpdate().Set(field, value)
+}
+
+// MongoUnset starts an update removing field
+func MongoUnset(field string) *MongoUpdate {
+	return NewMongoUpdate().Unset(field)
+}
+
+func (u *MongoUpdate) op(operator, field string, value interface{}) *MongoUpdate {
+	fields, ok := u.ops[operator].(bson.M)
+	if !ok {
+		fields = bson.M{}
+		u.ops[operator] = fields
+	}
+	fields[field] = value
+	return u
+}
+
+// Set sets field to value
+func (u *MongoUpdate) Set(field string, value interface{}) *MongoUpdate {
+	return u.op("$set", field, value)
+}
+
+// SetOnInsert sets field to value when an upsert inserts the document
+func (u *MongoUpdate) SetOnInsert(field string, value interface{}) *MongoUpdate {
+	return u.op("$setOnInsert", field, value)
+}
+
+// Unset removes field
+func (u *MongoUpdate) Unset(field string) *MongoUpdate {
+	return u.op("$unset", field, "")
+}
+
+// Inc adds n to field
+func (u *MongoUpdate) Inc(field string, n interface{}) *MongoUpdate {
+	return u.op("$inc", field, n)
+}
+
+// Min sets field to value if value is lower
+func (u *MongoUpdate) Min(field string, value interface{}) *MongoUpdate {
+	return u.op("$min", field, value)
+}
+
+// Max sets field to value if value is higher
+func (u *MongoUpdate) Max(field string, value interface{}) *MongoUpdate {
+	return u.op("$max", field, value)
+}
+
+// Push appends value to the array field
+func (u *MongoUpdate) Push(field string, value interface{}) *MongoUpdate {
+	return u.op("$push", field, value)
+}
+
+// AddToSet appends value to the array field unless present
+func (u *MongoUpdate) AddToSet(field string, value interface{}) *MongoUpdate {
+	return u.op("$addToSet", field, value)
+}
+
+// Pull removes the values matching value from the array field
+func (u *MongoUpdate) Pull(field string, value interface{}) *MongoUpdate {
+	return u.op("$pull", field, value)
+}
+
+// CurrentDate sets field to the current date
+func (u *MongoUpdate) CurrentDate(field string) *MongoUpdate {
+	return u.op("$currentDate", field, true)
+}
+
+// Document returns the update document
+func (u *MongoUpdate) Document() bson.M {
+	return u.ops
+}
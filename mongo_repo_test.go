@@ -0,0 +1,143 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type repoProduct struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	Price     int64              `bson:"price"`
+	Tags      []string           `bson:"tags"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty"`
+}
+
+func TestMongoID(t *testing.T) {
+	oid := primitive.NewObjectID()
+	if MongoID(oid.Hex()) != oid {
+		t.Error("Expected a hex string to become an ObjectID")
+	}
+	if MongoID("SKU123") != "SKU123" || MongoID(42) != 42 {
+		t.Error("Expected other ids to be kept")
+	}
+
+	var p repoProduct
+	setMongoObjectID(&p)
+	if p.ID.IsZero() {
+		t.Error("Expected a new ObjectID")
+	}
+	id := p.ID
+	setMongoObjectID(&p)
+	if p.ID != id {
+		t.Error("Expected an existing ObjectID to be kept")
+	}
+	var s struct {
+		ID string `bson:"_id"`
+	}
+	setMongoObjectID(&s)
+	if _, err := primitive.ObjectIDFromHex(s.ID); err != nil {
+		t.Errorf("Expected a hex ObjectID, got %q", s.ID)
+	}
+}
+
+func TestMongoUpdate(t *testing.T) {
+	update := MongoSet("price", 450).Set("name", "Latte").Inc("stock", -1).Push("tags", "hot").Unset("promo")
+	want := bson.M{
+		"$set":   bson.M{"price": 450, "name": "Latte"},
+		"$inc":   bson.M{"stock": -1},
+		"$push":  bson.M{"tags": "hot"},
+		"$unset": bson.M{"promo": ""},
+	}
+	if !reflect.DeepEqual(update.Document(), want) {
+		t.Errorf("Expected %v, got %v", want, update.Document())
+	}
+}
+
+func TestMongoRepoScope(t *testing.T) {
+	repo := &MongoRepo[repoProduct]{MongoRepository: &MongoRepository{}}
+	filter := bson.M{"name": "Latte"}
+	if !reflect.DeepEqual(repo.scope(filter, false), filter) {
+		t.Error("Expected the filter unchanged without soft delete")
+	}
+	repo.WithSoftDelete("")
+	want := bson.M{"$and": bson.A{filter, bson.M{"deleted_at": nil}}}
+	if got := repo.scope(filter, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	want = bson.M{"$and": bson.A{bson.M{}, bson.M{"deleted_at": bson.M{"$ne": nil}}}}
+	if got := repo.scope(nil, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestMongoRepo(t *testing.T) {
+	client := skipIfNoMongo(t)
+	if client == nil {
+		return
+	}
+	defer client.Close()
+	ctx := context.Background()
+	client.Collection("test_repo_products").Drop(ctx)
+
+	repo := NewMongoRepo[repoProduct](client, "test_repo_products").WithSoftDelete("")
+	for i, name := range []string{"Coffee", "Latte", "Bagel"} {
+		if err := repo.Create(ctx, &repoProduct{Name: name, Price: int64(300 + i*50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latte, err := repo.FindOneAs(ctx, bson.M{"name": "Latte"})
+	if err != nil || latte.ID.IsZero() || latte.Price != 350 {
+		t.Fatalf("Unexpected product %+v %v", latte, err)
+	}
+	if err := repo.Update(ctx, latte.ID.Hex(), MongoSet("price", 400).Push("tags", "hot")); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := repo.FindByIDAs(ctx, latte.ID.Hex()); p == nil || p.Price != 400 || len(p.Tags) != 1 {
+		t.Errorf("Expected the updated product, got %+v", p)
+	}
+
+	page := &MongoPagination{Page: 1, PageSize: 2}
+	list, err := repo.FindAllAs(ctx, nil, page, options.Find().SetSort(bson.M{"price": 1}))
+	if err != nil || len(list) != 2 || list[0].Name != "Coffee" || page.Total != 3 || page.Pages != 2 {
+		t.Errorf("Unexpected page %+v %+v %v", list, page, err)
+	}
+
+	if err := repo.Delete(ctx, latte.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByIDAs(ctx, latte.ID); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the deleted product to be hidden, got %v", err)
+	}
+	if trashed, _ := repo.FindTrashedAs(ctx, nil, nil); len(trashed) != 1 || trashed[0].DeletedAt == nil {
+		t.Errorf("Expected the trashed product, got %+v", trashed)
+	}
+	if err := repo.Update(ctx, latte.ID, MongoSet("price", 1)); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected deleted products not to be updated, got %v", err)
+	}
+	if err := repo.Restore(ctx, latte.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := repo.Count(ctx, nil); n != 3 {
+		t.Errorf("Expected 3 products after the restore, got %d", n)
+	}
+	if err := repo.ForceDelete(ctx, latte.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Restore(ctx, latte.ID); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the removed product to be gone, got %v", err)
+	}
+}
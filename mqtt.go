@@ -0,0 +1,307 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MethodMQTT is the request method of the Context built for MQTT messages
+const MethodMQTT = "MQTT"
+
+// MQTTMessage is a message received on an MQTT topic
+type MQTTMessage struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retained bool
+
+	// Properties are MQTT 5 user properties, e.g. "authorization"
+	Properties map[string]string
+
+	// ResponseTopic is the MQTT 5 response topic. A handler's response
+	// body is published there.
+	ResponseTopic string
+}
+
+// MQTTClient is the part of an MQTT client goTap uses. goTap does not
+// depend on an MQTT library; wrap a paho client connected to an external
+// broker, or the inline client of an embedded broker such as mochi-mqtt,
+// to implement it.
+type MQTTClient interface {
+	// Publish sends payload on topic
+	Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+
+	// Subscribe calls handler for each message matching filter, which may
+	// contain the + and # wildcards
+	Subscribe(filter string, qos byte, handler func(msg *MQTTMessage)) error
+
+	// Unsubscribe removes the subscriptions of filters
+	Unsubscribe(filters ...string) error
+}
+
+// MQTTInject injects an MQTT client into context, so HTTP handlers can
+// publish to devices
+func MQTTInject(client MQTTClient) HandlerFunc {
+	return Inject("mqtt", client)
+}
+
+// GetMQTT retrieves the MQTT client from context
+func GetMQTT(c *Context) (MQTTClient, bool) {
+	return Get[MQTTClient](c, "mqtt")
+}
+
+// MustGetMQTT retrieves the MQTT client from context or panics
+func MustGetMQTT(c *Context) MQTTClient {
+	client, ok := GetMQTT(c)
+	if !ok {
+		panic("MQTT client not found in context. Did you forget to use MQTTInject()?")
+	}
+	return client
+}
+
+// MQTTConfig holds configuration for an MQTTBridge
+type MQTTConfig struct {
+	// QoS is the quality of service of the subscriptions
+	// Default: 0 (at most once)
+	QoS byte
+
+	// ErrorHandler is called when a handler responds with a status of 400
+	// or above, adds errors with c.Error or panics
+	// Default: logs a warning
+	ErrorHandler func(msg *MQTTMessage, status int, err error)
+}
+
+// MQTTBridge routes MQTT messages to goTap handlers, e.g. for payment
+// terminals that speak MQTT:
+//
+//	bridge := r.MQTT(client, goTap.MQTTConfig{QoS: 1})
+//	bridge.Use(goTap.JWTAuth(secret))
+//	bridge.Handle("terminals/:id/status", func(c *goTap.Context) {
+//	    var status TerminalStatus
+//	    if err := c.ShouldBindJSON(&status); err != nil {
+//	        c.Error(err)
+//	        return
+//	    }
+//	    updateTerminal(c.Param("id"), status)
+//	})
+//
+// Each message is handled with a Context whose method is MethodMQTT, whose
+// path is the topic and whose body is the payload. MQTT 5 user properties
+// become request headers, so JWTAuth authenticates devices with the same
+// tokens as HTTP clients when they send an "authorization" property. The
+// response body is published to the message's response topic, if any.
+type MQTTBridge struct {
+	engine *Engine
+	client MQTTClient
+	config MQTTConfig
+
+	mu       sync.Mutex
+	handlers HandlersChain
+	filters  []string
+	closed   bool
+}
+
+// MQTT returns a bridge subscribing client to the topics of its handlers.
+// The subscriptions are removed on server shutdown.
+func (engine *Engine) MQTT(client MQTTClient, config MQTTConfig) *MQTTBridge {
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(msg *MQTTMessage, status int, err error) {
+			debugPrint("[WARNING] MQTT message on %s failed with %d: %v", msg.Topic, status, err)
+		}
+	}
+	b := &MQTTBridge{engine: engine, client: client, config: config}
+	engine.addService("mqtt bridge", b.Close)
+	return b
+}
+
+// Use adds middleware to the handlers registered afterwards
+func (b *MQTTBridge) Use(middleware ...HandlerFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, middleware...)
+}
+
+// Handle subscribes to the topics matching pattern. Levels of the form
+// ":name" match one level and "*name", as the last level, the rest of the
+// topic, both available with c.Param; the MQTT wildcards + and # match
+// without a name.
+func (b *MQTTBridge) Handle(pattern string, handlers ...HandlerFunc) error {
+	filter, err := mqttFilter(pattern)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrConnectionClosed
+	}
+	chain := make(HandlersChain, 0, len(b.handlers)+len(handlers))
+	chain = append(append(chain, b.handlers...), handlers...)
+	b.mu.Unlock()
+
+	err = b.client.Subscribe(filter, b.config.QoS, func(msg *MQTTMessage) {
+		b.dispatch(pattern, chain, msg)
+	})
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.filters = append(b.filters, filter)
+	b.mu.Unlock()
+	return nil
+}
+
+// Publish sends payload on topic
+func (b *MQTTBridge) Publish(ctx context.Context, topic string, payload []byte) error {
+	return b.client.Publish(ctx, topic, b.config.QoS, false, payload)
+}
+
+// PublishJSON sends v as JSON on topic
+func (b *MQTTBridge) PublishJSON(ctx context.Context, topic string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Publish(ctx, topic, payload)
+}
+
+// Close removes the subscriptions
+func (b *MQTTBridge) Close(ctx context.Context) error {
+	b.mu.Lock()
+	filters := b.filters
+	b.filters, b.closed = nil, true
+	b.mu.Unlock()
+	if len(filters) == 0 {
+		return nil
+	}
+	return b.client.Unsubscribe(filters...)
+}
+
+// dispatch runs the handlers of pattern for msg
+func (b *MQTTBridge) dispatch(pattern string, handlers HandlersChain, msg *MQTTMessage) {
+	params, ok := mqttParams(pattern, msg.Topic)
+	if !ok {
+		return
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), MethodMQTT, "/", bytes.NewReader(msg.Payload))
+	req.URL = &url.URL{Path: msg.Topic}
+	req.RequestURI = msg.Topic
+	req.Header.Set("Content-Type", MIMEJSON)
+	for k, v := range msg.Properties {
+		req.Header.Set(k, v)
+	}
+
+	w := &mqttResponse{header: make(http.Header)}
+	c := b.engine.pool.Get().(*Context)
+	c.writermem.reset(w)
+	c.Request = req
+	c.reset()
+	c.Params = params
+	c.fullPath = pattern
+	c.handlers = handlers
+	defer b.engine.pool.Put(c)
+
+	if err := b.run(c); err != nil {
+		b.config.ErrorHandler(msg, http.StatusInternalServerError, err)
+		return
+	}
+	status := c.Writer.Status()
+	if status >= http.StatusBadRequest || len(c.Errors) > 0 {
+		err := errors.New(http.StatusText(status))
+		if last := c.Errors.Last(); last != nil {
+			err = last
+		}
+		b.config.ErrorHandler(msg, status, err)
+	}
+	if msg.ResponseTopic != "" && w.body.Len() > 0 {
+		if err := b.client.Publish(context.Background(), msg.ResponseTopic, b.config.QoS, false, w.body.Bytes()); err != nil {
+			b.config.ErrorHandler(msg, status, err)
+		}
+	}
+}
+
+// run calls the handlers, recovering a panic so it doesn't crash the
+// client's delivery goroutine
+func (b *MQTTBridge) run(c *Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	c.Next()
+	return nil
+}
+
+// mqttFilter converts a handler pattern to an MQTT topic filter
+func mqttFilter(pattern string) (string, error) {
+	levels := strings.Split(pattern, "/")
+	for i, level := range levels {
+		switch {
+		case level == "+" || strings.HasPrefix(level, ":"):
+			levels[i] = "+"
+		case level == "#" || strings.HasPrefix(level, "*"):
+			if i != len(levels)-1 {
+				return "", fmt.Errorf("goTap: %q must be the last level of MQTT pattern %q", level, pattern)
+			}
+			levels[i] = "#"
+		case strings.ContainsAny(level, "+#"):
+			return "", fmt.Errorf("goTap: invalid MQTT pattern %q", pattern)
+		}
+	}
+	return strings.Join(levels, "/"), nil
+}
+
+// mqttParams matches topic against pattern and returns its named levels
+func mqttParams(pattern, topic string) (Params, bool) {
+	var params Params
+	patternLevels := strings.Split(pattern, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range patternLevels {
+		if level == "#" || strings.HasPrefix(level, "*") {
+			if level != "#" && len(level) > 1 {
+				params = append(params, Param{Key: level[1:], Value: strings.Join(topicLevels[min(i, len(topicLevels)):], "/")})
+			}
+			return params, true
+		}
+		if i >= len(topicLevels) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(level, ":"):
+			params = append(params, Param{Key: level[1:], Value: topicLevels[i]})
+		case level != "+" && level != topicLevels[i]:
+			return nil, false
+		}
+	}
+	return params, len(patternLevels) == len(topicLevels)
+}
+
+// MQTTTopicMatch reports whether topic matches an MQTT topic filter with
+// the + and # wildcards, e.g. for MQTTClient implementations
+func MQTTTopicMatch(filter, topic string) bool {
+	_, ok := mqttParams(filter, topic)
+	return ok
+}
+
+// mqttResponse captures the response of an MQTT handler
+type mqttResponse struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *mqttResponse) Header() http.Header         { return w.header }
+func (w *mqttResponse) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *mqttResponse) WriteHeader(int)             {}
@@ -0,0 +1,149 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryMQTT is an in-process broker delivering messages synchronously
+type memoryMQTT struct {
+	mu        sync.Mutex
+	subs      map[string]func(*MQTTMessage)
+	published []*MQTTMessage
+}
+
+func (m *memoryMQTT) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	m.deliver(&MQTTMessage{Topic: topic, QoS: qos, Retained: retained, Payload: payload})
+	return nil
+}
+
+func (m *memoryMQTT) Subscribe(filter string, qos byte, handler func(*MQTTMessage)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subs == nil {
+		m.subs = make(map[string]func(*MQTTMessage))
+	}
+	m.subs[filter] = handler
+	return nil
+}
+
+func (m *memoryMQTT) Unsubscribe(filters ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, filter := range filters {
+		delete(m.subs, filter)
+	}
+	return nil
+}
+
+func (m *memoryMQTT) deliver(msg *MQTTMessage) {
+	m.mu.Lock()
+	m.published = append(m.published, msg)
+	var handlers []func(*MQTTMessage)
+	for filter, handler := range m.subs {
+		if MQTTTopicMatch(filter, msg.Topic) {
+			handlers = append(handlers, handler)
+		}
+	}
+	m.mu.Unlock()
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
+
+func TestMQTTBridge(t *testing.T) {
+	broker := &memoryMQTT{}
+	r := New()
+	var failures []int
+	bridge := r.MQTT(broker, MQTTConfig{QoS: 1, ErrorHandler: func(msg *MQTTMessage, status int, err error) {
+		failures = append(failures, status)
+	}})
+	bridge.Use(JWTAuth("secret"))
+
+	var got struct {
+		Terminal, User, Battery, Rest string
+	}
+	bridge.Handle("terminals/:id/status", func(c *Context) {
+		var status struct {
+			Battery string `json:"battery"`
+		}
+		c.ShouldBindJSON(&status)
+		got.Terminal, got.User, got.Battery = c.Param("id"), c.MustGet("user_id").(string), status.Battery
+		c.JSON(http.StatusOK, H{"ack": true})
+	})
+	bridge.Handle("logs/*path", func(c *Context) { got.Rest = c.Param("path") })
+	bridge.Handle("panics/+", func(c *Context) { panic("boom") })
+
+	token, _ := GenerateJWT("secret", JWTClaims{UserID: "dev-1", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	auth := map[string]string{"authorization": "Bearer " + token}
+	broker.deliver(&MQTTMessage{
+		Topic:         "terminals/T-9/status",
+		Payload:       []byte(`{"battery": "low"}`),
+		Properties:    auth,
+		ResponseTopic: "terminals/T-9/ack",
+	})
+	if got.Terminal != "T-9" || got.User != "dev-1" || got.Battery != "low" {
+		t.Errorf("Unexpected handler input %+v", got)
+	}
+	if last := broker.published[len(broker.published)-1]; last.Topic != "terminals/T-9/ack" || strings.TrimSpace(string(last.Payload)) != `{"ack":true}` {
+		t.Errorf("Expected the response on the response topic, got %s %s", last.Topic, last.Payload)
+	}
+
+	broker.deliver(&MQTTMessage{Topic: "logs/a/b/c", Properties: auth})
+	if got.Rest != "a/b/c" {
+		t.Errorf("Expected the catch-all param, got %q", got.Rest)
+	}
+	broker.deliver(&MQTTMessage{Topic: "terminals/T-9/status"})
+	broker.deliver(&MQTTMessage{Topic: "panics/x", Properties: auth})
+	if len(failures) != 2 || failures[0] != http.StatusUnauthorized || failures[1] != http.StatusInternalServerError {
+		t.Errorf("Expected an unauthorized message and a panic, got %v", failures)
+	}
+
+	bridge.Close(context.Background())
+	if len(broker.subs) != 0 {
+		t.Errorf("Expected Close to unsubscribe, got %d subscriptions", len(broker.subs))
+	}
+	if err := bridge.Handle("late", func(c *Context) {}); err != ErrConnectionClosed {
+		t.Errorf("Expected ErrConnectionClosed, got %v", err)
+	}
+}
+
+func TestMQTTPatterns(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"terminals/:id/status": "terminals/+/status",
+		"logs/*path":           "logs/#",
+		"a/+/b/#":              "a/+/b/#",
+	} {
+		if filter, err := mqttFilter(pattern); err != nil || filter != want {
+			t.Errorf("%s: expected %s, got %s %v", pattern, want, filter, err)
+		}
+	}
+	for _, pattern := range []string{"a/#/b", "a/b+"} {
+		if _, err := mqttFilter(pattern); err == nil {
+			t.Errorf("%s: expected an error", pattern)
+		}
+	}
+
+	for _, tt := range []struct {
+		filter, topic string
+		match         bool
+	}{
+		{"sport/#", "sport", true},
+		{"sport/#", "sport/tennis/player1", true},
+		{"sport/+/player1", "sport/tennis/player1", true},
+		{"sport/+", "sport/tennis/player1", false},
+		{"sport/tennis", "sport/golf", false},
+	} {
+		if MQTTTopicMatch(tt.filter, tt.topic) != tt.match {
+			t.Errorf("MQTTTopicMatch(%q, %q) != %v", tt.filter, tt.topic, tt.match)
+		}
+	}
+}
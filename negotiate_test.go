@@ -0,0 +1,121 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNegotiateFormatQuality(t *testing.T) {
+	offers := []string{MIMEJSON, MIMEXML, MIMEYAML}
+	for accept, want := range map[string]string{
+		"": MIMEJSON,
+		"application/xml;q=0.5, application/json":   MIMEJSON,
+		"application/json;q=0.4, application/xml":   MIMEXML,
+		"text/html, */*;q=0.1":                      MIMEJSON,
+		"application/*;q=0.2, application/json;q=0": MIMEXML,
+		"application/x-yaml, application/*;q=0.9":   MIMEYAML,
+		"application/xml, application/json":         MIMEJSON,
+		"application/json, application/xml":         MIMEJSON,
+		"text/*":                                    MIMEJSON,
+	} {
+		r := New()
+		r.GET("/", func(c *Context) {
+			c.Writer.WriteString(c.NegotiateFormat(offers...))
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Body.String(); got != want {
+			t.Errorf("Accept %q: got %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestNegotiateFormatDefaultOffer(t *testing.T) {
+	r := New()
+	r.DefaultOffer = MIMEXML
+	r.GET("/", func(c *Context) {
+		c.Writer.WriteString(c.NegotiateFormat(MIMEJSON, MIMEXML))
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != MIMEXML {
+		t.Errorf("Expected the default offer, got %q", w.Body.String())
+	}
+}
+
+func TestSetAccepted(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) {
+		c.SetAccepted(MIMEXML)
+		c.Writer.WriteString(c.NegotiateFormat(MIMEJSON, MIMEXML))
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", MIMEJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != MIMEXML {
+		t.Errorf("Expected SetAccepted to override the header, got %q", w.Body.String())
+	}
+}
+
+func TestNegotiateBinaryFormats(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) {
+		c.Negotiate(http.StatusOK, Negotiate{
+			Offered:      []string{MIMEJSON, MIMEYAML, MIMEMsgPack, MIMEProtoBuf},
+			Data:         H{"name": "espresso"},
+			ProtoBufData: wrapperspb.String("espresso"),
+		})
+	})
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(MIMEYAML); w.Body.String() != "name: espresso\n" {
+		t.Errorf("Unexpected YAML %q", w.Body.String())
+	}
+
+	w := get(MIMEMsgPack + ", */*;q=0.1")
+	if ct := w.Header().Get("Content-Type"); ct != MIMEMsgPack {
+		t.Errorf("Expected MsgPack content type, got %q", ct)
+	}
+	var decoded map[string]any
+	if err := codec.NewDecoderBytes(w.Body.Bytes(), new(codec.MsgpackHandle)).Decode(&decoded); err != nil || string(toBytes(decoded["name"])) != "espresso" {
+		t.Errorf("Unexpected MsgPack %v %v", decoded, err)
+	}
+
+	w = get(MIMEProtoBuf)
+	var msg wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &msg); err != nil || msg.GetValue() != "espresso" {
+		t.Errorf("Unexpected ProtoBuf %v %v", msg.GetValue(), err)
+	}
+}
+
+func toBytes(v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
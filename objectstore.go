@@ -0,0 +1,480 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBucket is returned by an ObjectStore for bucket names that
+// aren't a single path segment
+var ErrInvalidBucket = errors.New("invalid bucket name")
+
+// ObjectStore stores objects by bucket and slash-separated key, e.g.
+// receipt images and product photos. Clients can upload and download
+// objects directly with presigned URLs, without passing them through the
+// server.
+type ObjectStore interface {
+	// Put stores size bytes from r under key, replacing any existing object
+	Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the object stored under key, or ErrFileNotFound
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; missing keys are no error
+	Delete(ctx context.Context, bucket, key string) error
+	// PresignPut returns a URL a client can PUT the object to until
+	// expires has passed
+	PresignPut(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+	// PresignGet returns a URL a client can GET the object from until
+	// expires has passed
+	PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+}
+
+// presignExpiry checks the lifetime of a presigned URL: 15 minutes if
+// unset, at most the 7 days S3 accepts
+func presignExpiry(expires time.Duration) (time.Duration, error) {
+	if expires <= 0 {
+		return 15 * time.Minute, nil
+	}
+	if expires < time.Second || expires > 7*24*time.Hour {
+		return 0, fmt.Errorf("presigned URL expiry %v is not between 1s and 7 days", expires)
+	}
+	return expires, nil
+}
+
+// ObjectStoreInject injects an ObjectStore into context for use in handlers:
+//
+//	r.Use(goTap.ObjectStoreInject(goTap.NewS3ObjectStore(goTap.S3Config{
+//	    Endpoint:  "https://s3.eu-west-1.amazonaws.com",
+//	    Region:    "eu-west-1",
+//	    AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+//	    SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//	})))
+func ObjectStoreInject(store ObjectStore) HandlerFunc {
+	return Inject("objectstore", store)
+}
+
+// GetObjectStore retrieves the ObjectStore from context
+func GetObjectStore(c *Context) (ObjectStore, bool) {
+	return Get[ObjectStore](c, "objectstore")
+}
+
+// MustGetObjectStore retrieves the ObjectStore from context or panics
+func MustGetObjectStore(c *Context) ObjectStore {
+	store, ok := GetObjectStore(c)
+	if !ok {
+		panic("ObjectStore not found in context. Did you forget to use ObjectStoreInject()?")
+	}
+	return store
+}
+
+// SaveUploadedFileToStore saves an uploaded file under key in bucket of
+// the injected ObjectStore. The content type is sniffed from the file's
+// first bytes:
+//
+//	fh, _ := c.FormFile("receipt")
+//	file, err := c.SaveUploadedFileToStore(fh, "receipts", "2025/03/"+orderID+".jpg")
+//
+// To check the size and type first, use SaveUploadedFileValidated with
+// BucketStorage.
+func (c *Context) SaveUploadedFileToStore(file *multipart.FileHeader, bucket, key string) (*StoredFile, error) {
+	store := MustGetObjectStore(c)
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	br := bufio.NewReaderSize(src, 512)
+	contentType, err := sniffContentType(br)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(c.Request.Context(), bucket, key, br, file.Size, contentType); err != nil {
+		return nil, err
+	}
+	return &StoredFile{Key: key, Filename: SanitizeFilename(file.Filename), Size: file.Size, ContentType: contentType}, nil
+}
+
+// PresignedUploadURL returns a URL the client can PUT an object to in the
+// injected ObjectStore, e.g. for a product photo taken on a tablet. expires
+// defaults to 15 minutes.
+func (c *Context) PresignedUploadURL(bucket, key string, expires time.Duration) (string, error) {
+	return MustGetObjectStore(c).PresignPut(c.Request.Context(), bucket, key, expires)
+}
+
+// PresignedDownloadURL returns a URL the client can GET an object from in
+// the injected ObjectStore. expires defaults to 15 minutes.
+func (c *Context) PresignedDownloadURL(bucket, key string, expires time.Duration) (string, error) {
+	return MustGetObjectStore(c).PresignGet(c.Request.Context(), bucket, key, expires)
+}
+
+// BucketStorage returns a FileStorage saving to bucket of store, e.g. as
+// UploadRules.Storage or Engine.FileStorage
+func BucketStorage(store ObjectStore, bucket string) FileStorage {
+	return &bucketStorage{store: store, bucket: bucket}
+}
+
+type bucketStorage struct {
+	store  ObjectStore
+	bucket string
+}
+
+func (s *bucketStorage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return s.store.Put(ctx, s.bucket, key, r, size, contentType)
+}
+
+func (s *bucketStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.store.Get(ctx, s.bucket, key)
+}
+
+func (s *bucketStorage) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, s.bucket, key)
+}
+
+// ========== S3 compatible ==========
+
+// S3ObjectStore is an ObjectStore for S3 compatible services such as AWS
+// S3, MinIO, Cloudflare R2 and Google Cloud Storage through its XML API
+// with HMAC keys ("https://storage.googleapis.com"). Requests and
+// presigned URLs are signed with AWS Signature Version 4.
+type S3ObjectStore struct {
+	config S3Config
+	now    func() time.Time
+}
+
+// NewS3ObjectStore returns an ObjectStore for an S3 compatible service;
+// config.Bucket is not used
+func NewS3ObjectStore(config S3Config) *S3ObjectStore {
+	if config.Endpoint == "" {
+		panic("goTap: S3ObjectStore requires Endpoint")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	return &S3ObjectStore{config: config, now: time.Now}
+}
+
+// objectURL returns the URL of key in bucket
+func (s *S3ObjectStore) objectURL(bucket, key string) (*url.URL, error) {
+	if bucket == "" || strings.Contains(bucket, "/") {
+		return nil, ErrInvalidBucket
+	}
+	u, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	p := path.Clean("/" + key)
+	if s.config.VirtualHosted {
+		u.Host = bucket + "." + u.Host
+	} else {
+		p = "/" + bucket + p
+	}
+	// Signatures cover the path encoded the way S3 encodes it
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	u.Path, u.RawPath = p, strings.Join(segments, "/")
+	return u, nil
+}
+
+func (s *S3ObjectStore) do(ctx context.Context, method, bucket, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u, err := s.objectURL(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	signS3Request(req, "UNSIGNED-PAYLOAD", s.config.Region, s.config.AccessKey, s.config.SecretKey, s.now())
+	return s.config.Client.Do(req)
+}
+
+// Put implements ObjectStore
+func (s *S3ObjectStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, bucket, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	return s3Error(resp, http.StatusOK)
+}
+
+// Get implements ObjectStore
+func (s *S3ObjectStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, bucket, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, http.StatusOK)
+	}
+	return resp.Body, nil
+}
+
+// Delete implements ObjectStore
+func (s *S3ObjectStore) Delete(ctx context.Context, bucket, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, bucket, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil
+	}
+	return s3Error(resp, http.StatusNoContent, http.StatusOK)
+}
+
+// PresignPut implements ObjectStore
+func (s *S3ObjectStore) PresignPut(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, bucket, key, expires)
+}
+
+// PresignGet implements ObjectStore
+func (s *S3ObjectStore) PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, bucket, key, expires)
+}
+
+// presign returns a URL authenticated by its query string, signing only
+// the host so clients can send any headers
+func (s *S3ObjectStore) presign(method, bucket, key string, expires time.Duration) (string, error) {
+	expires, err := presignExpiry(expires)
+	if err != nil {
+		return "", err
+	}
+	u, err := s.objectURL(bucket, key)
+	if err != nil {
+		return "", err
+	}
+	amzDate := s.now().UTC().Format("20060102T150405Z")
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+amzDate[:8]+"/"+s.config.Region+"/s3/aws4_request")
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalS3Query(query),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	u.RawQuery = canonicalS3Query(query) + "&X-Amz-Signature=" + s3Signature(canonicalRequest, amzDate, s.config.Region, s.config.SecretKey)
+	return u.String(), nil
+}
+
+// ========== Local disk ==========
+
+// LocalObjectStoreConfig holds configuration for a LocalObjectStore
+type LocalObjectStoreConfig struct {
+	// Dir holds a directory per bucket
+	// Required.
+	Dir string
+
+	// BaseURL is where Handler is mounted, e.g.
+	// "http://localhost:5066/objects"
+	// Required for presigned URLs.
+	BaseURL string
+
+	// Secret signs the presigned URLs
+	// Required for presigned URLs.
+	Secret []byte
+}
+
+// LocalObjectStore is an ObjectStore on local disk, for development and
+// single-server installs. Its presigned URLs are served by Handler.
+type LocalObjectStore struct {
+	config LocalObjectStoreConfig
+	now    func() time.Time
+}
+
+// NewLocalObjectStore returns an ObjectStore writing under config.Dir:
+//
+//	store := goTap.NewLocalObjectStore(goTap.LocalObjectStoreConfig{
+//	    Dir:     "data/objects",
+//	    BaseURL: "http://localhost:5066/objects",
+//	    Secret:  []byte(os.Getenv("OBJECTS_SECRET")),
+//	})
+//	r.GET("/objects/*object", store.Handler())
+//	r.PUT("/objects/*object", store.Handler())
+func NewLocalObjectStore(config LocalObjectStoreConfig) *LocalObjectStore {
+	if config.Dir == "" {
+		panic("goTap: LocalObjectStore requires Dir")
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &LocalObjectStore{config: config, now: time.Now}
+}
+
+// bucket returns the storage of a bucket
+func (s *LocalObjectStore) bucket(bucket string) (*LocalStorage, error) {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\`) {
+		return nil, ErrInvalidBucket
+	}
+	return NewLocalStorage(filepath.Join(s.config.Dir, bucket)), nil
+}
+
+// Put implements ObjectStore
+func (s *LocalObjectStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	storage, err := s.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return storage.Save(ctx, key, r, size, contentType)
+}
+
+// Get implements ObjectStore
+func (s *LocalObjectStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	storage, err := s.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Open(ctx, key)
+}
+
+// Delete implements ObjectStore
+func (s *LocalObjectStore) Delete(ctx context.Context, bucket, key string) error {
+	storage, err := s.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return storage.Delete(ctx, key)
+}
+
+// PresignPut implements ObjectStore
+func (s *LocalObjectStore) PresignPut(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, bucket, key, expires)
+}
+
+// PresignGet implements ObjectStore
+func (s *LocalObjectStore) PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, bucket, key, expires)
+}
+
+func (s *LocalObjectStore) presign(method, bucket, key string, expires time.Duration) (string, error) {
+	if s.config.BaseURL == "" || len(s.config.Secret) == 0 {
+		return "", errors.New("goTap: LocalObjectStore presigned URLs require BaseURL and Secret")
+	}
+	expires, err := presignExpiry(expires)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.bucket(bucket); err != nil {
+		return "", err
+	}
+	object := bucket + path.Clean("/"+key)
+	deadline := strconv.FormatInt(s.now().Add(expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", deadline)
+	query.Set("signature", s.signature(method, object, deadline))
+	return s.config.BaseURL + (&url.URL{Path: "/" + object}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+// signature authenticates method on object until the Unix time deadline
+func (s *LocalObjectStore) signature(method, object, deadline string) string {
+	mac := hmac.New(sha256.New, s.config.Secret)
+	mac.Write([]byte(method + "\n" + object + "\n" + deadline))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler returns a handler serving the presigned URLs, mounted for GET
+// and PUT on a route ending with a catch-all parameter at BaseURL
+func (s *LocalObjectStore) Handler() HandlerFunc {
+	return func(c *Context) {
+		object := strings.TrimPrefix(path.Clean(c.Params[len(c.Params)-1].Value), "/")
+		method := c.Request.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		deadline := c.Query("expires")
+		unix, err := strconv.ParseInt(deadline, 10, 64)
+		expected := s.signature(method, object, deadline)
+		if err != nil || s.now().Unix() > unix || len(s.config.Secret) == 0 ||
+			!hmac.Equal([]byte(c.Query("signature")), []byte(expected)) {
+			c.JSON(http.StatusForbidden, H{
+				"error":   "Forbidden",
+				"message": "invalid or expired signature",
+			})
+			c.Abort()
+			return
+		}
+		bucket, key, _ := strings.Cut(object, "/")
+
+		switch c.Request.Method {
+		case http.MethodPut:
+			err = s.Put(c.Request.Context(), bucket, key, c.Request.Body, c.Request.ContentLength, c.ContentType())
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, H{
+					"error":   "Internal Server Error",
+					"message": "failed to store object",
+				})
+				c.Abort()
+				return
+			}
+			c.Status(http.StatusOK)
+		case http.MethodGet, http.MethodHead:
+			f, err := s.Get(c.Request.Context(), bucket, key)
+			if errors.Is(err, ErrFileNotFound) {
+				c.JSON(http.StatusNotFound, H{"error": "Not Found", "message": "object not found"})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.Error(err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			defer f.Close()
+			if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+				c.Header("Content-Type", ct)
+			}
+			if rs, ok := f.(io.ReadSeeker); ok {
+				http.ServeContent(c.Writer, c.Request, key, time.Time{}, rs)
+				return
+			}
+			c.Status(http.StatusOK)
+			io.Copy(c.Writer, f)
+		default:
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+// OptionFunc configures an Engine created with New or Default, so apps
+// embedding goTap don't have to change Engine fields after construction:
+//
+//	r := goTap.New(
+//	    goTap.WithLogger(goTap.LoggerWithConfig(goTap.LoggerConfig{Output: appLog})),
+//	    goTap.WithRecovery(goTap.RecoveryWithWriter(appLog)),
+//	    goTap.WithMaxMultipartMemory(16<<20),
+//	    goTap.WithoutDebugBanner(),
+//	)
+//
+// Options are applied in order, before middleware is installed.
+type OptionFunc func(*Engine)
+
+// WithLogger installs logger as the first middleware. With Default it
+// replaces Logger().
+func WithLogger(logger HandlerFunc) OptionFunc {
+	return func(engine *Engine) {
+		engine.optLogger = logger
+	}
+}
+
+// WithRecovery installs recovery after the logger. With Default it
+// replaces Recovery().
+func WithRecovery(recovery HandlerFunc) OptionFunc {
+	return func(engine *Engine) {
+		engine.optRecovery = recovery
+	}
+}
+
+// WithMiddleware installs middleware after the logger and recovery
+func WithMiddleware(middleware ...HandlerFunc) OptionFunc {
+	return func(engine *Engine) {
+		engine.optMiddleware = append(engine.optMiddleware, middleware...)
+	}
+}
+
+// WithMaxMultipartMemory sets Engine.MaxMultipartMemory, the memory used
+// to parse multipart forms before files go to disk
+func WithMaxMultipartMemory(size int64) OptionFunc {
+	return func(engine *Engine) {
+		engine.MaxMultipartMemory = size
+	}
+}
+
+// WithTrustedProxies sets the trusted proxies, see
+// Engine.SetTrustedProxies. It panics on an invalid address.
+func WithTrustedProxies(proxies ...string) OptionFunc {
+	return func(engine *Engine) {
+		if err := engine.SetTrustedProxies(proxies); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WithKVStore sets Engine.KVStore
+func WithKVStore(store KVStore) OptionFunc {
+	return func(engine *Engine) {
+		engine.KVStore = store
+	}
+}
+
+// WithDefaultLocale sets Engine.DefaultLocale
+func WithDefaultLocale(locale string) OptionFunc {
+	return func(engine *Engine) {
+		engine.DefaultLocale = locale
+	}
+}
+
+// WithoutDebugBanner doesn't print the version banner and the Default
+// warning in debug mode
+func WithoutDebugBanner() OptionFunc {
+	return func(engine *Engine) {
+		engine.noBanner = true
+	}
+}
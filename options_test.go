@@ -0,0 +1,89 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	var calls []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) {
+			calls = append(calls, name)
+			c.Next()
+		}
+	}
+	store := NewMemoryKVStore()
+	r := New(
+		WithMiddleware(mark("middleware")),
+		WithRecovery(mark("recovery")),
+		WithLogger(mark("logger")),
+		WithMaxMultipartMemory(16<<20),
+		WithKVStore(store),
+		WithDefaultLocale("de-DE"),
+		WithTrustedProxies("10.0.0.0/8"),
+	)
+	r.GET("/", func(c *Context) { c.String(http.StatusOK, c.Locale()) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if strings.Join(calls, ",") != "logger,recovery,middleware" {
+		t.Errorf("Unexpected middleware order %v", calls)
+	}
+	if r.MaxMultipartMemory != 16<<20 || r.KVStore != store || w.Body.String() != "de-DE" {
+		t.Errorf("Options not applied: %d %v %q", r.MaxMultipartMemory, r.KVStore, w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid proxy to panic")
+		}
+	}()
+	New(WithTrustedProxies("not-an-ip"))
+}
+
+func TestDefaultOptions(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	var out bytes.Buffer
+	old := DefaultWriter
+	DefaultWriter = &out
+	defer func() { DefaultWriter = old }()
+
+	recovered := false
+	r := Default(WithRecovery(func(c *Context) {
+		defer func() {
+			if recover() != nil {
+				recovered = true
+				c.AbortWithStatus(http.StatusTeapot)
+			}
+		}()
+		c.Next()
+	}), WithoutDebugBanner())
+	if out.Len() != 0 {
+		t.Errorf("Expected no banner, got %q", out.String())
+	}
+	r.GET("/panic", func(c *Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if !recovered || w.Code != http.StatusTeapot || len(r.Handlers) != 2 {
+		t.Errorf("Expected the custom recovery to replace the default, got %d with %d handlers", w.Code, len(r.Handlers))
+	}
+	if !strings.Contains(out.String(), "/panic") {
+		t.Errorf("Expected the default logger to log the request, got %q", out.String())
+	}
+
+	out.Reset()
+	New()
+	if !strings.Contains(out.String(), "goTap v"+Version) {
+		t.Errorf("Expected the banner, got %q", out.String())
+	}
+}
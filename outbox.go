@@ -0,0 +1,632 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbox message statuses
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+	OutboxFailed    = "failed"
+)
+
+// ErrNoOutboxPublisher is recorded on messages no OutboxRoute matches
+var ErrNoOutboxPublisher = errors.New("no outbox publisher for topic")
+
+// OutboxMessage is an event stored in the outbox table until it has been
+// delivered
+type OutboxMessage struct {
+	ID            uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Topic         string          `gorm:"size:255;not null" json:"topic"`
+	Payload       json.RawMessage `gorm:"type:text" json:"payload"`
+	Status        string          `gorm:"size:16;not null;index:idx_outbox_due,priority:1" json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `gorm:"size:1024" json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// PublishOutboxEvent stores an event in the outbox using tx. When tx is a
+// transaction the event is only delivered if the transaction commits.
+// The payload is stored as JSON; []byte and json.RawMessage are stored as is.
+func PublishOutboxEvent(tx *DB, topic string, payload any) error {
+	var data []byte
+	switch p := payload.(type) {
+	case json.RawMessage:
+		data = p
+	case []byte:
+		data = p
+	default:
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("outbox: encoding %s payload: %w", topic, err)
+		}
+	}
+
+	now := time.Now()
+	return tx.Create(&OutboxMessage{
+		Topic:         topic,
+		Payload:       data,
+		Status:        OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// PublishEvent stores an event in the outbox using the request's GORM
+// handle. Inside GormTransaction the event commits or rolls back together
+// with the handler's writes:
+//
+//	r.POST("/orders", goTap.GormTransaction(), func(c *goTap.Context) {
+//	    tx := goTap.MustGetGorm(c)
+//	    tx.Create(&order)
+//	    if err := c.PublishEvent("order.created", order); err != nil {
+//	        c.Error(err) // rolls back the order too
+//	    }
+//	})
+func (c *Context) PublishEvent(topic string, payload any) error {
+	db, ok := GetGorm(c)
+	if !ok {
+		return errors.New("outbox: no GORM database in context, use GormInject()")
+	}
+	return PublishOutboxEvent(db.WithContext(c.Request.Context()), topic, payload)
+}
+
+// OutboxPublisher delivers outbox messages to a broker. Delivery is
+// at-least-once: a message may be published again after a crash or a
+// failed attempt, so consumers should deduplicate by message ID.
+type OutboxPublisher interface {
+	Publish(ctx context.Context, msg *OutboxMessage) error
+}
+
+// OutboxPublisherFunc adapts a function to the OutboxPublisher interface
+type OutboxPublisherFunc func(ctx context.Context, msg *OutboxMessage) error
+
+// Publish calls f(ctx, msg)
+func (f OutboxPublisherFunc) Publish(ctx context.Context, msg *OutboxMessage) error {
+	return f(ctx, msg)
+}
+
+// OutboxRoute sends messages whose topic matches Pattern to Publisher.
+// Patterns use the EventBus syntax, e.g. "order.*".
+type OutboxRoute struct {
+	Pattern   string
+	Publisher OutboxPublisher
+}
+
+// OutboxConfig holds configuration for an OutboxDispatcher
+type OutboxConfig struct {
+	// DB holds the outbox table, created on NewOutboxDispatcher
+	DB *DB
+
+	// Routes select the publishers of each message. A message is delivered
+	// once every matching publisher succeeded; a failure retries all of them.
+	Routes []OutboxRoute
+
+	// PollInterval is how often the table is checked for due messages
+	// Default: 1 second
+	PollInterval time.Duration
+
+	// BatchSize is the number of messages claimed per poll
+	// Default: 100
+	BatchSize int
+
+	// MaxAttempts is the number of deliveries tried before a message is
+	// marked failed
+	// Default: 10
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first retry, doubling per attempt
+	// up to 5 minutes
+	// Default: 1 second
+	RetryBackoff time.Duration
+
+	// LockTimeout is how long a claimed message is hidden from other
+	// dispatchers; messages of a crashed dispatcher are retried after it
+	// Default: 30 seconds
+	LockTimeout time.Duration
+
+	// Retention is how long delivered messages are kept; negative keeps them
+	// Default: 24 hours
+	Retention time.Duration
+
+	// OnFailure is called when a message is marked failed
+	// Default: logs a warning
+	OnFailure func(msg OutboxMessage, err error)
+}
+
+// OutboxStats reports outbox activity since the dispatcher started, and the
+// number of pending messages
+type OutboxStats struct {
+	Pending   int64 `json:"pending"`
+	Delivered int64 `json:"delivered"`
+	Retried   int64 `json:"retried"`
+	Failed    int64 `json:"failed"`
+}
+
+// OutboxDispatcher delivers outbox messages in the background. Several
+// dispatchers, e.g. one per app instance, can share a table; each message is
+// claimed by one of them at a time.
+type OutboxDispatcher struct {
+	config OutboxConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	started   bool
+	stopped   bool
+	stop      chan struct{}
+	done      chan struct{}
+	lastPrune time.Time
+
+	delivered, retried, failed atomic.Int64
+}
+
+// NewOutboxDispatcher creates the outbox table if needed and returns a
+// dispatcher; call Start to begin delivering
+func NewOutboxDispatcher(config OutboxConfig) (*OutboxDispatcher, error) {
+	if config.DB == nil {
+		return nil, errors.New("outbox: DB is required")
+	}
+	for _, route := range config.Routes {
+		if _, err := path.Match(route.Pattern, ""); err != nil || route.Publisher == nil {
+			return nil, fmt.Errorf("outbox: invalid route %q", route.Pattern)
+		}
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = 30 * time.Second
+	}
+	if config.Retention == 0 {
+		config.Retention = 24 * time.Hour
+	}
+	if config.OnFailure == nil {
+		config.OnFailure = func(msg OutboxMessage, err error) {
+			debugPrint("[WARNING] outbox message %d (%s) failed after %d attempts: %v", msg.ID, msg.Topic, msg.Attempts, err)
+		}
+	}
+
+	if err := config.DB.AutoMigrate(&OutboxMessage{}); err != nil {
+		return nil, fmt.Errorf("outbox: creating table: %w", err)
+	}
+	return &OutboxDispatcher{config: config, now: time.Now}, nil
+}
+
+// Start begins polling for due messages
+func (d *OutboxDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started || d.stopped {
+		return
+	}
+	d.started = true
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			// Keep going while full batches are found
+			for {
+				n, err := d.Dispatch(context.Background())
+				if err != nil {
+					debugPrint("[WARNING] outbox dispatch failed: %v", err)
+				}
+				if n < d.config.BatchSize {
+					break
+				}
+				select {
+				case <-d.stop:
+					return
+				default:
+				}
+			}
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling, waits for the current batch to finish and closes the
+// publishers having a Close(context.Context) error method, such as webhook
+// publishers
+func (d *OutboxDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return nil
+	}
+	d.stopped = true
+	started := d.started
+	d.mu.Unlock()
+
+	if started {
+		close(d.stop)
+		select {
+		case <-d.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	for _, route := range d.config.Routes {
+		if closer, ok := route.Publisher.(interface{ Close(context.Context) error }); ok {
+			if err := closer.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dispatch claims up to BatchSize due messages and delivers them, returning
+// the number claimed. It is called by the polling loop and can be used to
+// flush the outbox, e.g. in tests.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	db := d.config.DB.WithContext(ctx)
+	now := d.now()
+
+	var due []OutboxMessage
+	err := db.Where("status = ? AND next_attempt_at <= ?", OutboxPending, now).
+		Order("id").Limit(d.config.BatchSize).Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	for i := range due {
+		msg := &due[i]
+		// Claim the message by counting the attempt and pushing its next
+		// attempt past the lock timeout; when several dispatchers race for
+		// it, the attempts check lets only the first one through
+		lockedUntil := now.Add(d.config.LockTimeout)
+		res := db.Model(&OutboxMessage{}).
+			Where("id = ? AND status = ? AND attempts = ?", msg.ID, OutboxPending, msg.Attempts).
+			Updates(map[string]any{"attempts": msg.Attempts + 1, "next_attempt_at": lockedUntil})
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		claimed++
+		msg.Attempts++
+		msg.NextAttemptAt = lockedUntil
+		if err := d.deliver(ctx, db, msg); err != nil {
+			return claimed, err
+		}
+	}
+
+	d.prune(db, now)
+	return claimed, nil
+}
+
+// deliver publishes a claimed message and records the outcome
+func (d *OutboxDispatcher) deliver(ctx context.Context, db *gorm.DB, msg *OutboxMessage) error {
+	err := d.publish(ctx, msg)
+
+	updates := map[string]any{}
+	switch {
+	case err == nil:
+		delivered := d.now()
+		updates["status"] = OutboxDelivered
+		updates["delivered_at"] = delivered
+		updates["last_error"] = ""
+		d.delivered.Add(1)
+	case msg.Attempts >= d.config.MaxAttempts:
+		updates["status"] = OutboxFailed
+		updates["last_error"] = truncateError(err)
+		d.failed.Add(1)
+	default:
+		updates["next_attempt_at"] = d.now().Add(backoffDelay(d.config.RetryBackoff, msg.Attempts, 5*time.Minute))
+		updates["last_error"] = truncateError(err)
+		d.retried.Add(1)
+	}
+
+	if dbErr := db.Model(&OutboxMessage{}).Where("id = ?", msg.ID).Updates(updates).Error; dbErr != nil {
+		return dbErr
+	}
+	if err != nil && msg.Attempts >= d.config.MaxAttempts {
+		msg.Status = OutboxFailed
+		d.config.OnFailure(*msg, err)
+	}
+	return nil
+}
+
+// publish sends msg to every matching route
+func (d *OutboxDispatcher) publish(ctx context.Context, msg *OutboxMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("outbox publisher panicked: %v", r)
+		}
+	}()
+
+	matched := false
+	for _, route := range d.config.Routes {
+		if ok, _ := path.Match(route.Pattern, msg.Topic); !ok {
+			continue
+		}
+		matched = true
+		if err := route.Publisher.Publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return ErrNoOutboxPublisher
+	}
+	return nil
+}
+
+// prune deletes delivered messages past the retention, at most once a minute
+func (d *OutboxDispatcher) prune(db *gorm.DB, now time.Time) {
+	if d.config.Retention < 0 {
+		return
+	}
+	d.mu.Lock()
+	if now.Sub(d.lastPrune) < time.Minute {
+		d.mu.Unlock()
+		return
+	}
+	d.lastPrune = now
+	d.mu.Unlock()
+
+	err := db.Where("status = ? AND delivered_at < ?", OutboxDelivered, now.Add(-d.config.Retention)).
+		Delete(&OutboxMessage{}).Error
+	if err != nil {
+		debugPrint("[WARNING] outbox prune failed: %v", err)
+	}
+}
+
+// Retry moves failed messages back to pending, e.g. after fixing a broken
+// webhook endpoint, and returns how many were requeued
+func (d *OutboxDispatcher) Retry(ctx context.Context) (int64, error) {
+	res := d.config.DB.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("status = ?", OutboxFailed).
+		Updates(map[string]any{"status": OutboxPending, "attempts": 0, "next_attempt_at": d.now()})
+	return res.RowsAffected, res.Error
+}
+
+// Stats returns delivery counters and the number of pending messages
+func (d *OutboxDispatcher) Stats() OutboxStats {
+	var pending int64
+	d.config.DB.Model(&OutboxMessage{}).Where("status = ?", OutboxPending).Count(&pending)
+	return OutboxStats{
+		Pending:   pending,
+		Delivered: d.delivered.Load(),
+		Retried:   d.retried.Load(),
+		Failed:    d.failed.Load(),
+	}
+}
+
+// Outbox creates and starts an outbox dispatcher that is stopped with the
+// server:
+//
+//	outbox, err := r.Outbox(goTap.OutboxConfig{
+//	    DB: db,
+//	    Routes: []goTap.OutboxRoute{
+//	        {Pattern: "order.*", Publisher: goTap.NewRedisOutboxPublisher(redisClient)},
+//	        {Pattern: "*", Publisher: goTap.NewWebhookOutboxPublisher(goTap.WebhookPublisherConfig{URL: hookURL})},
+//	    },
+//	})
+func (engine *Engine) Outbox(config OutboxConfig) (*OutboxDispatcher, error) {
+	d, err := NewOutboxDispatcher(config)
+	if err != nil {
+		return nil, err
+	}
+	d.Start()
+	engine.addService("outbox dispatcher", d.Stop)
+	return d, nil
+}
+
+// backoffDelay returns the retry delay after the given number of attempts,
+// doubling base per attempt up to max
+func backoffDelay(base time.Duration, attempts int, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > 1024 {
+		msg = msg[:1024]
+	}
+	return msg
+}
+
+// ========== Publishers ==========
+
+// NewRedisOutboxPublisher publishes messages on the Redis pub/sub channel
+// named after the topic
+func NewRedisOutboxPublisher(client *RedisClient) OutboxPublisher {
+	return OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		return client.Client.Publish(ctx, msg.Topic, []byte(msg.Payload)).Err()
+	})
+}
+
+// KafkaProducer is the part of a Kafka client the outbox needs. Wrap the
+// client of your choice, e.g. a kafka-go Writer or a sarama SyncProducer.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+}
+
+// NewKafkaOutboxPublisher produces messages to the Kafka topic named after
+// the outbox topic, keyed by message ID so consumers can deduplicate
+func NewKafkaOutboxPublisher(producer KafkaProducer) OutboxPublisher {
+	return OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		id := strconv.FormatUint(msg.ID, 10)
+		return producer.Produce(ctx, msg.Topic, []byte(id), msg.Payload, map[string]string{
+			"outbox-id":    id,
+			"outbox-topic": msg.Topic,
+		})
+	})
+}
+
+// WebhookPublisherConfig holds configuration for a webhook outbox publisher
+type WebhookPublisherConfig struct {
+	// URL receives a POST per message with the JSON payload as body
+	URL string
+
+	// Secret signs the body; the X-Webhook-Signature header is
+	// "sha256=" + hex(HMAC-SHA256(Secret, body))
+	// Optional.
+	Secret string
+
+	// Headers are added to every request
+	// Optional.
+	Headers map[string]string
+
+	// Client sends the requests
+	// Default: http.Client with a 10 second timeout
+	Client *http.Client
+
+	// Writer configures the bounded worker pool sending the requests. When
+	// its queue is full the delivery fails with ErrWebhookQueueFull and the
+	// outbox retries it later.
+	// Default: 4 workers, a queue of 1000 and a BatchSize of 1
+	Writer BatchWriterConfig
+}
+
+// ErrWebhookQueueFull is returned by a webhook publisher whose worker pool
+// queue is full, or closed by OutboxDispatcher.Stop
+var ErrWebhookQueueFull = errors.New("webhook queue full")
+
+// webhookPublisher sends webhooks on a BatchWriter worker pool
+type webhookPublisher struct {
+	config WebhookPublisherConfig
+	writer *BatchWriter[*webhookDelivery]
+}
+
+// webhookDelivery is a queued webhook and the channel receiving its outcome
+type webhookDelivery struct {
+	ctx  context.Context
+	msg  *OutboxMessage
+	done chan error
+}
+
+// NewWebhookOutboxPublisher POSTs messages to an HTTP endpoint from a
+// bounded worker pool, which OutboxDispatcher.Stop closes. The message ID
+// and topic are sent in the X-Webhook-ID and X-Webhook-Topic headers; any
+// non-2xx response is retried.
+func NewWebhookOutboxPublisher(config WebhookPublisherConfig) OutboxPublisher {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Writer.Name == "" {
+		config.Writer.Name = "webhook publisher"
+	}
+	if config.Writer.Workers <= 0 {
+		config.Writer.Workers = 4
+	}
+	if config.Writer.QueueSize <= 0 {
+		config.Writer.QueueSize = 1000
+	}
+	if config.Writer.BatchSize <= 0 {
+		config.Writer.BatchSize = 1
+	}
+
+	p := &webhookPublisher{config: config}
+	p.writer = NewBatchWriter(p.sendBatch, config.Writer)
+	return p
+}
+
+// Publish queues msg and waits for its delivery
+func (p *webhookPublisher) Publish(ctx context.Context, msg *OutboxMessage) error {
+	d := &webhookDelivery{ctx: ctx, msg: msg, done: make(chan error, 1)}
+	if !p.writer.Add(d) {
+		return ErrWebhookQueueFull
+	}
+	select {
+	case err := <-d.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the worker pool once queued webhooks are sent
+func (p *webhookPublisher) Close(ctx context.Context) error {
+	return p.writer.Close(ctx)
+}
+
+// sendBatch sends each webhook, reporting the outcome to its publisher
+func (p *webhookPublisher) sendBatch(_ context.Context, batch []*webhookDelivery) error {
+	for _, d := range batch {
+		if err := d.ctx.Err(); err != nil {
+			d.done <- err
+			continue
+		}
+		d.done <- p.send(d.ctx, d.msg)
+	}
+	return nil
+}
+
+func (p *webhookPublisher) send(ctx context.Context, msg *OutboxMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Webhook-ID", strconv.FormatUint(msg.ID, 10))
+	req.Header.Set("X-Webhook-Topic", msg.Topic)
+	if p.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.config.Secret))
+		mac.Write(msg.Payload)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	for k, v := range p.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded %d", p.config.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type outboxOrder struct {
+	ID    uint `json:"id"`
+	Total int  `json:"total"`
+}
+
+func setupOutbox(t *testing.T, routes ...OutboxRoute) (*DB, *OutboxDispatcher) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping outbox tests: sqlite not available (%v)", err)
+	}
+	db.AutoMigrate(&outboxOrder{})
+	d, err := NewOutboxDispatcher(OutboxConfig{DB: db, Routes: routes, MaxAttempts: 3, RetryBackoff: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, d
+}
+
+func TestOutboxPublishEventInTransaction(t *testing.T) {
+	var published []string
+	db, d := setupOutbox(t, OutboxRoute{Pattern: "order.*", Publisher: OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		published = append(published, msg.Topic+" "+string(msg.Payload))
+		return nil
+	})})
+
+	r := New()
+	r.Use(GormInject(db))
+	r.POST("/orders", GormTransaction(), func(c *Context) {
+		order := outboxOrder{Total: 42}
+		MustGetGorm(c).Create(&order)
+		if err := c.PublishEvent("order.created", order); err != nil {
+			t.Fatal(err)
+		}
+		if c.Query("fail") != "" {
+			c.Error(errors.New("payment declined"))
+			return
+		}
+		c.JSON(201, order)
+	})
+
+	for _, path := range []string{"/orders", "/orders?fail=1"} {
+		req, _ := http.NewRequest("POST", path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var orders, messages int64
+	db.Model(&outboxOrder{}).Count(&orders)
+	db.Model(&OutboxMessage{}).Count(&messages)
+	if orders != 1 || messages != 1 {
+		t.Fatalf("Expected rolled back request to leave no order or event, got %d orders %d messages", orders, messages)
+	}
+
+	if n, err := d.Dispatch(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Expected 1 message dispatched, got %d %v", n, err)
+	}
+	if len(published) != 1 || published[0] != `order.created {"id":1,"total":42}` {
+		t.Errorf("Unexpected published %v", published)
+	}
+	if n, _ := d.Dispatch(context.Background()); n != 0 {
+		t.Errorf("Expected delivered message not to be dispatched again, got %d", n)
+	}
+	if stats := d.Stats(); stats.Delivered != 1 || stats.Pending != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestOutboxRetriesAndFailure(t *testing.T) {
+	calls := 0
+	db, d := setupOutbox(t, OutboxRoute{Pattern: "*", Publisher: OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		calls++
+		return errors.New("broker down")
+	})})
+	var failed []OutboxMessage
+	d.config.OnFailure = func(msg OutboxMessage, err error) { failed = append(failed, msg) }
+
+	PublishOutboxEvent(db, "sale.completed", H{"id": 7})
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if n, err := d.Dispatch(context.Background()); n != 1 || err != nil {
+			t.Fatalf("Attempt %d: expected message to be due, got %d %v", i+1, n, err)
+		}
+		// Not due again until the backoff has passed
+		if n, _ := d.Dispatch(context.Background()); n != 0 {
+			t.Fatalf("Attempt %d: expected backoff, got %d", i+1, n)
+		}
+		now = now.Add(backoffDelay(time.Minute, i+1, 5*time.Minute))
+	}
+
+	var msg OutboxMessage
+	db.First(&msg)
+	if calls != 3 || msg.Status != OutboxFailed || msg.Attempts != 3 || msg.LastError != "broker down" {
+		t.Errorf("Unexpected message after retries: calls=%d %+v", calls, msg)
+	}
+	if len(failed) != 1 || failed[0].ID != msg.ID {
+		t.Errorf("Expected OnFailure once, got %v", failed)
+	}
+	if stats := d.Stats(); stats.Retried != 2 || stats.Failed != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if n, err := d.Retry(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Expected 1 message requeued, got %d %v", n, err)
+	}
+	if n, _ := d.Dispatch(context.Background()); n != 1 || calls != 4 {
+		t.Errorf("Expected requeued message to be retried, got %d calls=%d", n, calls)
+	}
+}
+
+func TestOutboxClaimIsExclusive(t *testing.T) {
+	db, d := setupOutbox(t, OutboxRoute{Pattern: "*", Publisher: OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		return nil
+	})})
+	PublishOutboxEvent(db, "a", nil)
+
+	// Another dispatcher claims the message between our read and claim
+	d.config.Routes[0].Publisher = OutboxPublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+		t.Error("Expected message claimed elsewhere not to be published")
+		return nil
+	})
+	stolen := false
+	db.Callback().Query().After("gorm:query").Register("outbox_test:steal", func(tx *DB) {
+		if tx.Statement.Table == "outbox_messages" && !stolen {
+			stolen = true
+			tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Exec("UPDATE outbox_messages SET attempts = attempts + 1")
+		}
+	})
+	if n, err := d.Dispatch(context.Background()); !stolen || n != 0 || err != nil {
+		t.Errorf("Expected no message claimed, got %d %v", n, err)
+	}
+}
+
+func TestOutboxRedisPublisher(t *testing.T) {
+	client, mr := setupMiniRedis(t)
+	defer mr.Close()
+	sub := client.Client.Subscribe(context.Background(), "order.created")
+	defer sub.Close()
+	if _, err := sub.Receive(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewRedisOutboxPublisher(client).Publish(context.Background(), &OutboxMessage{ID: 1, Topic: "order.created", Payload: []byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-sub.Channel():
+		if msg.Payload != `{"id":1}` {
+			t.Errorf("Unexpected payload %q", msg.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected message on Redis channel")
+	}
+}
+
+func TestOutboxWebhookPublisher(t *testing.T) {
+	status := 500
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	publisher := NewWebhookOutboxPublisher(WebhookPublisherConfig{URL: srv.URL, Secret: "s3cret"})
+	msg := &OutboxMessage{ID: 9, Topic: "order.created", Payload: []byte(`{"id":9}`)}
+	if err := publisher.Publish(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected error for 500 response, got %v", err)
+	}
+
+	status = 204
+	if err := publisher.Publish(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if sig := got.Header.Get("X-Webhook-Signature"); sig != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("Unexpected signature %q", sig)
+	}
+	if got.Header.Get("X-Webhook-ID") != "9" || got.Header.Get("X-Webhook-Topic") != "order.created" || string(body) != `{"id":9}` {
+		t.Errorf("Unexpected request %v %s", got.Header, body)
+	}
+}
+
+func TestOutboxWebhookPublisherPool(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+	defer srv.Close()
+
+	publisher := NewWebhookOutboxPublisher(WebhookPublisherConfig{
+		URL:    srv.URL,
+		Writer: BatchWriterConfig{Workers: 1, QueueSize: 1},
+	})
+	_, d := setupOutbox(t, OutboxRoute{Pattern: "*", Publisher: publisher})
+
+	results := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		go func(id uint64) {
+			results <- publisher.Publish(context.Background(), &OutboxMessage{ID: id, Topic: "order.created"})
+		}(uint64(i))
+	}
+	// One webhook is being sent by the single worker, the other is queued
+	<-entered
+	writer := publisher.(*webhookPublisher).writer
+	waitFor(t, func() bool { return writer.Stats().Queued == 1 })
+
+	if err := publisher.Publish(context.Background(), &OutboxMessage{ID: 3}); !errors.Is(err, ErrWebhookQueueFull) {
+		t.Errorf("Expected ErrWebhookQueueFull, got %v", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("Expected queued webhooks to be sent, got %v", err)
+		}
+	}
+
+	// Stopping the dispatcher closes the pool
+	if err := d.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish(context.Background(), &OutboxMessage{ID: 4}); !errors.Is(err, ErrWebhookQueueFull) {
+		t.Errorf("Expected no delivery after Stop, got %v", err)
+	}
+}
+
+type recordingProducer struct {
+	topic, key string
+	headers    map[string]string
+}
+
+func (p *recordingProducer) Produce(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
+	p.topic, p.key, p.headers = topic, string(key), headers
+	return nil
+}
+
+func TestOutboxKafkaPublisher(t *testing.T) {
+	producer := &recordingProducer{}
+	NewKafkaOutboxPublisher(producer).Publish(context.Background(), &OutboxMessage{ID: 3, Topic: "sale.completed"})
+	if producer.topic != "sale.completed" || producer.key != "3" || producer.headers["outbox-id"] != "3" {
+		t.Errorf("Unexpected produce %+v", producer)
+	}
+}
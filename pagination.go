@@ -0,0 +1,166 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"math"
+	"strconv"
+)
+
+// Pagination is the framework-wide description of a page request.
+// It is populated by the Paginate middleware and honored by
+// NewGormPagination and NewMongoPagination.
+type Pagination struct {
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+	Pages   int64 `json:"pages"`
+}
+
+// Offset returns the number of records to skip
+func (p *Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Limit returns the page size
+func (p *Pagination) Limit() int {
+	return p.PerPage
+}
+
+// SetTotal sets the total count and calculates pages
+func (p *Pagination) SetTotal(total int64) {
+	p.Total = total
+	if p.PerPage > 0 {
+		p.Pages = (total + int64(p.PerPage) - 1) / int64(p.PerPage)
+	}
+}
+
+// Response returns pagination metadata for API response
+func (p *Pagination) Response() H {
+	return H{
+		"page":     p.Page,
+		"per_page": p.PerPage,
+		"total":    p.Total,
+		"pages":    p.Pages,
+	}
+}
+
+// PaginationConfig holds configuration for the Paginate middleware
+type PaginationConfig struct {
+	// DefaultPerPage is used when the client does not send a page size
+	// Default: 20
+	DefaultPerPage int
+
+	// MaxPerPage caps the page size a client may request
+	// Default: 100
+	MaxPerPage int
+
+	// PageParam is the query parameter holding the page number
+	// Default: "page"
+	PageParam string
+
+	// PerPageParam is the query parameter holding the page size
+	// Default: "per_page"
+	PerPageParam string
+
+	// PerPageAliases are additional query parameters accepted as page size.
+	// They are rewritten alongside PerPageParam so existing binders keep working.
+	// Default: []string{"page_size"}
+	PerPageAliases []string
+}
+
+// DefaultPaginationConfig returns the default pagination configuration
+func DefaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		DefaultPerPage: 20,
+		MaxPerPage:     100,
+		PageParam:      "page",
+		PerPageParam:   "per_page",
+		PerPageAliases: []string{"page_size"},
+	}
+}
+
+// Paginate returns a middleware that enforces pagination on list endpoints
+func Paginate() HandlerFunc {
+	return PaginateWithConfig(DefaultPaginationConfig())
+}
+
+// PaginateWithConfig returns a middleware that injects default page/per_page
+// values when they are absent and caps the page size at MaxPerPage. The page
+// is capped so Offset can't overflow.
+// Attach it to list routes (or a group of list routes) so no list endpoint
+// can be called unbounded:
+//
+//	products := r.Group("/products", goTap.Paginate())
+//	products.GET("", listProducts)
+func PaginateWithConfig(config PaginationConfig) HandlerFunc {
+	if config.DefaultPerPage <= 0 {
+		config.DefaultPerPage = 20
+	}
+	if config.MaxPerPage <= 0 {
+		config.MaxPerPage = 100
+	}
+	if config.DefaultPerPage > config.MaxPerPage {
+		config.DefaultPerPage = config.MaxPerPage
+	}
+	if config.PageParam == "" {
+		config.PageParam = "page"
+	}
+	if config.PerPageParam == "" {
+		config.PerPageParam = "per_page"
+	}
+	if config.PerPageAliases == nil {
+		config.PerPageAliases = []string{"page_size"}
+	}
+
+	perPageParams := append([]string{config.PerPageParam}, config.PerPageAliases...)
+
+	return func(c *Context) {
+		query := c.Request.URL.Query()
+
+		page, err := strconv.Atoi(query.Get(config.PageParam))
+		if err != nil || page < 1 {
+			page = 1
+		}
+
+		perPage := 0
+		for _, param := range perPageParams {
+			if v, err := strconv.Atoi(query.Get(param)); err == nil && v > 0 {
+				perPage = v
+				break
+			}
+		}
+		if perPage == 0 {
+			perPage = config.DefaultPerPage
+		}
+		if perPage > config.MaxPerPage {
+			perPage = config.MaxPerPage
+		}
+		// Keep Offset from overflowing for huge page numbers
+		if page > math.MaxInt/perPage {
+			page = math.MaxInt / perPage
+		}
+
+		// Rewrite the query so binders and helpers downstream see the enforced values
+		query.Set(config.PageParam, strconv.Itoa(page))
+		for _, param := range perPageParams {
+			query.Set(param, strconv.Itoa(perPage))
+		}
+		c.Request.URL.RawQuery = query.Encode()
+		c.queryCache = nil
+
+		c.Set("pagination", &Pagination{
+			Page:    page,
+			PerPage: perPage,
+		})
+
+		c.Next()
+	}
+}
+
+// GetPagination retrieves the enforced pagination from context
+func GetPagination(c *Context) (*Pagination, bool) {
+	return Get[*Pagination](c, "pagination")
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPaginateDefaults(t *testing.T) {
+	r := New()
+	r.GET("/products", Paginate(), func(c *Context) {
+		p, ok := GetPagination(c)
+		if !ok {
+			t.Fatal("pagination not found in context")
+		}
+		if p.Page != 1 || p.PerPage != 20 {
+			t.Errorf("Expected page=1 per_page=20, got page=%d per_page=%d", p.Page, p.PerPage)
+		}
+		if c.Query("per_page") != "20" || c.Query("page_size") != "20" {
+			t.Errorf("Expected query to be rewritten, got %q", c.Request.URL.RawQuery)
+		}
+		c.Status(200)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/products", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestPaginateCapsMaximum(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantPage    int
+		wantPerPage int
+	}{
+		{"?page=3&per_page=10", 3, 10},
+		{"?per_page=5000", 1, 50},
+		{"?page_size=30", 1, 30},
+		{"?page=-2&per_page=abc", 1, 20},
+	}
+
+	for _, tt := range tests {
+		r := New()
+		r.GET("/orders", PaginateWithConfig(PaginationConfig{MaxPerPage: 50}), func(c *Context) {
+			p, _ := GetPagination(c)
+			if p.Page != tt.wantPage || p.PerPage != tt.wantPerPage {
+				t.Errorf("%s: expected page=%d per_page=%d, got page=%d per_page=%d",
+					tt.query, tt.wantPage, tt.wantPerPage, p.Page, p.PerPage)
+			}
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/orders"+tt.query, nil)
+		r.ServeHTTP(w, req)
+	}
+}
+
+func TestPaginateCapsPage(t *testing.T) {
+	r := New()
+	called := false
+	r.GET("/orders", Paginate(), func(c *Context) {
+		called = true
+		p, _ := GetPagination(c)
+		if p.Page != math.MaxInt/10 || p.Offset() < 0 {
+			t.Errorf("Expected the page to be capped, got page=%d offset=%d", p.Page, p.Offset())
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders?per_page=10&page="+strconv.Itoa(math.MaxInt), nil)
+	r.ServeHTTP(w, req)
+	if !called {
+		t.Fatal("Expected the handler to run")
+	}
+}
+
+func TestPaginateHonoredByGormAndMongoPagination(t *testing.T) {
+	r := New()
+	r.GET("/items", PaginateWithConfig(PaginationConfig{MaxPerPage: 25}), func(c *Context) {
+		gp := NewGormPagination(c)
+		if gp.PageSize != 25 || gp.Page != 2 {
+			t.Errorf("GormPagination: expected page=2 size=25, got page=%d size=%d", gp.Page, gp.PageSize)
+		}
+		mp := NewMongoPagination(c)
+		if mp.PageSize != 25 || mp.Page != 2 {
+			t.Errorf("MongoPagination: expected page=2 size=25, got page=%d size=%d", mp.Page, mp.PageSize)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/items?page=2&page_size=500", nil)
+	r.ServeHTTP(w, req)
+}
+
+func TestPaginationSetTotal(t *testing.T) {
+	p := &Pagination{Page: 2, PerPage: 10}
+	p.SetTotal(95)
+
+	if p.Pages != 10 {
+		t.Errorf("Expected 10 pages, got %d", p.Pages)
+	}
+	if p.Offset() != 10 {
+		t.Errorf("Expected offset 10, got %d", p.Offset())
+	}
+	if p.Response()["total"] != int64(95) {
+		t.Errorf("Expected total 95 in response, got %v", p.Response()["total"])
+	}
+}
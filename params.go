@@ -0,0 +1,187 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrParamMissing is wrapped by the ParamError of a parameter absent from
+// the request
+var ErrParamMissing = errors.New("missing parameter")
+
+// ParamError reports a URL parameter that is missing or doesn't parse
+type ParamError struct {
+	// Source is "path" or "query"
+	Source string
+	Name   string
+	Value  string
+	// Expected describes the type, e.g. "an integer"
+	Expected string
+	// Err is ErrParamMissing or the parsing error
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrParamMissing) {
+		return fmt.Sprintf("%s parameter %s is required", e.Source, e.Name)
+	}
+	return fmt.Sprintf("%s parameter %s must be %s, got %q", e.Source, e.Name, e.Expected, e.Value)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// parseParam parses a parameter value with parse
+func parseParam[T any](source, name, value string, ok bool, expected string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	if !ok || value == "" {
+		return zero, &ParamError{Source: source, Name: name, Expected: expected, Err: ErrParamMissing}
+	}
+	v, err := parse(value)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+		return zero, &ParamError{Source: source, Name: name, Value: value, Expected: expected, Err: err}
+	}
+	return v, nil
+}
+
+// mustParam responds 400 Bad Request and aborts when err is set
+func mustParam[T any](c *Context, v T, err error) (T, bool) {
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return v, false
+	}
+	return v, true
+}
+
+func parseInt(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// ParamInt returns the URL param key as an int, or a *ParamError when it
+// is missing or not an integer:
+//
+//	router.GET("/products/:id", func(c *goTap.Context) {
+//	    id, err := c.ParamInt("id")
+//	    if err != nil {
+//	        c.JSON(400, goTap.H{"error": err.Error()})
+//	        return
+//	    }
+//	})
+func (c *Context) ParamInt(key string) (int, error) {
+	value, ok := c.Params.Get(key)
+	return parseParam("path", key, value, ok, "an integer", strconv.Atoi)
+}
+
+// ParamInt64 returns the URL param key as an int64, see ParamInt
+func (c *Context) ParamInt64(key string) (int64, error) {
+	value, ok := c.Params.Get(key)
+	return parseParam("path", key, value, ok, "an integer", parseInt)
+}
+
+// QueryInt returns the query value key as an int, or a *ParamError when it
+// is missing or not an integer
+func (c *Context) QueryInt(key string) (int, error) {
+	value, ok := c.GetQuery(key)
+	return parseParam("query", key, value, ok, "an integer", strconv.Atoi)
+}
+
+// QueryInt64 returns the query value key as an int64, see QueryInt
+func (c *Context) QueryInt64(key string) (int64, error) {
+	value, ok := c.GetQuery(key)
+	return parseParam("query", key, value, ok, "an integer", parseInt)
+}
+
+// QueryFloat64 returns the query value key as a float64, see QueryInt
+func (c *Context) QueryFloat64(key string) (float64, error) {
+	value, ok := c.GetQuery(key)
+	return parseParam("query", key, value, ok, "a number", parseFloat)
+}
+
+// QueryBool returns the query value key as a bool, accepting the values of
+// strconv.ParseBool such as "true", "1" and "false", see QueryInt
+func (c *Context) QueryBool(key string) (bool, error) {
+	value, ok := c.GetQuery(key)
+	return parseParam("query", key, value, ok, "a boolean", strconv.ParseBool)
+}
+
+// QueryTime returns the query value key parsed with layout, time.RFC3339
+// if empty, see QueryInt:
+//
+//	from, err := c.QueryTime("from", time.DateOnly)
+func (c *Context) QueryTime(key, layout string) (time.Time, error) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	value, ok := c.GetQuery(key)
+	return parseParam("query", key, value, ok, "a time formatted as "+layout, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	})
+}
+
+// MustParamInt is like ParamInt but responds 400 Bad Request and aborts on
+// error, reporting whether the handler can go on:
+//
+//	id, ok := c.MustParamInt("id")
+//	if !ok {
+//	    return
+//	}
+func (c *Context) MustParamInt(key string) (int, bool) {
+	v, err := c.ParamInt(key)
+	return mustParam(c, v, err)
+}
+
+// MustParamInt64 is like ParamInt64, see MustParamInt
+func (c *Context) MustParamInt64(key string) (int64, bool) {
+	v, err := c.ParamInt64(key)
+	return mustParam(c, v, err)
+}
+
+// MustQueryInt is like QueryInt, see MustParamInt
+func (c *Context) MustQueryInt(key string) (int, bool) {
+	v, err := c.QueryInt(key)
+	return mustParam(c, v, err)
+}
+
+// MustQueryInt64 is like QueryInt64, see MustParamInt
+func (c *Context) MustQueryInt64(key string) (int64, bool) {
+	v, err := c.QueryInt64(key)
+	return mustParam(c, v, err)
+}
+
+// MustQueryFloat64 is like QueryFloat64, see MustParamInt
+func (c *Context) MustQueryFloat64(key string) (float64, bool) {
+	v, err := c.QueryFloat64(key)
+	return mustParam(c, v, err)
+}
+
+// MustQueryBool is like QueryBool, see MustParamInt
+func (c *Context) MustQueryBool(key string) (bool, bool) {
+	v, err := c.QueryBool(key)
+	return mustParam(c, v, err)
+}
+
+// MustQueryTime is like QueryTime, see MustParamInt
+func (c *Context) MustQueryTime(key, layout string) (time.Time, bool) {
+	v, err := c.QueryTime(key, layout)
+	return mustParam(c, v, err)
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedParams(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/products/42?page=3&big=9007199254740993&price=9.95&active=1&from=2025-03-01&bad=x", nil)
+	c.Params = Params{{Key: "id", Value: "42"}, {Key: "sku", Value: "AB-1"}}
+
+	if id, err := c.ParamInt("id"); err != nil || id != 42 {
+		t.Errorf("ParamInt = %d, %v", id, err)
+	}
+	if id, err := c.ParamInt64("id"); err != nil || id != 42 {
+		t.Errorf("ParamInt64 = %d, %v", id, err)
+	}
+	if page, err := c.QueryInt("page"); err != nil || page != 3 {
+		t.Errorf("QueryInt = %d, %v", page, err)
+	}
+	if big, err := c.QueryInt64("big"); err != nil || big != 9007199254740993 {
+		t.Errorf("QueryInt64 = %d, %v", big, err)
+	}
+	if price, err := c.QueryFloat64("price"); err != nil || price != 9.95 {
+		t.Errorf("QueryFloat64 = %v, %v", price, err)
+	}
+	if active, err := c.QueryBool("active"); err != nil || !active {
+		t.Errorf("QueryBool = %v, %v", active, err)
+	}
+	if from, err := c.QueryTime("from", time.DateOnly); err != nil || !from.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("QueryTime = %v, %v", from, err)
+	}
+
+	_, err := c.ParamInt("sku")
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) || paramErr.Source != "path" || paramErr.Value != "AB-1" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("ParamInt(sku) error = %#v", err)
+	}
+	if err.Error() != `path parameter sku must be an integer, got "AB-1"` {
+		t.Errorf("message = %q", err)
+	}
+	if _, err := c.QueryInt("missing"); !errors.Is(err, ErrParamMissing) || err.Error() != "query parameter missing is required" {
+		t.Errorf("QueryInt(missing) error = %v", err)
+	}
+	if v, err := c.QueryBool("bad"); err == nil || v {
+		t.Errorf("QueryBool(bad) = %v, %v", v, err)
+	}
+	if _, err := c.QueryTime("from", ""); err == nil {
+		t.Error("QueryTime with the RFC 3339 default accepted a date")
+	}
+}
+
+func TestMustParams(t *testing.T) {
+	r := New()
+	r.GET("/orders/:id", func(c *Context) {
+		id, ok := c.MustParamInt("id")
+		if !ok {
+			return
+		}
+		limit, ok := c.MustQueryInt("limit")
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, H{"id": id, "limit": limit})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	if w := get("/orders/7?limit=10"); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"id":7,"limit":10}` {
+		t.Errorf("valid = %d %s", w.Code, w.Body)
+	}
+	if w := get("/orders/abc?limit=10"); w.Code != http.StatusBadRequest || strings.TrimSpace(w.Body.String()) != `{"error":"Bad Request","message":"path parameter id must be an integer, got \"abc\""}` {
+		t.Errorf("bad id = %d %s", w.Code, w.Body)
+	}
+	if w := get("/orders/7"); w.Code != http.StatusBadRequest {
+		t.Errorf("missing limit = %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// Password policy errors
+var (
+	ErrWeakPassword     = RegisterErrorCode("weak_password", http.StatusUnprocessableEntity, "Password is too weak")
+	ErrBreachedPassword = RegisterErrorCode("breached_password", http.StatusUnprocessableEntity, "Password appears in a data breach")
+)
+
+// PasswordConfig holds configuration for password hashing
+type PasswordConfig struct {
+	// Algorithm is PasswordArgon2id or PasswordBcrypt
+	// Default: PasswordArgon2id
+	Algorithm string
+
+	// Memory used by argon2id in KiB, at most MaxArgon2Memory
+	// Default: 19456 (19 MiB)
+	Memory uint32
+
+	// Iterations of argon2id, at most MaxArgon2Iterations
+	// Default: 2
+	Iterations uint32
+
+	// Parallelism of argon2id
+	// Default: 1
+	Parallelism uint8
+
+	// BcryptCost is the bcrypt cost factor
+	// Default: bcrypt.DefaultCost (10)
+	BcryptCost int
+}
+
+// Upper bounds of argon2id parameters. VerifyPassword rejects hashes over
+// them, so a tampered hash can't exhaust memory or CPU on every login.
+const (
+	MaxArgon2Memory     = 1 << 20 // 1 GiB in KiB
+	MaxArgon2Iterations = 100
+)
+
+// validArgon2Params reports whether argon2id parameters are in bounds
+func validArgon2Params(memory, iterations uint32, parallelism uint8) error {
+	if parallelism < 1 || iterations < 1 || iterations > MaxArgon2Iterations ||
+		memory < 8*uint32(parallelism) || memory > MaxArgon2Memory {
+		return fmt.Errorf("argon2id parameters out of range: m=%d,t=%d,p=%d", memory, iterations, parallelism)
+	}
+	return nil
+}
+
+// DefaultPasswordConfig returns the recommended argon2id parameters
+func DefaultPasswordConfig() PasswordConfig {
+	return PasswordConfig{
+		Algorithm:   PasswordArgon2id,
+		Memory:      19456,
+		Iterations:  2,
+		Parallelism: 1,
+		BcryptCost:  bcrypt.DefaultCost,
+	}
+}
+
+func (config *PasswordConfig) defaults() {
+	d := DefaultPasswordConfig()
+	if config.Algorithm == "" {
+		config.Algorithm = d.Algorithm
+	}
+	if config.Memory == 0 {
+		config.Memory = d.Memory
+	}
+	if config.Iterations == 0 {
+		config.Iterations = d.Iterations
+	}
+	if config.Parallelism == 0 {
+		config.Parallelism = d.Parallelism
+	}
+	if config.BcryptCost == 0 {
+		config.BcryptCost = d.BcryptCost
+	}
+}
+
+// HashPassword hashes a password with argon2id, encoded as
+// "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>":
+//
+//	hash, err := goTap.HashPassword(req.Password)
+//	// ...
+//	if ok, _ := goTap.VerifyPassword(user.PasswordHash, req.Password); !ok {
+//	    c.JSON(401, goTap.H{"error": "Invalid credentials"})
+//	}
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithConfig(password, PasswordConfig{})
+}
+
+// HashPasswordWithConfig hashes a password with config
+func HashPasswordWithConfig(password string, config PasswordConfig) (string, error) {
+	config.defaults()
+	switch config.Algorithm {
+	case PasswordArgon2id:
+		if err := validArgon2Params(config.Memory, config.Iterations, config.Parallelism); err != nil {
+			return "", err
+		}
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, config.Iterations, config.Memory, config.Parallelism, 32)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			config.Memory, config.Iterations, config.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	case PasswordBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), config.BcryptCost)
+		return string(hash), err
+	default:
+		return "", fmt.Errorf("unsupported password algorithm: %s", config.Algorithm)
+	}
+}
+
+// VerifyPassword checks a password against an argon2id or bcrypt hash. An
+// error means the hash is malformed; a wrong password returns false.
+func VerifyPassword(hash, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return err == nil, err
+	default:
+		return false, fmt.Errorf("unknown password hash format")
+	}
+}
+
+// PasswordNeedsRehash reports whether hash was made with another
+// algorithm or weaker parameters than config, e.g. a legacy bcrypt hash.
+// Rehash the password after a successful login:
+//
+//	if ok && goTap.PasswordNeedsRehash(user.PasswordHash, goTap.PasswordConfig{}) {
+//	    user.PasswordHash, _ = goTap.HashPassword(req.Password)
+//	}
+func PasswordNeedsRehash(hash string, config PasswordConfig) bool {
+	config.defaults()
+	if config.Algorithm == PasswordBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < config.BcryptCost
+	}
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params.Memory < config.Memory || params.Iterations < config.Iterations ||
+		params.Parallelism < config.Parallelism
+}
+
+func decodeArgon2id(hash string) (params PasswordConfig, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordArgon2id {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters")
+	}
+	if err := validArgon2Params(params.Memory, params.Iterations, params.Parallelism); err != nil {
+		return params, nil, nil, err
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt")
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) < 16 || len(key) > 64 {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	return params, salt, key, nil
+}
+
+// commonPasswords are rejected regardless of their composition
+var commonPasswords = map[string]bool{
+	"password": true, "password1": true, "password123": true, "passw0rd": true,
+	"123456": true, "12345678": true, "123456789": true, "1234567890": true,
+	"qwerty": true, "qwerty123": true, "qwertyuiop": true, "abc123": true,
+	"letmein": true, "welcome": true, "welcome1": true, "admin": true,
+	"admin123": true, "iloveyou": true, "monkey": true, "dragon": true,
+	"111111": true, "000000": true, "sunshine": true, "football": true,
+	"changeme": true, "secret": true, "trustno1": true,
+}
+
+// PasswordStrength scores a password from 0 (very weak) to 4 (strong) by
+// length, character classes and repetition. Common passwords score 0.
+func PasswordStrength(password string) int {
+	length := len([]rune(password))
+	if length < 8 || commonPasswords[strings.ToLower(password)] {
+		return 0
+	}
+
+	var lower, upper, digit, symbol bool
+	unique := make(map[rune]bool)
+	for _, r := range password {
+		unique[r] = true
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+
+	score := 1
+	if length >= 12 {
+		score++
+	}
+	if classes >= 3 {
+		score++
+	}
+	if classes == 4 || length >= 16 {
+		score++
+	}
+	// Few distinct characters, e.g. "aaaaaaaaaaaa1!"
+	if len(unique) < length/2 {
+		score--
+	}
+	return max(0, min(score, 4))
+}
+
+// validatePasswordStrength implements the "password_strength=N" rule
+func validatePasswordStrength(fieldName string, value reflect.Value, param string) error {
+	if value.Kind() != reflect.String || value.String() == "" {
+		return nil
+	}
+	minScore, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("invalid password_strength parameter: %s", param)
+	}
+	if PasswordStrength(value.String()) < minScore {
+		return fmt.Errorf("field '%s' is too weak a password", fieldName)
+	}
+	return nil
+}
+
+// BreachChecker reports whether a password appears in a breach list
+type BreachChecker func(ctx context.Context, password string) (bool, error)
+
+// pwnedPasswordsURL is the Have I Been Pwned range API
+var pwnedPasswordsURL = "https://api.pwnedpasswords.com/range/"
+
+// PwnedPasswords returns a BreachChecker querying the Have I Been Pwned
+// Passwords API. Only the first 5 characters of the password's SHA-1 are
+// sent (k-anonymity); the match happens locally. A nil client uses
+// http.DefaultClient.
+func PwnedPasswords(client *http.Client) BreachChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, password string) (bool, error) {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		prefix, suffix := hash[:5], hash[5:]
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pwnedPasswordsURL+prefix, nil)
+		if err != nil {
+			return false, err
+		}
+		// Padding hides the number of matches from observers
+		req.Header.Set("Add-Padding", "true")
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, fmt.Errorf("pwned passwords: unexpected status %d", resp.StatusCode)
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			candidate, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+			if candidate == suffix && count != "0" {
+				return true, nil
+			}
+		}
+		return false, scanner.Err()
+	}
+}
+
+// PasswordPolicy checks new passwords, e.g. at sign-up or password change
+type PasswordPolicy struct {
+	// MinStrength is the minimum PasswordStrength score
+	// Default: 3
+	MinStrength int
+
+	// BreachCheck rejects breached passwords, e.g. PwnedPasswords(nil)
+	// Optional. Default: no breach check
+	BreachCheck BreachChecker
+}
+
+// Check returns ErrWeakPassword or ErrBreachedPassword if password doesn't
+// satisfy the policy; c.Problem sends them as 422. A failing breach check
+// is ignored, so an outage of the breach list doesn't block sign-ups.
+func (p PasswordPolicy) Check(ctx context.Context, password string) error {
+	minStrength := p.MinStrength
+	if minStrength == 0 {
+		minStrength = 3
+	}
+	if PasswordStrength(password) < minStrength {
+		return ErrWeakPassword.WithDetail("Use at least 12 characters mixing letters, digits and symbols", nil)
+	}
+	if p.BreachCheck != nil {
+		breached, err := p.BreachCheck(ctx, password)
+		if err != nil {
+			debugPrint("[WARNING] Password breach check failed: %v", err)
+		} else if breached {
+			return ErrBreachedPassword
+		}
+	}
+	return nil
+}
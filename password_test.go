@@ -0,0 +1,183 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Errorf("Unexpected hash %s", hash)
+	}
+	if other, _ := HashPassword("correct horse battery staple"); other == hash {
+		t.Error("Expected a random salt")
+	}
+	if ok, err := VerifyPassword(hash, "correct horse battery staple"); !ok || err != nil {
+		t.Errorf("Expected the password to match, got %v %v", ok, err)
+	}
+	if ok, err := VerifyPassword(hash, "wrong"); ok || err != nil {
+		t.Errorf("Expected a wrong password to fail, got %v %v", ok, err)
+	}
+	if _, err := VerifyPassword("$argon2id$v=19$m=x$salt$hash", "x"); err == nil {
+		t.Error("Expected a malformed hash to fail")
+	}
+	if _, err := VerifyPassword("plaintext", "x"); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
+
+func TestVerifyPasswordRejectsUnsafeParameters(t *testing.T) {
+	hash, _ := HashPassword("secret")
+	parts := strings.Split(hash, "$")
+	for _, params := range []string{
+		"m=19456,t=2,p=0",      // argon2 panics on no parallelism
+		"m=19456,t=0,p=1",      // no iterations
+		"m=4194304,t=2,p=1",    // 4 GiB per verification
+		"m=19456,t=100000,p=1", // CPU exhaustion
+		"m=19456,t=2,p=256",    // overflows uint8
+		"m=-1,t=2,p=1",         // negative memory
+	} {
+		parts[3] = params
+		tampered := strings.Join(parts, "$")
+		ok, err := VerifyPassword(tampered, "secret")
+		if ok || err == nil {
+			t.Errorf("Expected %s to be rejected, got %v %v", params, ok, err)
+		}
+		if !PasswordNeedsRehash(tampered, PasswordConfig{}) {
+			t.Errorf("Expected %s to need a rehash", params)
+		}
+	}
+
+	if _, err := HashPasswordWithConfig("secret", PasswordConfig{Memory: MaxArgon2Memory + 1}); err == nil {
+		t.Error("Expected hashing with too much memory to fail")
+	}
+}
+
+func TestHashPasswordBcrypt(t *testing.T) {
+	legacy, err := HashPasswordWithConfig("secret-pin-1234", PasswordConfig{Algorithm: PasswordBcrypt, BcryptCost: 4})
+	if err != nil || !strings.HasPrefix(legacy, "$2a$04$") {
+		t.Fatalf("Unexpected bcrypt hash %s %v", legacy, err)
+	}
+	if ok, err := VerifyPassword(legacy, "secret-pin-1234"); !ok || err != nil {
+		t.Errorf("Expected bcrypt hashes to verify, got %v %v", ok, err)
+	}
+	if ok, _ := VerifyPassword(legacy, "secret-pin-0000"); ok {
+		t.Error("Expected a wrong password to fail")
+	}
+
+	if !PasswordNeedsRehash(legacy, PasswordConfig{}) {
+		t.Error("Expected bcrypt hashes to need an argon2id rehash")
+	}
+	if !PasswordNeedsRehash(legacy, PasswordConfig{Algorithm: PasswordBcrypt}) {
+		t.Error("Expected a low bcrypt cost to need a rehash")
+	}
+	hash, _ := HashPassword("secret-pin-1234")
+	if PasswordNeedsRehash(hash, PasswordConfig{}) {
+		t.Error("Expected a current hash not to need a rehash")
+	}
+	if !PasswordNeedsRehash(hash, PasswordConfig{Iterations: 3}) {
+		t.Error("Expected stronger parameters to need a rehash")
+	}
+}
+
+func TestPasswordStrength(t *testing.T) {
+	tests := map[string]int{
+		"short1!":                   0,
+		"password123":               0,
+		"abcdefgh":                  1,
+		"abcdefgh1":                 1,
+		"Abcdefgh1":                 2,
+		"Abcdefgh1!":                3,
+		"Tr0ub4dor&3xyz":            4,
+		"aaaaaaaaaaaaaaaaa1!A":      3,
+		"correct horse battery st8": 4,
+	}
+	for password, want := range tests {
+		if got := PasswordStrength(password); got != want {
+			t.Errorf("PasswordStrength(%q) = %d, want %d", password, got, want)
+		}
+	}
+
+	type signup struct {
+		Password string `validate:"required,password_strength=3"`
+	}
+	if err := (&DefaultValidator{}).ValidateStruct(&signup{Password: "abcdefgh1"}); err == nil {
+		t.Error("Expected a weak password to fail validation")
+	}
+	if err := (&DefaultValidator{}).ValidateStruct(&signup{Password: "Abcdefgh1!"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	var prefix string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix = strings.TrimPrefix(r.URL.Path, "/range/")
+		if r.Header.Get("Add-Padding") != "true" {
+			t.Error("Expected padding to be requested")
+		}
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n")
+		fmt.Fprint(w, "00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n")
+	}))
+	defer server.Close()
+	original := pwnedPasswordsURL
+	pwnedPasswordsURL = server.URL + "/range/"
+	defer func() { pwnedPasswordsURL = original }()
+
+	policy := PasswordPolicy{BreachCheck: PwnedPasswords(server.Client())}
+	ctx := context.Background()
+	if err := policy.Check(ctx, "abcdefgh1"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Expected ErrWeakPassword, got %v", err)
+	}
+	if err := policy.Check(ctx, "Tr0ub4dor&3xyz"); err != nil {
+		t.Errorf("Expected the password to pass, got %v", err)
+	}
+	if len(prefix) != 5 {
+		t.Errorf("Expected only a 5-character prefix to be sent, got %q", prefix)
+	}
+
+	breached := PasswordPolicy{BreachCheck: func(ctx context.Context, password string) (bool, error) {
+		return password == "Tr0ub4dor&3xyz", nil
+	}}
+	if err := breached.Check(ctx, "Tr0ub4dor&3xyz"); !errors.Is(err, ErrBreachedPassword) {
+		t.Errorf("Expected ErrBreachedPassword, got %v", err)
+	}
+	failing := PasswordPolicy{BreachCheck: func(ctx context.Context, password string) (bool, error) {
+		return false, errors.New("unavailable")
+	}}
+	if err := failing.Check(ctx, "Tr0ub4dor&3xyz"); err != nil {
+		t.Errorf("Expected a failing breach check to be ignored, got %v", err)
+	}
+}
+
+func TestPwnedPasswordsMatch(t *testing.T) {
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n")
+	}))
+	defer server.Close()
+	original := pwnedPasswordsURL
+	pwnedPasswordsURL = server.URL + "/range/"
+	defer func() { pwnedPasswordsURL = original }()
+
+	breached, err := PwnedPasswords(nil)(context.Background(), "password")
+	if err != nil || !breached {
+		t.Errorf("Expected the password to be breached, got %v %v", breached, err)
+	}
+}
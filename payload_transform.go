@@ -0,0 +1,264 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PayloadVersionKey is the context key of the API version a client speaks
+const PayloadVersionKey = "gotap.payload_version"
+
+// PayloadFunc rewrites a decoded JSON payload, made of map[string]any,
+// []any and json.Number values, and returns the result
+type PayloadFunc func(c *Context, payload any) (any, error)
+
+// PayloadMigration converts payloads between an API version and the next
+type PayloadMigration struct {
+	// Version is the last API version with the old payload shape. Clients
+	// on this version or an earlier one get the migration.
+	Version string
+
+	// Request upgrades request bodies to the newer shape
+	// Optional. Default: bodies are kept
+	Request PayloadFunc
+
+	// Response downgrades response bodies to the older shape
+	// Optional. Default: bodies are kept
+	Response PayloadFunc
+}
+
+// PayloadTransformConfig holds configuration for the TransformPayloads
+// middleware
+type PayloadTransformConfig struct {
+	// Migrations are listed from the oldest version to the newest
+	Migrations []PayloadMigration
+
+	// VersionFunc returns the API version of the client. Versions that
+	// aren't listed get no migrations.
+	// Default: the "version" route parameter, else the X-API-Version header
+	VersionFunc func(c *Context) string
+}
+
+// TransformPayloads returns a middleware that lets handlers speak the
+// current API while deployed clients keep their version. JSON request
+// bodies of older clients are upgraded migration by migration before the
+// handler runs, and JSON responses are downgraded in reverse order:
+//
+//	r.Use(goTap.TransformPayloads(
+//	    goTap.PayloadMigration{
+//	        Version:  "1", // v1 terminals send customer_id
+//	        Request:  goTap.RenameFields(map[string]string{"customer_id": "customerId"}),
+//	        Response: goTap.RenameFields(map[string]string{"customerId": "customer_id"}),
+//	    },
+//	    goTap.PayloadMigration{
+//	        Version: "2", // v1 and v2 terminals don't send a currency
+//	        Request: goTap.DefaultFields(goTap.H{"currency": "USD"}),
+//	    },
+//	))
+//
+// Handlers read the client's version with GetPayloadVersion.
+func TransformPayloads(migrations ...PayloadMigration) HandlerFunc {
+	return TransformPayloadsWithConfig(PayloadTransformConfig{Migrations: migrations})
+}
+
+// TransformPayloadsWithConfig returns a TransformPayloads middleware with
+// config
+func TransformPayloadsWithConfig(config PayloadTransformConfig) HandlerFunc {
+	if config.VersionFunc == nil {
+		config.VersionFunc = func(c *Context) string {
+			if v := c.Param("version"); v != "" {
+				return v
+			}
+			return c.GetHeader("X-API-Version")
+		}
+	}
+
+	return func(c *Context) {
+		version := config.VersionFunc(c)
+		c.Set(PayloadVersionKey, version)
+
+		var migrations []PayloadMigration
+		for i, m := range config.Migrations {
+			if m.Version == version {
+				migrations = config.Migrations[i:]
+				break
+			}
+		}
+		if len(migrations) == 0 {
+			c.Next()
+			return
+		}
+
+		if err := upgradeRequest(c, migrations); err != nil {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "Bad Request",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		// Hold the response to rewrite it, as DeltaSync does
+		original := c.Writer
+		w := &deltaWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if w.body.Len() == 0 || !strings.Contains(original.Header().Get("Content-Type"), "json") {
+			w.flush()
+			return
+		}
+		body, err := transformPayload(c, w.body.Bytes(), migrations, true)
+		if err != nil {
+			debugPrint("[WARNING] Response transform for version %s failed: %v", version, err)
+			original.Header().Del("Content-Length")
+			c.JSON(http.StatusInternalServerError, H{
+				"error":   "Internal Server Error",
+				"message": "Response could not be converted to API version " + version,
+			})
+			return
+		}
+		original.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		original.WriteHeader(w.status)
+		original.Write(body)
+	}
+}
+
+// GetPayloadVersion returns the API version of the client, as determined
+// by TransformPayloads
+func GetPayloadVersion(c *Context) string {
+	version, _ := c.Get(PayloadVersionKey)
+	s, _ := version.(string)
+	return s
+}
+
+// upgradeRequest replaces a JSON request body with its migrated version
+func upgradeRequest(c *Context, migrations []PayloadMigration) error {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return nil
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if data, err = transformPayload(c, data, migrations, false); err != nil {
+			return err
+		}
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	c.Request.ContentLength = int64(len(data))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	return nil
+}
+
+// transformPayload applies the request migrations in order, or the
+// response migrations in reverse order
+func transformPayload(c *Context, data []byte, migrations []PayloadMigration, response bool) ([]byte, error) {
+	funcs := make([]PayloadFunc, 0, len(migrations))
+	for i := range migrations {
+		if response {
+			if f := migrations[len(migrations)-1-i].Response; f != nil {
+				funcs = append(funcs, f)
+			}
+		} else if f := migrations[i].Request; f != nil {
+			funcs = append(funcs, f)
+		}
+	}
+	if len(funcs) == 0 {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		return nil, err
+	}
+	for _, f := range funcs {
+		var err error
+		if payload, err = f(c, payload); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// RenameFields returns a PayloadFunc renaming the keys of objects at any
+// depth, e.g. {"customer_id": "customerId"}
+func RenameFields(renames map[string]string) PayloadFunc {
+	var rename func(v any)
+	rename = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for from, to := range renames {
+				if value, ok := v[from]; ok {
+					delete(v, from)
+					v[to] = value
+				}
+			}
+			for _, value := range v {
+				rename(value)
+			}
+		case []any:
+			for _, value := range v {
+				rename(value)
+			}
+		}
+	}
+	return func(c *Context, payload any) (any, error) {
+		rename(payload)
+		return payload, nil
+	}
+}
+
+// DefaultFields returns a PayloadFunc setting missing keys of a top-level
+// object, or of each object in a top-level array
+func DefaultFields(defaults H) PayloadFunc {
+	return func(c *Context, payload any) (any, error) {
+		forEachObject(payload, func(obj map[string]any) {
+			for k, v := range defaults {
+				if _, ok := obj[k]; !ok {
+					obj[k] = v
+				}
+			}
+		})
+		return payload, nil
+	}
+}
+
+// RemoveFields returns a PayloadFunc deleting keys of a top-level object,
+// or of each object in a top-level array
+func RemoveFields(names ...string) PayloadFunc {
+	return func(c *Context, payload any) (any, error) {
+		forEachObject(payload, func(obj map[string]any) {
+			for _, name := range names {
+				delete(obj, name)
+			}
+		})
+		return payload, nil
+	}
+}
+
+func forEachObject(payload any, fn func(map[string]any)) {
+	switch v := payload.(type) {
+	case map[string]any:
+		fn(v)
+	case []any:
+		for _, item := range v {
+			if obj, ok := item.(map[string]any); ok {
+				fn(obj)
+			}
+		}
+	}
+}
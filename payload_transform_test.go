@@ -0,0 +1,114 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func setupPayloadRouter() *Engine {
+	r := New()
+	r.Use(TransformPayloads(
+		PayloadMigration{
+			Version:  "1",
+			Request:  RenameFields(map[string]string{"customer_id": "customerId"}),
+			Response: RenameFields(map[string]string{"customerId": "customer_id"}),
+		},
+		PayloadMigration{
+			Version:  "2",
+			Request:  DefaultFields(H{"currency": "USD"}),
+			Response: RemoveFields("currency"),
+		},
+	))
+	r.POST("/orders", func(c *Context) {
+		var order map[string]any
+		if err := c.ShouldBindJSON(&order); err != nil {
+			c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+			return
+		}
+		order["version"] = GetPayloadVersion(c)
+		order["items"] = []any{H{"customerId": order["customerId"]}}
+		c.JSON(http.StatusCreated, order)
+	})
+	return r
+}
+
+func payloadRequest(r *Engine, version, body string) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if version != "" {
+		req.Header.Set("X-API-Version", version)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestTransformPayloads(t *testing.T) {
+	r := setupPayloadRouter()
+
+	// Current clients are untouched
+	w, resp := payloadRequest(r, "", `{"customerId":7,"currency":"EUR"}`)
+	if w.Code != http.StatusCreated || resp["customerId"] != float64(7) || resp["currency"] != "EUR" {
+		t.Errorf("Unexpected response %d %v", w.Code, resp)
+	}
+
+	// v2 clients get a default currency, which is removed from responses
+	w, resp = payloadRequest(r, "2", `{"customerId":7}`)
+	if w.Code != http.StatusCreated || resp["customerId"] != float64(7) || resp["currency"] != nil || resp["version"] != "2" {
+		t.Errorf("Unexpected v2 response %d %v", w.Code, resp)
+	}
+
+	// v1 clients get both migrations
+	w, resp = payloadRequest(r, "1", `{"customer_id":7}`)
+	if w.Code != http.StatusCreated || resp["customerId"] != nil || resp["currency"] != nil {
+		t.Fatalf("Unexpected v1 response %d %v", w.Code, resp)
+	}
+	if resp["customer_id"] != float64(7) || resp["version"] != "1" {
+		t.Errorf("Expected the renamed field, got %v", resp)
+	}
+	if item := resp["items"].([]any)[0].(map[string]any); item["customer_id"] == nil {
+		t.Errorf("Expected nested fields to be renamed, got %v", item)
+	}
+	if w.Header().Get("Content-Length") != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("Unexpected Content-Length %s", w.Header().Get("Content-Length"))
+	}
+
+	w, _ = payloadRequest(r, "1", `{"customer_id":`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid JSON to be rejected, got %d", w.Code)
+	}
+}
+
+func TestTransformPayloadsError(t *testing.T) {
+	r := New()
+	r.GET("/:version/ping", TransformPayloads(PayloadMigration{
+		Version: "v1",
+		Response: func(c *Context, payload any) (any, error) {
+			return nil, errors.New("no v1 shape")
+		},
+	}), func(c *Context) {
+		c.JSON(http.StatusOK, H{"pong": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/ping", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a failed response transform to fail, got %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v2/ping", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"pong":true}` {
+		t.Errorf("Expected v2 untouched, got %d %s", w.Code, w.Body.String())
+	}
+}
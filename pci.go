@@ -0,0 +1,396 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCardNumber is returned when binding a CardNumber that isn't a
+// valid card number
+var ErrInvalidCardNumber = RegisterErrorCode("invalid_card_number", http.StatusUnprocessableEntity, "Invalid card number")
+
+// ErrNoCardTokenizer is returned when binding a CardNumber while
+// TokenizeCard is not set
+var ErrNoCardTokenizer = errors.New("goTap: TokenizeCard is not configured")
+
+// LuhnValid reports whether number, ignoring spaces and dashes, is a 12 to
+// 19 digit number passing the Luhn checksum of payment card numbers
+func LuhnValid(number string) bool {
+	digits := cardDigits(number)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+	return luhn(digits)
+}
+
+// cardDigits removes spaces and dashes, returning "" if other characters
+// remain
+func cardDigits(number string) string {
+	var b strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-':
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
+
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// MaskPANs masks all but the last four digits of every card number (PAN)
+// in s: runs of 13 to 19 digits, optionally grouped by spaces or dashes,
+// passing the Luhn check, e.g. "card 4111 1111 1111 1111 declined" becomes
+// "card **** **** **** 1111 declined"
+func MaskPANs(s string) string {
+	masked, ok := maskPANs([]byte(s))
+	if !ok {
+		return s
+	}
+	return string(masked)
+}
+
+// maskPANs returns a masked copy of data and true if it contains PANs, or
+// data and false
+func maskPANs(data []byte) ([]byte, bool) {
+	var out []byte
+	var positions, groups []int
+	for i := 0; i < len(data); {
+		if !isASCIIDigit(data[i]) {
+			i++
+			continue
+		}
+
+		// Collect a run of digit groups separated by single spaces or dashes
+		positions, groups = positions[:0], groups[:0]
+		j := i
+		for j < len(data) {
+			if isASCIIDigit(data[j]) {
+				if j == i || !isASCIIDigit(data[j-1]) {
+					groups = append(groups, len(positions))
+				}
+				positions = append(positions, j)
+				j++
+			} else if (data[j] == ' ' || data[j] == '-') && j+1 < len(data) && isASCIIDigit(data[j+1]) {
+				j++
+			} else {
+				break
+			}
+		}
+		groups = append(groups, len(positions))
+
+		// Check every span of whole groups, so a PAN next to another
+		// number, e.g. "qty 2 4111111111111111", is still found
+		for a := 0; a < len(groups)-1; a++ {
+			for b := a + 1; b < len(groups); b++ {
+				span := positions[groups[a]:groups[b]]
+				if len(span) > 19 {
+					break
+				}
+				if len(span) < 13 || !luhnAt(data, span) {
+					continue
+				}
+				if out == nil {
+					out = bytes.Clone(data)
+				}
+				for _, p := range span[:len(span)-4] {
+					out[p] = '*'
+				}
+			}
+		}
+		i = j
+	}
+	if out == nil {
+		return data, false
+	}
+	return out, true
+}
+
+// maskPANsIn masks PANs like maskPANs, but only inside the strings of a
+// JSON document, so numbers such as millisecond timestamps or snowflake IDs
+// are never altered and the document stays valid
+func maskPANsIn(data []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid(trimmed) {
+		return maskPANs(data)
+	}
+
+	var out []byte
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			continue
+		}
+		start := i + 1
+		for i = start; i < len(data) && data[i] != '"'; i++ {
+			if data[i] == '\\' {
+				i++
+			}
+		}
+		if masked, ok := maskPANs(data[start:i]); ok {
+			if out == nil {
+				out = bytes.Clone(data)
+			}
+			copy(out[start:i], masked)
+		}
+	}
+	if out == nil {
+		return data, false
+	}
+	return out, true
+}
+
+func luhnAt(data []byte, positions []int) bool {
+	digits := make([]byte, len(positions))
+	for i, p := range positions {
+		digits[i] = data[p]
+	}
+	return luhn(string(digits))
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// PANScrubberConfig holds configuration for the PANScrubber middleware
+type PANScrubberConfig struct {
+	// SkipRequest leaves request bodies and query strings unchanged
+	// Optional.
+	SkipRequest bool
+
+	// SkipResponse leaves response bodies unchanged
+	// Optional.
+	SkipResponse bool
+
+	// OnDetect is called when a PAN was masked in the "request" or
+	// "response", e.g. to alert on card data reaching the wrong endpoint
+	// Optional.
+	OnDetect func(c *Context, source string)
+}
+
+// PANScrubber returns a middleware masking card numbers (PANs) in request
+// bodies, query strings and response bodies, see MaskPANs. In JSON bodies
+// only strings are masked, so numbers keep their value. Use it on
+// routes outside the cardholder data environment, so stray card numbers,
+// e.g. typed into a note field, are never stored or echoed:
+//
+//	r.Use(goTap.PANScrubber())
+//
+// Responses are buffered until the handler returns. Combine it with
+// PANScrubbingWriter for logs.
+func PANScrubber() HandlerFunc {
+	return PANScrubberWithConfig(PANScrubberConfig{})
+}
+
+// PANScrubberWithConfig returns a PANScrubber middleware with config
+func PANScrubberWithConfig(config PANScrubberConfig) HandlerFunc {
+	detected := func(c *Context, source string) {
+		if config.OnDetect != nil {
+			config.OnDetect(c, source)
+		}
+	}
+
+	return func(c *Context) {
+		if !config.SkipRequest {
+			if query, ok := maskPANs([]byte(c.Request.URL.RawQuery)); ok {
+				c.Request.URL.RawQuery = string(query)
+				c.queryCache = nil
+				detected(c, "request")
+			}
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				body, err := io.ReadAll(c.Request.Body)
+				c.Request.Body.Close()
+				if err != nil {
+					c.JSON(http.StatusBadRequest, H{
+						"error":   "Bad Request",
+						"message": "Failed to read request body",
+					})
+					c.Abort()
+					return
+				}
+				if masked, ok := maskPANsIn(body); ok {
+					body = masked
+					detected(c, "request")
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+				c.Request.ContentLength = int64(len(body))
+			}
+		}
+
+		if config.SkipResponse {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &deltaWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		masked, ok := maskPANsIn(w.body.Bytes())
+		if !ok {
+			w.flush()
+			return
+		}
+		detected(c, "response")
+		original.Header().Set("Content-Length", strconv.Itoa(len(masked)))
+		original.WriteHeader(w.status)
+		original.Write(masked)
+	}
+}
+
+// PANScrubbingWriter returns a writer masking card numbers in everything
+// written to w, see MaskPANs. Each Write is masked separately, which suits
+// loggers writing whole lines; in JSON lines only strings are masked:
+//
+//	r.Use(goTap.LoggerWithConfig(goTap.LoggerConfig{
+//	    Output: goTap.PANScrubbingWriter(os.Stdout),
+//	}))
+//	log.SetOutput(goTap.PANScrubbingWriter(os.Stderr))
+func PANScrubbingWriter(w io.Writer) io.Writer {
+	return &panScrubbingWriter{w: w}
+}
+
+type panScrubbingWriter struct {
+	w io.Writer
+}
+
+func (s *panScrubbingWriter) Write(p []byte) (int, error) {
+	masked, _ := maskPANsIn(p)
+	if _, err := s.w.Write(masked); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CardTokenizer replaces a card number with a token, e.g. from a payment
+// gateway or vault
+type CardTokenizer func(pan string) (string, error)
+
+// TokenizeCard tokenizes card numbers bound into CardNumber fields. It must
+// be set at startup, to your payment gateway's tokenization or to
+// HMACCardTokenizer with a key shared by every instance; until then binding
+// a CardNumber fails with ErrNoCardTokenizer.
+var TokenizeCard CardTokenizer
+
+// HMACCardTokenizer returns a CardTokenizer deriving tokens with
+// HMAC-SHA256 under key, which must be at least 32 bytes. Tokens stay the
+// same across restarts and instances as long as the key does:
+//
+//	goTap.TokenizeCard = goTap.HMACCardTokenizer(cardTokenKey)
+func HMACCardTokenizer(key []byte) CardTokenizer {
+	if len(key) < 32 {
+		panic("goTap: HMACCardTokenizer requires a key of at least 32 bytes")
+	}
+	key = bytes.Clone(key)
+	return func(pan string) (string, error) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(pan))
+		return "tok_" + hex.EncodeToString(mac.Sum(nil))[:32], nil
+	}
+}
+
+// CardNumber binds a card number from JSON, validating it with LuhnValid
+// and tokenizing it with TokenizeCard, so the number itself never reaches
+// handlers, models or logs:
+//
+//	type Payment struct {
+//	    Card   goTap.CardNumber `json:"card"`
+//	    Amount goTap.Money      `json:"amount"`
+//	}
+//
+//	var p Payment
+//	if err := c.ShouldBindJSON(&p); err != nil { // ErrInvalidCardNumber
+//	    return
+//	}
+//	charge(p.Card.Token)
+//
+// It is rendered as {"token":...,"last4":...,"brand":...} but only binds
+// from a card number string, so clients can't supply a token of their own.
+type CardNumber struct {
+	Token string `json:"token"`
+	Last4 string `json:"last4"`
+	Brand string `json:"brand,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *CardNumber) UnmarshalJSON(data []byte) error {
+	var number string
+	if err := json.Unmarshal(data, &number); err != nil || !LuhnValid(number) {
+		return ErrInvalidCardNumber
+	}
+	if TokenizeCard == nil {
+		return ErrNoCardTokenizer
+	}
+	digits := cardDigits(number)
+	token, err := TokenizeCard(digits)
+	if err != nil {
+		return err
+	}
+	*n = CardNumber{Token: token, Last4: digits[len(digits)-4:], Brand: CardBrand(digits)}
+	return nil
+}
+
+// String returns the masked number, e.g. "**** **** **** 1111"
+func (n CardNumber) String() string {
+	return MaskCard(n.Last4)
+}
+
+// CardBrand returns the brand of a card number by its prefix, e.g. "visa",
+// "mastercard", "amex", "discover", "jcb", "diners" or "unionpay", or ""
+func CardBrand(number string) string {
+	digits := cardDigits(number)
+	prefix := func(n int) int {
+		if len(digits) < n {
+			return -1
+		}
+		p, _ := strconv.Atoi(digits[:n])
+		return p
+	}
+	switch p2, p3, p4 := prefix(2), prefix(3), prefix(4); {
+	case strings.HasPrefix(digits, "4"):
+		return "visa"
+	case p2 >= 51 && p2 <= 55, p4 >= 2221 && p4 <= 2720:
+		return "mastercard"
+	case p2 == 34 || p2 == 37:
+		return "amex"
+	case p4 == 6011, p2 == 65, p3 >= 644 && p3 <= 649:
+		return "discover"
+	case p4 >= 3528 && p4 <= 3589:
+		return "jcb"
+	case p2 == 36, p2 == 38, p3 >= 300 && p3 <= 305:
+		return "diners"
+	case p2 == 62:
+		return "unionpay"
+	}
+	return ""
+}
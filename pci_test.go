@@ -0,0 +1,174 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaskPANs(t *testing.T) {
+	tests := map[string]string{
+		"card 4111 1111 1111 1111 declined": "card **** **** **** 1111 declined",
+		`{"pan":"5500-0000-0000-0004"}`:     `{"pan":"****-****-****-0004"}`,
+		"amex 378282246310005":              "amex ***********0005",
+		"qty 2 4111111111111111":            "qty 2 ************1111",
+		"order 4111111111111112":            "order 4111111111111112", // fails Luhn
+		"phone 555-0100, total 1250":        "phone 555-0100, total 1250",
+		"id 41111111111111111111111111":     "id 41111111111111111111111111", // too long
+		"no digits":                         "no digits",
+	}
+	for input, want := range tests {
+		if got := MaskPANs(input); got != want {
+			t.Errorf("MaskPANs(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if !LuhnValid("4111-1111-1111-1111") || LuhnValid("4111 1111 1111 1112") || LuhnValid("4111x1111") {
+		t.Error("Unexpected LuhnValid results")
+	}
+}
+
+func TestPANScrubber(t *testing.T) {
+	var detected []string
+	r := New()
+	r.Use(PANScrubberWithConfig(PANScrubberConfig{OnDetect: func(c *Context, source string) {
+		detected = append(detected, source)
+	}}))
+	r.POST("/notes", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "%s|%s", body, c.Query("ref"))
+	})
+	r.GET("/echo", func(c *Context) {
+		c.JSON(http.StatusOK, H{"card": "4111111111111111"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/notes?ref=4111111111111111", strings.NewReader("customer card 4111 1111 1111 1111")))
+	if w.Code != http.StatusCreated || w.Body.String() != "customer card **** **** **** 1111|************1111" {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if len(detected) != 2 || detected[0] != "request" {
+		t.Errorf("Unexpected detections %v", detected)
+	}
+
+	detected = nil
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/echo", nil))
+	if strings.TrimSpace(w.Body.String()) != `{"card":"************1111"}` || len(detected) != 1 || detected[0] != "response" {
+		t.Errorf("Unexpected response %s %v", w.Body.String(), detected)
+	}
+}
+
+func TestPANScrubberKeepsJSONNumbers(t *testing.T) {
+	// A millisecond timestamp passing the Luhn check
+	if !LuhnValid("1760630400008") {
+		t.Fatal("Expected the timestamp to pass the Luhn check")
+	}
+	var received map[string]any
+	r := New()
+	r.Use(PANScrubber())
+	r.POST("/orders", func(c *Context) {
+		if err := c.ShouldBindJSON(&received); err != nil {
+			t.Errorf("Expected the scrubbed request to stay valid JSON: %v", err)
+		}
+		c.JSON(http.StatusOK, H{"created_at_ms": int64(1760630400008), "note": "card 4111111111111111"})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"id":1760630400008,"note":"4111 1111 1111 1111"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if received["id"] != float64(1760630400008) || received["note"] != "**** **** **** 1111" {
+		t.Errorf("Unexpected request %v", received)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected the scrubbed response to stay valid JSON: %v %s", err, w.Body)
+	}
+	if body["created_at_ms"] != float64(1760630400008) || body["note"] != "card ************1111" {
+		t.Errorf("Unexpected response %v", body)
+	}
+}
+
+func TestPANScrubbingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := PANScrubbingWriter(&buf)
+	line := "charge failed for 4111111111111111\n"
+	if n, err := w.Write([]byte(line)); n != len(line) || err != nil {
+		t.Errorf("Unexpected write %d %v", n, err)
+	}
+	if buf.String() != "charge failed for ************1111\n" {
+		t.Errorf("Unexpected log %q", buf.String())
+	}
+}
+
+func TestCardNumber(t *testing.T) {
+	var payment struct {
+		Card CardNumber `json:"card"`
+	}
+	if err := json.Unmarshal([]byte(`{"card":"4111 1111 1111 1111"}`), &payment); !errors.Is(err, ErrNoCardTokenizer) {
+		t.Errorf("Expected ErrNoCardTokenizer without a tokenizer, got %v", err)
+	}
+	defer func(tokenize CardTokenizer) { TokenizeCard = tokenize }(TokenizeCard)
+	TokenizeCard = HMACCardTokenizer(bytes.Repeat([]byte("k"), 32))
+
+	if err := json.Unmarshal([]byte(`{"card":"4111 1111 1111 1111"}`), &payment); err != nil {
+		t.Fatal(err)
+	}
+	card := payment.Card
+	if !strings.HasPrefix(card.Token, "tok_") || card.Last4 != "1111" || card.Brand != "visa" {
+		t.Errorf("Unexpected card %+v", card)
+	}
+	if card.String() != "**** **** **** 1111" {
+		t.Errorf("Unexpected string %s", card.String())
+	}
+
+	// The token is stable, also with another tokenizer using the same key
+	var again CardNumber
+	TokenizeCard = HMACCardTokenizer(bytes.Repeat([]byte("k"), 32))
+	json.Unmarshal([]byte(`"4111111111111111"`), &again)
+	if again.Token != card.Token {
+		t.Error("Expected the same token for the same number")
+	}
+	data, _ := json.Marshal(card)
+	if strings.Contains(string(data), "4111111111111111") {
+		t.Errorf("Expected the number not to be rendered: %s", data)
+	}
+
+	// Clients can't bind a token and brand of their choosing
+	var forged CardNumber
+	if err := json.Unmarshal([]byte(`{"token":"tok_forged","last4":"1111","brand":"visa"}`), &forged); !errors.Is(err, ErrInvalidCardNumber) {
+		t.Errorf("Expected ErrInvalidCardNumber for an object, got %v", err)
+	}
+	if forged.Token != "" {
+		t.Errorf("Expected no token to be bound, got %+v", forged)
+	}
+
+	if err := json.Unmarshal([]byte(`{"card":"4111111111111112"}`), &payment); !errors.Is(err, ErrInvalidCardNumber) {
+		t.Errorf("Expected ErrInvalidCardNumber, got %v", err)
+	}
+
+	for number, brand := range map[string]string{
+		"5555555555554444": "mastercard",
+		"2221000000000009": "mastercard",
+		"378282246310005":  "amex",
+		"6011111111111117": "discover",
+		"3530111333300000": "jcb",
+		"30569309025904":   "diners",
+		"9999999999999995": "",
+	} {
+		if got := CardBrand(number); got != brand {
+			t.Errorf("CardBrand(%s) = %q, want %q", number, got, brand)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sync"
+)
+
+// maxPooledBufferSize is the largest buffer returned to a pool. Buffers
+// grown by unusually large responses are left to the garbage collector so
+// the pools don't pin that memory.
+const maxPooledBufferSize = 64 << 10
+
+// jsonEncoder is an encoder of the current JSONCodec bound to its own
+// buffer. Rendering encodes into the buffer and writes the response in a
+// single call.
+type jsonEncoder struct {
+	buf        bytes.Buffer
+	enc        JSONEncoder
+	codec      *jsonCodecState
+	escapeHTML bool
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() any { return &jsonEncoder{} },
+}
+
+// getJSONEncoder returns a pooled encoder with an empty buffer. Encoders
+// created by a codec replaced since are rebuilt.
+func getJSONEncoder(escapeHTML bool) *jsonEncoder {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	if codec := currentJSONCodec.Load(); e.codec != codec {
+		e.codec = codec
+		e.enc = codec.codec.NewEncoder(&e.buf)
+		e.enc.SetEscapeHTML(escapeHTML)
+		e.escapeHTML = escapeHTML
+	} else if e.escapeHTML != escapeHTML {
+		// Some codecs rebuild their configuration on every call, so only
+		// changes are applied
+		e.enc.SetEscapeHTML(escapeHTML)
+		e.escapeHTML = escapeHTML
+	}
+	return e
+}
+
+// putJSONEncoder returns e to the pool. The buffer must not be used afterwards.
+func putJSONEncoder(e *jsonEncoder) {
+	if e.buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	e.buf.Reset()
+	jsonEncoderPool.Put(e)
+}
+
+// encode encodes obj into the pooled buffer. Codecs such as jsoniter keep
+// the first error, so a failed encoder is rebuilt on its next use.
+func (e *jsonEncoder) encode(obj any) error {
+	err := e.enc.Encode(obj)
+	if err != nil {
+		e.codec = nil
+	}
+	return err
+}
+
+// marshal encodes obj like json.Marshal, into the pooled buffer
+func (e *jsonEncoder) marshal(obj any) ([]byte, error) {
+	if err := e.encode(obj); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}), nil
+}
+
+var renderBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns a pooled empty buffer for rendering a response
+func getBuffer() *bytes.Buffer {
+	return renderBufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool. The buffer must not be used afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	renderBufferPool.Put(buf)
+}
+
+// xmlEncoder is an indenting xml.Encoder bound to its own buffer
+type xmlEncoder struct {
+	buf bytes.Buffer
+	enc *xml.Encoder
+}
+
+var xmlEncoderPool = sync.Pool{
+	New: func() any {
+		e := &xmlEncoder{}
+		e.enc = xml.NewEncoder(&e.buf)
+		e.enc.Indent("", "  ")
+		return e
+	},
+}
+
+// getXMLEncoder returns a pooled encoder with an empty buffer
+func getXMLEncoder() *xmlEncoder {
+	return xmlEncoderPool.Get().(*xmlEncoder)
+}
+
+// bytes returns the encoded document. A reused encoder starts it with the
+// newline separating it from the previous one, which is dropped.
+func (e *xmlEncoder) bytes() []byte {
+	return bytes.TrimPrefix(e.buf.Bytes(), []byte{'\n'})
+}
+
+// putXMLEncoder returns e to the pool after a successful Encode; an encoder
+// that failed may hold unclosed elements and is dropped
+func putXMLEncoder(e *xmlEncoder) {
+	if e.buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	e.buf.Reset()
+	xmlEncoderPool.Put(e)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// PprofConfig holds configuration for the debug endpoints mounted by
+// PprofWithConfig
+type PprofConfig struct {
+	// AllowedIPs restricts the endpoints to these IPs and CIDR ranges, see
+	// IPWhitelist
+	// Default: any IP
+	AllowedIPs []string
+
+	// Accounts require HTTP basic auth, see BasicAuth
+	// Default: no authentication
+	Accounts Accounts
+
+	// Middleware runs before the endpoints, e.g. JWT authentication
+	Middleware []HandlerFunc
+}
+
+// RuntimeStats reports the Go runtime of the process, served by the
+// runtime endpoint of Pprof
+type RuntimeStats struct {
+	StatusRuntime
+	UptimeSeconds     int64   `json:"uptime_seconds"`
+	HeapObjects       uint64  `json:"heap_objects"`
+	HeapIdleBytes     uint64  `json:"heap_idle_bytes"`
+	HeapReleasedBytes uint64  `json:"heap_released_bytes"`
+	StackInuseBytes   uint64  `json:"stack_inuse_bytes"`
+	NextGCBytes       uint64  `json:"next_gc_bytes"`
+	GCPauseTotalNs    uint64  `json:"gc_pause_total_ns"`
+	GCCPUFraction     float64 `json:"gc_cpu_fraction"`
+	NumCgoCall        int64   `json:"num_cgo_call"`
+}
+
+// Pprof mounts the net/http/pprof profiles, expvar variables and runtime
+// stats on group, for diagnosing production servers:
+//
+//	goTap.Pprof(r.Group("/debug"))
+//
+//	go tool pprof http://localhost:5066/debug/pprof/heap
+//	curl localhost:5066/debug/vars
+//	curl localhost:5066/debug/runtime
+//
+// The endpoints expose internals, so guard them with PprofWithConfig in
+// production.
+func Pprof(group *RouterGroup) {
+	PprofWithConfig(group, PprofConfig{})
+}
+
+// PprofWithConfig mounts the Pprof endpoints with config:
+//
+//	goTap.PprofWithConfig(r.Group("/debug"), goTap.PprofConfig{
+//	    AllowedIPs: []string{"10.0.0.0/8"},
+//	    Accounts:   goTap.Accounts{"ops": os.Getenv("DEBUG_PASSWORD")},
+//	})
+func PprofWithConfig(group *RouterGroup, config PprofConfig) {
+	var guards []HandlerFunc
+	if len(config.AllowedIPs) > 0 {
+		guards = append(guards, IPWhitelist(config.AllowedIPs...))
+	}
+	if len(config.Accounts) > 0 {
+		guards = append(guards, BasicAuthForRealm(config.Accounts, "Debug"))
+	}
+	guards = append(guards, config.Middleware...)
+	debug := group.Group("", guards...)
+
+	debug.GET("/pprof/", WrapF(pprof.Index))
+	debug.GET("/pprof/cmdline", WrapF(pprof.Cmdline))
+	debug.GET("/pprof/profile", WrapF(pprof.Profile))
+	debug.GET("/pprof/symbol", WrapF(pprof.Symbol))
+	debug.POST("/pprof/symbol", WrapF(pprof.Symbol))
+	debug.GET("/pprof/trace", WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		debug.GET("/pprof/"+name, WrapH(pprof.Handler(name)))
+	}
+	debug.GET("/vars", WrapH(expvar.Handler()))
+	debug.GET("/runtime", runtimeStats)
+}
+
+func runtimeStats(c *Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(200, readRuntimeStats())
+}
+
+func readRuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return RuntimeStats{
+		StatusRuntime:     statusRuntime(&mem),
+		UptimeSeconds:     int64(time.Since(processStart).Seconds()),
+		HeapObjects:       mem.HeapObjects,
+		HeapIdleBytes:     mem.HeapIdle,
+		HeapReleasedBytes: mem.HeapReleased,
+		StackInuseBytes:   mem.StackInuse,
+		NextGCBytes:       mem.NextGC,
+		GCPauseTotalNs:    mem.PauseTotalNs,
+		GCCPUFraction:     mem.GCCPUFraction,
+		NumCgoCall:        runtime.NumCgoCall(),
+	}
+}
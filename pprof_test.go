@@ -0,0 +1,67 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPprof(t *testing.T) {
+	r := New()
+	Pprof(r.Group("/debug"))
+
+	for path, want := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile",
+		"/debug/vars":                    "memstats",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 200 || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: expected 200 containing %q, got %d", path, want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/debug/runtime", nil))
+	var stats RuntimeStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Goroutines == 0 || stats.HeapObjects == 0 || w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Unexpected runtime stats %s", w.Body.String())
+	}
+}
+
+func TestPprofGuards(t *testing.T) {
+	r := New()
+	PprofWithConfig(r.Group("/debug"), PprofConfig{
+		AllowedIPs: []string{"10.0.0.0/8"},
+		Accounts:   Accounts{"ops": "secret"},
+	})
+	get := func(remote string, auth bool) int {
+		req := httptest.NewRequest("GET", "/debug/runtime", nil)
+		req.RemoteAddr = remote + ":1234"
+		if auth {
+			req.SetBasicAuth("ops", "secret")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get("192.168.1.1", true); code != 403 {
+		t.Errorf("Expected 403 for a foreign IP, got %d", code)
+	}
+	if code := get("10.1.2.3", false); code != 401 {
+		t.Errorf("Expected 401 without credentials, got %d", code)
+	}
+	if code := get("10.1.2.3", true); code != 200 {
+		t.Errorf("Expected 200 for an allowed client, got %d", code)
+	}
+}
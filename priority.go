@@ -0,0 +1,47 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+// Priority is the request class of a route, used by LoadShed to decide
+// which traffic to drop first under overload
+type Priority int
+
+const (
+	// PriorityBackground is for traffic that can wait or fail, e.g.
+	// reports, exports and syncs
+	PriorityBackground Priority = -1
+	// PriorityNormal is the priority of routes that declare none
+	PriorityNormal Priority = 0
+	// PriorityCritical is for traffic that must be served, e.g. payments
+	PriorityCritical Priority = 1
+)
+
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "background"
+	case p > PriorityNormal:
+		return "critical"
+	}
+	return "normal"
+}
+
+// Priority returns a group whose routes have priority p:
+//
+//	pos := r.Group("/pos").Priority(goTap.PriorityCritical)
+//	reports := r.Group("/reports").Priority(goTap.PriorityBackground)
+func (group *RouterGroup) Priority(p Priority) *RouterGroup {
+	child := group.Group("")
+	child.priority = p
+	return child
+}
+
+// Priority returns the priority declared by the matched route
+func (c *Context) Priority() Priority {
+	if c.engine == nil {
+		return PriorityNormal
+	}
+	return c.engine.routeMeta[c.Request.Method+" "+c.FullPath()].priority
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MIMEProblemJSON is the media type of RFC 9457 problem details
+const MIMEProblemJSON = "application/problem+json"
+
+// APIError is an error with a stable, machine-readable code. Clients
+// switch on Code, while Title and Detail are translated for the request's
+// locale, see RegisterErrorMessages. Define errors once with
+// RegisterErrorCode and add details where they occur:
+//
+//	var ErrOutOfStock = goTap.RegisterErrorCode("out_of_stock", 409, "Product is out of stock")
+//
+//	c.Problem(ErrOutOfStock.WithDetail("Only {available} left", goTap.H{"available": 2}))
+type APIError struct {
+	// Code identifies the error, e.g. "out_of_stock"
+	Code string
+
+	// Status is the HTTP status code
+	Status int
+
+	// Title summarizes the error in English
+	Title string
+
+	// Detail explains this occurrence in English; "{name}" is replaced by
+	// Params
+	Detail string
+	Params H
+
+	// Err is the underlying cause; it is logged but never sent to clients
+	Err error
+}
+
+var _ error = (*APIError)(nil)
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := e.Code + ": " + e.Title
+	if e.Detail != "" {
+		msg += ": " + expandParams(e.Detail, e.Params)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an APIError with the same code, so
+// errors.Is(err, ErrOutOfStock) matches copies made by WithDetail and Wrap
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of e explaining this occurrence
+func (e *APIError) WithDetail(detail string, params H) *APIError {
+	c := *e
+	c.Detail, c.Params = detail, params
+	return &c
+}
+
+// Wrap returns a copy of e caused by err
+func (e *APIError) Wrap(err error) *APIError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+var (
+	errorCodesMu sync.RWMutex
+	errorCodes   = map[string]*APIError{}
+)
+
+// RegisterErrorCode defines an error code. Codes are part of the API
+// contract: registering the same code twice panics.
+func RegisterErrorCode(code string, status int, title string) *APIError {
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+	if _, ok := errorCodes[code]; ok {
+		panic("goTap: error code " + code + " is already registered")
+	}
+	e := &APIError{Code: code, Status: status, Title: title}
+	errorCodes[code] = e
+	return e
+}
+
+// ErrorCodes returns the registered error codes sorted by code, e.g. to
+// document them
+func ErrorCodes() []*APIError {
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+	codes := make([]*APIError, 0, len(errorCodes))
+	for _, e := range errorCodes {
+		codes = append(codes, e)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// Built-in error codes
+var (
+	ErrBadRequest      = RegisterErrorCode("bad_request", http.StatusBadRequest, "Bad request")
+	ErrUnauthorized    = RegisterErrorCode("unauthorized", http.StatusUnauthorized, "Authentication required")
+	ErrForbidden       = RegisterErrorCode("forbidden", http.StatusForbidden, "Access denied")
+	ErrNotFound        = RegisterErrorCode("not_found", http.StatusNotFound, "Resource not found")
+	ErrConflict        = RegisterErrorCode("conflict", http.StatusConflict, "Conflict with the current state")
+	ErrValidation      = RegisterErrorCode("validation_failed", http.StatusUnprocessableEntity, "Validation failed")
+	ErrTooManyRequests = RegisterErrorCode("too_many_requests", http.StatusTooManyRequests, "Too many requests")
+	ErrInternal        = RegisterErrorCode("internal_error", http.StatusInternalServerError, "Internal server error")
+)
+
+// ErrorMessage translates the title and detail of an error code. "{name}"
+// in Detail is replaced by the error's Params.
+type ErrorMessage struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+var (
+	errorMessagesMu sync.RWMutex
+	errorMessages   = map[string]map[string]ErrorMessage{}
+)
+
+// RegisterErrorMessages adds translations of error codes for a locale,
+// e.g. "de" or "de-CH". Problems use the messages of the request's locale,
+// then of its language, then the English title and detail of the error.
+//
+//	goTap.RegisterErrorMessages("de", map[string]goTap.ErrorMessage{
+//	    "out_of_stock": {Title: "Artikel nicht vorrätig", Detail: "Nur noch {available} verfügbar"},
+//	})
+func RegisterErrorMessages(locale string, messages map[string]ErrorMessage) {
+	locale = normalizeLocale(locale)
+	errorMessagesMu.Lock()
+	defer errorMessagesMu.Unlock()
+	if errorMessages[locale] == nil {
+		errorMessages[locale] = map[string]ErrorMessage{}
+	}
+	for code, msg := range messages {
+		errorMessages[locale][code] = msg
+	}
+}
+
+// lookupErrorMessage returns the translation of code for locale or its
+// language, and the locale it was found for
+func lookupErrorMessage(locale, code string) (ErrorMessage, string, bool) {
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	errorMessagesMu.RLock()
+	defer errorMessagesMu.RUnlock()
+	for _, l := range []string{locale, language} {
+		if msg, ok := errorMessages[l][code]; ok {
+			return msg, l, true
+		}
+	}
+	return ErrorMessage{}, "", false
+}
+
+func hasErrorMessages(locale string) bool {
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	errorMessagesMu.RLock()
+	defer errorMessagesMu.RUnlock()
+	return len(errorMessages[locale]) > 0 || len(errorMessages[language]) > 0
+}
+
+// Problem holds RFC 9457 problem details
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Code is the stable error code, see RegisterErrorCode
+	Code string `json:"code"`
+}
+
+// toAPIError finds the APIError in err. Binding errors become
+// ErrValidation and other errors ErrInternal.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var ctxErr *Error
+	if errors.As(err, &ctxErr) && ctxErr.IsType(ErrorTypeBind) {
+		return ErrValidation.WithDetail(ctxErr.Err.Error(), nil)
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// NewProblem returns the problem details of err translated for the
+// request's locale, see Context.Locale
+func (c *Context) NewProblem(err error) Problem {
+	apiErr := toAPIError(err)
+	p := Problem{
+		Title:    apiErr.Title,
+		Status:   apiErr.Status,
+		Detail:   apiErr.Detail,
+		Instance: c.Request.URL.Path,
+		Code:     apiErr.Code,
+	}
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if msg, _, ok := lookupErrorMessage(c.Locale(), apiErr.Code); ok {
+		if msg.Title != "" {
+			p.Title = msg.Title
+		}
+		if msg.Detail != "" {
+			p.Detail = msg.Detail
+		}
+	}
+	p.Detail = expandParams(p.Detail, apiErr.Params)
+	return p
+}
+
+// Problem writes err as application/problem+json with its title and detail
+// translated for the request's locale, and aborts. Errors that aren't an
+// APIError are sent as ErrInternal without their message.
+func (c *Context) Problem(err error) {
+	// Keep the cause for the logs
+	if cause := toAPIError(err).Err; cause != nil && !c.hasError(cause) {
+		c.Error(cause)
+	}
+	p := c.NewProblem(err)
+	if _, locale, ok := lookupErrorMessage(c.Locale(), p.Code); ok {
+		c.Header("Content-Language", locale)
+	}
+	body, _ := json.Marshal(p)
+	c.Abort()
+	c.Data(p.Status, MIMEProblemJSON, body)
+}
+
+// Problems returns a middleware writing the last error added with
+// Context.Error as problem details when the handler wrote no response:
+//
+//	r.Use(goTap.Problems())
+//	r.POST("/orders", func(c *goTap.Context) {
+//	    if err := c.ShouldBindJSON(&order); err != nil {
+//	        c.Error(err).SetType(goTap.ErrorTypeBind)
+//	        return
+//	    }
+//	    if stock < order.Quantity {
+//	        c.Error(ErrOutOfStock)
+//	        return
+//	    }
+//	})
+func Problems() HandlerFunc {
+	return func(c *Context) {
+		c.Next()
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		c.Problem(c.Errors.Last())
+	}
+}
+
+func (c *Context) hasError(err error) bool {
+	for _, e := range c.Errors {
+		if e == err || e.Err == err {
+			return true
+		}
+	}
+	return false
+}
+
+// expandParams replaces "{name}" in s with params
+func expandParams(s string, params H) string {
+	for k, v := range params {
+		s = strings.ReplaceAll(s, "{"+k+"}", fmt.Sprint(v))
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+var errTestOutOfStock = RegisterErrorCode("test_out_of_stock", 409, "Product is out of stock")
+
+func TestProblemTranslation(t *testing.T) {
+	RegisterErrorMessages("de", map[string]ErrorMessage{
+		"test_out_of_stock": {Title: "Artikel nicht vorrätig", Detail: "Nur noch {available} verfügbar"},
+		"validation_failed": {Title: "Ungültige Eingabe"},
+	})
+	RegisterErrorMessages("uk", map[string]ErrorMessage{
+		"test_out_of_stock": {Title: "Товару немає в наявності"},
+	})
+
+	r := New()
+	r.Use(Problems())
+	r.POST("/orders", func(c *Context) {
+		c.Error(errTestOutOfStock.WithDetail("Only {available} left", H{"available": 2}))
+	})
+	r.POST("/bind", func(c *Context) {
+		var v struct {
+			Qty int `json:"qty" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&v); err != nil {
+			c.Error(err).SetType(ErrorTypeBind)
+		}
+	})
+	r.GET("/crash", func(c *Context) {
+		c.Problem(errors.New("connection refused"))
+	})
+
+	serve := func(method, path, lang string) (*httptest.ResponseRecorder, Problem) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var p Problem
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w, p
+	}
+
+	w, p := serve("POST", "/orders", "de-CH, en;q=0.5")
+	if w.Code != 409 || w.Header().Get("Content-Type") != MIMEProblemJSON || w.Header().Get("Content-Language") != "de" {
+		t.Errorf("Unexpected response %d %v", w.Code, w.Header())
+	}
+	want := Problem{Title: "Artikel nicht vorrätig", Status: 409, Detail: "Nur noch 2 verfügbar", Instance: "/orders", Code: "test_out_of_stock"}
+	if p != want {
+		t.Errorf("Unexpected problem %+v", p)
+	}
+
+	// A translation without detail keeps the English detail
+	if _, p = serve("POST", "/orders", "uk"); p.Title != "Товару немає в наявності" || p.Detail != "Only 2 left" {
+		t.Errorf("Unexpected problem %+v", p)
+	}
+	if _, p = serve("POST", "/orders", "fr"); p.Title != "Product is out of stock" {
+		t.Errorf("Expected the English title, got %+v", p)
+	}
+
+	if w, p = serve("POST", "/bind", "de"); w.Code != 422 || p.Code != "validation_failed" || p.Title != "Ungültige Eingabe" || p.Detail == "" {
+		t.Errorf("Unexpected bind problem %d %+v", w.Code, p)
+	}
+
+	if w, p = serve("GET", "/crash", ""); w.Code != 500 || p.Code != "internal_error" || p.Detail != "" {
+		t.Errorf("Expected an internal error without the cause, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	err := errTestOutOfStock.WithDetail("Only {n} left", H{"n": 1}).Wrap(errors.New("stock query"))
+	if !errors.Is(err, errTestOutOfStock) || errors.Is(err, ErrConflict) {
+		t.Error("Expected errors.Is to match by code")
+	}
+	if err.Error() != "test_out_of_stock: Product is out of stock: Only 1 left: stock query" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a duplicate code")
+		}
+	}()
+	RegisterErrorCode("test_out_of_stock", 409, "again")
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxyConfig holds configuration for Proxy
+type ProxyConfig struct {
+	// RewritePath maps the request path to the upstream path, which is
+	// then joined to the target's path
+	// Default: the path is unchanged
+	RewritePath func(path string) string
+
+	// ModifyResponse can change or reject upstream responses
+	// Optional.
+	ModifyResponse func(*http.Response) error
+
+	// DialTimeout bounds connecting to the upstream
+	// Default: 10 seconds
+	DialTimeout time.Duration
+
+	// ResponseHeaderTimeout bounds waiting for the upstream's response
+	// headers; bodies stream without a deadline
+	// Default: no timeout
+	ResponseHeaderTimeout time.Duration
+
+	// PreserveHost forwards the request's Host header instead of the target's
+	PreserveHost bool
+
+	// StripHeaders are removed from requests before forwarding, e.g.
+	// "Cookie" for upstreams that mustn't see the session
+	// Optional.
+	StripHeaders []string
+
+	// SetHeaders are added to forwarded requests
+	// Optional.
+	SetHeaders map[string]string
+
+	// FlushInterval is how often the response is flushed while copying.
+	// Streaming responses such as server-sent events are always flushed
+	// immediately.
+	// Default: 0, flush when the copy buffer fills
+	FlushInterval time.Duration
+
+	// Transport sends the upstream requests
+	// Default: a transport with DialTimeout and ResponseHeaderTimeout
+	Transport http.RoundTripper
+
+	// ErrorHandler writes the response when the upstream fails
+	// Default: 502, or 504 on timeouts
+	ErrorHandler func(c *Context, err error)
+}
+
+// Proxy returns a handler forwarding requests to target, for fronting
+// older services from goTap. Bodies are streamed in both directions,
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto are set from the
+// client connection, and WebSocket upgrades are passed through:
+//
+//	legacy := goTap.Proxy("http://legacy-pos:8080", goTap.ProxyConfig{
+//	    RewritePath: func(p string) string { return "/api" + p },
+//	})
+//	r.Mount("/legacy", legacy)
+func Proxy(target string, config ProxyConfig) HandlerFunc {
+	targetURL, err := url.Parse(target)
+	if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+		panic("goTap: invalid proxy target " + target)
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 10 * time.Second
+	}
+	if config.Transport == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{
+			Timeout:   config.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+		config.Transport = transport
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = defaultProxyErrorHandler
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if config.RewritePath != nil {
+				pr.Out.URL.Path = config.RewritePath(pr.In.URL.Path)
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(targetURL)
+			pr.SetXForwarded()
+			if config.PreserveHost {
+				pr.Out.Host = pr.In.Host
+			}
+			for _, header := range config.StripHeaders {
+				pr.Out.Header.Del(header)
+			}
+			for header, value := range config.SetHeaders {
+				pr.Out.Header.Set(header, value)
+			}
+		},
+		Transport:      config.Transport,
+		FlushInterval:  config.FlushInterval,
+		ModifyResponse: config.ModifyResponse,
+	}
+
+	return func(c *Context) {
+		// The error handler needs the request's Context
+		p := *proxy
+		p.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+			config.ErrorHandler(c, err)
+		}
+		p.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func defaultProxyErrorHandler(c *Context, err error) {
+	debugPrint("[WARNING] proxy error for %s: %v", c.Request.URL.Path, err)
+	code := http.StatusBadGateway
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		code = http.StatusGatewayTimeout
+	}
+	c.JSON(code, H{
+		"error":   http.StatusText(code),
+		"message": "upstream unavailable",
+	})
+	c.Abort()
+}
+
+// Mount routes all requests under relativePath to handler for any method,
+// with relativePath stripped from the request path, e.g. to front another
+// service with Proxy:
+//
+//	r.Mount("/legacy", goTap.Proxy("http://legacy-pos:8080", goTap.ProxyConfig{}))
+//
+// GET /legacy/receipts/7 then reaches the handler as /receipts/7.
+func (group *RouterGroup) Mount(relativePath string, handler HandlerFunc) IRoutes {
+	prefix := strings.TrimSuffix(group.calculateAbsolutePath(relativePath), "/")
+	mounted := func(c *Context) {
+		original := c.Request
+		req := *original
+		u := *original.URL
+		u.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(u.Path, prefix), "/")
+		u.RawPath = ""
+		req.URL = &u
+		c.Request = &req
+		defer func() { c.Request = original }()
+		handler(c)
+	}
+
+	relativePath = strings.TrimSuffix(relativePath, "/")
+	group.Any(relativePath, mounted)
+	return group.Any(relativePath+"/*mountpath", mounted)
+}
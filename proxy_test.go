@@ -0,0 +1,121 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestProxyMount(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Upstream-Path", r.URL.RequestURI())
+		w.Header().Set("X-Upstream-Cookie", r.Header.Get("Cookie"))
+		w.Header().Set("X-Upstream-Forwarded", r.Header.Get("X-Forwarded-For"))
+		w.Header().Set("X-Upstream-Gateway", r.Header.Get("X-Gateway"))
+		w.Write(body)
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Mount("/legacy", Proxy(upstream.URL+"/v1", ProxyConfig{
+		RewritePath:  func(p string) string { return "/api" + p },
+		StripHeaders: []string{"Cookie"},
+		SetHeaders:   map[string]string{"X-Gateway": "gotap"},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Set("X-Proxied", "true")
+			return nil
+		},
+	}))
+
+	req := httptest.NewRequest("POST", "/legacy/receipts/7?copy=1", strings.NewReader("receipt"))
+	req.Header.Set("Cookie", "session_id=secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	h := w.Header()
+	if w.Code != 200 || w.Body.String() != "receipt" {
+		t.Fatalf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if h.Get("X-Upstream-Path") != "/v1/api/receipts/7?copy=1" {
+		t.Errorf("Unexpected upstream path %q", h.Get("X-Upstream-Path"))
+	}
+	if h.Get("X-Upstream-Cookie") != "" || h.Get("X-Upstream-Gateway") != "gotap" ||
+		h.Get("X-Upstream-Forwarded") != "192.0.2.1" || h.Get("X-Proxied") != "true" {
+		t.Errorf("Unexpected header forwarding %v", h)
+	}
+}
+
+func TestProxyUpstreamDown(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	r := New()
+	r.GET("/legacy", Proxy(upstream.URL, ProxyConfig{DialTimeout: time.Second}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/legacy", nil))
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "upstream unavailable") {
+		t.Errorf("Expected 502, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProxyStreamingAndWebSocket(t *testing.T) {
+	release := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: first\n\n"))
+			w.(http.Flusher).Flush()
+			<-release
+			w.Write([]byte("data: second\n\n"))
+		case "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			mt, msg, _ := conn.ReadMessage()
+			conn.WriteMessage(mt, append([]byte("echo: "), msg...))
+		}
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Mount("/upstream", Proxy(upstream.URL, ProxyConfig{}))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	// The first event arrives before the upstream finishes
+	resp, err := http.Get(srv.URL + "/upstream/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	close(release)
+	resp.Body.Close()
+	if line != "data: first\n" {
+		t.Errorf("Expected streamed event, got %q", line)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/upstream/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "echo: hello" {
+		t.Errorf("Unexpected WebSocket reply %q %v", msg, err)
+	}
+}
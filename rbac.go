@@ -0,0 +1,367 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"gorm.io/gorm"
+)
+
+// maxPolicyDecisions bounds the decision cache; it is cleared when full
+const maxPolicyDecisions = 10000
+
+// Authorizer decides whether a role may perform an action on a resource.
+// Policy implements it; wrap other engines with AuthorizerFunc, e.g.
+// Casbin:
+//
+//	r.Authorizer = goTap.AuthorizerFunc(func(role, resource, action string) (bool, error) {
+//	    return enforcer.Enforce(role, resource, action)
+//	})
+type Authorizer interface {
+	Authorize(role, resource, action string) (bool, error)
+}
+
+// AuthorizerFunc adapts a function to an Authorizer
+type AuthorizerFunc func(role, resource, action string) (bool, error)
+
+// Authorize calls f
+func (f AuthorizerFunc) Authorize(role, resource, action string) (bool, error) {
+	return f(role, resource, action)
+}
+
+// PolicyRole lists the permissions of a role. A permission is
+// "resource:action"; "*" matches any resource or action and a trailing
+// "*" a prefix, e.g. "products:*", "reports.*:read" or "*".
+type PolicyRole struct {
+	// Permissions granted to the role
+	Permissions []string `yaml:"permissions" json:"permissions"`
+
+	// Inherits the permissions of other roles
+	Inherits []string `yaml:"inherits" json:"inherits,omitempty"`
+}
+
+// PolicyRule is a row of the GORM policy table, see Policy.LoadGorm
+type PolicyRule struct {
+	ID       uint   `gorm:"primarykey" json:"id"`
+	Role     string `gorm:"index;not null" json:"role"`
+	Resource string `json:"resource,omitempty"`
+	Action   string `json:"action,omitempty"`
+
+	// Inherits makes Role inherit another role instead of granting
+	// Resource and Action
+	Inherits string `json:"inherits,omitempty"`
+}
+
+// Policy is a role-based access control policy with role inheritance and
+// a decision cache:
+//
+//	policy := goTap.NewPolicy().
+//	    Allow("cashier", "orders", "create", "read").
+//	    Allow("cashier", "products", "read").
+//	    Allow("manager", "products", "*").
+//	    Inherit("manager", "cashier")
+//
+//	r.Authorizer = policy
+//	r.DELETE("/products/:id", goTap.Authorize("products", "delete"), deleteProduct)
+type Policy struct {
+	mu        sync.RWMutex
+	roles     map[string]*PolicyRole
+	decisions map[string]bool
+}
+
+// NewPolicy creates an empty policy, denying everything
+func NewPolicy() *Policy {
+	return &Policy{
+		roles:     make(map[string]*PolicyRole),
+		decisions: make(map[string]bool),
+	}
+}
+
+func (p *Policy) role(name string) *PolicyRole {
+	role, ok := p.roles[name]
+	if !ok {
+		role = &PolicyRole{}
+		p.roles[name] = role
+	}
+	return role
+}
+
+// Allow grants role the actions on resource
+func (p *Policy) Allow(role, resource string, actions ...string) *Policy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.role(role)
+	for _, action := range actions {
+		r.Permissions = append(r.Permissions, resource+":"+action)
+	}
+	clear(p.decisions)
+	return p
+}
+
+// Inherit grants role the permissions of parents
+func (p *Policy) Inherit(role string, parents ...string) *Policy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.role(role)
+	r.Inherits = append(r.Inherits, parents...)
+	clear(p.decisions)
+	return p
+}
+
+// Load replaces the policy's roles
+func (p *Policy) Load(roles map[string]PolicyRole) error {
+	loaded := make(map[string]*PolicyRole, len(roles))
+	for name, role := range roles {
+		for _, perm := range role.Permissions {
+			if perm != "*" && !strings.Contains(perm, ":") {
+				return fmt.Errorf("role %s: permission %q is not resource:action", name, perm)
+			}
+		}
+		role := role
+		loaded[name] = &role
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles = loaded
+	clear(p.decisions)
+	return nil
+}
+
+// LoadYAML replaces the policy's roles with a YAML definition:
+//
+//	roles:
+//	  cashier:
+//	    permissions: ["orders:create", "orders:read", "products:read"]
+//	  manager:
+//	    inherits: [cashier]
+//	    permissions: ["products:*", "reports.*:read"]
+//	  admin:
+//	    permissions: ["*"]
+func (p *Policy) LoadYAML(data []byte) error {
+	var doc struct {
+		Roles map[string]PolicyRole `yaml:"roles"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return p.Load(doc.Roles)
+}
+
+// LoadGorm replaces the policy's roles with the PolicyRule rows of db.
+// Migrate the table with AutoMigrate(db, &goTap.PolicyRule{}) and call
+// LoadGorm again after changing rules.
+func (p *Policy) LoadGorm(db *gorm.DB) error {
+	var rules []PolicyRule
+	if err := db.Order("id").Find(&rules).Error; err != nil {
+		return err
+	}
+	roles := make(map[string]PolicyRole)
+	for _, rule := range rules {
+		role := roles[rule.Role]
+		if rule.Inherits != "" {
+			role.Inherits = append(role.Inherits, rule.Inherits)
+		} else {
+			role.Permissions = append(role.Permissions, rule.Resource+":"+rule.Action)
+		}
+		roles[rule.Role] = role
+	}
+	return p.Load(roles)
+}
+
+// Can reports whether role may perform action on resource. Decisions are
+// cached until the policy changes.
+func (p *Policy) Can(role, resource, action string) bool {
+	key := role + "\x00" + resource + "\x00" + action
+	p.mu.RLock()
+	decision, ok := p.decisions[key]
+	p.mu.RUnlock()
+	if ok {
+		return decision
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	decision = p.can(role, resource, action, make(map[string]bool))
+	if len(p.decisions) >= maxPolicyDecisions {
+		clear(p.decisions)
+	}
+	p.decisions[key] = decision
+	return decision
+}
+
+func (p *Policy) can(role, resource, action string, visited map[string]bool) bool {
+	if visited[role] {
+		return false
+	}
+	visited[role] = true
+	r, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	for _, perm := range r.Permissions {
+		if perm == "*" {
+			return true
+		}
+		i := strings.LastIndex(perm, ":")
+		if policyMatch(perm[:i], resource) && policyMatch(perm[i+1:], action) {
+			return true
+		}
+	}
+	for _, parent := range r.Inherits {
+		if p.can(parent, resource, action, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize implements Authorizer
+func (p *Policy) Authorize(role, resource, action string) (bool, error) {
+	return p.Can(role, resource, action), nil
+}
+
+// policyMatch matches value against a pattern with an optional trailing *
+func policyMatch(pattern, value string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return pattern == value
+}
+
+// AuthorizeConfig holds configuration for the Authorize middleware
+type AuthorizeConfig struct {
+	// Resource and Action to check, e.g. "products" and "delete"
+	Resource string
+	Action   string
+
+	// Authorizer decides the check
+	// Default: Engine.Authorizer
+	Authorizer Authorizer
+
+	// RolesFunc returns the roles of the request; any of them may grant
+	// the action
+	// Default: the JWT role and the "roles" custom claim
+	RolesFunc func(c *Context) []string
+}
+
+// Authorize returns a middleware rejecting requests whose roles may not
+// perform action on resource with 403 Forbidden, decided by
+// Engine.Authorizer:
+//
+//	r.Authorizer = policy
+//	api := r.Group("/api", goTap.JWTAuth(secret))
+//	api.DELETE("/products/:id", goTap.Authorize("products", "delete"), deleteProduct)
+func Authorize(resource, action string) HandlerFunc {
+	return AuthorizeWithConfig(AuthorizeConfig{Resource: resource, Action: action})
+}
+
+// AuthorizeWithConfig returns an Authorize middleware with config
+func AuthorizeWithConfig(config AuthorizeConfig) HandlerFunc {
+	if config.RolesFunc == nil {
+		config.RolesFunc = jwtRoles
+	}
+
+	return func(c *Context) {
+		roles := config.RolesFunc(c)
+		if len(roles) == 0 {
+			c.JSON(http.StatusUnauthorized, H{
+				"error":   "Unauthorized",
+				"message": "No role found",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := authorizeRoles(c, config.Authorizer, roles, config.Resource, config.Action)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, H{
+				"error":   "Internal Server Error",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, H{
+				"error":   "Forbidden",
+				"message": fmt.Sprintf("Not allowed to %s %s", config.Action, config.Resource),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Can reports whether the request's JWT roles may perform action on
+// resource according to Engine.Authorizer, e.g. to hide fields or buttons
+func (c *Context) Can(resource, action string) bool {
+	allowed, err := authorizeRoles(c, nil, jwtRoles(c), resource, action)
+	return err == nil && allowed
+}
+
+// ResourcePolicy returns a ResourceConfig.Authorize function checking the
+// Resource actions against Engine.Authorizer for resource:
+//
+//	goTap.Resource[Product](r.Group("/products"), goTap.ResourceConfig{
+//	    Authorize: goTap.ResourcePolicy("products"),
+//	})
+func ResourcePolicy(resource string) func(c *Context, action ResourceAction, record any) error {
+	return func(c *Context, action ResourceAction, record any) error {
+		if !c.Can(resource, string(action)) {
+			return fmt.Errorf("not allowed to %s %s", action, resource)
+		}
+		return nil
+	}
+}
+
+// authorizeRoles reports whether any of roles may perform action on
+// resource, using the engine's Authorizer if authorizer is nil
+func authorizeRoles(c *Context, authorizer Authorizer, roles []string, resource, action string) (bool, error) {
+	if authorizer == nil && c.engine != nil {
+		authorizer = c.engine.Authorizer
+	}
+	if authorizer == nil {
+		return false, fmt.Errorf("authorizer not configured")
+	}
+	for _, role := range roles {
+		allowed, err := authorizer.Authorize(role, resource, action)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// jwtRoles returns the JWT role and the "roles" custom claim
+func jwtRoles(c *Context) []string {
+	claims, ok := GetJWTClaims(c)
+	if !ok {
+		return nil
+	}
+	var roles []string
+	if claims.Role != "" {
+		roles = append(roles, claims.Role)
+	}
+	switch custom := claims.Custom["roles"].(type) {
+	case []string:
+		roles = append(roles, custom...)
+	case []interface{}:
+		for _, role := range custom {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return roles
+}
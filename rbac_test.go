@@ -0,0 +1,157 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rbacPolicyYAML = `
+roles:
+  cashier:
+    permissions: ["orders:create", "orders:read", "products:read"]
+  manager:
+    inherits: [cashier]
+    permissions: ["products:*", "reports.*:read"]
+  admin:
+    permissions: ["*"]
+  loop:
+    inherits: [loop2]
+  loop2:
+    inherits: [loop]
+`
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy()
+	if err := policy.LoadYAML([]byte(rbacPolicyYAML)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role, resource, action string
+		want                   bool
+	}{
+		{"cashier", "orders", "create", true},
+		{"cashier", "products", "read", true},
+		{"cashier", "products", "delete", false},
+		{"manager", "products", "delete", true},
+		{"manager", "orders", "read", true},
+		{"manager", "reports.sales", "read", true},
+		{"manager", "reports.sales", "export", false},
+		{"manager", "orders", "refund", false},
+		{"admin", "anything", "refund", true},
+		{"loop", "orders", "read", false},
+		{"unknown", "orders", "read", false},
+	}
+	for _, tt := range tests {
+		if got := policy.Can(tt.role, tt.resource, tt.action); got != tt.want {
+			t.Errorf("Can(%s, %s, %s) = %v, want %v", tt.role, tt.resource, tt.action, got, tt.want)
+		}
+	}
+
+	// Changes clear cached decisions
+	policy.Allow("cashier", "orders", "refund")
+	if !policy.Can("manager", "orders", "refund") {
+		t.Error("Expected the new permission to be inherited")
+	}
+
+	if err := NewPolicy().LoadYAML([]byte("roles:\n  x:\n    permissions: [products]\n")); err == nil {
+		t.Error("Expected a permission without action to be rejected")
+	}
+}
+
+func TestPolicyLoadGorm(t *testing.T) {
+	db := setupResourceDB(t)
+	if err := AutoMigrate(db, &PolicyRule{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&[]PolicyRule{
+		{Role: "cashier", Resource: "orders", Action: "create"},
+		{Role: "manager", Inherits: "cashier"},
+		{Role: "manager", Resource: "products", Action: "*"},
+	})
+
+	policy := NewPolicy()
+	if err := policy.LoadGorm(db); err != nil {
+		t.Fatal(err)
+	}
+	if !policy.Can("manager", "orders", "create") || !policy.Can("manager", "products", "delete") || policy.Can("cashier", "products", "delete") {
+		t.Error("Unexpected decisions from the database policy")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	const secret = "rbac-secret"
+	r := New()
+	r.Authorizer = NewPolicy().
+		Allow("cashier", "products", "read").
+		Allow("manager", "products", "*")
+	api := r.Group("/api", JWTAuth(secret))
+	api.GET("/products", Authorize("products", "read"), func(c *Context) {
+		c.JSON(http.StatusOK, H{"can_delete": c.Can("products", "delete")})
+	})
+	api.DELETE("/products/:id", Authorize("products", "delete"), func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func(method, path string, claims JWTClaims) *httptest.ResponseRecorder {
+		claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		token, _ := GenerateJWT(secret, claims)
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	cashier := JWTClaims{UserID: "1", Role: "cashier"}
+	if w := request("GET", "/api/products", cashier); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"can_delete":false}` {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := request("DELETE", "/api/products/1", cashier); w.Code != http.StatusForbidden {
+		t.Errorf("Expected the cashier to be forbidden, got %d", w.Code)
+	}
+	// Any of the roles may grant the action
+	both := JWTClaims{UserID: "2", Role: "cashier", Custom: map[string]interface{}{"roles": []string{"manager"}}}
+	if w := request("DELETE", "/api/products/1", both); w.Code != http.StatusNoContent {
+		t.Errorf("Expected the manager role to grant the delete, got %d", w.Code)
+	}
+	if w := request("GET", "/api/products", JWTClaims{UserID: "3"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a request without role to be rejected, got %d", w.Code)
+	}
+
+	// Other engines plug in through AuthorizerFunc
+	r.Authorizer = AuthorizerFunc(func(role, resource, action string) (bool, error) {
+		return role == "cashier", nil
+	})
+	if w := request("DELETE", "/api/products/1", cashier); w.Code != http.StatusNoContent {
+		t.Errorf("Expected the custom authorizer to allow, got %d", w.Code)
+	}
+}
+
+func TestResourcePolicy(t *testing.T) {
+	db := setupResourceDB(t)
+	r := New()
+	r.Authorizer = NewPolicy().Allow("viewer", "items", "list", "get")
+	r.Use(GormInject(db), func(c *Context) {
+		c.Set("jwt_claims", &JWTClaims{Role: c.GetHeader("X-Role")})
+	})
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{Authorize: ResourcePolicy("items")})
+
+	if w, _ := resourceRequest(r, "GET", "/items", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a request without role to be forbidden, got %d", w.Code)
+	}
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("X-Role", "viewer")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the viewer to list items, got %d", w.Code)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"io/fs"
+	"text/template"
+
+	"github.com/jaswant99k/gotap/receipts"
+)
+
+// MIMEESCPOS is the content type of ESC/POS printer commands
+const MIMEESCPOS = "application/vnd.escpos"
+
+// LoadReceiptGlob loads receipt templates from a glob pattern, with the
+// functions of Engine.FuncMap, e.g. LocaleFuncMap. See the receipts package
+// for the markup:
+//
+//	r.SetFuncMap(goTap.LocaleFuncMap())
+//	r.LoadReceiptGlob("templates/receipts/*.tmpl")
+//
+//	c.PDF(200, "receipt.tmpl", order)    // e-mailed or downloaded receipt
+//	c.ESCPOS(200, "receipt.tmpl", order) // fetched by the till's printer
+func (engine *Engine) LoadReceiptGlob(pattern string) {
+	t, err := engine.newReceiptTemplates().ParseGlob(pattern)
+	if err != nil {
+		panic(err)
+	}
+	engine.receiptTemplates = t
+}
+
+// LoadReceiptFS loads receipt templates matching patterns from fsys, e.g.
+// an embed.FS
+func (engine *Engine) LoadReceiptFS(fsys fs.FS, patterns ...string) {
+	t, err := engine.newReceiptTemplates().ParseFS(fsys, patterns...)
+	if err != nil {
+		panic(err)
+	}
+	engine.receiptTemplates = t
+}
+
+// SetReceiptTemplates sets custom receipt templates, e.g. with another
+// line width
+func (engine *Engine) SetReceiptTemplates(t *receipts.Templates) {
+	engine.receiptTemplates = t
+}
+
+func (engine *Engine) newReceiptTemplates() *receipts.Templates {
+	return receipts.New(template.FuncMap(engine.FuncMap))
+}
+
+// receipt renders the named receipt template with the AddHTMLData values
+func (c *Context) receipt(name string, data any) *receipts.Document {
+	if c.engine == nil || c.engine.receiptTemplates == nil {
+		panic("Receipt templates not loaded. Use LoadReceiptGlob() or LoadReceiptFS()")
+	}
+	doc, err := c.engine.receiptTemplates.Execute(name, c.htmlData(data))
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+// PDF renders the named receipt template as a PDF on 80 mm wide paper
+func (c *Context) PDF(code int, name string, data any) {
+	var buf bytes.Buffer
+	if err := c.receipt(name, data).PDF(&buf, receipts.PDFOptions{}); err != nil {
+		panic(err)
+	}
+	c.Data(code, "application/pdf", buf.Bytes())
+}
+
+// ESCPOS renders the named receipt template as ESC/POS commands for
+// thermal receipt printers
+func (c *Context) ESCPOS(code int, name string, data any) {
+	var buf bytes.Buffer
+	if err := c.receipt(name, data).ESCPOS(&buf, receipts.ESCPOSOptions{}); err != nil {
+		panic(err)
+	}
+	c.Data(code, MIMEESCPOS, buf.Bytes())
+}
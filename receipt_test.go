@@ -0,0 +1,43 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReceipts(t *testing.T) {
+	r := New()
+	r.SetFuncMap(LocaleFuncMap())
+	r.AddHTMLData(LocaleHTMLData)
+	r.LoadReceiptFS(fstest.MapFS{
+		"receipts/sale.tmpl": {Data: []byte("[center][bold]{{.store}}\nTotal|{{formatMoney .locale .total}}\n[cut]\n")},
+	}, "receipts/*.tmpl")
+	r.GET("/sale.pdf", func(c *Context) {
+		c.PDF(http.StatusOK, "sale.tmpl", H{"store": "VervePOS", "total": NewMoney(975, "USD")})
+	})
+	r.GET("/sale.escpos", func(c *Context) {
+		c.ESCPOS(http.StatusOK, "sale.tmpl", H{"store": "VervePOS", "total": NewMoney(975, "USD")})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/sale.pdf", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("Unexpected PDF response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/sale.escpos", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != MIMEESCPOS {
+		t.Fatalf("Unexpected ESC/POS response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("Total                                $9.75\n")) {
+		t.Errorf("Expected the formatted total, got %q", w.Body.Bytes())
+	}
+}
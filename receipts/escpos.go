@@ -0,0 +1,142 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package receipts
+
+import (
+	"bytes"
+	"io"
+)
+
+// ESC/POS commands
+var (
+	escInit        = []byte{0x1b, '@'}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escLarge       = []byte{0x1d, '!', 0x11}
+	escNormal      = []byte{0x1d, '!', 0x00}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escAlignRight  = []byte{0x1b, 'a', 2}
+	escFeedCut     = []byte{0x1d, 'V', 66, 3}
+)
+
+// ESCPOSOptions holds options for rendering ESC/POS commands
+type ESCPOSOptions struct {
+	// Encode converts text to the printer's code page
+	// Default: ASCII, other characters print as "?"
+	Encode func(s string) []byte
+
+	// QRSize is the module size of QR codes in dots, 1 to 16
+	// Default: 6
+	QRSize int
+
+	// BarcodeHeight is the height of barcodes in dots
+	// Default: 80
+	BarcodeHeight int
+}
+
+// ESCPOS renders the document as ESC/POS commands for thermal receipt
+// printers. QR codes and barcodes use the printer's native commands.
+func (d *Document) ESCPOS(w io.Writer, opts ESCPOSOptions) error {
+	if opts.Encode == nil {
+		opts.Encode = encodeASCII
+	}
+	if opts.QRSize <= 0 || opts.QRSize > 16 {
+		opts.QRSize = 6
+	}
+	if opts.BarcodeHeight <= 0 || opts.BarcodeHeight > 255 {
+		opts.BarcodeHeight = 80
+	}
+	width := d.Width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	for _, line := range d.Lines {
+		switch line.Kind {
+		case QRCode:
+			buf.Write(alignCommand(line.Align))
+			writeESCPOSQR(&buf, line.Text, opts.QRSize)
+			buf.Write(escAlignLeft)
+		case Barcode:
+			buf.Write(alignCommand(line.Align))
+			writeESCPOSBarcode(&buf, line.Text, opts.BarcodeHeight)
+			buf.Write(escAlignLeft)
+		case Cut:
+			buf.Write(escFeedCut)
+		default:
+			if line.Bold {
+				buf.Write(escBoldOn)
+			}
+			if line.Large {
+				buf.Write(escLarge)
+			}
+			for _, row := range line.Rows(width) {
+				buf.Write(opts.Encode(row))
+				buf.WriteByte('\n')
+			}
+			if line.Large {
+				buf.Write(escNormal)
+			}
+			if line.Bold {
+				buf.Write(escBoldOff)
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func alignCommand(align Align) []byte {
+	switch align {
+	case AlignCenter:
+		return escAlignCenter
+	case AlignRight:
+		return escAlignRight
+	}
+	return escAlignLeft
+}
+
+// writeESCPOSQR stores and prints a QR code (GS ( k, model 2, error
+// correction M)
+func writeESCPOSQR(buf *bytes.Buffer, data string, size int) {
+	buf.Write([]byte{0x1d, '(', 'k', 4, 0, 49, 65, 50, 0})
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 67, byte(size)})
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 69, 49})
+	n := len(data) + 3
+	buf.Write([]byte{0x1d, '(', 'k', byte(n), byte(n >> 8), 49, 80, 48})
+	buf.WriteString(data)
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 81, 48})
+	buf.WriteByte('\n')
+}
+
+// writeESCPOSBarcode prints a Code 128 barcode (GS k 73) with its text
+// below
+func writeESCPOSBarcode(buf *bytes.Buffer, data string, height int) {
+	buf.Write([]byte{0x1d, 'h', byte(height)})
+	buf.Write([]byte{0x1d, 'H', 2})
+	// "{B" selects code set B
+	code := encodeASCII("{B" + data)
+	if len(code) > 255 {
+		code = code[:255]
+	}
+	buf.Write([]byte{0x1d, 'k', 73, byte(len(code))})
+	buf.Write(code)
+	buf.WriteByte('\n')
+}
+
+func encodeASCII(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x80 {
+			out = append(out, byte(r))
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package receipts
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+)
+
+const (
+	// monoAdvance is the advance of a Go Mono character in em
+	monoAdvance = 0.6
+
+	// pointsPerMM converts millimeters to PDF points
+	pointsPerMM = 72 / 25.4
+)
+
+// PDFOptions holds options for rendering PDFs
+type PDFOptions struct {
+	// PaperWidth in millimeters; the page is as long as the receipt
+	// Default: 80
+	PaperWidth float64
+
+	// Margin around the receipt in millimeters
+	// Default: 4
+	Margin float64
+
+	// QRSize is the width of QR codes in millimeters
+	// Default: 30
+	QRSize float64
+
+	// BarcodeHeight is the height of barcodes in millimeters
+	// Default: 12
+	BarcodeHeight float64
+}
+
+// PDF renders the document as a PDF the width of receipt paper, with the
+// Go Mono font embedded so it looks the same everywhere. The font size is
+// chosen to fit Width characters per line.
+func (d *Document) PDF(w io.Writer, opts PDFOptions) error {
+	if opts.PaperWidth <= 0 {
+		opts.PaperWidth = 80
+	}
+	if opts.Margin <= 0 {
+		opts.Margin = 4
+	}
+	if opts.QRSize <= 0 {
+		opts.QRSize = 30
+	}
+	if opts.BarcodeHeight <= 0 {
+		opts.BarcodeHeight = 12
+	}
+	width := d.Width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	content := opts.PaperWidth - 2*opts.Margin
+	if content <= 0 {
+		return fmt.Errorf("receipts: margins exceed the paper width")
+	}
+
+	r := &pdfRenderer{
+		lines:    d.Lines,
+		opts:     opts,
+		width:    width,
+		content:  content,
+		fontSize: content / float64(width) / monoAdvance,
+	}
+
+	// Measure first, as the page is as long as the receipt
+	height := r.render(nil) + 2*opts.Margin
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: opts.PaperWidth, Ht: height},
+	})
+	pdf.SetMargins(opts.Margin, opts.Margin, opts.Margin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes("gomono", "", gomono.TTF)
+	pdf.AddUTF8FontFromBytes("gomono", "B", gomonobold.TTF)
+	pdf.AddPage()
+	r.render(pdf)
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+type pdfRenderer struct {
+	lines    []Line
+	opts     PDFOptions
+	width    int
+	content  float64
+	fontSize float64 // in millimeters
+}
+
+// render draws the document on pdf, or only measures it if pdf is nil, and
+// returns the height of the content
+func (r *pdfRenderer) render(pdf *gofpdf.Fpdf) float64 {
+	y := r.opts.Margin
+	for _, line := range r.lines {
+		switch line.Kind {
+		case QRCode:
+			code, err := qr.Encode(line.Text, qr.M, qr.Auto)
+			if err != nil {
+				y += r.text(pdf, Line{Text: "[invalid QR code]", Align: line.Align}, y)
+				continue
+			}
+			size := min(r.opts.QRSize, r.content)
+			if pdf != nil {
+				r.drawModules(pdf, code, r.x(line.Align, size), y, size, size)
+			}
+			y += size + r.fontSize
+		case Barcode:
+			code, err := code128.Encode(line.Text)
+			if err != nil {
+				y += r.text(pdf, Line{Text: "[invalid barcode]", Align: line.Align}, y)
+				continue
+			}
+			modules := float64(code.Bounds().Dx())
+			barWidth := min(modules*0.33, r.content)
+			if pdf != nil {
+				r.drawModules(pdf, code, r.x(line.Align, barWidth), y, barWidth, r.opts.BarcodeHeight)
+			}
+			y += r.opts.BarcodeHeight
+			y += r.text(pdf, Line{Text: line.Text, Align: AlignCenter}, y)
+		case Cut:
+			y += 2 * r.fontSize
+		default:
+			y += r.text(pdf, line, y)
+		}
+	}
+	return y - r.opts.Margin
+}
+
+// text draws the rows of a text or rule line at y and returns their height
+func (r *pdfRenderer) text(pdf *gofpdf.Fpdf, line Line, y float64) float64 {
+	size := r.fontSize
+	if line.Large {
+		size *= 2
+	}
+	rowHeight := size * 1.25
+	rows := line.Rows(r.width)
+	if pdf != nil {
+		style := ""
+		if line.Bold {
+			style = "B"
+		}
+		pdf.SetFont("gomono", style, size*pointsPerMM)
+		for i, row := range rows {
+			// Text is positioned by its baseline
+			pdf.Text(r.opts.Margin, y+float64(i)*rowHeight+size, row)
+		}
+	}
+	return float64(len(rows)) * rowHeight
+}
+
+// x returns the left edge of an item of width aligned in the content
+func (r *pdfRenderer) x(align Align, width float64) float64 {
+	switch align {
+	case AlignCenter:
+		return r.opts.Margin + (r.content-width)/2
+	case AlignRight:
+		return r.opts.Margin + r.content - width
+	}
+	return r.opts.Margin
+}
+
+// drawModules draws the dark modules of a QR code or the bars of a barcode
+// into the w by h rectangle at x, y
+func (r *pdfRenderer) drawModules(pdf *gofpdf.Fpdf, code barcode.Barcode, x, y, w, h float64) {
+	bounds := code.Bounds()
+	cols, rows := bounds.Dx(), bounds.Dy()
+	mw, mh := w/float64(cols), h/float64(rows)
+	pdf.SetFillColor(0, 0, 0)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if c, _, _, _ := code.At(bounds.Min.X+col, bounds.Min.Y+row).RGBA(); c != 0 {
+				continue
+			}
+			// Runs of dark modules are drawn as one rectangle
+			run := 1
+			for col+run < cols {
+				if c, _, _, _ := code.At(bounds.Min.X+col+run, bounds.Min.Y+row).RGBA(); c != 0 {
+					break
+				}
+				run++
+			}
+			pdf.Rect(x+float64(col)*mw, y+float64(row)*mh, float64(run)*mw, mh, "F")
+			col += run - 1
+		}
+	}
+}
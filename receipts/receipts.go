@@ -0,0 +1,290 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package receipts renders templated receipts to PDF and ESC/POS printer
+// commands.
+//
+// Receipt templates are text/template files producing a line based markup;
+// each line may start with formatting tags:
+//
+//	[center][large][bold]VervePOS
+//	[center]{{.Store.Address}}
+//	---
+//	{{range .Items}}{{.Qty}} x {{.Name}}|{{.Total}}
+//	{{end}}===
+//	[bold]Total|{{.Total}}
+//
+//	[center][qr]{{.ReceiptURL}}
+//	[center][barcode]{{.Number}}
+//	[cut]
+//
+// A "|" splits a line into a left and a right aligned column ("\|" is a
+// literal "|"). "---" and "===" are rules across the paper. [qr] prints a QR
+// code and [barcode] a Code 128 barcode of the rest of the line; [cut] cuts
+// the paper. Text wraps at Document.Width characters.
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// DefaultWidth is the number of characters per line of 80 mm paper
+const DefaultWidth = 42
+
+// Align is the horizontal alignment of a line
+type Align int
+
+// Alignments
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Kind is what a line prints
+type Kind int
+
+// Line kinds
+const (
+	// Text prints Text, and Right right aligned if set
+	Text Kind = iota
+
+	// Rule prints a line of Text's first character across the paper
+	Rule
+
+	// QRCode prints Text as a QR code
+	QRCode
+
+	// Barcode prints Text as a Code 128 barcode
+	Barcode
+
+	// Cut cuts the paper
+	Cut
+)
+
+// Line is a line of a receipt
+type Line struct {
+	Kind  Kind
+	Text  string
+	Right string
+	Align Align
+	Bold  bool
+
+	// Large prints text at double width and height
+	Large bool
+}
+
+// Document is a parsed receipt
+type Document struct {
+	// Width is the number of characters per line
+	// Default: DefaultWidth
+	Width int
+
+	Lines []Line
+}
+
+// Parse parses receipt markup, see the package documentation
+func Parse(markup string) *Document {
+	doc := &Document{Width: DefaultWidth}
+	markup = strings.TrimSuffix(strings.ReplaceAll(markup, "\r\n", "\n"), "\n")
+	for _, raw := range strings.Split(markup, "\n") {
+		doc.Lines = append(doc.Lines, parseLine(raw))
+	}
+	return doc
+}
+
+func parseLine(raw string) Line {
+	var line Line
+	for strings.HasPrefix(raw, "[") {
+		end := strings.Index(raw, "]")
+		if end < 0 {
+			break
+		}
+		switch raw[1:end] {
+		case "center":
+			line.Align = AlignCenter
+		case "right":
+			line.Align = AlignRight
+		case "bold":
+			line.Bold = true
+		case "large":
+			line.Large = true
+		case "qr":
+			line.Kind = QRCode
+		case "barcode":
+			line.Kind = Barcode
+		case "cut":
+			line.Kind = Cut
+		default:
+			// Not a tag, e.g. "[x] done"
+			end = -1
+		}
+		if end < 0 {
+			break
+		}
+		raw = raw[end+1:]
+	}
+	if line.Kind != Text {
+		line.Text = strings.TrimSpace(raw)
+		return line
+	}
+
+	if trimmed := strings.TrimSpace(raw); trimmed == "---" || trimmed == "===" {
+		line.Kind = Rule
+		line.Text = trimmed[:1]
+		return line
+	}
+	if i := lastUnescapedPipe(raw); i >= 0 {
+		line.Text, line.Right = strings.TrimSpace(raw[:i]), strings.TrimSpace(raw[i+1:])
+	} else {
+		line.Text = raw
+	}
+	line.Text = strings.ReplaceAll(line.Text, `\|`, "|")
+	line.Right = strings.ReplaceAll(line.Right, `\|`, "|")
+	return line
+}
+
+func lastUnescapedPipe(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '|' && (i == 0 || s[i-1] != '\\') {
+			return i
+		}
+	}
+	return -1
+}
+
+// Rows lays out a text or rule line into rows of at most width characters,
+// padded to align them. Large lines get width/2 characters per row.
+func (l Line) Rows(width int) []string {
+	if l.Large {
+		width /= 2
+	}
+	if width <= 0 {
+		width = 1
+	}
+	switch l.Kind {
+	case Rule:
+		return []string{strings.Repeat(l.Text, width)}
+	case Text:
+	default:
+		return nil
+	}
+
+	rows := wrap(l.Text, width)
+	if l.Right != "" {
+		// The right column goes at the end of the last row, or on a row of
+		// its own if it doesn't fit
+		last := rows[len(rows)-1]
+		gap := width - runeLen(last) - runeLen(l.Right)
+		if gap >= 1 {
+			rows[len(rows)-1] = last + strings.Repeat(" ", gap) + l.Right
+		} else {
+			rows = append(rows, padLeft(l.Right, width))
+		}
+		return rows
+	}
+	for i, row := range rows {
+		switch l.Align {
+		case AlignCenter:
+			pad := (width - runeLen(row)) / 2
+			rows[i] = strings.Repeat(" ", pad) + row
+		case AlignRight:
+			rows[i] = padLeft(row, width)
+		}
+	}
+	return rows
+}
+
+// wrap splits text into rows of at most width characters at spaces,
+// breaking longer words
+func wrap(text string, width int) []string {
+	var rows []string
+	var row string
+	for _, word := range strings.Fields(text) {
+		for runeLen(word) > width {
+			if row != "" {
+				rows = append(rows, row)
+				row = ""
+			}
+			head := string([]rune(word)[:width])
+			rows = append(rows, head)
+			word = word[len(head):]
+		}
+		switch {
+		case row == "":
+			row = word
+		case runeLen(row)+1+runeLen(word) <= width:
+			row += " " + word
+		default:
+			rows = append(rows, row)
+			row = word
+		}
+	}
+	return append(rows, row)
+}
+
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(0, width-runeLen(s))) + s
+}
+
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// Templates is a set of receipt templates
+type Templates struct {
+	templates *template.Template
+
+	// Width is the number of characters per line of rendered documents
+	// Default: DefaultWidth
+	Width int
+}
+
+// New creates an empty template set with funcs available to templates
+func New(funcs template.FuncMap) *Templates {
+	return &Templates{templates: template.New("").Funcs(funcs), Width: DefaultWidth}
+}
+
+// ParseGlob parses the templates matching pattern, named by their file name
+func (t *Templates) ParseGlob(pattern string) (*Templates, error) {
+	if _, err := t.templates.ParseGlob(pattern); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ParseFS parses the templates of fsys matching patterns, e.g. from an
+// embed.FS
+func (t *Templates) ParseFS(fsys fs.FS, patterns ...string) (*Templates, error) {
+	if _, err := t.templates.ParseFS(fsys, patterns...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Parse adds a template named name
+func (t *Templates) Parse(name, text string) (*Templates, error) {
+	if _, err := t.templates.New(name).Parse(text); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Execute renders the template named name with data into a Document
+func (t *Templates) Execute(name string, data any) (*Document, error) {
+	var buf bytes.Buffer
+	if err := t.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("receipts: %w", err)
+	}
+	doc := Parse(buf.String())
+	if t.Width > 0 {
+		doc.Width = t.Width
+	}
+	return doc, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package receipts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+const receiptTemplate = `[center][large][bold]{{.Store}}
+[center]Order {{.Number}}
+---
+{{range .Items}}{{.Name}}|{{.Price}}
+{{end}}===
+[bold]Total|{{.Total}}
+[right]Thank you \| come again
+[center][qr]https://example.com/r/{{.Number}}
+[center][barcode]{{.Number}}
+[cut]
+`
+
+var receiptData = map[string]any{
+	"Store":  "VervePOS",
+	"Number": "R-1001",
+	"Items": []map[string]string{
+		{"Name": "Flat white", "Price": "$4.50"},
+		{"Name": "Almond croissant with extra chocolate", "Price": "$5.25"},
+	},
+	"Total": "$9.75",
+}
+
+func TestParse(t *testing.T) {
+	doc := Parse("[center][bold]Title\n[x] not a tag\n---\nLeft|Right\n[qr] data \n[cut]")
+	want := []Line{
+		{Text: "Title", Align: AlignCenter, Bold: true},
+		{Text: "[x] not a tag"},
+		{Kind: Rule, Text: "-"},
+		{Text: "Left", Right: "Right"},
+		{Kind: QRCode, Text: "data"},
+		{Kind: Cut},
+	}
+	if len(doc.Lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %+v", len(want), doc.Lines)
+	}
+	for i, line := range doc.Lines {
+		if line != want[i] {
+			t.Errorf("Line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+}
+
+func TestLineRows(t *testing.T) {
+	tests := []struct {
+		line Line
+		want []string
+	}{
+		{Line{Text: "Total", Right: "$9.75"}, []string{"Total          $9.75"}},
+		{Line{Text: "Almond croissant with chocolate", Right: "$5.25"}, []string{"Almond croissant", "with chocolate $5.25"}},
+		{Line{Text: "Almond croissant extra", Right: "$5.25"}, []string{"Almond croissant", "extra          $5.25"}},
+		{Line{Text: "Long item name xx", Right: "$15.25"}, []string{"Long item name xx", "              $15.25"}},
+		{Line{Text: "Hi", Align: AlignCenter}, []string{"         Hi"}},
+		{Line{Text: "Hi", Align: AlignRight}, []string{"                  Hi"}},
+		{Line{Text: "Big", Large: true, Align: AlignRight}, []string{"       Big"}},
+		{Line{Text: "abcdefghijklmnopqrstuvwxyz"}, []string{"abcdefghijklmnopqrst", "uvwxyz"}},
+		{Line{Kind: Rule, Text: "="}, []string{"===================="}},
+		{Line{}, []string{""}},
+	}
+	for _, tt := range tests {
+		got := tt.line.Rows(20)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("Rows(%+v) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	templates, err := New(template.FuncMap{"upper": strings.ToUpper}).Parse("receipt", `{{upper .}}|1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates.Width = 32
+	doc, err := templates.Execute("receipt", "coffee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Width != 32 || doc.Lines[0].Text != "COFFEE" || doc.Lines[0].Right != "1" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if _, err := templates.Execute("missing", nil); err == nil {
+		t.Error("Expected a missing template to fail")
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+	templates, _ := New(nil).Parse("receipt", receiptTemplate)
+	doc, err := templates.Execute("receipt", receiptData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.ESCPOS(&buf, ESCPOSOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+
+	for name, want := range map[string][]byte{
+		"init":     {0x1b, '@'},
+		"large":    append([]byte{0x1d, '!', 0x11}, "      VervePOS\n"...),
+		"item":     []byte("Flat white                           $4.50\n"),
+		"wrapped":  []byte("Almond croissant with extra chocolate\n                                     $5.25\n"),
+		"total":    []byte("\x1b\x45\x01Total                                $9.75\n\x1b\x45\x00"),
+		"pipe":     []byte("        Thank you | come again\n"),
+		"qr data":  append([]byte{0x1d, '(', 'k', 31, 0, 49, 80, 48}, "https://example.com/r/R-1001"...),
+		"qr print": {0x1d, '(', 'k', 3, 0, 49, 81, 48},
+		"barcode":  append([]byte{0x1d, 'k', 73, 8}, "{BR-1001"...),
+		"cut":      {0x1d, 'V', 66, 3},
+	} {
+		if !bytes.Contains(out, want) {
+			t.Errorf("Expected %s command %q in output", name, want)
+		}
+	}
+
+	var latin bytes.Buffer
+	Parse("Café €").ESCPOS(&latin, ESCPOSOptions{})
+	if !bytes.Contains(latin.Bytes(), []byte("Caf? ?\n")) {
+		t.Errorf("Expected non-ASCII characters to be replaced, got %q", latin.Bytes())
+	}
+}
+
+func TestPDF(t *testing.T) {
+	templates, _ := New(nil).Parse("receipt", receiptTemplate)
+	doc, _ := templates.Execute("receipt", receiptData)
+
+	var buf bytes.Buffer
+	if err := doc.PDF(&buf, PDFOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-") || !strings.Contains(out, "/FontFile2") {
+		t.Errorf("Expected a PDF with an embedded font, got %d bytes", len(out))
+	}
+	// 80 mm wide, as long as the receipt
+	if !strings.Contains(out, "/MediaBox [0 0 226.77") {
+		t.Error("Expected an 80 mm wide page")
+	}
+
+	var short bytes.Buffer
+	Parse("Total|$1.00").PDF(&short, PDFOptions{})
+	if short.Len() >= buf.Len() {
+		t.Error("Expected a shorter receipt to make a smaller PDF")
+	}
+	if err := doc.PDF(&buf, PDFOptions{PaperWidth: 5, Margin: 4}); err == nil {
+		t.Error("Expected margins wider than the paper to fail")
+	}
+}
@@ -6,6 +6,9 @@ package goTap
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,7 +17,11 @@ import (
 	"net/http/httputil"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -28,6 +35,85 @@ var (
 // RecoveryFunc defines the function passable to CustomRecovery.
 type RecoveryFunc func(c *Context, err any)
 
+// StackFrame is a frame of a recovered panic's stack trace
+type StackFrame struct {
+	Function string  `json:"function"`
+	File     string  `json:"file"`
+	Line     int     `json:"line"`
+	PC       uintptr `json:"-"`
+}
+
+// PanicEvent describes a recovered panic for RecoveryConfig.Hooks
+type PanicEvent struct {
+	// Err is the value passed to panic
+	Err any
+
+	// Stack is the stack trace without frames hidden by SkipFrame, from
+	// the panicking function outwards
+	Stack []StackFrame
+
+	// Method and Route identify the route, e.g. "POST" and "/orders/:id"
+	Method string
+	Route  string
+
+	// ClientGone reports that the client disconnected, so no response is
+	// written
+	ClientGone bool
+}
+
+// PanicHook receives recovered panics, e.g. to report them to Sentry or
+// record them on an OpenTelemetry span
+type PanicHook func(c *Context, event PanicEvent)
+
+// RecoveryConfig holds configuration for the Recovery middleware
+type RecoveryConfig struct {
+	// Output is where panics are logged
+	// Default: goTap.DefaultErrorWriter
+	Output io.Writer
+
+	// Handle writes the response after a panic
+	// Default: aborts with 500
+	Handle RecoveryFunc
+
+	// StackDepth is the maximum number of frames logged, counted after
+	// SkipFrame; 0 logs every frame
+	// Optional.
+	StackDepth int
+
+	// SkipFrame hides matching frames from the logged stack, e.g.
+	// SkipFrameworkFrames
+	// Optional.
+	SkipFrame func(frame StackFrame) bool
+
+	// JSON logs each panic as a single line JSON object for log collectors,
+	// instead of the human readable format
+	// Optional.
+	JSON bool
+
+	// DedupWindow logs panics with the same message at the same location
+	// only once per window, so a broken hot endpoint doesn't flood the log.
+	// The number of suppressed repeats is reported with the next log.
+	// Optional.
+	DedupWindow time.Duration
+
+	// Hooks are called with every recovered panic before Handle, even
+	// when logging is deduplicated or the client is gone. Middleware such
+	// as ErrorReporter adds its own hooks per request.
+	// Optional.
+	Hooks []PanicHook
+}
+
+// SkipFrameworkFrames is a RecoveryConfig.SkipFrame hiding frames of the Go
+// runtime, net/http and goTap, leaving the application's own frames
+func SkipFrameworkFrames(frame StackFrame) bool {
+	for _, prefix := range []string{"runtime.", "net/http.", "github.com/jaswant99k/gotap."} {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Recovery returns a middleware that recovers from any panics and writes a 500 if there was one.
 func Recovery() HandlerFunc {
 	return RecoveryWithWriter(DefaultErrorWriter)
@@ -43,53 +129,95 @@ func RecoveryWithWriter(out io.Writer, recovery ...RecoveryFunc) HandlerFunc {
 
 // CustomRecoveryWithWriter returns a middleware for a given writer that recovers from any panics and calls the provided handle func to handle it.
 func CustomRecoveryWithWriter(out io.Writer, handle RecoveryFunc) HandlerFunc {
+	return newRecovery(RecoveryConfig{Output: out, Handle: handle})
+}
+
+// RecoveryWithHandler returns a Recovery middleware writing the response
+// with handle, e.g. as problem details:
+//
+//	r.Use(goTap.RecoveryWithHandler(func(c *goTap.Context, err any) {
+//	    c.Problem(goTap.ErrInternal)
+//	}))
+//
+// handle isn't called when the client has disconnected.
+func RecoveryWithHandler(handle RecoveryFunc) HandlerFunc {
+	return RecoveryWithConfig(RecoveryConfig{Handle: handle})
+}
+
+// RecoveryWithConfig returns a Recovery middleware with config:
+//
+//	r.Use(goTap.RecoveryWithConfig(goTap.RecoveryConfig{
+//	    StackDepth:  20,
+//	    SkipFrame:   goTap.SkipFrameworkFrames,
+//	    JSON:        true,
+//	    DedupWindow: time.Minute,
+//	}))
+func RecoveryWithConfig(config RecoveryConfig) HandlerFunc {
+	if config.Output == nil {
+		config.Output = DefaultErrorWriter
+	}
+	if config.Handle == nil {
+		config.Handle = defaultHandleRecovery
+	}
+	return newRecovery(config)
+}
+
+// newRecovery builds the middleware; a nil Output disables logging
+func newRecovery(config RecoveryConfig) HandlerFunc {
 	var logger *log.Logger
-	if out != nil {
-		logger = log.New(out, "\n\n\x1b[31m", log.LstdFlags)
+	if config.Output != nil && !config.JSON {
+		logger = log.New(config.Output, "\n\n\x1b[31m", log.LstdFlags)
 	}
+	dedup := &panicDedup{window: config.DedupWindow}
+
 	return func(c *Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				// Check for a broken connection, as it is not really a
-				// condition that warrants a panic stack trace.
-				var brokenPipe bool
-				if ne, ok := err.(*net.OpError); ok {
-					if se, ok := ne.Err.(*os.SyscallError); ok {
-						seStr := strings.ToLower(se.Error())
-						if strings.Contains(seStr, "broken pipe") ||
-							strings.Contains(seStr, "connection reset by peer") {
-							brokenPipe = true
-						}
-					}
+				// A broken connection is not really a condition that
+				// warrants a panic stack trace
+				brokenPipe := isBrokenPipe(err)
+				clientGone := brokenPipe || errors.Is(c.Request.Context().Err(), context.Canceled)
+				if c.engine != nil {
+					c.engine.panics.record(c.Request.Method, c.FullPath(), err)
 				}
-				if logger != nil {
-					stack := stack(3)
-					httpRequest, _ := httputil.DumpRequest(c.Request, false)
-					headers := strings.Split(string(httpRequest), "\r\n")
-					for idx, header := range headers {
-						current := strings.Split(header, ":")
-						if current[0] == "Authorization" {
-							headers[idx] = current[0] + ": *"
-						}
+
+				hooks := append(config.Hooks[:len(config.Hooks):len(config.Hooks)], contextPanicHooks(c)...)
+				if config.Output != nil || len(hooks) > 0 {
+					frames, _ := captureStack(4, 0, config.SkipFrame)
+					for _, hook := range hooks {
+						hook(c, PanicEvent{
+							Err:        err,
+							Stack:      frames,
+							Method:     c.Request.Method,
+							Route:      c.FullPath(),
+							ClientGone: clientGone,
+						})
 					}
-					headersToStr := strings.Join(headers, "\r\n")
-					if brokenPipe {
-						logger.Printf("%s\n%s%s", err, headersToStr, reset)
-					} else if IsDebugging() {
-						logger.Printf("[Recovery] %s panic recovered:\n%s\n%s\n%s%s",
-							timeFormat(time.Now()), headersToStr, err, stack, reset)
-					} else {
-						logger.Printf("[Recovery] %s panic recovered:\n%s\n%s%s",
-							timeFormat(time.Now()), err, stack, reset)
+					if config.Output != nil {
+						more := 0
+						if config.StackDepth > 0 && len(frames) > config.StackDepth {
+							frames, more = frames[:config.StackDepth], len(frames)-config.StackDepth
+						}
+						if suppressed, ok := dedup.allow(err, frames); ok {
+							if config.JSON {
+								logPanicJSON(config.Output, c, err, frames, more, suppressed, brokenPipe)
+							} else {
+								logPanic(logger, c, err, frames, more, suppressed, brokenPipe)
+							}
+						}
 					}
 				}
 
 				// If the connection is dead, we can't write a status to it.
-				if brokenPipe {
-					c.Error(err.(error))
+				if clientGone {
+					if e, ok := err.(error); ok {
+						c.Error(e)
+					} else {
+						c.Error(fmt.Errorf("%v", err))
+					}
 					c.Abort()
 				} else {
-					handle(c, err)
+					config.Handle(c, err)
 				}
 			}
 		}()
@@ -97,37 +225,268 @@ func CustomRecoveryWithWriter(out io.Writer, handle RecoveryFunc) HandlerFunc {
 	}
 }
 
+// isBrokenPipe reports whether a panic is a write to a disconnected client
+func isBrokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET) || errors.Is(e, http.ErrAbortHandler) {
+		return true
+	}
+	var ne *net.OpError
+	if errors.As(e, &ne) {
+		var se *os.SyscallError
+		if errors.As(ne.Err, &se) {
+			seStr := strings.ToLower(se.Error())
+			return strings.Contains(seStr, "broken pipe") ||
+				strings.Contains(seStr, "connection reset by peer")
+		}
+	}
+	return false
+}
+
+// RoutePanics counts the recovered panics of a route
+type RoutePanics struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Count     uint64    `json:"count"`
+	LastPanic time.Time `json:"last_panic"`
+	LastError string    `json:"last_error"`
+}
+
+// panicRegistry counts panics per route
+type panicRegistry struct {
+	mu     sync.Mutex
+	routes map[string]*RoutePanics
+}
+
+func (p *panicRegistry) record(method, path string, err any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.routes == nil {
+		p.routes = make(map[string]*RoutePanics)
+	}
+	key := method + " " + path
+	route, ok := p.routes[key]
+	if !ok {
+		route = &RoutePanics{Method: method, Path: path}
+		p.routes[key] = route
+	}
+	route.Count++
+	route.LastPanic = time.Now()
+	route.LastError = fmt.Sprint(err)
+}
+
+// PanicReport returns the panics recovered per route, most panics first.
+// Requests that matched no route are counted with an empty path.
+func (engine *Engine) PanicReport() []RoutePanics {
+	engine.panics.mu.Lock()
+	defer engine.panics.mu.Unlock()
+	routes := make([]RoutePanics, 0, len(engine.panics.routes))
+	for _, route := range engine.panics.routes {
+		routes = append(routes, *route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Count != routes[j].Count {
+			return routes[i].Count > routes[j].Count
+		}
+		return routes[i].Method+routes[i].Path < routes[j].Method+routes[j].Path
+	})
+	return routes
+}
+
+// PanicReportHandler returns a handler serving Engine.PanicReport as JSON,
+// for an admin endpoint:
+//
+//	admin.GET("/panics", goTap.PanicReportHandler())
+func PanicReportHandler() HandlerFunc {
+	return func(c *Context) {
+		routes := []RoutePanics{}
+		if c.engine != nil {
+			routes = c.engine.PanicReport()
+		}
+		c.JSON(http.StatusOK, H{"routes": routes})
+	}
+}
+
 func defaultHandleRecovery(c *Context, err any) {
 	c.AbortWithStatus(http.StatusInternalServerError)
 }
 
-// stack returns a nicely formatted stack frame, skipping skip frames.
-func stack(skip int) []byte {
+// dumpHeaders returns the request line and headers with credentials masked
+func dumpHeaders(req *http.Request) string {
+	httpRequest, _ := httputil.DumpRequest(req, false)
+	headers := strings.Split(string(httpRequest), "\r\n")
+	for idx, header := range headers {
+		current := strings.Split(header, ":")
+		if current[0] == "Authorization" {
+			headers[idx] = current[0] + ": *"
+		}
+	}
+	return strings.Join(headers, "\r\n")
+}
+
+// logPanic writes the human readable panic report
+func logPanic(logger *log.Logger, c *Context, err any, frames []StackFrame, more, suppressed int, brokenPipe bool) {
+	headersToStr := dumpHeaders(c.Request)
+	if brokenPipe {
+		logger.Printf("%s\n%s%s", err, headersToStr, reset)
+		return
+	}
+
+	stack := formatStack(frames, more)
+	repeated := ""
+	if suppressed > 0 {
+		repeated = fmt.Sprintf(" (%d similar panics suppressed)", suppressed)
+	}
+	if IsDebugging() {
+		logger.Printf("[Recovery] %s panic recovered%s:\n%s\n%s\n%s%s",
+			timeFormat(time.Now()), repeated, headersToStr, err, stack, reset)
+	} else {
+		logger.Printf("[Recovery] %s panic recovered%s:\n%s\n%s%s",
+			timeFormat(time.Now()), repeated, err, stack, reset)
+	}
+}
+
+// logPanicJSON writes the panic report as one JSON line
+func logPanicJSON(out io.Writer, c *Context, err any, frames []StackFrame, more, suppressed int, brokenPipe bool) {
+	entry := struct {
+		Time         time.Time    `json:"time"`
+		Message      string       `json:"message"`
+		Error        string       `json:"error"`
+		Method       string       `json:"method"`
+		Path         string       `json:"path"`
+		BrokenPipe   bool         `json:"broken_pipe,omitempty"`
+		Headers      string       `json:"headers,omitempty"`
+		Stack        []StackFrame `json:"stack,omitempty"`
+		HiddenFrames int          `json:"hidden_frames,omitempty"`
+		Suppressed   int          `json:"suppressed,omitempty"`
+	}{
+		Time:       time.Now(),
+		Message:    "panic recovered",
+		Error:      fmt.Sprint(err),
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		BrokenPipe: brokenPipe,
+		Suppressed: suppressed,
+	}
+	if !brokenPipe {
+		entry.Stack = frames
+		entry.HiddenFrames = more
+	}
+	if IsDebugging() {
+		entry.Headers = dumpHeaders(c.Request)
+	}
+	data, _ := json.Marshal(entry)
+	out.Write(append(data, '\n'))
+}
+
+// captureStack returns up to depth frames of the calling goroutine that
+// skipFrame doesn't hide, skipping skip frames like runtime.Callers, and
+// the number of frames left out because of depth
+func captureStack(skip, depth int, skipFrame func(StackFrame) bool) (frames []StackFrame, more int) {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, ok := iter.Next()
+		frame := StackFrame{Function: f.Function, File: f.File, Line: f.Line, PC: f.PC}
+		if frame.Function != "" && (skipFrame == nil || !skipFrame(frame)) {
+			if depth > 0 && len(frames) >= depth {
+				more++
+			} else {
+				frames = append(frames, frame)
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+	return frames, more
+}
+
+// formatStack renders frames with their source lines
+func formatStack(frames []StackFrame, more int) []byte {
 	buf := new(bytes.Buffer) // the returned data
 	// As we loop, we open files and read them. These variables record the currently
 	// loaded file.
 	var lines [][]byte
 	var lastFile string
-	for i := skip; ; i++ { // Skip the expected number of frames
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
+	for _, frame := range frames {
 		// Print this much at least.  If we can't find the source, it won't show.
-		fmt.Fprintf(buf, "%s:%d (0x%x)\n", file, line, pc)
-		if file != lastFile {
-			data, err := os.ReadFile(file)
+		fmt.Fprintf(buf, "%s:%d (0x%x)\n", frame.File, frame.Line, frame.PC)
+		if frame.File != lastFile {
+			data, err := os.ReadFile(frame.File)
 			if err != nil {
 				continue
 			}
 			lines = bytes.Split(data, []byte{'\n'})
-			lastFile = file
+			lastFile = frame.File
 		}
-		fmt.Fprintf(buf, "\t%s: %s\n", function(pc), source(lines, line))
+		fmt.Fprintf(buf, "\t%s: %s\n", function(frame.Function), source(lines, frame.Line))
+	}
+	if more > 0 {
+		fmt.Fprintf(buf, "\t... %d more frames\n", more)
 	}
 	return buf.Bytes()
 }
 
+// maxPanicDedupKeys bounds the panics tracked for deduplication
+const maxPanicDedupKeys = 1000
+
+// panicDedup rate limits logging of identical panics
+type panicDedup struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*panicSeen
+}
+
+type panicSeen struct {
+	logged     time.Time
+	suppressed int
+}
+
+// allow reports whether a panic should be logged, and how many identical
+// panics were suppressed since it was last logged
+func (d *panicDedup) allow(err any, frames []StackFrame) (suppressed int, ok bool) {
+	if d.window <= 0 {
+		return 0, true
+	}
+	key := fmt.Sprint(err)
+	if len(frames) > 0 {
+		key += "\x00" + frames[0].File + ":" + strconv.Itoa(frames[0].Line)
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil || len(d.seen) >= maxPanicDedupKeys {
+		d.seen = make(map[string]*panicSeen)
+	}
+	seen, found := d.seen[key]
+	if !found {
+		d.seen[key] = &panicSeen{logged: now}
+		return 0, true
+	}
+	if now.Sub(seen.logged) < d.window {
+		seen.suppressed++
+		return 0, false
+	}
+	suppressed = seen.suppressed
+	seen.logged, seen.suppressed = now, 0
+	return suppressed, true
+}
+
 // source returns a space-trimmed slice of the n'th line.
 func source(lines [][]byte, n int) []byte {
 	n-- // in stack trace, lines are 1-indexed but our array is 0-indexed
@@ -137,13 +496,12 @@ func source(lines [][]byte, n int) []byte {
 	return bytes.TrimSpace(lines[n])
 }
 
-// function returns, if possible, the name of the function containing the PC.
-func function(pc uintptr) []byte {
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
+// function returns the name of the function without its package path.
+func function(fullName string) []byte {
+	if fullName == "" {
 		return dunno
 	}
-	name := []byte(fn.Name())
+	name := []byte(fullName)
 	// The name includes the path name to the package, which is unnecessary
 	// since the file name is already included.  Plus, it has center dots.
 	// That is, we see
@@ -0,0 +1,226 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func panickingHandler(c *Context) {
+	panic("checkout failed")
+}
+
+func TestRecoveryTextOutput(t *testing.T) {
+	var out bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithWriter(&out))
+	r.GET("/checkout", panickingHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/checkout", nil))
+	if w.Code != 500 {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	log := out.String()
+	if !strings.Contains(log, "panic recovered") || !strings.Contains(log, "checkout failed") {
+		t.Errorf("Unexpected log %q", log)
+	}
+	// The stack starts at the panicking function
+	first := strings.SplitN(log[strings.Index(log, "recovery_test.go"):], "\n", 3)
+	if !strings.Contains(first[1], "panickingHandler: panic(\"checkout failed\")") {
+		t.Errorf("Expected stack to start at the panic, got %q", first)
+	}
+}
+
+func TestRecoveryJSONStackFiltering(t *testing.T) {
+	var out bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{
+		Output:     &out,
+		JSON:       true,
+		StackDepth: 1,
+		SkipFrame: func(frame StackFrame) bool {
+			return strings.HasPrefix(frame.Function, "runtime.")
+		},
+	}))
+	r.GET("/checkout", panickingHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/checkout?x=1", nil))
+	if w.Code != 500 {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+
+	var entry struct {
+		Error        string       `json:"error"`
+		Method       string       `json:"method"`
+		Path         string       `json:"path"`
+		Stack        []StackFrame `json:"stack"`
+		HiddenFrames int          `json:"hidden_frames"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON object, got %q: %v", out.String(), err)
+	}
+	if entry.Error != "checkout failed" || entry.Method != "GET" || entry.Path != "/checkout" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if len(entry.Stack) != 1 || !strings.HasSuffix(entry.Stack[0].Function, ".panickingHandler") ||
+		!strings.HasSuffix(entry.Stack[0].File, "recovery_test.go") {
+		t.Errorf("Unexpected stack %+v", entry.Stack)
+	}
+	if entry.HiddenFrames == 0 {
+		t.Error("Expected the remaining frames to be counted")
+	}
+}
+
+func TestSkipFrameworkFrames(t *testing.T) {
+	for fn, skip := range map[string]bool{
+		"runtime.gopanic":                             true,
+		"net/http.HandlerFunc.ServeHTTP":              true,
+		"github.com/jaswant99k/gotap.(*Context).Next": true,
+		"github.com/acme/pos/handlers.Checkout":       false,
+		"main.main":                                   false,
+	} {
+		if got := SkipFrameworkFrames(StackFrame{Function: fn}); got != skip {
+			t.Errorf("SkipFrameworkFrames(%s) = %v", fn, got)
+		}
+	}
+}
+
+func TestRecoveryDedup(t *testing.T) {
+	var out bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{Output: &out, DedupWindow: 50 * time.Millisecond}))
+	r.GET("/checkout", panickingHandler)
+	r.GET("/refund", func(c *Context) { panic("refund failed") })
+
+	serve := func(path string) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 500 {
+			t.Errorf("Expected 500 for suppressed panics too, got %d", w.Code)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		serve("/checkout")
+	}
+	serve("/refund")
+	if n := strings.Count(out.String(), "panic recovered"); n != 2 {
+		t.Errorf("Expected one log per distinct panic, got %d", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	out.Reset()
+	serve("/checkout")
+	if !strings.Contains(out.String(), "(4 similar panics suppressed)") {
+		t.Errorf("Expected suppressed count after the window, got %q", out.String())
+	}
+}
+
+func TestRecoveryWithHandler(t *testing.T) {
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{
+		Output: io.Discard,
+		Handle: func(c *Context, err any) {
+			c.JSON(http.StatusServiceUnavailable, H{"error": fmt.Sprint(err)})
+		},
+	}))
+	r.GET("/checkout", panickingHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/checkout", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "checkout failed") {
+		t.Errorf("Expected the custom response, got %d %s", w.Code, w.Body.String())
+	}
+
+	defer func(w io.Writer) { DefaultErrorWriter = w }(DefaultErrorWriter)
+	DefaultErrorWriter = io.Discard
+	r = New()
+	r.Use(RecoveryWithHandler(func(c *Context, err any) {
+		c.String(http.StatusTeapot, "recovered")
+	}))
+	r.GET("/checkout", panickingHandler)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/checkout", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected RecoveryWithHandler to write the response, got %d", w.Code)
+	}
+}
+
+func TestRecoveryClientGone(t *testing.T) {
+	var events []PanicEvent
+	handled := false
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{
+		Output: io.Discard,
+		Handle: func(c *Context, err any) { handled = true },
+		Hooks:  []PanicHook{func(c *Context, event PanicEvent) { events = append(events, event) }},
+	}))
+	r.GET("/pipe", func(c *Context) {
+		panic(&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+	r.GET("/canceled", panickingHandler)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pipe", nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/canceled", nil).WithContext(ctx))
+
+	if handled {
+		t.Error("Expected no response for disconnected clients")
+	}
+	if len(events) != 2 || !events[0].ClientGone || !events[1].ClientGone || events[1].Route != "/canceled" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+}
+
+func TestRecoveryHooksAndPanicReport(t *testing.T) {
+	var event PanicEvent
+	var out bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{
+		Output:      &out,
+		SkipFrame:   SkipFrameworkFrames,
+		DedupWindow: time.Minute,
+		Hooks:       []PanicHook{func(c *Context, e PanicEvent) { event = e }},
+	}))
+	r.GET("/orders/:id", panickingHandler)
+	r.GET("/refund", func(c *Context) { panic("refund failed") })
+	r.GET("/panics", PanicReportHandler())
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/refund"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	if event.Err != "refund failed" || event.Method != "GET" || event.Route != "/refund" || event.ClientGone {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	for _, frame := range event.Stack {
+		if SkipFrameworkFrames(frame) {
+			t.Errorf("Expected framework frames to be hidden, got %s", frame.Function)
+		}
+	}
+
+	report := r.PanicReport()
+	if len(report) != 2 || report[0].Path != "/orders/:id" || report[0].Count != 2 || report[0].LastError != "checkout failed" || report[1].Count != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panics", nil))
+	if !strings.Contains(w.Body.String(), `"path":"/orders/:id","count":2`) {
+		t.Errorf("Unexpected report response %s", w.Body.String())
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RedactionConfig holds configuration for the Redaction middleware
+type RedactionConfig struct {
+	// RoleFunc returns the role of the current request
+	// Default: the Role claim set by JWTAuth
+	RoleFunc func(*Context) string
+
+	// Policy maps a role to JSON field names removed from responses for that role.
+	// It applies to struct fields and map keys at any depth, e.g.
+	// {"clerk": {"cost_price", "supplier_id"}}
+	Policy map[string][]string
+
+	// TagName is the struct tag listing the roles a field is hidden from,
+	// e.g. `redact:"clerk,user"`. Tagged fields are hidden from requests
+	// without a role, whatever roles they list.
+	// Default: "redact"
+	TagName string
+}
+
+// redactor removes fields from JSON responses for a single role
+type redactor struct {
+	role    string
+	tagName string
+	fields  map[string]struct{}
+}
+
+// Redaction returns a middleware that filters JSON responses by role.
+// Fields tagged `redact:"<role>"` or listed in the policy for the current
+// role are dropped by c.JSON and the other JSON renderers, so one handler
+// can serve full records to admins and trimmed records to clerks:
+//
+//	type Product struct {
+//	    Name      string  `json:"name"`
+//	    CostPrice float64 `json:"cost_price" redact:"clerk"`
+//	}
+//
+//	r.Use(goTap.JWTAuth(secret), goTap.Redaction(goTap.RedactionConfig{}))
+func Redaction(config RedactionConfig) HandlerFunc {
+	if config.RoleFunc == nil {
+		config.RoleFunc = func(c *Context) string {
+			if claims, ok := GetJWTClaims(c); ok {
+				return claims.Role
+			}
+			return ""
+		}
+	}
+	if config.TagName == "" {
+		config.TagName = "redact"
+	}
+
+	policy := make(map[string]map[string]struct{}, len(config.Policy))
+	for role, fields := range config.Policy {
+		set := make(map[string]struct{}, len(fields))
+		for _, field := range fields {
+			set[field] = struct{}{}
+		}
+		policy[role] = set
+	}
+
+	return func(c *Context) {
+		role := config.RoleFunc(c)
+		c.Set("redactor", &redactor{
+			role:    role,
+			tagName: config.TagName,
+			fields:  policy[role],
+		})
+		c.Next()
+	}
+}
+
+// Redact returns a copy of obj, as generic JSON values, with the fields
+// hidden from role removed. An empty role sees no tagged field. Policy may
+// be nil.
+func Redact(obj any, role string, policy map[string][]string) any {
+	r := &redactor{role: role, tagName: "redact", fields: map[string]struct{}{}}
+	for _, field := range policy[role] {
+		r.fields[field] = struct{}{}
+	}
+	return r.apply(obj)
+}
+
+func (r *redactor) apply(obj any) any {
+	return filterJSON(reflect.ValueOf(obj), r.keep)
+}
+
+// keep reports whether a field is visible to the role
+func (r *redactor) keep(name string, field *reflect.StructField) bool {
+	if _, hidden := r.fields[name]; hidden {
+		return false
+	}
+	if field != nil {
+		if roles := field.Tag.Get(r.tagName); roles != "" {
+			// An unknown role is the most restricted
+			if r.role == "" {
+				return false
+			}
+			for _, role := range strings.Split(roles, ",") {
+				if strings.TrimSpace(role) == r.role {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// prepareJSON applies the request's response filters to obj before encoding
+func (c *Context) prepareJSON(obj any) any {
+	if v, ok := c.Get("redactor"); ok {
+		if r, ok := v.(*redactor); ok {
+			obj = r.apply(obj)
+		}
+	}
+	return obj
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// filterJSON converts v into generic JSON values (maps, slices and leaves),
+// keeping only the struct fields and map keys accepted by keep. field is nil
+// for map keys. Values implementing json.Marshaler or encoding.TextMarshaler
+// are kept as-is so their own encoding is preserved.
+func filterJSON(v reflect.Value, keep func(name string, field *reflect.StructField) bool) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return filterJSON(v.Elem(), keep)
+
+	case reflect.Struct:
+		if reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.NumField())
+		filterStructFields(v, out, keep)
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			name := iter.Key().String()
+			if keep(name, nil) {
+				out[name] = filterJSON(iter.Value(), keep)
+			}
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		// []byte is encoded as base64 by encoding/json
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = filterJSON(v.Index(i), keep)
+		}
+		return out
+
+	default:
+		return v.Interface()
+	}
+}
+
+func filterStructFields(v reflect.Value, out map[string]any, keep func(name string, field *reflect.StructField) bool) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := v.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a JSON name are flattened like encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				filterStructFields(embedded, out, keep)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyJSONValue(fieldValue) {
+			continue
+		}
+		if !keep(name, &field) {
+			continue
+		}
+		out[name] = filterJSON(fieldValue, keep)
+	}
+}
+
+// isEmptyJSONValue reports whether v is empty in the sense of the
+// encoding/json omitempty option
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type redactedSupplier struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone" redact:"clerk"`
+}
+
+type redactedProduct struct {
+	Model
+	Name      string            `json:"name"`
+	CostPrice float64           `json:"cost_price" redact:"clerk,user"`
+	Supplier  *redactedSupplier `json:"supplier,omitempty"`
+	Notes     string            `json:"notes,omitempty"`
+	secret    string
+}
+
+func redactionRouter(role string, policy map[string][]string) *Engine {
+	r := New()
+	r.Use(Redaction(RedactionConfig{
+		RoleFunc: func(c *Context) string { return role },
+		Policy:   policy,
+	}))
+	r.GET("/product", func(c *Context) {
+		c.JSON(200, redactedProduct{
+			Model:     Model{ID: 7},
+			Name:      "Espresso",
+			CostPrice: 1.25,
+			Supplier:  &redactedSupplier{Name: "Beans Co", Phone: "555-0100"},
+			secret:    "hidden",
+		})
+	})
+	r.GET("/summary", func(c *Context) {
+		c.IndentedJSON(200, H{"total": 10, "margin": 0.4, "items": []H{{"sku": "A1", "margin": 0.2}}})
+	})
+	return r
+}
+
+func decodeRedacted(t *testing.T, r *Engine, path string) map[string]any {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	r.ServeHTTP(w, req)
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestRedactionByTag(t *testing.T) {
+	body := decodeRedacted(t, redactionRouter("clerk", nil), "/product")
+
+	if _, ok := body["cost_price"]; ok {
+		t.Error("Expected cost_price to be redacted for clerk")
+	}
+	if body["name"] != "Espresso" {
+		t.Errorf("Expected name Espresso, got %v", body["name"])
+	}
+	if body["id"] != float64(7) {
+		t.Errorf("Expected embedded Model id to be flattened, got %v", body["id"])
+	}
+	if _, ok := body["created_at"].(string); !ok {
+		t.Errorf("Expected created_at encoded as time string, got %v", body["created_at"])
+	}
+	if _, ok := body["notes"]; ok {
+		t.Error("Expected omitempty notes to be omitted")
+	}
+	if _, ok := body["secret"]; ok {
+		t.Error("Expected unexported field to be omitted")
+	}
+
+	supplier, _ := body["supplier"].(map[string]any)
+	if supplier["name"] != "Beans Co" {
+		t.Errorf("Expected nested supplier name, got %v", supplier)
+	}
+	if _, ok := supplier["phone"]; ok {
+		t.Error("Expected nested phone to be redacted for clerk")
+	}
+}
+
+func TestRedactionUnlistedRoleSeesEverything(t *testing.T) {
+	body := decodeRedacted(t, redactionRouter("admin", nil), "/product")
+
+	if body["cost_price"] != 1.25 {
+		t.Errorf("Expected admin to see cost_price, got %v", body["cost_price"])
+	}
+	supplier, _ := body["supplier"].(map[string]any)
+	if supplier["phone"] != "555-0100" {
+		t.Errorf("Expected admin to see supplier phone, got %v", supplier)
+	}
+}
+
+func TestRedactionWithoutRoleHidesTaggedFields(t *testing.T) {
+	body := decodeRedacted(t, redactionRouter("", nil), "/product")
+
+	if _, ok := body["cost_price"]; ok {
+		t.Error("Expected cost_price to be redacted without a role")
+	}
+	if body["name"] != "Espresso" {
+		t.Errorf("Expected name Espresso, got %v", body["name"])
+	}
+	supplier, _ := body["supplier"].(map[string]any)
+	if _, ok := supplier["phone"]; ok || supplier["name"] != "Beans Co" {
+		t.Errorf("Expected only the supplier phone to be redacted without a role, got %v", supplier)
+	}
+
+	data, _ := json.Marshal(Redact(redactedSupplier{Name: "A", Phone: "1"}, "", nil))
+	if string(data) != `{"name":"A"}` {
+		t.Errorf("Expected Redact without a role to hide phone, got %s", data)
+	}
+}
+
+func TestRedactionPolicyMap(t *testing.T) {
+	policy := map[string][]string{"clerk": {"margin", "supplier"}}
+
+	body := decodeRedacted(t, redactionRouter("clerk", policy), "/summary")
+	if _, ok := body["margin"]; ok {
+		t.Error("Expected margin to be removed by policy")
+	}
+	items, _ := body["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %v", body["items"])
+	}
+	if item := items[0].(map[string]any); item["sku"] != "A1" || item["margin"] != nil {
+		t.Errorf("Expected nested margin to be removed, got %v", item)
+	}
+
+	body = decodeRedacted(t, redactionRouter("clerk", policy), "/product")
+	if _, ok := body["supplier"]; ok {
+		t.Error("Expected supplier struct field to be removed by policy")
+	}
+}
+
+func TestRedactFunction(t *testing.T) {
+	out := Redact([]redactedSupplier{{Name: "A", Phone: "1"}}, "clerk", nil)
+	data, _ := json.Marshal(out)
+	if string(data) != `[{"name":"A"}]` {
+		t.Errorf("Expected phone to be redacted, got %s", data)
+	}
+
+	out = Redact(H{"name": "A", "email": "a@example.com"}, "user", map[string][]string{"user": {"email"}})
+	data, _ = json.Marshal(out)
+	if string(data) != `{"name":"A"}` {
+		t.Errorf("Expected email to be redacted, got %s", data)
+	}
+}
@@ -2,12 +2,17 @@ package goTap
 
 import (
 	"bytes"
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"unicode"
+
+	"github.com/goccy/go-yaml"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
 )
 
 // ========== JSON Rendering ==========
@@ -20,7 +25,13 @@ func (c *Context) IndentedJSON(code int, obj interface{}) {
 	c.Status(code)
 	c.setContentType("application/json; charset=utf-8")
 
-	jsonBytes, err := json.MarshalIndent(obj, "", "    ")
+	e := getJSONEncoder(true)
+	e.enc.SetIndent("", "    ")
+	defer func() {
+		e.enc.SetIndent("", "")
+		putJSONEncoder(e)
+	}()
+	jsonBytes, err := e.marshal(c.prepareJSON(obj))
 	if err != nil {
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 		return
@@ -45,7 +56,9 @@ func (c *Context) SecureJSONWithPrefix(code int, prefix string, obj interface{})
 	c.Status(code)
 	c.setContentType("application/json; charset=utf-8")
 
-	jsonBytes, err := json.Marshal(obj)
+	e := getJSONEncoder(true)
+	defer putJSONEncoder(e)
+	jsonBytes, err := e.marshal(c.prepareJSON(obj))
 	if err != nil {
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 		return
@@ -71,7 +84,9 @@ func (c *Context) JSONP(code int, obj interface{}) {
 	c.Status(code)
 	c.setContentType("application/javascript; charset=utf-8")
 
-	jsonBytes, err := json.Marshal(obj)
+	e := getJSONEncoder(true)
+	defer putJSONEncoder(e)
+	jsonBytes, err := e.marshal(c.prepareJSON(obj))
 	if err != nil {
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 		return
@@ -92,19 +107,28 @@ func (c *Context) AsciiJSON(code int, obj interface{}) {
 	c.Status(code)
 	c.setContentType("application/json")
 
-	jsonBytes, err := json.Marshal(obj)
+	e := getJSONEncoder(true)
+	defer putJSONEncoder(e)
+	jsonBytes, err := e.marshal(c.prepareJSON(obj))
 	if err != nil {
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var buffer bytes.Buffer
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+	var hex [8]byte
 	for _, r := range string(jsonBytes) {
 		if r > unicode.MaxASCII {
 			// Convert to Unicode escape sequence
-			fmt.Fprintf(&buffer, "\\u%04x", r)
+			digits := strconv.AppendInt(hex[:0], int64(r), 16)
+			buffer.WriteString(`\u`)
+			for i := len(digits); i < 4; i++ {
+				buffer.WriteByte('0')
+			}
+			buffer.Write(digits)
 		} else {
-			buffer.WriteRune(r)
+			buffer.WriteByte(byte(r))
 		}
 	}
 
@@ -117,12 +141,13 @@ func (c *Context) PureJSON(code int, obj interface{}) {
 	c.Status(code)
 	c.setContentType("application/json; charset=utf-8")
 
-	encoder := json.NewEncoder(c.Writer)
-	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(obj); err != nil {
+	e := getJSONEncoder(false)
+	defer putJSONEncoder(e)
+	if err := e.encode(c.prepareJSON(obj)); err != nil {
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	c.Writer.Write(e.buf.Bytes())
 }
 
 // ========== XML Rendering ==========
@@ -132,83 +157,51 @@ func (c *Context) XML(code int, obj interface{}) {
 	c.Status(code)
 	c.setContentType("application/xml; charset=utf-8")
 
-	encoder := xml.NewEncoder(c.Writer)
-	encoder.Indent("", "  ")
-
-	if err := encoder.Encode(obj); err != nil {
+	e := getXMLEncoder()
+	if err := e.enc.Encode(obj); err != nil {
 		panic(err)
 	}
+	c.Writer.Write(e.bytes())
+	putXMLEncoder(e)
 }
 
 // ========== YAML Rendering ==========
 
 // YAML serializes the given struct as YAML into the response body
 func (c *Context) YAML(code int, obj interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
 	c.Status(code)
 	c.setContentType("application/x-yaml; charset=utf-8")
-
-	// Simple YAML serialization (basic implementation)
-	// For production, use gopkg.in/yaml.v3
-	yamlData := convertToYAML(obj)
-	c.Writer.Write([]byte(yamlData))
-}
-
-// convertToYAML provides basic YAML conversion
-// For full YAML support, integrate gopkg.in/yaml.v3
-func convertToYAML(obj interface{}) string {
-	// This is a simplified version
-	// In production, use: yaml.Marshal(obj)
-	switch v := obj.(type) {
-	case H:
-		result := ""
-		for key, val := range v {
-			result += key + ": "
-			switch val := val.(type) {
-			case string:
-				result += val + "\n"
-			case int, int64, float64, bool:
-				result += toString(val) + "\n"
-			default:
-				result += toString(val) + "\n"
-			}
-		}
-		return result
-	default:
-		return toString(obj)
-	}
+	c.Writer.Write(data)
 }
 
-// ========== HTML Rendering ==========
-
-var htmlTemplates *template.Template
-
-// LoadHTMLGlob loads HTML templates from a glob pattern
-func (engine *Engine) LoadHTMLGlob(pattern string) {
-	htmlTemplates = template.Must(template.ParseGlob(pattern))
-}
+// ========== MsgPack Rendering ==========
 
-// LoadHTMLFiles loads HTML templates from specific files
-func (engine *Engine) LoadHTMLFiles(files ...string) {
-	htmlTemplates = template.Must(template.ParseFiles(files...))
+// MsgPack serializes the given struct as MessagePack into the response body
+func (c *Context) MsgPack(code int, obj interface{}) {
+	var buf bytes.Buffer
+	if err := codec.NewEncoder(&buf, new(codec.MsgpackHandle)).Encode(obj); err != nil {
+		panic(err)
+	}
+	c.Data(code, MIMEMsgPack, buf.Bytes())
 }
 
-// SetHTMLTemplate sets a custom HTML template
-func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
-	htmlTemplates = templ
-}
+// ========== ProtoBuf Rendering ==========
 
-// HTML renders the HTTP template specified by its file name
-func (c *Context) HTML(code int, name string, obj interface{}) {
-	c.Status(code)
-	c.setContentType("text/html; charset=utf-8")
-
-	if htmlTemplates == nil {
-		panic("HTML templates not loaded. Use LoadHTMLGlob() or LoadHTMLFiles()")
+// ProtoBuf serializes the given proto.Message into the response body
+func (c *Context) ProtoBuf(code int, obj interface{}) {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		panic(fmt.Sprintf("goTap: ProtoBuf requires a proto.Message, got %T", obj))
 	}
-
-	if err := htmlTemplates.ExecuteTemplate(c.Writer, name, obj); err != nil {
+	data, err := proto.Marshal(msg)
+	if err != nil {
 		panic(err)
 	}
+	c.Data(code, MIMEProtoBuf, data)
 }
 
 // ========== Redirect ==================
@@ -239,34 +232,47 @@ type SSEvent struct {
 	Retry uint
 }
 
-// Render renders a Server-Sent Event
-func (e SSEvent) Render(w http.ResponseWriter) error {
+// ContentType returns the Content-Type of an event stream
+func (e SSEvent) ContentType() string {
+	return "text/event-stream"
+}
+
+// Encode writes the event in the event stream format to buf
+func (e SSEvent) Encode(buf *bytes.Buffer) error {
 	if e.Event != "" {
-		if _, err := w.Write([]byte("event: " + e.Event + "\n")); err != nil {
-			return err
-		}
+		buf.WriteString("event: ")
+		buf.WriteString(e.Event)
+		buf.WriteByte('\n')
 	}
-
 	if e.ID != "" {
-		if _, err := w.Write([]byte("id: " + e.ID + "\n")); err != nil {
-			return err
-		}
+		buf.WriteString("id: ")
+		buf.WriteString(e.ID)
+		buf.WriteByte('\n')
 	}
-
 	if e.Retry > 0 {
-		if _, err := w.Write([]byte("retry: " + toString(e.Retry) + "\n")); err != nil {
-			return err
-		}
+		buf.WriteString("retry: ")
+		buf.WriteString(strconv.FormatUint(uint64(e.Retry), 10))
+		buf.WriteByte('\n')
 	}
-
-	dataStr := toString(e.Data)
-	if _, err := w.Write([]byte("data: " + dataStr + "\n\n")); err != nil {
-		return err
+	buf.WriteString("data: ")
+	if data, ok := e.Data.(string); ok {
+		buf.WriteString(data)
+	} else {
+		fmt.Fprint(buf, e.Data)
 	}
-
+	buf.WriteString("\n\n")
 	return nil
 }
 
+// Render renders a Server-Sent Event
+func (e SSEvent) Render(w http.ResponseWriter) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	e.Encode(buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
 // SSE writes Server-Sent Events into the response stream
 func (c *Context) SSE(event string, data interface{}) {
 	c.Render(-1, SSEvent{
@@ -275,20 +281,40 @@ func (c *Context) SSE(event string, data interface{}) {
 	})
 }
 
-// Render writes a response using the provided renderer
-func (c *Context) Render(code int, r interface{}) {
+// Renderer encodes a response body for Context.Render. Implementing it
+// plugs a custom encoder, e.g. CBOR or CSV, into the pooled rendering of
+// goTap: the body is encoded into a pooled buffer and written in one call.
+type Renderer interface {
+	// ContentType returns the Content-Type of the body, or "" to keep the
+	// header as set
+	ContentType() string
+
+	// Encode writes the body to buf
+	Encode(buf *bytes.Buffer) error
+}
+
+// Render writes a response using the provided renderer. A code of 0 or
+// less keeps the status, e.g. for each event of a Server-Sent Event stream.
+func (c *Context) Render(code int, r Renderer) {
 	if code > 0 {
 		c.Status(code)
 	}
-
-	switch v := r.(type) {
-	case SSEvent:
-		c.setContentType("text/event-stream")
+	if contentType := r.ContentType(); contentType != "" {
+		c.setContentType(contentType)
+	}
+	if _, ok := r.(SSEvent); ok {
 		c.Header("Cache-Control", "no-cache")
 		c.Header("Connection", "keep-alive")
-		v.Render(c.Writer)
-	default:
-		panic("Unknown render type")
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := r.Encode(buf); err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := c.Writer.Write(buf.Bytes()); err != nil {
+		c.Error(err)
 	}
 }
 
@@ -296,31 +322,41 @@ func (c *Context) Render(code int, r interface{}) {
 
 // Negotiate contains all negotiations data
 type Negotiate struct {
-	Offered  []string
-	HTMLName string
-	HTMLData interface{}
-	JSONData interface{}
-	XMLData  interface{}
-	YAMLData interface{}
-	Data     interface{}
+	Offered      []string
+	HTMLName     string
+	HTMLData     interface{}
+	JSONData     interface{}
+	XMLData      interface{}
+	YAMLData     interface{}
+	MsgPackData  interface{}
+	ProtoBufData interface{}
+	Data         interface{}
 }
 
 // Negotiate chooses the best format to render based on Accept header
 func (c *Context) Negotiate(code int, config Negotiate) {
 	switch c.NegotiateFormat(config.Offered...) {
-	case "application/json":
+	case MIMEJSON:
 		data := chooseData(config.JSONData, config.Data)
 		c.JSON(code, data)
 
-	case "application/xml", "text/xml":
+	case MIMEXML, MIMEXML2:
 		data := chooseData(config.XMLData, config.Data)
 		c.XML(code, data)
 
-	case "application/x-yaml":
+	case MIMEYAML, MIMEYAML2:
 		data := chooseData(config.YAMLData, config.Data)
 		c.YAML(code, data)
 
-	case "text/html":
+	case MIMEMsgPack, MIMEMsgPack2:
+		data := chooseData(config.MsgPackData, config.Data)
+		c.MsgPack(code, data)
+
+	case MIMEProtoBuf:
+		data := chooseData(config.ProtoBufData, config.Data)
+		c.ProtoBuf(code, data)
+
+	case MIMEHTML:
 		data := chooseData(config.HTMLData, config.Data)
 		if config.HTMLName != "" {
 			c.HTML(code, config.HTMLName, data)
@@ -333,27 +369,123 @@ func (c *Context) Negotiate(code int, config Negotiate) {
 	}
 }
 
-// NegotiateFormat returns an acceptable format from the Accept header
+// NegotiateFormat returns the offer the client prefers, following the
+// quality values, wildcards and specificity rules of RFC 7231 section
+// 5.3.2. Ties go to the earlier offer. Without an Accept header the first
+// offer is returned; when no offer is acceptable, Engine.DefaultOffer if it
+// is offered, else the first offer. Context.Accepted replaces the header.
 func (c *Context) NegotiateFormat(offered ...string) string {
 	if len(offered) == 0 {
 		return ""
 	}
 
-	accept := c.Request.Header.Get("Accept")
-	if accept == "" {
-		return offered[0]
+	var ranges []mediaRange
+	if c.Accepted != nil {
+		ranges = parseAccept(strings.Join(c.Accepted, ","))
+	} else {
+		accept := c.Request.Header.Get("Accept")
+		if accept == "" {
+			return offered[0]
+		}
+		ranges = parseAccept(accept)
 	}
 
-	// Simple content negotiation
+	best, bestQ := "", 0.0
 	for _, offer := range offered {
-		if contains(accept, offer) {
-			return offer
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
 		}
 	}
-
+	if best != "" {
+		return best
+	}
+	if c.engine != nil && c.engine.DefaultOffer != "" && containsString(offered, c.engine.DefaultOffer) {
+		return c.engine.DefaultOffer
+	}
 	return offered[0]
 }
 
+// SetAccepted sets the formats NegotiateFormat negotiates with instead of
+// the Accept header
+func (c *Context) SetAccepted(formats ...string) {
+	c.Accepted = formats
+}
+
+// mediaRange is a media range of an Accept header
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+const maxAcceptCache = 512
+
+var (
+	acceptCacheMu sync.RWMutex
+	acceptCache   = map[string][]mediaRange{}
+)
+
+// parseAccept parses an Accept header. Clients send few distinct headers,
+// so parsed headers are cached.
+func parseAccept(header string) []mediaRange {
+	acceptCacheMu.RLock()
+	ranges, ok := acceptCache[header]
+	acceptCacheMu.RUnlock()
+	if ok {
+		return ranges
+	}
+
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		if !ok {
+			if typ != "*" {
+				continue
+			}
+			subtype = "*" // some clients send a bare "*"
+		}
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(v, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	acceptCacheMu.Lock()
+	if len(acceptCache) >= maxAcceptCache {
+		clear(acceptCache)
+	}
+	acceptCache[header] = ranges
+	acceptCacheMu.Unlock()
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching
+// offer, or 0 when none matches
+func acceptQuality(ranges []mediaRange, offer string) float64 {
+	mediaType, _, _ := strings.Cut(offer, ";")
+	typ, subtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
 func chooseData(custom, wildcard interface{}) interface{} {
 	if custom != nil {
 		return custom
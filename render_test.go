@@ -1,6 +1,8 @@
 package goTap
 
 import (
+	"bytes"
+	"encoding/csv"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
@@ -270,3 +272,93 @@ func BenchmarkNegotiation(b *testing.B) {
 		router.ServeHTTP(w, req)
 	}
 }
+
+// csvRenderer is a third-party encoder plugged in through Renderer
+type csvRenderer struct {
+	rows [][]string
+}
+
+func (r csvRenderer) ContentType() string { return "text/csv" }
+
+func (r csvRenderer) Encode(buf *bytes.Buffer) error {
+	return csv.NewWriter(buf).WriteAll(r.rows)
+}
+
+func TestRenderer(t *testing.T) {
+	router := New()
+	router.GET("/report.csv", func(c *Context) {
+		c.Render(http.StatusOK, csvRenderer{rows: [][]string{{"sku", "qty"}, {"C-1", "3"}}})
+	})
+	router.GET("/events", func(c *Context) {
+		c.Render(http.StatusOK, SSEvent{Event: "receipt", ID: "7", Retry: 3000, Data: H{"total": 9}})
+		c.Render(-1, SSEvent{Data: "done"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/report.csv", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "text/csv" || w.Body.String() != "sku,qty\nC-1,3\n" {
+		t.Errorf("Unexpected CSV response %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if want := "event: receipt\nid: 7\nretry: 3000\ndata: map[total:9]\n\ndata: done\n\n"; w.Body.String() != want {
+		t.Errorf("Expected %q, got %q", want, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("Expected the event stream headers, got %v", w.Header())
+	}
+}
+
+func TestRenderersReuseBuffers(t *testing.T) {
+	router := New()
+	router.GET("/ascii", func(c *Context) {
+		c.AsciiJSON(http.StatusOK, H{"name": "Café ☕"})
+	})
+	router.GET("/indented", func(c *Context) {
+		c.IndentedJSON(http.StatusOK, H{"a": 1})
+	})
+	router.GET("/json", func(c *Context) {
+		c.JSON(http.StatusOK, H{"a": 1})
+	})
+	router.GET("/xml", func(c *Context) {
+		c.XML(http.StatusOK, struct {
+			XMLName struct{} `xml:"receipt"`
+			ID      string   `xml:"id"`
+		}{ID: "R-1"})
+	})
+
+	// Repeat so pooled encoders are reused with their settings reset
+	for i := 0; i < 3; i++ {
+		for path, want := range map[string]string{
+			"/ascii":    `{"name":"Caf\u00e9 \u2615"}`,
+			"/indented": "{\n    \"a\": 1\n}",
+			"/json":     "{\"a\":1}\n",
+			"/xml":      "<receipt>\n  <id>R-1</id>\n</receipt>",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Body.String() != want {
+				t.Errorf("%s: expected %q, got %q", path, want, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestContextKeysReused(t *testing.T) {
+	router := New()
+	var copies []*Context
+	router.GET("/keys", func(c *Context) {
+		if _, ok := c.Get("previous"); ok {
+			t.Error("Expected the keys of the previous request to be cleared")
+		}
+		c.Set("previous", c.Query("n"))
+		copies = append(copies, c.Copy())
+	})
+	for _, n := range []string{"1", "2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/keys?n="+n, nil))
+	}
+	if v, _ := copies[0].Get("previous"); v != "1" {
+		t.Errorf("Expected a copy to keep its keys, got %v", v)
+	}
+}
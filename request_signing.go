@@ -0,0 +1,212 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request signing headers
+const (
+	SignatureKeyIDHeader     = "X-Signature-Key"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+	SignatureHeader          = "X-Signature"
+)
+
+// SigningKeyIDKey is the context key holding the key ID of a request
+// verified by SignedRequests
+const SigningKeyIDKey = "gotap.signing_key_id"
+
+// Request signing errors
+var (
+	ErrMissingRequestSignature = errors.New("missing request signature")
+	ErrInvalidRequestSignature = errors.New("invalid request signature")
+	ErrRequestTimestamp        = errors.New("request timestamp outside tolerance")
+	ErrRequestReplayed         = errors.New("request nonce already used")
+)
+
+// SigningKeyLookup returns the secret of a signing key, e.g. a terminal's.
+// An error rejects the request.
+type SigningKeyLookup func(keyID string) (secret string, err error)
+
+// SignedRequestsConfig holds configuration for the SignedRequests middleware
+type SignedRequestsConfig struct {
+	// KeyLookup returns the secret of the request's key ID
+	KeyLookup SigningKeyLookup
+
+	// Tolerance is the maximum clock difference between client and server
+	// Default: 5 minutes
+	Tolerance time.Duration
+
+	// NonceStore remembers used nonces for twice the Tolerance
+	// Default: Engine.KVStore if set, else an in-memory store
+	NonceStore KVStore
+}
+
+// SignedRequests returns a middleware authenticating requests signed with
+// SignRequest by a shared HMAC key, rejecting unsigned, tampered, stale or
+// replayed requests with 401:
+//
+//	terminals := r.Group("/terminal", goTap.SignedRequests(func(keyID string) (string, error) {
+//	    return lookupTerminalSecret(keyID)
+//	}))
+//	terminals.POST("/sales", func(c *goTap.Context) {
+//	    terminalID := goTap.GetSigningKeyID(c)
+//	})
+//
+// The signature covers the method, path with query, timestamp, nonce and
+// a SHA-256 of the body.
+func SignedRequests(keyLookup SigningKeyLookup) HandlerFunc {
+	return SignedRequestsWithConfig(SignedRequestsConfig{KeyLookup: keyLookup})
+}
+
+// SignedRequestsWithConfig returns a SignedRequests middleware with config
+func SignedRequestsWithConfig(config SignedRequestsConfig) HandlerFunc {
+	if config.KeyLookup == nil {
+		panic("goTap: SignedRequests requires a KeyLookup")
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = 5 * time.Minute
+	}
+	var memory KVStore
+	if config.NonceStore == nil {
+		memory = NewMemoryKVStore()
+	}
+
+	return func(c *Context) {
+		keyID, err := c.verifyRequestSignature(config, memory)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, H{
+				"error":   "Unauthorized",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		c.Set(SigningKeyIDKey, keyID)
+		c.Next()
+	}
+}
+
+// GetSigningKeyID returns the key ID of a request verified by
+// SignedRequests
+func GetSigningKeyID(c *Context) string {
+	keyID, _ := c.Get(SigningKeyIDKey)
+	s, _ := keyID.(string)
+	return s
+}
+
+func (c *Context) verifyRequestSignature(config SignedRequestsConfig, memory KVStore) (string, error) {
+	keyID := c.GetHeader(SignatureKeyIDHeader)
+	timestamp := c.GetHeader(SignatureTimestampHeader)
+	nonce := c.GetHeader(SignatureNonceHeader)
+	signature := c.GetHeader(SignatureHeader)
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return "", ErrMissingRequestSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidRequestSignature
+	}
+	if age := time.Since(time.Unix(ts, 0)); math.Abs(float64(age)) > float64(config.Tolerance) {
+		return "", ErrRequestTimestamp
+	}
+
+	secret, err := config.KeyLookup(keyID)
+	if err != nil || secret == "" {
+		return "", ErrInvalidRequestSignature
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", ErrInvalidRequestSignature
+	}
+	body, err := c.BodyBytes()
+	if err != nil {
+		return "", err
+	}
+	expected := webhookHMAC(secret, canonicalRequest(c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body))
+	if !hmac.Equal(sig, expected) {
+		return "", ErrInvalidRequestSignature
+	}
+
+	// Only signed nonces are remembered, so forged requests can't fill
+	// the store
+	store := config.NonceStore
+	if store == nil {
+		if store = c.kvStore(); store == nil {
+			store = memory
+		}
+	}
+	fresh, err := store.SetNX(c.Request.Context(), "signed_nonce:"+keyID+":"+nonce, []byte(timestamp), 2*config.Tolerance)
+	if err != nil {
+		return "", err
+	}
+	if !fresh {
+		return "", ErrRequestReplayed
+	}
+	return keyID, nil
+}
+
+// canonicalRequest is the signed representation of a request:
+// method, path with query, timestamp, nonce and hex SHA-256 of the body,
+// separated by newlines
+func canonicalRequest(method, uri, timestamp, nonce string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(strings.Join([]string{
+		strings.ToUpper(method), uri, timestamp, nonce, hex.EncodeToString(sum[:]),
+	}, "\n"))
+}
+
+// SignRequest signs req for SignedRequests with the key keyID, e.g. on a
+// POS terminal:
+//
+//	req, _ := http.NewRequest("POST", server+"/terminal/sales", bytes.NewReader(payload))
+//	if err := goTap.SignRequest(req, terminalID, terminalSecret); err != nil {
+//	    return err
+//	}
+//	resp, err := http.DefaultClient.Do(req)
+//
+// The body is read and replaced, so req can still be sent.
+func SignRequest(req *http.Request, keyID, secret string) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+	signature := webhookHMAC(secret, canonicalRequest(req.Method, req.URL.RequestURI(), timestamp, nonceHex, body))
+
+	req.Header.Set(SignatureKeyIDHeader, keyID)
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, nonceHex)
+	req.Header.Set(SignatureHeader, hex.EncodeToString(signature))
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedRequests(t *testing.T) {
+	r := New()
+	r.Use(SignedRequests(func(keyID string) (string, error) {
+		if keyID != "terminal-7" {
+			return "", errors.New("unknown terminal")
+		}
+		return "terminal-secret", nil
+	}))
+	r.POST("/sales", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, GetSigningKeyID(c)+":"+string(body))
+	})
+
+	signed := func(keyID, secret, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/sales?store=1", strings.NewReader(body))
+		if err := SignRequest(req, keyID, secret); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	req := signed("terminal-7", "terminal-secret", `{"total":1250}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"total":1250}`))
+	if w := serve(req); w.Code != http.StatusOK || w.Body.String() != `terminal-7:{"total":1250}` {
+		t.Fatalf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(replay); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), ErrRequestReplayed.Error()) {
+		t.Errorf("Expected the replay to be rejected, got %d %s", w.Code, w.Body.String())
+	}
+
+	tampered := signed("terminal-7", "terminal-secret", `{"total":1250}`)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"total":1}`))
+	if w := serve(tampered); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a tampered body to be rejected, got %d", w.Code)
+	}
+	moved := signed("terminal-7", "terminal-secret", "")
+	moved.URL.RawQuery = "store=2"
+	if w := serve(moved); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a changed query to be rejected, got %d", w.Code)
+	}
+	if w := serve(signed("terminal-7", "wrong-secret", "")); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong secret to be rejected, got %d", w.Code)
+	}
+	if w := serve(signed("terminal-8", "terminal-secret", "")); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown key to be rejected, got %d", w.Code)
+	}
+	if w := serve(httptest.NewRequest("POST", "/sales", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unsigned request to be rejected, got %d", w.Code)
+	}
+
+	stale := signed("terminal-7", "terminal-secret", "")
+	stale.Header.Set(SignatureTimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+	if w := serve(stale); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), ErrRequestTimestamp.Error()) {
+		t.Errorf("Expected a stale request to be rejected, got %d %s", w.Code, w.Body.String())
+	}
+}
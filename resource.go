@@ -0,0 +1,507 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ResourceAction identifies an operation registered by Resource
+type ResourceAction string
+
+// Resource actions
+const (
+	ActionList   ResourceAction = "list"
+	ActionGet    ResourceAction = "get"
+	ActionCreate ResourceAction = "create"
+	ActionUpdate ResourceAction = "update"
+	ActionDelete ResourceAction = "delete"
+
+	// ActionRestore and ActionPurge are the ResourceTrash actions
+	ActionRestore ResourceAction = "restore"
+	ActionPurge   ResourceAction = "purge"
+)
+
+// ResourceConfig holds configuration for Resource
+type ResourceConfig struct {
+	// DB is used when no database was injected with GormInject
+	DB *DB
+
+	// Actions limits the routes that are registered
+	// Default: all actions
+	Actions []ResourceAction
+
+	// Pagination configures paging of the list route
+	// Default: DefaultPaginationConfig()
+	Pagination PaginationConfig
+
+	// FilterFields are JSON field names accepted as equality filters on the
+	// list route, e.g. GET /products?category=books&category=music
+	// Default: none
+	FilterFields []string
+
+	// SortFields are JSON field names accepted by the sort parameter,
+	// e.g. GET /products?sort=-price,name
+	// Default: none
+	SortFields []string
+
+	// DefaultSort is used when the client does not send a sort parameter
+	// Default: primary key ascending
+	DefaultSort string
+
+	// Preload lists associations loaded by the list and get routes
+	Preload []string
+
+	// ReadOnlyFields are JSON field names clients cannot set on create or update.
+	// The primary key and auto-managed timestamps are always read-only.
+	ReadOnlyFields []string
+
+	// HiddenFields are JSON field names removed from every response
+	HiddenFields []string
+
+	// Scope restricts every query, e.g. to the current tenant or owner
+	Scope func(c *Context, db *DB) *DB
+
+	// Authorize is called before each action with the record being read,
+	// created or deleted (nil for list). Updates are authorized against the
+	// stored record, before the request body is applied. Returning an error
+	// aborts the request with 403.
+	Authorize func(c *Context, action ResourceAction, record any) error
+
+	// AuthorizeUpdate is called after Authorize with the stored record and
+	// the record with the request body applied, for policies that restrict
+	// the new values, e.g. moving a product to another store. Returning an
+	// error aborts the request with 403.
+	// Optional.
+	AuthorizeUpdate func(c *Context, stored, updated any) error
+
+	// Serialize converts a record before it is rendered, for field-level
+	// control beyond HiddenFields and the Redaction middleware
+	Serialize func(c *Context, record any) any
+}
+
+// Resource registers GORM-backed CRUD routes for model T on group:
+//
+//	GET    /          list with pagination, filtering and sorting
+//	GET    /:id       get one record
+//	POST   /          create
+//	PUT    /:id       update the fields present in the body (PATCH is an alias)
+//	DELETE /:id       delete (soft delete when T has a DeletedAt field)
+//
+// When T embeds VersionedModel, updates must match the stored version or
+// fail with 409 Conflict.
+//
+// Example:
+//
+//	goTap.Resource[Product](r.Group("/products"), goTap.ResourceConfig{
+//	    FilterFields: []string{"category", "is_active"},
+//	    SortFields:   []string{"price", "name"},
+//	})
+func Resource[T any](group *RouterGroup, config ResourceConfig) *RouterGroup {
+	if config.Actions == nil {
+		config.Actions = []ResourceAction{ActionList, ActionGet, ActionCreate, ActionUpdate, ActionDelete}
+	}
+
+	res := &resource[T]{config: config, sortable: make(map[string]bool)}
+	for _, name := range config.SortFields {
+		res.sortable[name] = true
+	}
+	for _, key := range strings.Split(config.DefaultSort, ",") {
+		res.sortable[strings.TrimPrefix(key, "-")] = true
+	}
+	for _, action := range config.Actions {
+		switch action {
+		case ActionList:
+			group.GET("", PaginateWithConfig(config.Pagination), res.list)
+		case ActionGet:
+			group.GET("/:id", res.get)
+		case ActionCreate:
+			group.POST("", res.create)
+		case ActionUpdate:
+			group.PUT("/:id", res.update)
+			group.PATCH("/:id", res.update)
+		case ActionDelete:
+			group.DELETE("/:id", res.delete)
+		default:
+			panic("goTap: unknown resource action " + string(action))
+		}
+	}
+	return group
+}
+
+type resource[T any] struct {
+	config   ResourceConfig
+	sortable map[string]bool
+}
+
+// resourceSchema describes the columns of T addressable by JSON name
+type resourceSchema struct {
+	primary  *schema.Field
+	fields   map[string]*schema.Field // JSON name -> field
+	readOnly []*schema.Field
+	updated  []*schema.Field
+}
+
+func (res *resource[T]) db(c *Context) (*DB, *resourceSchema, bool) {
+	db, ok := GetGorm(c)
+	if !ok {
+		db = res.config.DB
+	}
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, H{
+			"error":   "Internal Server Error",
+			"message": "Database not configured",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		c.JSON(http.StatusInternalServerError, H{
+			"error":   "Internal Server Error",
+			"message": "Resource model has no primary key",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	s := &resourceSchema{
+		primary: stmt.Schema.PrioritizedPrimaryField,
+		fields:  make(map[string]*schema.Field),
+	}
+	for _, field := range stmt.Schema.Fields {
+		name := jsonFieldName(field.StructField)
+		if name == "" || field.DBName == "" {
+			continue
+		}
+		s.fields[name] = field
+
+		readOnly := field.PrimaryKey || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 ||
+			field.FieldType == reflect.TypeOf(gorm.DeletedAt{})
+		for _, ro := range res.config.ReadOnlyFields {
+			readOnly = readOnly || ro == name
+		}
+		if readOnly {
+			s.readOnly = append(s.readOnly, field)
+		}
+		if field.AutoUpdateTime > 0 {
+			s.updated = append(s.updated, field)
+		}
+	}
+
+	db = db.WithContext(c.Request.Context()).Model(new(T))
+	if res.config.Scope != nil {
+		db = res.config.Scope(c, db)
+	}
+	// A new session lets each action chain further conditions safely
+	return db.Session(&gorm.Session{}), s, true
+}
+
+func (res *resource[T]) list(c *Context) {
+	db, s, ok := res.db(c)
+	if !ok || !res.authorize(c, ActionList, nil) {
+		return
+	}
+
+	for _, name := range res.config.FilterFields {
+		values := c.QueryArray(name)
+		field, known := s.fields[name]
+		if len(values) == 0 || !known {
+			continue
+		}
+		column := clause.Column{Name: field.DBName}
+		if len(values) == 1 {
+			db = db.Where(clause.Eq{Column: column, Value: values[0]})
+		} else {
+			in := make([]any, len(values))
+			for i, v := range values {
+				in[i] = v
+			}
+			db = db.Where(clause.IN{Column: column, Values: in})
+		}
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		resourceError(c, err)
+		return
+	}
+
+	sort := c.DefaultQuery("sort", res.config.DefaultSort)
+	sorted := false
+	for _, key := range strings.Split(sort, ",") {
+		desc := strings.HasPrefix(key, "-")
+		name := strings.TrimPrefix(key, "-")
+		if field, known := s.fields[name]; known && res.sortable[name] {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: field.DBName}, Desc: desc})
+			sorted = true
+		}
+	}
+	if !sorted {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.primary.DBName}})
+	}
+
+	for _, assoc := range res.config.Preload {
+		db = db.Preload(assoc)
+	}
+
+	p, _ := GetPagination(c)
+	var records []T
+	if err := db.Offset(p.Offset()).Limit(p.Limit()).Find(&records).Error; err != nil {
+		resourceError(c, err)
+		return
+	}
+	p.SetTotal(total)
+
+	data := make([]any, len(records))
+	for i := range records {
+		data[i] = res.serialize(c, &records[i])
+	}
+	c.JSON(http.StatusOK, H{
+		"data":       data,
+		"pagination": p.Response(),
+	})
+}
+
+func (res *resource[T]) get(c *Context) {
+	db, s, ok := res.db(c)
+	if !ok {
+		return
+	}
+	for _, assoc := range res.config.Preload {
+		db = db.Preload(assoc)
+	}
+
+	record, ok := res.find(c, db, s)
+	if !ok || !res.authorize(c, ActionGet, record) {
+		return
+	}
+	c.JSON(http.StatusOK, res.serialize(c, record))
+}
+
+func (res *resource[T]) create(c *Context) {
+	db, s, ok := res.db(c)
+	if !ok {
+		return
+	}
+
+	record := new(T)
+	if err := c.ShouldBindJSON(record); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return
+	}
+	s.restoreReadOnly(c, reflect.ValueOf(record), reflect.ValueOf(new(T)))
+
+	if !res.authorize(c, ActionCreate, record) {
+		return
+	}
+	if err := db.Create(record).Error; err != nil {
+		resourceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res.serialize(c, record))
+}
+
+func (res *resource[T]) update(c *Context) {
+	db, s, ok := res.db(c)
+	if !ok {
+		return
+	}
+
+	record, ok := res.find(c, db, s)
+	if !ok || !res.authorize(c, ActionUpdate, record) {
+		return
+	}
+
+	body, err := c.GetRawData()
+	var present map[string]json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(body, &present)
+	}
+	original := reflect.New(reflect.TypeOf(record).Elem())
+	original.Elem().Set(reflect.ValueOf(record).Elem())
+	if err == nil {
+		err = json.Unmarshal(body, record)
+	}
+	if err == nil {
+		err = validate(record)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return
+	}
+	s.restoreReadOnly(c, reflect.ValueOf(record), original)
+
+	if res.config.AuthorizeUpdate != nil {
+		if err := res.config.AuthorizeUpdate(c, original.Interface(), record); err != nil {
+			res.forbidden(c, err)
+			return
+		}
+	}
+
+	var columns []string
+	for name := range present {
+		if field, known := s.fields[name]; known && !s.isReadOnly(field) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(columns) > 0 {
+		for _, field := range s.updated {
+			columns = append(columns, field.DBName)
+		}
+		// Select forces zero values (false, 0, "") in the body to be written too
+		if _, versioned := any(record).(interface{ versioned() }); versioned {
+			// The version in the body must match, see VersionedModel
+			if version := s.fields["version"].DBName; !containsString(columns, version) {
+				columns = append(columns, version)
+			}
+			err = GormUpdateVersioned(db.Select(columns), record, record)
+		} else {
+			err = db.Model(record).Select(columns).Updates(record).Error
+		}
+		if err != nil {
+			resourceError(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, res.serialize(c, record))
+}
+
+func (res *resource[T]) delete(c *Context) {
+	db, s, ok := res.db(c)
+	if !ok {
+		return
+	}
+
+	record, ok := res.find(c, db, s)
+	if !ok || !res.authorize(c, ActionDelete, record) {
+		return
+	}
+	if err := db.Model(record).Delete(record).Error; err != nil {
+		resourceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// find loads the record addressed by the :id parameter
+func (res *resource[T]) find(c *Context, db *DB, s *resourceSchema) (*T, bool) {
+	record := new(T)
+	err := db.Where(clause.Eq{Column: clause.Column{Name: s.primary.DBName}, Value: c.Param("id")}).
+		First(record).Error
+	if err != nil {
+		resourceError(c, err)
+		return nil, false
+	}
+	return record, true
+}
+
+func (res *resource[T]) authorize(c *Context, action ResourceAction, record any) bool {
+	if res.config.Authorize == nil {
+		return true
+	}
+	if err := res.config.Authorize(c, action, record); err != nil {
+		res.forbidden(c, err)
+		return false
+	}
+	return true
+}
+
+func (res *resource[T]) forbidden(c *Context, err error) {
+	c.JSON(http.StatusForbidden, H{
+		"error":   "Forbidden",
+		"message": err.Error(),
+	})
+	c.Abort()
+}
+
+func (res *resource[T]) serialize(c *Context, record *T) any {
+	var out any = record
+	if res.config.Serialize != nil {
+		out = res.config.Serialize(c, record)
+	}
+	if len(res.config.HiddenFields) > 0 {
+		out = filterJSON(reflect.ValueOf(out), func(name string, _ *reflect.StructField) bool {
+			return !containsString(res.config.HiddenFields, name)
+		})
+	}
+	return out
+}
+
+// restoreReadOnly copies the read-only fields of src back into dst
+func (s *resourceSchema) restoreReadOnly(c *Context, dst, src reflect.Value) {
+	ctx := c.Request.Context()
+	dst, src = reflect.Indirect(dst), reflect.Indirect(src)
+	for _, field := range s.readOnly {
+		field.ReflectValueOf(ctx, dst).Set(field.ReflectValueOf(ctx, src))
+	}
+}
+
+func (s *resourceSchema) isReadOnly(field *schema.Field) bool {
+	for _, ro := range s.readOnly {
+		if ro == field {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceError(c *Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "Not Found",
+			"message": "Record not found",
+		})
+	} else if errors.Is(err, ErrStaleRecord) {
+		c.JSON(http.StatusConflict, H{
+			"error":   "Conflict",
+			"message": "Record was modified by another request",
+		})
+	} else {
+		c.JSON(http.StatusInternalServerError, H{
+			"error":   "Internal Server Error",
+			"message": err.Error(),
+		})
+	}
+	c.Abort()
+}
+
+// jsonFieldName returns the name encoding/json uses for field, or "" if it is skipped
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
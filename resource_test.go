@@ -0,0 +1,238 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+type resourceItem struct {
+	Model
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	Active   bool    `json:"active"`
+	Cost     float64 `json:"cost"`
+	OwnerID  uint    `json:"owner_id"`
+}
+
+func setupResourceDB(t *testing.T) *DB {
+	db, err := NewGormDB(&DBConfig{
+		Driver:       "sqlite",
+		DSN:          ":memory:",
+		MaxIdleConns: 1,
+		MaxOpenConns: 1,
+		LogLevel:     logger.Silent,
+	})
+	if err != nil {
+		t.Skipf("Skipping resource tests: sqlite not available (%v)", err)
+	}
+	if err := db.AutoMigrate(&resourceItem{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return db
+}
+
+func resourceRequest(r *Engine, method, path, body string) (*httptest.ResponseRecorder, map[string]any) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var out map[string]any
+	json.Unmarshal(w.Body.Bytes(), &out)
+	return w, out
+}
+
+func TestResourceCRUD(t *testing.T) {
+	db := setupResourceDB(t)
+	r := New()
+	r.Use(GormInject(db))
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{
+		HiddenFields:   []string{"cost"},
+		ReadOnlyFields: []string{"owner_id"},
+	})
+
+	w, item := resourceRequest(r, "POST", "/items", `{"id":99,"name":"Tea","price":2.5,"active":true,"cost":1,"owner_id":5}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if item["id"] == float64(99) || item["name"] != "Tea" {
+		t.Errorf("Expected client id to be ignored, got %v", item)
+	}
+	if _, ok := item["cost"]; ok {
+		t.Error("Expected cost to be hidden")
+	}
+	var stored resourceItem
+	db.First(&stored)
+	if stored.OwnerID != 0 || stored.Cost != 1 {
+		t.Errorf("Expected owner_id to be read-only and cost stored, got %+v", stored)
+	}
+
+	w, item = resourceRequest(r, "GET", "/items/1", "")
+	if w.Code != http.StatusOK || item["name"] != "Tea" {
+		t.Errorf("Expected to get Tea, got %d %v", w.Code, item)
+	}
+
+	// Zero values present in the body are written, absent fields are kept
+	w, item = resourceRequest(r, "PATCH", "/items/1", `{"active":false,"id":7}`)
+	if w.Code != http.StatusOK || item["active"] != false || item["name"] != "Tea" || item["id"] != float64(1) {
+		t.Errorf("Unexpected update result %d %v", w.Code, item)
+	}
+	db.First(&stored, 1)
+	if stored.Active || stored.Name != "Tea" {
+		t.Errorf("Expected active=false to be persisted, got %+v", stored)
+	}
+
+	w, _ = resourceRequest(r, "PUT", "/items/1", `not json`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid body, got %d", w.Code)
+	}
+
+	w, _ = resourceRequest(r, "DELETE", "/items/1", "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", w.Code)
+	}
+	w, _ = resourceRequest(r, "GET", "/items/1", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestResourceListFilterSortPaginate(t *testing.T) {
+	db := setupResourceDB(t)
+	for _, it := range []resourceItem{
+		{Name: "a", Category: "tea", Price: 3},
+		{Name: "b", Category: "coffee", Price: 1},
+		{Name: "c", Category: "tea", Price: 2},
+		{Name: "d", Category: "juice", Price: 5},
+	} {
+		db.Create(&it)
+	}
+
+	r := New()
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{
+		DB:           db,
+		Actions:      []ResourceAction{ActionList},
+		FilterFields: []string{"category"},
+		SortFields:   []string{"price"},
+		Pagination:   PaginationConfig{MaxPerPage: 2},
+	})
+
+	_, body := resourceRequest(r, "GET", "/items?category=tea&category=coffee&sort=-price&per_page=10", "")
+	data, _ := body["data"].([]any)
+	if len(data) != 2 {
+		t.Fatalf("Expected 2 items on the first page, got %v", body)
+	}
+	if data[0].(map[string]any)["name"] != "a" || data[1].(map[string]any)["name"] != "c" {
+		t.Errorf("Expected items sorted by price desc, got %v", data)
+	}
+	pagination := body["pagination"].(map[string]any)
+	if pagination["total"] != float64(3) || pagination["pages"] != float64(2) {
+		t.Errorf("Expected total=3 pages=2, got %v", pagination)
+	}
+
+	// Unknown sort fields are ignored instead of reaching SQL
+	w, body := resourceRequest(r, "GET", "/items?sort=name;DROP TABLE", "")
+	if w.Code != http.StatusOK || len(body["data"].([]any)) != 2 {
+		t.Errorf("Expected unknown sort to be ignored, got %d %v", w.Code, body)
+	}
+
+	w, _ = resourceRequest(r, "POST", "/items", `{}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected create route to be absent, got %d", w.Code)
+	}
+}
+
+func TestResourceScopeAndAuthorize(t *testing.T) {
+	db := setupResourceDB(t)
+	db.Create(&resourceItem{Name: "mine", OwnerID: 1})
+	db.Create(&resourceItem{Name: "theirs", OwnerID: 2})
+
+	r := New()
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{
+		DB: db,
+		Scope: func(c *Context, db *DB) *DB {
+			return db.Where("owner_id = ?", 1)
+		},
+		Authorize: func(c *Context, action ResourceAction, record any) error {
+			if action == ActionDelete {
+				return errors.New("items cannot be deleted")
+			}
+			return nil
+		},
+		Serialize: func(c *Context, record any) any {
+			return H{"label": strings.ToUpper(record.(*resourceItem).Name)}
+		},
+	})
+
+	_, body := resourceRequest(r, "GET", "/items", "")
+	data, _ := body["data"].([]any)
+	if len(data) != 1 || data[0].(map[string]any)["label"] != "MINE" {
+		t.Errorf("Expected only scoped, serialized item, got %v", body)
+	}
+
+	w, _ := resourceRequest(r, "GET", "/items/2", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected out-of-scope record to be 404, got %d", w.Code)
+	}
+
+	w, _ = resourceRequest(r, "DELETE", "/items/1", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 from Authorize, got %d", w.Code)
+	}
+}
+
+func TestResourceAuthorizeUpdate(t *testing.T) {
+	db := setupResourceDB(t)
+	db.Create(&resourceItem{Name: "mine", OwnerID: 1})
+	db.Create(&resourceItem{Name: "theirs", OwnerID: 2})
+
+	r := New()
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{
+		DB: db,
+		// User 1 may only change their own items
+		Authorize: func(c *Context, action ResourceAction, record any) error {
+			if record != nil && record.(*resourceItem).OwnerID != 1 {
+				return errors.New("not your item")
+			}
+			return nil
+		},
+		AuthorizeUpdate: func(c *Context, stored, updated any) error {
+			if updated.(*resourceItem).OwnerID != stored.(*resourceItem).OwnerID {
+				return errors.New("items cannot change owner")
+			}
+			return nil
+		},
+	})
+
+	// The stored owner is checked, not the one written in the body
+	w, _ := resourceRequest(r, "PUT", "/items/2", `{"name":"stolen","owner_id":1}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 updating another owner's item, got %d", w.Code)
+	}
+	w, _ = resourceRequest(r, "PUT", "/items/1", `{"owner_id":2}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 from AuthorizeUpdate, got %d", w.Code)
+	}
+
+	var item resourceItem
+	db.First(&item, 2)
+	if item.Name != "theirs" || item.OwnerID != 2 {
+		t.Errorf("Forbidden update was written: %+v", item)
+	}
+
+	w, body := resourceRequest(r, "PUT", "/items/1", `{"name":"renamed"}`)
+	if w.Code != http.StatusOK || body["name"] != "renamed" {
+		t.Errorf("Expected update of own item, got %d %v", w.Code, body)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// softDeleteSchema returns the primary key and DeletedAt field of model
+func softDeleteSchema(db *gorm.DB, model interface{}) (primary, deletedAt *schema.Field, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			deletedAt = field
+			break
+		}
+	}
+	if deletedAt == nil {
+		return nil, nil, fmt.Errorf("%s has no gorm.DeletedAt field", stmt.Schema.Name)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, fmt.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+	return stmt.Schema.PrioritizedPrimaryField, deletedAt, nil
+}
+
+// trashed scopes db to the soft-deleted records of model
+func trashed(db *gorm.DB, model interface{}) (query *gorm.DB, primary, deletedAt *schema.Field, err error) {
+	primary, deletedAt, err = softDeleteSchema(db, model)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	query = db.Unscoped().Model(model).Where(clause.Neq{Column: clause.Column{Name: deletedAt.DBName}, Value: nil})
+	return query, primary, deletedAt, nil
+}
+
+// GormFindTrashed finds soft-deleted records, e.g. of a model embedding
+// goTap.Model:
+//
+//	var products []Product
+//	err := goTap.GormFindTrashed(db, &products, pagination)
+func GormFindTrashed(db *gorm.DB, dest interface{}, pagination *GormPagination, condition ...interface{}) error {
+	query, _, _, err := trashed(db, dest)
+	if err != nil {
+		return err
+	}
+	return GormFind(query, dest, pagination, condition...)
+}
+
+// GormRestore undoes the soft delete of the record of model with id. It
+// returns gorm.ErrRecordNotFound if no such record is deleted.
+//
+//	err := goTap.GormRestore(db, &Product{}, 42)
+func GormRestore(db *gorm.DB, model interface{}, id interface{}) error {
+	query, primary, deletedAt, err := trashed(db, model)
+	if err != nil {
+		return err
+	}
+	result := query.Where(clause.Eq{Column: clause.Column{Name: primary.DBName}, Value: id}).
+		UpdateColumn(deletedAt.DBName, nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GormPurge permanently deletes the soft-deleted record of model with id.
+// Records that aren't deleted are kept, so a purge can't skip the trash.
+func GormPurge(db *gorm.DB, model interface{}, id interface{}) error {
+	query, primary, _, err := trashed(db, model)
+	if err != nil {
+		return err
+	}
+	result := query.Where(clause.Eq{Column: clause.Column{Name: primary.DBName}, Value: id}).Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResourceTrash registers admin routes for the soft-deleted records of
+// model T on group:
+//
+//	GET    /              list deleted records with pagination
+//	POST   /:id/restore   restore a record
+//	DELETE /:id           permanently delete a record
+//
+// Example:
+//
+//	admin := r.Group("/admin", goTap.BasicAuth(accounts))
+//	goTap.ResourceTrash[Product](admin.Group("/trash/products"), goTap.ResourceConfig{})
+//
+// DB, Actions, Pagination, Scope, Authorize, Serialize and HiddenFields
+// apply as for Resource. Records are listed by deletion time, latest first.
+func ResourceTrash[T any](group *RouterGroup, config ResourceConfig) *RouterGroup {
+	if config.Actions == nil {
+		config.Actions = []ResourceAction{ActionList, ActionRestore, ActionPurge}
+	}
+
+	res := &resource[T]{config: config}
+	for _, action := range config.Actions {
+		switch action {
+		case ActionList:
+			group.GET("", PaginateWithConfig(config.Pagination), res.listTrashed)
+		case ActionRestore:
+			group.POST("/:id/restore", res.restore)
+		case ActionPurge:
+			group.DELETE("/:id", res.purge)
+		default:
+			panic("goTap: unknown trash action " + string(action))
+		}
+	}
+	return group
+}
+
+// trashed returns the request's database and the query for its
+// soft-deleted records
+func (res *resource[T]) trashed(c *Context) (db, query *DB, s *resourceSchema, deletedAt *schema.Field, ok bool) {
+	db, s, ok = res.db(c)
+	if !ok {
+		return nil, nil, nil, nil, false
+	}
+	query, _, deletedAt, err := trashed(db, new(T))
+	if err != nil {
+		resourceError(c, err)
+		return nil, nil, nil, nil, false
+	}
+	return db, query.Session(&gorm.Session{}), s, deletedAt, true
+}
+
+func (res *resource[T]) listTrashed(c *Context) {
+	_, query, _, deletedAt, ok := res.trashed(c)
+	if !ok || !res.authorize(c, ActionList, nil) {
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		resourceError(c, err)
+		return
+	}
+
+	p, _ := GetPagination(c)
+	var records []T
+	err := query.Order(clause.OrderByColumn{Column: clause.Column{Name: deletedAt.DBName}, Desc: true}).
+		Offset(p.Offset()).Limit(p.Limit()).Find(&records).Error
+	if err != nil {
+		resourceError(c, err)
+		return
+	}
+	p.SetTotal(total)
+
+	data := make([]any, len(records))
+	for i := range records {
+		data[i] = res.serialize(c, &records[i])
+	}
+	c.JSON(http.StatusOK, H{
+		"data":       data,
+		"pagination": p.Response(),
+	})
+}
+
+func (res *resource[T]) restore(c *Context) {
+	db, query, s, _, ok := res.trashed(c)
+	if !ok {
+		return
+	}
+	record, ok := res.find(c, query, s)
+	if !ok || !res.authorize(c, ActionRestore, record) {
+		return
+	}
+	if err := GormRestore(db, new(T), c.Param("id")); err != nil {
+		resourceError(c, err)
+		return
+	}
+	restored, ok := res.find(c, db, s)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, res.serialize(c, restored))
+}
+
+func (res *resource[T]) purge(c *Context) {
+	db, query, s, _, ok := res.trashed(c)
+	if !ok {
+		return
+	}
+	record, ok := res.find(c, query, s)
+	if !ok || !res.authorize(c, ActionPurge, record) {
+		return
+	}
+	if err := GormPurge(db, new(T), c.Param("id")); err != nil {
+		resourceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
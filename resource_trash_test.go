@@ -0,0 +1,119 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestGormRestoreAndPurge(t *testing.T) {
+	db := setupResourceDB(t)
+	items := []resourceItem{{Name: "Coffee"}, {Name: "Latte"}, {Name: "Bagel"}}
+	db.Create(&items)
+	db.Delete(&items[0])
+	db.Delete(&items[1])
+
+	var deleted []resourceItem
+	if err := GormFindTrashed(db, &deleted, nil); err != nil || len(deleted) != 2 {
+		t.Fatalf("Expected 2 deleted items, got %d %v", len(deleted), err)
+	}
+	if err := GormFindTrashed(db, &deleted, &GormPagination{Page: 1, PageSize: 10}, "name = ?", "Latte"); err != nil || len(deleted) != 1 {
+		t.Errorf("Expected the deleted latte, got %+v %v", deleted, err)
+	}
+
+	if err := GormRestore(db, &resourceItem{}, items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	var restored resourceItem
+	if err := db.First(&restored, items[0].ID).Error; err != nil || restored.DeletedAt.Valid {
+		t.Errorf("Expected the restored item, got %+v %v", restored, err)
+	}
+	if err := GormRestore(db, &resourceItem{}, items[2].ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected restoring a live item to fail, got %v", err)
+	}
+
+	if err := GormPurge(db, &resourceItem{}, items[2].ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected purging a live item to fail, got %v", err)
+	}
+	if err := GormPurge(db, &resourceItem{}, items[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Unscoped().Model(&resourceItem{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 items left, got %d", count)
+	}
+
+	type plain struct {
+		ID   uint
+		Name string
+	}
+	if err := GormRestore(db, &plain{}, 1); err == nil {
+		t.Error("Expected a model without DeletedAt to be rejected")
+	}
+}
+
+func TestResourceTrash(t *testing.T) {
+	db := setupResourceDB(t)
+	r := New()
+	r.Use(GormInject(db))
+	Resource[resourceItem](r.Group("/items"), ResourceConfig{})
+	ResourceTrash[resourceItem](r.Group("/admin/trash/items"), ResourceConfig{
+		HiddenFields: []string{"cost"},
+		Authorize: func(c *Context, action ResourceAction, record any) error {
+			if action == ActionPurge && c.GetHeader("X-Role") != "owner" {
+				return errors.New("only owners can purge")
+			}
+			return nil
+		},
+	})
+
+	for _, name := range []string{"Coffee", "Latte", "Bagel"} {
+		resourceRequest(r, "POST", "/items", `{"name":"`+name+`","cost":1}`)
+	}
+	resourceRequest(r, "DELETE", "/items/1", "")
+	resourceRequest(r, "DELETE", "/items/2", "")
+
+	w, body := resourceRequest(r, "GET", "/admin/trash/items", "")
+	data, _ := body["data"].([]any)
+	if w.Code != http.StatusOK || len(data) != 2 {
+		t.Fatalf("Expected 2 deleted items, got %d %v", w.Code, body)
+	}
+	// Latest deletion first
+	if first := data[0].(map[string]any); first["name"] != "Latte" || first["cost"] != nil || first["deleted_at"] == nil {
+		t.Errorf("Unexpected item %v", first)
+	}
+
+	if w, body := resourceRequest(r, "POST", "/admin/trash/items/1/restore", ""); w.Code != http.StatusOK || body["name"] != "Coffee" || body["deleted_at"] != nil {
+		t.Errorf("Expected the restored item, got %d %v", w.Code, body)
+	}
+	if w, _ := resourceRequest(r, "GET", "/items/1", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected the restored item to be visible, got %d", w.Code)
+	}
+	if w, _ := resourceRequest(r, "POST", "/admin/trash/items/3/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected a live item not to be in the trash, got %d", w.Code)
+	}
+
+	if w, _ := resourceRequest(r, "DELETE", "/admin/trash/items/2", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected the purge to be forbidden, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/admin/trash/items/2", nil)
+	req.Header.Set("X-Role", "owner")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected the purge, got %d %s", w.Code, w.Body.String())
+	}
+	var count int64
+	db.Unscoped().Model(&resourceItem{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 items left, got %d", count)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"syscall"
 )
 
 const (
@@ -43,6 +44,10 @@ type responseWriter struct {
 	http.ResponseWriter
 	size   int
 	status int
+
+	// engine provides the file copy buffers, tls disables sendfile
+	engine *Engine
+	tls    bool
 }
 
 var _ ResponseWriter = (*responseWriter)(nil)
@@ -96,6 +101,55 @@ func (w *responseWriter) Written() bool {
 	return w.size != noWritten
 }
 
+// ReadFrom implements io.ReaderFrom, which http.ServeContent uses to send
+// files. Files are handed to the connection so the kernel can send them
+// with sendfile; anything else is copied with a buffer of
+// Engine.FileBufferSize.
+func (w *responseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	w.WriteHeaderNow()
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok && !w.tls && canSendfile(r) {
+		n, err = rf.ReadFrom(r)
+	} else {
+		buf := w.engine.getFileBuffer()
+		// Hide ReaderFrom and WriterTo so the copy goes through buf
+		n, err = io.CopyBuffer(struct{ io.Writer }{w.ResponseWriter}, struct{ io.Reader }{r}, *buf)
+		w.engine.putFileBuffer(buf)
+	}
+	w.size += int(n)
+	return
+}
+
+// canSendfile reports whether r is backed by a file descriptor the
+// sendfile system call can read from
+func canSendfile(r io.Reader) bool {
+	if lr, ok := r.(*io.LimitedReader); ok {
+		r = lr.R
+	}
+	_, ok := r.(syscall.Conn)
+	return ok
+}
+
+// getFileBuffer returns a copy buffer of FileBufferSize bytes
+func (engine *Engine) getFileBuffer() *[]byte {
+	size := defaultFileBufferSize
+	if engine != nil && engine.FileBufferSize > 0 {
+		size = engine.FileBufferSize
+	}
+	if engine != nil {
+		if buf, ok := engine.fileBuffers.Get().(*[]byte); ok && len(*buf) == size {
+			return buf
+		}
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+func (engine *Engine) putFileBuffer(buf *[]byte) {
+	if engine != nil {
+		engine.fileBuffers.Put(buf)
+	}
+}
+
 // Hijack implements the http.Hijacker interface.
 func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if w.size < 0 {
@@ -6,7 +6,9 @@ package goTap
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
+	"strings"
 )
 
 var (
@@ -17,6 +19,16 @@ var (
 	}
 )
 
+// methodOrder sorts methods in the order of anyMethods, unknown ones last
+func methodOrder(m string) int {
+	for i, method := range anyMethods {
+		if method == m {
+			return i
+		}
+	}
+	return len(anyMethods)
+}
+
 // Param is a single URL parameter, consisting of a key and a value.
 type Param struct {
 	Key   string
@@ -51,7 +63,18 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 	assert1(method != "", "HTTP method can not be empty")
 	assert1(len(handlers) > 0, "there must be at least one handler")
 
-	debugPrint("%-6s %-25s --> %s\n", method, path, nameOfFunction(handlers.Last()))
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(*RouteConflictError); ok {
+				err.Method = method
+			}
+			panic(r)
+		}
+	}()
+	var shape string
+	if engine.StrictRoutes {
+		shape = engine.checkStrictRoute(method, path)
+	}
 
 	root := engine.trees.get(method)
 	if root == nil {
@@ -60,6 +83,14 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 		engine.trees = append(engine.trees, methodTree{method: method, root: root})
 	}
 	root.addRoute(path, handlers)
+	if shape != "" {
+		if engine.routeShapes == nil {
+			engine.routeShapes = make(map[string]string)
+		}
+		if _, ok := engine.routeShapes[shape]; !ok {
+			engine.routeShapes[shape] = method + " " + path
+		}
+	}
 
 	// Update maxParams
 	if paramsCount := countParams(path); paramsCount > engine.maxParams {
@@ -72,6 +103,100 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 	}
 }
 
+// RouteConflictError reports a route that can't be registered because a
+// segment overlaps an existing route, e.g. "/users/new" and "/users/:id".
+// Registration panics with it; TryHandle returns it.
+type RouteConflictError struct {
+	Method string
+	// Path is the route being registered
+	Path string
+	// Segment is the conflicting segment of Path
+	Segment string
+	// Existing is a registered route sharing the segment's position
+	Existing string
+	// ExistingSegment is the segment of Existing at that position
+	ExistingSegment string
+	// Reason explains why the segments can't coexist
+	Reason string
+}
+
+func (e *RouteConflictError) Error() string {
+	width := max(len(e.Path), len(e.Existing))
+	return fmt.Sprintf("route conflict: %s %s\n  new:      %-*s  segment %q\n  existing: %-*s  segment %q\n%s",
+		e.Method, e.Path, width, e.Path, e.Segment, width, e.Existing, e.ExistingSegment, e.Reason)
+}
+
+// newRouteConflict describes the conflict of path with the existing route at
+// byte offset off, where the two diverge
+func newRouteConflict(path string, off int, existing string) *RouteConflictError {
+	if off < len(path) && path[off] == '/' {
+		off++
+	}
+	start := strings.LastIndexByte(path[:off], '/') + 1
+	return &RouteConflictError{
+		Path:            path,
+		Segment:         pathSegment(path, start),
+		Existing:        existing,
+		ExistingSegment: pathSegment(existing, start),
+		Reason:          "a wildcard matches every value of its segment, so it can't share the position with static segments or other wildcards",
+	}
+}
+
+// pathSegment returns the segment of path starting at start
+func pathSegment(path string, start int) string {
+	if start >= len(path) {
+		return ""
+	}
+	segment := path[start:]
+	if i := strings.IndexByte(segment, '/'); i >= 0 && segment[0] != '*' {
+		segment = segment[:i]
+	}
+	return segment
+}
+
+// checkStrictRoute panics if path repeats a param name, or names the params
+// of a route registered for another method differently, e.g.
+// "GET /users/:id" and "DELETE /users/:user_id". It returns the shape of
+// path, with param names removed.
+func (engine *Engine) checkStrictRoute(method, path string) string {
+	var shape strings.Builder
+	seen := make(map[string]bool)
+	for i, segment := range strings.Split(path, "/") {
+		if i > 0 {
+			shape.WriteByte('/')
+		}
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			shape.WriteString(segment)
+			continue
+		}
+		if seen[segment[1:]] {
+			panic(&RouteConflictError{
+				Method: method, Path: path, Segment: segment, Existing: path, ExistingSegment: segment,
+				Reason: "param names must be unique within a route",
+			})
+		}
+		seen[segment[1:]] = true
+		shape.WriteByte(segment[0])
+	}
+
+	key := shape.String()
+	existing, ok := engine.routeShapes[key]
+	if !ok {
+		return key
+	}
+	if existingPath := existing[strings.IndexByte(existing, ' ')+1:]; existingPath != path {
+		start := 0
+		for start < len(path) && start < len(existingPath) && path[start] == existingPath[start] {
+			start++
+		}
+		conflict := newRouteConflict(path, start, existing)
+		conflict.ExistingSegment = pathSegment(existingPath, strings.LastIndexByte(path[:start], '/')+1)
+		conflict.Reason = "strict routes must name the params of a path the same for every method"
+		panic(conflict)
+	}
+	return key
+}
+
 type methodTree struct {
 	method string
 	root   *node
@@ -6,6 +6,7 @@ package goTap
 
 import (
 	"net/http"
+	"reflect"
 )
 
 // IRouter defines all router handle interface includes single and group router.
@@ -40,6 +41,24 @@ type RouterGroup struct {
 	basePath string
 	engine   *Engine
 	root     bool
+
+	// deprecation is set on groups created by Deprecated
+	deprecation *DeprecationConfig
+
+	// limits declared with Timeout and BodyLimit
+	limits routeLimits
+
+	// binds is the request type declared with Binds
+	binds reflect.Type
+
+	// engineHandlers is the number of Handlers from the engine's Use
+	engineHandlers int
+
+	// overrides replace registered middleware, or skip it when nil
+	overrides map[string]HandlerFunc
+
+	// priority is the request class declared with Priority
+	priority Priority
 }
 
 var _ IRouter = (*RouterGroup)(nil)
@@ -54,12 +73,33 @@ func (group *RouterGroup) Use(middleware ...HandlerFunc) IRoutes {
 // For example, all the routes that use a common middleware for authorization could be grouped.
 func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
 	return &RouterGroup{
-		Handlers: group.combineHandlers(handlers),
-		basePath: group.calculateAbsolutePath(relativePath),
-		engine:   group.engine,
+		Handlers:       group.combineHandlers(handlers),
+		basePath:       group.calculateAbsolutePath(relativePath),
+		engine:         group.engine,
+		deprecation:    group.deprecation,
+		limits:         group.limits,
+		binds:          group.binds,
+		engineHandlers: group.inheritedHandlers(),
+		overrides:      group.overrides,
+		priority:       group.priority,
 	}
 }
 
+// NoRoute sets the handlers for unmatched paths below the group, after the
+// group's middleware, e.g. JSON errors for an API group while the engine's
+// NoRoute renders an HTML page. The group with the longest matching prefix
+// wins. It returns a 404 code by default.
+func (group *RouterGroup) NoRoute(handlers ...HandlerFunc) {
+	group.engine.groupNoRoute = setGroupFallback(group.engine.groupNoRoute, group.basePath, group.combineHandlers(handlers))
+}
+
+// NoMethod sets the handlers for paths below the group matched by another
+// method when Engine.HandleMethodNotAllowed = true, after the group's
+// middleware. It returns a 405 code with an Allow header by default.
+func (group *RouterGroup) NoMethod(handlers ...HandlerFunc) {
+	group.engine.groupNoMethod = setGroupFallback(group.engine.groupNoMethod, group.basePath, group.combineHandlers(handlers))
+}
+
 // BasePath returns the base path of router group.
 // For example, if v := router.Group("/rest/n/v1/api"), v.BasePath() is "/rest/n/v1/api".
 func (group *RouterGroup) BasePath() string {
@@ -68,8 +108,18 @@ func (group *RouterGroup) BasePath() string {
 
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath)
-	handlers = group.combineHandlers(handlers)
+	handlers, middleware := group.routeHandlers(handlers)
 	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	if group.deprecation != nil {
+		group.engine.deprecations.register(httpMethod, absolutePath, *group.deprecation)
+	}
+	group.engine.declareRoute(httpMethod, absolutePath, routeMeta{
+		group:      group.basePath,
+		limits:     group.limits,
+		binds:      group.binds,
+		middleware: middleware,
+		priority:   group.priority,
+	})
 	return group.returnObj()
 }
 
@@ -86,6 +136,23 @@ func (group *RouterGroup) Handle(httpMethod, relativePath string, handlers ...Ha
 	return group.handle(httpMethod, relativePath, handlers)
 }
 
+// TryHandle registers a route like Handle, but returns a *RouteConflictError
+// instead of panicking when the path conflicts with a registered route, e.g.
+// for routes loaded from plugins or configuration at runtime.
+func (group *RouterGroup) TryHandle(httpMethod, relativePath string, handlers ...HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			conflict, ok := r.(*RouteConflictError)
+			if !ok {
+				panic(r)
+			}
+			err = conflict
+		}
+	}()
+	group.handle(httpMethod, relativePath, handlers)
+	return nil
+}
+
 // POST is a shortcut for router.Handle("POST", path, handlers).
 func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
 	return group.handle(http.MethodPost, relativePath, handlers)
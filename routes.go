@@ -0,0 +1,256 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteFormat is an output format of Engine.WriteRoutes
+type RouteFormat int
+
+const (
+	// RoutesText is the table printed at startup in debug mode
+	RoutesText RouteFormat = iota
+	// RoutesMarkdown renders a Markdown table per route group
+	RoutesMarkdown
+	// RoutesJSON renders the RouteInfo list
+	RoutesJSON
+	// RoutesTable renders an aligned table with a column per field
+	RoutesTable
+)
+
+// routeMeta holds what is known about a route at registration
+type routeMeta struct {
+	group      string
+	limits     routeLimits
+	binds      reflect.Type
+	middleware []string
+	priority   Priority
+}
+
+func (engine *Engine) declareRoute(method, path string, meta routeMeta) {
+	if engine.routeMeta == nil {
+		engine.routeMeta = make(map[string]routeMeta)
+	}
+	engine.routeMeta[method+" "+path] = meta
+}
+
+// describeRoutes returns Routes with group, middleware and metadata,
+// sorted by group and path
+func (engine *Engine) describeRoutes() RoutesInfo {
+	routes := engine.Routes()
+	for i := range routes {
+		route := &routes[i]
+		meta := engine.routeMeta[route.Method+" "+route.Path]
+		route.Group = meta.group
+		if meta.middleware != nil {
+			route.Middleware = meta.middleware
+		}
+		if route.Group == "" {
+			route.Group = "/"
+		}
+
+		metadata := make(map[string]string)
+		if meta.limits.timeout > 0 {
+			metadata["timeout"] = meta.limits.timeout.String()
+		}
+		if meta.limits.bodyLimit != "" {
+			metadata["body_limit"] = meta.limits.bodyLimit
+		}
+		if meta.binds != nil {
+			metadata["binds"] = meta.binds.String()
+		}
+		if meta.priority != PriorityNormal {
+			metadata["priority"] = meta.priority.String()
+		}
+		if d, ok := engine.deprecations.lookup(route.Method, route.Path); ok {
+			metadata["deprecated"] = "true"
+			if !d.Sunset.IsZero() {
+				metadata["sunset"] = d.Sunset.Format("2006-01-02")
+			}
+		}
+		if len(metadata) > 0 {
+			route.Metadata = metadata
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodOrder(a.Method) < methodOrder(b.Method)
+	})
+	return routes
+}
+
+// WriteRoutes writes the route table, grouped by router group prefix with
+// each route's middleware chain and metadata such as timeouts, body limits
+// and deprecation
+func (engine *Engine) WriteRoutes(w io.Writer, format RouteFormat) error {
+	routes := engine.describeRoutes()
+	switch format {
+	case RoutesJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	case RoutesMarkdown:
+		return writeRoutesMarkdown(w, routes)
+	case RoutesTable:
+		return writeRoutesTable(w, routes)
+	default:
+		return writeRoutesText(w, routes)
+	}
+}
+
+// PrintRoutes writes the routes as an aligned table of method, path,
+// handler and middleware chain, e.g. for a startup log or a CLI command.
+// WriteRoutes with RoutesJSON gives the same routes for docs tooling.
+func (engine *Engine) PrintRoutes(w io.Writer) error {
+	return engine.WriteRoutes(w, RoutesTable)
+}
+
+// WriteRoutesFile writes the route table to path, as Markdown for ".md",
+// JSON for ".json" and text otherwise
+func (engine *Engine) WriteRoutesFile(path string) error {
+	format := RoutesText
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		format = RoutesMarkdown
+	case ".json":
+		format = RoutesJSON
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := engine.WriteRoutes(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printRoutes prints the route table in debug mode and writes RoutesFile;
+// called when the server starts
+func (engine *Engine) printRoutes() {
+	if routes := engine.describeRoutes(); IsDebugging() && len(routes) > 0 {
+		var b strings.Builder
+		writeRoutesText(&b, routes)
+		for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+			debugPrint("%s", line)
+		}
+	}
+	if engine.RoutesFile != "" {
+		if err := engine.WriteRoutesFile(engine.RoutesFile); err != nil {
+			debugPrintError(fmt.Errorf("writing routes file: %w", err))
+		}
+	}
+}
+
+func writeRoutesText(w io.Writer, routes RoutesInfo) error {
+	group := ""
+	for i, route := range routes {
+		if i == 0 || route.Group != group {
+			group = route.Group
+			if _, err := fmt.Fprintf(w, "%s\n", group); err != nil {
+				return err
+			}
+		}
+		line := fmt.Sprintf("  %-7s %-30s --> %s", route.Method, route.Path, route.Handler)
+		if len(route.Middleware) > 0 {
+			line += " [" + strings.Join(route.Middleware, " > ") + "]"
+		}
+		if meta := formatRouteMetadata(route.Metadata); meta != "" {
+			line += " " + meta
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRoutesTable(w io.Writer, routes RoutesInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tMIDDLEWARE\tMETADATA")
+	for _, route := range routes {
+		middleware := strings.Join(route.Middleware, " > ")
+		if middleware == "" {
+			middleware = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler, middleware,
+			formatRouteMetadata(route.Metadata))
+	}
+	return tw.Flush()
+}
+
+func writeRoutesMarkdown(w io.Writer, routes RoutesInfo) error {
+	group := ""
+	for i, route := range routes {
+		if i == 0 || route.Group != group {
+			group = route.Group
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "## %s\n\n", group)
+			fmt.Fprintln(w, "| Method | Path | Handler | Middleware | Metadata |")
+			fmt.Fprintln(w, "|--------|------|---------|------------|----------|")
+		}
+		_, err := fmt.Fprintf(w, "| %s | `%s` | `%s` | %s | %s |\n", route.Method, route.Path, route.Handler,
+			strings.Join(route.Middleware, ", "), formatRouteMetadata(route.Metadata))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatRouteMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + metadata[k]
+	}
+	return strings.Join(parts, " ")
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// middlewareName returns a short name for a middleware, e.g. "Logger" for
+// the closure returned by goTap.LoggerWithConfig
+func middlewareName(f HandlerFunc) string {
+	name := nameOfFunction(f)
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	name = closureSuffix.ReplaceAllString(name, "")
+	name = strings.TrimSuffix(name, "WithConfig")
+	// Unexported constructors such as timeoutMiddleware show as "Timeout"
+	if trimmed := strings.TrimSuffix(name, "Middleware"); trimmed != "" && trimmed != name {
+		name = strings.ToUpper(trimmed[:1]) + trimmed[1:]
+	}
+	return name
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listReceipts(c *Context) {}
+
+func routeTableEngine() *Engine {
+	r := New()
+	r.Use(Logger())
+	r.GET("/health", func(c *Context) {})
+	api := r.Group("/api/v1", BasicAuth(Accounts{"admin": "secret"}))
+	api.GET("/receipts", listReceipts)
+	api.Timeout(2*time.Second).BodyLimit("256KB").POST("/payments", listReceipts)
+	r.Group("/v0").Deprecated(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), "").GET("/sales", listReceipts)
+	return r
+}
+
+func TestWriteRoutesText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := routeTableEngine().WriteRoutes(&buf, RoutesText); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"/\n  GET     /health",
+		"  GET     /api/v1/receipts               --> github.com/jaswant99k/gotap.listReceipts [Logger > BasicAuthForRealm]\n",
+		"/api/v1\n  POST    /api/v1/payments               --> github.com/jaswant99k/gotap.listReceipts [Logger > BasicAuthForRealm > Timeout > BodyLimit] body_limit=256KB timeout=2s",
+		"/v0\n  GET     /v0/sales",
+		"deprecated=true sunset=2026-01-31",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in route table:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteRoutesFile(t *testing.T) {
+	r := routeTableEngine()
+	dir := t.TempDir()
+
+	md := filepath.Join(dir, "ROUTES.md")
+	if err := r.WriteRoutesFile(md); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(md)
+	if !strings.Contains(string(data), "## /api/v1\n\n| Method | Path |") ||
+		!strings.Contains(string(data), "| GET | `/api/v1/receipts` |") {
+		t.Errorf("Unexpected Markdown:\n%s", data)
+	}
+
+	js := filepath.Join(dir, "routes.json")
+	if err := r.WriteRoutesFile(js); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(js)
+	var routes []RouteInfo
+	if err := json.Unmarshal(data, &routes); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 4 || routes[1].Path != "/api/v1/payments" || routes[1].Metadata["timeout"] != "2s" ||
+		routes[1].Group != "/api/v1" || len(routes[1].Middleware) != 4 {
+		t.Errorf("Unexpected JSON routes %+v", routes)
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := routeTableEngine().PrintRoutes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "METHOD  PATH") {
+		t.Fatalf("Unexpected table:\n%s", buf.String())
+	}
+	column := strings.Index(lines[0], "MIDDLEWARE")
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "POST") && line[column:] != "Logger > BasicAuthForRealm > Timeout > BodyLimit  body_limit=256KB timeout=2s" {
+			t.Errorf("Expected aligned columns, got:\n%s", buf.String())
+		}
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	for _, tt := range []struct {
+		existing, path, segment, existingSegment string
+	}{
+		{"/users/:id", "/users/new", "new", ":id"},
+		{"/users/new", "/users/:id", ":id", "new"},
+		{"/users/:id", "/users/:name/orders", ":name", ":id"},
+		{"/users/:id/orders", "/users/*all", "*all", ":id"},
+		{"/files/*path", "/files/readme", "readme", "*path"},
+	} {
+		r := New()
+		r.GET(tt.existing, func(c *Context) {})
+		err := r.TryHandle("GET", tt.path, func(c *Context) {})
+		conflict, ok := err.(*RouteConflictError)
+		if !ok {
+			t.Errorf("%s after %s: expected a conflict, got %v", tt.path, tt.existing, err)
+			continue
+		}
+		if conflict.Method != "GET" || conflict.Path != tt.path || conflict.Existing != tt.existing ||
+			conflict.Segment != tt.segment || conflict.ExistingSegment != tt.existingSegment {
+			t.Errorf("Unexpected conflict %+v", conflict)
+		}
+	}
+
+	defer func() {
+		err, _ := recover().(*RouteConflictError)
+		if err == nil || !strings.Contains(err.Error(), "new:      /users/new  segment \"new\"\n  existing: /users/:id  segment \":id\"") {
+			t.Errorf("Expected registration to panic with the conflict, got %v", err)
+		}
+	}()
+	r := New()
+	r.GET("/users/:id", func(c *Context) {})
+	r.GET("/users/new", func(c *Context) {})
+}
+
+func TestRouteSharedWildcard(t *testing.T) {
+	r := New()
+	r.GET("/users/:id/orders", func(c *Context) { c.String(200, "orders "+c.Param("id")) })
+	r.GET("/users/:id", func(c *Context) { c.String(200, "user "+c.Param("id")) })
+	r.GET("/users/:id/orders/:order", func(c *Context) { c.String(200, "order "+c.Param("order")) })
+	for path, want := range map[string]string{
+		"/users/5":          "user 5",
+		"/users/5/orders":   "orders 5",
+		"/users/5/orders/9": "order 9",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: expected %q, got %d %q", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStrictRoutes(t *testing.T) {
+	r := New()
+	r.StrictRoutes = true
+	r.GET("/users/:id", func(c *Context) {})
+	if err := r.TryHandle("DELETE", "/users/:id", func(c *Context) {}); err != nil {
+		t.Errorf("Expected the same params to be accepted, got %v", err)
+	}
+	err := r.TryHandle("PUT", "/users/:user_id", func(c *Context) {})
+	if conflict, ok := err.(*RouteConflictError); !ok || conflict.Existing != "GET /users/:id" || conflict.Segment != ":user_id" || conflict.ExistingSegment != ":id" {
+		t.Errorf("Expected a param name conflict, got %+v", err)
+	}
+	if err := r.TryHandle("GET", "/orders/:id/lines/:id", func(c *Context) {}); err == nil {
+		t.Error("Expected repeated param names to be rejected")
+	}
+
+	// Without strict routes, methods name params independently
+	r = New()
+	r.GET("/users/:id", func(c *Context) {})
+	if err := r.TryHandle("PUT", "/users/:user_id", func(c *Context) {}); err != nil {
+		t.Errorf("Expected no conflict, got %v", err)
+	}
+}
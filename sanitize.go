@@ -0,0 +1,221 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// SanitizerFunc cleans a string field, see RegisterSanitizer
+type SanitizerFunc func(s string) string
+
+var (
+	strictHTMLPolicy = bluemonday.StrictPolicy()
+
+	sanitizersMu sync.RWMutex
+	sanitizers   = map[string]SanitizerFunc{
+		"trim":       strings.TrimSpace,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"strip_html": StripHTML,
+		"escape":     html.EscapeString,
+	}
+	htmlPolicies = map[string]*bluemonday.Policy{
+		"": bluemonday.UGCPolicy(),
+	}
+)
+
+// RegisterSanitizer adds a sanitizer for "sanitize" tags, e.g.
+//
+//	goTap.RegisterSanitizer("digits", func(s string) string {
+//	    return strings.Map(func(r rune) rune {
+//	        if r >= '0' && r <= '9' {
+//	            return r
+//	        }
+//	        return -1
+//	    }, s)
+//	})
+func RegisterSanitizer(name string, fn SanitizerFunc) {
+	sanitizersMu.Lock()
+	defer sanitizersMu.Unlock()
+	sanitizers[name] = fn
+}
+
+// RegisterHTMLPolicy adds a bluemonday policy for rich-text fields tagged
+// `sanitize:"html=name"`. Without a name, "html" uses a policy allowing
+// common formatting, links and images but no scripts, styles or event
+// handlers; registering the name "" replaces it.
+func RegisterHTMLPolicy(name string, policy *bluemonday.Policy) {
+	sanitizersMu.Lock()
+	defer sanitizersMu.Unlock()
+	htmlPolicies[name] = policy
+}
+
+// StripHTML removes all HTML tags from s and decodes entities, leaving
+// plain text
+func StripHTML(s string) string {
+	// Decoding entities may reveal new tags, e.g. "&lt;script&gt;"
+	for range 4 {
+		stripped := html.UnescapeString(strictHTMLPolicy.Sanitize(s))
+		if stripped == s {
+			return s
+		}
+		s = stripped
+	}
+	return strictHTMLPolicy.Sanitize(s)
+}
+
+// noSanitize caches the struct types without "sanitize" tags
+var noSanitize sync.Map
+
+// Sanitize cleans the string fields of the struct obj points to by their
+// "sanitize" tags, including fields of nested structs, slices and
+// pointers. Rules are applied in order:
+//
+//	type ProductInput struct {
+//	    Name        string   `json:"name" sanitize:"trim,strip_html"`
+//	    SKU         string   `json:"sku" sanitize:"trim,upper"`
+//	    Email       string   `json:"email" sanitize:"trim,lower"`
+//	    Description string   `json:"description" sanitize:"html"`
+//	    Tags        []string `json:"tags" sanitize:"trim,lower"`
+//	}
+//
+// Built-in rules are trim, lower, upper, strip_html, escape and html, which
+// keeps safe rich text, or html=name for a policy registered with
+// RegisterHTMLPolicy. Bindings sanitize before validating, so rules like
+// required see the cleaned value.
+func Sanitize(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := noSanitize.Load(value.Type()); ok {
+		return nil
+	}
+	found, err := sanitizeStruct(value)
+	if err == nil && !found {
+		noSanitize.Store(value.Type(), true)
+	}
+	return err
+}
+
+// sanitizeStruct sanitizes the fields of value and reports whether it has
+// any "sanitize" tags
+func sanitizeStruct(value reflect.Value) (bool, error) {
+	found := false
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		structField := value.Field(i)
+		if !structField.CanSet() {
+			continue
+		}
+
+		tag, ok := typeField.Tag.Lookup("sanitize")
+		if !ok || tag == "-" {
+			nested, err := sanitizeNested(structField)
+			if err != nil {
+				return false, err
+			}
+			found = found || nested
+			continue
+		}
+		found = true
+
+		rules, err := sanitizeRules(tag)
+		if err != nil {
+			return false, fmt.Errorf("field '%s': %w", typeField.Name, err)
+		}
+		if !applySanitizers(structField, rules) {
+			return false, fmt.Errorf("field '%s': sanitize needs a string, not %s", typeField.Name, structField.Type())
+		}
+	}
+	return found, nil
+}
+
+// sanitizeNested sanitizes structs in value, which may be a struct, a
+// pointer to one or a slice of them
+func sanitizeNested(value reflect.Value) (bool, error) {
+	switch value.Kind() {
+	case reflect.Struct:
+		return sanitizeStruct(value)
+	case reflect.Ptr:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct {
+			return false, nil
+		}
+		return sanitizeStruct(value.Elem())
+	case reflect.Slice, reflect.Array:
+		found := false
+		for i := 0; i < value.Len(); i++ {
+			nested, err := sanitizeNested(value.Index(i))
+			if err != nil {
+				return false, err
+			}
+			found = found || nested
+		}
+		return found, nil
+	}
+	return false, nil
+}
+
+// applySanitizers cleans a string, string pointer or string slice and
+// reports whether value is one
+func applySanitizers(value reflect.Value, rules []SanitizerFunc) bool {
+	switch {
+	case value.Kind() == reflect.String:
+		s := value.String()
+		for _, rule := range rules {
+			s = rule(s)
+		}
+		value.SetString(s)
+	case value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.String:
+		if !value.IsNil() {
+			applySanitizers(value.Elem(), rules)
+		}
+	case (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < value.Len(); i++ {
+			applySanitizers(value.Index(i), rules)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func sanitizeRules(tag string) ([]SanitizerFunc, error) {
+	sanitizersMu.RLock()
+	defer sanitizersMu.RUnlock()
+	var rules []SanitizerFunc
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if policyName, ok := strings.CutPrefix(name, "html"); ok && (policyName == "" || policyName[0] == '=') {
+			policy, ok := htmlPolicies[strings.TrimPrefix(policyName, "=")]
+			if !ok {
+				return nil, fmt.Errorf("unknown HTML policy %q", name)
+			}
+			rules = append(rules, policy.Sanitize)
+			continue
+		}
+		rule, ok := sanitizers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sanitizer %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+type sanitizeVariant struct {
+	Label string `json:"label" sanitize:"trim,strip_html"`
+}
+
+type sanitizeProduct struct {
+	Name        string            `json:"name" sanitize:"trim,strip_html" validate:"required"`
+	SKU         string            `json:"sku" sanitize:"trim,upper"`
+	Email       *string           `json:"email" sanitize:"trim,lower"`
+	Description string            `json:"description" sanitize:"html"`
+	Tags        []string          `json:"tags" sanitize:"trim,lower"`
+	Variants    []sanitizeVariant `json:"variants"`
+	Raw         string            `json:"raw"`
+}
+
+func TestSanitizeBinding(t *testing.T) {
+	var p sanitizeProduct
+	r := New()
+	r.POST("/products", func(c *Context) {
+		p = sanitizeProduct{}
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	body := `{
+		"name": "  <b>Flat</b> white<script>alert(1)</script> ",
+		"sku": " fw-01 ",
+		"email": " Barista@Example.COM ",
+		"description": "<p onclick=\"steal()\">Smooth <em>espresso</em></p><script>alert(1)</script><a href=\"javascript:alert(1)\">x</a>",
+		"tags": [" Coffee ", "HOT"],
+		"variants": [{"label": " <i>Large</i> "}],
+		"raw": " <b>kept</b> "
+	}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/products", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d %s", w.Code, w.Body.String())
+	}
+	if p.Name != "Flat white" || p.SKU != "FW-01" || *p.Email != "barista@example.com" || p.Raw != " <b>kept</b> " {
+		t.Errorf("Unexpected product %+v", p)
+	}
+	if p.Description != "<p>Smooth <em>espresso</em></p>x" {
+		t.Errorf("Unexpected description %q", p.Description)
+	}
+	if strings.Join(p.Tags, ",") != "coffee,hot" || p.Variants[0].Label != "Large" {
+		t.Errorf("Unexpected tags %v or variants %v", p.Tags, p.Variants)
+	}
+
+	// Sanitizing happens before validation
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/products", strings.NewReader(`{"name": "<script>x</script>"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a name of only HTML to fail required, got %d", w.Code)
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := map[string]string{
+		"Tom &amp; Jerry":                       "Tom & Jerry",
+		"a < b":                                 "a < b",
+		"<p>Hi</p>":                             "Hi",
+		"&lt;script&gt;alert(1)&lt;/script&gt;": "",
+		"&amp;lt;b&amp;gt;x":                    "x",
+	}
+	for in, want := range tests {
+		if got := StripHTML(in); got != want {
+			t.Errorf("StripHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSanitizeCustom(t *testing.T) {
+	RegisterSanitizer("digits", func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+	})
+	RegisterHTMLPolicy("inline", bluemonday.NewPolicy().AllowElements("b", "i"))
+
+	var v struct {
+		Phone string `sanitize:"digits"`
+		Note  string `sanitize:"html=inline"`
+	}
+	v.Phone = "+1 (555) 010-9999"
+	v.Note = "<p><b>Bold</b> <a href='/'>link</a></p>"
+	if err := Sanitize(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Phone != "15550109999" || v.Note != "<b>Bold</b> link" {
+		t.Errorf("Unexpected result %+v", v)
+	}
+
+	var unknown struct {
+		Name string `sanitize:"nope"`
+	}
+	if err := Sanitize(&unknown); err == nil {
+		t.Error("Expected an unknown sanitizer to fail")
+	}
+	var notString struct {
+		Count int `sanitize:"trim"`
+	}
+	if err := Sanitize(&notString); err == nil {
+		t.Error("Expected a non-string field to fail")
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSchedulerStopped is returned when adding a job to a stopped scheduler
+var ErrSchedulerStopped = errors.New("scheduler stopped")
+
+// JobFunc is the function run by a scheduled job.
+// The context is canceled when the scheduler stops or the job times out.
+type JobFunc func(ctx context.Context)
+
+// JobConfig holds configuration for a scheduled job
+type JobConfig struct {
+	// Name identifies the job in logs and stats
+	// Default: the cron spec
+	Name string
+
+	// Jitter adds a random delay in [0, Jitter) before each run so that
+	// many instances don't hit shared resources at the same instant
+	Jitter time.Duration
+
+	// AllowOverlap lets a run start while the previous one is still running
+	// Default: false (the run is skipped)
+	AllowOverlap bool
+
+	// Timeout bounds a single run through its context
+	// Optional. Default: no timeout
+	Timeout time.Duration
+
+	// OnPanic is called when the job panics
+	// Default: logs the panic through debugPrint
+	OnPanic func(job *ScheduledJob, err any)
+}
+
+// JobStats holds run statistics for a scheduled job
+type JobStats struct {
+	Name    string    `json:"name"`
+	Spec    string    `json:"spec"`
+	LastRun time.Time `json:"last_run"`
+	NextRun time.Time `json:"next_run"`
+	Runs    int64     `json:"runs"`
+	Skipped int64     `json:"skipped"`
+	Panics  int64     `json:"panics"`
+	Running bool      `json:"running"`
+}
+
+// ScheduledJob is a job registered with a Scheduler
+type ScheduledJob struct {
+	spec     string
+	schedule cronSchedule
+	fn       JobFunc
+	config   JobConfig
+
+	mu      sync.Mutex
+	running bool
+	stats   JobStats
+}
+
+// Stats returns a snapshot of the job statistics
+func (j *ScheduledJob) Stats() JobStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	stats := j.stats
+	stats.Running = j.running
+	return stats
+}
+
+// Scheduler runs jobs on cron schedules until it is stopped
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []*ScheduledJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+	stopped bool
+}
+
+// NewScheduler creates a new scheduler. Jobs start running after Start.
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job with a cron spec.
+// Supported specs are standard five-field expressions ("0 2 * * *"),
+// the descriptors @yearly, @monthly, @weekly, @daily, @hourly and
+// "@every <duration>".
+func (s *Scheduler) Add(spec string, fn JobFunc, config ...JobConfig) (*ScheduledJob, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ScheduledJob{
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+	}
+	if len(config) > 0 {
+		job.config = config[0]
+	}
+	if job.config.Name == "" {
+		job.config.Name = spec
+	}
+	if job.config.OnPanic == nil {
+		job.config.OnPanic = func(job *ScheduledJob, err any) {
+			debugPrint("[WARNING] scheduled job %q panicked: %v", job.config.Name, err)
+		}
+	}
+	job.stats.Name = job.config.Name
+	job.stats.Spec = spec
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil, ErrSchedulerStopped
+	}
+	s.jobs = append(s.jobs, job)
+	if s.started {
+		s.launch(job)
+	}
+	return job, nil
+}
+
+// Start starts running registered jobs
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	for _, job := range s.jobs {
+		s.launch(job)
+	}
+}
+
+// Stop stops scheduling new runs, cancels the context of running jobs
+// and waits for them to return or for ctx to be done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Jobs returns the statistics of all registered jobs
+func (s *Scheduler) Jobs() []JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]JobStats, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		stats = append(stats, job.Stats())
+	}
+	return stats
+}
+
+func (s *Scheduler) launch(job *ScheduledJob) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			next := job.schedule.next(time.Now())
+			if job.config.Jitter > 0 {
+				next = next.Add(time.Duration(rand.Int63n(int64(job.config.Jitter))))
+			}
+			job.mu.Lock()
+			job.stats.NextRun = next
+			job.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			job.mu.Lock()
+			if job.running && !job.config.AllowOverlap {
+				job.stats.Skipped++
+				job.mu.Unlock()
+				continue
+			}
+			job.running = true
+			job.mu.Unlock()
+
+			s.wg.Add(1)
+			go s.run(job)
+		}
+	}()
+}
+
+func (s *Scheduler) run(job *ScheduledJob) {
+	defer s.wg.Done()
+
+	ctx := s.ctx
+	if job.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.config.Timeout)
+		defer cancel()
+	}
+
+	job.mu.Lock()
+	job.stats.LastRun = time.Now()
+	job.stats.Runs++
+	job.mu.Unlock()
+
+	defer func() {
+		if err := recover(); err != nil {
+			job.mu.Lock()
+			job.stats.Panics++
+			job.mu.Unlock()
+			job.config.OnPanic(job, err)
+		}
+		job.mu.Lock()
+		job.running = false
+		job.mu.Unlock()
+	}()
+
+	job.fn(ctx)
+}
+
+// Schedule registers a periodic task that runs for the lifetime of the engine.
+// The scheduler is started on first use and stopped when a server started
+// with RunServer is shut down (or when Stop is called on engine.Scheduler()).
+// It panics if the spec is invalid or the scheduler was already stopped.
+//
+//	r.Schedule("0 2 * * *", func(ctx context.Context) {
+//	    rollupDailyReports(ctx)
+//	})
+func (engine *Engine) Schedule(spec string, fn JobFunc, config ...JobConfig) *ScheduledJob {
+	job, err := engine.Scheduler().Add(spec, fn, config...)
+	if err != nil {
+		panic("goTap: cannot schedule " + strconv.Quote(spec) + ": " + err.Error())
+	}
+	return job
+}
+
+// Scheduler returns the engine scheduler, creating and starting it if needed
+func (engine *Engine) Scheduler() *Scheduler {
+	engine.servicesMu.Lock()
+	defer engine.servicesMu.Unlock()
+	if engine.scheduler == nil {
+		engine.scheduler = NewScheduler()
+		engine.scheduler.Start()
+	}
+	return engine.scheduler
+}
+
+// ========== Cron parsing ==========
+
+type cronSchedule interface {
+	next(t time.Time) time.Time
+}
+
+// everySchedule runs at a fixed interval
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronSpec is a parsed five-field cron expression stored as bitsets
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronBounds struct {
+	min, max int
+}
+
+var (
+	minuteBounds = cronBounds{0, 59}
+	hourBounds   = cronBounds{0, 23}
+	domBounds    = cronBounds{1, 31}
+	monthBounds  = cronBounds{1, 12}
+	dowBounds    = cronBounds{0, 7}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCron(spec string) (cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("interval must be positive")
+		}
+		return everySchedule{interval: d}, nil
+	}
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+
+	var s cronSpec
+	var err error
+	if s.minute, err = parseCronField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], domBounds); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], dowBounds); err != nil {
+		return nil, err
+	}
+	// Sunday may be written as 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return &s, nil
+}
+
+func parseCronField(field string, bounds cronBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:i]
+		}
+
+		start, end := bounds.min, bounds.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			r := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(r[0])
+			end, err2 = strconv.Atoi(r[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			start = v
+			if step == 1 {
+				end = v
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, fmt.Errorf("value out of range [%d-%d] in %q", bounds.min, bounds.max, field)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSpec) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Cron expressions repeat at least every few years
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return limit
+}
+
+func (s *cronSpec) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
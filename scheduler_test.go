@@ -0,0 +1,139 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	base := time.Date(2025, time.March, 14, 10, 30, 0, 0, time.UTC) // Friday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.March, 14, 10, 31, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2025, time.March, 15, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.March, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2025, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"30 6 1 * *", time.Date(2025, time.April, 1, 6, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := parseCron(tt.spec)
+		if err != nil {
+			t.Errorf("parseCron(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.next(base); !got.Equal(tt.want) {
+			t.Errorf("parseCron(%q).next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "a b c d e", "@every -1s"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) expected error", spec)
+		}
+	}
+}
+
+func TestEngineSchedule(t *testing.T) {
+	r := New()
+	var runs int32
+	job := r.Schedule("@every 20ms", func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+	})
+
+	time.Sleep(110 * time.Millisecond)
+	if err := r.Scheduler().Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	count := atomic.LoadInt32(&runs)
+	if count < 2 {
+		t.Errorf("Expected at least 2 runs, got %d", count)
+	}
+	if job.Stats().Runs != int64(count) {
+		t.Errorf("Expected stats to report %d runs, got %d", count, job.Stats().Runs)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != count {
+		t.Error("Job kept running after scheduler was stopped")
+	}
+}
+
+func TestSchedulerOverlapPreventionAndPanicRecovery(t *testing.T) {
+	s := NewScheduler()
+	s.Start()
+
+	slow, _ := s.Add("@every 10ms", func(ctx context.Context) {
+		<-ctx.Done()
+	}, JobConfig{Name: "slow"})
+
+	var panicked int32
+	s.Add("@every 10ms", func(ctx context.Context) {
+		panic("boom")
+	}, JobConfig{OnPanic: func(job *ScheduledJob, err any) {
+		atomic.AddInt32(&panicked, 1)
+	}})
+
+	time.Sleep(80 * time.Millisecond)
+
+	stats := slow.Stats()
+	if stats.Runs != 1 {
+		t.Errorf("Expected overlapping runs to be skipped, got %d runs", stats.Runs)
+	}
+	if stats.Skipped == 0 {
+		t.Error("Expected skipped runs to be counted")
+	}
+	if atomic.LoadInt32(&panicked) == 0 {
+		t.Error("Expected panic handler to be called")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop should cancel running jobs: %v", err)
+	}
+
+	if _, err := s.Add("@hourly", func(ctx context.Context) {}); err != ErrSchedulerStopped {
+		t.Errorf("Expected ErrSchedulerStopped, got %v", err)
+	}
+}
+
+func TestSchedulerStopsOnServerShutdown(t *testing.T) {
+	r := New()
+	r.Schedule("@hourly", func(ctx context.Context) {})
+
+	srv := r.RunServer("127.0.0.1:0")
+	if err := ShutdownWithTimeout(srv, time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s := r.Scheduler()
+		s.mu.Lock()
+		stopped := s.stopped
+		s.mu.Unlock()
+		if stopped {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expected scheduler to stop on server shutdown")
+}
@@ -0,0 +1,500 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSearchSyncStopped is returned by Backfill and ReindexAll after Stop
+var ErrSearchSyncStopped = errors.New("search sync stopped")
+
+// SearchDocument is the searchable form of a record
+type SearchDocument struct {
+	// ID identifies the document in every index
+	ID string
+	// Text is the content that is embedded or full-text indexed
+	Text string
+	// Fields are stored alongside the document, e.g. name and price
+	Fields map[string]any
+}
+
+// SearchIndex is a search backend kept in sync with model changes
+type SearchIndex interface {
+	// Upsert adds or replaces documents
+	Upsert(ctx context.Context, docs []SearchDocument) error
+	// Remove deletes documents by ID
+	Remove(ctx context.Context, ids []string) error
+}
+
+// SearchIndexClearer is implemented by indexes that can drop all documents.
+// ReindexAll clears such indexes before backfilling.
+type SearchIndexClearer interface {
+	Clear(ctx context.Context) error
+}
+
+// Embedder turns text into an embedding vector
+type Embedder interface {
+	Embed(ctx context.Context, text string) (Vector, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc func(ctx context.Context, text string) (Vector, error)
+
+// Embed calls f(ctx, text)
+func (f EmbedderFunc) Embed(ctx context.Context, text string) (Vector, error) {
+	return f(ctx, text)
+}
+
+// SearchSyncConfig holds configuration for SearchSync
+type SearchSyncConfig struct {
+	// Model is the model name whose events are followed, e.g. "product"
+	// (see RegisterModelEvents)
+	Model string
+
+	// Document converts a record into a search document. Returning false
+	// removes the record from the indexes, e.g. for inactive products.
+	// It runs synchronously when the model event is published, so the
+	// document is a snapshot of the record at that time; keep it cheap.
+	Document func(record any) (SearchDocument, bool)
+
+	// Indexes receive the documents
+	Indexes []SearchIndex
+
+	// Source iterates over all records for Backfill and ReindexAll,
+	// see GormSearchSource
+	Source func(ctx context.Context, yield func(record any) error) error
+
+	// BatchSize is the number of documents sent per Upsert during backfill
+	// Default: 100
+	BatchSize int
+
+	// Workers is the number of goroutines applying changes
+	// Default: 1, which keeps changes to one record in order
+	Workers int
+
+	// QueueSize bounds pending changes; events are dropped and counted when full
+	// Default: 1024
+	QueueSize int
+
+	// Retries is the number of extra attempts for a failed index write
+	// Default: 3
+	Retries int
+}
+
+// SearchSyncStats reports the activity of a SearchSync
+type SearchSyncStats struct {
+	Indexed int64 `json:"indexed"`
+	Removed int64 `json:"removed"`
+	Failed  int64 `json:"failed"`
+	Dropped int64 `json:"dropped"`
+}
+
+// SearchSync keeps search indexes up to date from model events
+type SearchSync struct {
+	config      SearchSyncConfig
+	bus         *EventBus
+	queue       chan searchChange
+	unsubscribe func()
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	started bool
+	stopped bool
+
+	indexed, removed, failed, dropped atomic.Int64
+}
+
+// NewSearchSync creates a search sync worker following config.Model events on bus
+func NewSearchSync(bus *EventBus, config SearchSyncConfig) *SearchSync {
+	if config.Model == "" || config.Document == nil {
+		panic("goTap: SearchSync requires Model and Document")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	} else if config.Retries == 0 {
+		config.Retries = 3
+	}
+
+	return &SearchSync{
+		config: config,
+		bus:    bus,
+		queue:  make(chan searchChange, config.QueueSize),
+	}
+}
+
+// searchChange is a queued index update
+type searchChange struct {
+	doc    SearchDocument
+	remove bool
+}
+
+// Start subscribes to model events and starts the workers
+func (s *SearchSync) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	s.unsubscribe = s.bus.Subscribe(s.config.Model+".*", func(ctx context.Context, e Event) {
+		event, ok := e.Payload.(ModelEvent)
+		if !ok {
+			return
+		}
+		change, ok := s.change(event)
+		if !ok {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stopped {
+			return
+		}
+		select {
+		case s.queue <- change:
+		default:
+			s.dropped.Add(1)
+			debugPrint("[WARNING] search sync queue for %s is full, dropping %s", s.config.Model, e.Name)
+		}
+	})
+
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for change := range s.queue {
+				if change.remove {
+					s.remove(context.Background(), []string{change.doc.ID})
+				} else {
+					s.upsert(context.Background(), []SearchDocument{change.doc})
+				}
+			}
+		}()
+	}
+}
+
+// Stop unsubscribes from events and waits for queued changes to be applied
+func (s *SearchSync) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	started := s.started
+	s.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	s.unsubscribe()
+	close(s.queue)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns sync counters
+func (s *SearchSync) Stats() SearchSyncStats {
+	return SearchSyncStats{
+		Indexed: s.indexed.Load(),
+		Removed: s.removed.Load(),
+		Failed:  s.failed.Load(),
+		Dropped: s.dropped.Load(),
+	}
+}
+
+// change converts a model event into an index update
+func (s *SearchSync) change(event ModelEvent) (searchChange, bool) {
+	var doc SearchDocument
+	keep := false
+	if event.Record != nil {
+		doc, keep = s.config.Document(event.Record)
+	}
+	if keep && event.Action != ModelDeleted {
+		return searchChange{doc: doc}, true
+	}
+
+	if event.ID != nil {
+		doc.ID = fmt.Sprint(event.ID)
+	}
+	return searchChange{doc: SearchDocument{ID: doc.ID}, remove: true}, doc.ID != ""
+}
+
+func (s *SearchSync) upsert(ctx context.Context, docs []SearchDocument) error {
+	var errs []error
+	for _, index := range s.config.Indexes {
+		if err := s.retry(ctx, func() error { return index.Upsert(ctx, docs) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		s.indexed.Add(int64(len(docs)))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SearchSync) remove(ctx context.Context, ids []string) error {
+	var errs []error
+	for _, index := range s.config.Indexes {
+		if err := s.retry(ctx, func() error { return index.Remove(ctx, ids) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		s.removed.Add(int64(len(ids)))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SearchSync) retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= s.config.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			}
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	s.failed.Add(1)
+	debugPrint("[WARNING] search sync for %s failed: %v", s.config.Model, err)
+	return err
+}
+
+// Backfill indexes every record produced by config.Source in batches.
+// It is safe to run while the sync is following events.
+func (s *SearchSync) Backfill(ctx context.Context) error {
+	if s.config.Source == nil {
+		return errors.New("search sync has no Source to backfill from")
+	}
+	s.mu.Lock()
+	stopped := s.stopped
+	s.mu.Unlock()
+	if stopped {
+		return ErrSearchSyncStopped
+	}
+
+	batch := make([]SearchDocument, 0, s.config.BatchSize)
+	var removed []string
+	flush := func() error {
+		if len(batch) > 0 {
+			if err := s.upsert(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		if len(removed) > 0 {
+			if err := s.remove(ctx, removed); err != nil {
+				return err
+			}
+			removed = removed[:0]
+		}
+		return nil
+	}
+
+	err := s.config.Source(ctx, func(record any) error {
+		if doc, ok := s.config.Document(record); ok {
+			batch = append(batch, doc)
+		} else if doc.ID != "" {
+			removed = append(removed, doc.ID)
+		}
+		if len(batch)+len(removed) >= s.config.BatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// ReindexAll clears the indexes that support it and backfills them
+func (s *SearchSync) ReindexAll(ctx context.Context) error {
+	for _, index := range s.config.Indexes {
+		if clearer, ok := index.(SearchIndexClearer); ok {
+			if err := clearer.Clear(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Backfill(ctx)
+}
+
+// SyncSearch starts a SearchSync on the engine event bus that is stopped
+// with the server:
+//
+//	goTap.RegisterModelEvents(db, r.Events(), &Product{})
+//	sync := r.SyncSearch(goTap.SearchSyncConfig{
+//	    Model:   "product",
+//	    Indexes: []goTap.SearchIndex{goTap.NewVectorSearchIndex(store, embedder)},
+//	    Source:  goTap.GormSearchSource[Product](db, 500),
+//	    Document: func(record any) (goTap.SearchDocument, bool) {
+//	        p := record.(*Product)
+//	        return goTap.SearchDocument{ID: fmt.Sprint(p.ID), Text: p.Description}, p.IsActive
+//	    },
+//	})
+func (engine *Engine) SyncSearch(config SearchSyncConfig) *SearchSync {
+	s := NewSearchSync(engine.Events(), config)
+	s.Start()
+	engine.addService("search sync for "+config.Model, s.Stop)
+	return s
+}
+
+// GormSearchSource returns a SearchSyncConfig.Source reading all T records
+// from db in batches. Records are passed as *T, like model events.
+func GormSearchSource[T any](db *DB, batchSize int) func(ctx context.Context, yield func(record any) error) error {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return func(ctx context.Context, yield func(record any) error) error {
+		var records []T
+		var yieldErr error
+		err := db.WithContext(ctx).FindInBatches(&records, batchSize, func(tx *DB, batch int) error {
+			for i := range records {
+				if yieldErr = yield(&records[i]); yieldErr != nil {
+					return yieldErr
+				}
+			}
+			return nil
+		}).Error
+		if yieldErr != nil {
+			return yieldErr
+		}
+		return err
+	}
+}
+
+// ========== Search indexes ==========
+
+// vectorSearchIndex stores documents as embeddings in a VectorStore
+type vectorSearchIndex struct {
+	store    VectorStore
+	embedder Embedder
+}
+
+// NewVectorSearchIndex returns a SearchIndex that embeds document text into
+// store. Documents whose text has not changed keep their existing vector, so
+// only changed descriptions are re-embedded.
+func NewVectorSearchIndex(store VectorStore, embedder Embedder) SearchIndex {
+	return &vectorSearchIndex{store: store, embedder: embedder}
+}
+
+func (v *vectorSearchIndex) Upsert(ctx context.Context, docs []SearchDocument) error {
+	for _, doc := range docs {
+		sum := sha256.Sum256([]byte(doc.Text))
+		hash := hex.EncodeToString(sum[:])
+
+		metadata := make(map[string]interface{}, len(doc.Fields)+1)
+		for k, val := range doc.Fields {
+			metadata[k] = val
+		}
+		metadata["_text_hash"] = hash
+
+		existing, err := v.store.Get(ctx, doc.ID)
+		if err == nil && existing != nil && existing.Metadata["_text_hash"] == hash {
+			if err := v.store.Update(ctx, &VectorDocument{ID: doc.ID, Vector: existing.Vector, Metadata: metadata}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		vector, err := v.embedder.Embed(ctx, doc.Text)
+		if err != nil {
+			return fmt.Errorf("failed to embed %s: %w", doc.ID, err)
+		}
+		vd := &VectorDocument{ID: doc.ID, Vector: vector, Metadata: metadata}
+		if existing != nil {
+			err = v.store.Update(ctx, vd)
+		} else {
+			err = v.store.Insert(ctx, []*VectorDocument{vd})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *vectorSearchIndex) Remove(ctx context.Context, ids []string) error {
+	return v.store.Delete(ctx, ids)
+}
+
+// mongoSearchIndex stores documents in a MongoDB collection with a text index
+type mongoSearchIndex struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSearchIndex returns a SearchIndex that writes documents to repo's
+// collection as {_id, text, ...fields}. Create a text index on "text"
+// (see MongoTextSearch.CreateTextIndex) to query it with $text.
+func NewMongoSearchIndex(repo *MongoRepository) SearchIndex {
+	return &mongoSearchIndex{collection: repo.collection}
+}
+
+func (m *mongoSearchIndex) Upsert(ctx context.Context, docs []SearchDocument) error {
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		record := bson.M{"_id": doc.ID, "text": doc.Text}
+		for k, v := range doc.Fields {
+			record[k] = v
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetReplacement(record).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := m.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (m *mongoSearchIndex) Remove(ctx context.Context, ids []string) error {
+	_, err := m.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func (m *mongoSearchIndex) Clear(ctx context.Context) error {
+	_, err := m.collection.DeleteMany(ctx, bson.M{})
+	return err
+}
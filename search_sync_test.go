@@ -0,0 +1,166 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+type searchProduct struct {
+	ID          uint
+	Name        string
+	Description string
+	Active      bool
+}
+
+func searchProductDocument(record any) (SearchDocument, bool) {
+	p := record.(*searchProduct)
+	return SearchDocument{
+		ID:     fmt.Sprint(p.ID),
+		Text:   p.Description,
+		Fields: map[string]any{"name": p.Name},
+	}, p.Active
+}
+
+// memorySearchIndex records documents for assertions
+type memorySearchIndex struct {
+	mu   sync.Mutex
+	docs map[string]SearchDocument
+}
+
+func (m *memorySearchIndex) Upsert(ctx context.Context, docs []SearchDocument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range docs {
+		m.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (m *memorySearchIndex) Remove(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+	return nil
+}
+
+func (m *memorySearchIndex) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.docs = map[string]SearchDocument{}
+	return nil
+}
+
+func (m *memorySearchIndex) ids() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for id := range m.docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSearchSyncFollowsModelEvents(t *testing.T) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping search sync tests: sqlite not available (%v)", err)
+	}
+	db.AutoMigrate(&searchProduct{})
+
+	r := New()
+	if err := RegisterModelEvents(db, r.Events(), &searchProduct{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var embeds []string
+	embedder := EmbedderFunc(func(ctx context.Context, text string) (Vector, error) {
+		embeds = append(embeds, text)
+		return Vector{float32(len(text)), 1}, nil
+	})
+	store := NewInMemoryVectorStore()
+	index := &memorySearchIndex{docs: map[string]SearchDocument{}}
+
+	s := r.SyncSearch(SearchSyncConfig{
+		Model:    "search_product",
+		Document: searchProductDocument,
+		Indexes:  []SearchIndex{NewVectorSearchIndex(store, embedder), index},
+	})
+
+	tea := &searchProduct{Name: "Tea", Description: "green tea", Active: true}
+	coffee := &searchProduct{Name: "Coffee", Description: "dark roast", Active: true}
+	db.Create(tea)
+	db.Create(coffee)
+	db.Model(tea).Update("name", "Green Tea")     // same description: no re-embedding
+	db.Model(coffee).Update("active", false)      // inactive: removed
+	db.Model(tea).Update("description", "sencha") // changed description: re-embedded
+	juice := &searchProduct{Name: "Juice", Description: "orange", Active: true}
+	db.Create(juice)
+	db.Delete(juice)
+
+	r.shutdownServices() // drains the queue
+
+	if got := fmt.Sprint(embeds); got != "[green tea dark roast sencha orange]" {
+		t.Errorf("Unexpected embeddings %s", got)
+	}
+	if got := fmt.Sprint(index.ids()); got != "[1]" {
+		t.Errorf("Expected only product 1 indexed, got %s", got)
+	}
+	doc, err := store.Get(context.Background(), "1")
+	if err != nil || doc.Metadata["name"] != "Green Tea" || doc.Vector[0] != float32(len("sencha")) {
+		t.Errorf("Unexpected vector document %+v (%v)", doc, err)
+	}
+	if stats := s.Stats(); stats.Indexed != 5 || stats.Removed != 2 || stats.Failed != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestSearchSyncBackfillAndReindex(t *testing.T) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping search sync tests: sqlite not available (%v)", err)
+	}
+	db.AutoMigrate(&searchProduct{})
+	for i := 1; i <= 5; i++ {
+		db.Create(&searchProduct{Name: fmt.Sprint("p", i), Description: "d", Active: i != 3})
+	}
+
+	index := &memorySearchIndex{docs: map[string]SearchDocument{"stale": {ID: "stale"}, "3": {ID: "3"}}}
+	s := NewSearchSync(NewEventBus(), SearchSyncConfig{
+		Model:     "search_product",
+		Document:  searchProductDocument,
+		Indexes:   []SearchIndex{index},
+		Source:    GormSearchSource[searchProduct](db, 2),
+		BatchSize: 2,
+	})
+
+	if err := s.Backfill(context.Background()); err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if got := fmt.Sprint(index.ids()); got != "[1 2 4 5 stale]" {
+		t.Errorf("Unexpected ids after backfill %s", got)
+	}
+
+	if err := s.ReindexAll(context.Background()); err != nil {
+		t.Fatalf("ReindexAll failed: %v", err)
+	}
+	if got := fmt.Sprint(index.ids()); got != "[1 2 4 5]" {
+		t.Errorf("Unexpected ids after reindex %s", got)
+	}
+
+	s.Stop(context.Background())
+	if err := s.Backfill(context.Background()); err != ErrSearchSyncStopped {
+		t.Errorf("Expected ErrSearchSyncStopped, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReadHeaderTimeout bounds how long Run, RunServer and RunTLS wait
+// for request headers, so slow clients can't hold connections open
+// indefinitely
+const DefaultReadHeaderTimeout = 10 * time.Second
+
+// ServerOption configures the http.Server started by RunWithOptions,
+// RunServerWithOptions and RunTLS:
+//
+//	r.RunWithOptions(":8080", goTap.WithReadTimeout(5*time.Second), goTap.WithMaxConnections(1000))
+type ServerOption func(*ServerConfig)
+
+// WithReadHeaderTimeout sets how long reading the request headers may take
+// Default: DefaultReadHeaderTimeout
+func WithReadHeaderTimeout(d time.Duration) ServerOption {
+	return func(c *ServerConfig) { c.ReadHeaderTimeout = d }
+}
+
+// WithReadTimeout sets how long reading the whole request may take
+func WithReadTimeout(d time.Duration) ServerOption {
+	return func(c *ServerConfig) { c.ReadTimeout = d }
+}
+
+// WithWriteTimeout sets how long writing the response may take. Streaming
+// responses such as SSE need it disabled.
+func WithWriteTimeout(d time.Duration) ServerOption {
+	return func(c *ServerConfig) { c.WriteTimeout = d }
+}
+
+// WithIdleTimeout sets how long keep-alive connections wait for the next
+// request
+// Default: the read timeout
+func WithIdleTimeout(d time.Duration) ServerOption {
+	return func(c *ServerConfig) { c.IdleTimeout = d }
+}
+
+// WithMaxHeaderBytes limits the size of the request headers
+// Default: http.DefaultMaxHeaderBytes (1 MB)
+func WithMaxHeaderBytes(n int) ServerOption {
+	return func(c *ServerConfig) { c.MaxHeaderBytes = n }
+}
+
+// WithMaxConnections limits the number of simultaneous connections; further
+// connections wait to be accepted
+// Default: no limit
+func WithMaxConnections(n int) ServerOption {
+	return func(c *ServerConfig) { c.MaxConnections = n }
+}
+
+// WithShutdownTimeout sets how long RunGraceful waits for active requests
+// to finish when stopping or handing over to a new process
+// Default: 30 seconds
+func WithShutdownTimeout(d time.Duration) ServerOption {
+	return func(c *ServerConfig) { c.ShutdownTimeout = d }
+}
+
+// WithServerConfig applies every setting of config, e.g. loaded by LoadConfig
+func WithServerConfig(config ServerConfig) ServerOption {
+	return func(c *ServerConfig) {
+		if config.ReadHeaderTimeout == 0 {
+			config.ReadHeaderTimeout = c.ReadHeaderTimeout
+		}
+		if config.ShutdownTimeout == 0 {
+			config.ShutdownTimeout = c.ShutdownTimeout
+		}
+		*c = config
+	}
+}
+
+// serverConfig returns the defaults with opts applied
+func serverConfig(opts []ServerOption) ServerConfig {
+	config := ServerConfig{ReadHeaderTimeout: DefaultReadHeaderTimeout, ShutdownTimeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// newServer returns an http.Server for the engine with opts applied, and
+// the connection limit
+func (engine *Engine) newServer(addr string, opts []ServerOption) (*http.Server, int) {
+	config := serverConfig(opts)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           engine,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+	srv.RegisterOnShutdown(engine.shutdownServices)
+	return srv, config.MaxConnections
+}
+
+// listen opens the server's TCP listener, limited to maxConns connections
+func listen(srv *http.Server, maxConns int) (net.Listener, error) {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return limitListen(l, maxConns), nil
+}
+
+// limitListen limits l to maxConns simultaneous connections if positive
+func limitListen(l net.Listener, maxConns int) net.Listener {
+	if maxConns <= 0 {
+		return l
+	}
+	return &limitListener{Listener: l, sem: make(chan struct{}, maxConns)}
+}
+
+// RunListener serves HTTP requests from l, e.g. a listener inherited from a
+// parent process or created by a test
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunListener(l net.Listener, opts ...ServerOption) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		l.Close()
+		return err
+	}
+	srv, maxConns := engine.newServer(l.Addr().String(), opts)
+	engine.printRoutes()
+	debugPrint("Listening and serving HTTP on %s", l.Addr())
+	err = srv.Serve(limitListen(l, maxConns))
+	return
+}
+
+// RunUnix serves HTTP requests on a unix domain socket, for running behind
+// a local reverse proxy such as nginx without a TCP port. A stale socket
+// file is replaced, but any other file at the path is an error. The socket
+// has the permissions perm, e.g. 0660 to share it with the proxy's group,
+// before it accepts connections, and is removed when serving ends.
+//
+//	r.RunUnix("/var/run/gotap.sock", 0660)
+//
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunUnix(file string, perm os.FileMode, opts ...ServerOption) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		return err
+	}
+	// Only a stale socket is replaced, never another kind of file
+	if info, statErr := os.Lstat(file); statErr == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("goTap: %s exists and is not a socket", file)
+		}
+		if err = os.Remove(file); err != nil {
+			return err
+		}
+	} else if !errors.Is(statErr, os.ErrNotExist) {
+		return statErr
+	}
+	l, err := listenUnix(file, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+
+	srv, maxConns := engine.newServer(file, opts)
+	engine.printRoutes()
+	debugPrint("Listening and serving HTTP on unix:/%s", file)
+	err = srv.Serve(limitListen(l, maxConns))
+	return
+}
+
+// listenUnix listens on a unix socket at file with perm. The socket is
+// created in a private directory and moved into place once its permissions
+// are set, so it is never reachable with the umask's.
+func listenUnix(file string, perm os.FileMode) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(file), ".gotap-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = os.Chmod(tmp, perm); err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// listenFDsStart is the first file descriptor passed by systemd
+const listenFDsStart = 3
+
+// SystemdListeners returns the sockets passed by systemd socket
+// activation, in the order of the socket unit's Listen directives, or none
+// when the process wasn't socket activated. The LISTEN_* variables are
+// unset so child processes don't inherit them.
+func SystemdListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("systemd socket %s: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// RunSystemd serves HTTP requests on the sockets passed by systemd socket
+// activation, so systemd can own the port or unix socket and start the
+// server on the first connection:
+//
+//	# gotap.socket
+//	[Socket]
+//	ListenStream=/run/gotap.sock
+//
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunSystemd(opts ...ServerOption) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	listeners, err := SystemdListeners()
+	if err != nil {
+		return err
+	}
+	if len(listeners) == 0 {
+		return errors.New("no systemd sockets: LISTEN_FDS is not set for this process")
+	}
+	if err = engine.RunStartHooks(context.Background()); err != nil {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return err
+	}
+
+	srv, maxConns := engine.newServer(listeners[0].Addr().String(), opts)
+	engine.printRoutes()
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		debugPrint("Listening and serving HTTP on systemd socket %s", l.Addr())
+		go func(l net.Listener) {
+			errs <- srv.Serve(limitListen(l, maxConns))
+		}(l)
+	}
+	err = <-errs
+	return
+}
+
+// limitListener accepts at most cap(sem) simultaneous connections
+type limitListener struct {
+	net.Listener
+	sem       chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+	initOnce  sync.Once
+}
+
+func (l *limitListener) closed() chan struct{} {
+	l.initOnce.Do(func() { l.done = make(chan struct{}) })
+	return l.done
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	select {
+	case l.sem <- struct{}{}:
+	case <-l.closed():
+		return nil, net.ErrClosed
+	}
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	return &limitConn{Conn: conn, release: func() { <-l.sem }}, nil
+}
+
+func (l *limitListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { close(l.closed()) })
+	return err
+}
+
+// limitConn frees its slot of a limitListener once closed
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
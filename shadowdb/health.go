@@ -7,6 +7,7 @@ package shadowdb
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -33,7 +34,7 @@ func (sdb *ShadowDB) startHealthChecks() {
 	}()
 }
 
-// performHealthCheck checks health of both databases
+// performHealthCheck checks health of all databases
 func (sdb *ShadowDB) performHealthCheck() {
 	ctx, cancel := context.WithTimeout(context.Background(), sdb.config.HealthCheckTimeout)
 	defer cancel()
@@ -78,6 +79,9 @@ func (sdb *ShadowDB) performHealthCheck() {
 		}
 	}
 
+	// Check read replicas
+	sdb.checkReplicas(ctx)
+
 	// Handle auto-failover and auto-failback
 	if sdb.config.AutoFailover {
 		sdb.handleAutoFailover()
@@ -114,17 +118,23 @@ func (sdb *ShadowDB) handleAutoFailover() {
 
 	// Failover from primary to shadow
 	if currentlyPrimary && !primaryHealthy && shadowHealthy {
-		sdb.Failover()
+		stats := sdb.primaryHealth.GetStats()
+		sdb.failover(fmt.Sprintf("primary %s after %d failed health checks", stats.Status, stats.ConsecutiveFails))
 	}
 
 	// Failback from shadow to primary (auto-failback)
 	if sdb.config.AutoFailback && !currentlyPrimary && primaryHealthy {
-		sdb.Failback()
+		sdb.failback("primary recovered")
 	}
 }
 
 // Failover manually triggers failover from primary to shadow
 func (sdb *ShadowDB) Failover() error {
+	return sdb.failover("manual")
+}
+
+// failover switches writes to the shadow and records why
+func (sdb *ShadowDB) failover(reason string) error {
 	sdb.failoverLock.Lock()
 	defer sdb.failoverLock.Unlock()
 
@@ -143,6 +153,7 @@ func (sdb *ShadowDB) Failover() error {
 
 	// Perform failover
 	sdb.activePrimary = false
+	sdb.metrics.event(FailoverEvent{Type: "failover", From: "primary", To: "shadow", Reason: reason})
 
 	if sdb.config.OnFailover != nil {
 		go sdb.config.OnFailover("primary", "shadow")
@@ -153,6 +164,11 @@ func (sdb *ShadowDB) Failover() error {
 
 // Failback manually triggers failback from shadow to primary
 func (sdb *ShadowDB) Failback() error {
+	return sdb.failback("manual")
+}
+
+// failback switches writes back to the primary and records why
+func (sdb *ShadowDB) failback(reason string) error {
 	sdb.failoverLock.Lock()
 	defer sdb.failoverLock.Unlock()
 
@@ -171,6 +187,7 @@ func (sdb *ShadowDB) Failback() error {
 
 	// Perform failback
 	sdb.activePrimary = true
+	sdb.metrics.event(FailoverEvent{Type: "failback", From: "shadow", To: "primary", Reason: reason})
 
 	if sdb.config.OnFailback != nil {
 		go sdb.config.OnFailback()
@@ -205,6 +222,7 @@ func (sdb *ShadowDB) GetStatus() SystemStatus {
 		ActiveDB:      sdb.getActiveDBName(),
 		PrimaryHealth: sdb.primaryHealth.GetStats(),
 		ShadowHealth:  sdb.shadowHealth.GetStats(),
+		Replicas:      sdb.Replicas(),
 		ReadStrategy:  sdb.config.ReadStrategy,
 		WriteStrategy: sdb.config.WriteStrategy,
 		AutoFailover:  sdb.config.AutoFailover,
@@ -225,6 +243,7 @@ type SystemStatus struct {
 	ActiveDB      string
 	PrimaryHealth HealthStats
 	ShadowHealth  HealthStats
+	Replicas      []ReplicaStatus
 	ReadStrategy  ReadStrategy
 	WriteStrategy WriteStrategy
 	AutoFailover  bool
@@ -0,0 +1,145 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// latencySamples is the number of recent query latencies kept per
+	// database for percentiles
+	latencySamples = 1024
+
+	// maxFailoverEvents bounds the failover history
+	maxFailoverEvents = 100
+)
+
+// DBMetrics holds query statistics for one database. Only queries run
+// through ShadowDB helpers (ExecWrite, QueryRead, QueryRowRead, BeginTx and
+// WriteBoth replication) are measured.
+type DBMetrics struct {
+	Queries   int64
+	Errors    int64
+	ErrorRate float64
+	P50       time.Duration
+	P95       time.Duration
+	P99       time.Duration
+}
+
+// FailoverEvent records a switch between primary and shadow
+type FailoverEvent struct {
+	Type   string // "failover" or "failback"
+	From   string
+	To     string
+	Reason string
+	Time   time.Time
+}
+
+type dbMetrics struct {
+	queries int64
+	errors  int64
+	samples []time.Duration
+	next    int
+}
+
+// metricsRecorder collects query metrics and failover history
+type metricsRecorder struct {
+	mu     sync.Mutex
+	dbs    map[string]*dbMetrics
+	events []FailoverEvent
+}
+
+// observe records a query against the named database
+func (m *metricsRecorder) observe(name string, start time.Time, err error) {
+	d := time.Since(start)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dbs == nil {
+		m.dbs = make(map[string]*dbMetrics)
+	}
+	dm, ok := m.dbs[name]
+	if !ok {
+		dm = &dbMetrics{}
+		m.dbs[name] = dm
+	}
+	dm.queries++
+	if err != nil {
+		dm.errors++
+	}
+	if len(dm.samples) < latencySamples {
+		dm.samples = append(dm.samples, d)
+	} else {
+		dm.samples[dm.next] = d
+		dm.next = (dm.next + 1) % latencySamples
+	}
+}
+
+// event appends to the failover history
+func (m *metricsRecorder) event(e FailoverEvent) {
+	e.Time = time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	if len(m.events) > maxFailoverEvents {
+		m.events = m.events[1:]
+	}
+}
+
+// observe records a query against db
+func (sdb *ShadowDB) observe(db *sql.DB, start time.Time, err error) {
+	sdb.metrics.observe(sdb.dbName(db), start, err)
+}
+
+// dbName returns the name of a connection owned by sdb
+func (sdb *ShadowDB) dbName(db *sql.DB) string {
+	switch db {
+	case sdb.primary:
+		return "primary"
+	case sdb.shadow:
+		return "shadow"
+	}
+	for _, r := range sdb.replicas {
+		if r.db == db {
+			return r.name
+		}
+	}
+	return "unknown"
+}
+
+// Metrics returns query counts, error rates and latency percentiles over
+// the last 1024 queries, keyed by "primary", "shadow" or replica name
+func (sdb *ShadowDB) Metrics() map[string]DBMetrics {
+	sdb.metrics.mu.Lock()
+	defer sdb.metrics.mu.Unlock()
+
+	metrics := make(map[string]DBMetrics, len(sdb.metrics.dbs))
+	for name, dm := range sdb.metrics.dbs {
+		m := DBMetrics{Queries: dm.queries, Errors: dm.errors}
+		if dm.queries > 0 {
+			m.ErrorRate = float64(dm.errors) / float64(dm.queries)
+		}
+		if n := len(dm.samples); n > 0 {
+			sorted := append([]time.Duration(nil), dm.samples...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			m.P50 = sorted[(n-1)*50/100]
+			m.P95 = sorted[(n-1)*95/100]
+			m.P99 = sorted[(n-1)*99/100]
+		}
+		metrics[name] = m
+	}
+	return metrics
+}
+
+// FailoverEvents returns recent failovers and failbacks, oldest first
+func (sdb *ShadowDB) FailoverEvents() []FailoverEvent {
+	sdb.metrics.mu.Lock()
+	defer sdb.metrics.mu.Unlock()
+	return append([]FailoverEvent(nil), sdb.metrics.events...)
+}
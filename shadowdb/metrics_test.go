@@ -0,0 +1,73 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetricsPercentiles(t *testing.T) {
+	sdb := &ShadowDB{}
+	for i := 1; i <= 100; i++ {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("boom")
+		}
+		sdb.metrics.observe("primary", time.Now().Add(-time.Duration(i)*time.Millisecond), err)
+	}
+
+	got := sdb.Metrics()["primary"]
+	if got.Queries != 100 || got.Errors != 10 || got.ErrorRate != 0.1 {
+		t.Errorf("Unexpected counts %+v", got)
+	}
+	if got.P50.Round(time.Millisecond) != 50*time.Millisecond ||
+		got.P95.Round(time.Millisecond) != 95*time.Millisecond ||
+		got.P99.Round(time.Millisecond) != 99*time.Millisecond {
+		t.Errorf("Unexpected percentiles p50=%v p95=%v p99=%v", got.P50, got.P95, got.P99)
+	}
+}
+
+func TestMetricsAndFailoverEvents(t *testing.T) {
+	dir := t.TempDir()
+	sdb, err := New(Config{
+		Primary:             DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "primary.db")},
+		Shadow:              DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "shadow.db")},
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Skipf("Skipping metrics tests: sqlite not available (%v)", err)
+	}
+	defer sdb.Close()
+
+	sdb.ExecWrite("CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	sdb.ExecWrite("INSERT INTO items (id) VALUES (1)")
+	sdb.ExecWrite("INSERT INTO missing (id) VALUES (1)")
+	if rows, err := sdb.QueryRead("SELECT id FROM items"); err == nil {
+		rows.Close()
+	}
+
+	primary := sdb.Metrics()["primary"]
+	if primary.Queries != 4 || primary.Errors != 1 || primary.P99 <= 0 {
+		t.Errorf("Unexpected primary metrics %+v", primary)
+	}
+
+	if err := sdb.Failover(); err != nil {
+		t.Fatalf("Failover failed: %v", err)
+	}
+	sdb.ExecWrite("CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	sdb.Failback()
+
+	if shadow := sdb.Metrics()["shadow"]; shadow.Queries != 1 {
+		t.Errorf("Expected write on shadow after failover, got %+v", shadow)
+	}
+	events := sdb.FailoverEvents()
+	if len(events) != 2 || events[0].Type != "failover" || events[0].Reason != "manual" ||
+		events[1].Type != "failback" || events[1].To != "primary" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidTable is returned by Reconcile for table names that are not
+// plain identifiers
+var ErrInvalidTable = errors.New("invalid table name")
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// TableDrift compares a table between primary and shadow
+type TableDrift struct {
+	Table           string
+	PrimaryRows     int64
+	ShadowRows      int64
+	PrimaryChecksum string
+	ShadowChecksum  string
+	InSync          bool
+}
+
+// ReconcileReport is the result of Reconcile
+type ReconcileReport struct {
+	Tables    []TableDrift
+	InSync    bool
+	CheckedAt time.Time
+}
+
+// Reconcile compares row counts and content checksums of the given tables
+// on primary and shadow. Rows are read ordered by the first column, which
+// should be the primary key. Every row is read, so run it off-peak on large
+// tables.
+func (sdb *ShadowDB) Reconcile(ctx context.Context, tables ...string) (*ReconcileReport, error) {
+	primary, shadow := sdb.Primary(), sdb.Shadow()
+	if primary == nil {
+		return nil, ErrNoPrimaryDB
+	}
+	if shadow == nil {
+		return nil, ErrNoShadowDB
+	}
+
+	report := &ReconcileReport{InSync: true, CheckedAt: time.Now()}
+	for _, table := range tables {
+		if !tableNamePattern.MatchString(table) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTable, table)
+		}
+
+		drift := TableDrift{Table: table}
+		var err error
+		if drift.PrimaryRows, drift.PrimaryChecksum, err = tableChecksum(ctx, primary, table); err != nil {
+			return nil, fmt.Errorf("primary %s: %w", table, err)
+		}
+		if drift.ShadowRows, drift.ShadowChecksum, err = tableChecksum(ctx, shadow, table); err != nil {
+			return nil, fmt.Errorf("shadow %s: %w", table, err)
+		}
+		drift.InSync = drift.PrimaryRows == drift.ShadowRows && drift.PrimaryChecksum == drift.ShadowChecksum
+		report.InSync = report.InSync && drift.InSync
+		report.Tables = append(report.Tables, drift)
+	}
+	return report, nil
+}
+
+// tableChecksum counts the rows of a table and hashes their values in order
+func tableChecksum(ctx context.Context, db *sql.DB, table string) (int64, string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+table+" ORDER BY 1")
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, "", err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	h := sha256.New()
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return 0, "", err
+		}
+		for _, v := range values {
+			switch v := v.(type) {
+			case nil:
+				h.Write([]byte("NULL"))
+			case []byte:
+				h.Write(v)
+			case time.Time:
+				h.Write([]byte(v.UTC().Format(time.RFC3339Nano)))
+			default:
+				fmt.Fprint(h, v)
+			}
+			h.Write([]byte{0x1f})
+		}
+		h.Write([]byte{0x1e})
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+	return count, hex.EncodeToString(h.Sum(nil)), nil
+}
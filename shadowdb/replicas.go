@@ -0,0 +1,221 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaConfig holds configuration for a read replica
+type ReplicaConfig struct {
+	DBConfig
+
+	// Name identifies the replica in status output and health callbacks
+	// Default: "replica-<n>"
+	Name string
+
+	// Weight is the relative share of reads under ReadWeighted
+	// Default: 1
+	Weight int
+}
+
+// ReplicaStatus describes a read replica and whether it is serving reads
+type ReplicaStatus struct {
+	Name    string
+	Weight  int
+	Latency time.Duration
+	InPool  bool
+	Health  HealthStats
+}
+
+// replica is a read replica with its health and load balancing state
+type replica struct {
+	name   string
+	weight int
+	db     *sql.DB
+	health *HealthStatus
+
+	// latency is a moving average of health check round trips in nanoseconds
+	latency atomic.Int64
+
+	// current is the smooth weighted round-robin score, guarded by replicaMu
+	current int
+}
+
+// latencySmoothing is the weight of the newest sample in the latency average
+const latencySmoothing = 0.3
+
+// openDB opens a database handle with its pool settings applied. It does
+// not connect; callers ping to verify the connection.
+func openDB(config DBConfig) (*sql.DB, error) {
+	db, err := sql.Open(config.Driver, config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+	return db, nil
+}
+
+// connectReplicas opens all configured replicas. A replica that cannot be
+// reached stays out of the read pool until a health check succeeds.
+func (sdb *ShadowDB) connectReplicas() {
+	for i, cfg := range sdb.config.Replicas {
+		r := &replica{
+			name:   cfg.Name,
+			weight: cfg.Weight,
+			health: &HealthStatus{status: StatusUnknown},
+		}
+		if r.name == "" {
+			r.name = fmt.Sprintf("replica-%d", i+1)
+		}
+		if r.weight <= 0 {
+			r.weight = 1
+		}
+
+		db, err := openDB(cfg.DBConfig)
+		if err != nil {
+			if sdb.config.OnHealthChange != nil {
+				sdb.config.OnHealthChange(r.name, StatusUnknown, StatusUnhealthy)
+			}
+			continue
+		}
+
+		// Keep an unreachable replica so later health checks can add it
+		if err := db.Ping(); err != nil {
+			r.health.updateStatus(StatusUnhealthy)
+			if sdb.config.OnHealthChange != nil {
+				sdb.config.OnHealthChange(r.name, StatusUnknown, StatusUnhealthy)
+			}
+		} else {
+			r.health.updateStatus(StatusHealthy)
+		}
+		r.db = db
+		sdb.replicas = append(sdb.replicas, r)
+	}
+}
+
+// checkReplicas runs a health check against every replica. Replicas leave
+// the read pool on their first failed check and rejoin once a check passes.
+func (sdb *ShadowDB) checkReplicas(ctx context.Context) {
+	for _, r := range sdb.replicas {
+		start := time.Now()
+		healthy := sdb.checkDatabaseHealth(ctx, r.db, r.name)
+		if healthy {
+			r.observeLatency(time.Since(start))
+		}
+
+		old := r.health.GetStatus()
+		switch {
+		case healthy:
+			r.health.updateStatus(StatusHealthy)
+		case r.health.GetStats().ConsecutiveFails+1 >= sdb.config.MaxFailures:
+			r.health.updateStatus(StatusUnhealthy)
+		default:
+			r.health.updateStatus(StatusDegraded)
+		}
+
+		if status := r.health.GetStatus(); status != old && sdb.config.OnHealthChange != nil {
+			sdb.config.OnHealthChange(r.name, old, status)
+		}
+	}
+}
+
+// observeLatency folds a health check round trip into the moving average
+func (r *replica) observeLatency(d time.Duration) {
+	old := r.latency.Load()
+	if old == 0 {
+		r.latency.Store(int64(d))
+		return
+	}
+	r.latency.Store(int64(latencySmoothing*float64(d) + (1-latencySmoothing)*float64(old)))
+}
+
+// pickReplica selects a healthy replica according to the read strategy,
+// or returns nil when none is in the pool
+func (sdb *ShadowDB) pickReplica() *replica {
+	pool := make([]*replica, 0, len(sdb.replicas))
+	for _, r := range sdb.replicas {
+		if r.health.isHealthy() {
+			pool = append(pool, r)
+		}
+	}
+	if len(pool) == 0 {
+		return nil
+	}
+
+	switch sdb.config.ReadStrategy {
+	case ReadLeastLatency:
+		best := pool[0]
+		for _, r := range pool[1:] {
+			if r.latency.Load() < best.latency.Load() {
+				best = r
+			}
+		}
+		return best
+
+	case ReadWeighted:
+		// Smooth weighted round-robin: spreads picks evenly instead of
+		// sending bursts to the heaviest replica
+		sdb.replicaMu.Lock()
+		defer sdb.replicaMu.Unlock()
+		total := 0
+		var best *replica
+		for _, r := range pool {
+			r.current += r.weight
+			total += r.weight
+			if best == nil || r.current > best.current {
+				best = r
+			}
+		}
+		best.current -= total
+		return best
+
+	default:
+		n := atomic.AddUint64(&sdb.replicaCount, 1)
+		return pool[(n-1)%uint64(len(pool))]
+	}
+}
+
+// Replicas returns the status of all configured read replicas
+func (sdb *ShadowDB) Replicas() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(sdb.replicas))
+	for _, r := range sdb.replicas {
+		health := r.health.GetStats()
+		statuses = append(statuses, ReplicaStatus{
+			Name:    r.name,
+			Weight:  r.weight,
+			Latency: time.Duration(r.latency.Load()),
+			InPool:  health.Status == StatusHealthy,
+			Health:  health,
+		})
+	}
+	return statuses
+}
+
+// Replica returns the connection of the named read replica, or nil
+func (sdb *ShadowDB) Replica(name string) *sql.DB {
+	for _, r := range sdb.replicas {
+		if r.name == name {
+			return r.db
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newReplicaShadowDB(t *testing.T, config Config) *ShadowDB {
+	t.Helper()
+	for i := range config.Replicas {
+		config.Replicas[i].Driver = "sqlite3"
+		config.Replicas[i].DSN = ":memory:"
+	}
+	config.Primary = DBConfig{Driver: "sqlite3", DSN: ":memory:"}
+	config.HealthCheckInterval = time.Hour
+	sdb, err := New(config)
+	if err != nil {
+		t.Skipf("Skipping replica tests: sqlite not available (%v)", err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+
+	// Wait for the initial background health check so tests can break
+	// replicas without racing it
+	last := sdb.replicas[len(sdb.replicas)-1].health
+	for last.GetStats().TotalChecks < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	return sdb
+}
+
+// readCounts performs n reads and counts them per replica name
+func readCounts(t *testing.T, sdb *ShadowDB, n int) map[string]int {
+	t.Helper()
+	names := map[*sql.DB]string{sdb.Primary(): "primary"}
+	for _, r := range sdb.replicas {
+		names[r.db] = r.name
+	}
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		db, err := sdb.Read()
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		counts[names[db]]++
+	}
+	return counts
+}
+
+func TestReplicaRoundRobin(t *testing.T) {
+	sdb := newReplicaShadowDB(t, Config{
+		ReadStrategy: ReadReplicaRoundRobin,
+		Replicas:     []ReplicaConfig{{}, {}, {}},
+	})
+
+	counts := readCounts(t, sdb, 9)
+	if fmt.Sprint(counts) != "map[replica-1:3 replica-2:3 replica-3:3]" {
+		t.Errorf("Expected reads spread evenly, got %v", counts)
+	}
+}
+
+func TestReplicaWeighted(t *testing.T) {
+	sdb := newReplicaShadowDB(t, Config{
+		ReadStrategy: ReadWeighted,
+		Replicas:     []ReplicaConfig{{Name: "big", Weight: 3}, {Name: "small"}},
+	})
+
+	counts := readCounts(t, sdb, 8)
+	if counts["big"] != 6 || counts["small"] != 2 {
+		t.Errorf("Expected 6/2 split, got %v", counts)
+	}
+}
+
+func TestReplicaLeastLatency(t *testing.T) {
+	sdb := newReplicaShadowDB(t, Config{
+		ReadStrategy: ReadLeastLatency,
+		Replicas:     []ReplicaConfig{{Name: "far"}, {Name: "near"}},
+	})
+	sdb.replicas[0].latency.Store(int64(20 * time.Millisecond))
+	sdb.replicas[1].latency.Store(int64(2 * time.Millisecond))
+
+	if counts := readCounts(t, sdb, 3); counts["near"] != 3 {
+		t.Errorf("Expected reads on the nearest replica, got %v", counts)
+	}
+
+	sdb.replicas[0].observeLatency(0)
+	if got := time.Duration(sdb.replicas[0].latency.Load()); got != 14*time.Millisecond {
+		t.Errorf("Expected smoothed latency 14ms, got %v", got)
+	}
+}
+
+func TestReplicaRemovedAndReadded(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	sdb := newReplicaShadowDB(t, Config{
+		ReadStrategy: ReadReplicaRoundRobin,
+		Replicas:     []ReplicaConfig{{Name: "a"}, {Name: "b"}},
+		OnHealthChange: func(db string, old, new DBStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, fmt.Sprintf("%s:%s->%s", db, old, new))
+		},
+	})
+
+	// Break replica b: it leaves the pool after a failed check
+	broken := sdb.replicas[1].db
+	broken.Close()
+	sdb.performHealthCheck()
+	if counts := readCounts(t, sdb, 4); counts["a"] != 4 {
+		t.Errorf("Expected unhealthy replica out of the pool, got %v", counts)
+	}
+	if status := sdb.Replicas()[1]; status.InPool || status.Health.Status != StatusDegraded {
+		t.Errorf("Unexpected replica status %+v", status)
+	}
+
+	// Repair it: the next check puts it back
+	sdb.replicas[1].db, _ = sql.Open("sqlite3", ":memory:")
+	sdb.performHealthCheck()
+	if counts := readCounts(t, sdb, 4); counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("Expected recovered replica back in the pool, got %v", counts)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(changes) != "[b:healthy->degraded b:degraded->healthy]" {
+		t.Errorf("Unexpected health changes %v", changes)
+	}
+}
+
+func TestReplicaFallbackToPrimary(t *testing.T) {
+	sdb := newReplicaShadowDB(t, Config{ReadStrategy: ReadWeighted, Replicas: []ReplicaConfig{{}}})
+	sdb.replicas[0].db.Close()
+	sdb.performHealthCheck()
+
+	if counts := readCounts(t, sdb, 2); counts["primary"] != 2 {
+		t.Errorf("Expected reads to fall back to primary, got %v", counts)
+	}
+	if status := sdb.GetStatus(); len(status.Replicas) != 1 || status.Replicas[0].InPool {
+		t.Errorf("Unexpected system status %+v", status.Replicas)
+	}
+}
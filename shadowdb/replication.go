@@ -0,0 +1,502 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReplicationConfig configures WriteBoth replication from primary to shadow
+type ReplicationConfig struct {
+	// QueueDir holds the durable retry queue. Writes not yet applied to the
+	// shadow survive restarts and are replayed on the next New.
+	// Default: "" (in-memory queue)
+	QueueDir string
+
+	// MaxRetries is how often a write is attempted on the shadow before it is
+	// logged as a conflict and dropped
+	// Default: 5
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled per attempt
+	// and capped at one minute
+	// Default: 1s
+	RetryBackoff time.Duration
+
+	// OnConflict is called when a write cannot be applied to the shadow or
+	// affects a different number of rows than on the primary
+	// Default: log.Printf
+	OnConflict func(ReplicationConflict)
+}
+
+// ReplicationConflict describes a write that diverged between primary and shadow
+type ReplicationConflict struct {
+	Seq         uint64
+	Query       string
+	Args        []any
+	PrimaryRows int64
+	ShadowRows  int64
+	Err         error
+	Time        time.Time
+}
+
+// ReplicationStats reports the state of WriteBoth replication
+type ReplicationStats struct {
+	Pending    int
+	Replicated uint64
+	Conflicts  uint64
+	LastError  string
+	Oldest     time.Time
+}
+
+// maxRecentConflicts bounds the conflicts kept for ReplicationConflicts
+const maxRecentConflicts = 100
+
+// replicatedStmt is a statement executed on the primary, queued for the shadow
+type replicatedStmt struct {
+	Query string           `json:"query"`
+	Args  []replicationArg `json:"args,omitempty"`
+	Rows  int64            `json:"rows"`
+}
+
+// replicationArg is a driver value that survives a JSON round trip
+type replicationArg struct {
+	Kind  string `json:"k"`
+	Value string `json:"v,omitempty"`
+}
+
+type replicationEntry struct {
+	Seq      uint64           `json:"seq,omitempty"`
+	Stmts    []replicatedStmt `json:"stmts,omitempty"`
+	Queued   time.Time        `json:"queued,omitempty"`
+	Ack      uint64           `json:"ack,omitempty"`
+	attempts int
+}
+
+// replicator applies primary writes to the shadow in order
+type replicator struct {
+	sdb    *ShadowDB
+	config ReplicationConfig
+
+	mu         sync.Mutex
+	journal    *os.File
+	journaled  int
+	pending    []*replicationEntry
+	nextSeq    uint64
+	replicated uint64
+	conflicts  uint64
+	recent     []ReplicationConflict
+	lastError  error
+
+	signal chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newStmt captures a statement and its arguments for replication
+func newStmt(query string, args []any) (replicatedStmt, error) {
+	stmt := replicatedStmt{Query: query, Rows: -1}
+	for _, arg := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return stmt, err
+		}
+		var a replicationArg
+		switch v := v.(type) {
+		case nil:
+			a.Kind = "nil"
+		case int64:
+			a = replicationArg{Kind: "int", Value: strconv.FormatInt(v, 10)}
+		case float64:
+			a = replicationArg{Kind: "float", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+		case bool:
+			a = replicationArg{Kind: "bool", Value: strconv.FormatBool(v)}
+		case string:
+			a = replicationArg{Kind: "string", Value: v}
+		case []byte:
+			a = replicationArg{Kind: "bytes", Value: base64.StdEncoding.EncodeToString(v)}
+		case time.Time:
+			a = replicationArg{Kind: "time", Value: v.Format(time.RFC3339Nano)}
+		default:
+			return stmt, fmt.Errorf("shadowdb: cannot replicate argument of type %T", v)
+		}
+		stmt.Args = append(stmt.Args, a)
+	}
+	return stmt, nil
+}
+
+// args decodes the statement arguments
+func (s replicatedStmt) args() []any {
+	args := make([]any, len(s.Args))
+	for i, a := range s.Args {
+		switch a.Kind {
+		case "int":
+			args[i], _ = strconv.ParseInt(a.Value, 10, 64)
+		case "float":
+			args[i], _ = strconv.ParseFloat(a.Value, 64)
+		case "bool":
+			args[i], _ = strconv.ParseBool(a.Value)
+		case "string":
+			args[i] = a.Value
+		case "bytes":
+			args[i], _ = base64.StdEncoding.DecodeString(a.Value)
+		case "time":
+			args[i], _ = time.Parse(time.RFC3339Nano, a.Value)
+		}
+	}
+	return args
+}
+
+// rowsAffected returns the affected rows of a result, or -1 if unknown
+func rowsAffected(result sql.Result) int64 {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// newReplicator creates a replicator and replays its durable queue
+func newReplicator(sdb *ShadowDB, config ReplicationConfig) (*replicator, error) {
+	r := &replicator{
+		sdb:     sdb,
+		config:  config,
+		nextSeq: 1,
+		signal:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if config.QueueDir != "" {
+		if err := r.openJournal(); err != nil {
+			return nil, err
+		}
+	}
+	go r.run()
+	return r, nil
+}
+
+// openJournal loads unacknowledged entries and compacts the journal
+func (r *replicator) openJournal() error {
+	if err := os.MkdirAll(r.config.QueueDir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(r.config.QueueDir, "replication.log")
+
+	entries := map[uint64]*replicationEntry{}
+	var order []uint64
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 64<<20)
+		for scanner.Scan() {
+			var e replicationEntry
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue // torn write from a crash
+			}
+			if e.Ack != 0 {
+				delete(entries, e.Ack)
+				continue
+			}
+			entries[e.Seq] = &e
+			order = append(order, e.Seq)
+		}
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	// Rewrite the journal with only pending entries
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, seq := range order {
+		e, ok := entries[seq]
+		if !ok {
+			continue
+		}
+		line, _ := json.Marshal(e)
+		f.Write(append(line, '\n'))
+		r.pending = append(r.pending, e)
+		r.nextSeq = seq + 1
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	r.journal, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	r.journaled = len(r.pending)
+	return err
+}
+
+// writeJournal appends a record and syncs it to disk. Callers hold r.mu.
+func (r *replicator) writeJournal(e *replicationEntry) error {
+	if r.journal == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := r.journal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	r.journaled++
+	return r.journal.Sync()
+}
+
+// enqueue queues statements committed on the primary
+func (r *replicator) enqueue(stmts []replicatedStmt) {
+	r.mu.Lock()
+	e := &replicationEntry{Seq: r.nextSeq, Stmts: stmts, Queued: time.Now()}
+	r.nextSeq++
+	err := r.writeJournal(e)
+	if err == nil {
+		r.pending = append(r.pending, e)
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		// The write is on the primary only; Reconcile will report the drift
+		for _, stmt := range stmts {
+			r.conflict(ReplicationConflict{Seq: e.Seq, Query: stmt.Query, Args: stmt.args(), PrimaryRows: stmt.Rows, ShadowRows: -1, Err: err})
+		}
+		return
+	}
+
+	select {
+	case r.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run applies queued entries to the shadow one at a time
+func (r *replicator) run() {
+	defer close(r.done)
+	for {
+		r.mu.Lock()
+		var e *replicationEntry
+		if len(r.pending) > 0 {
+			e = r.pending[0]
+		}
+		r.mu.Unlock()
+
+		if e == nil {
+			select {
+			case <-r.signal:
+				continue
+			case <-r.stop:
+				return
+			}
+		}
+
+		shadow := r.sdb.Shadow()
+		if shadow == nil || !r.sdb.shadowHealth.isHealthy() {
+			if !r.wait(r.config.RetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		err := r.apply(shadow, e)
+		if err == nil {
+			r.finish(e, nil)
+			continue
+		}
+
+		e.attempts++
+		if e.attempts >= r.config.MaxRetries {
+			for _, stmt := range e.Stmts {
+				r.conflict(ReplicationConflict{Seq: e.Seq, Query: stmt.Query, Args: stmt.args(), PrimaryRows: stmt.Rows, ShadowRows: -1, Err: err})
+			}
+			r.finish(e, err)
+			continue
+		}
+
+		r.mu.Lock()
+		r.lastError = err
+		r.mu.Unlock()
+		backoff := r.config.RetryBackoff << (e.attempts - 1)
+		if backoff > time.Minute || backoff <= 0 {
+			backoff = time.Minute
+		}
+		if !r.wait(backoff) {
+			return
+		}
+	}
+}
+
+// apply runs an entry in a shadow transaction. Row count mismatches are
+// logged as conflicts but do not fail the entry.
+func (r *replicator) apply(shadow *sql.DB, e *replicationEntry) error {
+	tx, err := shadow.Begin()
+	if err != nil {
+		return err
+	}
+
+	var mismatches []ReplicationConflict
+	for _, stmt := range e.Stmts {
+		args := stmt.args()
+		start := time.Now()
+		result, err := tx.Exec(stmt.Query, args...)
+		r.sdb.metrics.observe("shadow", start, err)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if rows := rowsAffected(result); stmt.Rows >= 0 && rows >= 0 && rows != stmt.Rows {
+			mismatches = append(mismatches, ReplicationConflict{
+				Seq:         e.Seq,
+				Query:       stmt.Query,
+				Args:        args,
+				PrimaryRows: stmt.Rows,
+				ShadowRows:  rows,
+			})
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, c := range mismatches {
+		r.conflict(c)
+	}
+	return nil
+}
+
+// finish removes the head entry from the queue and acknowledges it
+func (r *replicator) finish(e *replicationEntry, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = r.pending[1:]
+	r.lastError = err
+	if err == nil {
+		r.replicated++
+	}
+	if r.journal == nil {
+		return
+	}
+	if len(r.pending) == 0 && r.journaled > 1000 {
+		// Everything is applied: start a fresh journal
+		if r.journal.Truncate(0) == nil {
+			r.journaled = 0
+			return
+		}
+	}
+	r.writeJournal(&replicationEntry{Ack: e.Seq})
+}
+
+// conflict records and reports a replication conflict
+func (r *replicator) conflict(c ReplicationConflict) {
+	c.Time = time.Now()
+	r.mu.Lock()
+	r.conflicts++
+	r.recent = append(r.recent, c)
+	if len(r.recent) > maxRecentConflicts {
+		r.recent = r.recent[1:]
+	}
+	r.mu.Unlock()
+
+	if r.config.OnConflict != nil {
+		r.config.OnConflict(c)
+		return
+	}
+	log.Printf("shadowdb: replication conflict seq=%d primary_rows=%d shadow_rows=%d err=%v query=%q",
+		c.Seq, c.PrimaryRows, c.ShadowRows, c.Err, c.Query)
+}
+
+// wait sleeps for d and reports false if the replicator is stopping
+func (r *replicator) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.stop:
+		return false
+	}
+}
+
+// close stops the worker. Pending entries stay in the journal.
+func (r *replicator) close() error {
+	close(r.stop)
+	<-r.done
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal != nil {
+		return r.journal.Close()
+	}
+	return nil
+}
+
+// ReplicationStatus returns WriteBoth replication statistics
+func (sdb *ShadowDB) ReplicationStatus() ReplicationStats {
+	r := sdb.replicator
+	if r == nil {
+		return ReplicationStats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := ReplicationStats{
+		Pending:    len(r.pending),
+		Replicated: r.replicated,
+		Conflicts:  r.conflicts,
+	}
+	if r.lastError != nil {
+		stats.LastError = r.lastError.Error()
+	}
+	if len(r.pending) > 0 {
+		stats.Oldest = r.pending[0].Queued
+	}
+	return stats
+}
+
+// ReplicationConflicts returns the most recent replication conflicts
+func (sdb *ShadowDB) ReplicationConflicts() []ReplicationConflict {
+	r := sdb.replicator
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReplicationConflict(nil), r.recent...)
+}
+
+// WaitForReplication blocks until all queued writes were applied to the
+// shadow, e.g. before a planned failover
+func (sdb *ShadowDB) WaitForReplication(ctx context.Context) error {
+	if sdb.replicator == nil {
+		return nil
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for sdb.ReplicationStatus().Pending > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
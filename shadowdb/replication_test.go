@@ -0,0 +1,170 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadowdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newReplicatingShadowDB(t *testing.T, dir string, replication ReplicationConfig) *ShadowDB {
+	t.Helper()
+	if replication.RetryBackoff == 0 {
+		replication.RetryBackoff = 5 * time.Millisecond
+	}
+	sdb, err := New(Config{
+		Primary:             DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "primary.db")},
+		Shadow:              DBConfig{Driver: "sqlite3", DSN: filepath.Join(dir, "shadow.db")},
+		WriteStrategy:       WriteBoth,
+		Replication:         replication,
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Skipf("Skipping replication tests: sqlite not available (%v)", err)
+	}
+
+	// Wait for the initial background health check
+	for sdb.shadowHealth.GetStats().TotalChecks < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, db := range []*sql.DB{sdb.Primary(), sdb.Shadow()} {
+		if _, err := db.Exec("CREATE TABLE IF NOT EXISTS items (id INTEGER PRIMARY KEY, name TEXT, price REAL, added DATETIME)"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sdb
+}
+
+func waitReplicated(t *testing.T, sdb *ShadowDB) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sdb.WaitForReplication(ctx); err != nil {
+		t.Fatalf("Replication did not drain: %+v", sdb.ReplicationStatus())
+	}
+}
+
+func TestWriteBothReplicatesToShadow(t *testing.T) {
+	sdb := newReplicatingShadowDB(t, t.TempDir(), ReplicationConfig{})
+	defer sdb.Close()
+
+	added := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := sdb.ExecWrite("INSERT INTO items (id, name, price, added) VALUES (?, ?, ?, ?)", 1, "tea", 2.5, added); err != nil {
+		t.Fatalf("ExecWrite failed: %v", err)
+	}
+
+	tx, err := sdb.BeginTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Exec("INSERT INTO items (id, name, price, added) VALUES (?, ?, ?, ?)", 2, "coffee", 3, added)
+	tx.Exec("UPDATE items SET price = price + ? WHERE id = ?", 1, 1)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	rolledBack, _ := sdb.BeginTx()
+	rolledBack.Exec("INSERT INTO items (id, name) VALUES (?, ?)", 3, "juice")
+	rolledBack.Rollback()
+
+	waitReplicated(t, sdb)
+
+	var price float64
+	sdb.Shadow().QueryRow("SELECT price FROM items WHERE id = 1").Scan(&price)
+	if price != 3.5 {
+		t.Errorf("Expected replicated price 3.5, got %v", price)
+	}
+
+	report, err := sdb.Reconcile(context.Background(), "items")
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !report.InSync || report.Tables[0].PrimaryRows != 2 || report.Tables[0].ShadowRows != 2 {
+		t.Errorf("Expected databases in sync, got %+v", report.Tables)
+	}
+	if stats := sdb.ReplicationStatus(); stats.Replicated != 2 || stats.Conflicts != 0 {
+		t.Errorf("Unexpected replication stats %+v", stats)
+	}
+}
+
+func TestWriteBothLogsConflicts(t *testing.T) {
+	var mu sync.Mutex
+	var conflicts []ReplicationConflict
+	sdb := newReplicatingShadowDB(t, t.TempDir(), ReplicationConfig{
+		MaxRetries: 2,
+		OnConflict: func(c ReplicationConflict) {
+			mu.Lock()
+			defer mu.Unlock()
+			conflicts = append(conflicts, c)
+		},
+	})
+	defer sdb.Close()
+
+	// Rows that exist only on the shadow
+	sdb.Shadow().Exec("INSERT INTO items (id, name) VALUES (1, 'stale'), (2, 'stale')")
+
+	sdb.ExecWrite("INSERT INTO items (id, name) VALUES (?, ?)", 1, "tea")
+	sdb.ExecWrite("UPDATE items SET name = ?", "renamed")
+	waitReplicated(t, sdb)
+
+	mu.Lock()
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Err == nil || conflicts[0].Args[0] != int64(1) {
+		t.Errorf("Expected failed insert conflict, got %+v", conflicts[0])
+	}
+	if c := conflicts[1]; c.Err != nil || c.PrimaryRows != 1 || c.ShadowRows != 2 {
+		t.Errorf("Expected row count conflict, got %+v", c)
+	}
+	mu.Unlock()
+
+	report, _ := sdb.Reconcile(context.Background(), "items")
+	if report.InSync || report.Tables[0].PrimaryRows != 1 || report.Tables[0].ShadowRows != 2 {
+		t.Errorf("Expected drift to be reported, got %+v", report.Tables)
+	}
+	if len(sdb.ReplicationConflicts()) != 2 {
+		t.Errorf("Expected recent conflicts to be kept")
+	}
+	if _, err := sdb.Reconcile(context.Background(), "items; DROP TABLE items"); err == nil {
+		t.Error("Expected invalid table name to be rejected")
+	}
+}
+
+func TestWriteBothDurableQueue(t *testing.T) {
+	dir := t.TempDir()
+	queue := ReplicationConfig{QueueDir: filepath.Join(dir, "queue")}
+
+	// Shadow is down: writes stay queued
+	sdb := newReplicatingShadowDB(t, dir, queue)
+	sdb.shadowHealth.updateStatus(StatusUnhealthy)
+	for i := 1; i <= 3; i++ {
+		if _, err := sdb.ExecWrite("INSERT INTO items (id, name) VALUES (?, ?)", i, []byte("item")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := sdb.ReplicationStatus(); stats.Pending != 3 {
+		t.Fatalf("Expected 3 pending writes, got %+v", stats)
+	}
+	sdb.Close()
+
+	// After a restart the queue is replayed
+	sdb = newReplicatingShadowDB(t, dir, queue)
+	defer sdb.Close()
+	waitReplicated(t, sdb)
+
+	var count int
+	sdb.Shadow().QueryRow("SELECT COUNT(*) FROM items WHERE CAST(name AS TEXT) = 'item'").Scan(&count)
+	if count != 3 {
+		t.Errorf("Expected 3 replayed rows on the shadow, got %d", count)
+	}
+	if report, _ := sdb.Reconcile(context.Background(), "items"); !report.InSync {
+		t.Errorf("Expected databases in sync, got %+v", report.Tables)
+	}
+}
@@ -40,9 +40,17 @@ const (
 	ReadRoundRobin   ReadStrategy = "round-robin"
 	ReadPrimaryFirst ReadStrategy = "primary-first"
 	ReadShadowFirst  ReadStrategy = "shadow-first"
+
+	// Replica strategies spread reads over the healthy Config.Replicas and
+	// fall back to primary-first when no replica is in the pool
+	ReadReplicaRoundRobin ReadStrategy = "replica-round-robin"
+	ReadLeastLatency      ReadStrategy = "least-latency"
+	ReadWeighted          ReadStrategy = "weighted"
 )
 
-// WriteStrategy defines how write operations are handled
+// WriteStrategy defines how write operations are handled. WriteBoth writes
+// to the primary and replicates successful writes to the shadow
+// asynchronously, see ReplicationConfig.
 type WriteStrategy string
 
 const (
@@ -59,12 +67,18 @@ type Config struct {
 	// Shadow database configuration
 	Shadow DBConfig
 
+	// Read replicas used by the replica read strategies
+	Replicas []ReplicaConfig
+
 	// Read strategy
 	ReadStrategy ReadStrategy
 
 	// Write strategy
 	WriteStrategy WriteStrategy
 
+	// Replication configures the WriteBoth strategy
+	Replication ReplicationConfig
+
 	// Auto failover enabled
 	AutoFailover bool
 
@@ -86,7 +100,7 @@ type Config struct {
 	// Failback callback
 	OnFailback func()
 
-	// Health status change callback
+	// Health status change callback, db is "primary", "shadow" or a replica name
 	OnHealthChange func(db string, oldStatus, newStatus DBStatus)
 }
 
@@ -125,6 +139,13 @@ type ShadowDB struct {
 	failoverLock    sync.Mutex
 	roundRobinCount uint64
 
+	replicas     []*replica
+	replicaMu    sync.Mutex
+	replicaCount uint64
+
+	replicator *replicator
+	metrics    metricsRecorder
+
 	stopHealthCheck chan struct{}
 	healthCheckWg   sync.WaitGroup
 }
@@ -163,6 +184,12 @@ func New(config Config) (*ShadowDB, error) {
 	if config.WriteStrategy == "" {
 		config.WriteStrategy = WritePrimaryOnly
 	}
+	if config.Replication.MaxRetries == 0 {
+		config.Replication.MaxRetries = 5
+	}
+	if config.Replication.RetryBackoff == 0 {
+		config.Replication.RetryBackoff = time.Second
+	}
 
 	sdb := &ShadowDB{
 		config:          config,
@@ -191,6 +218,19 @@ func New(config Config) (*ShadowDB, error) {
 		}
 	}
 
+	// Connect to read replicas
+	sdb.connectReplicas()
+
+	// Replicate primary writes to the shadow
+	if config.WriteStrategy == WriteBoth {
+		r, err := newReplicator(sdb, config.Replication)
+		if err != nil {
+			sdb.closeDBs()
+			return nil, err
+		}
+		sdb.replicator = r
+	}
+
 	// Start health checks
 	sdb.startHealthChecks()
 
@@ -199,25 +239,11 @@ func New(config Config) (*ShadowDB, error) {
 
 // connectPrimary establishes connection to primary database
 func (sdb *ShadowDB) connectPrimary() error {
-	db, err := sql.Open(sdb.config.Primary.Driver, sdb.config.Primary.DSN)
+	db, err := openDB(sdb.config.Primary)
 	if err != nil {
 		return err
 	}
 
-	// Configure connection pool
-	if sdb.config.Primary.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(sdb.config.Primary.MaxOpenConns)
-	}
-	if sdb.config.Primary.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(sdb.config.Primary.MaxIdleConns)
-	}
-	if sdb.config.Primary.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(sdb.config.Primary.ConnMaxLifetime)
-	}
-	if sdb.config.Primary.ConnMaxIdleTime > 0 {
-		db.SetConnMaxIdleTime(sdb.config.Primary.ConnMaxIdleTime)
-	}
-
 	// Test connection
 	if err := db.Ping(); err != nil {
 		db.Close()
@@ -231,25 +257,11 @@ func (sdb *ShadowDB) connectPrimary() error {
 
 // connectShadow establishes connection to shadow database
 func (sdb *ShadowDB) connectShadow() error {
-	db, err := sql.Open(sdb.config.Shadow.Driver, sdb.config.Shadow.DSN)
+	db, err := openDB(sdb.config.Shadow)
 	if err != nil {
 		return err
 	}
 
-	// Configure connection pool
-	if sdb.config.Shadow.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(sdb.config.Shadow.MaxOpenConns)
-	}
-	if sdb.config.Shadow.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(sdb.config.Shadow.MaxIdleConns)
-	}
-	if sdb.config.Shadow.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(sdb.config.Shadow.ConnMaxLifetime)
-	}
-	if sdb.config.Shadow.ConnMaxIdleTime > 0 {
-		db.SetConnMaxIdleTime(sdb.config.Shadow.ConnMaxIdleTime)
-	}
-
 	// Test connection
 	if err := db.Ping(); err != nil {
 		db.Close()
@@ -281,6 +293,18 @@ func (sdb *ShadowDB) Read() (*sql.DB, error) {
 	defer sdb.mu.RUnlock()
 
 	switch sdb.config.ReadStrategy {
+	case ReadReplicaRoundRobin, ReadLeastLatency, ReadWeighted:
+		if r := sdb.pickReplica(); r != nil {
+			return r.db, nil
+		}
+		if sdb.primary != nil && sdb.primaryHealth.isHealthy() {
+			return sdb.primary, nil
+		}
+		if sdb.shadow != nil && sdb.shadowHealth.isHealthy() {
+			return sdb.shadow, nil
+		}
+		return nil, ErrBothDBsDown
+
 	case ReadPrimaryOnly:
 		if sdb.primary != nil && sdb.primaryHealth.isHealthy() {
 			return sdb.primary, nil
@@ -378,6 +402,26 @@ func (sdb *ShadowDB) Close() error {
 	close(sdb.stopHealthCheck)
 	sdb.healthCheckWg.Wait()
 
+	// Stop replication; pending writes stay in the durable queue
+	var errs []error
+	if sdb.replicator != nil {
+		if err := sdb.replicator.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := sdb.closeDBs(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// closeDBs closes all database connections
+func (sdb *ShadowDB) closeDBs() error {
 	var errs []error
 
 	sdb.mu.Lock()
@@ -395,6 +439,12 @@ func (sdb *ShadowDB) Close() error {
 		}
 	}
 
+	for _, r := range sdb.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if len(errs) > 0 {
 		return errs[0]
 	}
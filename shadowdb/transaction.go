@@ -6,6 +6,7 @@ package shadowdb
 
 import (
 	"database/sql"
+	"time"
 )
 
 // Middleware integration helpers
@@ -24,33 +25,25 @@ type Transaction struct {
 	Primary *sql.Tx
 	Shadow  *sql.Tx
 	sdb     *ShadowDB
+
+	// replicate is set for WriteBoth transactions on the primary; stmts are
+	// queued for the shadow on commit
+	replicate bool
+	stmts     []replicatedStmt
 }
 
 // BeginTx starts a transaction based on write strategy
 func (sdb *ShadowDB) BeginTx() (*Transaction, error) {
 	tx := &Transaction{sdb: sdb}
 
-	if sdb.config.WriteStrategy == WriteBoth {
-		// Start transaction on both databases
-		if sdb.primary != nil && sdb.primaryHealth.isHealthy() {
-			primaryTx, err := sdb.primary.Begin()
-			if err != nil {
-				return nil, err
-			}
-			tx.Primary = primaryTx
-		}
-
-		if sdb.shadow != nil && sdb.shadowHealth.isHealthy() {
-			shadowTx, err := sdb.shadow.Begin()
-			if err != nil {
-				// Rollback primary if shadow fails
-				if tx.Primary != nil {
-					tx.Primary.Rollback()
-				}
-				return nil, err
-			}
-			tx.Shadow = shadowTx
+	if sdb.config.WriteStrategy == WriteBoth && sdb.primary != nil && sdb.primaryHealth.isHealthy() {
+		// Run on the primary and replicate to the shadow after commit
+		primaryTx, err := sdb.primary.Begin()
+		if err != nil {
+			return nil, err
 		}
+		tx.Primary = primaryTx
+		tx.replicate = true
 	} else {
 		// Single transaction based on active database
 		db, err := sdb.Write()
@@ -75,6 +68,16 @@ func (sdb *ShadowDB) BeginTx() (*Transaction, error) {
 
 // Commit commits all transactions
 func (tx *Transaction) Commit() error {
+	if tx.replicate {
+		if err := tx.Primary.Commit(); err != nil {
+			return err
+		}
+		if len(tx.stmts) > 0 {
+			tx.sdb.replicator.enqueue(tx.stmts)
+		}
+		return nil
+	}
+
 	var errs []error
 
 	if tx.Primary != nil {
@@ -121,35 +124,33 @@ func (tx *Transaction) Rollback() error {
 
 // Exec executes a query on appropriate database(s)
 func (tx *Transaction) Exec(query string, args ...interface{}) (sql.Result, error) {
-	if tx.sdb.config.WriteStrategy == WriteBoth {
-		// Execute on both
-		var result sql.Result
-		var err error
-
-		if tx.Primary != nil {
-			result, err = tx.Primary.Exec(query, args...)
-			if err != nil {
-				return nil, err
-			}
+	if tx.replicate {
+		stmt, err := newStmt(query, args)
+		if err != nil {
+			return nil, err
 		}
-
-		if tx.Shadow != nil {
-			_, err = tx.Shadow.Exec(query, args...)
-			if err != nil {
-				// Log error but don't fail the transaction
-				// In production, you might want to handle this differently
-			}
+		start := time.Now()
+		result, err := tx.Primary.Exec(query, args...)
+		tx.sdb.metrics.observe("primary", start, err)
+		if err != nil {
+			return nil, err
 		}
-
+		stmt.Rows = rowsAffected(result)
+		tx.stmts = append(tx.stmts, stmt)
 		return result, nil
 	}
 
 	// Execute on active database
+	start := time.Now()
 	if tx.Primary != nil {
-		return tx.Primary.Exec(query, args...)
+		result, err := tx.Primary.Exec(query, args...)
+		tx.sdb.metrics.observe("primary", start, err)
+		return result, err
 	}
 	if tx.Shadow != nil {
-		return tx.Shadow.Exec(query, args...)
+		result, err := tx.Shadow.Exec(query, args...)
+		tx.sdb.metrics.observe("shadow", start, err)
+		return result, err
 	}
 
 	return nil, ErrBothDBsDown
@@ -157,22 +158,32 @@ func (tx *Transaction) Exec(query string, args ...interface{}) (sql.Result, erro
 
 // Query executes a query and returns rows
 func (tx *Transaction) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
 	if tx.Primary != nil {
-		return tx.Primary.Query(query, args...)
+		rows, err := tx.Primary.Query(query, args...)
+		tx.sdb.metrics.observe("primary", start, err)
+		return rows, err
 	}
 	if tx.Shadow != nil {
-		return tx.Shadow.Query(query, args...)
+		rows, err := tx.Shadow.Query(query, args...)
+		tx.sdb.metrics.observe("shadow", start, err)
+		return rows, err
 	}
 	return nil, ErrBothDBsDown
 }
 
 // QueryRow executes a query that returns at most one row
 func (tx *Transaction) QueryRow(query string, args ...interface{}) *sql.Row {
+	start := time.Now()
 	if tx.Primary != nil {
-		return tx.Primary.QueryRow(query, args...)
+		row := tx.Primary.QueryRow(query, args...)
+		tx.sdb.metrics.observe("primary", start, row.Err())
+		return row
 	}
 	if tx.Shadow != nil {
-		return tx.Shadow.QueryRow(query, args...)
+		row := tx.Shadow.QueryRow(query, args...)
+		tx.sdb.metrics.observe("shadow", start, row.Err())
+		return row
 	}
 	// Return a row that will error on Scan
 	return nil
@@ -182,24 +193,21 @@ func (tx *Transaction) QueryRow(query string, args ...interface{}) *sql.Row {
 
 // ExecWrite executes a write query on the appropriate database(s)
 func (sdb *ShadowDB) ExecWrite(query string, args ...interface{}) (sql.Result, error) {
-	if sdb.config.WriteStrategy == WriteBoth {
-		var result sql.Result
-		var err error
-
-		// Execute on primary
-		if sdb.primary != nil && sdb.primaryHealth.isHealthy() {
-			result, err = sdb.primary.Exec(query, args...)
-			if err != nil {
-				return nil, err
-			}
+	if sdb.config.WriteStrategy == WriteBoth && sdb.primary != nil && sdb.primaryHealth.isHealthy() {
+		stmt, err := newStmt(query, args)
+		if err != nil {
+			return nil, err
 		}
-
-		// Execute on shadow
-		if sdb.shadow != nil && sdb.shadowHealth.isHealthy() {
-			_, _ = sdb.shadow.Exec(query, args...)
-			// Ignore shadow errors in dual-write mode
+		start := time.Now()
+		result, err := sdb.primary.Exec(query, args...)
+		sdb.observe(sdb.primary, start, err)
+		if err != nil {
+			return nil, err
 		}
 
+		// Replicate to the shadow in the background
+		stmt.Rows = rowsAffected(result)
+		sdb.replicator.enqueue([]replicatedStmt{stmt})
 		return result, nil
 	}
 
@@ -209,7 +217,10 @@ func (sdb *ShadowDB) ExecWrite(query string, args ...interface{}) (sql.Result, e
 		return nil, err
 	}
 
-	return db.Exec(query, args...)
+	start := time.Now()
+	result, err := db.Exec(query, args...)
+	sdb.observe(db, start, err)
+	return result, err
 }
 
 // QueryRead executes a read query on the appropriate database
@@ -219,7 +230,10 @@ func (sdb *ShadowDB) QueryRead(query string, args ...interface{}) (*sql.Rows, er
 		return nil, err
 	}
 
-	return db.Query(query, args...)
+	start := time.Now()
+	rows, err := db.Query(query, args...)
+	sdb.observe(db, start, err)
+	return rows, err
 }
 
 // QueryRowRead executes a read query that returns at most one row
@@ -230,5 +244,8 @@ func (sdb *ShadowDB) QueryRowRead(query string, args ...interface{}) *sql.Row {
 		return nil
 	}
 
-	return db.QueryRow(query, args...)
+	start := time.Now()
+	row := db.QueryRow(query, args...)
+	sdb.observe(db, start, row.Err())
+	return row
 }
@@ -0,0 +1,184 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SSEHubConfig holds configuration for NewSSEHubWithConfig
+type SSEHubConfig struct {
+	// BufferSize is the number of events queued per client
+	// Default: 64
+	BufferSize int
+
+	// Overflow decides what happens when a client's queue is full:
+	// OverflowDrop and OverflowDropOldest drop an event and
+	// OverflowDisconnect ends the slow client's stream. OverflowBlock is
+	// treated as OverflowDrop, as one client must not stall the others.
+	// Default: OverflowDrop
+	Overflow OverflowPolicy
+
+	// Heartbeat is the interval of comment lines that keep idle streams
+	// open through proxies
+	// Default: 15s
+	Heartbeat time.Duration
+}
+
+// SSEHub fans out Server-Sent Events to the clients subscribed to rooms
+type SSEHub struct {
+	config  SSEHubConfig
+	mu      sync.RWMutex
+	clients map[*sseClient]bool
+
+	dropped, disconnected atomic.Int64
+}
+
+type sseClient struct {
+	rooms  []string
+	events chan SSEvent
+
+	// kicked is closed to disconnect a slow client
+	kicked   chan struct{}
+	kickOnce sync.Once
+}
+
+// NewSSEHub creates an SSE hub
+func NewSSEHub() *SSEHub {
+	return NewSSEHubWithConfig(SSEHubConfig{})
+}
+
+// NewSSEHubWithConfig creates an SSE hub with custom configuration
+func NewSSEHubWithConfig(config SSEHubConfig) *SSEHub {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 64
+	}
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = 15 * time.Second
+	}
+	return &SSEHub{config: config, clients: make(map[*sseClient]bool)}
+}
+
+// Serve streams the events of rooms to the client until it disconnects:
+//
+//	r.GET("/orders/live", func(c *goTap.Context) {
+//	    hub.Serve(c, "store:"+c.Query("store"))
+//	})
+func (h *SSEHub) Serve(c *Context, rooms ...string) {
+	client := &sseClient{rooms: rooms, events: make(chan SSEvent, h.config.BufferSize), kicked: make(chan struct{})}
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client)
+		h.mu.Unlock()
+	}()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.config.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-client.kicked:
+			return
+		case event := <-client.events:
+			if err := event.Render(c.Writer); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// Publish sends event to the clients subscribed to room
+func (h *SSEHub) Publish(room string, event SSEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if containsString(client.rooms, room) {
+			h.send(client, event)
+		}
+	}
+}
+
+// Broadcast sends event to every client
+func (h *SSEHub) Broadcast(event SSEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		h.send(client, event)
+	}
+}
+
+// send queues event for client, applying the overflow policy when the
+// client's queue is full
+func (h *SSEHub) send(client *sseClient, event SSEvent) {
+	for {
+		select {
+		case client.events <- event:
+			return
+		default:
+		}
+		h.dropped.Add(1)
+		switch h.config.Overflow {
+		case OverflowDropOldest:
+			select {
+			case <-client.events:
+			default:
+			}
+			continue
+		case OverflowDisconnect:
+			client.kickOnce.Do(func() {
+				h.disconnected.Add(1)
+				close(client.kicked)
+			})
+		default:
+			debugPrint("[WARNING] SSE client queue full, dropping event %s", event.ID)
+		}
+		return
+	}
+}
+
+// Stats reports the queues of the connected clients, with the events
+// dropped and the clients disconnected since the hub was created
+func (h *SSEHub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := HubStats{
+		Clients:      len(h.clients),
+		Dropped:      h.dropped.Load(),
+		Disconnected: int(h.disconnected.Load()),
+	}
+	for client := range h.clients {
+		stats.Queued += len(client.events)
+		stats.MaxQueued = max(stats.MaxQueued, len(client.events))
+	}
+	return stats
+}
+
+// ClientCount returns the number of connected clients
+func (h *SSEHub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import "testing"
+
+func TestSSEHubOverflow(t *testing.T) {
+	addClient := func(h *SSEHub) *sseClient {
+		client := &sseClient{rooms: []string{"store:1"}, events: make(chan SSEvent, 1), kicked: make(chan struct{})}
+		h.clients[client] = true
+		return client
+	}
+
+	hub := NewSSEHubWithConfig(SSEHubConfig{BufferSize: 1, Overflow: OverflowDropOldest})
+	client := addClient(hub)
+	hub.Publish("store:1", SSEvent{ID: "1"})
+	hub.Publish("store:1", SSEvent{ID: "2"})
+	if s := hub.Stats(); s.Dropped != 1 || s.Queued != 1 || (<-client.events).ID != "2" {
+		t.Errorf("Expected the oldest event to be dropped, got %+v", s)
+	}
+
+	hub = NewSSEHubWithConfig(SSEHubConfig{BufferSize: 1, Overflow: OverflowDisconnect})
+	client = addClient(hub)
+	for _, id := range []string{"1", "2", "3"} {
+		hub.Broadcast(SSEvent{ID: id})
+	}
+	select {
+	case <-client.kicked:
+	default:
+		t.Error("Expected the slow client to be disconnected")
+	}
+	if s := hub.Stats(); s.Disconnected != 1 || s.Dropped != 2 || s.MaxQueued != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StartFailurePolicy decides what a failing start hook does
+type StartFailurePolicy int
+
+const (
+	// StartAbort stops the server from starting: Run returns the error
+	StartAbort StartFailurePolicy = iota
+	// StartContinue logs a warning and starts the server, e.g. for cache
+	// warming the first requests can do themselves
+	StartContinue
+)
+
+// StartHookConfig holds configuration for a start hook
+type StartHookConfig struct {
+	// Name identifies the hook in logs and errors
+	// Default: the function name
+	Name string
+
+	// Timeout bounds each attempt of the hook through its context
+	// Default: 30s
+	Timeout time.Duration
+
+	// Retries is the number of further attempts after a failure, e.g.
+	// while a database is still starting
+	// Default: 0
+	Retries int
+
+	// RetryDelay is the pause between attempts
+	// Default: 1s
+	RetryDelay time.Duration
+
+	// Policy decides what happens when the hook still fails
+	// Default: StartAbort
+	Policy StartFailurePolicy
+}
+
+// startHook is a hook added with OnStart
+type startHook struct {
+	fn     func(ctx context.Context) error
+	config StartHookConfig
+}
+
+// StartHookError reports the start hook that kept the server from starting
+type StartHookError struct {
+	Name string
+	Err  error
+}
+
+func (e *StartHookError) Error() string {
+	return fmt.Sprintf("start hook %s failed: %v", e.Name, e.Err)
+}
+
+func (e *StartHookError) Unwrap() error {
+	return e.Err
+}
+
+// OnStart adds a hook run before the server accepts traffic, so the first
+// requests after a deploy don't pay for cold caches and connections:
+//
+//	r.OnStart(func(ctx context.Context) error {
+//	    return redisClient.Ping(ctx).Err()
+//	})
+//	r.OnStartWithConfig(primeCatalog, goTap.StartHookConfig{
+//	    Name:    "catalog",
+//	    Timeout: 2 * time.Minute,
+//	    Policy:  goTap.StartContinue,
+//	})
+//
+// Hooks run one after the other in the order they were added, each
+// attempt bounded by a 30 second timeout, when Run, RunTLS, RunServer,
+// RunListener, RunUnix, RunSystemd, RunGraceful or RunConfig starts. A
+// failing hook keeps the server from starting.
+func (engine *Engine) OnStart(hook func(ctx context.Context) error) {
+	engine.OnStartWithConfig(hook, StartHookConfig{})
+}
+
+// OnStartWithConfig adds a start hook with config
+func (engine *Engine) OnStartWithConfig(hook func(ctx context.Context) error, config StartHookConfig) {
+	if config.Name == "" {
+		config.Name = nameOfFunction(hook)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	engine.servicesMu.Lock()
+	defer engine.servicesMu.Unlock()
+	engine.startHooks = append(engine.startHooks, startHook{fn: hook, config: config})
+}
+
+// RunStartHooks runs the OnStart hooks, for servers not started by the Run
+// methods, e.g. with Config.HTTPServer. It returns a *StartHookError for
+// the first hook failing with StartAbort.
+func (engine *Engine) RunStartHooks(ctx context.Context) error {
+	engine.servicesMu.Lock()
+	hooks := engine.startHooks
+	engine.servicesMu.Unlock()
+
+	for _, hook := range hooks {
+		start := time.Now()
+		err := hook.run(ctx)
+		if err == nil {
+			debugPrint("Start hook %s done in %v", hook.config.Name, time.Since(start))
+			continue
+		}
+		if hook.config.Policy == StartContinue {
+			debugPrint("[WARNING] start hook %s failed, starting anyway: %v", hook.config.Name, err)
+			continue
+		}
+		return &StartHookError{Name: hook.config.Name, Err: err}
+	}
+	return nil
+}
+
+// run calls the hook until it succeeds or runs out of retries
+func (h startHook) run(ctx context.Context) error {
+	var err error
+	for attempt := 0; attempt <= h.config.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(h.config.RetryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+		err = h.fn(attemptCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
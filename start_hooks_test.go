@@ -0,0 +1,105 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRunStartHooks(t *testing.T) {
+	r := New()
+	var order []string
+	r.OnStart(func(ctx context.Context) error {
+		order = append(order, "db")
+		return nil
+	})
+	r.OnStartWithConfig(func(ctx context.Context) error {
+		order = append(order, "cache")
+		return errors.New("redis down")
+	}, StartHookConfig{Name: "cache", Policy: StartContinue})
+	r.OnStart(func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("hook context has no deadline")
+		}
+		order = append(order, "templates")
+		return nil
+	})
+
+	if err := r.RunStartHooks(context.Background()); err != nil {
+		t.Fatalf("RunStartHooks: %v", err)
+	}
+	if len(order) != 3 || order[0] != "db" || order[1] != "cache" || order[2] != "templates" {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestRunStartHooksRetries(t *testing.T) {
+	r := New()
+	attempts := 0
+	r.OnStartWithConfig(func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	}, StartHookConfig{Retries: 2, RetryDelay: time.Millisecond})
+
+	if err := r.RunStartHooks(context.Background()); err != nil {
+		t.Fatalf("RunStartHooks: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRunStartHooksTimeout(t *testing.T) {
+	r := New()
+	r.OnStartWithConfig(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, StartHookConfig{Name: "slow", Timeout: 10 * time.Millisecond})
+
+	err := r.RunStartHooks(context.Background())
+	var hookErr *StartHookError
+	if !errors.As(err, &hookErr) || hookErr.Name != "slow" {
+		t.Fatalf("err = %v, want *StartHookError for slow", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestRunListenerStartHookAbort(t *testing.T) {
+	r := New()
+	warmed := false
+	r.OnStartWithConfig(func(ctx context.Context) error {
+		return errors.New("migrations pending")
+	}, StartHookConfig{Name: "migrations"})
+	r.OnStart(func(ctx context.Context) error {
+		warmed = true
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RunListener(l)
+	var hookErr *StartHookError
+	if !errors.As(err, &hookErr) || hookErr.Name != "migrations" {
+		t.Fatalf("err = %v, want *StartHookError for migrations", err)
+	}
+	if warmed {
+		t.Error("hook after the failing one ran")
+	}
+	// The listener is closed
+	if _, err := l.Accept(); err == nil {
+		t.Error("listener still open")
+	}
+}
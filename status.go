@@ -0,0 +1,219 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// StatusSchema identifies the version of the StatusReport schema. Fields
+// are only ever added to a schema version, never renamed or removed.
+const StatusSchema = "gotap.status/v1"
+
+// processStart is when the process started serving, for uptime
+var processStart = time.Now()
+
+// StatusReport is the response of the Status endpoint
+type StatusReport struct {
+	Schema         string             `json:"schema"`
+	Service        string             `json:"service,omitempty"`
+	Version        string             `json:"version"`
+	Framework      string             `json:"framework"`
+	Mode           string             `json:"mode"`
+	Host           string             `json:"host"`
+	StartedAt      time.Time          `json:"started_at"`
+	UptimeSeconds  int64              `json:"uptime_seconds"`
+	Build          StatusBuild        `json:"build"`
+	Runtime        StatusRuntime      `json:"runtime"`
+	Dependencies   []DependencyStatus `json:"dependencies"`
+	ConfigChecksum string             `json:"config_checksum,omitempty"`
+}
+
+// StatusBuild describes the binary, from its embedded build information
+type StatusBuild struct {
+	Module    string `json:"module,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// StatusRuntime reports Go runtime statistics
+type StatusRuntime struct {
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+	NumCPU         int    `json:"num_cpu"`
+	GOMAXPROCS     int    `json:"gomaxprocs"`
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64 `json:"heap_sys_bytes"`
+	TotalAlloc     uint64 `json:"total_alloc_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+	LastGCPauseNs  uint64 `json:"last_gc_pause_ns"`
+}
+
+// DependencyStatus reports the server version of a dependency
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Version string `json:"version,omitempty"`
+	// Status is "ok", or "error" when the version probe failed
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StatusProbe reads the server version of a dependency, see
+// RedisStatusProbe, MongoStatusProbe and GormStatusProbe
+type StatusProbe struct {
+	// Name identifies the dependency, e.g. "orders-db"
+	Name string
+	// Kind is the type of server, e.g. "postgres" or "redis"
+	Kind string
+	// Version returns the server version
+	Version func(ctx context.Context) (string, error)
+}
+
+// StatusConfig holds configuration for StatusWithConfig
+type StatusConfig struct {
+	// Service names the application
+	// Optional.
+	Service string
+
+	// Version of the application
+	// Default: the main module version from the build information
+	Version string
+
+	// Probes read dependency versions once, when the handler is created
+	Probes []StatusProbe
+
+	// ProbeTimeout bounds each probe
+	// Default: 5 seconds
+	ProbeTimeout time.Duration
+
+	// Config is the application's configuration; its SHA-256 checksum is
+	// reported so drift between deployments is visible without exposing
+	// values
+	// Optional.
+	Config any
+}
+
+// Status returns a handler reporting uptime, version, mode and runtime
+// statistics, see StatusWithConfig
+func Status() HandlerFunc {
+	return StatusWithConfig(StatusConfig{})
+}
+
+// StatusWithConfig returns a handler reporting the service's status as a
+// StatusReport, one schema monitoring agents can scrape across deployments:
+//
+//	r.GET("/status", goTap.StatusWithConfig(goTap.StatusConfig{
+//	    Service: "pos-api",
+//	    Probes:  []goTap.StatusProbe{goTap.GormStatusProbe("db", db), goTap.RedisStatusProbe("cache", rdb)},
+//	    Config:  cfg,
+//	}))
+//
+// Dependency versions are probed in parallel when the handler is created.
+func StatusWithConfig(config StatusConfig) HandlerFunc {
+	if config.ProbeTimeout <= 0 {
+		config.ProbeTimeout = 5 * time.Second
+	}
+
+	build := StatusBuild{GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build.Module = info.Main.Path
+		if config.Version == "" && info.Main.Version != "(devel)" {
+			config.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				build.Revision = s.Value
+			case "vcs.time":
+				build.Time = s.Value
+			case "vcs.modified":
+				build.Modified = s.Value == "true"
+			}
+		}
+	}
+	if config.Version == "" {
+		config.Version = "unknown"
+	}
+
+	var checksum string
+	if config.Config != nil {
+		if data, err := json.Marshal(config.Config); err == nil {
+			sum := sha256.Sum256(data)
+			checksum = "sha256:" + hex.EncodeToString(sum[:])
+		}
+	}
+
+	host, _ := os.Hostname()
+	dependencies := probeDependencies(config.Probes, config.ProbeTimeout)
+
+	return func(c *Context) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(200, StatusReport{
+			Schema:         StatusSchema,
+			Service:        config.Service,
+			Version:        config.Version,
+			Framework:      "goTap " + Version,
+			Mode:           Mode(),
+			Host:           host,
+			StartedAt:      processStart.UTC(),
+			UptimeSeconds:  int64(time.Since(processStart).Seconds()),
+			Build:          build,
+			Runtime:        statusRuntime(&mem),
+			Dependencies:   dependencies,
+			ConfigChecksum: checksum,
+		})
+	}
+}
+
+func statusRuntime(mem *runtime.MemStats) StatusRuntime {
+	return StatusRuntime{
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		NumCPU:         runtime.NumCPU(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+		TotalAlloc:     mem.TotalAlloc,
+		NumGC:          mem.NumGC,
+		LastGCPauseNs:  mem.PauseNs[(mem.NumGC+255)%256],
+	}
+}
+
+func probeDependencies(probes []StatusProbe, timeout time.Duration) []DependencyStatus {
+	dependencies := make([]DependencyStatus, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			dep := DependencyStatus{Name: probe.Name, Kind: probe.Kind, Status: "ok"}
+			version, err := probe.Version(ctx)
+			if err != nil {
+				dep.Status, dep.Error = "error", err.Error()
+			}
+			dep.Version = version
+			dependencies[i] = dep
+		}()
+	}
+	wg.Wait()
+	return dependencies
+}
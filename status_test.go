@@ -0,0 +1,59 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+func TestStatus(t *testing.T) {
+	probes := []StatusProbe{
+		{Name: "search", Kind: "meilisearch", Version: func(ctx context.Context) (string, error) { return "1.8.0", nil }},
+		{Name: "queue", Kind: "nats", Version: func(ctx context.Context) (string, error) { return "", errors.New("connection refused") }},
+	}
+	if db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent}); err == nil {
+		probes = append(probes, GormStatusProbe("db", db))
+	}
+
+	type appConfig struct{ Port int }
+	r := New()
+	r.GET("/status", StatusWithConfig(StatusConfig{Service: "pos-api", Version: "1.4.2", Probes: probes, Config: appConfig{Port: 8080}}))
+	r.GET("/plain", Status())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	var report StatusReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Schema != StatusSchema || report.Service != "pos-api" || report.Version != "1.4.2" || report.Framework != "goTap "+Version ||
+		report.Mode != Mode() || report.Runtime.Goroutines == 0 || report.Build.GoVersion == "" || report.StartedAt.IsZero() {
+		t.Errorf("Unexpected report %s", w.Body.String())
+	}
+	if !strings.HasPrefix(report.ConfigChecksum, "sha256:") || len(report.ConfigChecksum) != 71 {
+		t.Errorf("Unexpected config checksum %q", report.ConfigChecksum)
+	}
+	deps := report.Dependencies
+	if deps[0] != (DependencyStatus{Name: "search", Kind: "meilisearch", Version: "1.8.0", Status: "ok"}) ||
+		deps[1].Status != "error" || deps[1].Error != "connection refused" {
+		t.Errorf("Unexpected dependencies %+v", deps)
+	}
+	if len(deps) == 3 && (deps[2].Kind != "sqlite" || deps[2].Status != "ok" || !strings.HasPrefix(deps[2].Version, "3.")) {
+		t.Errorf("Unexpected database dependency %+v", deps[2])
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/plain", nil))
+	if !strings.Contains(w.Body.String(), `"dependencies":[]`) || strings.Contains(w.Body.String(), "config_checksum") {
+		t.Errorf("Unexpected plain status %s", w.Body.String())
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateTestContext returns a context writing to w and a new engine, for
+// unit testing handlers without routing a request. The context holds a
+// GET / request until c.Request is replaced.
+func CreateTestContext(w http.ResponseWriter) (c *Context, r *Engine) {
+	r = New()
+	c = CreateTestContextOnly(w, r)
+	return
+}
+
+// CreateTestContextOnly returns a context writing to w for the given engine
+func CreateTestContextOnly(w http.ResponseWriter, r *Engine) *Context {
+	c := r.allocateContext(r.maxParams)
+	c.reset()
+	c.writermem.reset(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	return c
+}
+
+// AddParam adds a path parameter, e.g. for handlers run by a test context
+func (c *Context) AddParam(key, value string) {
+	c.Params = append(c.Params, Param{Key: key, Value: value})
+}
+
+// TestContextBuilder builds a context for unit testing a handler with its
+// params, query, body and injected stores:
+//
+//	w := httptest.NewRecorder()
+//	goTap.NewTestContext(w).
+//	    Request("PUT", "/products/42").
+//	    Param("id", "42").
+//	    JSON(goTap.H{"price": 3.5}).
+//	    Gorm(db).
+//	    Handle(UpdateProduct)
+type TestContextBuilder struct {
+	w         http.ResponseWriter
+	engine    *Engine
+	method    string
+	target    string
+	params    Params
+	query     url.Values
+	header    http.Header
+	body      []byte
+	keys      map[string]any
+	encodeErr error
+}
+
+// NewTestContext returns a builder for a GET / request writing to w
+func NewTestContext(w http.ResponseWriter) *TestContextBuilder {
+	return &TestContextBuilder{
+		w:      w,
+		method: http.MethodGet,
+		target: "/",
+		query:  url.Values{},
+		header: http.Header{},
+		keys:   map[string]any{},
+	}
+}
+
+// Engine sets the engine of the context, e.g. to use its HTML templates
+// Default: New()
+func (b *TestContextBuilder) Engine(engine *Engine) *TestContextBuilder {
+	b.engine = engine
+	return b
+}
+
+// Request sets the method and target, which may include a query
+func (b *TestContextBuilder) Request(method, target string) *TestContextBuilder {
+	b.method, b.target = method, target
+	return b
+}
+
+// Param adds a path parameter
+func (b *TestContextBuilder) Param(key, value string) *TestContextBuilder {
+	b.params = append(b.params, Param{Key: key, Value: value})
+	return b
+}
+
+// Query adds a query parameter
+func (b *TestContextBuilder) Query(key, value string) *TestContextBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Header sets a request header
+func (b *TestContextBuilder) Header(key, value string) *TestContextBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// Body sets the request body with contentType
+func (b *TestContextBuilder) Body(contentType string, body []byte) *TestContextBuilder {
+	b.body = body
+	b.header.Set("Content-Type", contentType)
+	return b
+}
+
+// JSON sets v encoded as JSON as the request body
+func (b *TestContextBuilder) JSON(v any) *TestContextBuilder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		b.encodeErr = err
+		return b
+	}
+	return b.Body(MIMEJSON, body)
+}
+
+// Form sets values as a URL encoded form body
+func (b *TestContextBuilder) Form(values url.Values) *TestContextBuilder {
+	return b.Body(MIMEPOSTForm, []byte(values.Encode()))
+}
+
+// Set stores a value in the context keys, like a middleware would
+func (b *TestContextBuilder) Set(key string, value any) *TestContextBuilder {
+	b.keys[key] = value
+	return b
+}
+
+// Gorm injects db as GormInject does, e.g. an in-memory SQLite database
+func (b *TestContextBuilder) Gorm(db *DB) *TestContextBuilder {
+	return b.Set("gorm", db)
+}
+
+// Redis injects client as RedisInject does, e.g. connected to miniredis
+func (b *TestContextBuilder) Redis(client *RedisClient) *TestContextBuilder {
+	return b.Set("redis", client)
+}
+
+// Mongo injects client as MongoInject does
+func (b *TestContextBuilder) Mongo(client *MongoClient) *TestContextBuilder {
+	return b.Set("mongodb", client)
+}
+
+// Claims authenticates the request as JWTAuth does after verifying a token
+// with claims
+func (b *TestContextBuilder) Claims(claims *JWTClaims) *TestContextBuilder {
+	b.Set("jwt_claims", claims)
+	return b.Set("user_id", claims.UserID)
+}
+
+// Build returns the context. It panics if the JSON body couldn't be
+// encoded.
+func (b *TestContextBuilder) Build() *Context {
+	if b.encodeErr != nil {
+		panic("goTap: encoding the test context JSON body: " + b.encodeErr.Error())
+	}
+	engine := b.engine
+	if engine == nil {
+		engine = New()
+	}
+	c := CreateTestContextOnly(b.w, engine)
+
+	var body io.Reader
+	if b.body != nil {
+		body = bytes.NewReader(b.body)
+	}
+	req, err := http.NewRequest(b.method, b.target, body)
+	if err != nil {
+		panic("goTap: invalid test context request: " + err.Error())
+	}
+	if len(b.query) > 0 {
+		q := req.URL.Query()
+		for key, values := range b.query {
+			q[key] = append(q[key], values...)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+	for key, values := range b.header {
+		req.Header[key] = values
+	}
+	c.Request = req
+	c.Params = append(c.Params, b.params...)
+	for key, value := range b.keys {
+		c.Set(key, value)
+	}
+	return c
+}
+
+// Handle builds the context and runs handlers on it as a route's chain,
+// so middleware calling c.Next or c.Abort behaves as when routed
+func (b *TestContextBuilder) Handle(handlers ...HandlerFunc) *Context {
+	c := b.Build()
+	c.handlers = handlers
+	c.Next()
+	return c
+}
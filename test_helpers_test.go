@@ -0,0 +1,89 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+func TestCreateTestContext(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, r := CreateTestContext(w)
+	if r == nil || c.engine != r || c.Request == nil {
+		t.Fatal("Expected a context bound to the engine with a request")
+	}
+	c.AddParam("id", "42")
+	c.JSON(200, H{"id": c.Param("id"), "q": c.Query("q")})
+	if w.Code != 200 || strings.TrimSpace(w.Body.String()) != `{"id":"42","q":""}` {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTestContextBuilder(t *testing.T) {
+	type update struct {
+		Price float64 `json:"price" binding:"required"`
+	}
+	var seen []string
+	auth := func(c *Context) {
+		seen = append(seen, "auth")
+		if _, ok := GetJWTClaims(c); !ok {
+			c.AbortWithStatus(401)
+			return
+		}
+		c.Next()
+	}
+	handler := func(c *Context) {
+		seen = append(seen, "handler")
+		var req update
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, H{"error": err.Error()})
+			return
+		}
+		store, _ := c.Get("store")
+		c.JSON(200, H{"id": c.Param("id"), "expand": c.Query("expand"), "price": req.Price, "store": store, "user": c.MustGet("user_id")})
+	}
+
+	w := httptest.NewRecorder()
+	NewTestContext(w).
+		Request("PUT", "/products/42").
+		Param("id", "42").
+		Query("expand", "stock").
+		JSON(H{"price": 3.5}).
+		Set("store", "main").
+		Claims(&JWTClaims{UserID: "7"}).
+		Handle(auth, handler)
+	want := `{"expand":"stock","id":"42","price":3.5,"store":"main","user":"7"}`
+	if w.Code != 200 || strings.TrimSpace(w.Body.String()) != want {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if strings.Join(seen, ",") != "auth,handler" {
+		t.Errorf("Expected the chain to run in order, got %v", seen)
+	}
+
+	seen = nil
+	w = httptest.NewRecorder()
+	c := NewTestContext(w).Form(url.Values{"price": {"1"}}).Handle(auth, handler)
+	if w.Code != 401 || !c.IsAborted() || len(seen) != 1 {
+		t.Errorf("Expected the middleware to abort, got %d %v", w.Code, seen)
+	}
+}
+
+func TestTestContextStores(t *testing.T) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Skipf("Skipping store tests: sqlite not available (%v)", err)
+	}
+	redis := &RedisClient{}
+	mongo := &MongoClient{}
+	c := NewTestContext(httptest.NewRecorder()).Gorm(db).Redis(redis).Mongo(mongo).Build()
+	if MustGetGorm(c) != db || MustGetRedis(c) != redis || MustGetMongo(c) != mongo {
+		t.Error("Expected the injected stores")
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Session2FAKey is the session key holding when the session passed 2FA,
+// as Unix seconds
+const Session2FAKey = "2fa_verified_at"
+
+// TOTPConfig holds configuration for TOTP codes. The defaults match
+// authenticator apps such as Google Authenticator.
+type TOTPConfig struct {
+	// Period a code is valid for
+	// Default: 30 seconds
+	Period time.Duration
+
+	// Digits of a code
+	// Default: 6
+	Digits int
+
+	// Skew is how many periods before and after the current one are
+	// accepted, to allow for clock drift
+	// Default: 1
+	Skew uint
+}
+
+func (config *TOTPConfig) defaults() {
+	if config.Period <= 0 {
+		config.Period = 30 * time.Second
+	}
+	if config.Digits <= 0 {
+		config.Digits = 6
+	}
+}
+
+// TOTPKey is a generated TOTP secret
+type TOTPKey struct {
+	// Secret is the base32 secret; store it with the user
+	Secret string `json:"secret"`
+
+	// URL is the otpauth:// URI authenticator apps scan
+	URL string `json:"url"`
+}
+
+// GenerateTOTP generates a TOTP secret for account, e.g. the user's email,
+// shown in authenticator apps under issuer:
+//
+//	key, err := goTap.GenerateTOTP("VervePOS", user.Email)
+//	// Store key.Secret, show key.URL as a QR code, then confirm a first
+//	// code with VerifyTOTP before enabling 2FA
+func GenerateTOTP(issuer, account string) (*TOTPKey, error) {
+	return GenerateTOTPWithConfig(issuer, account, TOTPConfig{})
+}
+
+// GenerateTOTPWithConfig generates a TOTP secret with config
+func GenerateTOTPWithConfig(issuer, account string, config TOTPConfig) (*TOTPKey, error) {
+	config.defaults()
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      uint(config.Period / time.Second),
+		Digits:      otp.Digits(config.Digits),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPKey{Secret: key.Secret(), URL: key.URL()}, nil
+}
+
+// PNG renders the otpauth:// URI as a size x size QR code
+func (k *TOTPKey) PNG(size int) ([]byte, error) {
+	key, err := otp.NewKeyFromURL(k.URL)
+	if err != nil {
+		return nil, err
+	}
+	img, err := key.Image(size, size)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TOTPQRCode returns a handler serving the QR code of the key returned by
+// keyFunc as a 256x256 PNG, e.g. for the pending key of an enrollment:
+//
+//	r.GET("/account/2fa/qr.png", goTap.TOTPQRCode(func(c *goTap.Context) (*goTap.TOTPKey, error) {
+//	    return pendingKeys.Get(currentUser(c).ID)
+//	}))
+//
+// A size query parameter between 64 and 1024 changes the size.
+func TOTPQRCode(keyFunc func(c *Context) (*TOTPKey, error)) HandlerFunc {
+	return func(c *Context) {
+		key, err := keyFunc(c)
+		if err != nil || key == nil {
+			c.JSON(http.StatusNotFound, H{
+				"error":   "Not Found",
+				"message": "No TOTP key to enroll",
+			})
+			c.Abort()
+			return
+		}
+
+		size := 256
+		if s, err := strconv.Atoi(c.Query("size")); err == nil {
+			size = min(max(s, 64), 1024)
+		}
+		image, err := key.PNG(size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, H{
+				"error":   "Internal Server Error",
+				"message": "Failed to render QR code",
+			})
+			c.Abort()
+			return
+		}
+		// The QR code carries the secret
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "image/png", image)
+	}
+}
+
+// VerifyTOTP checks a code against secret, accepting the previous and next
+// code for clock drift. It doesn't stop a code from being used twice; use
+// VerifyTOTPStep for logins.
+func VerifyTOTP(secret, code string) bool {
+	return VerifyTOTPWithConfig(secret, code, TOTPConfig{Skew: 1})
+}
+
+// VerifyTOTPWithConfig checks a code against secret with config
+func VerifyTOTPWithConfig(secret, code string, config TOTPConfig) bool {
+	_, ok := VerifyTOTPStepWithConfig(secret, code, 0, config)
+	return ok
+}
+
+// VerifyTOTPStep checks a code against secret like VerifyTOTP, rejecting
+// codes of time steps up to lastStep. On success it returns the code's
+// step, which the caller must store with the user and pass as lastStep
+// next time, so an intercepted code can't be replayed:
+//
+//	step, ok := goTap.VerifyTOTPStep(user.TOTPSecret, code, user.TOTPStep)
+//	if ok {
+//	    user.TOTPStep = step
+//	    users.Save(user)
+//	}
+//
+// Pass 0 as lastStep for a user who hasn't used a code yet.
+func VerifyTOTPStep(secret, code string, lastStep int64) (step int64, ok bool) {
+	return VerifyTOTPStepWithConfig(secret, code, lastStep, TOTPConfig{Skew: 1})
+}
+
+// VerifyTOTPStepWithConfig checks a code against secret with config,
+// rejecting codes of time steps up to lastStep
+func VerifyTOTPStepWithConfig(secret, code string, lastStep int64, config TOTPConfig) (step int64, ok bool) {
+	config.defaults()
+	code = strings.TrimSpace(code)
+	if len(code) != config.Digits {
+		return 0, false
+	}
+	opts := totp.ValidateOpts{
+		Period:    uint(config.Period / time.Second),
+		Digits:    otp.Digits(config.Digits),
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	period := int64(opts.Period)
+	current := time.Now().Unix() / period
+	skew := int64(config.Skew)
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0).UTC(), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateBackupCodes returns n one-time backup codes such as
+// "4f9a-c2e1-7b30" to show the user once, and their hashes to store.
+// Check a code with UseBackupCode.
+func GenerateBackupCodes(n int) (codes, hashes []string, err error) {
+	codes = make([]string, n)
+	hashes = make([]string, n)
+	for i := range codes {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		codes[i] = code[:4] + "-" + code[4:8] + "-" + code[8:]
+		hashes[i] = HashBackupCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// HashBackupCode returns the stored form of a backup code. Dashes, spaces
+// and case are ignored.
+func HashBackupCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseBackupCode checks code against the stored hashes. If it matches, the
+// remaining hashes are returned to be stored, so each code works once.
+func UseBackupCode(hashes []string, code string) (remaining []string, ok bool) {
+	hash := HashBackupCode(code)
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			remaining = append(append(remaining, hashes[:i]...), hashes[i+1:]...)
+			return remaining, true
+		}
+	}
+	return hashes, false
+}
+
+// Set2FAVerified marks the request's session as having passed 2FA, e.g.
+// after VerifyTOTP or UseBackupCode succeeded, moving it to a new ID with
+// Session.Regenerate. It requires Sessions or RedisSession.
+func Set2FAVerified(c *Context) error {
+	session, ok := GetSession(c)
+	if !ok {
+		return fmt.Errorf("session not found in context")
+	}
+	if err := session.Regenerate(); err != nil {
+		return err
+	}
+	session.Set(Session2FAKey, strconv.FormatInt(time.Now().Unix(), 10))
+	return nil
+}
+
+// Is2FAVerified reports whether the request's session passed 2FA within
+// maxAge, or at all if maxAge is 0
+func Is2FAVerified(c *Context, maxAge time.Duration) bool {
+	session, ok := GetSession(c)
+	if !ok {
+		return false
+	}
+	value, ok := session.Get(Session2FAKey)
+	if !ok {
+		return false
+	}
+	at, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return maxAge <= 0 || time.Since(time.Unix(at, 0)) <= maxAge
+}
+
+// Require2FAConfig holds configuration for the Require2FA middleware
+type Require2FAConfig struct {
+	// MaxAge asks for a new code once the last verification is older
+	// Optional. Default: 0, verified for the session's lifetime
+	MaxAge time.Duration
+
+	// VerifiedFunc reports whether the request passed 2FA, e.g. from a
+	// JWT claim
+	// Default: Is2FAVerified with MaxAge
+	VerifiedFunc func(c *Context) bool
+}
+
+// Require2FA returns a middleware rejecting requests whose session hasn't
+// passed 2FA with 403 Forbidden:
+//
+//	r.Use(goTap.Sessions(goTap.SessionConfig{Secure: true}))
+//	r.POST("/2fa/verify", func(c *goTap.Context) {
+//	    if step, ok := goTap.VerifyTOTPStep(user.TOTPSecret, c.PostForm("code"), user.TOTPStep); ok {
+//	        user.TOTPStep = step // store it with the user
+//	        goTap.Set2FAVerified(c)
+//	    }
+//	})
+//	admin := r.Group("/admin", goTap.Require2FA())
+func Require2FA() HandlerFunc {
+	return Require2FAWithConfig(Require2FAConfig{})
+}
+
+// Require2FAWithConfig returns a Require2FA middleware with config
+func Require2FAWithConfig(config Require2FAConfig) HandlerFunc {
+	if config.VerifiedFunc == nil {
+		config.VerifiedFunc = func(c *Context) bool {
+			return Is2FAVerified(c, config.MaxAge)
+		}
+	}
+
+	return func(c *Context) {
+		if !config.VerifiedFunc(c) {
+			c.JSON(http.StatusForbidden, H{
+				"error":   "Forbidden",
+				"message": "Two-factor authentication required",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
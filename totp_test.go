@@ -0,0 +1,185 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+func TestTOTP(t *testing.T) {
+	key, err := GenerateTOTP("VervePOS", "cashier@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key.URL, "otpauth://totp/VervePOS:cashier@example.com?") || !strings.Contains(key.URL, "secret="+key.Secret) {
+		t.Errorf("Unexpected URL %s", key.URL)
+	}
+
+	now := time.Now()
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{now, true},
+		{now.Add(-30 * time.Second), true},
+		{now.Add(30 * time.Second), true},
+		{now.Add(-90 * time.Second), false},
+	} {
+		code, _ := totp.GenerateCode(key.Secret, tt.at)
+		if got := VerifyTOTP(key.Secret, code); got != tt.want {
+			t.Errorf("VerifyTOTP at %v = %v, want %v", tt.at.Sub(now), got, tt.want)
+		}
+	}
+	if VerifyTOTPWithConfig(key.Secret, mustTOTP(key.Secret, now.Add(-30*time.Second)), TOTPConfig{}) {
+		t.Error("Expected no drift to be accepted without Skew")
+	}
+	if VerifyTOTP(key.Secret, "abc") || VerifyTOTP("not base32!", "123456") {
+		t.Error("Expected invalid input to be rejected")
+	}
+
+	image, err := key.PNG(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img, err := png.Decode(bytes.NewReader(image)); err != nil || img.Bounds().Dx() != 128 {
+		t.Errorf("Expected a 128px PNG, got %v", err)
+	}
+}
+
+func TestVerifyTOTPStepRejectsReplays(t *testing.T) {
+	key, _ := GenerateTOTP("VervePOS", "cashier@example.com")
+	now := time.Now()
+
+	step, ok := VerifyTOTPStep(key.Secret, mustTOTP(key.Secret, now), 0)
+	if !ok || step != now.Unix()/30 {
+		t.Fatalf("Expected the current code to be accepted at step %d, got %d %v", now.Unix()/30, step, ok)
+	}
+	if _, ok := VerifyTOTPStep(key.Secret, mustTOTP(key.Secret, now), step); ok {
+		t.Error("Expected a used code to be rejected")
+	}
+	if _, ok := VerifyTOTPStep(key.Secret, mustTOTP(key.Secret, now.Add(-30*time.Second)), step); ok {
+		t.Error("Expected an older code to be rejected after a newer one was used")
+	}
+	if next, ok := VerifyTOTPStep(key.Secret, mustTOTP(key.Secret, now.Add(30*time.Second)), step); !ok || next != step+1 {
+		t.Errorf("Expected the next code to be accepted at step %d, got %d %v", step+1, next, ok)
+	}
+}
+
+func mustTOTP(secret string, at time.Time) string {
+	code, _ := totp.GenerateCode(secret, at)
+	return code
+}
+
+func TestTOTPQRCode(t *testing.T) {
+	key, _ := GenerateTOTP("VervePOS", "admin")
+	r := New()
+	r.GET("/qr.png", TOTPQRCode(func(c *Context) (*TOTPKey, error) {
+		if c.Query("user") == "" {
+			return nil, errors.New("no enrollment")
+		}
+		return key, nil
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/qr.png?user=1&size=5000", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Unexpected response %d %v", w.Code, w.Header())
+	}
+	if img, err := png.Decode(w.Body); err != nil || img.Bounds().Dx() != 1024 {
+		t.Errorf("Expected the size to be capped, got %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/qr.png", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a key, got %d", w.Code)
+	}
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, hashes, err := GenerateBackupCodes(10)
+	if err != nil || len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("Unexpected codes %v %v", codes, err)
+	}
+	if len(codes[0]) != 14 || codes[0] == codes[1] {
+		t.Errorf("Unexpected code %q", codes[0])
+	}
+
+	remaining, ok := UseBackupCode(hashes, strings.ToUpper(strings.ReplaceAll(codes[3], "-", " ")))
+	if !ok || len(remaining) != 9 || len(hashes) != 10 {
+		t.Fatalf("Expected the code to be used, got %v %d", ok, len(remaining))
+	}
+	if _, ok := UseBackupCode(remaining, codes[3]); ok {
+		t.Error("Expected a used code to be rejected")
+	}
+	if _, ok := UseBackupCode(remaining, "0000-0000-0000"); ok {
+		t.Error("Expected an unknown code to be rejected")
+	}
+}
+
+func TestRequire2FA(t *testing.T) {
+	key, _ := GenerateTOTP("VervePOS", "admin")
+	r := New()
+	r.Use(Sessions(SessionConfig{}))
+	r.POST("/2fa", func(c *Context) {
+		if !VerifyTOTP(key.Secret, c.PostForm("code")) {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		Set2FAVerified(c)
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/admin", Require2FA(), func(c *Context) {
+		c.String(http.StatusOK, "admin")
+	})
+	r.GET("/admin/fresh", Require2FAWithConfig(Require2FAConfig{MaxAge: time.Nanosecond}), func(c *Context) {
+		c.String(http.StatusOK, "admin")
+	})
+
+	request := func(method, path, cookie string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("GET", "/admin", "", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 before 2FA, got %d", w.Code)
+	}
+	cookie := strings.Split(w.Header().Get("Set-Cookie"), ";")[0]
+
+	if w := request("POST", "/2fa", cookie, "code=000000x"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong code to fail, got %d", w.Code)
+	}
+	w = request("POST", "/2fa", cookie, "code="+mustTOTP(key.Secret, time.Now()))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected the code to be accepted, got %d", w.Code)
+	}
+	if w := request("GET", "/admin", cookie, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected the session ID from before 2FA to be replaced, got %d", w.Code)
+	}
+	cookie = strings.Split(w.Header().Get("Set-Cookie"), ";")[0]
+	if w := request("GET", "/admin", cookie, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected access after 2FA, got %d", w.Code)
+	}
+	time.Sleep(time.Millisecond)
+	if w := request("GET", "/admin/fresh", cookie, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected an expired verification to be rejected, got %d", w.Code)
+	}
+}
@@ -6,6 +6,7 @@ package goTap
 
 import (
 	"net/url"
+	"strings"
 )
 
 // Simplified radix tree for routing
@@ -118,6 +119,23 @@ walk:
 				continue walk
 			}
 
+			// A wildcard child only shares its segment with the same
+			// wildcard, since the lookup can't fall back to other children
+			if n.wildChild {
+				child := n.children[0]
+				if child.nType == param && strings.HasPrefix(path, child.path) &&
+					(len(path) == len(child.path) || path[len(child.path)] == '/') {
+					parentFullPathIndex += len(n.path)
+					n = child
+					n.priority++
+					continue walk
+				}
+				panic(newRouteConflict(fullPath, len(fullPath)-len(path), child.firstRoute()))
+			}
+			if (c == ':' || c == '*') && len(n.children) > 0 {
+				panic(newRouteConflict(fullPath, len(fullPath)-len(path), n.children[0].firstRoute()))
+			}
+
 			// Check if a child with the next path byte exists
 			for i, max := 0, len(n.indices); i < max; i++ {
 				if c == n.indices[i] {
@@ -152,6 +170,14 @@ walk:
 	}
 }
 
+// firstRoute returns the path of a route registered below n
+func (n *node) firstRoute() string {
+	for n.handlers == nil && len(n.children) > 0 {
+		n = n.children[0]
+	}
+	return n.fullPath
+}
+
 func (n *node) insertChild(path, fullPath string, handlers HandlersChain) {
 	for {
 		// Find prefix until first wildcard
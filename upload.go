@@ -0,0 +1,240 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Upload validation errors
+var (
+	ErrUploadTooLarge = errors.New("uploaded file is too large")
+	ErrUploadType     = errors.New("uploaded file type is not allowed")
+)
+
+// UploadRules validate and place an uploaded file, see
+// Context.SaveUploadedFileValidated
+type UploadRules struct {
+	// MaxSize is the largest accepted file in bytes
+	// Default: no limit beyond the request body limit
+	MaxSize int64
+
+	// AllowedMIME lists the accepted content types, detected from the
+	// file's content rather than the client's Content-Type. "image/*"
+	// accepts every image type.
+	// Default: any type
+	AllowedMIME []string
+
+	// AllowedExt lists the accepted file name extensions, e.g. ".png"
+	// Default: any extension
+	AllowedExt []string
+
+	// Prefix is prepended to the stored key, e.g. "products/"
+	Prefix string
+
+	// Filename returns the stored name for the sanitized original name
+	// Default: a random name with the original extension
+	Filename func(original string) string
+
+	// Storage receives the file
+	// Default: Engine.FileStorage, or the "uploads" directory
+	Storage FileStorage
+}
+
+// StoredFile describes a saved upload
+type StoredFile struct {
+	// Key locates the file in its FileStorage
+	Key string `json:"key"`
+	// Filename is the sanitized name the client sent
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// SaveUploadedFileValidated checks an uploaded file against rules and
+// saves it to storage. The content type is sniffed from the file's first
+// bytes, and the client's file name is sanitized so it can't escape the
+// upload directory:
+//
+//	fh, _ := c.FormFile("photo")
+//	file, err := c.SaveUploadedFileValidated(fh, goTap.UploadRules{
+//	    MaxSize:     5 << 20,
+//	    AllowedMIME: []string{"image/jpeg", "image/png"},
+//	    AllowedExt:  []string{".jpg", ".jpeg", ".png"},
+//	    Prefix:      "products/",
+//	})
+//	if errors.Is(err, goTap.ErrUploadTooLarge) || errors.Is(err, goTap.ErrUploadType) {
+//	    c.JSON(422, goTap.H{"error": err.Error()})
+//	    return
+//	}
+func (c *Context) SaveUploadedFileValidated(file *multipart.FileHeader, rules UploadRules) (*StoredFile, error) {
+	name := SanitizeFilename(file.Filename)
+	ext := strings.ToLower(filepath.Ext(name))
+
+	if rules.MaxSize > 0 && file.Size > rules.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrUploadTooLarge, file.Size, rules.MaxSize)
+	}
+	if len(rules.AllowedExt) > 0 && !matchExtension(ext, rules.AllowedExt) {
+		return nil, fmt.Errorf("%w: extension %q", ErrUploadType, ext)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	br := bufio.NewReaderSize(src, 512)
+	contentType, err := sniffContentType(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules.AllowedMIME) > 0 && !matchMIME(contentType, rules.AllowedMIME) {
+		return nil, fmt.Errorf("%w: %s", ErrUploadType, contentType)
+	}
+
+	storage := rules.Storage
+	if storage == nil && c.engine != nil {
+		storage = c.engine.FileStorage
+	}
+	if storage == nil {
+		storage = NewLocalStorage("uploads")
+	}
+
+	stored := randomFilename() + ext
+	if rules.Filename != nil {
+		stored = rules.Filename(name)
+	}
+	key := path.Join(rules.Prefix, stored)
+
+	var body io.Reader = br
+	if rules.MaxSize > 0 {
+		body = io.LimitReader(br, rules.MaxSize)
+	}
+	if err := storage.Save(c.Request.Context(), key, body, file.Size, contentType); err != nil {
+		return nil, err
+	}
+	return &StoredFile{Key: key, Filename: name, Size: file.Size, ContentType: contentType}, nil
+}
+
+// sniffContentType detects the media type from the first bytes of br,
+// without consuming them
+func sniffContentType(br *bufio.Reader) (string, error) {
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return contentType, nil
+}
+
+// StreamMultipart calls fn with each part of a multipart/form-data body as
+// it arrives, instead of buffering the whole upload in memory and temp
+// files like FormFile, so large files can be processed or passed on while
+// they are received:
+//
+//	err := c.StreamMultipart(func(part *multipart.Part) error {
+//	    if part.FormName() != "catalog" {
+//	        return nil
+//	    }
+//	    return importProducts(csv.NewReader(part))
+//	})
+//
+// Parts must be read within fn; the unread rest of a part is skipped. An
+// error from fn stops the parsing and is returned. The body can't be
+// streamed after the form was parsed, e.g. by PostForm or FormFile.
+func (c *Context) StreamMultipart(fn func(part *multipart.Part) error) error {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = fn(part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// SanitizeFilename returns the base name of a client supplied file name
+// with path separators, control characters and leading dots removed, so
+// it is safe to use as a file name
+func SanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return '_'
+		}
+	}, name)
+	name = strings.TrimLeft(name, ".")
+	if len(name) > 200 {
+		ext := filepath.Ext(name)
+		if len(ext) > 20 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:200-len(ext)], "") + ext
+	}
+	if name == "" {
+		name = "file"
+	}
+	return name
+}
+
+func matchExtension(ext string, allowed []string) bool {
+	for _, a := range allowed {
+		if !strings.HasPrefix(a, ".") {
+			a = "." + a
+		}
+		if strings.EqualFold(ext, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMIME(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, contentType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func randomFilename() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
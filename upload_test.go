@@ -0,0 +1,171 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestSaveUploadedFileValidated(t *testing.T) {
+	dir := t.TempDir()
+	r := New()
+	r.FileStorage = NewLocalStorage(dir)
+
+	var (
+		file *StoredFile
+		err  error
+	)
+	r.POST("/upload", func(c *Context) {
+		fh, ferr := c.FormFile("photo")
+		if ferr != nil {
+			file, err = nil, ferr
+			return
+		}
+		file, err = c.SaveUploadedFileValidated(fh, UploadRules{
+			MaxSize:     1024,
+			AllowedMIME: []string{"image/*"},
+			AllowedExt:  []string{".png", "jpg"},
+			Prefix:      "products",
+		})
+	})
+	upload := func(filename string, content []byte) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("photo", filename)
+		part.Write(content)
+		mw.Close()
+		req := httptest.NewRequest("POST", "/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	upload("../../etc/Photo 1.PNG", pngHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Filename != "Photo_1.PNG" || file.ContentType != "image/png" || file.Size != int64(len(pngHeader)) ||
+		!strings.HasPrefix(file.Key, "products/") || !strings.HasSuffix(file.Key, ".png") {
+		t.Errorf("Unexpected stored file %+v", file)
+	}
+	data, rerr := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Key)))
+	if rerr != nil || !bytes.Equal(data, pngHeader) {
+		t.Errorf("Expected the file on disk, got %q %v", data, rerr)
+	}
+
+	// An HTML page renamed to .png is rejected by its content
+	upload("evil.png", []byte("<html><script>alert(1)</script></html>"))
+	if !errors.Is(err, ErrUploadType) {
+		t.Errorf("Expected ErrUploadType for sniffed HTML, got %v", err)
+	}
+	upload("photo.gif", pngHeader)
+	if !errors.Is(err, ErrUploadType) {
+		t.Errorf("Expected ErrUploadType for the extension, got %v", err)
+	}
+	upload("big.png", append(pngHeader, make([]byte, 2048)...))
+	if !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("Expected ErrUploadTooLarge, got %v", err)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	for in, want := range map[string]string{
+		"receipt.pdf":                     "receipt.pdf",
+		"../../etc/passwd":                "passwd",
+		`C:\Users\pos\menu.jpg`:           "menu.jpg",
+		"..":                              "file",
+		".htaccess":                       "htaccess",
+		"my photo (1).png":                "my_photo__1_.png",
+		"caf\u00e9\x00.txt":               "caf\u00e9.txt",
+		strings.Repeat("a", 300) + ".png": strings.Repeat("a", 196) + ".png",
+	} {
+		if got := SanitizeFilename(in); got != want {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStreamMultipart(t *testing.T) {
+	r := New()
+	var fields []string
+	var size int
+	chunkRead := make(chan struct{})
+	r.POST("/import", func(c *Context) {
+		err := c.StreamMultipart(func(part *multipart.Part) error {
+			if part.FileName() == "" {
+				value, _ := io.ReadAll(part)
+				fields = append(fields, part.FormName()+"="+string(value))
+				return nil
+			}
+			buf := make([]byte, 1024)
+			for {
+				n, err := part.Read(buf)
+				if size == 0 && n > 0 {
+					close(chunkRead)
+				}
+				size += n
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+		})
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+		}
+	})
+
+	// The writer waits for the handler to read the first chunk before
+	// sending the rest, which deadlocks unless the body is streamed
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	streamed := make(chan bool, 1)
+	go func() {
+		mw.WriteField("store", "12")
+		part, _ := mw.CreateFormFile("catalog", "catalog.csv")
+		part.Write(bytes.Repeat([]byte("sku,name\n"), 1000))
+		select {
+		case <-chunkRead:
+			streamed <- true
+		case <-time.After(2 * time.Second):
+			streamed <- false
+		}
+		part.Write(bytes.Repeat([]byte("sku,name\n"), 1000))
+		mw.Close()
+		pw.Close()
+	}()
+	req := httptest.NewRequest("POST", "/import", pr)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || len(fields) != 1 || fields[0] != "store=12" || size != 18000 {
+		t.Errorf("Unexpected result %d %v %d", w.Code, fields, size)
+	}
+	if !<-streamed {
+		t.Error("Expected the file to be read while it was sent")
+	}
+
+	req = httptest.NewRequest("POST", "/import", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", MIMEJSON)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a non-multipart body to fail, got %d", w.Code)
+	}
+}
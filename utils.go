@@ -7,6 +7,7 @@ package goTap
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"path"
 	"reflect"
 	"runtime"
@@ -15,6 +16,20 @@ import (
 // H is a shortcut for map[string]any
 type H map[string]any
 
+// WrapF wraps an http.HandlerFunc into a goTap handler
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		f(c.Writer, c.Request)
+	}
+}
+
+// WrapH wraps an http.Handler into a goTap handler
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
 func assert1(guard bool, text string) {
 	if !guard {
 		panic(text)
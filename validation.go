@@ -90,6 +90,10 @@ func validateRule(fieldName string, value reflect.Value, rule string) error {
 		return validateURL(fieldName, value)
 	case "oneof":
 		return validateOneOf(fieldName, value, ruleParam)
+	case "password_strength":
+		return validatePasswordStrength(fieldName, value, ruleParam)
+	case "currency":
+		return validateCurrency(fieldName, value, ruleParam)
 	default:
 		// Unknown rules are ignored
 		return nil
@@ -0,0 +1,555 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// HNSWConfig holds configuration for HNSWVectorStore
+type HNSWConfig struct {
+	// M is the number of neighbours linked per node and layer; layer 0
+	// links 2*M. Higher values improve recall for high-dimensional vectors
+	// at the cost of memory and insert time.
+	// Default: 16
+	M int
+
+	// EfConstruction is the candidate list size when inserting. Higher
+	// values build a better graph more slowly.
+	// Default: 200
+	EfConstruction int
+
+	// EfSearch is the candidate list size when searching, raised to the
+	// limit of a search if lower. Higher values improve recall at the cost
+	// of latency.
+	// Default: 64
+	EfSearch int
+
+	// BruteForceThreshold is the collection size up to which searches scan
+	// every vector, which is exact and faster than the graph for tiny
+	// collections
+	// Default: 1000
+	BruteForceThreshold int
+
+	// RebuildRatio is the share of deleted nodes that triggers rebuilding
+	// the graph. Deleted nodes stay in the graph to keep it connected
+	// until then.
+	// Default: 0.25
+	RebuildRatio float64
+
+	// Seed makes the layer assignment, and so the graph, reproducible
+	// Optional. Default value 0 (random).
+	Seed uint64
+}
+
+// HNSWVectorStore is an in-memory VectorStore indexed by a Hierarchical
+// Navigable Small World graph, searching large collections by cosine
+// similarity in logarithmic time. Results are approximate; raise EfSearch
+// for better recall. It is safe for concurrent use.
+type HNSWVectorStore struct {
+	config HNSWConfig
+	mu     sync.RWMutex
+	rng    *rand.Rand
+	levelM float64
+
+	nodes   []*hnswNode
+	ids     map[string]int32
+	entry   int32
+	top     int
+	dims    int
+	deleted int
+
+	visited sync.Pool
+}
+
+type hnswNode struct {
+	doc     *VectorDocument
+	vec     Vector // normalized copy of doc.Vector
+	links   [][]int32
+	deleted bool
+}
+
+// hnswCandidate is a node and its distance to the query
+type hnswCandidate struct {
+	id   int32
+	dist float32
+}
+
+// NewHNSWVectorStore returns an empty HNSWVectorStore
+func NewHNSWVectorStore(config HNSWConfig) *HNSWVectorStore {
+	if config.M <= 1 {
+		config.M = 16
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = 200
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = 64
+	}
+	if config.BruteForceThreshold <= 0 {
+		config.BruteForceThreshold = 1000
+	}
+	if config.RebuildRatio <= 0 {
+		config.RebuildRatio = 0.25
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &HNSWVectorStore{
+		config: config,
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+		levelM: 1 / math.Log(float64(config.M)),
+		ids:    make(map[string]int32),
+		entry:  -1,
+	}
+}
+
+// Len returns the number of documents in the store
+func (s *HNSWVectorStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ids)
+}
+
+// Insert implements VectorStore. Documents with an existing ID replace it.
+func (s *HNSWVectorStore) Insert(ctx context.Context, documents []*VectorDocument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range documents {
+		if err := s.checkDims(doc.Vector); err != nil {
+			return fmt.Errorf("document %s: %w", doc.ID, err)
+		}
+	}
+	for _, doc := range documents {
+		if id, ok := s.ids[doc.ID]; ok {
+			s.remove(id)
+		}
+		s.insert(doc)
+	}
+	s.maybeRebuild()
+	return nil
+}
+
+// Search implements VectorStore
+func (s *HNSWVectorStore) Search(ctx context.Context, queryVector Vector, limit int) ([]*VectorSearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.ids) == 0 || limit <= 0 {
+		return []*VectorSearchResult{}, nil
+	}
+	if len(queryVector) != s.dims {
+		return nil, fmt.Errorf("query vector has %d dimensions, want %d", len(queryVector), s.dims)
+	}
+
+	q := normalizeVector(queryVector)
+	var found []hnswCandidate
+	if len(s.ids) <= s.config.BruteForceThreshold {
+		found = s.bruteForce(q, limit)
+	} else {
+		found = s.searchGraph(q, max(s.config.EfSearch, limit), limit)
+	}
+
+	results := make([]*VectorSearchResult, len(found))
+	for i, c := range found {
+		results[i] = &VectorSearchResult{Document: s.nodes[c.id].doc, Score: 1 - c.dist, Distance: c.dist}
+	}
+	return results, nil
+}
+
+// Delete implements VectorStore
+func (s *HNSWVectorStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if n, ok := s.ids[id]; ok {
+			s.remove(n)
+		}
+	}
+	s.maybeRebuild()
+	return nil
+}
+
+// Get implements VectorStore
+func (s *HNSWVectorStore) Get(ctx context.Context, id string) (*VectorDocument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.ids[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, id)
+	}
+	return s.nodes[n].doc, nil
+}
+
+// Update implements VectorStore
+func (s *HNSWVectorStore) Update(ctx context.Context, document *VectorDocument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.ids[document.ID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrVectorNotFound, document.ID)
+	}
+	if err := s.checkDims(document.Vector); err != nil {
+		return err
+	}
+	s.remove(n)
+	s.insert(document)
+	s.maybeRebuild()
+	return nil
+}
+
+func (s *HNSWVectorStore) checkDims(v Vector) error {
+	if len(v) == 0 {
+		return fmt.Errorf("empty vector")
+	}
+	if len(s.nodes) > 0 && len(v) != s.dims {
+		return fmt.Errorf("vector has %d dimensions, want %d", len(v), s.dims)
+	}
+	return nil
+}
+
+// remove marks node n deleted; it stays in the graph as a waypoint
+func (s *HNSWVectorStore) remove(n int32) {
+	node := s.nodes[n]
+	delete(s.ids, node.doc.ID)
+	node.deleted = true
+	s.deleted++
+}
+
+// maybeRebuild rebuilds the graph from the live documents once deleted
+// nodes make up RebuildRatio of it
+func (s *HNSWVectorStore) maybeRebuild() {
+	if s.deleted == 0 || float64(s.deleted) < s.config.RebuildRatio*float64(len(s.nodes)) {
+		return
+	}
+	old := s.nodes
+	s.nodes = make([]*hnswNode, 0, len(s.ids))
+	clear(s.ids)
+	s.entry, s.top, s.deleted = -1, 0, 0
+	for _, node := range old {
+		if !node.deleted {
+			s.insert(node.doc)
+		}
+	}
+}
+
+func (s *HNSWVectorStore) randomLevel() int {
+	return int(-math.Log(1-s.rng.Float64()) * s.levelM)
+}
+
+func (s *HNSWVectorStore) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * s.config.M
+	}
+	return s.config.M
+}
+
+func (s *HNSWVectorStore) insert(doc *VectorDocument) {
+	level := s.randomLevel()
+	n := int32(len(s.nodes))
+	node := &hnswNode{doc: doc, vec: normalizeVector(doc.Vector), links: make([][]int32, level+1)}
+	s.nodes = append(s.nodes, node)
+	s.ids[doc.ID] = n
+	s.dims = len(doc.Vector)
+
+	if s.entry < 0 {
+		s.entry, s.top = n, level
+		return
+	}
+
+	ep := hnswCandidate{s.entry, cosineDistance(node.vec, s.nodes[s.entry].vec)}
+	for l := s.top; l > level; l-- {
+		ep = s.greedy(node.vec, ep, l)
+	}
+	entries := []hnswCandidate{ep}
+	for l := min(level, s.top); l >= 0; l-- {
+		candidates := s.searchLayer(node.vec, entries, s.config.EfConstruction, l)
+		neighbours := s.selectNeighbours(candidates, s.config.M)
+		node.links[l] = make([]int32, len(neighbours), s.maxLinks(l)+1)
+		for i, c := range neighbours {
+			node.links[l][i] = c.id
+			s.link(c.id, n, l)
+		}
+		entries = candidates
+	}
+	if level > s.top {
+		s.entry, s.top = n, level
+	}
+}
+
+// link adds a link from node from to node to on level l, pruning the
+// links of from to the closest diverse ones when it has too many
+func (s *HNSWVectorStore) link(from, to int32, l int) {
+	node := s.nodes[from]
+	node.links[l] = append(node.links[l], to)
+	if len(node.links[l]) <= s.maxLinks(l) {
+		return
+	}
+	candidates := make([]hnswCandidate, len(node.links[l]))
+	for i, id := range node.links[l] {
+		candidates[i] = hnswCandidate{id, cosineDistance(node.vec, s.nodes[id].vec)}
+	}
+	slices.SortFunc(candidates, compareCandidates)
+	kept := s.selectNeighbours(candidates, s.maxLinks(l))
+	node.links[l] = node.links[l][:0]
+	for _, c := range kept {
+		node.links[l] = append(node.links[l], c.id)
+	}
+}
+
+// selectNeighbours picks up to m of the candidates sorted by distance with
+// the HNSW heuristic: a candidate is skipped if it is closer to an already
+// selected neighbour than to the new node, which keeps links spread out.
+// Skipped candidates fill up the remaining slots.
+func (s *HNSWVectorStore) selectNeighbours(candidates []hnswCandidate, m int) []hnswCandidate {
+	if len(candidates) <= m {
+		return candidates
+	}
+	selected := make([]hnswCandidate, 0, m)
+	var skipped []hnswCandidate
+	for _, c := range candidates {
+		if len(selected) == m {
+			break
+		}
+		diverse := true
+		for _, sel := range selected {
+			if cosineDistance(s.nodes[c.id].vec, s.nodes[sel.id].vec) < c.dist {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+	for _, c := range skipped {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// greedy walks level l towards q from ep, returning the closest node found
+func (s *HNSWVectorStore) greedy(q Vector, ep hnswCandidate, l int) hnswCandidate {
+	for changed := true; changed; {
+		changed = false
+		for _, id := range s.nodes[ep.id].links[l] {
+			if d := cosineDistance(q, s.nodes[id].vec); d < ep.dist {
+				ep, changed = hnswCandidate{id, d}, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer returns up to ef nodes of level l closest to q, sorted by
+// distance, starting from entries
+func (s *HNSWVectorStore) searchLayer(q Vector, entries []hnswCandidate, ef, l int) []hnswCandidate {
+	visited := s.visitedSet()
+	defer s.visited.Put(visited)
+
+	var candidates, results hnswHeap
+	results.max = true
+	for _, e := range entries {
+		visited.visit(e.id)
+		candidates.push(e)
+		results.push(e)
+	}
+	for results.len() > ef {
+		results.pop()
+	}
+
+	for candidates.len() > 0 {
+		c := candidates.pop()
+		if results.len() >= ef && c.dist > results.peek().dist {
+			break
+		}
+		for _, id := range s.nodes[c.id].links[l] {
+			if !visited.visit(id) {
+				continue
+			}
+			d := cosineDistance(q, s.nodes[id].vec)
+			if results.len() < ef || d < results.peek().dist {
+				candidates.push(hnswCandidate{id, d})
+				results.push(hnswCandidate{id, d})
+				if results.len() > ef {
+					results.pop()
+				}
+			}
+		}
+	}
+	out := results.items
+	slices.SortFunc(out, compareCandidates)
+	return out
+}
+
+// searchGraph returns the limit live nodes closest to q
+func (s *HNSWVectorStore) searchGraph(q Vector, ef, limit int) []hnswCandidate {
+	ep := hnswCandidate{s.entry, cosineDistance(q, s.nodes[s.entry].vec)}
+	for l := s.top; l > 0; l-- {
+		ep = s.greedy(q, ep, l)
+	}
+	// Deleted nodes are traversed but not returned, so look further ahead
+	// while the graph has many of them
+	if s.deleted > 0 {
+		ef += ef * s.deleted / len(s.nodes)
+	}
+	found := s.searchLayer(q, []hnswCandidate{ep}, ef, 0)
+	live := found[:0]
+	for _, c := range found {
+		if !s.nodes[c.id].deleted {
+			live = append(live, c)
+		}
+	}
+	return live[:min(limit, len(live))]
+}
+
+// bruteForce returns the limit live nodes closest to q by comparing all
+func (s *HNSWVectorStore) bruteForce(q Vector, limit int) []hnswCandidate {
+	var results hnswHeap
+	results.max = true
+	for _, n := range s.ids {
+		d := cosineDistance(q, s.nodes[n].vec)
+		if results.len() < limit {
+			results.push(hnswCandidate{n, d})
+		} else if d < results.peek().dist {
+			results.pop()
+			results.push(hnswCandidate{n, d})
+		}
+	}
+	slices.SortFunc(results.items, compareCandidates)
+	return results.items
+}
+
+func compareCandidates(a, b hnswCandidate) int {
+	switch {
+	case a.dist < b.dist:
+		return -1
+	case a.dist > b.dist:
+		return 1
+	}
+	return int(a.id - b.id)
+}
+
+// cosineDistance returns 1 - the cosine similarity of normalized vectors
+func cosineDistance(a, b Vector) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// normalizeVector returns a unit length copy of v, or a zero copy for a
+// zero vector
+func normalizeVector(v Vector) Vector {
+	var norm float32
+	for _, f := range v {
+		norm += f * f
+	}
+	out := make(Vector, len(v))
+	if norm == 0 {
+		return out
+	}
+	inv := 1 / float32(math.Sqrt(float64(norm)))
+	for i, f := range v {
+		out[i] = f * inv
+	}
+	return out
+}
+
+// hnswVisited marks visited nodes for one search. Marks hold the epoch of
+// the search that set them, so the set is cleared by incrementing it.
+type hnswVisited struct {
+	marks []uint32
+	epoch uint32
+}
+
+func (s *HNSWVectorStore) visitedSet() *hnswVisited {
+	v, _ := s.visited.Get().(*hnswVisited)
+	if v == nil {
+		v = &hnswVisited{}
+	}
+	if len(v.marks) < len(s.nodes) {
+		v.marks = make([]uint32, len(s.nodes)+len(s.nodes)/4)
+		v.epoch = 0
+	}
+	v.epoch++
+	if v.epoch == 0 {
+		clear(v.marks)
+		v.epoch = 1
+	}
+	return v
+}
+
+// visit marks id visited, reporting whether it wasn't before
+func (v *hnswVisited) visit(id int32) bool {
+	if v.marks[id] == v.epoch {
+		return false
+	}
+	v.marks[id] = v.epoch
+	return true
+}
+
+// hnswHeap is a binary heap of candidates, ordered by ascending distance or
+// by descending distance if max is set
+type hnswHeap struct {
+	items []hnswCandidate
+	max   bool
+}
+
+func (h *hnswHeap) len() int { return len(h.items) }
+
+func (h *hnswHeap) peek() hnswCandidate { return h.items[0] }
+
+func (h *hnswHeap) less(i, j int) bool {
+	if h.max {
+		return h.items[i].dist > h.items[j].dist
+	}
+	return h.items[i].dist < h.items[j].dist
+}
+
+func (h *hnswHeap) push(c hnswCandidate) {
+	h.items = append(h.items, c)
+	for i := len(h.items) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+func (h *hnswHeap) pop() hnswCandidate {
+	top := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items = h.items[:last]
+	for i := 0; ; {
+		smallest, l, r := i, 2*i+1, 2*i+2
+		if l < last && h.less(l, smallest) {
+			smallest = l
+		}
+		if r < last && h.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+	return top
+}
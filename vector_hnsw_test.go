@@ -0,0 +1,202 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func randomVectorDocuments(rng *rand.Rand, n, dims int) []*VectorDocument {
+	docs := make([]*VectorDocument, n)
+	for i := range docs {
+		v := make(Vector, dims)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		docs[i] = &VectorDocument{ID: strconv.Itoa(i), Vector: v}
+	}
+	return docs
+}
+
+// hnswRecall returns the share of the exact top k found by store over the
+// queries
+func hnswRecall(t testing.TB, store *HNSWVectorStore, exact VectorStore, queries []*VectorDocument, k int) float64 {
+	t.Helper()
+	ctx := context.Background()
+	hits := 0
+	for _, q := range queries {
+		want, _ := exact.Search(ctx, q.Vector, k)
+		got, err := store.Search(ctx, q.Vector, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[string]bool, len(got))
+		for _, r := range got {
+			ids[r.Document.ID] = true
+		}
+		for _, r := range want {
+			if ids[r.Document.ID] {
+				hits++
+			}
+		}
+	}
+	return float64(hits) / float64(len(queries)*k)
+}
+
+func TestHNSWVectorStore(t *testing.T) {
+	// The brute-force fallback and the graph behave the same
+	testVectorStore(t, NewHNSWVectorStore(HNSWConfig{}), true)
+	testVectorStore(t, NewHNSWVectorStore(HNSWConfig{BruteForceThreshold: 1, Seed: 1}), true)
+
+	store := NewHNSWVectorStore(HNSWConfig{})
+	ctx := context.Background()
+	store.Insert(ctx, []*VectorDocument{{ID: "a", Vector: Vector{1, 0}}})
+	if err := store.Insert(ctx, []*VectorDocument{{ID: "b", Vector: Vector{1, 0, 0}}}); err == nil {
+		t.Error("Expected a vector with other dimensions to be rejected")
+	}
+	if _, err := store.Search(ctx, Vector{1}, 1); err == nil {
+		t.Error("Expected a query with other dimensions to be rejected")
+	}
+	if results, _ := store.Search(ctx, Vector{0, 1}, 0); len(results) != 0 {
+		t.Errorf("Expected no results for limit 0, got %v", results)
+	}
+}
+
+func TestHNSWRecallAndDeletes(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	docs := randomVectorDocuments(rng, 3000, 32)
+	queries := randomVectorDocuments(rng, 50, 32)
+	ctx := context.Background()
+
+	store := NewHNSWVectorStore(HNSWConfig{BruteForceThreshold: 100, Seed: 7})
+	exact := NewInMemoryVectorStore()
+	store.Insert(ctx, docs)
+	exact.Insert(ctx, docs)
+	if recall := hnswRecall(t, store, exact, queries, 10); recall < 0.95 {
+		t.Errorf("Expected a recall of at least 0.95, got %.3f", recall)
+	}
+
+	// Deleted documents are skipped before and after the graph is rebuilt
+	for _, n := range []int{500, 1000} {
+		ids := make([]string, 0, n)
+		for _, d := range docs[:n] {
+			ids = append(ids, d.ID)
+		}
+		store.Delete(ctx, ids)
+		exact.Delete(ctx, ids)
+		if store.Len() != 3000-n {
+			t.Fatalf("Expected %d documents, got %d", 3000-n, store.Len())
+		}
+		for _, q := range queries {
+			results, _ := store.Search(ctx, q.Vector, 10)
+			if len(results) != 10 {
+				t.Fatalf("Expected 10 results, got %d", len(results))
+			}
+			for _, r := range results {
+				if id, _ := strconv.Atoi(r.Document.ID); id < n {
+					t.Fatalf("Expected deleted document %s to be skipped", r.Document.ID)
+				}
+			}
+		}
+		if recall := hnswRecall(t, store, exact, queries, 10); recall < 0.95 {
+			t.Errorf("Expected a recall of at least 0.95 after deleting %d, got %.3f", n, recall)
+		}
+	}
+	if store.deleted != 0 || len(store.nodes) != 2000 {
+		t.Errorf("Expected the graph to be rebuilt, got %d nodes and %d deleted", len(store.nodes), store.deleted)
+	}
+}
+
+func TestHNSWConcurrentAccess(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	docs := randomVectorDocuments(rng, 400, 8)
+	store := NewHNSWVectorStore(HNSWConfig{BruteForceThreshold: 10, EfConstruction: 50})
+	ctx := context.Background()
+	store.Insert(ctx, docs[:200])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, q := range docs[:50] {
+				if _, err := store.Search(ctx, q.Vector, 5); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for _, d := range docs[200:] {
+		store.Insert(ctx, []*VectorDocument{d})
+	}
+	wg.Wait()
+	if store.Len() != 400 {
+		t.Errorf("Expected 400 documents, got %d", store.Len())
+	}
+}
+
+var (
+	benchmarkHNSWOnce    sync.Once
+	benchmarkHNSWStore   *HNSWVectorStore
+	benchmarkHNSWExact   *InMemoryVectorStore
+	benchmarkHNSWQueries []*VectorDocument
+)
+
+// benchmarkHNSW builds a 100k vector store once for the search benchmarks
+func benchmarkHNSW(b *testing.B) {
+	benchmarkHNSWOnce.Do(func() {
+		rng := rand.New(rand.NewPCG(1, 1))
+		docs := randomVectorDocuments(rng, 100_000, 64)
+		benchmarkHNSWQueries = randomVectorDocuments(rng, 100, 64)
+		benchmarkHNSWStore = NewHNSWVectorStore(HNSWConfig{Seed: 1})
+		benchmarkHNSWExact = NewInMemoryVectorStore()
+		benchmarkHNSWStore.Insert(context.Background(), docs)
+		benchmarkHNSWExact.Insert(context.Background(), docs)
+	})
+	b.ResetTimer()
+}
+
+func BenchmarkHNSWSearch100k(b *testing.B) {
+	benchmarkHNSW(b)
+	ctx := context.Background()
+	for _, ef := range []int{64, 256, 1024} {
+		b.Run("ef="+strconv.Itoa(ef), func(b *testing.B) {
+			benchmarkHNSWStore.config.EfSearch = ef
+			recall := hnswRecall(b, benchmarkHNSWStore, benchmarkHNSWExact, benchmarkHNSWQueries, 10)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				benchmarkHNSWStore.Search(ctx, benchmarkHNSWQueries[i%len(benchmarkHNSWQueries)].Vector, 10)
+			}
+			b.ReportMetric(recall, "recall@10")
+		})
+	}
+}
+
+func BenchmarkBruteForceSearch100k(b *testing.B) {
+	benchmarkHNSW(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkHNSWExact.Search(ctx, benchmarkHNSWQueries[i%len(benchmarkHNSWQueries)].Vector, 10)
+	}
+}
+
+func BenchmarkHNSWInsert(b *testing.B) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	docs := randomVectorDocuments(rng, b.N, 64)
+	store := NewHNSWVectorStore(HNSWConfig{EfConstruction: 100, Seed: 2})
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, d := range docs {
+		store.Insert(ctx, []*VectorDocument{d})
+	}
+}
@@ -0,0 +1,747 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// ErrVectorNotFound is returned when a document doesn't exist in a
+// VectorStore
+var ErrVectorNotFound = errors.New("vector not found")
+
+// VectorStoreConfig holds configuration for NewVectorStore
+type VectorStoreConfig struct {
+	// Backend is "memory", "hnsw" (see HNSWVectorStore), "qdrant",
+	// "pgvector" or "redis"
+	// Default: "memory"
+	Backend string
+
+	// DSN locates the backend, e.g. "http://localhost:6333" for Qdrant,
+	// "host=localhost user=pos dbname=pos" for pgvector and
+	// "redis://localhost:6379/0" for Redis
+	DSN string
+
+	// Collection names the Qdrant collection, PostgreSQL table or Redis index
+	// Default: "vectors"
+	Collection string
+
+	// Dimensions of the stored vectors. When set, the collection, table or
+	// index is created if it doesn't exist.
+	// Optional. Default value 0 (the collection must exist).
+	Dimensions int
+
+	// APIKey authenticates with Qdrant Cloud
+	// Optional. Default value "".
+	APIKey string
+
+	// BatchSize is the number of documents upserted per request
+	// Default: 256
+	BatchSize int
+
+	// MaxConns limits the pooled connections to the backend
+	// Default: 10
+	MaxConns int
+}
+
+// NewVectorStore returns the VectorStore selected by config.Backend,
+// connected with a pool of config.MaxConns connections:
+//
+//	store, err := goTap.NewVectorStore(goTap.VectorStoreConfig{
+//	    Backend:    "qdrant",
+//	    DSN:        "http://localhost:6333",
+//	    Collection: "products",
+//	    Dimensions: 384,
+//	})
+//
+// The Qdrant, pgvector and Redis stores implement io.Closer to release
+// their connections.
+func NewVectorStore(config VectorStoreConfig) (VectorStore, error) {
+	if config.Collection == "" {
+		config.Collection = "vectors"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 256
+	}
+	if config.MaxConns <= 0 {
+		config.MaxConns = 10
+	}
+
+	var store interface {
+		VectorStore
+		EnsureCollection(ctx context.Context, dimensions int) error
+	}
+	switch config.Backend {
+	case "", "memory":
+		return NewInMemoryVectorStore(), nil
+	case "hnsw":
+		return NewHNSWVectorStore(HNSWConfig{}), nil
+	case "qdrant":
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = config.MaxConns
+		transport.MaxConnsPerHost = config.MaxConns
+		store = &QdrantVectorStore{
+			Endpoint:   config.DSN,
+			Collection: config.Collection,
+			APIKey:     config.APIKey,
+			BatchSize:  config.BatchSize,
+			Client:     &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		}
+	case "pgvector":
+		db, err := NewGormDB(&DBConfig{
+			Driver:          "postgres",
+			DSN:             config.DSN,
+			MaxIdleConns:    config.MaxConns,
+			MaxOpenConns:    config.MaxConns,
+			ConnMaxLifetime: time.Hour,
+			LogLevel:        logger.Warn,
+		})
+		if err != nil {
+			return nil, err
+		}
+		store = &PgVectorStore{DB: db, Table: config.Collection, BatchSize: config.BatchSize}
+	case "redis":
+		opts, err := redis.ParseURL(config.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("redis vector store: %w", err)
+		}
+		opts.PoolSize = config.MaxConns
+		opts.Protocol = 2
+		client := redis.NewClient(opts)
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("redis connection failed: %w", err)
+		}
+		store = &RedisVectorStore{Client: client, Index: config.Collection, BatchSize: config.BatchSize}
+	default:
+		return nil, fmt.Errorf("unsupported vector store backend: %s", config.Backend)
+	}
+
+	if config.Dimensions > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := store.EnsureCollection(ctx, config.Dimensions); err != nil {
+			store.(io.Closer).Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// vectorBatches calls fn with consecutive batches of at most size documents
+func vectorBatches[T any](items []T, size int, fn func([]T) error) error {
+	if size <= 0 {
+		size = 256
+	}
+	for len(items) > 0 {
+		n := min(size, len(items))
+		if err := fn(items[:n]); err != nil {
+			return err
+		}
+		items = items[n:]
+	}
+	return nil
+}
+
+// ========== Qdrant ==========
+
+// QdrantVectorStore stores vectors in a Qdrant collection through its REST
+// API, compared by cosine similarity. Qdrant only accepts UUIDs and integers
+// as point IDs, so each document is stored under a UUID derived from its ID
+// with the ID in the payload.
+type QdrantVectorStore struct {
+	// Endpoint is the API base URL
+	// Default: "http://localhost:6333"
+	Endpoint string
+
+	// Collection holds the points
+	Collection string
+
+	// APIKey authenticates with Qdrant Cloud
+	// Optional. Default value "".
+	APIKey string
+
+	// BatchSize is the number of points upserted per request
+	// Default: 256
+	BatchSize int
+
+	// Client sends the requests
+	// Default: a client with a 30 second timeout
+	Client *http.Client
+}
+
+var defaultQdrantClient = &http.Client{Timeout: 30 * time.Second}
+
+// errQdrantNotFound is wrapped by errors of 404 responses
+var errQdrantNotFound = errors.New("not found")
+
+type qdrantPoint struct {
+	ID      string        `json:"id"`
+	Vector  Vector        `json:"vector"`
+	Payload qdrantPayload `json:"payload"`
+	Score   float32       `json:"score,omitempty"`
+}
+
+type qdrantPayload struct {
+	ID       string                 `json:"id"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func (p qdrantPoint) document() *VectorDocument {
+	return &VectorDocument{ID: p.Payload.ID, Vector: p.Vector, Metadata: p.Payload.Metadata}
+}
+
+// qdrantPointID returns the UUID a document ID is stored under
+func qdrantPointID(id string) string {
+	sum := sha1.Sum([]byte(id))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	h := hex.EncodeToString(sum[:16])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// EnsureCollection creates the collection for vectors of the given
+// dimensions if it doesn't exist
+func (s *QdrantVectorStore) EnsureCollection(ctx context.Context, dimensions int) error {
+	err := s.do(ctx, http.MethodGet, "", nil, nil)
+	if !errors.Is(err, errQdrantNotFound) {
+		return err
+	}
+	return s.do(ctx, http.MethodPut, "", map[string]any{
+		"vectors": map[string]any{"size": dimensions, "distance": "Cosine"},
+	}, nil)
+}
+
+// Insert implements VectorStore, upserting the documents in batches
+func (s *QdrantVectorStore) Insert(ctx context.Context, documents []*VectorDocument) error {
+	return vectorBatches(documents, s.BatchSize, func(batch []*VectorDocument) error {
+		points := make([]qdrantPoint, len(batch))
+		for i, doc := range batch {
+			points[i] = qdrantPoint{
+				ID:      qdrantPointID(doc.ID),
+				Vector:  doc.Vector,
+				Payload: qdrantPayload{ID: doc.ID, Metadata: doc.Metadata},
+			}
+		}
+		return s.do(ctx, http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil)
+	})
+}
+
+// Search implements VectorStore
+func (s *QdrantVectorStore) Search(ctx context.Context, queryVector Vector, limit int) ([]*VectorSearchResult, error) {
+	if limit <= 0 {
+		return []*VectorSearchResult{}, nil
+	}
+	var points []qdrantPoint
+	err := s.do(ctx, http.MethodPost, "/points/search", map[string]any{
+		"vector":       queryVector,
+		"limit":        limit,
+		"with_payload": true,
+		"with_vector":  true,
+	}, &points)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*VectorSearchResult, len(points))
+	for i, p := range points {
+		results[i] = &VectorSearchResult{Document: p.document(), Score: p.Score, Distance: 1 - p.Score}
+	}
+	return results, nil
+}
+
+// Delete implements VectorStore
+func (s *QdrantVectorStore) Delete(ctx context.Context, ids []string) error {
+	return vectorBatches(ids, s.BatchSize, func(batch []string) error {
+		points := make([]string, len(batch))
+		for i, id := range batch {
+			points[i] = qdrantPointID(id)
+		}
+		return s.do(ctx, http.MethodPost, "/points/delete?wait=true", map[string]any{"points": points}, nil)
+	})
+}
+
+// Get implements VectorStore
+func (s *QdrantVectorStore) Get(ctx context.Context, id string) (*VectorDocument, error) {
+	var point qdrantPoint
+	if err := s.do(ctx, http.MethodGet, "/points/"+qdrantPointID(id), nil, &point); err != nil {
+		if errors.Is(err, errQdrantNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, id)
+		}
+		return nil, err
+	}
+	return point.document(), nil
+}
+
+// Update implements VectorStore
+func (s *QdrantVectorStore) Update(ctx context.Context, document *VectorDocument) error {
+	if _, err := s.Get(ctx, document.ID); err != nil {
+		return err
+	}
+	return s.Insert(ctx, []*VectorDocument{document})
+}
+
+// Close releases idle connections
+func (s *QdrantVectorStore) Close() error {
+	if s.Client != nil {
+		s.Client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// do sends a request for the collection and decodes the result field of
+// the response into out. Errors of 404 responses wrap errQdrantNotFound.
+func (s *QdrantVectorStore) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method,
+		endpointOr(s.Endpoint, "http://localhost:6333")+"/collections/"+s.Collection+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.APIKey != "" {
+		req.Header.Set("api-key", s.APIKey)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = defaultQdrantClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Status json.RawMessage `json:"status"`
+	}
+	if resp.StatusCode >= 300 {
+		msg := string(bytes.TrimSpace(data))
+		var status struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &envelope) == nil && json.Unmarshal(envelope.Status, &status) == nil && status.Error != "" {
+			msg = status.Error
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("qdrant: %s: %w", msg, errQdrantNotFound)
+		}
+		return fmt.Errorf("qdrant: %s: %s", resp.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+// ========== pgvector ==========
+
+// Value implements driver.Valuer, formatting the vector as a pgvector
+// literal such as "[1,2,3]"
+func (v Vector) Value() (driver.Value, error) {
+	buf := make([]byte, 0, 2+len(v)*10)
+	buf = append(buf, '[')
+	for i, f := range v {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, float64(f), 'g', -1, 32)
+	}
+	return string(append(buf, ']')), nil
+}
+
+// Scan implements sql.Scanner, parsing a pgvector literal
+func (v *Vector) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+		*v = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Vector", src)
+	}
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return fmt.Errorf("invalid vector %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		*v = Vector{}
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	out := make(Vector, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return fmt.Errorf("invalid vector component %q", p)
+		}
+		out[i] = float32(f)
+	}
+	*v = out
+	return nil
+}
+
+// PgVectorStore stores vectors in a PostgreSQL table with the pgvector
+// extension, searched by cosine distance:
+//
+//	CREATE TABLE vectors (id text PRIMARY KEY, embedding vector(384) NOT NULL, metadata jsonb NOT NULL DEFAULT '{}')
+type PgVectorStore struct {
+	DB *DB
+
+	// Table holds the vectors
+	Table string
+
+	// BatchSize is the number of rows upserted per statement
+	// Default: 256
+	BatchSize int
+}
+
+// pgVectorRow is a row of a PgVectorStore table
+type pgVectorRow struct {
+	ID        string `gorm:"primaryKey"`
+	Embedding Vector
+	Metadata  string
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnsureCollection creates the vector extension, the table and an HNSW
+// index for vectors of the given dimensions if they don't exist
+func (s *PgVectorStore) EnsureCollection(ctx context.Context, dimensions int) error {
+	if !sqlIdentifier.MatchString(s.Table) {
+		return fmt.Errorf("invalid table name %q", s.Table)
+	}
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			"CREATE EXTENSION IF NOT EXISTS vector",
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, embedding vector(%d) NOT NULL, metadata jsonb NOT NULL DEFAULT '{}')", s.Table, dimensions),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding vector_cosine_ops)", s.Table, s.Table),
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PgVectorStore) table(ctx context.Context) *gorm.DB {
+	return s.DB.WithContext(ctx).Table(s.Table)
+}
+
+func pgVectorRowOf(doc *VectorDocument) (pgVectorRow, error) {
+	metadata := []byte("{}")
+	if doc.Metadata != nil {
+		var err error
+		if metadata, err = json.Marshal(doc.Metadata); err != nil {
+			return pgVectorRow{}, err
+		}
+	}
+	return pgVectorRow{ID: doc.ID, Embedding: doc.Vector, Metadata: string(metadata)}, nil
+}
+
+func (r pgVectorRow) document() (*VectorDocument, error) {
+	doc := &VectorDocument{ID: r.ID, Vector: r.Embedding}
+	if err := json.Unmarshal([]byte(r.Metadata), &doc.Metadata); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Insert implements VectorStore, upserting the documents in batches
+func (s *PgVectorStore) Insert(ctx context.Context, documents []*VectorDocument) error {
+	return vectorBatches(documents, s.BatchSize, func(batch []*VectorDocument) error {
+		rows := make([]pgVectorRow, len(batch))
+		for i, doc := range batch {
+			row, err := pgVectorRowOf(doc)
+			if err != nil {
+				return err
+			}
+			rows[i] = row
+		}
+		return s.table(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"embedding", "metadata"}),
+		}).Create(&rows).Error
+	})
+}
+
+// searchQuery returns the nearest neighbour query, ordered by the HNSW
+// index on cosine distance
+func (s *PgVectorStore) searchQuery(ctx context.Context, queryVector Vector, limit int) *gorm.DB {
+	return s.table(ctx).
+		Select("id, embedding, metadata, embedding <=> CAST(? AS vector) AS distance", queryVector).
+		Order("distance").
+		Limit(limit)
+}
+
+// Search implements VectorStore
+func (s *PgVectorStore) Search(ctx context.Context, queryVector Vector, limit int) ([]*VectorSearchResult, error) {
+	if limit <= 0 {
+		return []*VectorSearchResult{}, nil
+	}
+	var rows []struct {
+		pgVectorRow
+		Distance float64
+	}
+	if err := s.searchQuery(ctx, queryVector, limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	results := make([]*VectorSearchResult, len(rows))
+	for i, row := range rows {
+		doc, err := row.document()
+		if err != nil {
+			return nil, err
+		}
+		results[i] = &VectorSearchResult{Document: doc, Score: float32(1 - row.Distance), Distance: float32(row.Distance)}
+	}
+	return results, nil
+}
+
+// Delete implements VectorStore
+func (s *PgVectorStore) Delete(ctx context.Context, ids []string) error {
+	return vectorBatches(ids, s.BatchSize, func(batch []string) error {
+		return s.table(ctx).Where("id IN ?", batch).Delete(&pgVectorRow{}).Error
+	})
+}
+
+// Get implements VectorStore
+func (s *PgVectorStore) Get(ctx context.Context, id string) (*VectorDocument, error) {
+	var rows []pgVectorRow
+	if err := s.table(ctx).Where("id = ?", id).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, id)
+	}
+	return rows[0].document()
+}
+
+// Update implements VectorStore
+func (s *PgVectorStore) Update(ctx context.Context, document *VectorDocument) error {
+	row, err := pgVectorRowOf(document)
+	if err != nil {
+		return err
+	}
+	result := s.table(ctx).Where("id = ?", row.ID).Updates(map[string]any{
+		"embedding": row.Embedding,
+		"metadata":  row.Metadata,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrVectorNotFound, document.ID)
+	}
+	return nil
+}
+
+// Close closes the database connections
+func (s *PgVectorStore) Close() error {
+	sqlDB, err := s.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// ========== Redis ==========
+
+// RedisVectorStore stores vectors in Redis hashes indexed by Redis vector
+// search (Redis Stack or Redis 8), compared by cosine distance. The search
+// commands need a client using RESP2, i.e. redis.Options.Protocol 2.
+type RedisVectorStore struct {
+	Client redis.UniversalClient
+
+	// Index is the search index
+	Index string
+
+	// Prefix of the hash keys, followed by the document ID
+	// Default: Index + ":"
+	Prefix string
+
+	// BatchSize is the number of documents written per pipeline
+	// Default: 256
+	BatchSize int
+}
+
+func (s *RedisVectorStore) prefix() string {
+	if s.Prefix != "" {
+		return s.Prefix
+	}
+	return s.Index + ":"
+}
+
+// EnsureCollection creates the HNSW index for vectors of the given
+// dimensions if it doesn't exist
+func (s *RedisVectorStore) EnsureCollection(ctx context.Context, dimensions int) error {
+	err := s.Client.FTCreate(ctx, s.Index,
+		&redis.FTCreateOptions{OnHash: true, Prefix: []any{s.prefix()}},
+		&redis.FieldSchema{
+			FieldName: "embedding",
+			FieldType: redis.SearchFieldTypeVector,
+			VectorArgs: &redis.FTVectorArgs{HNSWOptions: &redis.FTHNSWOptions{
+				Type: "FLOAT32", Dim: dimensions, DistanceMetric: "COSINE",
+			}},
+		},
+	).Err()
+	if err != nil && strings.Contains(err.Error(), "Index already exists") {
+		return nil
+	}
+	return err
+}
+
+// vectorBytes encodes v as little-endian float32 values, the format of
+// Redis vector fields
+func vectorBytes(v Vector) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func vectorFromBytes(b []byte) Vector {
+	v := make(Vector, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+func redisVectorDocument(id string, fields map[string]string) (*VectorDocument, error) {
+	doc := &VectorDocument{ID: id, Vector: vectorFromBytes([]byte(fields["embedding"]))}
+	if metadata := fields["metadata"]; metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &doc.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// Insert implements VectorStore, writing the documents in pipelined batches
+func (s *RedisVectorStore) Insert(ctx context.Context, documents []*VectorDocument) error {
+	return vectorBatches(documents, s.BatchSize, func(batch []*VectorDocument) error {
+		pipe := s.Client.Pipeline()
+		for _, doc := range batch {
+			metadata, err := json.Marshal(doc.Metadata)
+			if err != nil {
+				return err
+			}
+			pipe.HSet(ctx, s.prefix()+doc.ID, "embedding", vectorBytes(doc.Vector), "metadata", metadata)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// Search implements VectorStore with a KNN query
+func (s *RedisVectorStore) Search(ctx context.Context, queryVector Vector, limit int) ([]*VectorSearchResult, error) {
+	if limit <= 0 {
+		return []*VectorSearchResult{}, nil
+	}
+	res, err := s.Client.FTSearchWithArgs(ctx, s.Index, "*=>[KNN $k @embedding $vec AS distance]", &redis.FTSearchOptions{
+		Params:         map[string]any{"k": limit, "vec": vectorBytes(queryVector)},
+		SortBy:         []redis.FTSearchSortBy{{FieldName: "distance", Asc: true}},
+		Return:         []redis.FTSearchReturn{{FieldName: "distance"}, {FieldName: "embedding"}, {FieldName: "metadata"}},
+		Limit:          limit,
+		DialectVersion: 2,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*VectorSearchResult, 0, len(res.Docs))
+	for _, d := range res.Docs {
+		doc, err := redisVectorDocument(strings.TrimPrefix(d.ID, s.prefix()), d.Fields)
+		if err != nil {
+			return nil, err
+		}
+		distance, _ := strconv.ParseFloat(d.Fields["distance"], 32)
+		results = append(results, &VectorSearchResult{Document: doc, Score: float32(1 - distance), Distance: float32(distance)})
+	}
+	return results, nil
+}
+
+// Delete implements VectorStore
+func (s *RedisVectorStore) Delete(ctx context.Context, ids []string) error {
+	return vectorBatches(ids, s.BatchSize, func(batch []string) error {
+		keys := make([]string, len(batch))
+		for i, id := range batch {
+			keys[i] = s.prefix() + id
+		}
+		return s.Client.Del(ctx, keys...).Err()
+	})
+}
+
+// Get implements VectorStore
+func (s *RedisVectorStore) Get(ctx context.Context, id string) (*VectorDocument, error) {
+	fields, err := s.Client.HGetAll(ctx, s.prefix()+id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, id)
+	}
+	return redisVectorDocument(id, fields)
+}
+
+// Update implements VectorStore
+func (s *RedisVectorStore) Update(ctx context.Context, document *VectorDocument) error {
+	n, err := s.Client.Exists(ctx, s.prefix()+document.ID).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrVectorNotFound, document.ID)
+	}
+	return s.Insert(ctx, []*VectorDocument{document})
+}
+
+// Close closes the Redis connections
+func (s *RedisVectorStore) Close() error {
+	return s.Client.Close()
+}
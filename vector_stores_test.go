@@ -0,0 +1,282 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeQdrant serves the subset of the Qdrant REST API used by
+// QdrantVectorStore for a single collection
+type fakeQdrant struct {
+	mu      sync.Mutex
+	created map[string]any
+	points  map[string]qdrantPoint
+	upserts int
+	apiKeys []string
+}
+
+func (q *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.apiKeys = append(q.apiKeys, r.Header.Get("api-key"))
+	reply := func(result any) {
+		json.NewEncoder(w).Encode(map[string]any{"result": result, "status": "ok"})
+	}
+	var body map[string]json.RawMessage
+	json.NewDecoder(r.Body).Decode(&body)
+
+	path := strings.TrimPrefix(r.URL.Path, "/collections/products")
+	switch {
+	case path == "" && r.Method == "GET":
+		if q.created == nil {
+			w.WriteHeader(404)
+			json.NewEncoder(w).Encode(map[string]any{"status": map[string]string{"error": "Not found: Collection `products` doesn't exist!"}})
+			return
+		}
+		reply(map[string]any{"status": "green"})
+	case path == "" && r.Method == "PUT":
+		json.Unmarshal(body["vectors"], &q.created)
+		reply(true)
+	case path == "/points" && r.Method == "PUT":
+		var points []qdrantPoint
+		json.Unmarshal(body["points"], &points)
+		for _, p := range points {
+			q.points[p.ID] = p
+		}
+		q.upserts++
+		reply(map[string]string{"status": "completed"})
+	case path == "/points/search":
+		var vector Vector
+		var limit int
+		json.Unmarshal(body["vector"], &vector)
+		json.Unmarshal(body["limit"], &limit)
+		store := NewInMemoryVectorStore()
+		for _, p := range q.points {
+			store.Insert(r.Context(), []*VectorDocument{{ID: p.ID, Vector: p.Vector}})
+		}
+		found, _ := store.Search(r.Context(), vector, limit)
+		results := make([]qdrantPoint, len(found))
+		for i, f := range found {
+			results[i] = q.points[f.Document.ID]
+			results[i].Score = f.Score
+		}
+		reply(results)
+	case path == "/points/delete":
+		var ids []string
+		json.Unmarshal(body["points"], &ids)
+		for _, id := range ids {
+			delete(q.points, id)
+		}
+		reply(map[string]string{"status": "completed"})
+	case strings.HasPrefix(path, "/points/"):
+		p, ok := q.points[strings.TrimPrefix(path, "/points/")]
+		if !ok {
+			w.WriteHeader(404)
+			json.NewEncoder(w).Encode(map[string]any{"status": map[string]string{"error": "Not found: point"}})
+			return
+		}
+		reply(p)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// testVectorStore runs the VectorStore contract against store
+func testVectorStore(t *testing.T, store VectorStore, search bool) {
+	t.Helper()
+	ctx := context.Background()
+	docs := []*VectorDocument{
+		{ID: "coffee", Vector: Vector{1, 0, 0}, Metadata: map[string]interface{}{"name": "Coffee"}},
+		{ID: "espresso", Vector: Vector{0.9, 0.1, 0}, Metadata: map[string]interface{}{"name": "Espresso"}},
+		{ID: "bagel", Vector: Vector{0, 0, 1}, Metadata: map[string]interface{}{"name": "Bagel"}},
+	}
+	if err := store.Insert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	// Inserting again replaces the documents
+	if err := store.Insert(ctx, docs[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := store.Get(ctx, "espresso")
+	if err != nil || doc.Metadata["name"] != "Espresso" || len(doc.Vector) != 3 || doc.Vector[0] != 0.9 {
+		t.Errorf("Unexpected document %+v %v", doc, err)
+	}
+	if _, err := store.Get(ctx, "tea"); !errors.Is(err, ErrVectorNotFound) {
+		t.Errorf("Expected ErrVectorNotFound, got %v", err)
+	}
+	if err := store.Update(ctx, &VectorDocument{ID: "tea", Vector: Vector{1, 1, 1}}); !errors.Is(err, ErrVectorNotFound) {
+		t.Errorf("Expected updating a missing document to fail, got %v", err)
+	}
+	if err := store.Update(ctx, &VectorDocument{ID: "bagel", Vector: Vector{0, 1, 0}, Metadata: map[string]interface{}{"name": "Plain bagel"}}); err != nil {
+		t.Fatal(err)
+	}
+	if doc, _ := store.Get(ctx, "bagel"); doc == nil || doc.Metadata["name"] != "Plain bagel" || doc.Vector[1] != 1 {
+		t.Errorf("Expected the updated document, got %+v", doc)
+	}
+
+	if search {
+		results, err := store.Search(ctx, Vector{1, 0, 0}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 2 || results[0].Document.ID != "coffee" || results[1].Document.ID != "espresso" || results[0].Score < 0.99 {
+			t.Errorf("Unexpected search results %+v", results)
+		}
+	}
+
+	if err := store.Delete(ctx, []string{"coffee", "bagel"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "coffee"); !errors.Is(err, ErrVectorNotFound) {
+		t.Errorf("Expected the document to be deleted, got %v", err)
+	}
+	if _, err := store.Get(ctx, "espresso"); err != nil {
+		t.Errorf("Expected the other document to remain, got %v", err)
+	}
+}
+
+func TestQdrantVectorStore(t *testing.T) {
+	fake := &fakeQdrant{points: map[string]qdrantPoint{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewVectorStore(VectorStoreConfig{
+		Backend:    "qdrant",
+		DSN:        srv.URL,
+		Collection: "products",
+		Dimensions: 3,
+		APIKey:     "secret",
+		BatchSize:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.(*QdrantVectorStore).Close()
+	if fake.created["size"] != float64(3) || fake.created["distance"] != "Cosine" {
+		t.Errorf("Expected the collection to be created, got %v", fake.created)
+	}
+
+	testVectorStore(t, store, true)
+	// 3 documents in batches of 2, the reinsert and the update
+	if fake.upserts != 4 {
+		t.Errorf("Expected 4 upsert requests, got %d", fake.upserts)
+	}
+	for _, key := range fake.apiKeys {
+		if key != "secret" {
+			t.Fatalf("Expected the API key on every request, got %q", key)
+		}
+	}
+
+	// An existing collection is kept
+	fake.created["size"] = float64(5)
+	if err := store.(*QdrantVectorStore).EnsureCollection(context.Background(), 3); err != nil || fake.created["size"] != float64(5) {
+		t.Errorf("Expected the existing collection to be kept, got %v %v", fake.created, err)
+	}
+	if id := qdrantPointID("coffee"); len(id) != 36 || id[14] != '5' || id != qdrantPointID("coffee") {
+		t.Errorf("Expected a stable UUID, got %s", id)
+	}
+}
+
+func TestPgVectorStore(t *testing.T) {
+	db, err := NewGormDB(&DBConfig{Driver: "sqlite", DSN: ":memory:", MaxIdleConns: 1, MaxOpenConns: 1, LogLevel: logger.Silent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQLite stands in for PostgreSQL, without the distance operator
+	if err := db.Exec("CREATE TABLE products (id text PRIMARY KEY, embedding text NOT NULL, metadata text NOT NULL DEFAULT '{}')").Error; err != nil {
+		t.Fatal(err)
+	}
+	store := &PgVectorStore{DB: db, Table: "products", BatchSize: 2}
+	testVectorStore(t, store, false)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var rows []pgVectorRow
+		return (&PgVectorStore{DB: tx, Table: "products"}).searchQuery(context.Background(), Vector{1, 0.5}, 5).Find(&rows)
+	})
+	if want := `SELECT id, embedding, metadata, embedding <=> CAST("[1,0.5]" AS vector) AS distance FROM ` + "`products`" + ` ORDER BY distance LIMIT 5`; sql != want {
+		t.Errorf("Expected %s, got %s", want, sql)
+	}
+	if err := (&PgVectorStore{DB: db, Table: "products; DROP TABLE x"}).EnsureCollection(context.Background(), 3); err == nil {
+		t.Error("Expected an invalid table name to be rejected")
+	}
+}
+
+func TestVectorSQLValue(t *testing.T) {
+	value, _ := Vector{1, -0.25, 3e-7}.Value()
+	if value != "[1,-0.25,3e-07]" {
+		t.Errorf("Unexpected value %v", value)
+	}
+	var v Vector
+	if err := v.Scan([]byte("[1, -0.25,3e-07]")); err != nil || len(v) != 3 || v[1] != -0.25 || v[2] != 3e-7 {
+		t.Errorf("Unexpected vector %v %v", v, err)
+	}
+	for _, invalid := range []any{"1,2", "[a]", 42} {
+		if err := v.Scan(invalid); err == nil {
+			t.Errorf("Expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestRedisVectorStore(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	// miniredis doesn't implement vector search, so only storage is tested
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), Protocol: 2})
+	store := &RedisVectorStore{Client: client, Index: "products", BatchSize: 2}
+	defer store.Close()
+	testVectorStore(t, store, false)
+
+	if !mr.Exists("products:espresso") {
+		t.Errorf("Expected documents under the index prefix, got %v", mr.Keys())
+	}
+	if v := vectorFromBytes(vectorBytes(Vector{1.5, -2})); len(v) != 2 || v[0] != 1.5 || v[1] != -2 {
+		t.Errorf("Unexpected vector %v", v)
+	}
+}
+
+func TestNewVectorStore(t *testing.T) {
+	store, err := NewVectorStore(VectorStoreConfig{})
+	if _, ok := store.(*InMemoryVectorStore); !ok || err != nil {
+		t.Errorf("Expected the in-memory store by default, got %T %v", store, err)
+	}
+	if store, _ := NewVectorStore(VectorStoreConfig{Backend: "hnsw"}); store == nil {
+		t.Error("Expected the HNSW store")
+	}
+	if _, err := NewVectorStore(VectorStoreConfig{Backend: "milvus"}); err == nil {
+		t.Error("Expected an unknown backend to be rejected")
+	}
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	store, err = NewVectorStore(VectorStoreConfig{Backend: "redis", DSN: "redis://" + mr.Addr() + "/0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.(*RedisVectorStore).Close()
+	if s := store.(*RedisVectorStore); s.Index != "vectors" || s.BatchSize != 256 {
+		t.Errorf("Expected the defaults, got %+v", s)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ViewKey is the context key holding the view used by JSONView when the
+// call names none, see SetView
+const ViewKey = "gotap.view"
+
+// viewConfig holds the options of a JSONView call
+type viewConfig struct {
+	view      string
+	hasView   bool
+	fields    []string
+	hasFields bool
+}
+
+// ViewOption configures a JSONView call
+type ViewOption func(*viewConfig)
+
+// View selects the view whose fields are rendered: fields tagged
+// `view:"admin,user"` are only rendered in the admin and user views, while
+// untagged fields are always rendered
+func View(name string) ViewOption {
+	return func(vc *viewConfig) {
+		vc.view, vc.hasView = name, true
+	}
+}
+
+// Fields limits the rendered fields, like the fields query parameter,
+// which it replaces. Nested fields are dotted, e.g. "supplier.name".
+func Fields(names ...string) ViewOption {
+	return func(vc *viewConfig) {
+		vc.fields, vc.hasFields = names, true
+	}
+}
+
+// SetView sets the view rendered by JSONView calls that name none, e.g.
+// from the user's role in a middleware
+func (c *Context) SetView(name string) {
+	c.Set(ViewKey, name)
+}
+
+// JSONView serializes obj as JSON like JSON, rendering only the fields of
+// a view and those requested with the fields query parameter, so one type
+// serves every role and client instead of a DTO per role:
+//
+//	type Product struct {
+//	    ID        uint    `json:"id"`
+//	    Name      string  `json:"name"`
+//	    CostPrice float64 `json:"cost_price" view:"admin"`
+//	    Stock     int     `json:"stock" view:"admin,clerk"`
+//	}
+//
+//	c.JSONView(200, products, goTap.View(role))
+//
+// GET /products?fields=id,name then renders only the ID and name of each
+// product. The fields apply to obj, or to its elements when it is a list;
+// unknown names are ignored. Without a View option the view set with
+// SetView is used; with no view at all, tagged fields are left out.
+func (c *Context) JSONView(code int, obj any, opts ...ViewOption) {
+	var vc viewConfig
+	for _, opt := range opts {
+		opt(&vc)
+	}
+	if !vc.hasView {
+		if v, ok := c.Get(ViewKey); ok {
+			vc.view, _ = v.(string)
+		}
+	}
+	if !vc.hasFields {
+		if fields := c.Query("fields"); fields != "" {
+			vc.fields = strings.Split(fields, ",")
+		}
+	}
+
+	inView := viewKeep(vc.view)
+	keep := inView
+	if v, ok := c.Get("redactor"); ok {
+		if r, ok := v.(*redactor); ok {
+			keep = func(name string, field *reflect.StructField) bool {
+				return inView(name, field) && r.keep(name, field)
+			}
+		}
+	}
+	c.JSON(code, parseFieldTree(vc.fields).prune(filterJSON(reflect.ValueOf(obj), keep)))
+}
+
+// ApplyView returns a copy of obj, as generic JSON values, with only the
+// fields of view and, unless fields is empty, the listed fields, as
+// rendered by JSONView
+func ApplyView(obj any, view string, fields ...string) any {
+	return parseFieldTree(fields).prune(filterJSON(reflect.ValueOf(obj), viewKeep(view)))
+}
+
+// viewKeep keeps untagged fields and fields tagged with view
+func viewKeep(view string) func(name string, field *reflect.StructField) bool {
+	return func(name string, field *reflect.StructField) bool {
+		if field == nil {
+			return true
+		}
+		views, tagged := field.Tag.Lookup("view")
+		if !tagged {
+			return true
+		}
+		for _, v := range strings.Split(views, ",") {
+			if view != "" && strings.TrimSpace(v) == view {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// fieldTree holds requested fields; a nil subtree keeps the whole field
+type fieldTree map[string]fieldTree
+
+func parseFieldTree(fields []string) fieldTree {
+	tree := fieldTree{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		node := tree
+		segments := strings.Split(field, ".")
+		for i, segment := range segments {
+			child, exists := node[segment]
+			if exists && child == nil {
+				break // the whole field is already requested
+			}
+			if i == len(segments)-1 {
+				node[segment] = nil
+				break
+			}
+			if !exists {
+				child = fieldTree{}
+				node[segment] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// prune removes the fields of filtered JSON values missing from t; an
+// empty tree keeps them all
+func (t fieldTree) prune(v any) any {
+	if len(t) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			sub, ok := t[k]
+			if !ok {
+				delete(v, k)
+			} else if sub != nil {
+				v[k] = sub.prune(child)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = t.prune(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type viewSupplier struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone" view:"admin"`
+}
+
+type viewProduct struct {
+	ID        uint          `json:"id"`
+	Name      string        `json:"name"`
+	CostPrice float64       `json:"cost_price" view:"admin"`
+	Stock     int           `json:"stock" view:"admin, clerk"`
+	Supplier  *viewSupplier `json:"supplier"`
+}
+
+func TestJSONView(t *testing.T) {
+	products := []viewProduct{{ID: 1, Name: "Tea", CostPrice: 1.5, Stock: 9, Supplier: &viewSupplier{Name: "Leafco", Phone: "555"}}}
+
+	r := New()
+	r.GET("/products", func(c *Context) {
+		c.JSONView(200, products, View(c.Query("view")))
+	})
+	r.GET("/clerk/products", func(c *Context) { c.SetView("clerk"); c.Next() }, func(c *Context) {
+		c.JSONView(200, products)
+	})
+	r.GET("/fixed", func(c *Context) {
+		c.JSONView(200, products[0], View("admin"), Fields("name"))
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/products", `[{"id":1,"name":"Tea","supplier":{"name":"Leafco"}}]`},
+		{"/products?view=admin", `[{"cost_price":1.5,"id":1,"name":"Tea","stock":9,"supplier":{"name":"Leafco","phone":"555"}}]`},
+		{"/products?view=admin&fields=name,supplier.phone,bogus", `[{"name":"Tea","supplier":{"phone":"555"}}]`},
+		{"/products?view=user&fields=cost_price", `[{}]`},
+		{"/clerk/products?fields=id,stock", `[{"id":1,"stock":9}]`},
+		{"/fixed?fields=id", `{"name":"Tea"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+		var got, want any
+		json.Unmarshal(w.Body.Bytes(), &got)
+		json.Unmarshal([]byte(tt.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %s, want %s", tt.url, w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestJSONViewWithRedaction(t *testing.T) {
+	r := New()
+	r.Use(Redaction(RedactionConfig{
+		RoleFunc: func(c *Context) string { return "admin" },
+		Policy:   map[string][]string{"admin": {"phone"}},
+	}))
+	r.GET("/", func(c *Context) {
+		c.JSONView(200, viewProduct{ID: 1, Supplier: &viewSupplier{Name: "Leafco", Phone: "555"}}, View("admin"), Fields("supplier"))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != `{"supplier":{"name":"Leafco"}}`+"\n" {
+		t.Errorf("Expected redaction to apply to views, got %s", w.Body.String())
+	}
+}
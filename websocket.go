@@ -5,9 +5,11 @@
 package goTap
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -18,6 +20,9 @@ var (
 	ErrWebSocketUpgradeFailed = errors.New("websocket upgrade failed")
 	// ErrConnectionClosed is returned when connection is closed
 	ErrConnectionClosed = errors.New("connection closed")
+	// ErrSendQueueFull is returned when a message is dropped because the
+	// client's send queue is full
+	ErrSendQueueFull = errors.New("send queue full")
 )
 
 // WebSocketConfig holds WebSocket configuration
@@ -37,8 +42,42 @@ type WebSocketConfig struct {
 	// Error defines a function to handle errors
 	Error func(c *Context, status int, err error)
 
-	// Subprotocols specifies the server's supported protocols
+	// Subprotocols specifies the server's supported protocols, in order
+	// of preference; the first one the client offers in
+	// Sec-WebSocket-Protocol is selected, see WebSocketConn.Subprotocol
 	Subprotocols []string
+
+	// RequireSubprotocol rejects clients that offer none of Subprotocols
+	// with 400
+	// Optional.
+	RequireSubprotocol bool
+
+	// EnableCompression negotiates per-message compression
+	// (permessage-deflate) with clients that support it
+	// Optional.
+	EnableCompression bool
+
+	// CompressionLevel is the flate level of compressed messages, from
+	// -2 (Huffman only) to 9 (best compression)
+	// Default: 1 (best speed)
+	CompressionLevel int
+
+	// SendQueueSize is the number of outgoing messages queued per
+	// connection, so a slow client doesn't stall the senders
+	// Default: 256
+	SendQueueSize int
+
+	// Overflow decides what happens when the send queue is full:
+	// OverflowDrop and OverflowDropOldest drop a message,
+	// OverflowDisconnect closes the slow client and OverflowBlock waits
+	// for room, stalling the sender
+	// Default: OverflowDrop
+	Overflow OverflowPolicy
+
+	// WriteTimeout bounds each message write, so a client that stopped
+	// reading is disconnected
+	// Default: 10s
+	WriteTimeout time.Duration
 }
 
 // WebSocketHandler defines the function signature for WebSocket handlers
@@ -51,7 +90,30 @@ type WebSocketConn struct {
 	writeMu  sync.Mutex
 	Context  *Context
 	closed   bool
-	sendChan chan []byte
+	sendChan chan wsMessage
+
+	overflow     OverflowPolicy
+	writeTimeout time.Duration
+	done         chan struct{}
+	pumpDone     chan struct{}
+
+	sent, dropped atomic.Int64
+	disconnected  atomic.Bool
+}
+
+// wsMessage is a queued message with its frame type
+type wsMessage struct {
+	typ  int
+	data []byte
+}
+
+// WebSocketStats reports the send queue of a connection
+type WebSocketStats struct {
+	Queued       int   `json:"queued"`
+	QueueSize    int   `json:"queue_size"`
+	Sent         int64 `json:"sent"`
+	Dropped      int64 `json:"dropped"`
+	Disconnected bool  `json:"disconnected"`
 }
 
 // WSUpgrader is the default WebSocket upgrader
@@ -90,14 +152,28 @@ func (c *Context) WebSocketWithConfig(config WebSocketConfig, handler WebSocketH
 			c.String(status, err.Error())
 		}
 	}
+	if config.SendQueueSize <= 0 {
+		config.SendQueueSize = 256
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	if config.CompressionLevel == 0 {
+		config.CompressionLevel = 1
+	}
+	if config.RequireSubprotocol && !acceptsSubprotocol(c.Request, config.Subprotocols) {
+		config.Error(c, http.StatusBadRequest, errors.New("websocket: no supported subprotocol offered"))
+		return
+	}
 
 	// Create upgrader
 	upgrader := websocket.Upgrader{
-		ReadBufferSize:   config.ReadBufferSize,
-		WriteBufferSize:  config.WriteBufferSize,
-		HandshakeTimeout: config.HandshakeTimeout,
-		CheckOrigin:      config.CheckOrigin,
-		Subprotocols:     config.Subprotocols,
+		ReadBufferSize:    config.ReadBufferSize,
+		WriteBufferSize:   config.WriteBufferSize,
+		HandshakeTimeout:  config.HandshakeTimeout,
+		CheckOrigin:       config.CheckOrigin,
+		Subprotocols:      config.Subprotocols,
+		EnableCompression: config.EnableCompression,
 	}
 
 	// Upgrade connection
@@ -106,12 +182,19 @@ func (c *Context) WebSocketWithConfig(config WebSocketConfig, handler WebSocketH
 		config.Error(c, http.StatusBadRequest, err)
 		return
 	}
+	if config.EnableCompression {
+		conn.SetCompressionLevel(config.CompressionLevel)
+	}
 
 	// Create WebSocket connection wrapper
 	wsConn := &WebSocketConn{
-		Conn:     conn,
-		Context:  c,
-		sendChan: make(chan []byte, 256),
+		Conn:         conn,
+		Context:      c,
+		sendChan:     make(chan wsMessage, config.SendQueueSize),
+		overflow:     config.Overflow,
+		writeTimeout: config.WriteTimeout,
+		done:         make(chan struct{}),
+		pumpDone:     make(chan struct{}),
 	}
 
 	// Start write pump
@@ -129,29 +212,72 @@ func (ws *WebSocketConn) SendText(message string) error {
 	return ws.Send([]byte(message))
 }
 
-// SendJSON sends a JSON message
+// SendJSON queues a JSON message
 func (ws *WebSocketConn) SendJSON(v interface{}) error {
-	ws.writeMu.Lock()
-	defer ws.writeMu.Unlock()
-
-	if ws.closed {
-		return ErrConnectionClosed
+	message, err := json.Marshal(v)
+	if err != nil {
+		return err
 	}
-
-	return ws.WriteJSON(v)
+	return ws.Send(message)
 }
 
-// Send sends a binary message
+// Send queues a text message, applying the overflow policy when the send
+// queue is full
 func (ws *WebSocketConn) Send(message []byte) error {
-	if ws.closed {
+	return ws.enqueue(wsMessage{websocket.TextMessage, message})
+}
+
+// SendBinary queues a binary message, e.g. a receipt image or ESC/POS
+// bytes, without encoding it as text
+func (ws *WebSocketConn) SendBinary(data []byte) error {
+	return ws.enqueue(wsMessage{websocket.BinaryMessage, data})
+}
+
+// enqueue queues a message, applying the overflow policy when the send
+// queue is full
+func (ws *WebSocketConn) enqueue(message wsMessage) error {
+	select {
+	case <-ws.done:
+		return ErrConnectionClosed
+	case <-ws.pumpDone:
 		return ErrConnectionClosed
+	default:
 	}
 
-	select {
-	case ws.sendChan <- message:
-		return nil
+	switch ws.overflow {
+	case OverflowBlock:
+		select {
+		case ws.sendChan <- message:
+			return nil
+		case <-ws.pumpDone:
+			return ErrConnectionClosed
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case ws.sendChan <- message:
+				return nil
+			default:
+			}
+			select {
+			case <-ws.sendChan:
+				ws.dropped.Add(1)
+			default:
+			}
+		}
 	default:
-		return errors.New("send buffer full")
+		select {
+		case ws.sendChan <- message:
+			return nil
+		default:
+		}
+		ws.dropped.Add(1)
+		if ws.overflow == OverflowDisconnect && !ws.disconnected.Swap(true) {
+			// Closing the network connection fails the pending write
+			// and the handler's reads, so the handler returns
+			ws.Conn.Close()
+		}
+		return ErrSendQueueFull
 	}
 }
 
@@ -169,22 +295,36 @@ func (ws *WebSocketConn) ReadText() (string, error) {
 	return string(message), nil
 }
 
+// ReadBinary reads a binary message
+func (ws *WebSocketConn) ReadBinary() ([]byte, error) {
+	messageType, message, err := ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	if messageType != websocket.BinaryMessage {
+		return nil, errors.New("not a binary message")
+	}
+
+	return message, nil
+}
+
 // ReadJSON reads a JSON message
 func (ws *WebSocketConn) ReadJSON(v interface{}) error {
 	return ws.Conn.ReadJSON(v)
 }
 
-// Close closes the WebSocket connection
+// Close flushes the send queue and closes the WebSocket connection
 func (ws *WebSocketConn) Close() error {
 	ws.mu.Lock()
-	defer ws.mu.Unlock()
-
 	if ws.closed {
+		ws.mu.Unlock()
 		return nil
 	}
-
 	ws.closed = true
-	close(ws.sendChan)
+	close(ws.done)
+	ws.mu.Unlock()
+	<-ws.pumpDone
 
 	// Send close message
 	ws.WriteControl(websocket.CloseMessage,
@@ -194,25 +334,81 @@ func (ws *WebSocketConn) Close() error {
 	return ws.Conn.Close()
 }
 
+// acceptsSubprotocol reports whether the client offers one of protocols
+func acceptsSubprotocol(r *http.Request, protocols []string) bool {
+	for _, offered := range websocket.Subprotocols(r) {
+		if containsString(protocols, offered) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsClosed returns true if connection is closed
 func (ws *WebSocketConn) IsClosed() bool {
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
-	return ws.closed
+	select {
+	case <-ws.done:
+		return true
+	default:
+		return ws.disconnected.Load()
+	}
+}
+
+// QueueDepth returns the number of messages waiting to be sent
+func (ws *WebSocketConn) QueueDepth() int {
+	return len(ws.sendChan)
 }
 
-// writePump handles outgoing messages
+// Stats reports the send queue of the connection
+func (ws *WebSocketConn) Stats() WebSocketStats {
+	return WebSocketStats{
+		Queued:       len(ws.sendChan),
+		QueueSize:    cap(ws.sendChan),
+		Sent:         ws.sent.Load(),
+		Dropped:      ws.dropped.Load(),
+		Disconnected: ws.disconnected.Load(),
+	}
+}
+
+// writePump writes queued messages until the connection is closed, then
+// flushes what is left in the queue
 func (ws *WebSocketConn) writePump() {
-	for message := range ws.sendChan {
-		ws.writeMu.Lock()
-		if err := ws.WriteMessage(websocket.TextMessage, message); err != nil {
-			ws.writeMu.Unlock()
-			return
+	defer close(ws.pumpDone)
+	for {
+		select {
+		case message := <-ws.sendChan:
+			if !ws.write(message) {
+				return
+			}
+		case <-ws.done:
+			for {
+				select {
+				case message := <-ws.sendChan:
+					if !ws.write(message) {
+						return
+					}
+				default:
+					return
+				}
+			}
 		}
-		ws.writeMu.Unlock()
 	}
 }
 
+// write sends a message within the write timeout; a failed write closes
+// the network connection
+func (ws *WebSocketConn) write(message wsMessage) bool {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	ws.Conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout))
+	if err := ws.WriteMessage(message.typ, message.data); err != nil {
+		ws.Conn.Close()
+		return false
+	}
+	ws.sent.Add(1)
+	return true
+}
+
 // SetReadDeadline sets the read deadline
 func (ws *WebSocketConn) SetReadDeadline(t time.Time) error {
 	return ws.Conn.SetReadDeadline(t)
@@ -223,12 +419,29 @@ func (ws *WebSocketConn) SetWriteDeadline(t time.Time) error {
 	return ws.Conn.SetWriteDeadline(t)
 }
 
+// HubClient is a client of a WebSocketHub: a WebSocketConn, or a
+// PollClient for networks that block WebSocket upgrades
+type HubClient interface {
+	// Send queues a message for the client
+	Send(message []byte) error
+
+	// IsClosed reports whether the client is gone
+	IsClosed() bool
+
+	// Close disconnects the client
+	Close() error
+
+	// Stats reports the client's send queue
+	Stats() WebSocketStats
+}
+
 // WebSocketHub manages WebSocket connections
 type WebSocketHub struct {
-	clients    map[*WebSocketConn]bool
+	clients    map[HubClient]bool
+	rooms      map[string]map[HubClient]bool
 	broadcast  chan []byte
-	register   chan *WebSocketConn
-	unregister chan *WebSocketConn
+	register   chan HubClient
+	unregister chan HubClient
 	mu         sync.RWMutex
 }
 
@@ -236,9 +449,10 @@ type WebSocketHub struct {
 func NewWebSocketHub() *WebSocketHub {
 	hub := &WebSocketHub{
 		broadcast:  make(chan []byte, 256),
-		register:   make(chan *WebSocketConn),
-		unregister: make(chan *WebSocketConn),
-		clients:    make(map[*WebSocketConn]bool),
+		register:   make(chan HubClient),
+		unregister: make(chan HubClient),
+		clients:    make(map[HubClient]bool),
+		rooms:      make(map[string]map[HubClient]bool),
 	}
 
 	go hub.run()
@@ -257,31 +471,27 @@ func (h *WebSocketHub) run() {
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
+			_, ok := h.clients[client]
+			delete(h.clients, client)
+			h.leaveAll(client)
+			h.mu.Unlock()
+			if ok {
 				client.Close()
 			}
-			h.mu.Unlock()
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				if !client.IsClosed() {
-					client.Send(message)
-				}
-			}
-			h.mu.RUnlock()
+			h.sendAll(message)
 		}
 	}
 }
 
 // Register registers a new client
-func (h *WebSocketHub) Register(client *WebSocketConn) {
+func (h *WebSocketHub) Register(client HubClient) {
 	h.register <- client
 }
 
 // Unregister unregisters a client
-func (h *WebSocketHub) Unregister(client *WebSocketConn) {
+func (h *WebSocketHub) Unregister(client HubClient) {
 	h.unregister <- client
 }
 
@@ -290,18 +500,92 @@ func (h *WebSocketHub) Broadcast(message []byte) {
 	h.broadcast <- message
 }
 
-// BroadcastJSON sends a JSON message to all clients
-func (h *WebSocketHub) BroadcastJSON(v interface{}) {
+// BroadcastJSON sends a JSON message to all clients. The message is
+// queued for each client, so a slow client doesn't delay the others.
+func (h *WebSocketHub) BroadcastJSON(v interface{}) error {
+	message, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.sendAll(message)
+	return nil
+}
+
+// sendAll queues message for every client
+func (h *WebSocketHub) sendAll(message []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	for client := range h.clients {
 		if !client.IsClosed() {
-			client.SendJSON(v)
+			client.Send(message)
+		}
+	}
+}
+
+// Join adds client to room, e.g. "store:12" for the dashboards of one
+// store. Unregister removes the client from all its rooms.
+func (h *WebSocketHub) Join(client HubClient, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rooms == nil {
+		h.rooms = make(map[string]map[HubClient]bool)
+	}
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[HubClient]bool)
+	}
+	h.rooms[room][client] = true
+}
+
+// Leave removes client from room
+func (h *WebSocketHub) Leave(client HubClient, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rooms[room], client)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// leaveAll removes client from every room; h.mu must be held
+func (h *WebSocketHub) leaveAll(client HubClient) {
+	for room, members := range h.rooms {
+		delete(members, client)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// BroadcastToRoom sends a message to the clients in room
+func (h *WebSocketHub) BroadcastToRoom(room string, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.rooms[room] {
+		if !client.IsClosed() {
+			client.Send(message)
 		}
 	}
 }
 
+// BroadcastJSONToRoom sends a JSON message to the clients in room
+func (h *WebSocketHub) BroadcastJSONToRoom(room string, v interface{}) error {
+	message, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.BroadcastToRoom(room, message)
+	return nil
+}
+
+// RoomClientCount returns the number of clients in room
+func (h *WebSocketHub) RoomClientCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
 // ClientCount returns the number of connected clients
 func (h *WebSocketHub) ClientCount() int {
 	h.mu.RLock()
@@ -309,12 +593,28 @@ func (h *WebSocketHub) ClientCount() int {
 	return len(h.clients)
 }
 
-// Clients returns all connected clients
+// Clients returns the connected WebSocket clients. Clients of other
+// transports, such as PollClient, are listed by HubClients.
 func (h *WebSocketHub) Clients() []*WebSocketConn {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	clients := make([]*WebSocketConn, 0, len(h.clients))
+	for client := range h.clients {
+		if conn, ok := client.(*WebSocketConn); ok {
+			clients = append(clients, conn)
+		}
+	}
+
+	return clients
+}
+
+// HubClients returns all connected clients, whatever their transport
+func (h *WebSocketHub) HubClients() []HubClient {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := make([]HubClient, 0, len(h.clients))
 	for client := range h.clients {
 		clients = append(clients, client)
 	}
@@ -322,13 +622,50 @@ func (h *WebSocketHub) Clients() []*WebSocketConn {
 	return clients
 }
 
+// HubStats reports the send queues of a hub's clients
+type HubStats struct {
+	Clients int `json:"clients"`
+	// Queued is the number of messages waiting in all queues, and
+	// MaxQueued the depth of the fullest queue
+	Queued    int `json:"queued"`
+	MaxQueued int `json:"max_queued"`
+	// Dropped counts the messages dropped because a queue was full, and
+	// Disconnected the clients disconnected for being too slow
+	Dropped      int64 `json:"dropped"`
+	Disconnected int   `json:"disconnected"`
+}
+
+// Stats reports the send queues of the connected clients, e.g. for a
+// metrics endpoint. Clients that have unregistered are not counted.
+func (h *WebSocketHub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := HubStats{Clients: len(h.clients)}
+	for client := range h.clients {
+		s := client.Stats()
+		stats.Queued += s.Queued
+		stats.MaxQueued = max(stats.MaxQueued, s.Queued)
+		stats.Dropped += s.Dropped
+		if s.Disconnected {
+			stats.Disconnected++
+		}
+	}
+	return stats
+}
+
 // Close closes all connections
 func (h *WebSocketHub) Close() {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
+	clients := make([]HubClient, 0, len(h.clients))
 	for client := range h.clients {
-		client.Close()
+		clients = append(clients, client)
 		delete(h.clients, client)
 	}
+	clear(h.rooms)
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
 }
@@ -1,6 +1,11 @@
 package goTap
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -408,3 +413,206 @@ func TestWebSocketUpgrade(t *testing.T) {
 		}
 	}
 }
+
+func TestWebSocketSendQueueOverflow(t *testing.T) {
+	newConn := func(size int, overflow OverflowPolicy) *WebSocketConn {
+		return &WebSocketConn{
+			sendChan: make(chan wsMessage, size),
+			overflow: overflow,
+			done:     make(chan struct{}),
+			pumpDone: make(chan struct{}),
+		}
+	}
+
+	ws := newConn(2, OverflowDrop)
+	for _, msg := range []string{"a", "b", "c"} {
+		ws.SendText(msg)
+	}
+	if err := ws.SendText("d"); err != ErrSendQueueFull {
+		t.Errorf("Expected ErrSendQueueFull, got %v", err)
+	}
+	if s := ws.Stats(); s.Queued != 2 || s.QueueSize != 2 || s.Dropped != 2 || string((<-ws.sendChan).data) != "a" {
+		t.Errorf("Expected the newest messages to be dropped, got %+v", s)
+	}
+
+	ws = newConn(2, OverflowDropOldest)
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := ws.SendText(msg); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	if ws.Stats().Dropped != 1 || string((<-ws.sendChan).data) != "b" {
+		t.Error("Expected the oldest message to be dropped")
+	}
+}
+
+func TestWebSocketSlowClientDisconnect(t *testing.T) {
+	engine := New()
+	hub := NewWebSocketHub()
+	result := make(chan WebSocketStats, 1)
+	engine.GET("/ws", func(c *Context) {
+		c.WebSocketWithConfig(WebSocketConfig{SendQueueSize: 2, Overflow: OverflowDisconnect}, func(ws *WebSocketConn) {
+			hub.Register(ws)
+			waitFor(t, func() bool { return hub.ClientCount() == 1 })
+			// Stall the write pump like a client that stopped reading
+			ws.writeMu.Lock()
+			for i := 0; i < 4; i++ {
+				ws.SendText("tick")
+			}
+			ws.writeMu.Unlock()
+			if hub.Stats().Disconnected != 1 || !ws.IsClosed() {
+				t.Errorf("Expected the slow client to be disconnected, got %+v", hub.Stats())
+			}
+			result <- ws.Stats()
+			hub.Unregister(ws)
+		})
+	})
+
+	server := httptest.NewServer(engine)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Close()
+
+	stats := <-result
+	if !stats.Disconnected || stats.Dropped == 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+func TestWebSocketHubLongPoll(t *testing.T) {
+	hub := NewWebSocketHub()
+	defer hub.Close()
+	var received []string
+	r := New()
+	hub.LongPoll(r.Group("/ws/poll"), LongPollConfig{
+		Timeout:     50 * time.Millisecond,
+		IdleTimeout: 300 * time.Millisecond,
+		OnConnect: func(c *Context, client *PollClient) error {
+			if c.Query("store") == "" {
+				return errors.New("store is required")
+			}
+			hub.Join(client, "store:"+c.Query("store"))
+			return nil
+		},
+		OnMessage: func(client *PollClient, message []byte) { received = append(received, string(message)) },
+	})
+
+	type pollResponse struct {
+		ClientID string   `json:"client_id"`
+		Cursor   uint64   `json:"cursor"`
+		Messages []string `json:"messages"`
+	}
+	request := func(method, target, body string) (int, pollResponse) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var resp pollResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	if code, _ := request("GET", "/ws/poll", ""); code != http.StatusForbidden {
+		t.Errorf("Expected OnConnect to reject the client, got %d", code)
+	}
+	_, conn := request("GET", "/ws/poll?store=1", "")
+	waitFor(t, func() bool { return hub.ClientCount() == 1 })
+	if clients := hub.Clients(); len(clients) != 0 {
+		t.Errorf("Expected Clients to list only WebSocket connections, got %d", len(clients))
+	}
+	if clients := hub.HubClients(); len(clients) != 1 {
+		t.Errorf("Expected HubClients to list the poll client, got %d", len(clients))
+	}
+	poll := func(cursor int) (int, pollResponse) {
+		return request("GET", fmt.Sprintf("/ws/poll?client=%s&cursor=%d", conn.ClientID, cursor), "")
+	}
+
+	hub.BroadcastJSON(H{"n": 1})
+	hub.BroadcastToRoom("store:1", []byte("hello"))
+	hub.BroadcastToRoom("store:2", []byte("other store"))
+	for i := 0; i < 2; i++ {
+		// Polling again with the same cursor, as after a lost response,
+		// returns the same messages
+		if _, resp := poll(0); resp.Cursor != 2 || strings.Join(resp.Messages, ",") != `{"n":1},hello` {
+			t.Errorf("Unexpected poll %+v", resp)
+		}
+	}
+	if _, resp := poll(2); resp.Cursor != 2 || len(resp.Messages) != 0 {
+		t.Errorf("Expected an empty poll after the timeout, got %+v", resp)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		hub.BroadcastToRoom("store:1", []byte("late"))
+	}()
+	if _, resp := poll(2); resp.Cursor != 3 || len(resp.Messages) != 1 || resp.Messages[0] != "late" {
+		t.Errorf("Expected the poll to wait for the message, got %+v", resp)
+	}
+
+	if code, _ := request("POST", "/ws/poll?client="+conn.ClientID, "ping"); code != http.StatusNoContent || len(received) != 1 || received[0] != "ping" {
+		t.Errorf("Expected the message to reach OnMessage, got %d %v", code, received)
+	}
+	if s := hub.Stats(); s.Clients != 1 || s.Queued != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	waitFor(t, func() bool { return hub.ClientCount() == 0 })
+	if code, _ := poll(3); code != http.StatusGone {
+		t.Errorf("Expected an idle client to expire, got %d", code)
+	}
+}
+
+func TestWebSocketBinaryAndSubprotocols(t *testing.T) {
+	engine := New()
+	engine.GET("/ws", func(c *Context) {
+		c.WebSocketWithConfig(WebSocketConfig{
+			Subprotocols:       []string{"escpos.v2", "escpos.v1"},
+			RequireSubprotocol: true,
+			EnableCompression:  true,
+		}, func(ws *WebSocketConn) {
+			data, err := ws.ReadBinary()
+			if err != nil {
+				t.Errorf("Failed to read binary: %v", err)
+				return
+			}
+			ws.SendText(ws.Subprotocol())
+			ws.SendBinary(data)
+		})
+	})
+
+	server := httptest.NewServer(engine)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a client without a subprotocol to be rejected, got %v", err)
+	}
+
+	dialer := websocket.Dialer{Subprotocols: []string{"escpos.v1", "escpos.v2"}, EnableCompression: true}
+	ws, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Close()
+	if !strings.Contains(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Error("Expected permessage-deflate to be negotiated")
+	}
+
+	receipt := append([]byte{0x1b, 0x40, 0x00, 0xff}, bytes.Repeat([]byte("TOTAL 12.50\n"), 50)...)
+	ws.WriteMessage(websocket.BinaryMessage, receipt)
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if typ, msg, _ := ws.ReadMessage(); typ != websocket.TextMessage || string(msg) != "escpos.v2" {
+		t.Errorf("Expected the server's preferred subprotocol, got %q", msg)
+	}
+	if typ, msg, _ := ws.ReadMessage(); typ != websocket.BinaryMessage || !bytes.Equal(msg, receipt) {
+		t.Errorf("Expected the bytes echoed as a binary message, got type %d", typ)
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LongPollConfig holds configuration for WebSocketHub.LongPoll
+type LongPollConfig struct {
+	// Timeout is how long a poll waits for messages before returning an
+	// empty list; keep it below the timeouts of proxies in between
+	// Default: 25s
+	Timeout time.Duration
+
+	// IdleTimeout disconnects a client that stopped polling
+	// Default: 60s
+	IdleTimeout time.Duration
+
+	// QueueSize is the number of undelivered messages kept per client
+	// Default: 256
+	QueueSize int
+
+	// Overflow decides what happens when a client's queue is full, as
+	// for WebSocketConfig. OverflowBlock is treated as OverflowDrop.
+	// Default: OverflowDrop
+	Overflow OverflowPolicy
+
+	// MaxMessageBytes bounds the body of a sent message
+	// Default: 64 KB
+	MaxMessageBytes int64
+
+	// OnConnect is called with the request creating a client, e.g. to
+	// authorize it and join rooms. Returning an error rejects the client
+	// with 403.
+	// Optional.
+	OnConnect func(c *Context, client *PollClient) error
+
+	// OnMessage receives the messages the client sends
+	// Optional.
+	OnMessage func(client *PollClient, message []byte)
+}
+
+// LongPoll adds a long-polling transport for clients on networks that
+// block WebSocket upgrades. Its clients join the hub like WebSocket
+// connections, so broadcasts reach both:
+//
+//	hub.LongPoll(r.Group("/ws/poll"), goTap.LongPollConfig{
+//	    OnConnect: func(c *goTap.Context, client *goTap.PollClient) error {
+//	        hub.Join(client, "store:"+c.Query("store"))
+//	        return nil
+//	    },
+//	})
+//
+// A GET without a client parameter connects and returns the client ID.
+// GET ?client=<id>&cursor=<n> acknowledges the messages up to cursor and
+// waits for newer ones, returned as {"client_id", "cursor", "messages"}
+// with the cursor of the last message. Unacknowledged messages are sent
+// again, so a lost response loses nothing. POST ?client=<id> sends its
+// body to OnMessage. An unknown or expired client gets 410 and should
+// connect again.
+func (h *WebSocketHub) LongPoll(r IRoutes, config LongPollConfig) {
+	if config.Timeout <= 0 {
+		config.Timeout = 25 * time.Second
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 60 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	if config.MaxMessageBytes <= 0 {
+		config.MaxMessageBytes = 64 << 10
+	}
+
+	lp := &longPoll{hub: h, config: config, clients: make(map[string]*PollClient)}
+	r.GET("", lp.poll)
+	r.POST("", lp.send)
+}
+
+// longPoll serves the long-polling clients of a hub
+type longPoll struct {
+	hub    *WebSocketHub
+	config LongPollConfig
+
+	mu      sync.Mutex
+	clients map[string]*PollClient
+}
+
+func (lp *longPoll) poll(c *Context) {
+	id := c.Query("client")
+	if id == "" {
+		lp.connect(c)
+		return
+	}
+	client := lp.lookup(c, id)
+	if client == nil {
+		return
+	}
+	cursor, _ := strconv.ParseUint(c.Query("cursor"), 10, 64)
+	messages, last := client.wait(c.Request.Context(), cursor, lp.config.Timeout)
+	if messages == nil && client.IsClosed() {
+		lp.gone(c)
+		return
+	}
+	list := make([]string, len(messages))
+	for i, message := range messages {
+		list[i] = string(message)
+	}
+	c.JSON(http.StatusOK, H{"client_id": client.ID, "cursor": last, "messages": list})
+}
+
+func (lp *longPoll) connect(c *Context) {
+	client := &PollClient{
+		ID:       generateSessionID(),
+		size:     lp.config.QueueSize,
+		overflow: lp.config.Overflow,
+		notify:   make(chan struct{}),
+		next:     1,
+	}
+	client.onClose = func() {
+		lp.mu.Lock()
+		delete(lp.clients, client.ID)
+		lp.mu.Unlock()
+		lp.hub.Unregister(client)
+	}
+	client.idle = time.AfterFunc(lp.config.IdleTimeout, func() { client.Close() })
+	client.idleTimeout = lp.config.IdleTimeout
+
+	if lp.config.OnConnect != nil {
+		if err := lp.config.OnConnect(c, client); err != nil {
+			client.idle.Stop()
+			lp.hub.mu.Lock()
+			lp.hub.leaveAll(client)
+			lp.hub.mu.Unlock()
+			c.JSON(http.StatusForbidden, H{
+				"error":   "Forbidden",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+	}
+	lp.mu.Lock()
+	lp.clients[client.ID] = client
+	lp.mu.Unlock()
+	lp.hub.Register(client)
+	c.JSON(http.StatusOK, H{"client_id": client.ID, "cursor": 0, "messages": []string{}})
+}
+
+func (lp *longPoll) send(c *Context) {
+	client := lp.lookup(c, c.Query("client"))
+	if client == nil {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, lp.config.MaxMessageBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return
+	}
+	if int64(len(body)) > lp.config.MaxMessageBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, H{
+			"error":   "Request Entity Too Large",
+			"message": "message exceeds " + strconv.FormatInt(lp.config.MaxMessageBytes, 10) + " bytes",
+		})
+		c.Abort()
+		return
+	}
+	if lp.config.OnMessage != nil {
+		lp.config.OnMessage(client, body)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// lookup returns the client and marks it active, or responds 410
+func (lp *longPoll) lookup(c *Context, id string) *PollClient {
+	lp.mu.Lock()
+	client := lp.clients[id]
+	lp.mu.Unlock()
+	if client == nil || !client.touch() {
+		lp.gone(c)
+		return nil
+	}
+	return client
+}
+
+func (lp *longPoll) gone(c *Context) {
+	c.JSON(http.StatusGone, H{
+		"error":   "Gone",
+		"message": "unknown or expired client, connect again",
+	})
+	c.Abort()
+}
+
+// PollClient is a long-polling client of a WebSocketHub
+type PollClient struct {
+	// ID identifies the client in its requests; it is unguessable and so
+	// authenticates the client like a session ID
+	ID string
+
+	mu       sync.Mutex
+	size     int
+	overflow OverflowPolicy
+	messages [][]byte
+	// first is the cursor of messages[0], next the cursor of the next
+	// message sent
+	first, next uint64
+	notify      chan struct{}
+	closed      bool
+
+	idle        *time.Timer
+	idleTimeout time.Duration
+	onClose     func()
+
+	sent, dropped atomic.Int64
+	disconnected  atomic.Bool
+}
+
+var _ HubClient = (*PollClient)(nil)
+
+// Send queues a message for the next poll, applying the overflow policy
+// when the queue is full
+func (p *PollClient) Send(message []byte) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrConnectionClosed
+	}
+	if len(p.messages) >= p.size {
+		p.dropped.Add(1)
+		switch p.overflow {
+		case OverflowDropOldest:
+			p.messages = p.messages[1:]
+			p.first++
+		case OverflowDisconnect:
+			p.disconnected.Store(true)
+			p.mu.Unlock()
+			p.Close()
+			return ErrSendQueueFull
+		default:
+			p.mu.Unlock()
+			return ErrSendQueueFull
+		}
+	}
+	if len(p.messages) == 0 {
+		p.first = p.next
+	}
+	p.messages = append(p.messages, message)
+	p.next++
+	close(p.notify)
+	p.notify = make(chan struct{})
+	p.mu.Unlock()
+	return nil
+}
+
+// wait acknowledges the messages up to cursor and returns the pending
+// messages with the cursor of the last one, waiting up to timeout for one
+func (p *PollClient) wait(ctx context.Context, cursor uint64, timeout time.Duration) ([][]byte, uint64) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		p.mu.Lock()
+		if len(p.messages) > 0 && cursor >= p.first {
+			n := len(p.messages)
+			if acked := cursor - p.first + 1; acked < uint64(n) {
+				n = int(acked)
+			}
+			p.messages = p.messages[n:]
+			p.first += uint64(n)
+			p.sent.Add(int64(n))
+		}
+		if len(p.messages) > 0 || p.closed {
+			messages := append([][]byte(nil), p.messages...)
+			last := p.first + uint64(len(p.messages)) - 1
+			if len(messages) == 0 {
+				messages, last = nil, cursor
+			}
+			p.mu.Unlock()
+			return messages, last
+		}
+		notify := p.notify
+		p.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return [][]byte{}, cursor
+		case <-ctx.Done():
+			return [][]byte{}, cursor
+		}
+	}
+}
+
+// touch resets the idle timer; it reports false for a closed client
+func (p *PollClient) touch() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.idle.Reset(p.idleTimeout)
+	return true
+}
+
+// Close disconnects the client; its next poll gets 410
+func (p *PollClient) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.messages = nil
+	p.idle.Stop()
+	close(p.notify)
+	p.mu.Unlock()
+	if p.onClose != nil {
+		// Send may run under the hub's lock, which unregistering needs
+		go p.onClose()
+	}
+	return nil
+}
+
+// IsClosed returns true if the client disconnected or expired
+func (p *PollClient) IsClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// Stats reports the client's queue; Sent counts acknowledged messages
+func (p *PollClient) Stats() WebSocketStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return WebSocketStats{
+		Queued:       len(p.messages),
+		QueueSize:    p.size,
+		Sent:         p.sent.Load(),
+		Dropped:      p.dropped.Load(),
+		Disconnected: p.disconnected.Load(),
+	}
+}
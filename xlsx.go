@@ -0,0 +1,206 @@
+// Copyright 2025 goTap Authors. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package goTap
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// XLSXSheet is a named worksheet for Context.XLSX
+type XLSXSheet struct {
+	// Name is the sheet's tab name; Excel allows up to 31 characters and
+	// none of []:*?/\
+	// Default: "Sheet1", "Sheet2", ...
+	Name string
+
+	// Rows are the rows of the sheet, of the types Context.CSV accepts
+	Rows any
+}
+
+// XLSX writes an Excel workbook, streaming the rows into the zip archive
+// as they are read. sheet is an XLSXSheet, a []XLSXSheet, or rows of the
+// types CSV accepts for a single sheet:
+//
+//	c.Header("Content-Disposition", `attachment; filename="sales.xlsx"`)
+//	c.XLSX(http.StatusOK, []goTap.XLSXSheet{
+//	    {Name: "Products", Rows: products},
+//	    {Name: "Sales", Rows: sales},
+//	})
+//
+// Numbers and booleans are written as numeric cells, everything else as
+// text formatted like CSV.
+func (c *Context) XLSX(code int, sheet any) {
+	var sheets []XLSXSheet
+	switch s := sheet.(type) {
+	case XLSXSheet:
+		sheets = []XLSXSheet{s}
+	case []XLSXSheet:
+		sheets = s
+	default:
+		sheets = []XLSXSheet{{Rows: sheet}}
+	}
+	tables := make([]*table, len(sheets))
+	for i, s := range sheets {
+		t, err := newTable(s.Rows)
+		if err != nil {
+			panic(err)
+		}
+		tables[i] = t
+	}
+
+	c.Status(code)
+	c.setContentType(MIMEXLSX)
+	if err := writeXLSX(c.Writer, sheets, tables); err != nil {
+		c.Error(err)
+	}
+}
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// writeXLSX writes a minimal SpreadsheetML package with inline strings,
+// so no shared string table has to be kept in memory
+func writeXLSX(w io.Writer, sheets []XLSXSheet, tables []*table) error {
+	zw := zip.NewWriter(w)
+
+	var types, sheetList, rels strings.Builder
+	for i, s := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		sheetList.WriteString(`<sheet name="`)
+		xml.EscapeText(&sheetList, []byte(xlsxSheetName(s.Name, n)))
+		fmt.Fprintf(&sheetList, `" sheetId="%d" r:id="rId%d"/>`, n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheetList.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xlsxHeader+part.body); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range tables {
+		f, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeXLSXSheet(f, t); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeXLSXSheet writes the worksheet of t
+func writeXLSXSheet(w io.Writer, t *table) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xlsxHeader + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	row := 0
+	err := t.each(func(cells []reflect.Value) error {
+		row++
+		fmt.Fprintf(bw, `<row r="%d">`, row)
+		for i, cell := range cells {
+			ref := xlsxColumn(i) + strconv.Itoa(row)
+			text := formatCell(cell)
+			if text == "" {
+				continue
+			}
+			if xlsxNumeric(cell) {
+				switch text {
+				case "true":
+					fmt.Fprintf(bw, `<c r="%s" t="b"><v>1</v></c>`, ref)
+				case "false":
+					fmt.Fprintf(bw, `<c r="%s" t="b"><v>0</v></c>`, ref)
+				default:
+					fmt.Fprintf(bw, `<c r="%s"><v>%s</v></c>`, ref, text)
+				}
+				continue
+			}
+			fmt.Fprintf(bw, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(bw, []byte(text))
+			bw.WriteString(`</t></is></c>`)
+		}
+		bw.WriteString(`</row>`)
+		// Flush each row so large sheets stream to the client
+		return bw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	bw.WriteString(`</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+// xlsxNumeric reports whether v is written as a number or boolean cell
+func xlsxNumeric(v reflect.Value) bool {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case encoding.TextMarshaler, fmt.Stringer:
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// xlsxColumn returns the letters of a 0-based column index: A, ..., Z, AA
+func xlsxColumn(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// xlsxSheetName returns a name Excel accepts for the nth sheet
+func xlsxSheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Sheet" + strconv.Itoa(n)
+	}
+	return name
+}